@@ -0,0 +1,252 @@
+// Package adminapi provides authenticated HTTP endpoints to manage a ProviderSetAPI at runtime: add and remove
+// providers, rotate API keys, view health and request metrics, and toggle debug capture.
+//
+// Every request needs an "Authorization: Bearer <token>" header. Request and response bodies are JSON:
+//
+//	GET    /providers                  list providers (without credentials)
+//	POST   /providers                  {"name": "...", "config": AddProviderConfig}
+//	DELETE /providers/{name}
+//	PUT    /providers/{name}/apikey    {"apiKey": "..."}; an empty key clears it
+//	GET    /health                     overall status and provider statuses
+//	GET    /metrics                    per-provider request counters, fed by the event bus
+//	GET    /debug                      {"enabled": bool}
+//	PUT    /debug                      {"enabled": bool}
+package adminapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	inference "github.com/flexigpt/inference-go"
+	"github.com/flexigpt/inference-go/debugclient"
+	"github.com/flexigpt/inference-go/events"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// Config configures the admin endpoints.
+type Config struct {
+	// Token is the bearer token every request must carry. It is required.
+	Token string
+	// EventBus, if set, feeds /metrics. It should be the bus passed to inference.WithEventBus.
+	EventBus *events.Bus
+	// DebugSwitch, if set, is toggled by PUT /debug. It should be the switch in the debugclient.DebugConfig of the
+	// provider set's debug client builder.
+	DebugSwitch *debugclient.CaptureSwitch
+}
+
+// ProviderMetrics are the request counters of one provider since the handler was created. SchemaViolations counts
+// structured outputs that did not match the requested JSON schema.
+type ProviderMetrics struct {
+	Provider         spec.ProviderName `json:"provider"`
+	Requests         int64             `json:"requests"`
+	Failures         int64             `json:"failures"`
+	InputTokens      int64             `json:"inputTokens"`
+	OutputTokens     int64             `json:"outputTokens"`
+	LatencyMillis    int64             `json:"latencyMillis"`
+	SchemaViolations int64             `json:"schemaViolations"`
+	LastError        string            `json:"lastError,omitempty"`
+	LastFinishedAt   time.Time         `json:"lastFinishedAt,omitzero"`
+}
+
+// Health is the body of GET /health. Status is "ok" when every provider is configured and "degraded" otherwise.
+type Health struct {
+	Status    string                     `json:"status"`
+	Providers []inference.ProviderStatus `json:"providers"`
+}
+
+// Handler serves the admin endpoints. Close it to stop collecting metrics.
+type Handler struct {
+	ps    *inference.ProviderSetAPI
+	token string
+	debug *debugclient.CaptureSwitch
+	mux   *http.ServeMux
+
+	sub     *events.Subscription
+	mu      sync.Mutex
+	metrics map[spec.ProviderName]*ProviderMetrics
+}
+
+// NewHandler returns a Handler managing ps.
+func NewHandler(ps *inference.ProviderSetAPI, cfg Config) (*Handler, error) {
+	if ps == nil {
+		return nil, errors.New("adminapi: got nil provider set")
+	}
+	if strings.TrimSpace(cfg.Token) == "" {
+		return nil, errors.New("adminapi: a token is required")
+	}
+	h := &Handler{
+		ps:      ps,
+		token:   cfg.Token,
+		debug:   cfg.DebugSwitch,
+		mux:     http.NewServeMux(),
+		metrics: map[spec.ProviderName]*ProviderMetrics{},
+	}
+	if cfg.EventBus != nil {
+		h.sub = cfg.EventBus.Subscribe(h.record, events.SubscribeOptions{
+			Kinds: []events.Kind{events.KindFinished, events.KindSchemaViolation},
+		})
+	}
+	h.mux.HandleFunc("GET /providers", h.listProviders)
+	h.mux.HandleFunc("POST /providers", h.addProvider)
+	h.mux.HandleFunc("DELETE /providers/{name}", h.deleteProvider)
+	h.mux.HandleFunc("PUT /providers/{name}/apikey", h.setAPIKey)
+	h.mux.HandleFunc("GET /health", h.health)
+	h.mux.HandleFunc("GET /metrics", h.listMetrics)
+	h.mux.HandleFunc("GET /debug", h.getDebug)
+	h.mux.HandleFunc("PUT /debug", h.setDebug)
+	return h, nil
+}
+
+// Close stops collecting metrics.
+func (h *Handler) Close() {
+	if h.sub != nil {
+		h.sub.Close()
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) listProviders(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.ps.ListProviders(r.Context()))
+}
+
+func (h *Handler) addProvider(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name   spec.ProviderName           `json:"name"`
+		Config inference.AddProviderConfig `json:"config"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	if _, err := h.ps.AddProvider(r.Context(), body.Name, &body.Config); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) deleteProvider(w http.ResponseWriter, r *http.Request) {
+	if err := h.ps.DeleteProvider(r.Context(), spec.ProviderName(r.PathValue("name"))); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) setAPIKey(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		APIKey string `json:"apiKey"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	if err := h.ps.SetProviderAPIKey(r.Context(), spec.ProviderName(r.PathValue("name")), body.APIKey); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
+	out := Health{Status: "ok", Providers: h.ps.ListProviders(r.Context())}
+	for _, p := range out.Providers {
+		if !p.Configured {
+			out.Status = "degraded"
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (h *Handler) listMetrics(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.Metrics())
+}
+
+// Metrics returns the request counters of every provider that finished a request, sorted by provider.
+func (h *Handler) Metrics() []ProviderMetrics {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]ProviderMetrics, 0, len(h.metrics))
+	for _, name := range slices.Sorted(maps.Keys(h.metrics)) {
+		out = append(out, *h.metrics[name])
+	}
+	return out
+}
+
+func (h *Handler) record(ev events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	m := h.metrics[ev.Provider]
+	if m == nil {
+		m = &ProviderMetrics{Provider: ev.Provider}
+		h.metrics[ev.Provider] = m
+	}
+	if ev.Kind == events.KindSchemaViolation {
+		m.SchemaViolations++
+		return
+	}
+	m.Requests++
+	m.LatencyMillis += ev.Duration.Milliseconds()
+	m.LastFinishedAt = ev.Time
+	if ev.Usage != nil {
+		m.InputTokens += ev.Usage.InputTokensTotal
+		m.OutputTokens += ev.Usage.OutputTokens
+	}
+	if ev.Err != nil {
+		m.Failures++
+		m.LastError = ev.Err.Error()
+	}
+}
+
+type debugState struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (h *Handler) getDebug(w http.ResponseWriter, _ *http.Request) {
+	if h.debug == nil {
+		writeError(w, http.StatusNotFound, errors.New("debug capture switch not configured"))
+		return
+	}
+	writeJSON(w, http.StatusOK, debugState{Enabled: h.debug.Enabled()})
+}
+
+func (h *Handler) setDebug(w http.ResponseWriter, r *http.Request) {
+	if h.debug == nil {
+		writeError(w, http.StatusNotFound, errors.New("debug capture switch not configured"))
+		return
+	}
+	var body debugState
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	h.debug.SetEnabled(body.Enabled)
+	writeJSON(w, http.StatusOK, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,151 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	inference "github.com/flexigpt/inference-go"
+	"github.com/flexigpt/inference-go/debugclient"
+	"github.com/flexigpt/inference-go/events"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestNewHandlerRequiresToken(t *testing.T) {
+	t.Parallel()
+
+	ps, err := inference.NewProviderSetAPI()
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI() error = %v.", err)
+	}
+	if _, err := NewHandler(ps, Config{}); err == nil {
+		t.Fatalf("NewHandler() error = nil, want an error without a token.")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	bus := events.NewBus()
+	ps, err := inference.NewProviderSetAPI(inference.WithEventBus(bus))
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI() error = %v.", err)
+	}
+	sw := &debugclient.CaptureSwitch{}
+	h, err := NewHandler(ps, Config{Token: "secret", EventBus: bus, DebugSwitch: sw})
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v.", err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	do := func(method, path, token, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest() error = %v.", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Do(%s %s) error = %v.", method, path, err)
+		}
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+	decode := func(resp *http.Response, v any) {
+		t.Helper()
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("Decode() error = %v.", err)
+		}
+	}
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		want   int
+	}{
+		{name: "NoToken.", method: http.MethodGet, path: "/providers", want: http.StatusUnauthorized},
+		{name: "WrongToken.", method: http.MethodGet, path: "/providers", token: "nope", want: http.StatusUnauthorized},
+		{
+			name:   "AddProvider.",
+			method: http.MethodPost,
+			path:   "/providers",
+			token:  "secret",
+			body:   `{"name":"local","config":{"sdkType":"providerSDKTypeOllama","origin":"http://127.0.0.1:1"}}`,
+			want:   http.StatusCreated,
+		},
+		{
+			name:   "AddInvalidProvider.",
+			method: http.MethodPost,
+			path:   "/providers",
+			token:  "secret",
+			body:   `{"name":"bad","config":{"sdkType":"unknown"}}`,
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "RotateKey.",
+			method: http.MethodPut,
+			path:   "/providers/local/apikey",
+			token:  "secret",
+			body:   `{"apiKey":"k2"}`,
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "DebugOff.",
+			method: http.MethodPut,
+			path:   "/debug",
+			token:  "secret",
+			body:   `{"enabled":false}`,
+			want:   http.StatusOK,
+		},
+	}
+	for _, s := range steps {
+		if got := do(s.method, s.path, s.token, s.body).StatusCode; got != s.want {
+			t.Fatalf("%s %s %s status = %d, want %d.", s.name, s.method, s.path, got, s.want)
+		}
+	}
+	if sw.Enabled() {
+		t.Fatalf("CaptureSwitch.Enabled() = true after PUT /debug, want false.")
+	}
+
+	var providers []inference.ProviderStatus
+	decode(do(http.MethodGet, "/providers", "secret", ""), &providers)
+	if len(providers) != 1 || providers[0].Name != "local" || providers[0].SDKType != spec.ProviderSDKTypeOllama {
+		t.Fatalf("GET /providers = %+v, want the added provider.", providers)
+	}
+
+	var health Health
+	decode(do(http.MethodGet, "/health", "secret", ""), &health)
+	if health.Status != "ok" || len(health.Providers) != 1 {
+		t.Fatalf("GET /health = %+v, want ok with one provider.", health)
+	}
+
+	bus.Publish(events.Event{Kind: events.KindFinished, Provider: "local", Duration: 2 * time.Second})
+	bus.Publish(events.Event{Kind: events.KindFinished, Provider: "local", Err: errors.New("boom")})
+	bus.Publish(events.Event{Kind: events.KindStreamChunk, Provider: "local"})
+	bus.Publish(events.Event{Kind: events.KindSchemaViolation, Provider: "local"})
+	h.Close()
+	var metrics []ProviderMetrics
+	decode(do(http.MethodGet, "/metrics", "secret", ""), &metrics)
+	if len(metrics) != 1 || metrics[0].Requests != 2 || metrics[0].Failures != 1 ||
+		metrics[0].LatencyMillis != 2000 || metrics[0].LastError != "boom" || metrics[0].SchemaViolations != 1 {
+		t.Fatalf("GET /metrics = %+v, want two requests with one failure and one schema violation.", metrics)
+	}
+
+	if got := do(http.MethodDelete, "/providers/local", "secret", "").StatusCode; got != http.StatusNoContent {
+		t.Fatalf("DELETE /providers/local status = %d, want %d.", got, http.StatusNoContent)
+	}
+	if got := do(http.MethodDelete, "/providers/local", "secret", "").StatusCode; got != http.StatusNotFound {
+		t.Fatalf("second DELETE /providers/local status = %d, want %d.", got, http.StatusNotFound)
+	}
+}
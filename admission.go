@@ -0,0 +1,137 @@
+package inference
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// AdmissionConfig configures the per-provider admission queue. See WithAdmissionQueue.
+type AdmissionConfig struct {
+	// MaxConcurrent bounds the in-flight FetchCompletion calls of each provider. It must be > 0.
+	MaxConcurrent int `json:"maxConcurrent"`
+	// ClassLimits caps the in-flight calls of a priority class, e.g. {background: 2} keeps slots free for
+	// interactive traffic. Classes without a limit share MaxConcurrent.
+	ClassLimits map[spec.PriorityClass]int `json:"classLimits,omitempty"`
+}
+
+// WithAdmissionQueue queues FetchCompletion calls per provider once MaxConcurrent calls are in flight. Waiting calls
+// are admitted by priority class (FetchCompletionOptions.Priority), then in arrival order, subject to ClassLimits, so
+// batch jobs cannot starve user-facing traffic. A waiting call returns when its context is done.
+func WithAdmissionQueue(cfg AdmissionConfig) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		if cfg.MaxConcurrent <= 0 {
+			return
+		}
+		cfg.ClassLimits = maps.Clone(cfg.ClassLimits)
+		ps.admissionConfig = &cfg
+	}
+}
+
+type admissionQueue struct {
+	mu       sync.Mutex
+	cfg      AdmissionConfig
+	inFlight int
+	perClass map[spec.PriorityClass]int
+	// waiting holds the queued calls, by rank (interactive first).
+	waiting [2][]*admissionWaiter
+}
+
+type admissionWaiter struct {
+	class   spec.PriorityClass
+	granted chan struct{}
+}
+
+func newAdmissionQueue(cfg AdmissionConfig) *admissionQueue {
+	return &admissionQueue{cfg: cfg, perClass: map[spec.PriorityClass]int{}}
+}
+
+// priorityRank maps a class to its queue. Unknown classes wait with background traffic.
+func priorityRank(class spec.PriorityClass) int {
+	if class == spec.PriorityInteractive {
+		return 0
+	}
+	return 1
+}
+
+// acquire blocks until the call is admitted or ctx is done. The returned release must be called once the call ends.
+func (q *admissionQueue) acquire(ctx context.Context, class spec.PriorityClass) (release func(), err error) {
+	if class == "" {
+		class = spec.PriorityInteractive
+	}
+	release = func() { q.release(class) }
+	rank := priorityRank(class)
+
+	q.mu.Lock()
+	queuedAhead := false
+	for r := 0; r <= rank; r++ {
+		queuedAhead = queuedAhead || len(q.waiting[r]) > 0
+	}
+	if !queuedAhead && q.canAdmit(class) {
+		q.admit(class)
+		q.mu.Unlock()
+		return release, nil
+	}
+	w := &admissionWaiter{class: class, granted: make(chan struct{})}
+	q.waiting[rank] = append(q.waiting[rank], w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.granted:
+		return release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		select {
+		case <-w.granted:
+			// Admitted concurrently: hand the slot on.
+			q.inFlight--
+			q.perClass[class]--
+			q.dispatch()
+		default:
+			q.waiting[rank] = slices.DeleteFunc(q.waiting[rank], func(x *admissionWaiter) bool { return x == w })
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (q *admissionQueue) release(class spec.PriorityClass) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.inFlight--
+	q.perClass[class]--
+	q.dispatch()
+}
+
+// dispatch admits waiting calls in rank and arrival order while slots are free. Callers hold q.mu.
+func (q *admissionQueue) dispatch() {
+	for rank := range q.waiting {
+		kept := q.waiting[rank][:0]
+		for _, w := range q.waiting[rank] {
+			if q.canAdmit(w.class) {
+				q.admit(w.class)
+				close(w.granted)
+				continue
+			}
+			kept = append(kept, w)
+		}
+		clear(q.waiting[rank][len(kept):])
+		q.waiting[rank] = kept
+	}
+}
+
+func (q *admissionQueue) canAdmit(class spec.PriorityClass) bool {
+	if q.inFlight >= q.cfg.MaxConcurrent {
+		return false
+	}
+	limit, ok := q.cfg.ClassLimits[class]
+	return !ok || limit <= 0 || q.perClass[class] < limit
+}
+
+func (q *admissionQueue) admit(class spec.PriorityClass) {
+	q.inFlight++
+	q.perClass[class]++
+}
@@ -0,0 +1,82 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestAdmissionQueuePriority(t *testing.T) {
+	t.Parallel()
+
+	q := newAdmissionQueue(AdmissionConfig{MaxConcurrent: 1})
+	release, err := q.acquire(t.Context(), spec.PriorityBackground)
+	if err != nil {
+		t.Fatalf("acquire() error = %v.", err)
+	}
+
+	order := make(chan spec.PriorityClass, 2)
+	wait := func(class spec.PriorityClass) {
+		rel, err := q.acquire(t.Context(), class)
+		if err != nil {
+			t.Errorf("acquire(%s) error = %v.", class, err)
+			return
+		}
+		order <- class
+		rel()
+	}
+	go wait(spec.PriorityBackground)
+	waitQueued(t, q, 1, 1)
+	go wait(spec.PriorityInteractive)
+	waitQueued(t, q, 0, 1)
+
+	release()
+	if got := <-order; got != spec.PriorityInteractive {
+		t.Fatalf("first admitted = %s, want = %s.", got, spec.PriorityInteractive)
+	}
+	if got := <-order; got != spec.PriorityBackground {
+		t.Fatalf("second admitted = %s, want = %s.", got, spec.PriorityBackground)
+	}
+}
+
+func TestAdmissionQueueClassLimit(t *testing.T) {
+	t.Parallel()
+
+	q := newAdmissionQueue(AdmissionConfig{
+		MaxConcurrent: 2,
+		ClassLimits:   map[spec.PriorityClass]int{spec.PriorityBackground: 1},
+	})
+	release, err := q.acquire(t.Context(), spec.PriorityBackground)
+	if err != nil {
+		t.Fatalf("acquire() error = %v.", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.acquire(ctx, spec.PriorityBackground); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire() over class limit error = %v, want = %v.", err, context.DeadlineExceeded)
+	}
+	rel, err := q.acquire(t.Context(), spec.PriorityInteractive)
+	if err != nil {
+		t.Fatalf("acquire() interactive error = %v.", err)
+	}
+	rel()
+}
+
+func waitQueued(t *testing.T, q *admissionQueue, rank, n int) {
+	t.Helper()
+	for range 1000 {
+		q.mu.Lock()
+		got := len(q.waiting[rank])
+		q.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("waiting[%d] never reached %d.", rank, n)
+}
@@ -0,0 +1,455 @@
+// Package agent provides a batteries-included Agent on top of FetchCompletion.
+//
+// An Agent bundles instructions (system prompt), a tool registry, a model routing policy, session memory and
+// guardrails. Run and RunStream drive the tool loop: call the model, execute any requested client-side tools, feed
+// their outputs back, and repeat until the model answers without tool calls.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flexigpt/inference-go/events"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const DefaultMaxSteps = 10
+
+var (
+	// ErrMaxSteps is returned when a run reaches Config.MaxSteps while the model still requests tools.
+	ErrMaxSteps = errors.New("agent: max steps reached")
+)
+
+// CompletionFetcher is the subset of inference.ProviderSetAPI used by the agent.
+type CompletionFetcher interface {
+	FetchCompletion(
+		ctx context.Context,
+		provider spec.ProviderName,
+		fetchCompletionRequest *spec.FetchCompletionRequest,
+		opts *spec.FetchCompletionOptions,
+	) (*spec.FetchCompletionResponse, error)
+}
+
+// ModelRoute is the provider and model configuration used for a single model call.
+type ModelRoute struct {
+	Provider   spec.ProviderName `json:"provider"`
+	ModelParam spec.ModelParam   `json:"modelParam"`
+}
+
+// ModelPolicy chooses the model route for each step of a run.
+type ModelPolicy interface {
+	Route(ctx context.Context, step int, inputs []spec.InputUnion) (ModelRoute, error)
+}
+
+// ModelPolicyFunc adapts a function to ModelPolicy.
+type ModelPolicyFunc func(ctx context.Context, step int, inputs []spec.InputUnion) (ModelRoute, error)
+
+func (f ModelPolicyFunc) Route(ctx context.Context, step int, inputs []spec.InputUnion) (ModelRoute, error) {
+	return f(ctx, step, inputs)
+}
+
+// InputGuardrail inspects the inputs of a run before any model call. Returning an error rejects the run.
+type InputGuardrail func(ctx context.Context, inputs []spec.InputUnion) error
+
+// OutputGuardrail inspects the final model response of a run. Returning an error rejects the result.
+type OutputGuardrail func(ctx context.Context, resp *spec.FetchCompletionResponse) error
+
+// GuardrailError reports a rejection by a guardrail.
+type GuardrailError struct {
+	// Stage is "input" or "output".
+	Stage string
+	Err   error
+}
+
+func (e *GuardrailError) Error() string {
+	return fmt.Sprintf("agent: %s guardrail: %v", e.Stage, e.Err)
+}
+
+func (e *GuardrailError) Unwrap() error { return e.Err }
+
+// Config configures an Agent.
+type Config struct {
+	Name string `json:"name"`
+	// Description tells other agents what this agent does. It is used when the agent is a handoff target or a tool.
+	Description string `json:"description,omitempty"`
+	// Instructions is the system prompt. If non-empty it overrides the routed ModelParam.SystemPrompt.
+	Instructions string `json:"instructions,omitempty"`
+
+	// Model is the default model route. ModelPolicy, if set, takes precedence.
+	Model       ModelRoute  `json:"model"`
+	ModelPolicy ModelPolicy `json:"-"`
+
+	Tools      *ToolRegistry    `json:"-"`
+	ToolPolicy *spec.ToolPolicy `json:"toolPolicy,omitempty"`
+
+	Session SessionConfig `json:"-"`
+
+	// Handoffs are agents this agent may transfer the conversation to. Each is exposed to the model as a
+	// "transfer_to_<name>" tool; when called, the target continues the same run with its own instructions, tools and
+	// model, on the same conversation.
+	Handoffs []*Agent `json:"-"`
+
+	InputGuardrails  []InputGuardrail  `json:"-"`
+	OutputGuardrails []OutputGuardrail `json:"-"`
+
+	// MaxSteps bounds the number of model calls this agent makes in one run. Zero means DefaultMaxSteps.
+	// After a handoff the target's MaxSteps applies, counted from the handoff.
+	MaxSteps int `json:"maxSteps,omitempty"`
+	// Budget bounds the run with graceful finalization. The entry agent's Budget applies across handoffs.
+	Budget Budget `json:"budget"`
+
+	// Events, if set, receives tool call and tool result events of the run.
+	Events *events.Bus `json:"-"`
+
+	// Store, if set, receives a checkpoint after every model call and tool batch so that an interrupted run can be
+	// continued with Resume. The entry agent's Store is used across handoffs.
+	Store Store `json:"-"`
+	// ReasoningPersistence filters reasoning items before they are appended to Session.Session or saved to Store.
+	// Results returned to the caller are not filtered. The entry agent's policy is used across handoffs.
+	ReasoningPersistence spec.ReasoningPersistence `json:"reasoningPersistence,omitempty"`
+
+	// WebSearchCache, if set, caches server-side web search results and replays fresh ones that are missing from
+	// the session history at the start of a run. The entry agent's cache is used across handoffs.
+	WebSearchCache *WebSearchCache `json:"-"`
+
+	// ToolOutputRefs, if set, replaces large tool outputs of earlier turns with short references in model requests,
+	// which the model expands on demand. After a handoff the target's setting applies.
+	ToolOutputRefs *ToolOutputRefs `json:"toolOutputRefs,omitempty"`
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	RunID string `json:"runID"`
+	// Outputs are the outputs of the final model call.
+	Outputs []spec.OutputUnion `json:"outputs,omitempty"`
+	// NewItems are all items produced by this run (model outputs and tool outputs), as inputs for a next turn.
+	NewItems []spec.InputUnion `json:"newItems,omitempty"`
+	// Steps is the number of model calls made.
+	Steps int `json:"steps"`
+	// WebSearchCalls is the number of server-side web search calls made by the model.
+	WebSearchCalls int `json:"webSearchCalls,omitempty"`
+	// Usage is summed over all model calls.
+	Usage spec.Usage `json:"usage"`
+	// Cost is summed over all model calls using Budget.Cost, if set.
+	Cost float64 `json:"cost,omitempty"`
+	// BudgetExhausted names the budget that forced the run to finalize, if any.
+	BudgetExhausted BudgetExhaustion `json:"budgetExhausted,omitempty"`
+	// LastAgent is the name of the agent that produced Outputs, which differs from the entry agent after a handoff.
+	LastAgent string `json:"lastAgent,omitempty"`
+}
+
+// Agent runs the tool loop for a Config. It is safe for concurrent use if its tools and policies are.
+type Agent struct {
+	fetcher CompletionFetcher
+	config  Config
+}
+
+// New validates config and returns an Agent.
+func New(fetcher CompletionFetcher, config Config) (*Agent, error) {
+	if fetcher == nil {
+		return nil, errors.New("agent: nil completion fetcher")
+	}
+	if config.ModelPolicy == nil && (config.Model.Provider == "" || config.Model.ModelParam.Name == "") {
+		return nil, errors.New("agent: a model route or model policy is required")
+	}
+	for _, h := range config.Handoffs {
+		if h == nil || h.config.Name == "" {
+			return nil, errors.New("agent: handoff targets must be named agents")
+		}
+	}
+	if err := config.ReasoningPersistence.Validate(); err != nil {
+		return nil, fmt.Errorf("agent: %w", err)
+	}
+	if config.MaxSteps <= 0 {
+		config.MaxSteps = DefaultMaxSteps
+	}
+	return &Agent{fetcher: fetcher, config: config}, nil
+}
+
+// Config returns the agent configuration.
+func (a *Agent) Config() Config {
+	return a.config
+}
+
+// Run executes the agent on inputs (usually new user messages) without streaming.
+func (a *Agent) Run(ctx context.Context, inputs ...spec.InputUnion) (*Result, error) {
+	return a.run(ctx, nil, inputs)
+}
+
+// RunStream executes the agent on inputs, streaming every model call to handler.
+func (a *Agent) RunStream(
+	ctx context.Context,
+	handler spec.StreamHandler,
+	inputs ...spec.InputUnion,
+) (*Result, error) {
+	if handler == nil {
+		return nil, errors.New("agent: nil stream handler")
+	}
+	return a.run(ctx, handler, inputs)
+}
+
+func (a *Agent) run(ctx context.Context, handler spec.StreamHandler, inputs []spec.InputUnion) (*Result, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("agent: no inputs")
+	}
+	for _, g := range a.config.InputGuardrails {
+		if err := g(ctx, inputs); err != nil {
+			return nil, &GuardrailError{Stage: "input", Err: err}
+		}
+	}
+
+	runID, ok := RunIDFromContext(ctx)
+	if !ok {
+		runID = newRunID()
+	}
+	history := a.config.Session.history()
+	history = append(history, a.config.WebSearchCache.replay(a.toolChoices(), history)...)
+	cp := &Checkpoint{
+		RunID:        runID,
+		Agent:        a.config.Name,
+		StartedAt:    time.Now(),
+		Conversation: append(history, inputs...),
+		NewItems:     slices.Clone(inputs),
+	}
+	return a.loop(ctx, handler, cp)
+}
+
+// loop drives the tool loop from the state in cp, checkpointing to Config.Store after every model call and tool
+// batch.
+func (a *Agent) loop(ctx context.Context, handler spec.StreamHandler, cp *Checkpoint) (*Result, error) {
+	cur := a.findAgent(cp.Agent)
+	if cur == nil {
+		return nil, fmt.Errorf("agent: unknown agent %q in checkpoint", cp.Agent)
+	}
+	budget := newBudgetTracker(ctx, a.config.Budget, cp.StartedAt)
+	callCtx, cancel := budget.context(ctx, cp.StartedAt)
+	defer cancel()
+
+	var resp *spec.FetchCompletionResponse
+	for {
+		if len(cp.PendingToolCalls) > 0 {
+			if next := cur.runPendingToolCalls(callCtx, cp); next != nil {
+				cur, cp.Agent, cp.AgentSteps = next, next.config.Name, 0
+			}
+			if err := a.save(ctx, cp); err != nil {
+				return cp.result(), err
+			}
+		}
+		if cp.AgentSteps >= cur.config.MaxSteps {
+			cp.LastAgent = cur.config.Name
+			return cp.result(), ErrMaxSteps
+		}
+		route, err := cur.route(ctx, cp.AgentSteps, cp.Conversation)
+		if err != nil {
+			return cp.result(), err
+		}
+
+		inputs, referenced := cur.config.ToolOutputRefs.apply(cp.Conversation)
+		choices := cur.toolChoices()
+		if referenced {
+			choices = append(choices, expandToolOutputChoice())
+		}
+		req := &spec.FetchCompletionRequest{
+			ModelParam:  route.ModelParam,
+			Inputs:      inputs,
+			ToolPolicy:  cur.config.ToolPolicy,
+			ToolChoices: budget.toolChoices(choices, cp),
+		}
+		if cp.BudgetExhausted != BudgetExhaustionNone {
+			req.ToolPolicy = &spec.ToolPolicy{Mode: spec.ToolPolicyModeNone}
+		}
+		if cur.config.Instructions != "" {
+			req.ModelParam.SystemPrompt = cur.config.Instructions
+		}
+		var opts *spec.FetchCompletionOptions
+		if handler != nil {
+			req.ModelParam.Stream = true
+			opts = &spec.FetchCompletionOptions{StreamHandler: handler}
+		}
+
+		resp, err = cur.fetcher.FetchCompletion(callCtx, route.Provider, req, opts)
+		cp.Steps++
+		cp.AgentSteps++
+		cp.LastAgent = cur.config.Name
+		if resp != nil {
+			addUsage(&cp.Usage, resp.Usage)
+			cp.WebSearchCalls += webSearchCalls(resp.Outputs)
+			budget.addModelCall(route, resp.Usage, cp)
+		}
+		if err != nil {
+			return cp.result(), err
+		}
+
+		produced := outputsToInputs(resp.Outputs)
+		a.config.WebSearchCache.record(cur.toolChoices(), produced)
+		cp.ResponseStart = len(cp.Conversation)
+		cp.Conversation = append(cp.Conversation, produced...)
+		cp.NewItems = append(cp.NewItems, produced...)
+		cp.Outputs = resp.Outputs
+
+		calls := pendingToolCalls(resp.Outputs)
+		if len(calls) == 0 || cp.BudgetExhausted != BudgetExhaustionNone {
+			break
+		}
+		if reason := budget.exhausted(cp, len(calls)); reason != BudgetExhaustionNone {
+			cp.BudgetExhausted = reason
+			final := budget.finalizeInputs(calls, reason)
+			cp.Conversation = append(cp.Conversation, final...)
+			cp.NewItems = append(cp.NewItems, final...)
+			cp.AgentSteps = min(cp.AgentSteps, cur.config.MaxSteps-1)
+		} else {
+			cp.PendingToolCalls = calls
+		}
+		if err := a.save(ctx, cp); err != nil {
+			return cp.result(), err
+		}
+	}
+
+	res := cp.result()
+	for _, g := range cur.config.OutputGuardrails {
+		if err := g(ctx, resp); err != nil {
+			return res, &GuardrailError{Stage: "output", Err: err}
+		}
+	}
+	if a.config.Session.Session != nil {
+		a.config.Session.Session.Append(a.config.ReasoningPersistence.ApplyInputs(cp.NewItems)...)
+	}
+	if a.config.Store != nil {
+		if err := a.config.Store.Delete(ctx, cp.RunID); err != nil {
+			return res, fmt.Errorf("agent: delete checkpoint: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// runPendingToolCalls executes cp.PendingToolCalls and appends their outputs. Handoffs are resolved in order; all
+// other calls run concurrently. It returns the handoff target, if any.
+func (a *Agent) runPendingToolCalls(ctx context.Context, cp *Checkpoint) *Agent {
+	calls := cp.PendingToolCalls
+	outs := make([]spec.InputUnion, len(calls))
+	var toolCalls []spec.ToolCall
+	var toolIdx []int
+	var next *Agent
+	for i, call := range calls {
+		if a.config.ToolOutputRefs != nil && call.Name == ExpandToolOutputToolName {
+			outs[i] = expandToolOutput(call, cp.Conversation)
+			continue
+		}
+		target := a.handoff(call.Name)
+		if target == nil {
+			toolCalls = append(toolCalls, call)
+			toolIdx = append(toolIdx, i)
+			continue
+		}
+		text := "Transferred to " + target.config.Name + "."
+		if next != nil {
+			text = "Ignored: the conversation was already transferred to " + next.config.Name + "."
+		} else {
+			next = target
+		}
+		outs[i] = toolOutputInput(call, text, nil, nil)
+	}
+
+	history := cp.Conversation[:cp.ResponseStart]
+	cp.ToolCalls += len(toolCalls)
+	bus := a.config.Events
+	for _, call := range toolCalls {
+		bus.Publish(events.Event{Kind: events.KindToolCall, RunID: cp.RunID, Agent: a.config.Name, ToolCall: &call})
+	}
+	for j, out := range a.config.Tools.runTools(withCallerHistory(ctx, history), toolCalls) {
+		outs[toolIdx[j]] = out
+		if bus.Active() {
+			ev := events.Event{
+				Kind:     events.KindToolResult,
+				RunID:    cp.RunID,
+				Agent:    a.config.Name,
+				ToolCall: &toolCalls[j],
+			}
+			if ev.ToolOutput = toolOutputOf(out); ev.ToolOutput != nil && ev.ToolOutput.Execution != nil {
+				ev.Duration = time.Duration(ev.ToolOutput.Execution.DurationMillis) * time.Millisecond
+			}
+			bus.Publish(ev)
+		}
+	}
+	cp.Conversation = append(cp.Conversation, outs...)
+	cp.NewItems = append(cp.NewItems, outs...)
+	cp.PendingToolCalls = nil
+	return next
+}
+
+func (a *Agent) route(ctx context.Context, step int, inputs []spec.InputUnion) (ModelRoute, error) {
+	if a.config.ModelPolicy == nil {
+		return a.config.Model, nil
+	}
+	r, err := a.config.ModelPolicy.Route(ctx, step, inputs)
+	if err != nil {
+		return ModelRoute{}, fmt.Errorf("agent: model policy: %w", err)
+	}
+	if r.Provider == "" || r.ModelParam.Name == "" {
+		return ModelRoute{}, errors.New("agent: model policy returned an empty route")
+	}
+	return r, nil
+}
+
+// UserText returns a user text message input.
+func UserText(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role: spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			}},
+		},
+	}
+}
+
+// OutputText concatenates all assistant text in outputs.
+func OutputText(outputs []spec.OutputUnion) string {
+	var b strings.Builder
+	for _, o := range outputs {
+		if o.Kind != spec.OutputKindOutputMessage || o.OutputMessage == nil {
+			continue
+		}
+		for _, c := range o.OutputMessage.Contents {
+			if c.Kind == spec.ContentItemKindText && c.TextItem != nil {
+				b.WriteString(c.TextItem.Text)
+			}
+		}
+	}
+	return b.String()
+}
+
+func addUsage(dst, u *spec.Usage) {
+	if u == nil {
+		return
+	}
+	dst.InputTokensTotal += u.InputTokensTotal
+	dst.InputTokensCached += u.InputTokensCached
+	dst.InputTokensUncached += u.InputTokensUncached
+	dst.OutputTokens += u.OutputTokens
+	dst.ReasoningTokens += u.ReasoningTokens
+	if u.Cost != nil {
+		cost := *u.Cost
+		if dst.Cost != nil {
+			cost += *dst.Cost
+		}
+		dst.Cost = &cost
+	}
+}
+
+// webSearchCalls counts the server-side web search calls in outputs.
+func webSearchCalls(outputs []spec.OutputUnion) int {
+	n := 0
+	for _, o := range outputs {
+		if o.Kind == spec.OutputKindWebSearchToolCall && o.WebSearchToolCall != nil {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,667 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// scriptedFetcher returns one scripted response per call and records requests.
+type scriptedFetcher struct {
+	responses []*spec.FetchCompletionResponse
+	requests  []*spec.FetchCompletionRequest
+}
+
+func (f *scriptedFetcher) FetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	f.requests = append(f.requests, req)
+	if len(f.requests) > len(f.responses) {
+		return nil, errors.New("unexpected call")
+	}
+	return f.responses[len(f.requests)-1], nil
+}
+
+func toolCallResponse(name, args string) *spec.FetchCompletionResponse {
+	return &spec.FetchCompletionResponse{
+		Outputs: []spec.OutputUnion{{
+			Kind: spec.OutputKindFunctionToolCall,
+			FunctionToolCall: &spec.ToolCall{
+				Type: spec.ToolTypeFunction, ChoiceID: name, ID: "c1", CallID: "c1", Name: name, Arguments: args,
+			},
+		}},
+		Usage: &spec.Usage{OutputTokens: 1},
+	}
+}
+
+func textResponse(text string) *spec.FetchCompletionResponse {
+	return &spec.FetchCompletionResponse{
+		Outputs: []spec.OutputUnion{{
+			Kind: spec.OutputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{
+				Role: spec.RoleAssistant,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: text},
+				}},
+			},
+		}},
+		Usage: &spec.Usage{OutputTokens: 2},
+	}
+}
+
+func webSearchOutputs(callID, query string) []spec.OutputUnion {
+	return []spec.OutputUnion{
+		{Kind: spec.OutputKindWebSearchToolCall, WebSearchToolCall: &spec.ToolCall{
+			Type: spec.ToolTypeWebSearch, ChoiceID: "ws", CallID: callID,
+			WebSearchToolCallItems: []spec.WebSearchToolCallItemUnion{{
+				Kind:       spec.WebSearchToolCallKindSearch,
+				SearchItem: &spec.WebSearchToolCallSearch{Query: query},
+			}},
+		}},
+		{Kind: spec.OutputKindWebSearchToolOutput, WebSearchToolOutput: &spec.ToolOutput{
+			Type: spec.ToolTypeWebSearch, ChoiceID: "ws", CallID: callID,
+		}},
+	}
+}
+
+func TestAgentRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		responses []*spec.FetchCompletionResponse
+		maxSteps  int
+		wantErr   error
+		wantSteps int
+		wantText  string
+	}{
+		{
+			name:      "ToolLoopRunsUntilFinalAnswer.",
+			responses: []*spec.FetchCompletionResponse{toolCallResponse("add", `{"a":1}`), textResponse("done")},
+			wantSteps: 2,
+			wantText:  "done",
+		},
+		{
+			name: "MaxStepsStopsTheLoop.",
+			responses: []*spec.FetchCompletionResponse{
+				toolCallResponse("add", `{}`), toolCallResponse("add", `{}`),
+			},
+			maxSteps:  2,
+			wantErr:   ErrMaxSteps,
+			wantSteps: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var toolCalls int
+			tools, err := NewToolRegistry(Tool{
+				Choice: spec.ToolChoice{Name: "add", Arguments: map[string]any{"type": "object"}},
+				Handler: func(ctx context.Context, call spec.ToolCall) (string, error) {
+					toolCalls++
+					return "2", nil
+				},
+			})
+			if err != nil {
+				t.Fatalf("NewToolRegistry() error = %v.", err)
+			}
+			session := NewSession()
+			f := &scriptedFetcher{responses: tc.responses}
+			a, err := New(f, Config{
+				Instructions: "be brief",
+				Model:        ModelRoute{Provider: "p", ModelParam: spec.ModelParam{Name: "m"}},
+				Tools:        tools,
+				Session:      SessionConfig{Session: session},
+				MaxSteps:     tc.maxSteps,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v.", err)
+			}
+
+			res, err := a.Run(t.Context(), UserText("hi"))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Run() error = %v, want = %v.", err, tc.wantErr)
+			}
+			if res.Steps != tc.wantSteps {
+				t.Fatalf("Steps = %d, want = %d.", res.Steps, tc.wantSteps)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+			if got := OutputText(res.Outputs); got != tc.wantText {
+				t.Fatalf("OutputText() = %q, want = %q.", got, tc.wantText)
+			}
+			if toolCalls != 1 {
+				t.Fatalf("tool calls = %d, want = 1.", toolCalls)
+			}
+			// Second request carries user input, tool call and tool output.
+			if got := len(f.requests[1].Inputs); got != 3 {
+				t.Fatalf("second request inputs = %d, want = 3.", got)
+			}
+			if f.requests[0].ModelParam.SystemPrompt != "be brief" {
+				t.Fatalf("SystemPrompt = %q, want = %q.", f.requests[0].ModelParam.SystemPrompt, "be brief")
+			}
+			if got := len(session.Items()); got != 4 {
+				t.Fatalf("session items = %d, want = 4.", got)
+			}
+			if res.Usage.OutputTokens != 3 {
+				t.Fatalf("Usage.OutputTokens = %d, want = 3.", res.Usage.OutputTokens)
+			}
+		})
+	}
+}
+
+func TestAgentHandoff(t *testing.T) {
+	t.Parallel()
+
+	f := &scriptedFetcher{responses: []*spec.FetchCompletionResponse{
+		toolCallResponse(HandoffToolName("billing agent"), `{}`),
+		textResponse("refunded"),
+	}}
+	route := ModelRoute{Provider: "p", ModelParam: spec.ModelParam{Name: "m"}}
+	billing, err := New(f, Config{Name: "billing agent", Instructions: "handle billing", Model: route})
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+	triage, err := New(f, Config{Name: "triage", Instructions: "route", Model: route, Handoffs: []*Agent{billing}})
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+
+	res, err := triage.Run(t.Context(), UserText("refund please"))
+	if err != nil {
+		t.Fatalf("Run() error = %v.", err)
+	}
+	if res.LastAgent != "billing agent" {
+		t.Fatalf("LastAgent = %q, want = %q.", res.LastAgent, "billing agent")
+	}
+	if got := f.requests[0].ToolChoices[0].Name; got != "transfer_to_billing_agent" {
+		t.Fatalf("handoff tool = %q, want = %q.", got, "transfer_to_billing_agent")
+	}
+	if got := f.requests[1].ModelParam.SystemPrompt; got != "handle billing" {
+		t.Fatalf("SystemPrompt = %q, want = %q.", got, "handle billing")
+	}
+	if got := len(f.requests[1].Inputs); got != 3 {
+		t.Fatalf("second request inputs = %d, want = 3.", got)
+	}
+	if got := OutputText(res.Outputs); got != "refunded" {
+		t.Fatalf("OutputText() = %q, want = %q.", got, "refunded")
+	}
+}
+
+func TestAgentBudgetFinalizes(t *testing.T) {
+	t.Parallel()
+
+	f := &scriptedFetcher{responses: []*spec.FetchCompletionResponse{
+		toolCallResponse("add", `{}`), toolCallResponse("add", `{}`), textResponse("best effort"),
+	}}
+	var toolCalls int
+	tools, err := NewToolRegistry(Tool{
+		Choice: spec.ToolChoice{Name: "add", Arguments: map[string]any{"type": "object"}},
+		Handler: func(context.Context, spec.ToolCall) (string, error) {
+			toolCalls++
+			return "2", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewToolRegistry() error = %v.", err)
+	}
+	a, err := New(f, Config{
+		Model:  ModelRoute{Provider: "p", ModelParam: spec.ModelParam{Name: "m"}},
+		Tools:  tools,
+		Budget: Budget{MaxToolCalls: 1, Cost: func(ModelRoute, *spec.Usage) float64 { return 0.5 }},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+
+	res, err := a.Run(t.Context(), UserText("hi"))
+	if err != nil {
+		t.Fatalf("Run() error = %v.", err)
+	}
+	if res.BudgetExhausted != BudgetExhaustionToolCalls {
+		t.Fatalf("BudgetExhausted = %q, want = %q.", res.BudgetExhausted, BudgetExhaustionToolCalls)
+	}
+	if toolCalls != 1 {
+		t.Fatalf("tool calls = %d, want = 1.", toolCalls)
+	}
+	if res.Cost != 1.5 {
+		t.Fatalf("Cost = %v, want = 1.5.", res.Cost)
+	}
+	final := f.requests[2]
+	if final.ToolPolicy == nil || final.ToolPolicy.Mode != spec.ToolPolicyModeNone {
+		t.Fatalf("final ToolPolicy = %+v, want mode none.", final.ToolPolicy)
+	}
+	skipped := final.Inputs[len(final.Inputs)-2].FunctionToolOutput
+	if skipped == nil || skipped.Error == nil || skipped.Error.Code != ToolErrorCodeBudgetExhausted {
+		t.Fatalf("skipped tool output = %+v, want budget_exhausted error.", skipped)
+	}
+	if got := OutputText(res.Outputs); got != "best effort" {
+		t.Fatalf("OutputText() = %q, want = %q.", got, "best effort")
+	}
+}
+
+// blockingFetcher blocks until ctx is done and records its cause.
+type blockingFetcher struct {
+	cause error
+}
+
+func (f *blockingFetcher) FetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	<-ctx.Done()
+	f.cause = context.Cause(ctx)
+	return nil, ctx.Err()
+}
+
+func TestAgentBudgetCancelsOverrunningCall(t *testing.T) {
+	t.Parallel()
+
+	f := &blockingFetcher{}
+	a, err := New(f, Config{
+		Model:  ModelRoute{Provider: "p", ModelParam: spec.ModelParam{Name: "m"}},
+		Budget: Budget{MaxDuration: 50 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+
+	if _, err := a.Run(t.Context(), UserText("hi")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v, want context.DeadlineExceeded.", err)
+	}
+	if !errors.Is(f.cause, spec.ErrBudgetExhausted) {
+		t.Fatalf("context.Cause() = %v, want spec.ErrBudgetExhausted.", f.cause)
+	}
+}
+
+func TestAgentResume(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore()
+	var toolCalls int
+	newAgent := func(f *scriptedFetcher) *Agent {
+		tools, err := NewToolRegistry(Tool{
+			Choice: spec.ToolChoice{Name: "add", Arguments: map[string]any{"type": "object"}},
+			Handler: func(context.Context, spec.ToolCall) (string, error) {
+				toolCalls++
+				return "2", nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("NewToolRegistry() error = %v.", err)
+		}
+		a, err := New(f, Config{
+			Model: ModelRoute{Provider: "p", ModelParam: spec.ModelParam{Name: "m"}},
+			Tools: tools,
+			Store: store,
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v.", err)
+		}
+		return a
+	}
+
+	// The first process dies on its second model call.
+	first := &scriptedFetcher{responses: []*spec.FetchCompletionResponse{toolCallResponse("add", `{}`)}}
+	ctx := WithRunID(t.Context(), "run-1")
+	if _, err := newAgent(first).Run(ctx, UserText("hi")); err == nil {
+		t.Fatal("Run() error = nil, want an error.")
+	}
+
+	second := &scriptedFetcher{responses: []*spec.FetchCompletionResponse{textResponse("done")}}
+	res, err := newAgent(second).Resume(t.Context(), "run-1")
+	if err != nil {
+		t.Fatalf("Resume() error = %v.", err)
+	}
+	if len(second.requests) != 1 || len(second.requests[0].Inputs) != 3 {
+		t.Fatalf("resumed requests = %d, want = 1 with 3 inputs.", len(second.requests))
+	}
+	if toolCalls != 1 {
+		t.Fatalf("tool calls = %d, want = 1.", toolCalls)
+	}
+	if res.RunID != "run-1" || res.Steps != 2 || OutputText(res.Outputs) != "done" {
+		t.Fatalf("Resume() = %+v, want run-1 finished after 2 steps.", res)
+	}
+	if _, err := store.Load(t.Context(), "run-1"); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Fatalf("Load() error = %v, want = %v.", err, ErrCheckpointNotFound)
+	}
+}
+
+func TestAgentReasoningPersistence(t *testing.T) {
+	t.Parallel()
+
+	reasoning := &spec.ReasoningContent{
+		ID: "r1", Role: spec.RoleAssistant, Signature: "sig",
+		Summary: []string{"sum"}, Thinking: []string{"think"}, EncryptedContent: []string{"enc"},
+	}
+	tests := []struct {
+		name   string
+		policy spec.ReasoningPersistence
+		want   *spec.ReasoningContent
+	}{
+		{name: "KeepAllPersistsUnchanged.", policy: spec.ReasoningPersistenceKeepAll, want: reasoning},
+		{
+			name:   "EncryptedOnlyDropsPlaintext.",
+			policy: spec.ReasoningPersistenceEncryptedOnly,
+			want:   &spec.ReasoningContent{ID: "r1", Role: spec.RoleAssistant, EncryptedContent: []string{"enc"}},
+		},
+		{
+			name:   "SummariesKeepsSummary.",
+			policy: spec.ReasoningPersistenceSummaries,
+			want:   &spec.ReasoningContent{ID: "r1", Role: spec.RoleAssistant, Summary: []string{"sum"}},
+		},
+		{name: "DropAllRemovesReasoning.", policy: spec.ReasoningPersistenceDropAll},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := textResponse("done")
+			resp.Outputs = append(
+				[]spec.OutputUnion{{Kind: spec.OutputKindReasoningMessage, ReasoningMessage: reasoning}},
+				resp.Outputs...,
+			)
+			session := NewSession()
+			a, err := New(&scriptedFetcher{responses: []*spec.FetchCompletionResponse{resp}}, Config{
+				Model:                ModelRoute{Provider: "p", ModelParam: spec.ModelParam{Name: "m"}},
+				Session:              SessionConfig{Session: session},
+				ReasoningPersistence: tc.policy,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v.", err)
+			}
+			res, err := a.Run(t.Context(), UserText("hi"))
+			if err != nil {
+				t.Fatalf("Run() error = %v.", err)
+			}
+			if len(res.Outputs) != 2 || !reflect.DeepEqual(res.Outputs[0].ReasoningMessage, reasoning) {
+				t.Fatalf("Outputs = %+v, want the unfiltered reasoning.", res.Outputs)
+			}
+
+			var got *spec.ReasoningContent
+			for _, in := range session.Items() {
+				if in.Kind == spec.InputKindReasoningMessage {
+					got = in.ReasoningMessage
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("persisted reasoning = %+v, want = %+v.", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAgentWebSearchCache(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		age        time.Duration
+		wantReplay bool
+	}{
+		{name: "FreshResultIsReplayed.", age: time.Minute, wantReplay: true},
+		{name: "ExpiredResultIsDropped.", age: time.Hour},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			searched := textResponse("found")
+			searched.Outputs = append(webSearchOutputs("s1", "Go  1.25"), searched.Outputs...)
+			f := &scriptedFetcher{responses: []*spec.FetchCompletionResponse{searched, textResponse("again")}}
+
+			start := time.Now()
+			cache := NewWebSearchCache(30 * time.Minute)
+			cache.now = func() time.Time { return start }
+			tools, err := NewToolRegistry(Tool{Choice: spec.ToolChoice{
+				Type: spec.ToolTypeWebSearch, ID: "ws", Name: "web_search",
+				WebSearchArguments: &spec.WebSearchToolChoiceItem{AllowedDomains: []string{"go.dev"}},
+			}})
+			if err != nil {
+				t.Fatalf("NewToolRegistry() error = %v.", err)
+			}
+			a, err := New(f, Config{
+				Model:          ModelRoute{Provider: "p", ModelParam: spec.ModelParam{Name: "m"}},
+				Tools:          tools,
+				Session:        SessionConfig{Session: NewSession(), MaxHistoryItems: 1},
+				WebSearchCache: cache,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v.", err)
+			}
+			res, err := a.Run(t.Context(), UserText("search"))
+			if err != nil {
+				t.Fatalf("Run() error = %v.", err)
+			}
+			meta := res.NewItems[2].WebSearchToolOutput.Cache
+			if meta == nil || meta.Key != "go 1.25|allow=go.dev|block=" || meta.Hit {
+				t.Fatalf("recorded cache metadata = %+v, want a stored entry.", meta)
+			}
+
+			cache.now = func() time.Time { return start.Add(tc.age) }
+			if _, err := a.Run(t.Context(), UserText("again")); err != nil {
+				t.Fatalf("Run() error = %v.", err)
+			}
+			var replayed *spec.ToolOutput
+			for _, in := range f.requests[1].Inputs {
+				if in.Kind == spec.InputKindWebSearchToolOutput {
+					replayed = in.WebSearchToolOutput
+				}
+			}
+			if got := replayed != nil; got != tc.wantReplay {
+				t.Fatalf("replayed = %v, want = %v.", got, tc.wantReplay)
+			}
+			if replayed != nil && (replayed.Cache == nil || !replayed.Cache.Hit) {
+				t.Fatalf("replayed cache metadata = %+v, want a hit.", replayed.Cache)
+			}
+		})
+	}
+}
+
+func TestAgentWebSearchBudget(t *testing.T) {
+	t.Parallel()
+
+	searchAndCall := func(callID string) *spec.FetchCompletionResponse {
+		resp := toolCallResponse("add", `{}`)
+		resp.Outputs = append(webSearchOutputs(callID, "q "+callID), resp.Outputs...)
+		return resp
+	}
+	f := &scriptedFetcher{responses: []*spec.FetchCompletionResponse{
+		searchAndCall("s1"), searchAndCall("s2"), textResponse("done"),
+	}}
+	tools, err := NewToolRegistry(
+		Tool{Choice: spec.ToolChoice{
+			Type: spec.ToolTypeWebSearch, ID: "ws", Name: "web_search",
+			WebSearchArguments: &spec.WebSearchToolChoiceItem{MaxUses: 5},
+		}},
+		Tool{
+			Choice:  spec.ToolChoice{Name: "add", Arguments: map[string]any{"type": "object"}},
+			Handler: func(context.Context, spec.ToolCall) (string, error) { return "2", nil },
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewToolRegistry() error = %v.", err)
+	}
+	a, err := New(f, Config{
+		Model:  ModelRoute{Provider: "p", ModelParam: spec.ModelParam{Name: "m"}},
+		Tools:  tools,
+		Budget: Budget{MaxWebSearches: 2},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+	res, err := a.Run(t.Context(), UserText("hi"))
+	if err != nil {
+		t.Fatalf("Run() error = %v.", err)
+	}
+	if res.WebSearchCalls != 2 {
+		t.Fatalf("WebSearchCalls = %d, want = 2.", res.WebSearchCalls)
+	}
+
+	wantMaxUses := []int64{2, 1, 0}
+	for i, req := range f.requests {
+		var got int64
+		for _, ch := range req.ToolChoices {
+			if ch.Type == spec.ToolTypeWebSearch {
+				got = ch.WebSearchArguments.MaxUses
+			}
+		}
+		if got != wantMaxUses[i] {
+			t.Fatalf("request %d web search MaxUses = %d, want = %d.", i, got, wantMaxUses[i])
+		}
+	}
+	if got := tools.Choices()[0].WebSearchArguments.MaxUses; got != 5 {
+		t.Fatalf("registered MaxUses = %d, want = 5.", got)
+	}
+}
+
+func TestAgentToolOutputRefs(t *testing.T) {
+	t.Parallel()
+
+	big := strings.Repeat("x", 3000)
+	expand := &spec.FetchCompletionResponse{Outputs: []spec.OutputUnion{{
+		Kind: spec.OutputKindFunctionToolCall,
+		FunctionToolCall: &spec.ToolCall{
+			Type: spec.ToolTypeFunction, ChoiceID: ExpandToolOutputToolName, ID: "c2", CallID: "c2",
+			Name: ExpandToolOutputToolName, Arguments: `{"callID":"c1"}`,
+		},
+	}}}
+	f := &scriptedFetcher{responses: []*spec.FetchCompletionResponse{
+		toolCallResponse("fetch", `{}`), expand, textResponse("done"),
+	}}
+	tools, err := NewToolRegistry(Tool{
+		Choice:  spec.ToolChoice{Type: spec.ToolTypeFunction, ID: "fetch", Name: "fetch", Arguments: map[string]any{}},
+		Handler: func(context.Context, spec.ToolCall) (string, error) { return big, nil },
+	})
+	if err != nil {
+		t.Fatalf("NewToolRegistry() error = %v.", err)
+	}
+	a, err := New(f, Config{
+		Model:          ModelRoute{Provider: "p", ModelParam: spec.ModelParam{Name: "m"}},
+		Tools:          tools,
+		ToolOutputRefs: &ToolOutputRefs{PreviewBytes: 5},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+	res, err := a.Run(t.Context(), UserText("fetch it"))
+	if err != nil {
+		t.Fatalf("Run() error = %v.", err)
+	}
+
+	outputText := func(req *spec.FetchCompletionRequest, callID string) string {
+		for _, in := range req.Inputs {
+			if in.Kind == spec.InputKindFunctionToolOutput && in.FunctionToolOutput.CallID == callID {
+				text, _ := toolOutputText(in.FunctionToolOutput)
+				return text
+			}
+		}
+		return ""
+	}
+	hasExpand := func(req *spec.FetchCompletionRequest) bool {
+		return slices.ContainsFunc(req.ToolChoices, func(c spec.ToolChoice) bool {
+			return c.Name == ExpandToolOutputToolName
+		})
+	}
+
+	// The latest tool batch is sent in full.
+	if got := outputText(f.requests[1], "c1"); got != big || hasExpand(f.requests[1]) {
+		t.Fatalf("second request output = %d bytes, expand tool = %v, want the full output only.",
+			len(got), hasExpand(f.requests[1]))
+	}
+	// Once older, it is replaced by a reference and the expand tool is offered.
+	ref := outputText(f.requests[2], "c1")
+	if !strings.Contains(ref, `"xxxxx..."`) || !strings.Contains(ref, `callID "c1"`) || !hasExpand(f.requests[2]) {
+		t.Fatalf("third request output = %q, expand tool = %v, want a reference.", ref, hasExpand(f.requests[2]))
+	}
+	if got := outputText(f.requests[2], "c2"); got != big {
+		t.Fatalf("expanded output = %d bytes, want %d.", len(got), len(big))
+	}
+	// The run's items keep the full output.
+	if got, _ := toolOutputText(res.NewItems[2].FunctionToolOutput); got != big {
+		t.Fatalf("NewItems output = %d bytes, want the full output.", len(got))
+	}
+}
+
+func TestSessionCompact(t *testing.T) {
+	t.Parallel()
+
+	call := func(id, args string) spec.InputUnion {
+		return spec.InputUnion{Kind: spec.InputKindFunctionToolCall, FunctionToolCall: &spec.ToolCall{
+			Type: spec.ToolTypeFunction, ID: id, CallID: id, Name: "lookup", Arguments: args,
+		}}
+	}
+	output := func(id, text string) spec.InputUnion {
+		return toolOutputInput(spec.ToolCall{Type: spec.ToolTypeFunction, CallID: id, Name: "lookup"}, text, nil, nil)
+	}
+	reasoning := spec.InputUnion{
+		Kind:             spec.InputKindReasoningMessage,
+		ReasoningMessage: &spec.ReasoningContent{Signature: "sig", Thinking: []string{"hmm"}},
+	}
+	answer := outputsToInputs(textResponse("It is sunny.").Outputs)[0]
+	history := []spec.InputUnion{
+		UserText("weather?"), reasoning, call("c1", `{"city":"Paris"}`), output("c1", "sunny, 25C"), answer,
+		UserText("thanks"), answer,
+		UserText("and tomorrow?"), reasoning, call("c2", `{"city":"Paris","day":1}`), output("c2", "rain"),
+	}
+
+	tests := []struct {
+		name        string
+		keep        int
+		wantRemoved int
+		wantLen     int
+	}{
+		{name: "TailAlreadyShort.", keep: 20, wantRemoved: 0, wantLen: len(history)},
+		// The tail starts at "and tomorrow?"; the first turn collapses into one assistant message.
+		{name: "FirstTurnCollapsed.", keep: 4, wantRemoved: 3, wantLen: len(history) - 3},
+		// No user message in the last item: nothing can be cut safely.
+		{name: "NoSafeCut.", keep: 1, wantRemoved: 0, wantLen: len(history)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewSession(history...)
+			if got := s.Compact(CompactOptions{KeepRecent: tc.keep}); got != tc.wantRemoved {
+				t.Fatalf("Compact() = %d, want %d.", got, tc.wantRemoved)
+			}
+			items := s.Items()
+			if len(items) != tc.wantLen {
+				t.Fatalf("len(Items()) = %d, want %d.", len(items), tc.wantLen)
+			}
+			if !reflect.DeepEqual(items[len(items)-4:], history[len(history)-4:]) {
+				t.Fatalf("tail = %+v, want it unchanged.", items[len(items)-4:])
+			}
+			if tc.wantRemoved == 0 {
+				return
+			}
+			summary := items[1]
+			if summary.Kind != spec.InputKindOutputMessage || len(summary.OutputMessage.Contents) != 2 {
+				t.Fatalf("items[1] = %+v, want one assistant message with text and summary.", summary)
+			}
+			contents := summary.OutputMessage.Contents
+			got := contents[0].TextItem.Text + "\n" + contents[1].TextItem.Text
+			want := "It is sunny.\n[Earlier tool calls, compacted]\n- lookup({\"city\":\"Paris\"}) -> sunny, 25C"
+			if got != want {
+				t.Fatalf("summary = %q, want %q.", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// DefaultFinalizeInstruction is sent to the model when a budget is exhausted.
+const DefaultFinalizeInstruction = "The budget for this task is exhausted (%s). Do not call any tools. " +
+	"Give your best final answer now with the information you already have."
+
+// BudgetExhaustion names the budget that ended a run.
+type BudgetExhaustion string
+
+const (
+	BudgetExhaustionNone      BudgetExhaustion = ""
+	BudgetExhaustionDuration  BudgetExhaustion = "duration"
+	BudgetExhaustionTokens    BudgetExhaustion = "tokens"
+	BudgetExhaustionCost      BudgetExhaustion = "cost"
+	BudgetExhaustionToolCalls BudgetExhaustion = "toolCalls"
+)
+
+// CostFunc returns the cost of one model call. The unit is up to the caller (e.g. USD).
+type CostFunc func(route ModelRoute, usage *spec.Usage) float64
+
+// Budget bounds a run. Budgets are checked whenever the model requests tools: once one is exhausted, the pending
+// tool calls are not executed (they get a "budget_exhausted" error output), and the model is called once more with
+// tools disabled and FinalizeInstruction, so the run ends with an answer instead of an error. Zero fields are
+// unlimited.
+type Budget struct {
+	// MaxDuration bounds the wall-clock time of the run. If ctx has an earlier deadline, that deadline is used.
+	// Model and tool calls still running when it elapses are canceled with spec.ErrBudgetExhausted as the cause.
+	MaxDuration time.Duration `json:"maxDuration,omitempty"`
+	// FinalizeReserve is kept free before the deadline for the finalizing model call.
+	FinalizeReserve time.Duration `json:"finalizeReserve,omitempty"`
+	// MaxTokens bounds input plus output tokens summed over the run.
+	MaxTokens int64 `json:"maxTokens,omitempty"`
+	// MaxCost bounds the summed Cost of model calls. It requires Cost.
+	MaxCost float64  `json:"maxCost,omitempty"`
+	Cost    CostFunc `json:"-"`
+	// MaxToolCalls bounds the number of executed tool calls (handoffs excluded).
+	MaxToolCalls int `json:"maxToolCalls,omitempty"`
+	// MaxWebSearches bounds server-side web search calls over the run. Web search tools offered to a model call get
+	// MaxUses capped to the remaining budget, and are no longer offered once it is used up. It does not finalize
+	// the run. Providers that ignore MaxUses can overshoot within a single model call.
+	MaxWebSearches int `json:"maxWebSearches,omitempty"`
+	// FinalizeInstruction is a format string with one %s for the exhausted budget.
+	// Empty means DefaultFinalizeInstruction.
+	FinalizeInstruction string `json:"finalizeInstruction,omitempty"`
+}
+
+// budgetTracker checks a Budget against the consumption recorded in a run's Checkpoint.
+type budgetTracker struct {
+	budget   Budget
+	deadline time.Time
+}
+
+func newBudgetTracker(ctx context.Context, b Budget, startedAt time.Time) *budgetTracker {
+	t := &budgetTracker{budget: b}
+	if b.MaxDuration > 0 {
+		t.deadline = startedAt.Add(b.MaxDuration)
+	}
+	if d, ok := ctx.Deadline(); ok && (t.deadline.IsZero() || d.Before(t.deadline)) {
+		t.deadline = d
+	}
+	return t
+}
+
+// context bounds ctx by MaxDuration, so model and tool calls that overrun it are canceled with
+// spec.ErrBudgetExhausted as the cause.
+func (t *budgetTracker) context(ctx context.Context, startedAt time.Time) (context.Context, context.CancelFunc) {
+	if t.budget.MaxDuration <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithDeadlineCause(ctx, startedAt.Add(t.budget.MaxDuration), spec.ErrBudgetExhausted)
+}
+
+// addModelCall accounts the cost of a model call into cp.
+func (t *budgetTracker) addModelCall(route ModelRoute, usage *spec.Usage, cp *Checkpoint) {
+	if t.budget.Cost != nil && usage != nil {
+		cp.Cost += t.budget.Cost(route, usage)
+	}
+}
+
+// toolChoices applies MaxWebSearches to choices, given the web searches already made in cp.
+func (t *budgetTracker) toolChoices(choices []spec.ToolChoice, cp *Checkpoint) []spec.ToolChoice {
+	if t.budget.MaxWebSearches <= 0 {
+		return choices
+	}
+	remaining := int64(t.budget.MaxWebSearches - cp.WebSearchCalls)
+	out := make([]spec.ToolChoice, 0, len(choices))
+	for _, ch := range choices {
+		if ch.Type == spec.ToolTypeWebSearch {
+			if remaining <= 0 {
+				continue
+			}
+			args := spec.WebSearchToolChoiceItem{}
+			if ch.WebSearchArguments != nil {
+				args = *ch.WebSearchArguments
+			}
+			if args.MaxUses <= 0 || args.MaxUses > remaining {
+				args.MaxUses = remaining
+			}
+			ch.WebSearchArguments = &args
+		}
+		out = append(out, ch)
+	}
+	return out
+}
+
+// exhausted reports which budget, if any, prevents executing pendingToolCalls more tool calls.
+func (t *budgetTracker) exhausted(cp *Checkpoint, pendingToolCalls int) BudgetExhaustion {
+	b := t.budget
+	switch {
+	case !t.deadline.IsZero() && time.Until(t.deadline) <= b.FinalizeReserve:
+		return BudgetExhaustionDuration
+	case b.MaxTokens > 0 && cp.Usage.InputTokensTotal+cp.Usage.OutputTokens >= b.MaxTokens:
+		return BudgetExhaustionTokens
+	case b.MaxCost > 0 && cp.Cost >= b.MaxCost:
+		return BudgetExhaustionCost
+	case b.MaxToolCalls > 0 && cp.ToolCalls+pendingToolCalls > b.MaxToolCalls:
+		return BudgetExhaustionToolCalls
+	default:
+		return BudgetExhaustionNone
+	}
+}
+
+// finalizeInputs returns the error outputs for the skipped tool calls followed by the finalize instruction.
+func (t *budgetTracker) finalizeInputs(calls []spec.ToolCall, reason BudgetExhaustion) []spec.InputUnion {
+	out := make([]spec.InputUnion, 0, len(calls)+1)
+	for _, call := range calls {
+		toolErr := &spec.ToolError{
+			Code:    ToolErrorCodeBudgetExhausted,
+			Message: fmt.Sprintf("not executed: %s budget exhausted", reason),
+		}
+		out = append(out, toolOutputInput(call, "", toolErr, nil))
+	}
+	instruction := t.budget.FinalizeInstruction
+	if instruction == "" {
+		instruction = DefaultFinalizeInstruction
+	}
+	return append(out, UserText(fmt.Sprintf(instruction, reason)))
+}
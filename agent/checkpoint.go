@@ -0,0 +1,198 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ErrCheckpointNotFound is returned by Store.Load for an unknown run ID.
+var ErrCheckpointNotFound = errors.New("agent: checkpoint not found")
+
+// Checkpoint is the persisted state of an in-flight run. It holds everything needed to continue the run without
+// repeating model calls that were already paid for: the conversation so far, the tool calls requested by the last
+// model call that have not run yet, and the budget consumption.
+type Checkpoint struct {
+	RunID string `json:"runID"`
+	// Agent is the name of the agent currently driving the run (the entry agent or a handoff target).
+	Agent     string    `json:"agent"`
+	StartedAt time.Time `json:"startedAt"`
+
+	Conversation []spec.InputUnion `json:"conversation"`
+	NewItems     []spec.InputUnion `json:"newItems,omitempty"`
+	// ResponseStart is the index in Conversation where the items of the last model call start.
+	ResponseStart    int                `json:"responseStart"`
+	PendingToolCalls []spec.ToolCall    `json:"pendingToolCalls,omitempty"`
+	Outputs          []spec.OutputUnion `json:"outputs,omitempty"`
+
+	Steps           int              `json:"steps"`
+	AgentSteps      int              `json:"agentSteps"`
+	ToolCalls       int              `json:"toolCalls"`
+	WebSearchCalls  int              `json:"webSearchCalls,omitempty"`
+	Usage           spec.Usage       `json:"usage"`
+	Cost            float64          `json:"cost,omitempty"`
+	BudgetExhausted BudgetExhaustion `json:"budgetExhausted,omitempty"`
+	LastAgent       string           `json:"lastAgent,omitempty"`
+}
+
+func (cp *Checkpoint) result() *Result {
+	return &Result{
+		RunID:           cp.RunID,
+		Outputs:         cp.Outputs,
+		NewItems:        slices.Clone(cp.NewItems),
+		Steps:           cp.Steps,
+		WebSearchCalls:  cp.WebSearchCalls,
+		Usage:           cp.Usage,
+		Cost:            cp.Cost,
+		BudgetExhausted: cp.BudgetExhausted,
+		LastAgent:       cp.LastAgent,
+	}
+}
+
+// Store persists run checkpoints. Implementations must be safe for concurrent use.
+type Store interface {
+	Save(ctx context.Context, cp *Checkpoint) error
+	// Load returns ErrCheckpointNotFound if no checkpoint exists for runID.
+	Load(ctx context.Context, runID string) (*Checkpoint, error)
+	Delete(ctx context.Context, runID string) error
+}
+
+// MemoryStore is an in-process Store. Checkpoints are stored JSON-encoded, the same as a durable store would.
+type MemoryStore struct {
+	mu   sync.Mutex
+	runs map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{runs: map[string][]byte{}}
+}
+
+func (s *MemoryStore) Save(ctx context.Context, cp *Checkpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[cp.RunID] = b
+	return nil
+}
+
+func (s *MemoryStore) Load(ctx context.Context, runID string) (*Checkpoint, error) {
+	s.mu.Lock()
+	b, ok := s.runs[runID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrCheckpointNotFound
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.runs, runID)
+	return nil
+}
+
+type runIDKey struct{}
+
+// WithRunID sets the run ID used to checkpoint a run started with ctx. Without it a random ID is generated and
+// reported in Result.RunID, which is too late to resume after a crash.
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, runID)
+}
+
+// RunIDFromContext returns the run ID set by WithRunID.
+func RunIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(runIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Resume continues an interrupted run from its checkpoint in Config.Store. Pending tool calls of the last model
+// call are executed first; completed model calls are not repeated. Input guardrails are not re-run.
+func (a *Agent) Resume(ctx context.Context, runID string) (*Result, error) {
+	return a.resume(ctx, nil, runID)
+}
+
+// ResumeStream is Resume with streaming of the remaining model calls to handler.
+func (a *Agent) ResumeStream(ctx context.Context, handler spec.StreamHandler, runID string) (*Result, error) {
+	if handler == nil {
+		return nil, errors.New("agent: nil stream handler")
+	}
+	return a.resume(ctx, handler, runID)
+}
+
+func (a *Agent) resume(ctx context.Context, handler spec.StreamHandler, runID string) (*Result, error) {
+	if a.config.Store == nil {
+		return nil, errors.New("agent: no checkpoint store configured")
+	}
+	cp, err := a.config.Store.Load(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("agent: load checkpoint: %w", err)
+	}
+	return a.loop(ctx, handler, cp)
+}
+
+func (a *Agent) save(ctx context.Context, cp *Checkpoint) error {
+	if a.config.Store == nil {
+		return nil
+	}
+	if err := a.config.Store.Save(ctx, a.persisted(cp)); err != nil {
+		return fmt.Errorf("agent: save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// persisted returns cp with the reasoning persistence policy applied, keeping ResponseStart pointing at the same
+// item.
+func (a *Agent) persisted(cp *Checkpoint) *Checkpoint {
+	p := a.config.ReasoningPersistence
+	if p == spec.ReasoningPersistenceKeepAll {
+		return cp
+	}
+	c := *cp
+	c.Conversation = p.ApplyInputs(cp.Conversation[:cp.ResponseStart])
+	c.ResponseStart = len(c.Conversation)
+	c.Conversation = append(c.Conversation, p.ApplyInputs(cp.Conversation[cp.ResponseStart:])...)
+	c.NewItems = p.ApplyInputs(cp.NewItems)
+	c.Outputs = p.ApplyOutputs(cp.Outputs)
+	return &c
+}
+
+// findAgent returns the agent named name among a and the agents reachable from it through handoffs.
+func (a *Agent) findAgent(name string) *Agent {
+	seen := map[*Agent]bool{}
+	queue := []*Agent{a}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if seen[cur] {
+			continue
+		}
+		seen[cur] = true
+		if cur.config.Name == name {
+			return cur
+		}
+		queue = append(queue, cur.config.Handoffs...)
+	}
+	return nil
+}
+
+func newRunID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	defaultCompactKeepRecent   = 10
+	defaultCompactExcerptBytes = 200
+)
+
+// CompactOptions configures Session.Compact.
+type CompactOptions struct {
+	// KeepRecent is the minimum number of most recent items left untouched. Zero means 10.
+	KeepRecent int
+	// ExcerptBytes bounds the tool arguments and output excerpts in the summary. Zero means 200.
+	ExcerptBytes int
+}
+
+// Compact shrinks the history in place. Items older than the KeepRecent most recent ones are rewritten turn by turn:
+// user messages are kept, and everything the model produced in between (assistant text, reasoning, tool calls and
+// their outputs) collapses into one assistant message holding the assistant text and a short summary of each tool
+// call. It returns the number of items removed.
+//
+// The kept tail always starts at a user message, so no tool output loses its call and no reasoning item is separated
+// from the tool use it precedes: Anthropic signed thinking blocks and OpenAI encrypted reasoning stay valid in the
+// tail, while the ones in the compacted turns, which providers allow to be omitted, are dropped.
+func (s *Session) Compact(opts CompactOptions) int {
+	keep := opts.KeepRecent
+	if keep <= 0 {
+		keep = defaultCompactKeepRecent
+	}
+	excerpt := opts.ExcerptBytes
+	if excerpt <= 0 {
+		excerpt = defaultCompactExcerptBytes
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cut := -1
+	for i := max(len(s.items)-keep, 0); i < len(s.items); i++ {
+		if s.items[i].Kind == spec.InputKindInputMessage {
+			cut = i
+			break
+		}
+	}
+	if cut <= 0 {
+		return 0
+	}
+
+	head := compactTurns(s.items[:cut], excerpt)
+	if len(head) >= cut {
+		return 0
+	}
+	removed := cut - len(head)
+	s.items = append(head, s.items[cut:]...)
+	return removed
+}
+
+// compactTurns collapses the model items between user messages into single assistant messages.
+func compactTurns(items []spec.InputUnion, excerpt int) []spec.InputUnion {
+	outputs := map[string]*spec.ToolOutput{}
+	for _, in := range items {
+		if out := anyToolOutputOf(in); out != nil && out.CallID != "" {
+			outputs[out.CallID] = out
+		}
+	}
+
+	var (
+		result []spec.InputUnion
+		turn   []spec.InputUnion
+	)
+	flush := func() {
+		if len(turn) > 0 {
+			result = append(result, compactTurn(turn, outputs, excerpt)...)
+			turn = nil
+		}
+	}
+	for _, in := range items {
+		if in.Kind == spec.InputKindInputMessage {
+			flush()
+			result = append(result, in)
+			continue
+		}
+		turn = append(turn, in)
+	}
+	flush()
+	return result
+}
+
+// compactTurn returns turn unchanged if it is a single assistant message, and otherwise one assistant message with
+// its text and tool call summary. A turn with neither is dropped.
+func compactTurn(turn []spec.InputUnion, outputs map[string]*spec.ToolOutput, excerpt int) []spec.InputUnion {
+	if len(turn) == 1 && turn[0].Kind == spec.InputKindOutputMessage {
+		return turn
+	}
+	var (
+		contents []spec.InputOutputContentItemUnion
+		calls    []string
+	)
+	for _, in := range turn {
+		if in.Kind == spec.InputKindOutputMessage && in.OutputMessage != nil {
+			for _, c := range in.OutputMessage.Contents {
+				if c.Kind == spec.ContentItemKindText && c.TextItem != nil {
+					contents = append(contents, spec.InputOutputContentItemUnion{
+						Kind:     spec.ContentItemKindText,
+						TextItem: &spec.ContentItemText{Text: c.TextItem.Text},
+					})
+				}
+			}
+			continue
+		}
+		if call := anyToolCallOf(in); call != nil {
+			calls = append(calls, toolCallSummary(call, outputs[call.CallID], excerpt))
+		}
+	}
+	if len(calls) > 0 {
+		contents = append(contents, spec.InputOutputContentItemUnion{
+			Kind: spec.ContentItemKindText,
+			TextItem: &spec.ContentItemText{
+				Text: "[Earlier tool calls, compacted]\n" + strings.Join(calls, "\n"),
+			},
+		})
+	}
+	if len(contents) == 0 {
+		return nil
+	}
+	return []spec.InputUnion{{
+		Kind:          spec.InputKindOutputMessage,
+		OutputMessage: &spec.InputOutputContent{Role: spec.RoleAssistant, Contents: contents},
+	}}
+}
+
+func toolCallSummary(call *spec.ToolCall, out *spec.ToolOutput, excerpt int) string {
+	name := call.Name
+	if name == "" {
+		name = string(call.Type)
+	}
+	line := fmt.Sprintf("- %s(%s)", name, excerptOf(call.Arguments, excerpt))
+	switch {
+	case out == nil:
+		return line
+	case out.IsError && out.Error != nil:
+		return line + " failed: " + excerptOf(out.Error.Message, excerpt)
+	}
+	if text, ok := toolOutputText(out); ok {
+		return line + " -> " + excerptOf(text, excerpt)
+	}
+	return line + " -> (non-text output)"
+}
+
+func excerptOf(s string, maxBytes int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= maxBytes {
+		return s
+	}
+	return truncateUTF8(s, maxBytes) + "..."
+}
+
+func anyToolCallOf(in spec.InputUnion) *spec.ToolCall {
+	switch in.Kind {
+	case spec.InputKindFunctionToolCall:
+		return in.FunctionToolCall
+	case spec.InputKindCustomToolCall:
+		return in.CustomToolCall
+	case spec.InputKindWebSearchToolCall:
+		return in.WebSearchToolCall
+	case spec.InputKindCodeExecutionToolCall:
+		return in.CodeExecutionToolCall
+	case spec.InputKindCodeInterpreterToolCall:
+		return in.CodeInterpreterToolCall
+	case spec.InputKindFileSearchToolCall:
+		return in.FileSearchToolCall
+	case spec.InputKindComputerToolCall:
+		return in.ComputerToolCall
+	case spec.InputKindMCPToolCall:
+		return in.MCPToolCall
+	default:
+		return nil
+	}
+}
+
+func anyToolOutputOf(in spec.InputUnion) *spec.ToolOutput {
+	switch in.Kind {
+	case spec.InputKindFunctionToolOutput:
+		return in.FunctionToolOutput
+	case spec.InputKindCustomToolOutput:
+		return in.CustomToolOutput
+	case spec.InputKindWebSearchToolOutput:
+		return in.WebSearchToolOutput
+	case spec.InputKindCodeExecutionToolOutput:
+		return in.CodeExecutionToolOutput
+	case spec.InputKindComputerToolOutput:
+		return in.ComputerToolOutput
+	default:
+		return nil
+	}
+}
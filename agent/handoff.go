@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const handoffToolPrefix = "transfer_to_"
+
+// HandoffToolName returns the tool name under which a handoff to the named agent is exposed to the model.
+func HandoffToolName(agentName string) string {
+	return handoffToolPrefix + toolSafeName(agentName)
+}
+
+// DelegateOptions configures an agent exposed as a tool of another agent.
+type DelegateOptions struct {
+	// Name is the tool name. Defaults to the agent name.
+	Name string
+	// Description defaults to the agent description.
+	Description string
+	// IncludeHistory passes the caller's conversation, up to the model call that requested the tool, to the delegate
+	// ahead of the task. Otherwise the delegate only sees the task.
+	IncludeHistory bool
+	// MaxSteps, if > 0, overrides the delegate's MaxSteps for each delegated run.
+	MaxSteps int
+	// Budget, if non-nil, replaces the delegate's Budget for each delegated run.
+	Budget *Budget
+}
+
+// AsTool exposes the agent as a tool that another agent can call to delegate a task. The tool takes a single
+// "input" string argument; the delegate's final text answer is returned as the tool output. Errors of the delegated
+// run, including exhausted budgets, are reported to the caller model as error tool outputs.
+func (a *Agent) AsTool(opts DelegateOptions) Tool {
+	name := opts.Name
+	if name == "" {
+		name = toolSafeName(a.config.Name)
+	}
+	desc := opts.Description
+	if desc == "" {
+		desc = a.config.Description
+	}
+	delegate := *a
+	if opts.MaxSteps > 0 {
+		delegate.config.MaxSteps = opts.MaxSteps
+	}
+	if opts.Budget != nil {
+		delegate.config.Budget = *opts.Budget
+	}
+
+	return Tool{
+		Choice: spec.ToolChoice{
+			Type:        spec.ToolTypeFunction,
+			Name:        name,
+			Description: desc,
+			Arguments: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"input": map[string]any{
+						"type":        "string",
+						"description": "The task for the agent, with all context it needs.",
+					},
+				},
+				"required":             []any{"input"},
+				"additionalProperties": false,
+			},
+		},
+		Handler: func(ctx context.Context, call spec.ToolCall) (string, error) {
+			var args struct {
+				Input string `json:"input"`
+			}
+			if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil {
+				return "", &ToolError{Code: ToolErrorCodeInvalidArguments, Message: err.Error()}
+			}
+			if strings.TrimSpace(args.Input) == "" {
+				return "", &ToolError{Code: ToolErrorCodeInvalidArguments, Message: "input is required"}
+			}
+			var inputs []spec.InputUnion
+			if opts.IncludeHistory {
+				inputs = callerHistory(ctx)
+			}
+			inputs = append(inputs, UserText(args.Input))
+
+			res, err := delegate.Run(ctx, inputs...)
+			if err != nil {
+				return "", err
+			}
+			return OutputText(res.Outputs), nil
+		},
+	}
+}
+
+// toolChoices returns the registered tools followed by one tool per handoff target.
+func (a *Agent) toolChoices() []spec.ToolChoice {
+	choices := a.config.Tools.Choices()
+	for _, h := range a.config.Handoffs {
+		name := HandoffToolName(h.config.Name)
+		desc := "Transfer the conversation to the " + h.config.Name + " agent."
+		if h.config.Description != "" {
+			desc += " " + h.config.Description
+		}
+		choices = append(choices, spec.ToolChoice{
+			Type:        spec.ToolTypeFunction,
+			ID:          name,
+			Name:        name,
+			Description: desc,
+			Arguments:   map[string]any{"type": "object", "properties": map[string]any{}},
+		})
+	}
+	return choices
+}
+
+// handoff returns the handoff target for a tool name, or nil.
+func (a *Agent) handoff(toolName string) *Agent {
+	if !strings.HasPrefix(toolName, handoffToolPrefix) {
+		return nil
+	}
+	for _, h := range a.config.Handoffs {
+		if HandoffToolName(h.config.Name) == toolName {
+			return h
+		}
+	}
+	return nil
+}
+
+type callerHistoryKey struct{}
+
+func withCallerHistory(ctx context.Context, history []spec.InputUnion) context.Context {
+	return context.WithValue(ctx, callerHistoryKey{}, slices.Clip(history))
+}
+
+// callerHistory returns a copy of the conversation of the run that invoked the current tool.
+func callerHistory(ctx context.Context) []spec.InputUnion {
+	h, _ := ctx.Value(callerHistoryKey{}).([]spec.InputUnion)
+	return slices.Clone(h)
+}
+
+// toolSafeName maps a name to the characters accepted in tool names by all providers.
+func toolSafeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
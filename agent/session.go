@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// Session is an in-memory conversation history shared across runs. It is safe for concurrent use.
+type Session struct {
+	mu    sync.RWMutex
+	items []spec.InputUnion
+}
+
+// NewSession returns a session seeded with the given history.
+func NewSession(history ...spec.InputUnion) *Session {
+	return &Session{items: slices.Clone(history)}
+}
+
+// Items returns a copy of the history.
+func (s *Session) Items() []spec.InputUnion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.items)
+}
+
+// Append adds items to the history.
+func (s *Session) Append(items ...spec.InputUnion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, items...)
+}
+
+// SessionConfig controls how an agent uses conversation memory.
+type SessionConfig struct {
+	// Session, if non-nil, supplies history before each run and receives the run's new items afterwards.
+	Session *Session
+	// MaxHistoryItems, if > 0, keeps only the most recent history items when building a request.
+	// The cut is moved forward so that history never starts with a dangling tool output.
+	MaxHistoryItems int
+}
+
+func (c SessionConfig) history() []spec.InputUnion {
+	if c.Session == nil {
+		return nil
+	}
+	items := c.Session.Items()
+	if c.MaxHistoryItems <= 0 || len(items) <= c.MaxHistoryItems {
+		return items
+	}
+	items = items[len(items)-c.MaxHistoryItems:]
+	for len(items) > 0 && isToolOutput(items[0]) {
+		items = items[1:]
+	}
+	return items
+}
+
+func isToolOutput(in spec.InputUnion) bool {
+	switch in.Kind {
+	case spec.InputKindFunctionToolOutput, spec.InputKindCustomToolOutput, spec.InputKindWebSearchToolOutput,
+		spec.InputKindCodeExecutionToolOutput, spec.InputKindComputerToolOutput:
+		return true
+	default:
+		return false
+	}
+}
+
+// outputsToInputs converts model outputs into input items so they can be sent back on the next turn.
+func outputsToInputs(outputs []spec.OutputUnion) []spec.InputUnion {
+	out := make([]spec.InputUnion, 0, len(outputs))
+	for _, o := range outputs {
+		switch o.Kind {
+		case spec.OutputKindOutputMessage:
+			out = append(out, spec.InputUnion{Kind: spec.InputKindOutputMessage, OutputMessage: o.OutputMessage})
+		case spec.OutputKindReasoningMessage:
+			out = append(
+				out,
+				spec.InputUnion{Kind: spec.InputKindReasoningMessage, ReasoningMessage: o.ReasoningMessage},
+			)
+		case spec.OutputKindFunctionToolCall:
+			out = append(
+				out,
+				spec.InputUnion{Kind: spec.InputKindFunctionToolCall, FunctionToolCall: o.FunctionToolCall},
+			)
+		case spec.OutputKindCustomToolCall:
+			out = append(out, spec.InputUnion{Kind: spec.InputKindCustomToolCall, CustomToolCall: o.CustomToolCall})
+		case spec.OutputKindWebSearchToolCall:
+			out = append(
+				out,
+				spec.InputUnion{Kind: spec.InputKindWebSearchToolCall, WebSearchToolCall: o.WebSearchToolCall},
+			)
+		case spec.OutputKindWebSearchToolOutput:
+			out = append(
+				out,
+				spec.InputUnion{Kind: spec.InputKindWebSearchToolOutput, WebSearchToolOutput: o.WebSearchToolOutput},
+			)
+		case spec.OutputKindCodeExecutionToolCall:
+			out = append(out, spec.InputUnion{
+				Kind:                  spec.InputKindCodeExecutionToolCall,
+				CodeExecutionToolCall: o.CodeExecutionToolCall,
+			})
+		case spec.OutputKindCodeExecutionToolOutput:
+			out = append(out, spec.InputUnion{
+				Kind:                    spec.InputKindCodeExecutionToolOutput,
+				CodeExecutionToolOutput: o.CodeExecutionToolOutput,
+			})
+		case spec.OutputKindImageGenerationToolOutput:
+			out = append(out, spec.InputUnion{
+				Kind:                      spec.InputKindImageGenerationToolOutput,
+				ImageGenerationToolOutput: o.ImageGenerationToolOutput,
+			})
+		case spec.OutputKindCodeInterpreterToolCall:
+			out = append(out, spec.InputUnion{
+				Kind:                    spec.InputKindCodeInterpreterToolCall,
+				CodeInterpreterToolCall: o.CodeInterpreterToolCall,
+			})
+		case spec.OutputKindFileSearchToolCall:
+			out = append(out, spec.InputUnion{
+				Kind:               spec.InputKindFileSearchToolCall,
+				FileSearchToolCall: o.FileSearchToolCall,
+			})
+		case spec.OutputKindComputerToolCall:
+			out = append(out, spec.InputUnion{
+				Kind:             spec.InputKindComputerToolCall,
+				ComputerToolCall: o.ComputerToolCall,
+			})
+		case spec.OutputKindMCPToolCall:
+			out = append(out, spec.InputUnion{Kind: spec.InputKindMCPToolCall, MCPToolCall: o.MCPToolCall})
+		case spec.OutputKindMCPListTools:
+			out = append(out, spec.InputUnion{Kind: spec.InputKindMCPListTools, MCPListTools: o.MCPListTools})
+		}
+	}
+	return out
+}
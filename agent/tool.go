@@ -0,0 +1,359 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ToolHandler executes a single tool call and returns the text handed back to the model.
+// A returned error becomes an error tool output; return a *ToolError to choose its code.
+type ToolHandler func(ctx context.Context, call spec.ToolCall) (string, error)
+
+// Error codes set by the tool runner. Handlers may use these or their own codes.
+const (
+	ToolErrorCodeUnknownTool      = "unknown_tool"
+	ToolErrorCodeInvalidArguments = "invalid_arguments"
+	ToolErrorCodeTimeout          = "timeout"
+	ToolErrorCodeCanceled         = "canceled"
+	ToolErrorCodePanic            = "panic"
+	ToolErrorCodeFailed           = "tool_failed"
+	ToolErrorCodeBudgetExhausted  = "budget_exhausted"
+)
+
+// ToolError is a tool failure with a machine-readable code. Errors that are not (or do not wrap) a ToolError are
+// reported with ToolErrorCodeFailed.
+type ToolError struct {
+	Code    string
+	Message string
+}
+
+func (e *ToolError) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return e.Code + ": " + e.Message
+}
+
+// ExecutionPolicy bounds how a tool handler is executed. The zero value runs the handler once, without timeout or
+// output limit, one call at a time.
+type ExecutionPolicy struct {
+	// Timeout, if > 0, bounds each attempt. A handler that ignores ctx is abandoned when the timeout fires and its
+	// concurrency slot is released, so a retry does not wait for it; abandoned handlers are not counted by
+	// MaxConcurrency.
+	Timeout time.Duration
+	// MaxOutputBytes, if > 0, truncates the output handed back to the model.
+	MaxOutputBytes int
+	// MaxConcurrency bounds concurrent executions of the tool across calls and runs. Zero means 1.
+	MaxConcurrency int
+	// MaxRetries is the number of extra attempts after a failed attempt (error, timeout or panic).
+	MaxRetries int
+	// RetryDelay is the pause between attempts.
+	RetryDelay time.Duration
+}
+
+// Tool is a client-side tool: its definition sent to the model plus the Go handler that executes it.
+type Tool struct {
+	// Choice is the tool definition. Choice.ID defaults to Choice.Name and Choice.Type to ToolTypeFunction.
+	Choice spec.ToolChoice
+	// Handler executes the tool. It is required except for server-side tools (ToolTypeWebSearch,
+	// ToolTypeCodeExecution, ToolTypeImageGeneration, ToolTypeCodeInterpreter, ToolTypeFileSearch and ToolTypeMCP),
+	// which the provider runs.
+	Handler ToolHandler
+	Policy  ExecutionPolicy
+}
+
+// ToolRegistry holds the tools available to an agent, keyed by tool name. It is safe for concurrent use.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]*registeredTool
+	order []string
+}
+
+type registeredTool struct {
+	Tool
+
+	slots chan struct{}
+}
+
+// NewToolRegistry returns a registry with the given tools.
+func NewToolRegistry(tools ...Tool) (*ToolRegistry, error) {
+	r := &ToolRegistry{tools: map[string]*registeredTool{}}
+	for _, t := range tools {
+		if err := r.Register(t); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a tool. Tool names must be unique.
+func (r *ToolRegistry) Register(t Tool) error {
+	if t.Choice.Name == "" {
+		return errors.New("agent: tool name is required")
+	}
+	if t.Handler == nil && !isServerTool(t.Choice.Type) {
+		return fmt.Errorf("agent: tool %q has no handler", t.Choice.Name)
+	}
+	if t.Choice.Type == "" {
+		t.Choice.Type = spec.ToolTypeFunction
+	}
+	if t.Choice.ID == "" {
+		t.Choice.ID = t.Choice.Name
+	}
+	if t.Policy.MaxConcurrency <= 0 {
+		t.Policy.MaxConcurrency = 1
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tools[t.Choice.Name]; ok {
+		return fmt.Errorf("agent: duplicate tool %q", t.Choice.Name)
+	}
+	r.tools[t.Choice.Name] = &registeredTool{Tool: t, slots: make(chan struct{}, t.Policy.MaxConcurrency)}
+	r.order = append(r.order, t.Choice.Name)
+	return nil
+}
+
+// Lookup returns the tool with the given name.
+func (r *ToolRegistry) Lookup(name string) (Tool, bool) {
+	if r == nil {
+		return Tool{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tools[name]
+	if !ok {
+		return Tool{}, false
+	}
+	return t.Tool, true
+}
+
+// Choices returns the tool definitions in registration order.
+func (r *ToolRegistry) Choices() []spec.ToolChoice {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]spec.ToolChoice, 0, len(r.order))
+	for _, n := range r.order {
+		out = append(out, r.tools[n].Choice)
+	}
+	return out
+}
+
+// isServerTool reports whether tools of type t are run by the provider.
+func isServerTool(t spec.ToolType) bool {
+	switch t {
+	case spec.ToolTypeWebSearch, spec.ToolTypeCodeExecution, spec.ToolTypeImageGeneration,
+		spec.ToolTypeCodeInterpreter, spec.ToolTypeFileSearch, spec.ToolTypeMCP:
+		return true
+	default:
+		return false
+	}
+}
+
+// pendingToolCalls returns the client-side (function/custom) tool calls in outputs.
+func pendingToolCalls(outputs []spec.OutputUnion) []spec.ToolCall {
+	var calls []spec.ToolCall
+	for _, o := range outputs {
+		switch {
+		case o.Kind == spec.OutputKindFunctionToolCall && o.FunctionToolCall != nil:
+			calls = append(calls, *o.FunctionToolCall)
+		case o.Kind == spec.OutputKindCustomToolCall && o.CustomToolCall != nil:
+			calls = append(calls, *o.CustomToolCall)
+		}
+	}
+	return calls
+}
+
+// runTools executes tool calls concurrently, subject to each tool's policy, and returns their outputs in call order.
+func (r *ToolRegistry) runTools(ctx context.Context, calls []spec.ToolCall) []spec.InputUnion {
+	outs := make([]spec.InputUnion, len(calls))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Go(func() {
+			outs[i] = r.runTool(ctx, call)
+		})
+	}
+	wg.Wait()
+	return outs
+}
+
+// runTool executes one tool call and converts the result into an input item for the next model call.
+func (r *ToolRegistry) runTool(ctx context.Context, call spec.ToolCall) spec.InputUnion {
+	var t *registeredTool
+	if r != nil {
+		r.mu.RLock()
+		t = r.tools[call.Name]
+		r.mu.RUnlock()
+	}
+	if t == nil {
+		toolErr := &spec.ToolError{Code: ToolErrorCodeUnknownTool, Message: fmt.Sprintf("unknown tool %q", call.Name)}
+		return toolOutputInput(call, "", toolErr, nil)
+	}
+
+	exec := &spec.ToolExecution{}
+	start := time.Now()
+	text, err := t.execute(ctx, call, exec)
+	exec.DurationMillis = time.Since(start).Milliseconds()
+	var toolErr *spec.ToolError
+	if err != nil {
+		toolErr, text = specToolError(err), ""
+	}
+	limit := t.Policy.MaxOutputBytes
+	if toolErr != nil {
+		exec.OutputBytes = len(toolErr.Message)
+		if limit > 0 && len(toolErr.Message) > limit {
+			toolErr.Message, exec.Truncated = truncateUTF8(toolErr.Message, limit), true
+		}
+	} else {
+		exec.OutputBytes = len(text)
+		if limit > 0 && len(text) > limit {
+			text, exec.Truncated = truncateUTF8(text, limit), true
+		}
+	}
+	return toolOutputInput(call, text, toolErr, exec)
+}
+
+// execute runs the handler under the tool's policy, retrying failed attempts.
+func (t *registeredTool) execute(ctx context.Context, call spec.ToolCall, exec *spec.ToolExecution) (string, error) {
+	for {
+		exec.Attempts++
+		out, err := t.attempt(ctx, call, exec)
+		if err == nil || exec.Attempts > t.Policy.MaxRetries || ctx.Err() != nil {
+			return out, err
+		}
+		if t.Policy.RetryDelay > 0 {
+			timer := time.NewTimer(t.Policy.RetryDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return out, err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+type toolResult struct {
+	out      string
+	err      error
+	panicked bool
+}
+
+// attempt runs the handler once in its own goroutine, isolating panics and enforcing the timeout.
+func (t *registeredTool) attempt(ctx context.Context, call spec.ToolCall, exec *spec.ToolExecution) (string, error) {
+	select {
+	case t.slots <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+	if t.Policy.Timeout > 0 {
+		attemptCtx, cancel = context.WithTimeout(ctx, t.Policy.Timeout)
+	}
+	defer cancel()
+
+	// The slot is released when the handler returns or is abandoned, whichever comes first.
+	release := sync.OnceFunc(func() { <-t.slots })
+	done := make(chan toolResult, 1)
+	go func() {
+		defer release()
+		defer func() {
+			if p := recover(); p != nil {
+				done <- toolResult{
+					err:      &ToolError{Code: ToolErrorCodePanic, Message: fmt.Sprintf("tool panicked: %v", p)},
+					panicked: true,
+				}
+			}
+		}()
+		out, err := t.Handler(attemptCtx, call)
+		done <- toolResult{out: out, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		exec.Panicked = exec.Panicked || res.panicked
+		if res.err != nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			exec.TimedOut = true
+		}
+		return res.out, res.err
+	case <-attemptCtx.Done():
+		release()
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		exec.TimedOut = true
+		return "", &ToolError{Code: ToolErrorCodeTimeout, Message: "tool timed out after " + t.Policy.Timeout.String()}
+	}
+}
+
+// truncateUTF8 cuts s to at most maxBytes without splitting a rune.
+func truncateUTF8(s string, maxBytes int) string {
+	for maxBytes > 0 && !utf8.RuneStart(s[maxBytes]) {
+		maxBytes--
+	}
+	return s[:maxBytes]
+}
+
+// specToolError converts a handler or runner error into the structured error handed to the model.
+func specToolError(err error) *spec.ToolError {
+	var te *ToolError
+	switch {
+	case errors.As(err, &te):
+		return &spec.ToolError{Code: te.Code, Message: te.Message}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &spec.ToolError{Code: ToolErrorCodeTimeout, Message: err.Error()}
+	case errors.Is(err, context.Canceled):
+		return &spec.ToolError{Code: ToolErrorCodeCanceled, Message: err.Error()}
+	default:
+		return &spec.ToolError{Code: ToolErrorCodeFailed, Message: err.Error()}
+	}
+}
+
+// toolOutputInput builds the tool output item for call. A non-nil toolErr marks the output as an error; the
+// adapters render it for the model.
+func toolOutputInput(
+	call spec.ToolCall,
+	text string,
+	toolErr *spec.ToolError,
+	exec *spec.ToolExecution,
+) spec.InputUnion {
+	out := &spec.ToolOutput{
+		Type:      call.Type,
+		ChoiceID:  call.ChoiceID,
+		ID:        call.ID,
+		Role:      spec.RoleTool,
+		Status:    spec.StatusCompleted,
+		CallID:    call.CallID,
+		Name:      call.Name,
+		IsError:   toolErr != nil,
+		Error:     toolErr,
+		Execution: exec,
+	}
+	if text != "" {
+		out.Contents = []spec.ToolOutputItemUnion{{
+			Kind:     spec.ContentItemKindText,
+			TextItem: &spec.ContentItemText{Text: text},
+		}}
+	}
+	if call.Type == spec.ToolTypeCustom {
+		return spec.InputUnion{Kind: spec.InputKindCustomToolOutput, CustomToolOutput: out}
+	}
+	return spec.InputUnion{Kind: spec.InputKindFunctionToolOutput, FunctionToolOutput: out}
+}
+
+func toolOutputOf(in spec.InputUnion) *spec.ToolOutput {
+	if in.CustomToolOutput != nil {
+		return in.CustomToolOutput
+	}
+	return in.FunctionToolOutput
+}
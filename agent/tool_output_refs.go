@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ExpandToolOutputToolName is the tool through which the model reads a tool output replaced by a reference.
+const ExpandToolOutputToolName = "expand_tool_output"
+
+const (
+	defaultToolOutputRefMinBytes     = 2048
+	defaultToolOutputRefPreviewBytes = 200
+)
+
+// ToolOutputRefs replaces large tool outputs of earlier turns with short references in each model request, and
+// gives the model an ExpandToolOutputToolName tool to read one in full on demand. Outputs of the latest tool batch
+// are always sent in full. Only the requests are affected: the conversation, session and checkpoints keep the full
+// outputs.
+type ToolOutputRefs struct {
+	// MinBytes is the text size from which an output is replaced. Zero means 2048.
+	MinBytes int `json:"minBytes,omitempty"`
+	// PreviewBytes is the length of the output prefix kept in the reference. Zero means 200; negative means none.
+	PreviewBytes int `json:"previewBytes,omitempty"`
+}
+
+// apply returns inputs with large function and custom tool outputs before the trailing tool batch replaced by
+// references. inputs is not modified; it returns the inputs themselves and false if nothing was replaced.
+func (r *ToolOutputRefs) apply(inputs []spec.InputUnion) ([]spec.InputUnion, bool) {
+	if r == nil {
+		return inputs, false
+	}
+	minBytes, preview := r.MinBytes, r.PreviewBytes
+	if minBytes <= 0 {
+		minBytes = defaultToolOutputRefMinBytes
+	}
+	if preview == 0 {
+		preview = defaultToolOutputRefPreviewBytes
+	}
+
+	end := len(inputs)
+	for end > 0 && isToolOutput(inputs[end-1]) {
+		end--
+	}
+	var out []spec.InputUnion
+	for i, in := range inputs[:end] {
+		if in.Kind != spec.InputKindFunctionToolOutput && in.Kind != spec.InputKindCustomToolOutput {
+			continue
+		}
+		to := toolOutputOf(in)
+		text, ok := toolOutputText(to)
+		if !ok || len(text) < minBytes || to.CallID == "" {
+			continue
+		}
+		if out == nil {
+			out = make([]spec.InputUnion, len(inputs))
+			copy(out, inputs)
+		}
+		ref := *to
+		ref.Contents = []spec.ToolOutputItemUnion{{
+			Kind:     spec.ContentItemKindText,
+			TextItem: &spec.ContentItemText{Text: toolOutputRefText(to.CallID, text, preview)},
+		}}
+		if in.Kind == spec.InputKindCustomToolOutput {
+			out[i].CustomToolOutput = &ref
+		} else {
+			out[i].FunctionToolOutput = &ref
+		}
+	}
+	if out == nil {
+		return inputs, false
+	}
+	return out, true
+}
+
+func toolOutputRefText(callID, text string, preview int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Tool output of %d bytes omitted to save context.", len(text))
+	if preview > 0 {
+		p := text
+		if len(p) > preview {
+			p = truncateUTF8(p, preview) + "..."
+		}
+		fmt.Fprintf(&b, " It starts with: %q.", p)
+	}
+	fmt.Fprintf(&b, " Call %s with callID %q to read it in full.]", ExpandToolOutputToolName, callID)
+	return b.String()
+}
+
+// toolOutputText returns the text of an output made of text items only.
+func toolOutputText(to *spec.ToolOutput) (string, bool) {
+	if to == nil || len(to.Contents) == 0 {
+		return "", false
+	}
+	var b strings.Builder
+	for _, c := range to.Contents {
+		if c.Kind != spec.ContentItemKindText || c.TextItem == nil {
+			return "", false
+		}
+		b.WriteString(c.TextItem.Text)
+	}
+	return b.String(), true
+}
+
+func expandToolOutputChoice() spec.ToolChoice {
+	return spec.ToolChoice{
+		Type:        spec.ToolTypeFunction,
+		ID:          ExpandToolOutputToolName,
+		Name:        ExpandToolOutputToolName,
+		Description: "Return the full text of an earlier tool output that was omitted to save context.",
+		Arguments: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"callID": map[string]any{
+					"type":        "string",
+					"description": "The callID given in the omitted output's placeholder.",
+				},
+			},
+			"required":             []any{"callID"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+// expandToolOutput answers an ExpandToolOutputToolName call from the full outputs in conversation.
+func expandToolOutput(call spec.ToolCall, conversation []spec.InputUnion) spec.InputUnion {
+	var args struct {
+		CallID string `json:"callID"`
+	}
+	if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil || args.CallID == "" {
+		return toolOutputInput(call, "", &spec.ToolError{
+			Code:    ToolErrorCodeInvalidArguments,
+			Message: "callID is required",
+		}, nil)
+	}
+	for _, in := range conversation {
+		if in.Kind != spec.InputKindFunctionToolOutput && in.Kind != spec.InputKindCustomToolOutput {
+			continue
+		}
+		if to := toolOutputOf(in); to.CallID == args.CallID {
+			if text, ok := toolOutputText(to); ok {
+				return toolOutputInput(call, text, nil, nil)
+			}
+		}
+	}
+	return toolOutputInput(call, "", &spec.ToolError{
+		Code:    ToolErrorCodeInvalidArguments,
+		Message: fmt.Sprintf("no tool output with callID %q", args.CallID),
+	}, nil)
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestRunToolPolicy(t *testing.T) {
+	t.Parallel()
+
+	// hang blocks handlers that ignore their context until the test is done.
+	hang := make(chan struct{})
+	t.Cleanup(func() { close(hang) })
+	tests := []struct {
+		name     string
+		policy   ExecutionPolicy
+		handler  func(attempt int) ToolHandler
+		want     string
+		wantCode string
+		wantExe  spec.ToolExecution
+	}{
+		{
+			name: "PanicIsIsolated.",
+			handler: func(int) ToolHandler {
+				return func(context.Context, spec.ToolCall) (string, error) { panic("boom") }
+			},
+			want:     "tool panicked: boom",
+			wantCode: ToolErrorCodePanic,
+			wantExe:  spec.ToolExecution{Attempts: 1, Panicked: true, OutputBytes: 19},
+		},
+		{
+			name:   "TimeoutIsRetried.",
+			policy: ExecutionPolicy{Timeout: 10 * time.Millisecond, MaxRetries: 1},
+			handler: func(attempt int) ToolHandler {
+				return func(ctx context.Context, _ spec.ToolCall) (string, error) {
+					if attempt == 1 {
+						<-ctx.Done()
+						return "", ctx.Err()
+					}
+					return "ok", nil
+				}
+			},
+			want:    "ok",
+			wantExe: spec.ToolExecution{Attempts: 2, TimedOut: true, OutputBytes: 2},
+		},
+		{
+			name:   "AbandonedHandlerReleasesSlot.",
+			policy: ExecutionPolicy{Timeout: 10 * time.Millisecond, MaxRetries: 1},
+			handler: func(attempt int) ToolHandler {
+				return func(context.Context, spec.ToolCall) (string, error) {
+					if attempt == 1 {
+						<-hang
+					}
+					return "ok", nil
+				}
+			},
+			want:    "ok",
+			wantExe: spec.ToolExecution{Attempts: 2, TimedOut: true, OutputBytes: 2},
+		},
+		{
+			name:   "OutputIsTruncatedOnRuneBoundary.",
+			policy: ExecutionPolicy{MaxOutputBytes: 4},
+			handler: func(int) ToolHandler {
+				return func(context.Context, spec.ToolCall) (string, error) { return "abcé", nil }
+			},
+			want:    "abc",
+			wantExe: spec.ToolExecution{Attempts: 1, Truncated: true, OutputBytes: 5},
+		},
+		{
+			name: "ErrorWithoutRetries.",
+			handler: func(int) ToolHandler {
+				return func(context.Context, spec.ToolCall) (string, error) { return "", errors.New("nope") }
+			},
+			want:     "nope",
+			wantCode: ToolErrorCodeFailed,
+			wantExe:  spec.ToolExecution{Attempts: 1, OutputBytes: 4},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempt atomic.Int32
+			r, err := NewToolRegistry(Tool{
+				Choice: spec.ToolChoice{Name: "t"},
+				Policy: tc.policy,
+				Handler: func(ctx context.Context, call spec.ToolCall) (string, error) {
+					return tc.handler(int(attempt.Add(1)))(ctx, call)
+				},
+			})
+			if err != nil {
+				t.Fatalf("NewToolRegistry() error = %v.", err)
+			}
+
+			call := spec.ToolCall{Type: spec.ToolTypeFunction, Name: "t", CallID: "c"}
+			out := r.runTool(t.Context(), call).FunctionToolOutput
+			if out.IsError != (tc.wantCode != "") {
+				t.Fatalf("IsError = %v, want = %v.", out.IsError, tc.wantCode != "")
+			}
+			var got, gotCode string
+			if out.IsError {
+				got, gotCode = out.Error.Message, out.Error.Code
+			} else {
+				got = out.Contents[0].TextItem.Text
+			}
+			if got != tc.want || gotCode != tc.wantCode {
+				t.Fatalf("output = %q (%q), want = %q (%q).", got, gotCode, tc.want, tc.wantCode)
+			}
+			exec := *out.Execution
+			exec.DurationMillis = 0
+			if exec != tc.wantExe {
+				t.Fatalf("Execution = %+v, want = %+v.", exec, tc.wantExe)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// DefaultWebSearchCacheTTL is used by NewWebSearchCache when ttl <= 0.
+const DefaultWebSearchCacheTTL = 15 * time.Minute
+
+// WebSearchCache remembers server-side web search calls and their results, keyed by the normalized queries and the
+// domain filters of the web search tool. Fresh results that are missing from a run's history (for example because
+// MaxHistoryItems trimmed them) are replayed ahead of the run's new inputs, so the model can reuse them instead of
+// searching, and paying for the search, again. It is safe for concurrent use and may be shared by several agents.
+type WebSearchCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]webSearchEntry
+	order   []string
+}
+
+type webSearchEntry struct {
+	call   spec.ToolCall
+	output spec.ToolOutput
+}
+
+func NewWebSearchCache(ttl time.Duration) *WebSearchCache {
+	if ttl <= 0 {
+		ttl = DefaultWebSearchCacheTTL
+	}
+	return &WebSearchCache{ttl: ttl, now: time.Now, entries: map[string]webSearchEntry{}}
+}
+
+// record caches the web search call/output pairs in produced and stamps the outputs with cache metadata. Outputs
+// are copied before stamping; the model response is not modified.
+func (c *WebSearchCache) record(choices []spec.ToolChoice, produced []spec.InputUnion) {
+	if c == nil {
+		return
+	}
+	calls := map[string]*spec.ToolCall{}
+	for _, in := range produced {
+		if in.Kind == spec.InputKindWebSearchToolCall && in.WebSearchToolCall != nil {
+			calls[in.WebSearchToolCall.CallID] = in.WebSearchToolCall
+		}
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, in := range produced {
+		if in.Kind != spec.InputKindWebSearchToolOutput || in.WebSearchToolOutput == nil ||
+			in.WebSearchToolOutput.IsError {
+			continue
+		}
+		call := calls[in.WebSearchToolOutput.CallID]
+		if call == nil {
+			continue
+		}
+		key := webSearchCacheKey(choices, call)
+		if key == "" {
+			continue
+		}
+		out := *in.WebSearchToolOutput
+		out.Cache = &spec.ToolOutputCache{
+			Key:             key,
+			StoredAtMillis:  now.UnixMilli(),
+			ExpiresAtMillis: now.Add(c.ttl).UnixMilli(),
+		}
+		produced[i].WebSearchToolOutput = &out
+		if _, ok := c.entries[key]; !ok {
+			c.order = append(c.order, key)
+		}
+		c.entries[key] = webSearchEntry{call: *call, output: out}
+	}
+}
+
+// replay returns fresh cached call/output pairs for searches that do not appear in history, with the outputs marked
+// as cache hits. Nothing is replayed into an empty history, so that a conversation never starts with model items.
+func (c *WebSearchCache) replay(choices []spec.ToolChoice, history []spec.InputUnion) []spec.InputUnion {
+	if c == nil || len(history) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	for _, in := range history {
+		if in.Kind == spec.InputKindWebSearchToolCall && in.WebSearchToolCall != nil {
+			seen[webSearchCacheKey(choices, in.WebSearchToolCall)] = true
+		}
+	}
+
+	now := c.now().UnixMilli()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []spec.InputUnion
+	live := c.order[:0]
+	for _, key := range c.order {
+		e := c.entries[key]
+		if e.output.Cache.ExpiresAtMillis <= now {
+			delete(c.entries, key)
+			continue
+		}
+		live = append(live, key)
+		if seen[key] {
+			continue
+		}
+		call, output := e.call, e.output
+		meta := *output.Cache
+		meta.Hit = true
+		output.Cache = &meta
+		out = append(out,
+			spec.InputUnion{Kind: spec.InputKindWebSearchToolCall, WebSearchToolCall: &call},
+			spec.InputUnion{Kind: spec.InputKindWebSearchToolOutput, WebSearchToolOutput: &output},
+		)
+	}
+	c.order = live
+	return out
+}
+
+// webSearchCacheKey returns the cache key of a web search call, or "" if the call has no search query.
+func webSearchCacheKey(choices []spec.ToolChoice, call *spec.ToolCall) string {
+	var queries []string
+	for _, item := range call.WebSearchToolCallItems {
+		if item.Kind == spec.WebSearchToolCallKindSearch && item.SearchItem != nil {
+			if q := strings.Join(strings.Fields(strings.ToLower(item.SearchItem.Query)), " "); q != "" {
+				queries = append(queries, q)
+			}
+		}
+	}
+	if len(queries) == 0 {
+		return ""
+	}
+
+	var allowed, blocked []string
+	for _, ch := range choices {
+		if ch.Type != spec.ToolTypeWebSearch || ch.WebSearchArguments == nil {
+			continue
+		}
+		if ch.ID == call.ChoiceID || allowed == nil && blocked == nil {
+			allowed = normalizedDomains(ch.WebSearchArguments.AllowedDomains)
+			blocked = normalizedDomains(ch.WebSearchArguments.BlockedDomains)
+		}
+		if ch.ID == call.ChoiceID {
+			break
+		}
+	}
+	return strings.Join(queries, "\n") + "|allow=" + strings.Join(allowed, ",") + "|block=" + strings.Join(blocked, ",")
+}
+
+func normalizedDomains(domains []string) []string {
+	out := make([]string, 0, len(domains))
+	for _, d := range domains {
+		out = append(out, strings.ToLower(strings.TrimSpace(d)))
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
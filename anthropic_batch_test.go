@@ -0,0 +1,143 @@
+package inference
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestAnthropicBatches(t *testing.T) {
+	t.Parallel()
+
+	batchJSON := func(status string, processing, succeeded, errored int) []byte {
+		return fmt.Appendf(nil, `{"id":"msgbatch_1","type":"message_batch","processing_status":%q,`+
+			`"request_counts":{"processing":%d,"succeeded":%d,"errored":%d,"canceled":0,"expired":0},`+
+			`"created_at":"2025-01-01T00:00:00Z","expires_at":"2025-01-02T00:00:00Z"}`,
+			status, processing, succeeded, errored)
+	}
+	var (
+		mu        sync.Mutex
+		submitted string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/messages/batches":
+			mu.Lock()
+			submitted = string(b)
+			mu.Unlock()
+			_, _ = w.Write(batchJSON("in_progress", 2, 0, 0))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/messages/batches/msgbatch_1":
+			_, _ = w.Write(batchJSON("ended", 0, 1, 1))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/messages/batches/msgbatch_1/results":
+			w.Header().Set("Content-Type", "application/x-jsonl")
+			_, _ = w.Write([]byte(`{"custom_id":"a","result":{"type":"succeeded","message":{"id":"msg1",` +
+				`"type":"message","role":"assistant","model":"m","stop_reason":"end_turn",` +
+				`"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":3,"output_tokens":1}}}}` + "\n" +
+				`{"custom_id":"b","result":{"type":"errored","error":{"type":"error",` +
+				`"error":{"type":"invalid_request_error","message":"bad"}}}}` + "\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "a", &AddProviderConfig{
+		SDKType:                  spec.ProviderSDKTypeAnthropic,
+		Origin:                   srv.URL,
+		ChatCompletionPathPrefix: spec.DefaultAnthropicChatCompletionPrefix,
+	})
+
+	newRequest := func(text string) *spec.FetchCompletionRequest {
+		return &spec.FetchCompletionRequest{
+			ModelParam: spec.ModelParam{Name: "m", MaxOutputLength: 10},
+			Inputs:     userInputs(text),
+		}
+	}
+
+	t.Run("ClientSideLimits.", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name string
+			reqs []spec.BatchRequest
+		}{
+			{name: "InvalidCustomID.", reqs: []spec.BatchRequest{{CustomID: "a b", Request: newRequest("hi")}}},
+			{name: "DuplicateCustomID.", reqs: []spec.BatchRequest{
+				{CustomID: "a", Request: newRequest("hi")},
+				{CustomID: "a", Request: newRequest("hi")},
+			}},
+			{name: "EmptyRequest.", reqs: []spec.BatchRequest{{CustomID: "a"}}},
+		}
+		for _, tc := range tests {
+			if _, err := ps.SubmitBatch(t.Context(), "a", tc.reqs); err == nil {
+				t.Fatalf("%s SubmitBatch() succeeded, want an error.", tc.name)
+			}
+		}
+	})
+
+	t.Run("SubmitPollResults.", func(t *testing.T) {
+		t.Parallel()
+
+		status, err := ps.SubmitBatch(t.Context(), "a", []spec.BatchRequest{
+			{CustomID: "a", Request: newRequest("first")},
+			{CustomID: "b", Request: newRequest("second")},
+		})
+		if err != nil {
+			t.Fatalf("SubmitBatch() error = %v.", err)
+		}
+		if status.ID != "msgbatch_1" || status.Status != spec.BatchStatusInProgress || status.Processing != 2 {
+			t.Fatalf("SubmitBatch() = %+v, want an in-progress batch of 2.", status)
+		}
+		mu.Lock()
+		body := submitted
+		mu.Unlock()
+		if got := gjson.Get(body, "requests.1.custom_id").String(); got != "b" {
+			t.Fatalf("submitted custom_id = %q, want b.", got)
+		}
+		if got := gjson.Get(body, "requests.0.params.messages.0.content.0.text").String(); got != "first" {
+			t.Fatalf("submitted params text = %q, want first.", got)
+		}
+
+		status, err = ps.GetBatch(t.Context(), "a", status.ID)
+		if err != nil {
+			t.Fatalf("GetBatch() error = %v.", err)
+		}
+		if status.Status != spec.BatchStatusEnded || status.Succeeded != 1 || status.Errored != 1 {
+			t.Fatalf("GetBatch() = %+v, want an ended batch.", status)
+		}
+
+		results := map[string]spec.BatchResult{}
+		if err := ps.BatchResults(t.Context(), "a", status.ID, func(r spec.BatchResult) error {
+			results[r.CustomID] = r
+			return nil
+		}); err != nil {
+			t.Fatalf("BatchResults() error = %v.", err)
+		}
+		ok := results["a"].Response
+		if ok == nil || len(ok.Outputs) != 1 || ok.Outputs[0].OutputMessage == nil ||
+			ok.Outputs[0].OutputMessage.Contents[0].TextItem.Text != "ok" {
+			t.Fatalf("result a = %+v, want the text output.", results["a"])
+		}
+		if e := results["b"].Error; e == nil || e.Code != spec.ErrorCodeInvalidRequest || e.Message != "bad" {
+			t.Fatalf("result b = %+v, want an invalid request error.", results["b"])
+		}
+	})
+
+	t.Run("UnknownProvider.", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := ps.GetBatch(t.Context(), "missing", "msgbatch_1"); err == nil {
+			t.Fatalf("GetBatch() for an unknown provider succeeded, want an error.")
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package inference
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestAnthropicFiles(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests []string
+		bodies   []string
+		betaHdrs []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		bodies = append(bodies, string(b))
+		betaHdrs = append(betaHdrs, r.Header.Get("Anthropic-Beta"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/files":
+			_, _ = w.Write([]byte(`{"id":"file_1","type":"file","filename":"doc.pdf","mime_type":"application/pdf",` +
+				`"size_bytes":3,"created_at":"2025-01-01T00:00:00Z"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/v1/files/file_1":
+			_, _ = w.Write([]byte(`{"id":"file_1","type":"file_deleted"}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"msg1","type":"message","role":"assistant","model":"m",` +
+				`"stop_reason":"end_turn","content":[{"type":"text","text":"ok"}],` +
+				`"usage":{"input_tokens":1,"output_tokens":1}}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "a", &AddProviderConfig{
+		SDKType:                  spec.ProviderSDKTypeAnthropic,
+		Origin:                   srv.URL,
+		ChatCompletionPathPrefix: spec.DefaultAnthropicChatCompletionPrefix,
+	})
+
+	file, err := ps.UploadFile(t.Context(), "a", &spec.UploadFileRequest{
+		FileName: "doc.pdf",
+		FileMIME: "application/pdf",
+		Reader:   strings.NewReader("pdf"),
+	})
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v.", err)
+	}
+	if file.ID != "file_1" || file.FileName != "doc.pdf" || file.SizeBytes != 3 {
+		t.Fatalf("UploadFile() = %+v, want the stored file metadata.", file)
+	}
+
+	if _, err := ps.FetchCompletion(t.Context(), "a", &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m", MaxOutputLength: 10},
+		Inputs: []spec.InputUnion{{
+			Kind: spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{
+				Role: spec.RoleUser,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindFile,
+					FileItem: &spec.ContentItemFile{FileID: file.ID, FileMIME: "application/pdf"},
+				}},
+			},
+		}},
+	}, nil); err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+
+	if err := ps.DeleteFile(t.Context(), "a", file.ID); err != nil {
+		t.Fatalf("DeleteFile() error = %v.", err)
+	}
+	if err := ps.DeleteFile(t.Context(), "a", ""); err == nil {
+		t.Fatalf("DeleteFile(\"\") error = nil, want an error.")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"POST /v1/files", "POST /v1/messages", "DELETE /v1/files/file_1"}
+	if strings.Join(requests, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("requests = %v, want %v.", requests, want)
+	}
+	for i, h := range betaHdrs {
+		if !strings.Contains(h, "files-api-2025-04-14") {
+			t.Fatalf("%s anthropic-beta = %q, want the files api beta.", requests[i], h)
+		}
+	}
+	if !strings.Contains(bodies[1], `"source":{"file_id":"file_1","type":"file"}`) {
+		t.Fatalf("messages body = %s, want a file document source.", bodies[1])
+	}
+}
+
+func TestUploadFileUnsupported(t *testing.T) {
+	t.Parallel()
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "ollama", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOllama,
+	})
+	if _, err := ps.UploadFile(t.Context(), "ollama", &spec.UploadFileRequest{
+		FileName: "doc.pdf",
+		Reader:   strings.NewReader("pdf"),
+	}); err == nil {
+		t.Fatalf("UploadFile() error = nil, want an unsupported provider error.")
+	}
+}
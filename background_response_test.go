@@ -0,0 +1,107 @@
+package inference
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestBackgroundResponses(t *testing.T) {
+	t.Parallel()
+
+	response := func(status, output string) string {
+		return `{"id":"r1","object":"response","created_at":0,"model":"m","status":"` + status +
+			`","output":[` + output + `]}`
+	}
+	const text = `{"type":"message","id":"m1","role":"assistant","status":"completed",` +
+		`"content":[{"type":"output_text","text":"done","annotations":[]}]}`
+
+	var (
+		polls   atomic.Int32
+		created atomic.Value
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/responses"):
+			b, _ := io.ReadAll(r.Body)
+			created.Store(string(b))
+			_, _ = w.Write([]byte(response("queued", "")))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/responses/r1/cancel"):
+			_, _ = w.Write([]byte(response("cancelled", "")))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/responses/r1"):
+			switch polls.Add(1) {
+			case 1:
+				_, _ = w.Write([]byte(response("queued", "")))
+				return
+			case 2:
+				_, _ = w.Write([]byte(response("in_progress", "")))
+				return
+			}
+			_, _ = w.Write([]byte(response("completed", text)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "o", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOpenAIResponses,
+		Origin:  srv.URL,
+	})
+
+	resp, err := ps.FetchCompletion(t.Context(), "o", &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m", Background: true},
+		Inputs:     userInputs("think hard"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	if resp.Metadata == nil || resp.Metadata.ResponseID != "r1" || resp.Metadata.Status != spec.StatusQueued {
+		t.Fatalf("FetchCompletion() metadata = %+v, want queued response r1.", resp.Metadata)
+	}
+	body, _ := created.Load().(string)
+	if !strings.Contains(body, `"background":true`) || !strings.Contains(body, `"store":true`) {
+		t.Fatalf("request body = %s, want background and store set.", body)
+	}
+
+	var progress []spec.ProgressEvent
+	got, err := ps.WaitResponse(t.Context(), "o", "r1", time.Millisecond, func(e spec.ProgressEvent) {
+		progress = append(progress, e)
+	})
+	if err != nil {
+		t.Fatalf("WaitResponse() error = %v.", err)
+	}
+	if len(progress) != 2 || progress[0].Status != spec.ProgressStatusQueued ||
+		progress[0].ProviderStatus != spec.StatusQueued || progress[1].Status != spec.ProgressStatusInProgress ||
+		progress[1].ProviderStatus != spec.StatusInProgress || progress[1].Provider != "o" {
+		t.Fatalf("WaitResponse() progress = %+v, want the queued and in progress polls.", progress)
+	}
+	if got.Metadata.Status != spec.StatusCompleted || polls.Load() != 3 {
+		t.Fatalf("WaitResponse() status = %q after %d polls, want completed after 3.",
+			got.Metadata.Status, polls.Load())
+	}
+	if len(got.Outputs) != 1 || got.Outputs[0].OutputMessage == nil ||
+		got.Outputs[0].OutputMessage.Contents[0].TextItem.Text != "done" {
+		t.Fatalf("WaitResponse() outputs = %+v, want the final answer.", got.Outputs)
+	}
+
+	cancelled, err := ps.CancelResponse(t.Context(), "o", "r1")
+	if err != nil {
+		t.Fatalf("CancelResponse() error = %v.", err)
+	}
+	if cancelled.Metadata.Status != spec.StatusCancelled {
+		t.Fatalf("CancelResponse() status = %q, want cancelled.", cancelled.Metadata.Status)
+	}
+
+	if _, err := ps.GetResponse(t.Context(), "o", ""); err == nil {
+		t.Fatalf("GetResponse() error = nil, want an error for an empty response id.")
+	}
+}
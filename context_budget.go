@@ -0,0 +1,31 @@
+package inference
+
+import (
+	"fmt"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// promptTokenBudget returns the number of prompt tokens the inputs of a request may use, or 0 for no limit. It is
+// MaxPromptLength, lowered to what the model's context window leaves once MaxOutputLength and the system prompt are
+// reserved, so that prompt and output fit the window together. It fails if the reservation leaves no room for inputs.
+func promptTokenBudget(modelParam *spec.ModelParam, info spec.ModelInfo) (int, error) {
+	budget := modelParam.MaxPromptLength
+	if info.ContextWindow <= 0 {
+		return budget, nil
+	}
+
+	reserved := max(modelParam.MaxOutputLength, 0) + sdkutil.CountHeuristicTokens(modelParam.SystemPrompt)
+	available := info.ContextWindow - reserved
+	if available <= 0 {
+		return 0, fmt.Errorf(
+			"invalid request: model %s has a context window of %d tokens, %d of them reserved for output and system",
+			modelParam.Name, info.ContextWindow, reserved,
+		)
+	}
+	if budget <= 0 || available < budget {
+		return available, nil
+	}
+	return budget, nil
+}
@@ -0,0 +1,96 @@
+package inference
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionReservesOutputBudget(t *testing.T) {
+	t.Parallel()
+
+	userText := func(text string) spec.InputUnion {
+		return spec.InputUnion{
+			Kind: spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{
+				Role: spec.RoleUser,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: text},
+				}},
+			},
+		}
+	}
+	older := userText("alpha beta gamma delta epsilon zeta")
+	newer := userText("one two three four five six seven eight")
+	long := userText("a1 a2 a3 a4 a5 a6 a7 a8 a9 a10 a11 a12 a13 a14 a15")
+
+	tests := []struct {
+		name      string
+		model     spec.ModelName
+		maxOutput int
+		inputs    []spec.InputUnion
+		want      []string
+		notWant   []string
+		wantErr   bool
+	}{
+		{
+			name: "UnknownWindow.", model: "other", maxOutput: 10, inputs: []spec.InputUnion{older, newer},
+			want: []string{"alpha", "eight"},
+		},
+		{
+			name: "FitsWindow.", model: "m", maxOutput: 4, inputs: []spec.InputUnion{older, newer},
+			want: []string{"alpha", "eight"},
+		},
+		{
+			name: "DropsOlderInputs.", model: "m", maxOutput: 10, inputs: []spec.InputUnion{older, newer},
+			want: []string{"one", "eight"}, notWant: []string{"alpha"},
+		},
+		{
+			name: "TruncatesNewestInput.", model: "m", maxOutput: 10, inputs: []spec.InputUnion{long},
+			want: []string{"a1 ", "a10"}, notWant: []string{"a11"},
+		},
+		{name: "NoRoomForPrompt.", model: "m", maxOutput: 20, inputs: []spec.InputUnion{newer}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ps := newTestProviderSet(t, WithModelInfo(spec.ModelInfo{Name: "m", ContextWindow: 20}))
+			addTestProvider(t, ps, "p", &AddProviderConfig{
+				SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+				Origin:                   "http://127.0.0.1:1",
+				ChatCompletionPathPrefix: "/v1/chat/completions",
+			})
+
+			resp, err := ps.FetchCompletion(t.Context(), "p", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: tc.model, MaxOutputLength: tc.maxOutput},
+				Inputs:     tc.inputs,
+			}, &spec.FetchCompletionOptions{DryRun: true})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("FetchCompletion() error = nil, want an error.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			dd, _ := resp.DebugDetails.(map[string]any)
+			req, _ := dd["request"].(map[string]any)
+			body, _ := req["body"].(map[string]any)
+			raw, _ := json.Marshal(body["messages"])
+			for _, s := range tc.want {
+				if !strings.Contains(string(raw), s) {
+					t.Fatalf("messages = %s, want %q.", raw, s)
+				}
+			}
+			for _, s := range tc.notWant {
+				if strings.Contains(string(raw), s) {
+					t.Fatalf("messages = %s, want no %q.", raw, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package inference
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionCustomToolFormat(t *testing.T) {
+	t.Parallel()
+
+	const grammar = `start: "SELECT " /[a-z]+/`
+	tests := []struct {
+		name     string
+		sdkType  spec.ProviderSDKType
+		prefix   string
+		response string
+		// wantTool is the custom tool definition, wantChoice the allowed tool choice entry.
+		wantTool   string
+		wantChoice string
+	}{
+		{
+			name:    "OpenAIChat.",
+			sdkType: spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:  "/v1/chat/completions",
+			response: `{"id":"c1","object":"chat.completion","created":0,"model":"m","choices":[{"index":0,` +
+				`"finish_reason":"tool_calls","message":{"role":"assistant","content":null,"tool_calls":[` +
+				`{"id":"call1","type":"custom","custom":{"name":"sql","input":"SELECT users"}}]}}]}`,
+			wantTool: `{"custom":{"description":"Run SQL.","format":{"grammar":{"definition":` +
+				`"start: \"SELECT \" /[a-z]+/","syntax":"lark"},"type":"grammar"},"name":"sql"},"type":"custom"}`,
+			wantChoice: `{"custom":{"name":"sql"},"type":"custom"}`,
+		},
+		{
+			name:    "OpenAIResponses.",
+			sdkType: spec.ProviderSDKTypeOpenAIResponses,
+			prefix:  "/v1/responses",
+			response: `{"id":"r1","object":"response","created_at":0,"model":"m","status":"completed","output":[` +
+				`{"type":"custom_tool_call","id":"ct1","call_id":"call1","name":"sql","input":"SELECT users"}]}`,
+			wantTool: `{"description":"Run SQL.","format":{"definition":"start: \"SELECT \" /[a-z]+/",` +
+				`"syntax":"lark","type":"grammar"},"name":"sql","type":"custom"}`,
+			wantChoice: `{"name":"sql","type":"custom"}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu   sync.Mutex
+				body map[string]any
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				_ = json.Unmarshal(b, &body)
+				mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			t.Cleanup(srv.Close)
+
+			ps := newTestProviderSet(t)
+			addTestProvider(t, ps, "o", &AddProviderConfig{
+				SDKType:                  tc.sdkType,
+				Origin:                   srv.URL,
+				ChatCompletionPathPrefix: tc.prefix,
+			})
+
+			req := &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m"},
+				Inputs:     userInputs("list users"),
+				ToolChoices: []spec.ToolChoice{{
+					Type: spec.ToolTypeCustom, ID: "q", Name: "sql", Description: "Run SQL.",
+					Format: &spec.CustomToolFormat{
+						Kind:       spec.CustomToolFormatKindGrammar,
+						Syntax:     spec.CustomToolGrammarSyntaxLark,
+						Definition: grammar,
+					},
+				}},
+				ToolPolicy: &spec.ToolPolicy{
+					Mode:         spec.ToolPolicyModeTool,
+					AllowedTools: []spec.AllowedTool{{ToolChoiceID: "q"}},
+				},
+			}
+			resp, err := ps.FetchCompletion(t.Context(), "o", req, nil)
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+
+			mu.Lock()
+			tools, _ := body["tools"].([]any)
+			choice, _ := body["tool_choice"].(map[string]any)
+			mu.Unlock()
+			if nested, ok := choice["allowed_tools"].(map[string]any); ok {
+				// Chat Completions nests the allowed tools.
+				choice = nested
+			}
+			if len(tools) != 1 {
+				t.Fatalf("tools = %v, want one custom tool.", tools)
+			}
+			if got, _ := json.Marshal(tools[0]); string(got) != tc.wantTool {
+				t.Fatalf("tools[0] = %s, want %s.", got, tc.wantTool)
+			}
+			if allowed, _ := choice["tools"].([]any); len(allowed) != 1 {
+				t.Fatalf("tool_choice = %v, want one allowed tool.", choice)
+			} else if got, _ := json.Marshal(allowed[0]); string(got) != tc.wantChoice {
+				t.Fatalf("tool_choice.tools[0] = %s, want %s.", got, tc.wantChoice)
+			}
+
+			if len(resp.Outputs) != 1 || resp.Outputs[0].CustomToolCall == nil {
+				t.Fatalf("Outputs = %+v, want one custom tool call.", resp.Outputs)
+			}
+			if call := resp.Outputs[0].CustomToolCall; call.ChoiceID != "q" || call.Arguments != "SELECT users" {
+				t.Fatalf("CustomToolCall = %+v, want the raw grammar input for choice q.", call)
+			}
+
+			// An invalid grammar is rejected before the request is sent.
+			req.ToolChoices[0].Format = &spec.CustomToolFormat{Kind: spec.CustomToolFormatKindGrammar, Syntax: "ebnf"}
+			if _, err := ps.FetchCompletion(t.Context(), "o", req, nil); err == nil {
+				t.Fatalf("FetchCompletion() with an invalid grammar error = nil, want an error.")
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+//go:build !js
+
+package inference
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ComputeDataContractHash recomputes the SHA-256 hash of the contract files'
+// contents. It is intended for use in tests and development tooling.
+//
+// NOTE: This function assumes it is run in a source checkout of the module
+// where the paths in DataContractFiles exist on disk. It is not suitable for
+// use in production binaries where the Go source tree might not be available.
+func ComputeDataContractHash() (string, error) {
+	h := sha256.New()
+
+	for _, rel := range DataContractFiles {
+		// Paths are relative to module root (where "spec" lives).
+		path := filepath.FromSlash(rel)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read data contract file %q: %w", path, err)
+		}
+
+		if _, err := h.Write(data); err != nil {
+			return "", fmt.Errorf("hash data contract file %q: %w", path, err)
+		}
+
+		// Separator for determinism.
+		if _, err := h.Write([]byte("\n")); err != nil {
+			return "", fmt.Errorf("hash separator: %w", err)
+		}
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ValidateDataContract recomputes the hash and compares it to DataContractHash.
+// Tests in this module should call this to enforce that any schema change in
+// the contract files is accompanied by an explicit update of DataContractHash
+// (and, if breaking, DataContractVersion).
+func ValidateDataContract() error {
+	computed, err := ComputeDataContractHash()
+	if err != nil {
+		return err
+	}
+	if computed != DataContractHash {
+		return fmt.Errorf(
+			"data contract hash mismatch: compiled=%s, computed=%s. If this change is intentional, update DataContractHash in data_contract.go and bump DataContractVersion",
+			DataContractHash,
+			computed,
+		)
+	}
+	return nil
+}
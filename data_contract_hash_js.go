@@ -0,0 +1,19 @@
+//go:build js
+
+package inference
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ComputeDataContractHash is not available on js: there is no source checkout to read the contract files from.
+func ComputeDataContractHash() (string, error) {
+	return "", fmt.Errorf("compute data contract hash: %w on js", errors.ErrUnsupported)
+}
+
+// ValidateDataContract is not available on js, see ComputeDataContractHash.
+func ValidateDataContract() error {
+	_, err := ComputeDataContractHash()
+	return err
+}
@@ -1,15 +1,14 @@
 package inference
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"cmp"
 	"fmt"
-	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 // DataContractVersion is bumped when the *schema* of the contract types changes.
-const DataContractVersion = "v1.0.0"
+const DataContractVersion = "v1.1.0"
 
 // DataContractFiles lists files that define the data contract.
 // Paths are relative to the repo root.
@@ -32,7 +31,7 @@ var DataContractFiles = []string{
 // that they are running against the contract version they were built for.
 //
 // Format: "sha256:<hexstring>".
-const DataContractHash = "sha256:3c3ccc426b5cec85351f05c8b4fa16c9ad3dfb3cdf0beaa2280d2494cb412f1f"
+const DataContractHash = "sha256:bb0d933a942dd341ed52c5c1fa65aeabd90cfa80f7cc52b7a8c02dd04f976203"
 
 // DataContractInfo is the public shape returned to callers who want to
 // validate they are compatible with this version of the contract.
@@ -51,52 +50,99 @@ func GetDataContractInfo() DataContractInfo {
 	}
 }
 
-// ComputeDataContractHash recomputes the SHA-256 hash of the contract files'
-// contents. It is intended for use in tests and development tooling.
-//
-// NOTE: This function assumes it is run in a source checkout of the module
-// where the paths in DataContractFiles exist on disk. It is not suitable for
-// use in production binaries where the Go source tree might not be available.
-func ComputeDataContractHash() (string, error) {
-	h := sha256.New()
-
-	for _, rel := range DataContractFiles {
-		// Paths are relative to module root (where "spec" lives).
-		path := filepath.FromSlash(rel)
+// DataContractCompatibilityRule records the oldest contract version a version can exchange serialized values with.
+type DataContractCompatibilityRule struct {
+	Version              string `json:"version"`
+	MinCompatibleVersion string `json:"minCompatibleVersion"`
+}
 
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return "", fmt.Errorf("read data contract file %q: %w", path, err)
-		}
+// DataContractCompatibility is the minimal-compatibility matrix of all released contract versions. A new row is
+// added with every DataContractVersion bump; its MinCompatibleVersion is raised when the change is breaking for
+// older readers or writers.
+var DataContractCompatibility = []DataContractCompatibilityRule{
+	{Version: "v1.0.0", MinCompatibleVersion: "v1.0.0"},
+	// v1.1.0 only adds optional fields and enum values, which v1.0.0 readers ignore.
+	{Version: "v1.1.0", MinCompatibleVersion: "v1.0.0"},
+}
 
-		if _, err := h.Write(data); err != nil {
-			return "", fmt.Errorf("hash data contract file %q: %w", path, err)
-		}
+// GetDataContractCompatibility returns a copy of DataContractCompatibility.
+func GetDataContractCompatibility() []DataContractCompatibilityRule {
+	return append([]DataContractCompatibilityRule(nil), DataContractCompatibility...)
+}
 
-		// Separator for determinism.
-		if _, err := h.Write([]byte("\n")); err != nil {
-			return "", fmt.Errorf("hash separator: %w", err)
+// CompatibleWith reports whether values serialized by a peer built against contract version and hash (as returned
+// by its GetDataContractInfo) can be exchanged with this build. An empty hash skips the hash check. Versions are
+// compatible when they share the major version and each is at least the other's MinCompatibleVersion; peers newer
+// than this build are only checked against its own rule. The error describes the drift.
+func CompatibleWith(version, hash string) error {
+	if version == DataContractVersion {
+		if hash == "" || hash == DataContractHash {
+			return nil
 		}
+		return fmt.Errorf(
+			"data contract drift: both sides report version %s but hashes differ (local %s, peer %s); "+
+				"the contract changed without a version bump",
+			version, DataContractHash, hash,
+		)
 	}
 
-	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
-}
-
-// ValidateDataContract recomputes the hash and compares it to DataContractHash.
-// Tests in this module should call this to enforce that any schema change in
-// the contract files is accompanied by an explicit update of DataContractHash
-// (and, if breaking, DataContractVersion).
-func ValidateDataContract() error {
-	computed, err := ComputeDataContractHash()
+	local, err := parseContractVersion(DataContractVersion)
+	if err != nil {
+		return err
+	}
+	peer, err := parseContractVersion(version)
 	if err != nil {
 		return err
 	}
-	if computed != DataContractHash {
+	if peer[0] != local[0] {
 		return fmt.Errorf(
-			"data contract hash mismatch: compiled=%s, computed=%s. If this change is intentional, update DataContractHash in data_contract.go and bump DataContractVersion",
-			DataContractHash,
-			computed,
+			"data contract mismatch: peer version %s and local version %s differ in major version",
+			version, DataContractVersion,
 		)
 	}
+	for _, rule := range DataContractCompatibility {
+		minVersion, err := parseContractVersion(rule.MinCompatibleVersion)
+		if err != nil {
+			return err
+		}
+		switch {
+		case rule.Version == DataContractVersion && compareContractVersions(peer, minVersion) < 0:
+			return fmt.Errorf(
+				"data contract mismatch: peer version %s is older than %s, the oldest version local %s accepts",
+				version, rule.MinCompatibleVersion, DataContractVersion,
+			)
+		case rule.Version == version && compareContractVersions(local, minVersion) < 0:
+			return fmt.Errorf(
+				"data contract mismatch: local version %s is older than %s, the oldest version peer %s accepts",
+				DataContractVersion, rule.MinCompatibleVersion, version,
+			)
+		}
+	}
 	return nil
 }
+
+// parseContractVersion parses a "vMAJOR.MINOR.PATCH" contract version.
+func parseContractVersion(v string) ([3]int, error) {
+	var parsed [3]int
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if !strings.HasPrefix(v, "v") || len(parts) != 3 {
+		return parsed, fmt.Errorf("invalid data contract version %q, want vMAJOR.MINOR.PATCH", v)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("invalid data contract version %q, want vMAJOR.MINOR.PATCH", v)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+func compareContractVersions(a, b [3]int) int {
+	for i := range a {
+		if c := cmp.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
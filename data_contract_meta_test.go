@@ -7,3 +7,30 @@ func TestDataContractHash(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestCompatibleWith(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		version string
+		hash    string
+		wantErr bool
+	}{
+		{name: "Identical.", version: DataContractVersion, hash: DataContractHash},
+		{name: "SameVersionNoHash.", version: DataContractVersion},
+		{name: "SameVersionHashDrift.", version: DataContractVersion, hash: "sha256:00", wantErr: true},
+		{name: "ReleasedV1.0.0.", version: "v1.0.0", hash: "sha256:00"},
+		{name: "NewerMinor.", version: "v1.3.0", hash: "sha256:00"},
+		{name: "OtherMajor.", version: "v2.0.0", wantErr: true},
+		{name: "Malformed.", version: "1.0", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if err := CompatibleWith(tc.version, tc.hash); (err != nil) != tc.wantErr {
+				t.Fatalf("CompatibleWith(%q, %q) error = %v, wantErr = %v.", tc.version, tc.hash, err, tc.wantErr)
+			}
+		})
+	}
+}
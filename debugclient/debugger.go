@@ -2,8 +2,10 @@ package debugclient
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"sync/atomic"
 
 	"github.com/flexigpt/inference-go/internal/sdkutil"
 	"github.com/flexigpt/inference-go/spec"
@@ -34,6 +36,35 @@ type DebugConfig struct {
 
 	// LogToSlog logs HTTP request/response details at debug level when true.
 	LogToSlog bool `json:"logToSlog,omitempty"`
+
+	// NonStreamingCurl additionally captures, for streaming requests, a curl
+	// command with streaming disabled (APIRequestDetails.NonStreamingCurlCommand).
+	NonStreamingCurl bool `json:"nonStreamingCurl,omitempty"`
+
+	// Switch, if set, turns capture on and off at runtime, e.g. from an admin endpoint. Nil means always on
+	// (unless Disable is set).
+	Switch *CaptureSwitch `json:"-"`
+}
+
+// CaptureSwitch turns debug capture on and off at runtime for all debuggers configured with it. The zero value is
+// on. It is safe for concurrent use.
+type CaptureSwitch struct {
+	off atomic.Bool
+}
+
+// SetEnabled turns capture on or off. Calls already in flight are not affected.
+func (s *CaptureSwitch) SetEnabled(enabled bool) {
+	s.off.Store(!enabled)
+}
+
+// Enabled reports whether capture is on. A nil switch is always on.
+func (s *CaptureSwitch) Enabled() bool {
+	return s == nil || !s.off.Load()
+}
+
+// disabled reports whether capture is off, statically or via the switch.
+func (c DebugConfig) disabled() bool {
+	return c.Disable || !c.Switch.Enabled()
 }
 
 // HTTPCompletionDebugger implements spec.CompletionDebugger using the HTTP
@@ -89,7 +120,7 @@ func (d *HTTPCompletionDebugger) StartSpan(
 	ctx context.Context,
 	info *spec.CompletionSpanStart,
 ) (context.Context, spec.CompletionSpan) {
-	if d.config.Disable {
+	if d.config.disabled() {
 		return ctx, nil
 	}
 
@@ -111,6 +142,10 @@ type APIRequestDetails struct {
 	Data        any            `json:"data,omitempty"`
 	Timeout     *int           `json:"timeout,omitempty"`
 	CurlCommand *string        `json:"curlCommand,omitempty"`
+
+	// NonStreamingCurlCommand reproduces a streaming request with streaming
+	// disabled. Set only with DebugConfig.NonStreamingCurl.
+	NonStreamingCurlCommand *string `json:"nonStreamingCurlCommand,omitempty"`
 }
 
 // APIResponseDetails describes a single HTTP response captured by the debugger.
@@ -132,6 +167,8 @@ type HTTPDebugState struct {
 	RequestDetails  *APIRequestDetails  `json:"requestDetails,omitempty"`
 	ResponseDetails *APIResponseDetails `json:"responseDetails,omitempty"`
 	ErrorDetails    *APIErrorDetails    `json:"errorDetails,omitempty"`
+	// CancelReason is set when the call ended with its context canceled.
+	CancelReason spec.CancelReason `json:"cancelReason,omitempty"`
 
 	// ProviderResponse holds a scrubbed form of the raw provider SDK response
 	// (e.g. *responses.Response for OpenAI), if available.
@@ -163,6 +200,12 @@ func (s *httpSpan) End(end *spec.CompletionSpanEnd) any {
 		}
 	}
 
+	state.CancelReason = spec.CancelReasonOf(s.ctx)
+	if state.CancelReason == "" && errors.Is(end.Err, context.DeadlineExceeded) {
+		// Adapter timeouts run on a derived context, so the span's stays live.
+		state.CancelReason = spec.CancelReasonTimeout
+	}
+
 	// Compose error message from HTTP-level error + provider error.
 	var msgParts []string
 	if state.ErrorDetails != nil {
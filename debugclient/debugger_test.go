@@ -0,0 +1,53 @@
+package debugclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestSpanEndCancelReason(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		canceled bool
+		err      error
+		want     spec.CancelReason
+	}{
+		{name: "NoError."},
+		{name: "OtherError.", err: errors.New("boom")},
+		{
+			name: "AdapterTimeout.",
+			err:  &spec.CanceledError{Reason: spec.CancelReasonTimeout, Err: context.DeadlineExceeded},
+			want: spec.CancelReasonTimeout,
+		},
+		{
+			name:     "CanceledContext.",
+			canceled: true,
+			err:      context.Canceled,
+			want:     spec.CancelReasonClientDisconnect,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+			if tc.canceled {
+				cancel()
+			}
+			_, span := NewHTTPCompletionDebugger(nil).StartSpan(ctx, &spec.CompletionSpanStart{})
+			state, ok := span.End(&spec.CompletionSpanEnd{Err: tc.err}).(*HTTPDebugState)
+			if !ok {
+				t.Fatalf("End() did not return an *HTTPDebugState.")
+			}
+			if state.CancelReason != tc.want {
+				t.Fatalf("CancelReason = %q, want %q.", state.CancelReason, tc.want)
+			}
+		})
+	}
+}
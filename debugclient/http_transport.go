@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"sort"
 	"strings"
@@ -31,7 +32,7 @@ func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		base = http.DefaultTransport
 	}
 
-	if t.cfg.Disable {
+	if t.cfg.disabled() {
 		// Debugging disabled; just pass through.
 		return base.RoundTrip(req)
 	}
@@ -135,6 +136,10 @@ func captureRequestDetails(req *http.Request, cfg DebugConfig) *APIRequestDetail
 
 	curl := generateCurlCommand(apireq)
 	apireq.CurlCommand = &curl
+	if cfg.NonStreamingCurl && isStreamingRequest(apireq) {
+		nonStreaming := generateCurlCommand(nonStreamingVariant(apireq))
+		apireq.NonStreamingCurlCommand = &nonStreaming
+	}
 
 	return apireq
 }
@@ -175,15 +180,24 @@ func captureResponseDetails(
 
 // generateCurlCommand builds a (mostly) copy-pasteable curl command from
 // APIRequestDetails. It uses the already-redacted Data and Headers.
+// Streaming requests are reproduced as such: curl gets -N so events print as
+// they arrive, an event-stream Accept header unless one is set, and the body
+// an explicit "stream": true.
 func generateCurlCommand(config *APIRequestDetails) string {
 	if config == nil || config.URL == nil || config.Method == nil {
 		return ""
 	}
+	if isStreamingRequest(config) {
+		config = streamingVariant(config)
+	}
 
 	var b strings.Builder
 
 	method := strings.ToUpper(*config.Method)
 	b.WriteString("curl")
+	if isStreamingRequest(config) {
+		b.WriteString(" -N")
+	}
 	if method != "" {
 		b.WriteString(" -X ")
 		b.WriteString(method)
@@ -221,6 +235,69 @@ func generateCurlCommand(config *APIRequestDetails) string {
 	return b.String()
 }
 
+// isStreamingRequest reports whether the request asks for a streamed
+// response, via "stream": true in a JSON body or an event-stream Accept header.
+func isStreamingRequest(config *APIRequestDetails) bool {
+	if config == nil {
+		return false
+	}
+	if body, ok := config.Data.(map[string]any); ok {
+		if stream, ok := body["stream"].(bool); ok {
+			return stream
+		}
+	}
+	_, accept := acceptHeader(config.Headers)
+	return strings.Contains(strings.ToLower(fmt.Sprint(accept)), "text/event-stream")
+}
+
+// streamingVariant returns a copy of config with the stream flag and Accept
+// header a streaming call needs.
+func streamingVariant(config *APIRequestDetails) *APIRequestDetails {
+	out := *config
+	if body, ok := config.Data.(map[string]any); ok {
+		body = maps.Clone(body)
+		body["stream"] = true
+		out.Data = body
+	}
+	if key, _ := acceptHeader(config.Headers); key == "" {
+		out.Headers = maps.Clone(config.Headers)
+		if out.Headers == nil {
+			out.Headers = map[string]any{}
+		}
+		out.Headers["Accept"] = "text/event-stream"
+	}
+	return &out
+}
+
+// nonStreamingVariant returns a copy of a streaming request with streaming
+// disabled, whose single JSON response is easier to inspect.
+func nonStreamingVariant(config *APIRequestDetails) *APIRequestDetails {
+	out := *config
+	if body, ok := config.Data.(map[string]any); ok {
+		body = maps.Clone(body)
+		body["stream"] = false
+		// OpenAI Chat Completions rejects stream_options on non-streaming calls.
+		delete(body, "stream_options")
+		out.Data = body
+	}
+	if key, _ := acceptHeader(config.Headers); key != "" {
+		out.Headers = maps.Clone(config.Headers)
+		out.Headers[key] = "application/json"
+	}
+	return &out
+}
+
+// acceptHeader returns the key and value of the Accept header, matched
+// case-insensitively.
+func acceptHeader(headers map[string]any) (string, any) {
+	for k, v := range headers {
+		if strings.EqualFold(k, "Accept") {
+			return k, v
+		}
+	}
+	return "", nil
+}
+
 // withHTTPDebugState sets up an HTTPDebugState container on the context.
 // All SDK calls that should capture HTTP debug must use this context.
 func withHTTPDebugState(ctx context.Context) context.Context {
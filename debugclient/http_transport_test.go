@@ -593,3 +593,79 @@ func TestGenerateCurlCommand_Basic(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateCurlCommand_Streaming(t *testing.T) {
+	t.Parallel()
+
+	urlStr := "https://api.example.com/v1/chat"
+	method := "POST"
+	tests := []struct {
+		name         string
+		detail       *APIRequestDetails
+		want         []string
+		wantNot      []string
+		nonStreaming []string
+	}{
+		{
+			name: "StreamFlagInBody.",
+			detail: &APIRequestDetails{
+				URL:    &urlStr,
+				Method: &method,
+				Data:   map[string]any{"stream": true, "stream_options": map[string]any{"include_usage": true}},
+			},
+			want:         []string{"curl -N -X POST", "Accept: text/event-stream", `"stream": true`},
+			nonStreaming: []string{`"stream": false`},
+		},
+		{
+			name: "EventStreamAcceptHeader.",
+			detail: &APIRequestDetails{
+				URL:     &urlStr,
+				Method:  &method,
+				Headers: map[string]any{"accept": "text/event-stream"},
+				Data:    map[string]any{"model": "m"},
+			},
+			want:         []string{"curl -N -X POST", "accept: text/event-stream", `"stream": true`},
+			nonStreaming: []string{"accept: application/json", `"stream": false`},
+		},
+		{
+			name: "NotStreaming.",
+			detail: &APIRequestDetails{
+				URL:    &urlStr,
+				Method: &method,
+				Data:   map[string]any{"stream": false},
+			},
+			want:    []string{"curl -X POST", `"stream": false`},
+			wantNot: []string{" -N", "text/event-stream"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			curl := generateCurlCommand(tc.detail)
+			for _, want := range tc.want {
+				if !strings.Contains(curl, want) {
+					t.Fatalf("generateCurlCommand() = %q, want it to contain %q.", curl, want)
+				}
+			}
+			for _, notWant := range tc.wantNot {
+				if strings.Contains(curl, notWant) {
+					t.Fatalf("generateCurlCommand() = %q, want it not to contain %q.", curl, notWant)
+				}
+			}
+			if tc.nonStreaming == nil {
+				return
+			}
+			curl = generateCurlCommand(nonStreamingVariant(tc.detail))
+			for _, want := range tc.nonStreaming {
+				if !strings.Contains(curl, want) {
+					t.Fatalf("non-streaming curl = %q, want it to contain %q.", curl, want)
+				}
+			}
+			if strings.Contains(curl, " -N") || strings.Contains(curl, "stream_options") {
+				t.Fatalf("non-streaming curl = %q, want no -N and no stream_options.", curl)
+			}
+		})
+	}
+}
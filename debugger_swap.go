@@ -0,0 +1,132 @@
+package inference
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// SetDebugClientBuilder replaces the debug client builder at runtime. Every existing provider gets a debugger built
+// by builder for its ProviderParam, without being re-added, and so do providers added later. A nil builder disables
+// debugging. Calls already in flight keep the debugger they started with for their span, but their remaining HTTP
+// traffic goes through the new one.
+func (ps *ProviderSetAPI) SetDebugClientBuilder(builder DebugClientBuilder) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.debugClientBuilder = builder
+	for _, d := range ps.debuggers {
+		d.rebuild(builder)
+	}
+}
+
+// SetLogger replaces the process-wide logger of this SDK at runtime. A nil logger silences it.
+func (ps *ProviderSetAPI) SetLogger(logger *slog.Logger) {
+	ps.mu.Lock()
+	ps.logger = logger
+	ps.mu.Unlock()
+	logutil.SetDefault(logger)
+}
+
+// SetLogLevel drops SDK log records below level at runtime. It can only narrow what the logger's handler already
+// accepts. A nil level removes the filter.
+func (ps *ProviderSetAPI) SetLogLevel(level *slog.Level) {
+	logutil.SetLevel(level)
+}
+
+// swappableDebugger is the spec.CompletionDebugger every provider is built with. It forwards to the debugger
+// currently built by the provider set's DebugClientBuilder, so that debugging can be switched at runtime even though
+// providers build their HTTP client once.
+type swappableDebugger struct {
+	param spec.ProviderParam
+	cur   atomic.Pointer[debuggerSlot]
+}
+
+type debuggerSlot struct {
+	dbg spec.CompletionDebugger
+
+	once   sync.Once
+	client *http.Client
+}
+
+func newSwappableDebugger(param spec.ProviderParam, builder DebugClientBuilder) *swappableDebugger {
+	d := &swappableDebugger{param: param}
+	d.rebuild(builder)
+	return d
+}
+
+func (d *swappableDebugger) rebuild(builder DebugClientBuilder) {
+	slot := &debuggerSlot{}
+	if builder != nil {
+		slot.dbg = builder(d.param)
+	}
+	d.cur.Store(slot)
+}
+
+// HTTPClient returns a copy of base whose transport routes every request through the current debugger's client.
+func (d *swappableDebugger) HTTPClient(base *http.Client) *http.Client {
+	var c http.Client
+	if base != nil {
+		c = *base
+	}
+	c.Transport = &swappableTransport{d: d, base: base}
+	return &c
+}
+
+func (d *swappableDebugger) StartSpan(
+	ctx context.Context,
+	info *spec.CompletionSpanStart,
+) (context.Context, spec.CompletionSpan) {
+	if rd := requestDebuggerFromContext(ctx); rd != nil {
+		return rd.dbg.StartSpan(ctx, info)
+	}
+	slot := d.cur.Load()
+	if slot.dbg == nil {
+		return ctx, nil
+	}
+	return slot.dbg.StartSpan(ctx, info)
+}
+
+type swappableTransport struct {
+	d    *swappableDebugger
+	base *http.Client
+}
+
+func (t *swappableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	slot := t.d.cur.Load()
+	if rd := requestDebuggerFromContext(req.Context()); rd != nil {
+		slot = &rd.debuggerSlot
+	}
+	client := t.base
+	if slot.dbg != nil {
+		slot.once.Do(func() { slot.client = slot.dbg.HTTPClient(t.base) })
+		if slot.client != nil {
+			client = slot.client
+		}
+	}
+	if client == nil || client.Transport == nil {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	return client.Transport.RoundTrip(req)
+}
+
+type requestDebuggerKey struct{}
+
+// requestDebugger is a FetchCompletionOptions.Debugger override, carried in the call's context so that it reaches
+// the provider's HTTP transport. Its client is built on first use.
+type requestDebugger struct {
+	debuggerSlot
+}
+
+func withRequestDebugger(ctx context.Context, dbg spec.CompletionDebugger) context.Context {
+	return context.WithValue(ctx, requestDebuggerKey{}, &requestDebugger{debuggerSlot{dbg: dbg}})
+}
+
+func requestDebuggerFromContext(ctx context.Context) *requestDebugger {
+	rd, _ := ctx.Value(requestDebuggerKey{}).(*requestDebugger)
+	return rd
+}
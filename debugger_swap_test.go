@@ -0,0 +1,136 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type countingDebugger struct {
+	requests atomic.Int32
+	spans    atomic.Int32
+}
+
+func (d *countingDebugger) HTTPClient(base *http.Client) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		d.requests.Add(1)
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+}
+
+func (d *countingDebugger) StartSpan(
+	ctx context.Context,
+	_ *spec.CompletionSpanStart,
+) (context.Context, spec.CompletionSpan) {
+	d.spans.Add(1)
+	return ctx, nil
+}
+
+func TestSetDebugClientBuilder(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"ok"},"done":true}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "ollama", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOllama,
+		Origin:  srv.URL,
+	})
+	fetch := func() {
+		t.Helper()
+		if _, err := ps.FetchCompletion(t.Context(), "ollama", &spec.FetchCompletionRequest{
+			ModelParam: spec.ModelParam{Name: "m"},
+			Inputs:     userInputs("hi"),
+		}, nil); err != nil {
+			t.Fatalf("FetchCompletion() error = %v.", err)
+		}
+	}
+
+	fetch()
+	dbg := &countingDebugger{}
+	ps.SetDebugClientBuilder(func(p spec.ProviderParam) spec.CompletionDebugger { return dbg })
+	fetch()
+	if dbg.requests.Load() != 1 || dbg.spans.Load() != 1 {
+		t.Fatalf("after enabling: requests = %d, spans = %d, want 1 and 1.", dbg.requests.Load(), dbg.spans.Load())
+	}
+	ps.SetDebugClientBuilder(nil)
+	fetch()
+	if dbg.requests.Load() != 1 || dbg.spans.Load() != 1 {
+		t.Fatalf("after disabling: requests = %d, spans = %d, want 1 and 1.", dbg.requests.Load(), dbg.spans.Load())
+	}
+}
+
+func TestFetchCompletionDebuggerOverride(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"ok"},"done":true}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	fleet := &countingDebugger{}
+	ps := newTestProviderSet(t, WithDebugClientBuilder(func(spec.ProviderParam) spec.CompletionDebugger {
+		return fleet
+	}))
+	addTestProvider(t, ps, "ollama", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOllama,
+		Origin:  srv.URL,
+	})
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs:     userInputs("hi"),
+	}
+
+	scoped := &countingDebugger{}
+	opts := &spec.FetchCompletionOptions{Debugger: scoped}
+	if _, err := ps.FetchCompletion(t.Context(), "ollama", req, opts); err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	if _, err := ps.FetchCompletion(t.Context(), "ollama", req, nil); err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	if scoped.requests.Load() != 1 || scoped.spans.Load() != 1 {
+		t.Fatalf("scoped debugger: requests = %d, spans = %d, want 1 and 1.",
+			scoped.requests.Load(), scoped.spans.Load())
+	}
+	if fleet.requests.Load() != 1 || fleet.spans.Load() != 1 {
+		t.Fatalf("default debugger: requests = %d, spans = %d, want 1 and 1.",
+			fleet.requests.Load(), fleet.spans.Load())
+	}
+}
+
+// TestSetLogLevel changes the process-wide logger, so it does not run in parallel.
+func TestSetLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	ps := newTestProviderSet(t)
+	ps.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		ps.SetLogLevel(nil)
+		ps.SetLogger(nil)
+	})
+
+	warn := slog.LevelWarn
+	ps.SetLogLevel(&warn)
+	logutil.Info("hidden")
+	logutil.Warn("shown")
+	ps.SetLogLevel(nil)
+	logutil.Debug("restored")
+
+	got := buf.String()
+	if strings.Contains(got, "hidden") || !strings.Contains(got, "shown") || !strings.Contains(got, "restored") {
+		t.Fatalf("log output = %q, want only records at or above the level while it is set.", got)
+	}
+}
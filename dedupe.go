@@ -0,0 +1,122 @@
+package inference
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// WithDedupeWindow deduplicates accidental double submissions. A non-streaming FetchCompletion call whose provider,
+// request (by RequestHash) and options equal those of a call in flight waits for that call and shares its result;
+// one equal to a call that succeeded less than window ago returns that result without contacting the provider.
+// Failed calls are never reused, so retrying after an error always reaches the provider. Streaming calls, calls with
+// a ProgressHandler and dry runs are not deduplicated.
+func WithDedupeWindow(window time.Duration) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		if window <= 0 {
+			return
+		}
+		ps.dedupe = &dedupeGroup{window: window, calls: map[string]*dedupeCall{}}
+	}
+}
+
+type dedupeGroup struct {
+	mu     sync.Mutex
+	window time.Duration
+	calls  map[string]*dedupeCall
+}
+
+type dedupeCall struct {
+	done       chan struct{}
+	resp       *spec.FetchCompletionResponse
+	err        error
+	finishedAt time.Time
+}
+
+// dedupeKey returns the dedupe key of a call, or false if the call is not deduplicated.
+func (ps *ProviderSetAPI) dedupeKey(
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (string, bool) {
+	if ps.dedupe == nil || req.ModelParam.Stream {
+		return "", false
+	}
+	if opts != nil &&
+		(opts.StreamHandler != nil || opts.ProgressHandler != nil || opts.Debugger != nil || opts.DryRun) {
+		return "", false
+	}
+	hash, err := RequestHash(req)
+	if err != nil {
+		return "", false
+	}
+	optsJSON, err := canonicalJSON(opts)
+	if err != nil {
+		return "", false
+	}
+	return string(provider) + "\x00" + hash + "\x00" + string(optsJSON), true
+}
+
+// do runs fetch once per key: concurrent callers wait for the call in flight, later callers within the window reuse
+// its successful result. A waiting caller returns early when its own ctx is done.
+func (g *dedupeGroup) do(
+	ctx context.Context,
+	key string,
+	fetch func() (*spec.FetchCompletionResponse, error),
+) (*spec.FetchCompletionResponse, error) {
+	now := time.Now()
+	g.mu.Lock()
+	for k, c := range g.calls {
+		if !c.finishedAt.IsZero() && now.Sub(c.finishedAt) >= g.window {
+			delete(g.calls, k)
+		}
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			return shareResponse(c.resp), c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &dedupeCall{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.resp, c.err = fetch()
+
+	g.mu.Lock()
+	if c.err != nil {
+		delete(g.calls, key)
+	} else {
+		c.finishedAt = time.Now()
+	}
+	g.mu.Unlock()
+	close(c.done)
+	// The caller gets a copy too, so its edits to Metadata never reach the result shared with later callers.
+	return shareResponse(c.resp), c.err
+}
+
+// shareResponse returns a copy of resp for another caller. Metadata is copied so callers can tag it; the outputs
+// themselves are shared and must be treated as read-only.
+func shareResponse(resp *spec.FetchCompletionResponse) *spec.FetchCompletionResponse {
+	if resp == nil {
+		return nil
+	}
+	out := *resp
+	out.Outputs = slices.Clone(resp.Outputs)
+	out.Choices = slices.Clone(resp.Choices)
+	if resp.Metadata != nil {
+		md := *resp.Metadata
+		md.EffectiveParams = maps.Clone(md.EffectiveParams)
+		md.SchemaViolations = slices.Clone(md.SchemaViolations)
+		md.Experiments = maps.Clone(md.Experiments)
+		out.Metadata = &md
+	}
+	return &out
+}
@@ -0,0 +1,96 @@
+package inference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionDedupe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		window     time.Duration
+		concurrent bool
+		status     int
+		wantHits   int32
+	}{
+		{name: "Concurrent.", window: time.Minute, concurrent: true, status: http.StatusOK, wantHits: 1},
+		{name: "WithinWindow.", window: time.Minute, status: http.StatusOK, wantHits: 1},
+		{name: "WindowExpired.", window: time.Nanosecond, status: http.StatusOK, wantHits: 2},
+		{name: "FailuresNotReused.", window: time.Minute, status: http.StatusBadRequest, wantHits: 2},
+		{name: "Disabled.", status: http.StatusOK, wantHits: 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var hits atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+				time.Sleep(50 * time.Millisecond)
+				w.Header().Set("Content-Type", "application/json")
+				if tc.status != http.StatusOK {
+					w.WriteHeader(tc.status)
+					_, _ = w.Write([]byte(`{"error":"bad request"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"ok"},"done":true}` + "\n"))
+			}))
+			t.Cleanup(srv.Close)
+
+			ps := newTestProviderSet(t, WithDedupeWindow(tc.window))
+			addTestProvider(t, ps, "ollama", &AddProviderConfig{
+				SDKType: spec.ProviderSDKTypeOllama,
+				Origin:  srv.URL,
+			})
+
+			fetch := func() {
+				resp, err := ps.FetchCompletion(t.Context(), "ollama", &spec.FetchCompletionRequest{
+					ModelParam: spec.ModelParam{Name: "m"},
+					Inputs:     userInputs("hi"),
+				}, nil)
+				if tc.status != http.StatusOK {
+					if err == nil {
+						t.Errorf("FetchCompletion() error = nil, want an error.")
+					}
+					return
+				}
+				if err != nil {
+					t.Errorf("FetchCompletion() error = %v.", err)
+					return
+				}
+				if got := salvagedText(resp.Outputs); got != "ok" {
+					t.Errorf("FetchCompletion() text = %q, want %q.", got, "ok")
+				}
+				// Callers may tag their metadata; a shared result must not see the tags of another caller.
+				if resp.Metadata == nil || resp.Metadata.Experiments != nil {
+					t.Errorf("FetchCompletion() metadata = %+v, want untagged metadata.", resp.Metadata)
+					return
+				}
+				resp.Metadata.Experiments = map[string]string{"e": "v"}
+			}
+
+			if tc.concurrent {
+				var wg sync.WaitGroup
+				for range 2 {
+					wg.Go(fetch)
+				}
+				wg.Wait()
+			} else {
+				fetch()
+				time.Sleep(time.Millisecond)
+				fetch()
+			}
+			if got := hits.Load(); got != tc.wantHits {
+				t.Fatalf("provider requests = %d, want %d.", got, tc.wantHits)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Package events provides a typed, in-process event bus for inference and agent lifecycle events.
+//
+// Producers (ProviderSetAPI via WithEventBus, agent.Config.Events) publish without blocking: every subscriber has its
+// own buffered queue drained by its own goroutine, so a slow UI or telemetry exporter never stalls a model call.
+// Events are delivered to each subscriber in publish order. When a subscriber's queue is full, new events for it are
+// dropped and counted.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const DefaultBufferSize = 256
+
+type Kind string
+
+const (
+	KindRequestStarted Kind = "requestStarted"
+	KindAttempt        Kind = "attempt"
+	KindStreamChunk    Kind = "streamChunk"
+	KindToolCall       Kind = "toolCall"
+	KindToolResult     Kind = "toolResult"
+	// KindRetry is published when a failed attempt is retried on the same provider and model, e.g. for
+	// Pipeline.Retries. Attempt is the failed attempt. Retries made inside a provider SDK are not reported.
+	KindRetry    Kind = "retry"
+	KindFallback Kind = "fallback"
+	KindFinished Kind = "finished"
+	// KindHedge is published when a slow-starting stream is hedged on a fallback route.
+	KindHedge Kind = "hedge"
+	// KindDeprecation is published when a request targets a deprecated model. Err is a *spec.ModelDeprecatedError.
+	KindDeprecation Kind = "deprecation"
+	// KindSchemaViolation is published when a structured output does not match the requested JSON schema. Err is a
+	// *spec.SchemaViolationError.
+	KindSchemaViolation Kind = "schemaViolation"
+)
+
+// Event is a single lifecycle event. Only the fields relevant to Kind are set.
+type Event struct {
+	Kind Kind      `json:"kind"`
+	Time time.Time `json:"time"`
+
+	// RequestID correlates the events of one FetchCompletion call.
+	RequestID string `json:"requestID,omitempty"`
+	// RunID correlates the events of one agent run.
+	RunID string `json:"runID,omitempty"`
+	Agent string `json:"agent,omitempty"`
+
+	Provider spec.ProviderName `json:"provider,omitempty"`
+	Model    spec.ModelName    `json:"model,omitempty"`
+	// Attempt is 1-based and set on attempt, retry and finished events.
+	Attempt int `json:"attempt,omitempty"`
+
+	// FallbackProvider and FallbackModel are the route taken on a fallback event.
+	FallbackProvider spec.ProviderName `json:"fallbackProvider,omitempty"`
+	FallbackModel    spec.ModelName    `json:"fallbackModel,omitempty"`
+
+	Stream     *spec.StreamEvent `json:"stream,omitempty"`
+	ToolCall   *spec.ToolCall    `json:"toolCall,omitempty"`
+	ToolOutput *spec.ToolOutput  `json:"toolOutput,omitempty"`
+	Usage      *spec.Usage       `json:"usage,omitempty"`
+
+	// Duration is set on finished and tool result events.
+	Duration time.Duration `json:"duration,omitempty"`
+	Err      error         `json:"-"`
+}
+
+// NewID returns a random identifier for RequestID.
+func NewID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Handler receives events on a subscriber goroutine.
+type Handler func(Event)
+
+// Bus fans published events out to subscribers. The zero value is not usable; use NewBus. A nil *Bus is a valid
+// no-op publisher.
+type Bus struct {
+	mu   sync.RWMutex
+	subs []*subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+type subscription struct {
+	bus     *Bus
+	kinds   []Kind
+	queue   chan Event
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// Subscription is a registered handler. Close it to stop delivery.
+type Subscription struct {
+	s *subscription
+}
+
+// SubscribeOptions configures a subscription.
+type SubscribeOptions struct {
+	// Kinds filters the delivered events. Empty means all kinds.
+	Kinds []Kind
+	// BufferSize is the queue length. Zero means DefaultBufferSize.
+	BufferSize int
+}
+
+// Subscribe registers handler and starts its delivery goroutine.
+func (b *Bus) Subscribe(handler Handler, opts SubscribeOptions) *Subscription {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	s := &subscription{
+		bus:   b,
+		kinds: slices.Clone(opts.Kinds),
+		queue: make(chan Event, size),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		for ev := range s.queue {
+			deliver(handler, ev)
+		}
+	}()
+
+	b.mu.Lock()
+	b.subs = append(b.subs, s)
+	b.mu.Unlock()
+	return &Subscription{s: s}
+}
+
+// Close unsubscribes and waits until already queued events are delivered.
+func (sub *Subscription) Close() {
+	s := sub.s
+	s.once.Do(func() {
+		b := s.bus
+		b.mu.Lock()
+		b.subs = slices.DeleteFunc(b.subs, func(x *subscription) bool { return x == s })
+		close(s.queue)
+		b.mu.Unlock()
+	})
+	<-s.done
+}
+
+// Dropped returns the number of events dropped because the subscriber queue was full.
+func (sub *Subscription) Dropped() int64 {
+	return sub.s.dropped.Load()
+}
+
+// Publish enqueues ev for every matching subscriber without blocking. A zero Time is set to now.
+func (b *Bus) Publish(ev Event) {
+	if b == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, s := range b.subs {
+		if len(s.kinds) > 0 && !slices.Contains(s.kinds, ev.Kind) {
+			continue
+		}
+		select {
+		case s.queue <- ev:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Active reports whether any subscriber exists, so producers can skip building costly events.
+func (b *Bus) Active() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs) > 0
+}
+
+// deliver calls handler, isolating the bus from handler panics.
+func deliver(handler Handler, ev Event) {
+	defer sdkutil.Recover("events: subscriber panic", "kind", ev.Kind)
+	handler(ev)
+}
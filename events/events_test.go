@@ -0,0 +1,77 @@
+package events
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      SubscribeOptions
+		publish   []Kind
+		want      []Kind
+		wantDrops bool
+	}{
+		{
+			name:    "DeliversAllInOrder.",
+			publish: []Kind{KindRequestStarted, KindAttempt, KindStreamChunk, KindFinished},
+			want:    []Kind{KindRequestStarted, KindAttempt, KindStreamChunk, KindFinished},
+		},
+		{
+			name:    "FiltersByKind.",
+			opts:    SubscribeOptions{Kinds: []Kind{KindFinished}},
+			publish: []Kind{KindRequestStarted, KindFinished},
+			want:    []Kind{KindFinished},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bus := NewBus()
+			var mu sync.Mutex
+			var got []Kind
+			sub := bus.Subscribe(func(ev Event) {
+				mu.Lock()
+				defer mu.Unlock()
+				got = append(got, ev.Kind)
+			}, tc.opts)
+			for _, k := range tc.publish {
+				bus.Publish(Event{Kind: k})
+			}
+			sub.Close()
+			bus.Publish(Event{Kind: KindFinished})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(got) != len(tc.want) {
+				t.Fatalf("got = %v, want = %v.", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("got = %v, want = %v.", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBusSlowSubscriberDoesNotBlock(t *testing.T) {
+	t.Parallel()
+
+	bus := NewBus()
+	release := make(chan struct{})
+	sub := bus.Subscribe(func(Event) { <-release }, SubscribeOptions{BufferSize: 1})
+	for range 10 {
+		bus.Publish(Event{Kind: KindStreamChunk})
+	}
+	close(release)
+	sub.Close()
+	if sub.Dropped() == 0 {
+		t.Fatal("Dropped() = 0, want > 0.")
+	}
+}
@@ -0,0 +1,298 @@
+// Package experiments runs A/B experiments over prompts and models through FetchCompletion.
+//
+// A request is assigned to a variant by hashing the experiment name and an assignment unit (a session or user ID
+// attached with WithUnit), so the same unit always sees the same variant without any stored state. Wrap a fetcher
+// with Registry.Fetcher to apply the assigned variant, tag the response with it and collect per-variant request
+// metrics; report outcomes known only later (ratings, conversions) with Registry.RecordOutcome.
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// Variant is one arm of an experiment. Empty fields keep the value of the incoming request.
+type Variant struct {
+	Name string `json:"name"`
+	// Weight is the relative share of enrolled units assigned to the variant. Zero means 1.
+	Weight float64 `json:"weight,omitempty"`
+
+	Provider     spec.ProviderName `json:"provider,omitempty"`
+	Model        spec.ModelName    `json:"model,omitempty"`
+	SystemPrompt string            `json:"systemPrompt,omitempty"`
+	Temperature  *float64          `json:"temperature,omitempty"`
+
+	// Apply, if set, edits the copied request after the fields above were applied, for changes they do not cover.
+	Apply func(req *spec.FetchCompletionRequest) `json:"-"`
+}
+
+// Experiment is a named set of variants.
+type Experiment struct {
+	Name     string    `json:"name"`
+	Variants []Variant `json:"variants"`
+	// Traffic is the fraction of units enrolled in the experiment, in (0, 1]. Zero means 1. Units not enrolled get
+	// the request unchanged. Raising Traffic enrolls more units without reassigning the enrolled ones.
+	Traffic float64 `json:"traffic,omitempty"`
+}
+
+// MetricStats aggregates the values of one outcome metric.
+type MetricStats struct {
+	Count int64   `json:"count"`
+	Sum   float64 `json:"sum"`
+	Mean  float64 `json:"mean"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+// VariantStats are the aggregated metrics of one variant since the registry was created.
+type VariantStats struct {
+	Experiment    string  `json:"experiment"`
+	Variant       string  `json:"variant"`
+	Requests      int64   `json:"requests"`
+	Failures      int64   `json:"failures"`
+	InputTokens   int64   `json:"inputTokens"`
+	OutputTokens  int64   `json:"outputTokens"`
+	Cost          float64 `json:"cost,omitempty"`
+	LatencyMillis int64   `json:"latencyMillis"`
+	// Outcomes holds the metrics reported with RecordOutcome, by metric name.
+	Outcomes map[string]MetricStats `json:"outcomes,omitempty"`
+}
+
+// Registry holds experiments and their collected metrics. It is safe for concurrent use.
+type Registry struct {
+	experiments map[string]Experiment
+
+	mu    sync.Mutex
+	stats map[string]map[string]*VariantStats
+}
+
+// New validates experiments and returns a Registry.
+func New(experiments ...Experiment) (*Registry, error) {
+	r := &Registry{
+		experiments: make(map[string]Experiment, len(experiments)),
+		stats:       make(map[string]map[string]*VariantStats, len(experiments)),
+	}
+	for _, e := range experiments {
+		if strings.TrimSpace(e.Name) == "" {
+			return nil, errors.New("experiments: experiment name is required")
+		}
+		if _, ok := r.experiments[e.Name]; ok {
+			return nil, fmt.Errorf("experiments: duplicate experiment %q", e.Name)
+		}
+		if len(e.Variants) == 0 {
+			return nil, fmt.Errorf("experiments: experiment %q has no variants", e.Name)
+		}
+		if e.Traffic < 0 || e.Traffic > 1 {
+			return nil, fmt.Errorf("experiments: experiment %q traffic must be in (0, 1]", e.Name)
+		}
+		stats := make(map[string]*VariantStats, len(e.Variants))
+		for _, v := range e.Variants {
+			if strings.TrimSpace(v.Name) == "" {
+				return nil, fmt.Errorf("experiments: experiment %q has a variant without name", e.Name)
+			}
+			if _, ok := stats[v.Name]; ok {
+				return nil, fmt.Errorf("experiments: experiment %q has duplicate variant %q", e.Name, v.Name)
+			}
+			if v.Weight < 0 {
+				return nil, fmt.Errorf("experiments: variant %q of experiment %q has a negative weight", v.Name, e.Name)
+			}
+			stats[v.Name] = &VariantStats{Experiment: e.Name, Variant: v.Name}
+		}
+		e.Variants = slices.Clone(e.Variants)
+		r.experiments[e.Name] = e
+		r.stats[e.Name] = stats
+	}
+	return r, nil
+}
+
+// Assign returns the variant of experiment for unit. It returns false if the experiment is unknown, unit is empty
+// or the unit is not enrolled.
+func (r *Registry) Assign(experiment, unit string) (Variant, bool) {
+	e, ok := r.experiments[experiment]
+	if !ok || unit == "" {
+		return Variant{}, false
+	}
+	if e.Traffic > 0 && sdkutil.HashBucket(experiment, "traffic", unit) >= e.Traffic {
+		return Variant{}, false
+	}
+
+	total := 0.0
+	for _, v := range e.Variants {
+		total += variantWeight(v)
+	}
+	x := sdkutil.HashBucket(experiment, "variant", unit) * total
+	for _, v := range e.Variants {
+		if x -= variantWeight(v); x < 0 {
+			return v, true
+		}
+	}
+	return e.Variants[len(e.Variants)-1], true
+}
+
+// RecordOutcome adds value to metric (e.g. "thumbsUp", "converted") of the variant unit is assigned to. It returns
+// false if the unit is not enrolled in the experiment.
+func (r *Registry) RecordOutcome(experiment, unit, metric string, value float64) bool {
+	v, ok := r.Assign(experiment, unit)
+	if !ok {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats[experiment][v.Name]
+	if s.Outcomes == nil {
+		s.Outcomes = map[string]MetricStats{}
+	}
+	m, seen := s.Outcomes[metric]
+	m.Count++
+	m.Sum += value
+	m.Mean = m.Sum / float64(m.Count)
+	if !seen {
+		m.Min, m.Max = value, value
+	} else {
+		m.Min, m.Max = min(m.Min, value), max(m.Max, value)
+	}
+	s.Outcomes[metric] = m
+	return true
+}
+
+// Stats returns the metrics of every variant of experiment, in variant order. It returns nil for an unknown
+// experiment.
+func (r *Registry) Stats(experiment string) []VariantStats {
+	e, ok := r.experiments[experiment]
+	if !ok {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]VariantStats, 0, len(e.Variants))
+	for _, v := range e.Variants {
+		s := *r.stats[experiment][v.Name]
+		s.Outcomes = maps.Clone(s.Outcomes)
+		out = append(out, s)
+	}
+	return out
+}
+
+func (r *Registry) recordRequest(
+	experiment, variant string,
+	resp *spec.FetchCompletionResponse,
+	err error,
+	latency time.Duration,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats[experiment][variant]
+	s.Requests++
+	s.LatencyMillis += latency.Milliseconds()
+	if err != nil {
+		s.Failures++
+	}
+	if resp != nil && resp.Usage != nil {
+		s.InputTokens += resp.Usage.InputTokensTotal
+		s.OutputTokens += resp.Usage.OutputTokens
+		if resp.Usage.Cost != nil {
+			s.Cost += *resp.Usage.Cost
+		}
+	}
+}
+
+type unitKey struct{}
+
+// WithUnit attaches the assignment unit (e.g. a session or user ID) used by Registry.Fetcher.
+func WithUnit(ctx context.Context, unit string) context.Context {
+	return context.WithValue(ctx, unitKey{}, unit)
+}
+
+// UnitFromContext returns the unit attached with WithUnit.
+func UnitFromContext(ctx context.Context) (string, bool) {
+	unit, ok := ctx.Value(unitKey{}).(string)
+	return unit, ok && unit != ""
+}
+
+// CompletionFetcher is the subset of inference.ProviderSetAPI wrapped by Fetcher.
+type CompletionFetcher interface {
+	FetchCompletion(
+		ctx context.Context,
+		provider spec.ProviderName,
+		fetchCompletionRequest *spec.FetchCompletionRequest,
+		opts *spec.FetchCompletionOptions,
+	) (*spec.FetchCompletionResponse, error)
+}
+
+// Fetcher wraps next so that every call whose context carries an enrolled unit is sent with the assigned variant of
+// experiment applied. The response is tagged in ResponseMetadata.Experiments and the call is counted in the
+// variant's stats. Other calls pass through unchanged.
+func (r *Registry) Fetcher(next CompletionFetcher, experiment string) CompletionFetcher {
+	return &fetcher{next: next, registry: r, experiment: experiment}
+}
+
+type fetcher struct {
+	next       CompletionFetcher
+	registry   *Registry
+	experiment string
+}
+
+func (f *fetcher) FetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	unit, _ := UnitFromContext(ctx)
+	v, ok := f.registry.Assign(f.experiment, unit)
+	if !ok || req == nil {
+		return f.next.FetchCompletion(ctx, provider, req, opts)
+	}
+
+	reqCopy := *req
+	if v.Provider != "" {
+		provider = v.Provider
+	}
+	if v.Model != "" {
+		reqCopy.ModelParam.Name = v.Model
+	}
+	if v.SystemPrompt != "" {
+		reqCopy.ModelParam.SystemPrompt = v.SystemPrompt
+	}
+	if v.Temperature != nil {
+		t := *v.Temperature
+		reqCopy.ModelParam.Temperature = &t
+	}
+	if v.Apply != nil {
+		v.Apply(&reqCopy)
+	}
+
+	start := time.Now()
+	resp, err := f.next.FetchCompletion(ctx, provider, &reqCopy, opts)
+	f.registry.recordRequest(f.experiment, v.Name, resp, err, time.Since(start))
+	if resp != nil {
+		// Tag a copy: the metadata may be shared with other callers, e.g. by a deduplicated call.
+		var md spec.ResponseMetadata
+		if resp.Metadata != nil {
+			md = *resp.Metadata
+		}
+		md.Experiments = maps.Clone(md.Experiments)
+		if md.Experiments == nil {
+			md.Experiments = map[string]string{}
+		}
+		md.Experiments[f.experiment] = v.Name
+		resp.Metadata = &md
+	}
+	return resp, err
+}
+
+func variantWeight(v Variant) float64 {
+	if v.Weight == 0 {
+		return 1
+	}
+	return v.Weight
+}
@@ -0,0 +1,175 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type recordingFetcher struct {
+	provider spec.ProviderName
+	req      *spec.FetchCompletionRequest
+	metadata *spec.ResponseMetadata
+	err      error
+}
+
+func (f *recordingFetcher) FetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	f.provider, f.req = provider, req
+	cost := 0.5
+	return &spec.FetchCompletionResponse{
+		Usage:    &spec.Usage{InputTokensTotal: 10, OutputTokens: 4, Cost: &cost},
+		Metadata: f.metadata,
+	}, f.err
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		exps []Experiment
+	}{
+		{name: "NoName.", exps: []Experiment{{Variants: []Variant{{Name: "a"}}}}},
+		{name: "NoVariants.", exps: []Experiment{{Name: "e"}}},
+		{name: "DuplicateVariant.", exps: []Experiment{{Name: "e", Variants: []Variant{{Name: "a"}, {Name: "a"}}}}},
+		{name: "BadTraffic.", exps: []Experiment{{Name: "e", Variants: []Variant{{Name: "a"}}, Traffic: 2}}},
+		{name: "DuplicateExperiment.", exps: []Experiment{
+			{Name: "e", Variants: []Variant{{Name: "a"}}},
+			{Name: "e", Variants: []Variant{{Name: "a"}}},
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := New(tc.exps...); err == nil {
+				t.Fatalf("New() error = nil, want an error.")
+			}
+		})
+	}
+}
+
+func TestAssign(t *testing.T) {
+	t.Parallel()
+
+	r, err := New(
+		Experiment{Name: "split", Variants: []Variant{{Name: "a", Weight: 3}, {Name: "b"}}},
+		Experiment{Name: "partial", Variants: []Variant{{Name: "a"}}, Traffic: 0.2},
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+
+	counts := map[string]int{}
+	enrolled := 0
+	for i := range 4000 {
+		unit := fmt.Sprintf("user-%d", i)
+		v, ok := r.Assign("split", unit)
+		if !ok {
+			t.Fatalf("Assign(split, %s) not enrolled, want enrolled.", unit)
+		}
+		if again, _ := r.Assign("split", unit); again.Name != v.Name {
+			t.Fatalf("Assign(split, %s) = %s then %s, want a stable variant.", unit, v.Name, again.Name)
+		}
+		counts[v.Name]++
+		if _, ok := r.Assign("partial", unit); ok {
+			enrolled++
+		}
+	}
+	if share := float64(counts["a"]) / 4000; share < 0.7 || share > 0.8 {
+		t.Fatalf("variant a share = %.2f, want about 0.75.", share)
+	}
+	if share := float64(enrolled) / 4000; share < 0.17 || share > 0.23 {
+		t.Fatalf("enrolled share = %.2f, want about 0.2.", share)
+	}
+	if _, ok := r.Assign("split", ""); ok {
+		t.Fatalf("Assign() with an empty unit enrolled, want not enrolled.")
+	}
+	if _, ok := r.Assign("unknown", "u"); ok {
+		t.Fatalf("Assign() of an unknown experiment enrolled, want not enrolled.")
+	}
+}
+
+func TestFetcher(t *testing.T) {
+	t.Parallel()
+
+	temp := 0.2
+	r, err := New(Experiment{Name: "prompt", Variants: []Variant{{
+		Name:         "concise",
+		Provider:     "other",
+		Model:        "m2",
+		SystemPrompt: "Be concise.",
+		Temperature:  &temp,
+	}}})
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+	next := &recordingFetcher{}
+	f := r.Fetcher(next, "prompt")
+	req := &spec.FetchCompletionRequest{ModelParam: spec.ModelParam{Name: "m1", SystemPrompt: "Be helpful."}}
+
+	t.Run("NoUnit.", func(t *testing.T) {
+		resp, err := f.FetchCompletion(t.Context(), "p", req, nil)
+		if err != nil {
+			t.Fatalf("FetchCompletion() error = %v.", err)
+		}
+		if next.req != req || next.provider != "p" || resp.Metadata != nil {
+			t.Fatalf("request without unit was changed or tagged.")
+		}
+	})
+
+	t.Run("Enrolled.", func(t *testing.T) {
+		ctx := WithUnit(t.Context(), "user-1")
+		resp, err := f.FetchCompletion(ctx, "p", req, nil)
+		if err != nil {
+			t.Fatalf("FetchCompletion() error = %v.", err)
+		}
+		got := next.req.ModelParam
+		if next.provider != "other" || got.Name != "m2" || got.SystemPrompt != "Be concise." ||
+			got.Temperature == nil || *got.Temperature != 0.2 {
+			t.Fatalf("sent %s %+v, want the variant applied.", next.provider, got)
+		}
+		if req.ModelParam.Name != "m1" || req.ModelParam.SystemPrompt != "Be helpful." {
+			t.Fatalf("caller request was modified: %+v.", req.ModelParam)
+		}
+		if resp.Metadata == nil || resp.Metadata.Experiments["prompt"] != "concise" {
+			t.Fatalf("Metadata = %+v, want the variant tag.", resp.Metadata)
+		}
+
+		shared := &spec.ResponseMetadata{Experiments: map[string]string{"other": "a"}}
+		next.metadata = shared
+		resp, err = f.FetchCompletion(ctx, "p", req, nil)
+		next.metadata = nil
+		if err != nil {
+			t.Fatalf("FetchCompletion() error = %v.", err)
+		}
+		if resp.Metadata == shared || len(shared.Experiments) != 1 || resp.Metadata.Experiments["other"] != "a" {
+			t.Fatalf("Metadata = %+v (shared %+v), want a tagged copy.", resp.Metadata, shared)
+		}
+
+		next.err = errors.New("boom")
+		_, _ = f.FetchCompletion(ctx, "p", req, nil)
+		r.RecordOutcome("prompt", "user-1", "rating", 4)
+		r.RecordOutcome("prompt", "user-1", "rating", 2)
+
+		stats := r.Stats("prompt")
+		if len(stats) != 1 {
+			t.Fatalf("Stats() = %+v, want one variant.", stats)
+		}
+		s := stats[0]
+		if s.Requests != 3 || s.Failures != 1 || s.InputTokens != 30 || s.OutputTokens != 12 || s.Cost != 1.5 {
+			t.Fatalf("Stats() = %+v, want three requests with one failure.", s)
+		}
+		want := MetricStats{Count: 2, Sum: 6, Mean: 3, Min: 2, Max: 4}
+		if got := s.Outcomes["rating"]; got != want {
+			t.Fatalf("rating = %+v, want %+v.", got, want)
+		}
+	})
+}
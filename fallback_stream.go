@@ -0,0 +1,94 @@
+package inference
+
+import (
+	"sync"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// fallbackStream sits between the adapters and the caller's stream handler while a call has fallbacks. It numbers
+// events across routes, holds back the usage and error events of a route that may still be replaced, and tracks
+// whether any content was streamed, which rules out a transparent restart.
+type fallbackStream struct {
+	mu         sync.Mutex
+	next       spec.StreamHandler
+	seq        int64
+	delivered  bool
+	handlerErr error
+	hold       bool
+	held       []spec.StreamEvent
+}
+
+func (s *fallbackStream) handle(event spec.StreamEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	isContent := isContentEvent(event)
+	if !isContent && s.hold && !s.delivered {
+		s.held = append(s.held, event)
+		return nil
+	}
+	if isContent {
+		s.delivered = true
+	}
+	return s.deliverLocked(event)
+}
+
+func (s *fallbackStream) deliverLocked(event spec.StreamEvent) error {
+	s.seq++
+	event.SequenceNumber = s.seq
+	err := s.next(event)
+	if err != nil && s.handlerErr == nil {
+		s.handlerErr = err
+	}
+	return err
+}
+
+func (s *fallbackStream) setHold(hold bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hold = hold
+	s.held = nil
+}
+
+// canRestart reports whether the failed route streamed nothing and the handler did not fail.
+func (s *fallbackStream) canRestart() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.delivered && s.handlerErr == nil
+}
+
+// switchRoute announces the restart on the next route.
+func (s *fallbackStream) switchRoute(from, to spec.FallbackRoute, reason error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.held = nil
+	return s.deliverLocked(spec.StreamEvent{
+		Kind:     spec.StreamContentKindProviderSwitch,
+		Provider: to.Provider,
+		Model:    to.Model,
+		ProviderSwitch: &spec.StreamProviderSwitchChunk{
+			FromProvider: from.Provider,
+			FromModel:    from.Model,
+			ToProvider:   to.Provider,
+			ToModel:      to.Model,
+			Reason:       reason.Error(),
+		},
+	})
+}
+
+// releaseHeld delivers the events that were held back for a fallback that did not happen.
+func (s *fallbackStream) releaseHeld() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range s.held {
+		if s.deliverLocked(event) != nil {
+			break
+		}
+	}
+	s.held = nil
+}
+
+// isContentEvent reports events that carry output, as opposed to usage and error events.
+func isContentEvent(event spec.StreamEvent) bool {
+	return event.Kind != spec.StreamContentKindError && event.Kind != spec.StreamContentKindUsage
+}
@@ -0,0 +1,68 @@
+package inference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionStreamFallback(t *testing.T) {
+	t.Parallel()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"error":{"message":"bad request"}}`, http.StatusBadRequest)
+	}))
+	t.Cleanup(failing.Close)
+	streaming := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(
+			`data: {"id":"c","object":"chat.completion.chunk","created":0,"model":"b-model",` +
+				`"choices":[{"index":0,"delta":{"role":"assistant","content":"hi"},"finish_reason":null}]}` + "\n\n" +
+				`data: {"id":"c","object":"chat.completion.chunk","created":0,"model":"b-model",` +
+				`"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}` + "\n\n" +
+				"data: [DONE]\n\n",
+		))
+	}))
+	t.Cleanup(streaming.Close)
+
+	ps := newTestProviderSet(t)
+	for name, origin := range map[spec.ProviderName]string{"a": failing.URL, "b": streaming.URL} {
+		addTestProvider(t, ps, name, &AddProviderConfig{
+			SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+			Origin:                   origin,
+			ChatCompletionPathPrefix: spec.DefaultOpenAIChatCompletionsPrefix,
+		})
+	}
+
+	var got []spec.StreamEvent
+	resp, err := ps.FetchCompletion(t.Context(), "a", &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "a-model", Stream: true},
+		Inputs:     userInputs("hi"),
+	}, &spec.FetchCompletionOptions{
+		StreamHandler: func(event spec.StreamEvent) error {
+			got = append(got, event)
+			return nil
+		},
+		Fallbacks: []spec.FallbackRoute{{Provider: "b", Model: "b-model"}},
+	})
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v, events %+v.", err, got)
+	}
+	if resp.Metadata == nil || resp.Metadata.Provider != "b" {
+		t.Fatalf("Metadata = %+v, want provider b.", resp.Metadata)
+	}
+	if len(got) < 2 || got[0].Kind != spec.StreamContentKindProviderSwitch ||
+		got[0].ProviderSwitch.ToModel != "b-model" {
+		t.Fatalf("events = %+v, want a provider switch first.", got)
+	}
+	for i, ev := range got {
+		if ev.Kind == spec.StreamContentKindError {
+			t.Fatalf("event %d is an error event, want it held back.", i)
+		}
+		if ev.SequenceNumber != int64(i+1) {
+			t.Fatalf("event %d SequenceNumber = %d, want = %d.", i, ev.SequenceNumber, i+1)
+		}
+	}
+}
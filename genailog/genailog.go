@@ -0,0 +1,341 @@
+// Package genailog emits OpenTelemetry gen_ai event log records for completion calls.
+//
+// It follows the OpenTelemetry semantic conventions for generative AI events: every request yields one
+// gen_ai.{system,user,assistant,tool}.message record per input and one gen_ai.choice record for the response. Records
+// are handed to an Exporter; OTLPHTTPExporter ships them to any OTLP/HTTP (JSON) logs endpoint without an OpenTelemetry
+// SDK dependency. This is independent of the CompletionDebugger span hooks.
+//
+// Message content and tool arguments are not recorded unless Options.CaptureContent is set, matching the
+// OpenTelemetry default; captured content can be passed through Options.Redact.
+//
+// Emitter.Fetcher exports in the background through a bounded queue, so a slow or hung collector never delays a
+// completion call; Close the Emitter to flush the queue.
+package genailog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	EventSystemMessage    = "gen_ai.system.message"
+	EventUserMessage      = "gen_ai.user.message"
+	EventAssistantMessage = "gen_ai.assistant.message"
+	EventToolMessage      = "gen_ai.tool.message"
+	EventChoice           = "gen_ai.choice"
+
+	AttrSystem        = "gen_ai.system"
+	AttrRequestModel  = "gen_ai.request.model"
+	AttrOperationName = "gen_ai.operation.name"
+
+	// SeverityInfo is the OpenTelemetry severity number of INFO.
+	SeverityInfo = 9
+
+	DefaultQueueSize     = 256
+	DefaultExportTimeout = 10 * time.Second
+)
+
+// ErrQueueFull is reported to Options.OnExportError when the records of a call are dropped because the export queue
+// is full or the Emitter is closed.
+var ErrQueueFull = errors.New("genailog: export queue full")
+
+// Record is an OpenTelemetry log record. Body and attribute values are JSON-like values: string, bool, int64,
+// float64, []any and map[string]any.
+type Record struct {
+	Timestamp      time.Time      `json:"timestamp"`
+	EventName      string         `json:"eventName"`
+	SeverityNumber int            `json:"severityNumber"`
+	Attributes     map[string]any `json:"attributes,omitempty"`
+	Body           map[string]any `json:"body,omitempty"`
+}
+
+// Exporter ships records. Implementations must be safe for concurrent use.
+type Exporter interface {
+	Export(ctx context.Context, records []Record) error
+}
+
+// CompletionFetcher is the subset of inference.ProviderSetAPI wrapped by Fetcher.
+type CompletionFetcher interface {
+	FetchCompletion(
+		ctx context.Context,
+		provider spec.ProviderName,
+		fetchCompletionRequest *spec.FetchCompletionRequest,
+		opts *spec.FetchCompletionOptions,
+	) (*spec.FetchCompletionResponse, error)
+}
+
+type Options struct {
+	// CaptureContent records message text, tool arguments and tool results.
+	CaptureContent bool
+	// Redact, if set, is applied to every captured string.
+	Redact func(string) string
+	// System maps a provider to the gen_ai.system value (e.g. "openai", "anthropic"). Defaults to the provider name.
+	System func(provider spec.ProviderName) string
+	// OnExportError is called when the exporter fails. Export errors never fail the completion call.
+	OnExportError func(error)
+	// QueueSize bounds the calls waiting to be exported by Fetcher; further calls are dropped. Zero means
+	// DefaultQueueSize.
+	QueueSize int
+	// ExportTimeout bounds each export. Zero means DefaultExportTimeout.
+	ExportTimeout time.Duration
+}
+
+// Emitter builds and exports gen_ai records.
+type Emitter struct {
+	exporter Exporter
+	opts     Options
+
+	queue  chan []Record
+	done   chan struct{}
+	mu     sync.RWMutex
+	closed bool
+}
+
+func New(exporter Exporter, opts Options) (*Emitter, error) {
+	if exporter == nil {
+		return nil, errors.New("genailog: nil exporter")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.ExportTimeout <= 0 {
+		opts.ExportTimeout = DefaultExportTimeout
+	}
+	e := &Emitter{
+		exporter: exporter,
+		opts:     opts,
+		queue:    make(chan []Record, opts.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return e, nil
+}
+
+// Close stops accepting records from Fetcher, exports the queued ones and waits for completion or ctx.
+func (e *Emitter) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Emitter) run() {
+	defer close(e.done)
+	for records := range e.queue {
+		e.export(context.Background(), records)
+	}
+}
+
+// enqueue queues records for background export without blocking.
+func (e *Emitter) enqueue(records []Record) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if !e.closed {
+		select {
+		case e.queue <- records:
+			return
+		default:
+		}
+	}
+	if e.opts.OnExportError != nil {
+		e.opts.OnExportError(ErrQueueFull)
+	}
+}
+
+func (e *Emitter) export(ctx context.Context, records []Record) {
+	ctx, cancel := context.WithTimeout(ctx, e.opts.ExportTimeout)
+	defer cancel()
+	if err := e.exporter.Export(ctx, records); err != nil && e.opts.OnExportError != nil {
+		e.opts.OnExportError(err)
+	}
+}
+
+// Records returns the records for one completion call. resp may be nil for failed calls.
+func (e *Emitter) Records(
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	resp *spec.FetchCompletionResponse,
+) []Record {
+	now := time.Now()
+	attrs := map[string]any{
+		AttrSystem:        e.system(provider),
+		AttrRequestModel:  string(req.ModelParam.Name),
+		AttrOperationName: "chat",
+	}
+	rec := func(name string, body map[string]any) Record {
+		return Record{Timestamp: now, EventName: name, SeverityNumber: SeverityInfo, Attributes: attrs, Body: body}
+	}
+
+	var out []Record
+	if req.ModelParam.SystemPrompt != "" {
+		out = append(out, rec(EventSystemMessage, e.withContent(map[string]any{}, req.ModelParam.SystemPrompt)))
+	}
+	for _, in := range req.Inputs {
+		switch in.Kind {
+		case spec.InputKindInputMessage:
+			if in.InputMessage != nil {
+				out = append(out, rec(EventUserMessage, e.withContent(map[string]any{}, messageText(in.InputMessage))))
+			}
+		case spec.InputKindOutputMessage:
+			if in.OutputMessage != nil {
+				body := e.withContent(map[string]any{}, messageText(in.OutputMessage))
+				out = append(out, rec(EventAssistantMessage, body))
+			}
+		case spec.InputKindFunctionToolCall, spec.InputKindCustomToolCall:
+			call := in.FunctionToolCall
+			if call == nil {
+				call = in.CustomToolCall
+			}
+			if call != nil {
+				body := map[string]any{"tool_calls": []any{e.toolCall(call)}}
+				out = append(out, rec(EventAssistantMessage, body))
+			}
+		case spec.InputKindFunctionToolOutput, spec.InputKindCustomToolOutput:
+			res := in.FunctionToolOutput
+			if res == nil {
+				res = in.CustomToolOutput
+			}
+			if res != nil {
+				body := e.withContent(map[string]any{"id": res.CallID}, toolOutputText(res))
+				out = append(out, rec(EventToolMessage, body))
+			}
+		default:
+			// Reasoning and web search items have no gen_ai event.
+		}
+	}
+
+	if resp != nil {
+		out = append(out, rec(EventChoice, e.choice(resp)))
+	}
+	return out
+}
+
+// Emit builds the records of one completion call and exports them synchronously, bounded by Options.ExportTimeout.
+func (e *Emitter) Emit(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	resp *spec.FetchCompletionResponse,
+) {
+	e.export(ctx, e.Records(provider, req, resp))
+}
+
+// Fetcher wraps next so that every completion call is queued for background export after it returns.
+func (e *Emitter) Fetcher(next CompletionFetcher) CompletionFetcher {
+	return &fetcher{next: next, emitter: e}
+}
+
+type fetcher struct {
+	next    CompletionFetcher
+	emitter *Emitter
+}
+
+func (f *fetcher) FetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	resp, err := f.next.FetchCompletion(ctx, provider, req, opts)
+	if req != nil {
+		f.emitter.enqueue(f.emitter.Records(provider, req, resp))
+	}
+	return resp, err
+}
+
+func (e *Emitter) system(provider spec.ProviderName) string {
+	if e.opts.System != nil {
+		return e.opts.System(provider)
+	}
+	return string(provider)
+}
+
+func (e *Emitter) withContent(body map[string]any, content string) map[string]any {
+	if e.opts.CaptureContent && content != "" {
+		body["content"] = e.redact(content)
+	}
+	return body
+}
+
+func (e *Emitter) redact(s string) string {
+	if e.opts.Redact != nil {
+		return e.opts.Redact(s)
+	}
+	return s
+}
+
+func (e *Emitter) toolCall(call *spec.ToolCall) map[string]any {
+	fn := map[string]any{"name": call.Name}
+	if e.opts.CaptureContent && call.Arguments != "" {
+		fn["arguments"] = e.redact(call.Arguments)
+	}
+	return map[string]any{"id": call.CallID, "type": "function", "function": fn}
+}
+
+func (e *Emitter) choice(resp *spec.FetchCompletionResponse) map[string]any {
+	var text strings.Builder
+	var calls []any
+	for _, o := range resp.Outputs {
+		switch o.Kind {
+		case spec.OutputKindOutputMessage:
+			if o.OutputMessage != nil {
+				text.WriteString(messageText(o.OutputMessage))
+			}
+		case spec.OutputKindFunctionToolCall:
+			if o.FunctionToolCall != nil {
+				calls = append(calls, e.toolCall(o.FunctionToolCall))
+			}
+		case spec.OutputKindCustomToolCall:
+			if o.CustomToolCall != nil {
+				calls = append(calls, e.toolCall(o.CustomToolCall))
+			}
+		default:
+		}
+	}
+	message := e.withContent(map[string]any{}, text.String())
+	finish := "stop"
+	if len(calls) > 0 {
+		message["tool_calls"] = calls
+		finish = "tool_calls"
+	}
+	if resp.Error != nil {
+		finish = "error"
+	}
+	return map[string]any{"index": int64(0), "finish_reason": finish, "message": message}
+}
+
+func messageText(m *spec.InputOutputContent) string {
+	var b strings.Builder
+	for _, c := range m.Contents {
+		if c.Kind == spec.ContentItemKindText && c.TextItem != nil {
+			b.WriteString(c.TextItem.Text)
+		}
+	}
+	return b.String()
+}
+
+func toolOutputText(o *spec.ToolOutput) string {
+	var b strings.Builder
+	if o.Error != nil {
+		b.WriteString(o.Error.Message)
+	}
+	for _, c := range o.Contents {
+		if c.Kind == spec.ContentItemKindText && c.TextItem != nil {
+			b.WriteString(c.TextItem.Text)
+		}
+	}
+	return b.String()
+}
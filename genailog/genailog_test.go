@@ -0,0 +1,146 @@
+package genailog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestRecords(t *testing.T) {
+	t.Parallel()
+
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "gpt-x", SystemPrompt: "be nice"},
+		Inputs: []spec.InputUnion{{
+			Kind: spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{
+				Role: spec.RoleUser,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: "my secret"},
+				}},
+			},
+		}},
+	}
+	resp := &spec.FetchCompletionResponse{Outputs: []spec.OutputUnion{{
+		Kind: spec.OutputKindFunctionToolCall,
+		FunctionToolCall: &spec.ToolCall{
+			Type: spec.ToolTypeFunction, CallID: "c1", Name: "lookup", Arguments: `{"q":"secret"}`,
+		},
+	}}}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "ContentNotCapturedByDefault.",
+			want:    []string{EventSystemMessage, EventUserMessage, EventChoice, "tool_calls", "lookup"},
+			notWant: []string{"secret", "be nice"},
+		},
+		{
+			name: "CapturedContentIsRedacted.",
+			opts: Options{
+				CaptureContent: true,
+				Redact:         func(s string) string { return strings.ReplaceAll(s, "secret", "***") },
+			},
+			want:    []string{"my ***", `{\"q\":\"***\"}`, "be nice"},
+			notWant: []string{"secret"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = io.ReadAll(r.Body)
+			}))
+			defer srv.Close()
+
+			e, err := New(&OTLPHTTPExporter{Endpoint: srv.URL}, tc.opts)
+			if err != nil {
+				t.Fatalf("New() error = %v.", err)
+			}
+			var exportErr error
+			e.opts.OnExportError = func(err error) { exportErr = err }
+			e.Emit(t.Context(), "openai", req, resp)
+			if exportErr != nil {
+				t.Fatalf("Export() error = %v.", exportErr)
+			}
+			if !json.Valid(got) {
+				t.Fatalf("OTLP body is not valid JSON: %s.", got)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(string(got), w) {
+					t.Fatalf("OTLP body does not contain %q: %s.", w, got)
+				}
+			}
+			for _, w := range tc.notWant {
+				if strings.Contains(string(got), w) {
+					t.Fatalf("OTLP body contains %q: %s.", w, got)
+				}
+			}
+		})
+	}
+}
+
+type stubFetcher struct{}
+
+func (stubFetcher) FetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	return &spec.FetchCompletionResponse{}, nil
+}
+
+type exporterFunc func(ctx context.Context, records []Record) error
+
+func (f exporterFunc) Export(ctx context.Context, records []Record) error { return f(ctx, records) }
+
+func TestFetcherExportsInBackground(t *testing.T) {
+	t.Parallel()
+
+	errCh := make(chan error, 1)
+	// A hung collector: the export only ends when its deadline does.
+	hung := exporterFunc(func(ctx context.Context, _ []Record) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	e, err := New(hung, Options{ExportTimeout: 200 * time.Millisecond, OnExportError: func(err error) { errCh <- err }})
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+
+	req := &spec.FetchCompletionRequest{ModelParam: spec.ModelParam{Name: "m"}}
+	if _, err := e.Fetcher(stubFetcher{}).FetchCompletion(t.Context(), "p", req, nil); err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	select {
+	case err := <-errCh:
+		t.Fatalf("export finished (%v) before FetchCompletion returned, want it in the background.", err)
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	if err := e.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v.", err)
+	}
+	if err := <-errCh; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("OnExportError error = %v, want a deadline error.", err)
+	}
+}
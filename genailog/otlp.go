@@ -0,0 +1,126 @@
+package genailog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+const scopeName = "github.com/flexigpt/inference-go/genailog"
+
+// OTLPHTTPExporter posts records as OTLP/HTTP JSON to a logs endpoint, e.g. "http://localhost:4318/v1/logs".
+type OTLPHTTPExporter struct {
+	Endpoint string
+	// Headers are added to every request, e.g. for authentication.
+	Headers map[string]string
+	// ResourceAttributes describe the emitting service, e.g. {"service.name": "my-app"}.
+	ResourceAttributes map[string]any
+	// Client defaults to a client with a DefaultExportTimeout timeout.
+	Client *http.Client
+}
+
+var defaultOTLPClient = &http.Client{Timeout: DefaultExportTimeout}
+
+func (x *OTLPHTTPExporter) Export(ctx context.Context, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if x.Endpoint == "" {
+		return errors.New("genailog: empty OTLP endpoint")
+	}
+	body, err := json.Marshal(otlpLogsRequest(records, x.ResourceAttributes))
+	if err != nil {
+		return fmt.Errorf("genailog: encode OTLP request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("genailog: build OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range x.Headers {
+		req.Header.Set(k, v)
+	}
+	client := x.Client
+	if client == nil {
+		client = defaultOTLPClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("genailog: export: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("genailog: export: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// otlpLogsRequest encodes records in the OTLP ExportLogsServiceRequest JSON shape.
+func otlpLogsRequest(records []Record, resource map[string]any) map[string]any {
+	logRecords := make([]any, 0, len(records))
+	for _, r := range records {
+		ts := strconv.FormatInt(r.Timestamp.UnixNano(), 10)
+		lr := map[string]any{
+			"timeUnixNano":         ts,
+			"observedTimeUnixNano": ts,
+			"severityNumber":       r.SeverityNumber,
+			"severityText":         "INFO",
+			"eventName":            r.EventName,
+			"attributes":           otlpKeyValues(r.Attributes),
+		}
+		if r.Body != nil {
+			lr["body"] = otlpAnyValue(r.Body)
+		}
+		logRecords = append(logRecords, lr)
+	}
+	return map[string]any{
+		"resourceLogs": []any{map[string]any{
+			"resource": map[string]any{"attributes": otlpKeyValues(resource)},
+			"scopeLogs": []any{map[string]any{
+				"scope":      map[string]any{"name": scopeName},
+				"logRecords": logRecords,
+			}},
+		}},
+	}
+}
+
+func otlpKeyValues(m map[string]any) []any {
+	out := make([]any, 0, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		out = append(out, map[string]any{"key": k, "value": otlpAnyValue(m[k])})
+	}
+	return out
+}
+
+func otlpAnyValue(v any) map[string]any {
+	switch t := v.(type) {
+	case string:
+		return map[string]any{"stringValue": t}
+	case bool:
+		return map[string]any{"boolValue": t}
+	case int:
+		return map[string]any{"intValue": strconv.Itoa(t)}
+	case int64:
+		return map[string]any{"intValue": strconv.FormatInt(t, 10)}
+	case float64:
+		return map[string]any{"doubleValue": t}
+	case []any:
+		values := make([]any, 0, len(t))
+		for _, e := range t {
+			values = append(values, otlpAnyValue(e))
+		}
+		return map[string]any{"arrayValue": map[string]any{"values": values}}
+	case map[string]any:
+		return map[string]any{"kvlistValue": map[string]any{"values": otlpKeyValues(t)}}
+	default:
+		return map[string]any{"stringValue": fmt.Sprint(t)}
+	}
+}
@@ -5,10 +5,10 @@ go 1.25
 require (
 	github.com/anthropics/anthropic-sdk-go v1.20.0
 	github.com/openai/openai-go/v3 v3.17.0
+	github.com/tidwall/gjson v1.18.0
 )
 
 require (
-	github.com/tidwall/gjson v1.18.0 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.1 // indirect
 	github.com/tidwall/sjson v1.2.5 // indirect
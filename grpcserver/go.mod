@@ -0,0 +1,22 @@
+module github.com/flexigpt/inference-go/grpcserver
+
+go 1.25
+
+require (
+	github.com/flexigpt/inference-go v0.1.0
+	google.golang.org/grpc v1.76.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	github.com/anthropics/anthropic-sdk-go v1.20.0 // indirect
+	github.com/openai/openai-go/v3 v3.17.0 // indirect
+	github.com/tidwall/gjson v1.18.0 // indirect
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.1 // indirect
+	github.com/tidwall/sjson v1.2.5 // indirect
+	golang.org/x/net v0.42.0 // indirect
+	golang.org/x/sys v0.34.0 // indirect
+	golang.org/x/text v0.27.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250804133106-a7a43d27e69b // indirect
+)
@@ -0,0 +1,770 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        v5.28.3
+// source: inference.proto
+
+package inferencepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AddProviderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	ConfigJson    string                 `protobuf:"bytes,2,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddProviderRequest) Reset() {
+	*x = AddProviderRequest{}
+	mi := &file_inference_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddProviderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddProviderRequest) ProtoMessage() {}
+
+func (x *AddProviderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddProviderRequest.ProtoReflect.Descriptor instead.
+func (*AddProviderRequest) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AddProviderRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *AddProviderRequest) GetConfigJson() string {
+	if x != nil {
+		return x.ConfigJson
+	}
+	return ""
+}
+
+type AddProviderResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// provider_json is the spec.ProviderParam of the new provider.
+	ProviderJson  string `protobuf:"bytes,1,opt,name=provider_json,json=providerJson,proto3" json:"provider_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddProviderResponse) Reset() {
+	*x = AddProviderResponse{}
+	mi := &file_inference_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddProviderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddProviderResponse) ProtoMessage() {}
+
+func (x *AddProviderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddProviderResponse.ProtoReflect.Descriptor instead.
+func (*AddProviderResponse) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddProviderResponse) GetProviderJson() string {
+	if x != nil {
+		return x.ProviderJson
+	}
+	return ""
+}
+
+type DeleteProviderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProviderRequest) Reset() {
+	*x = DeleteProviderRequest{}
+	mi := &file_inference_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProviderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProviderRequest) ProtoMessage() {}
+
+func (x *DeleteProviderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProviderRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProviderRequest) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DeleteProviderRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+type DeleteProviderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProviderResponse) Reset() {
+	*x = DeleteProviderResponse{}
+	mi := &file_inference_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProviderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProviderResponse) ProtoMessage() {}
+
+func (x *DeleteProviderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProviderResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProviderResponse) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{3}
+}
+
+type SetProviderAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	ApiKey        string                 `protobuf:"bytes,2,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetProviderAPIKeyRequest) Reset() {
+	*x = SetProviderAPIKeyRequest{}
+	mi := &file_inference_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetProviderAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetProviderAPIKeyRequest) ProtoMessage() {}
+
+func (x *SetProviderAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetProviderAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*SetProviderAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SetProviderAPIKeyRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *SetProviderAPIKeyRequest) GetApiKey() string {
+	if x != nil {
+		return x.ApiKey
+	}
+	return ""
+}
+
+type SetProviderAPIKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetProviderAPIKeyResponse) Reset() {
+	*x = SetProviderAPIKeyResponse{}
+	mi := &file_inference_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetProviderAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetProviderAPIKeyResponse) ProtoMessage() {}
+
+func (x *SetProviderAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetProviderAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*SetProviderAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{5}
+}
+
+type ListProvidersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProvidersRequest) Reset() {
+	*x = ListProvidersRequest{}
+	mi := &file_inference_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProvidersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProvidersRequest) ProtoMessage() {}
+
+func (x *ListProvidersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProvidersRequest.ProtoReflect.Descriptor instead.
+func (*ListProvidersRequest) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{6}
+}
+
+type ListProvidersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// providers_json is a JSON array of inference.ProviderStatus, sorted by provider name.
+	ProvidersJson string `protobuf:"bytes,1,opt,name=providers_json,json=providersJson,proto3" json:"providers_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProvidersResponse) Reset() {
+	*x = ListProvidersResponse{}
+	mi := &file_inference_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProvidersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProvidersResponse) ProtoMessage() {}
+
+func (x *ListProvidersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProvidersResponse.ProtoReflect.Descriptor instead.
+func (*ListProvidersResponse) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListProvidersResponse) GetProvidersJson() string {
+	if x != nil {
+		return x.ProvidersJson
+	}
+	return ""
+}
+
+type FetchCompletionRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Provider string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// request_json is a spec.FetchCompletionRequest.
+	RequestJson string `protobuf:"bytes,2,opt,name=request_json,json=requestJson,proto3" json:"request_json,omitempty"`
+	// options_json is a spec.FetchCompletionOptions. It may be empty.
+	OptionsJson   string `protobuf:"bytes,3,opt,name=options_json,json=optionsJson,proto3" json:"options_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchCompletionRequest) Reset() {
+	*x = FetchCompletionRequest{}
+	mi := &file_inference_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchCompletionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchCompletionRequest) ProtoMessage() {}
+
+func (x *FetchCompletionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchCompletionRequest.ProtoReflect.Descriptor instead.
+func (*FetchCompletionRequest) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FetchCompletionRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *FetchCompletionRequest) GetRequestJson() string {
+	if x != nil {
+		return x.RequestJson
+	}
+	return ""
+}
+
+func (x *FetchCompletionRequest) GetOptionsJson() string {
+	if x != nil {
+		return x.OptionsJson
+	}
+	return ""
+}
+
+type FetchCompletionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// response_json is a spec.FetchCompletionResponse.
+	ResponseJson  string `protobuf:"bytes,1,opt,name=response_json,json=responseJson,proto3" json:"response_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchCompletionResponse) Reset() {
+	*x = FetchCompletionResponse{}
+	mi := &file_inference_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchCompletionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchCompletionResponse) ProtoMessage() {}
+
+func (x *FetchCompletionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchCompletionResponse.ProtoReflect.Descriptor instead.
+func (*FetchCompletionResponse) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FetchCompletionResponse) GetResponseJson() string {
+	if x != nil {
+		return x.ResponseJson
+	}
+	return ""
+}
+
+type StreamFetchCompletionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*StreamFetchCompletionResponse_EventJson
+	//	*StreamFetchCompletionResponse_ResponseJson
+	Payload       isStreamFetchCompletionResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamFetchCompletionResponse) Reset() {
+	*x = StreamFetchCompletionResponse{}
+	mi := &file_inference_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamFetchCompletionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFetchCompletionResponse) ProtoMessage() {}
+
+func (x *StreamFetchCompletionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFetchCompletionResponse.ProtoReflect.Descriptor instead.
+func (*StreamFetchCompletionResponse) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StreamFetchCompletionResponse) GetPayload() isStreamFetchCompletionResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *StreamFetchCompletionResponse) GetEventJson() string {
+	if x != nil {
+		if x, ok := x.Payload.(*StreamFetchCompletionResponse_EventJson); ok {
+			return x.EventJson
+		}
+	}
+	return ""
+}
+
+func (x *StreamFetchCompletionResponse) GetResponseJson() string {
+	if x != nil {
+		if x, ok := x.Payload.(*StreamFetchCompletionResponse_ResponseJson); ok {
+			return x.ResponseJson
+		}
+	}
+	return ""
+}
+
+type isStreamFetchCompletionResponse_Payload interface {
+	isStreamFetchCompletionResponse_Payload()
+}
+
+type StreamFetchCompletionResponse_EventJson struct {
+	// event_json is a spec.StreamEvent.
+	EventJson string `protobuf:"bytes,1,opt,name=event_json,json=eventJson,proto3,oneof"`
+}
+
+type StreamFetchCompletionResponse_ResponseJson struct {
+	// response_json is the final spec.FetchCompletionResponse. It is the last message of the stream.
+	ResponseJson string `protobuf:"bytes,2,opt,name=response_json,json=responseJson,proto3,oneof"`
+}
+
+func (*StreamFetchCompletionResponse_EventJson) isStreamFetchCompletionResponse_Payload() {}
+
+func (*StreamFetchCompletionResponse_ResponseJson) isStreamFetchCompletionResponse_Payload() {}
+
+type GetDataContractInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDataContractInfoRequest) Reset() {
+	*x = GetDataContractInfoRequest{}
+	mi := &file_inference_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataContractInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataContractInfoRequest) ProtoMessage() {}
+
+func (x *GetDataContractInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataContractInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetDataContractInfoRequest) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{11}
+}
+
+type GetDataContractInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Hash          string                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDataContractInfoResponse) Reset() {
+	*x = GetDataContractInfoResponse{}
+	mi := &file_inference_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataContractInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataContractInfoResponse) ProtoMessage() {}
+
+func (x *GetDataContractInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inference_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataContractInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetDataContractInfoResponse) Descriptor() ([]byte, []int) {
+	return file_inference_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetDataContractInfoResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *GetDataContractInfoResponse) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+var File_inference_proto protoreflect.FileDescriptor
+
+const file_inference_proto_rawDesc = "" +
+	"\n" +
+	"\x0finference.proto\x12\x15flexigpt.inference.v1\"Q\n" +
+	"\x12AddProviderRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x1f\n" +
+	"\vconfig_json\x18\x02 \x01(\tR\n" +
+	"configJson\":\n" +
+	"\x13AddProviderResponse\x12#\n" +
+	"\rprovider_json\x18\x01 \x01(\tR\fproviderJson\"3\n" +
+	"\x15DeleteProviderRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\"\x18\n" +
+	"\x16DeleteProviderResponse\"O\n" +
+	"\x18SetProviderAPIKeyRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x17\n" +
+	"\aapi_key\x18\x02 \x01(\tR\x06apiKey\"\x1b\n" +
+	"\x19SetProviderAPIKeyResponse\"\x16\n" +
+	"\x14ListProvidersRequest\">\n" +
+	"\x15ListProvidersResponse\x12%\n" +
+	"\x0eproviders_json\x18\x01 \x01(\tR\rprovidersJson\"z\n" +
+	"\x16FetchCompletionRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12!\n" +
+	"\frequest_json\x18\x02 \x01(\tR\vrequestJson\x12!\n" +
+	"\foptions_json\x18\x03 \x01(\tR\voptionsJson\">\n" +
+	"\x17FetchCompletionResponse\x12#\n" +
+	"\rresponse_json\x18\x01 \x01(\tR\fresponseJson\"r\n" +
+	"\x1dStreamFetchCompletionResponse\x12\x1f\n" +
+	"\n" +
+	"event_json\x18\x01 \x01(\tH\x00R\teventJson\x12%\n" +
+	"\rresponse_json\x18\x02 \x01(\tH\x00R\fresponseJsonB\t\n" +
+	"\apayload\"\x1c\n" +
+	"\x1aGetDataContractInfoRequest\"K\n" +
+	"\x1bGetDataContractInfoResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash2\xbb\x06\n" +
+	"\x10InferenceService\x12d\n" +
+	"\vAddProvider\x12).flexigpt.inference.v1.AddProviderRequest\x1a*.flexigpt.inference.v1.AddProviderResponse\x12m\n" +
+	"\x0eDeleteProvider\x12,.flexigpt.inference.v1.DeleteProviderRequest\x1a-.flexigpt.inference.v1.DeleteProviderResponse\x12v\n" +
+	"\x11SetProviderAPIKey\x12/.flexigpt.inference.v1.SetProviderAPIKeyRequest\x1a0.flexigpt.inference.v1.SetProviderAPIKeyResponse\x12j\n" +
+	"\rListProviders\x12+.flexigpt.inference.v1.ListProvidersRequest\x1a,.flexigpt.inference.v1.ListProvidersResponse\x12p\n" +
+	"\x0fFetchCompletion\x12-.flexigpt.inference.v1.FetchCompletionRequest\x1a..flexigpt.inference.v1.FetchCompletionResponse\x12~\n" +
+	"\x15StreamFetchCompletion\x12-.flexigpt.inference.v1.FetchCompletionRequest\x1a4.flexigpt.inference.v1.StreamFetchCompletionResponse0\x01\x12|\n" +
+	"\x13GetDataContractInfo\x121.flexigpt.inference.v1.GetDataContractInfoRequest\x1a2.flexigpt.inference.v1.GetDataContractInfoResponseB9Z7github.com/flexigpt/inference-go/grpcserver/inferencepbb\x06proto3"
+
+var (
+	file_inference_proto_rawDescOnce sync.Once
+	file_inference_proto_rawDescData []byte
+)
+
+func file_inference_proto_rawDescGZIP() []byte {
+	file_inference_proto_rawDescOnce.Do(func() {
+		file_inference_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_inference_proto_rawDesc), len(file_inference_proto_rawDesc)))
+	})
+	return file_inference_proto_rawDescData
+}
+
+var file_inference_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_inference_proto_goTypes = []any{
+	(*AddProviderRequest)(nil),            // 0: flexigpt.inference.v1.AddProviderRequest
+	(*AddProviderResponse)(nil),           // 1: flexigpt.inference.v1.AddProviderResponse
+	(*DeleteProviderRequest)(nil),         // 2: flexigpt.inference.v1.DeleteProviderRequest
+	(*DeleteProviderResponse)(nil),        // 3: flexigpt.inference.v1.DeleteProviderResponse
+	(*SetProviderAPIKeyRequest)(nil),      // 4: flexigpt.inference.v1.SetProviderAPIKeyRequest
+	(*SetProviderAPIKeyResponse)(nil),     // 5: flexigpt.inference.v1.SetProviderAPIKeyResponse
+	(*ListProvidersRequest)(nil),          // 6: flexigpt.inference.v1.ListProvidersRequest
+	(*ListProvidersResponse)(nil),         // 7: flexigpt.inference.v1.ListProvidersResponse
+	(*FetchCompletionRequest)(nil),        // 8: flexigpt.inference.v1.FetchCompletionRequest
+	(*FetchCompletionResponse)(nil),       // 9: flexigpt.inference.v1.FetchCompletionResponse
+	(*StreamFetchCompletionResponse)(nil), // 10: flexigpt.inference.v1.StreamFetchCompletionResponse
+	(*GetDataContractInfoRequest)(nil),    // 11: flexigpt.inference.v1.GetDataContractInfoRequest
+	(*GetDataContractInfoResponse)(nil),   // 12: flexigpt.inference.v1.GetDataContractInfoResponse
+}
+var file_inference_proto_depIdxs = []int32{
+	0,  // 0: flexigpt.inference.v1.InferenceService.AddProvider:input_type -> flexigpt.inference.v1.AddProviderRequest
+	2,  // 1: flexigpt.inference.v1.InferenceService.DeleteProvider:input_type -> flexigpt.inference.v1.DeleteProviderRequest
+	4,  // 2: flexigpt.inference.v1.InferenceService.SetProviderAPIKey:input_type -> flexigpt.inference.v1.SetProviderAPIKeyRequest
+	6,  // 3: flexigpt.inference.v1.InferenceService.ListProviders:input_type -> flexigpt.inference.v1.ListProvidersRequest
+	8,  // 4: flexigpt.inference.v1.InferenceService.FetchCompletion:input_type -> flexigpt.inference.v1.FetchCompletionRequest
+	8,  // 5: flexigpt.inference.v1.InferenceService.StreamFetchCompletion:input_type -> flexigpt.inference.v1.FetchCompletionRequest
+	11, // 6: flexigpt.inference.v1.InferenceService.GetDataContractInfo:input_type -> flexigpt.inference.v1.GetDataContractInfoRequest
+	1,  // 7: flexigpt.inference.v1.InferenceService.AddProvider:output_type -> flexigpt.inference.v1.AddProviderResponse
+	3,  // 8: flexigpt.inference.v1.InferenceService.DeleteProvider:output_type -> flexigpt.inference.v1.DeleteProviderResponse
+	5,  // 9: flexigpt.inference.v1.InferenceService.SetProviderAPIKey:output_type -> flexigpt.inference.v1.SetProviderAPIKeyResponse
+	7,  // 10: flexigpt.inference.v1.InferenceService.ListProviders:output_type -> flexigpt.inference.v1.ListProvidersResponse
+	9,  // 11: flexigpt.inference.v1.InferenceService.FetchCompletion:output_type -> flexigpt.inference.v1.FetchCompletionResponse
+	10, // 12: flexigpt.inference.v1.InferenceService.StreamFetchCompletion:output_type -> flexigpt.inference.v1.StreamFetchCompletionResponse
+	12, // 13: flexigpt.inference.v1.InferenceService.GetDataContractInfo:output_type -> flexigpt.inference.v1.GetDataContractInfoResponse
+	7,  // [7:14] is the sub-list for method output_type
+	0,  // [0:7] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_inference_proto_init() }
+func file_inference_proto_init() {
+	if File_inference_proto != nil {
+		return
+	}
+	file_inference_proto_msgTypes[10].OneofWrappers = []any{
+		(*StreamFetchCompletionResponse_EventJson)(nil),
+		(*StreamFetchCompletionResponse_ResponseJson)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_inference_proto_rawDesc), len(file_inference_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_inference_proto_goTypes,
+		DependencyIndexes: file_inference_proto_depIdxs,
+		MessageInfos:      file_inference_proto_msgTypes,
+	}.Build()
+	File_inference_proto = out.File
+	file_inference_proto_goTypes = nil
+	file_inference_proto_depIdxs = nil
+}
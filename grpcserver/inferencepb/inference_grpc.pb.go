@@ -0,0 +1,377 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             v5.28.3
+// source: inference.proto
+
+package inferencepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	InferenceService_AddProvider_FullMethodName           = "/flexigpt.inference.v1.InferenceService/AddProvider"
+	InferenceService_DeleteProvider_FullMethodName        = "/flexigpt.inference.v1.InferenceService/DeleteProvider"
+	InferenceService_SetProviderAPIKey_FullMethodName     = "/flexigpt.inference.v1.InferenceService/SetProviderAPIKey"
+	InferenceService_ListProviders_FullMethodName         = "/flexigpt.inference.v1.InferenceService/ListProviders"
+	InferenceService_FetchCompletion_FullMethodName       = "/flexigpt.inference.v1.InferenceService/FetchCompletion"
+	InferenceService_StreamFetchCompletion_FullMethodName = "/flexigpt.inference.v1.InferenceService/StreamFetchCompletion"
+	InferenceService_GetDataContractInfo_FullMethodName   = "/flexigpt.inference.v1.InferenceService/GetDataContractInfo"
+)
+
+// InferenceServiceClient is the client API for InferenceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// InferenceService exposes a ProviderSetAPI to non-Go services. Requests, responses, stream events, options and
+// provider configs travel as the JSON encoding of the corresponding Go types (the data contract described by
+// GetDataContractInfo), so clients need no generated copies of them.
+type InferenceServiceClient interface {
+	// AddProvider registers a provider. config_json is an inference.AddProviderConfig.
+	AddProvider(ctx context.Context, in *AddProviderRequest, opts ...grpc.CallOption) (*AddProviderResponse, error)
+	// DeleteProvider removes a provider.
+	DeleteProvider(ctx context.Context, in *DeleteProviderRequest, opts ...grpc.CallOption) (*DeleteProviderResponse, error)
+	// SetProviderAPIKey sets or clears the API key of a provider.
+	SetProviderAPIKey(ctx context.Context, in *SetProviderAPIKeyRequest, opts ...grpc.CallOption) (*SetProviderAPIKeyResponse, error)
+	// ListProviders returns the registered providers, without their credentials.
+	ListProviders(ctx context.Context, in *ListProvidersRequest, opts ...grpc.CallOption) (*ListProvidersResponse, error)
+	// FetchCompletion runs a non-streaming completion.
+	FetchCompletion(ctx context.Context, in *FetchCompletionRequest, opts ...grpc.CallOption) (*FetchCompletionResponse, error)
+	// StreamFetchCompletion runs a streaming completion: every spec.StreamEvent is sent as it arrives, followed by
+	// the final response.
+	StreamFetchCompletion(ctx context.Context, in *FetchCompletionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamFetchCompletionResponse], error)
+	// GetDataContractInfo returns the data contract version and hash of the server.
+	GetDataContractInfo(ctx context.Context, in *GetDataContractInfoRequest, opts ...grpc.CallOption) (*GetDataContractInfoResponse, error)
+}
+
+type inferenceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInferenceServiceClient(cc grpc.ClientConnInterface) InferenceServiceClient {
+	return &inferenceServiceClient{cc}
+}
+
+func (c *inferenceServiceClient) AddProvider(ctx context.Context, in *AddProviderRequest, opts ...grpc.CallOption) (*AddProviderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddProviderResponse)
+	err := c.cc.Invoke(ctx, InferenceService_AddProvider_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inferenceServiceClient) DeleteProvider(ctx context.Context, in *DeleteProviderRequest, opts ...grpc.CallOption) (*DeleteProviderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteProviderResponse)
+	err := c.cc.Invoke(ctx, InferenceService_DeleteProvider_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inferenceServiceClient) SetProviderAPIKey(ctx context.Context, in *SetProviderAPIKeyRequest, opts ...grpc.CallOption) (*SetProviderAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetProviderAPIKeyResponse)
+	err := c.cc.Invoke(ctx, InferenceService_SetProviderAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inferenceServiceClient) ListProviders(ctx context.Context, in *ListProvidersRequest, opts ...grpc.CallOption) (*ListProvidersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProvidersResponse)
+	err := c.cc.Invoke(ctx, InferenceService_ListProviders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inferenceServiceClient) FetchCompletion(ctx context.Context, in *FetchCompletionRequest, opts ...grpc.CallOption) (*FetchCompletionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchCompletionResponse)
+	err := c.cc.Invoke(ctx, InferenceService_FetchCompletion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inferenceServiceClient) StreamFetchCompletion(ctx context.Context, in *FetchCompletionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamFetchCompletionResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &InferenceService_ServiceDesc.Streams[0], InferenceService_StreamFetchCompletion_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FetchCompletionRequest, StreamFetchCompletionResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InferenceService_StreamFetchCompletionClient = grpc.ServerStreamingClient[StreamFetchCompletionResponse]
+
+func (c *inferenceServiceClient) GetDataContractInfo(ctx context.Context, in *GetDataContractInfoRequest, opts ...grpc.CallOption) (*GetDataContractInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDataContractInfoResponse)
+	err := c.cc.Invoke(ctx, InferenceService_GetDataContractInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InferenceServiceServer is the server API for InferenceService service.
+// All implementations must embed UnimplementedInferenceServiceServer
+// for forward compatibility.
+//
+// InferenceService exposes a ProviderSetAPI to non-Go services. Requests, responses, stream events, options and
+// provider configs travel as the JSON encoding of the corresponding Go types (the data contract described by
+// GetDataContractInfo), so clients need no generated copies of them.
+type InferenceServiceServer interface {
+	// AddProvider registers a provider. config_json is an inference.AddProviderConfig.
+	AddProvider(context.Context, *AddProviderRequest) (*AddProviderResponse, error)
+	// DeleteProvider removes a provider.
+	DeleteProvider(context.Context, *DeleteProviderRequest) (*DeleteProviderResponse, error)
+	// SetProviderAPIKey sets or clears the API key of a provider.
+	SetProviderAPIKey(context.Context, *SetProviderAPIKeyRequest) (*SetProviderAPIKeyResponse, error)
+	// ListProviders returns the registered providers, without their credentials.
+	ListProviders(context.Context, *ListProvidersRequest) (*ListProvidersResponse, error)
+	// FetchCompletion runs a non-streaming completion.
+	FetchCompletion(context.Context, *FetchCompletionRequest) (*FetchCompletionResponse, error)
+	// StreamFetchCompletion runs a streaming completion: every spec.StreamEvent is sent as it arrives, followed by
+	// the final response.
+	StreamFetchCompletion(*FetchCompletionRequest, grpc.ServerStreamingServer[StreamFetchCompletionResponse]) error
+	// GetDataContractInfo returns the data contract version and hash of the server.
+	GetDataContractInfo(context.Context, *GetDataContractInfoRequest) (*GetDataContractInfoResponse, error)
+	mustEmbedUnimplementedInferenceServiceServer()
+}
+
+// UnimplementedInferenceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInferenceServiceServer struct{}
+
+func (UnimplementedInferenceServiceServer) AddProvider(context.Context, *AddProviderRequest) (*AddProviderResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddProvider not implemented")
+}
+func (UnimplementedInferenceServiceServer) DeleteProvider(context.Context, *DeleteProviderRequest) (*DeleteProviderResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteProvider not implemented")
+}
+func (UnimplementedInferenceServiceServer) SetProviderAPIKey(context.Context, *SetProviderAPIKeyRequest) (*SetProviderAPIKeyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetProviderAPIKey not implemented")
+}
+func (UnimplementedInferenceServiceServer) ListProviders(context.Context, *ListProvidersRequest) (*ListProvidersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListProviders not implemented")
+}
+func (UnimplementedInferenceServiceServer) FetchCompletion(context.Context, *FetchCompletionRequest) (*FetchCompletionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FetchCompletion not implemented")
+}
+func (UnimplementedInferenceServiceServer) StreamFetchCompletion(*FetchCompletionRequest, grpc.ServerStreamingServer[StreamFetchCompletionResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamFetchCompletion not implemented")
+}
+func (UnimplementedInferenceServiceServer) GetDataContractInfo(context.Context, *GetDataContractInfoRequest) (*GetDataContractInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDataContractInfo not implemented")
+}
+func (UnimplementedInferenceServiceServer) mustEmbedUnimplementedInferenceServiceServer() {}
+func (UnimplementedInferenceServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeInferenceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InferenceServiceServer will
+// result in compilation errors.
+type UnsafeInferenceServiceServer interface {
+	mustEmbedUnimplementedInferenceServiceServer()
+}
+
+func RegisterInferenceServiceServer(s grpc.ServiceRegistrar, srv InferenceServiceServer) {
+	// If the following call panics, it indicates UnimplementedInferenceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&InferenceService_ServiceDesc, srv)
+}
+
+func _InferenceService_AddProvider_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddProviderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InferenceServiceServer).AddProvider(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InferenceService_AddProvider_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InferenceServiceServer).AddProvider(ctx, req.(*AddProviderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InferenceService_DeleteProvider_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProviderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InferenceServiceServer).DeleteProvider(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InferenceService_DeleteProvider_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InferenceServiceServer).DeleteProvider(ctx, req.(*DeleteProviderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InferenceService_SetProviderAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetProviderAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InferenceServiceServer).SetProviderAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InferenceService_SetProviderAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InferenceServiceServer).SetProviderAPIKey(ctx, req.(*SetProviderAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InferenceService_ListProviders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProvidersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InferenceServiceServer).ListProviders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InferenceService_ListProviders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InferenceServiceServer).ListProviders(ctx, req.(*ListProvidersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InferenceService_FetchCompletion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchCompletionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InferenceServiceServer).FetchCompletion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InferenceService_FetchCompletion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InferenceServiceServer).FetchCompletion(ctx, req.(*FetchCompletionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InferenceService_StreamFetchCompletion_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FetchCompletionRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InferenceServiceServer).StreamFetchCompletion(m, &grpc.GenericServerStream[FetchCompletionRequest, StreamFetchCompletionResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InferenceService_StreamFetchCompletionServer = grpc.ServerStreamingServer[StreamFetchCompletionResponse]
+
+func _InferenceService_GetDataContractInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDataContractInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InferenceServiceServer).GetDataContractInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InferenceService_GetDataContractInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InferenceServiceServer).GetDataContractInfo(ctx, req.(*GetDataContractInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InferenceService_ServiceDesc is the grpc.ServiceDesc for InferenceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InferenceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "flexigpt.inference.v1.InferenceService",
+	HandlerType: (*InferenceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddProvider",
+			Handler:    _InferenceService_AddProvider_Handler,
+		},
+		{
+			MethodName: "DeleteProvider",
+			Handler:    _InferenceService_DeleteProvider_Handler,
+		},
+		{
+			MethodName: "SetProviderAPIKey",
+			Handler:    _InferenceService_SetProviderAPIKey_Handler,
+		},
+		{
+			MethodName: "ListProviders",
+			Handler:    _InferenceService_ListProviders_Handler,
+		},
+		{
+			MethodName: "FetchCompletion",
+			Handler:    _InferenceService_FetchCompletion_Handler,
+		},
+		{
+			MethodName: "GetDataContractInfo",
+			Handler:    _InferenceService_GetDataContractInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFetchCompletion",
+			Handler:       _InferenceService_StreamFetchCompletion_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "inference.proto",
+}
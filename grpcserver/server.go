@@ -0,0 +1,224 @@
+// Package grpcserver exposes a ProviderSetAPI over gRPC, so that non-Go services can use the inference layer as a
+// sidecar. The service is defined in inferencepb/inference.proto; request, response and event payloads are the JSON
+// encoding of the spec types.
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	inference "github.com/flexigpt/inference-go"
+	"github.com/flexigpt/inference-go/grpcserver/inferencepb"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// Server implements inferencepb.InferenceServiceServer on top of a ProviderSetAPI.
+type Server struct {
+	inferencepb.UnimplementedInferenceServiceServer
+
+	ps     *inference.ProviderSetAPI
+	health *health.Server
+}
+
+// New returns a Server for ps.
+func New(ps *inference.ProviderSetAPI) *Server {
+	return &Server{ps: ps, health: health.NewServer()}
+}
+
+// Register registers the inference service and the standard gRPC health service on s. The health status of the
+// inference service is SERVING.
+func (s *Server) Register(gs *grpc.Server) {
+	inferencepb.RegisterInferenceServiceServer(gs, s)
+	healthpb.RegisterHealthServer(gs, s.health)
+	s.health.SetServingStatus(
+		inferencepb.InferenceService_ServiceDesc.ServiceName,
+		healthpb.HealthCheckResponse_SERVING,
+	)
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+}
+
+// Shutdown sets all health statuses to NOT_SERVING, e.g. before a graceful stop.
+func (s *Server) Shutdown() {
+	s.health.Shutdown()
+}
+
+func (s *Server) AddProvider(
+	ctx context.Context,
+	req *inferencepb.AddProviderRequest,
+) (*inferencepb.AddProviderResponse, error) {
+	var config inference.AddProviderConfig
+	if err := json.Unmarshal([]byte(req.GetConfigJson()), &config); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid config_json: %v", err)
+	}
+	pp, err := s.ps.AddProvider(ctx, spec.ProviderName(req.GetProvider()), &config)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	b, err := json.Marshal(pp)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &inferencepb.AddProviderResponse{ProviderJson: string(b)}, nil
+}
+
+func (s *Server) DeleteProvider(
+	ctx context.Context,
+	req *inferencepb.DeleteProviderRequest,
+) (*inferencepb.DeleteProviderResponse, error) {
+	if err := s.ps.DeleteProvider(ctx, spec.ProviderName(req.GetProvider())); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &inferencepb.DeleteProviderResponse{}, nil
+}
+
+func (s *Server) SetProviderAPIKey(
+	ctx context.Context,
+	req *inferencepb.SetProviderAPIKeyRequest,
+) (*inferencepb.SetProviderAPIKeyResponse, error) {
+	if err := s.ps.SetProviderAPIKey(ctx, spec.ProviderName(req.GetProvider()), req.GetApiKey()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &inferencepb.SetProviderAPIKeyResponse{}, nil
+}
+
+func (s *Server) ListProviders(
+	ctx context.Context,
+	_ *inferencepb.ListProvidersRequest,
+) (*inferencepb.ListProvidersResponse, error) {
+	b, err := json.Marshal(s.ps.ListProviders(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &inferencepb.ListProvidersResponse{ProvidersJson: string(b)}, nil
+}
+
+func (s *Server) FetchCompletion(
+	ctx context.Context,
+	req *inferencepb.FetchCompletionRequest,
+) (*inferencepb.FetchCompletionResponse, error) {
+	fcReq, opts, err := decodeFetchCompletion(req)
+	if err != nil {
+		return nil, err
+	}
+	fcReq.ModelParam.Stream = false
+	opts.StreamHandler = nil
+	resp, err := s.ps.FetchCompletion(ctx, spec.ProviderName(req.GetProvider()), fcReq, opts)
+	if err != nil {
+		return nil, statusError(resp, err)
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &inferencepb.FetchCompletionResponse{ResponseJson: string(b)}, nil
+}
+
+func (s *Server) StreamFetchCompletion(
+	req *inferencepb.FetchCompletionRequest,
+	stream grpc.ServerStreamingServer[inferencepb.StreamFetchCompletionResponse],
+) error {
+	fcReq, opts, err := decodeFetchCompletion(req)
+	if err != nil {
+		return err
+	}
+	var mu sync.Mutex
+	send := func(msg *inferencepb.StreamFetchCompletionResponse) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return stream.Send(msg)
+	}
+	fcReq.ModelParam.Stream = true
+	opts.StreamHandler = func(event spec.StreamEvent) error {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return send(&inferencepb.StreamFetchCompletionResponse{
+			Payload: &inferencepb.StreamFetchCompletionResponse_EventJson{EventJson: string(b)},
+		})
+	}
+
+	resp, fetchErr := s.ps.FetchCompletion(stream.Context(), spec.ProviderName(req.GetProvider()), fcReq, opts)
+	// The final response is sent on failure too: it carries salvaged partial output and the error details.
+	if resp != nil {
+		b, err := json.Marshal(resp)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := send(&inferencepb.StreamFetchCompletionResponse{
+			Payload: &inferencepb.StreamFetchCompletionResponse_ResponseJson{ResponseJson: string(b)},
+		}); err != nil {
+			return err
+		}
+	}
+	if fetchErr != nil {
+		return statusError(resp, fetchErr)
+	}
+	return nil
+}
+
+func (s *Server) GetDataContractInfo(
+	context.Context,
+	*inferencepb.GetDataContractInfoRequest,
+) (*inferencepb.GetDataContractInfoResponse, error) {
+	info := inference.GetDataContractInfo()
+	return &inferencepb.GetDataContractInfoResponse{Version: info.Version, Hash: info.Hash}, nil
+}
+
+func decodeFetchCompletion(
+	req *inferencepb.FetchCompletionRequest,
+) (*spec.FetchCompletionRequest, *spec.FetchCompletionOptions, error) {
+	var fcReq spec.FetchCompletionRequest
+	if err := json.Unmarshal([]byte(req.GetRequestJson()), &fcReq); err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "invalid request_json: %v", err)
+	}
+	var opts spec.FetchCompletionOptions
+	if o := req.GetOptionsJson(); o != "" {
+		if err := json.Unmarshal([]byte(o), &opts); err != nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid options_json: %v", err)
+		}
+	}
+	return &fcReq, &opts, nil
+}
+
+// statusError maps a failed completion to a gRPC status using the code of the response error.
+func statusError(resp *spec.FetchCompletionResponse, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	code := codes.Unknown
+	if resp != nil && resp.Error != nil {
+		switch resp.Error.Code {
+		case spec.ErrorCodeAuthentication:
+			code = codes.Unauthenticated
+		case spec.ErrorCodePermissionDenied:
+			code = codes.PermissionDenied
+		case spec.ErrorCodeNotFound:
+			code = codes.NotFound
+		case spec.ErrorCodeInvalidRequest, spec.ErrorCodeContextLength:
+			code = codes.InvalidArgument
+		case spec.ErrorCodeRateLimit, spec.ErrorCodeQuotaExceeded:
+			code = codes.ResourceExhausted
+		case spec.ErrorCodeOverloaded, spec.ErrorCodeNetwork:
+			code = codes.Unavailable
+		case spec.ErrorCodeTimeout:
+			code = codes.DeadlineExceeded
+		case spec.ErrorCodeCanceled:
+			code = codes.Canceled
+		case spec.ErrorCodeServer, spec.ErrorCodePanic:
+			code = codes.Internal
+		}
+	}
+	return status.Error(code, err.Error())
+}
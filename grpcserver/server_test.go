@@ -0,0 +1,182 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	inference "github.com/flexigpt/inference-go"
+	"github.com/flexigpt/inference-go/grpcserver/inferencepb"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		b, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(b), `"stream":true`) {
+			_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"o"},"done":false}` + "\n"))
+		}
+		_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"ok"},"done":true}` + "\n"))
+	}))
+	t.Cleanup(ollama.Close)
+
+	ps, err := inference.NewProviderSetAPI()
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI() error = %v.", err)
+	}
+	gs := grpc.NewServer()
+	New(ps).Register(gs)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v.", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := inferencepb.NewInferenceServiceClient(conn)
+
+	hc, err := healthpb.NewHealthClient(conn).Check(t.Context(), &healthpb.HealthCheckRequest{
+		Service: inferencepb.InferenceService_ServiceDesc.ServiceName,
+	})
+	if err != nil || hc.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Check() = %v, %v, want SERVING.", hc, err)
+	}
+
+	config, _ := json.Marshal(inference.AddProviderConfig{SDKType: spec.ProviderSDKTypeOllama, Origin: ollama.URL})
+	if _, err := client.AddProvider(t.Context(), &inferencepb.AddProviderRequest{
+		Provider:   "ollama",
+		ConfigJson: string(config),
+	}); err != nil {
+		t.Fatalf("AddProvider() error = %v.", err)
+	}
+
+	request, _ := json.Marshal(spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs: []spec.InputUnion{{
+			Kind: spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{
+				Role: spec.RoleUser,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: "hi"},
+				}},
+			},
+		}},
+	})
+
+	t.Run("ListProviders.", func(t *testing.T) {
+		resp, err := client.ListProviders(t.Context(), &inferencepb.ListProvidersRequest{})
+		if err != nil {
+			t.Fatalf("ListProviders() error = %v.", err)
+		}
+		var got []inference.ProviderStatus
+		if err := json.Unmarshal([]byte(resp.GetProvidersJson()), &got); err != nil {
+			t.Fatalf("ListProviders() providers_json = %s: %v.", resp.GetProvidersJson(), err)
+		}
+		if len(got) != 1 || got[0].Name != "ollama" || got[0].SDKType != spec.ProviderSDKTypeOllama {
+			t.Fatalf("ListProviders() = %+v, want the ollama provider.", got)
+		}
+	})
+
+	t.Run("Unary.", func(t *testing.T) {
+		resp, err := client.FetchCompletion(t.Context(), &inferencepb.FetchCompletionRequest{
+			Provider:    "ollama",
+			RequestJson: string(request),
+		})
+		if err != nil {
+			t.Fatalf("FetchCompletion() error = %v.", err)
+		}
+		if !strings.Contains(resp.GetResponseJson(), `"text":"ok"`) {
+			t.Fatalf("FetchCompletion() response = %s, want the answer text.", resp.GetResponseJson())
+		}
+	})
+
+	t.Run("Streaming.", func(t *testing.T) {
+		stream, err := client.StreamFetchCompletion(t.Context(), &inferencepb.FetchCompletionRequest{
+			Provider:    "ollama",
+			RequestJson: string(request),
+		})
+		if err != nil {
+			t.Fatalf("StreamFetchCompletion() error = %v.", err)
+		}
+		var events int
+		var final string
+		for {
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Recv() error = %v.", err)
+			}
+			if final != "" {
+				t.Fatalf("Recv() got a message after the final response.")
+			}
+			if msg.GetEventJson() != "" {
+				events++
+			}
+			final = msg.GetResponseJson()
+		}
+		if events == 0 || !strings.Contains(final, `"text":"ook"`) {
+			t.Fatalf("stream events = %d, final = %s, want events then the full response.", events, final)
+		}
+	})
+
+	t.Run("Errors.", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  *inferencepb.FetchCompletionRequest
+			want codes.Code
+		}{
+			{
+				name: "InvalidJSON.",
+				req:  &inferencepb.FetchCompletionRequest{Provider: "ollama", RequestJson: "{"},
+				want: codes.InvalidArgument,
+			},
+			{
+				name: "UnknownProvider.",
+				req:  &inferencepb.FetchCompletionRequest{Provider: "none", RequestJson: string(request)},
+				want: codes.Unknown,
+			},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := client.FetchCompletion(t.Context(), tc.req)
+				if got := status.Code(err); got != tc.want {
+					t.Fatalf("FetchCompletion() code = %v, want %v.", got, tc.want)
+				}
+			})
+		}
+	})
+
+	info, err := client.GetDataContractInfo(t.Context(), &inferencepb.GetDataContractInfoRequest{})
+	if err != nil || info.GetHash() != inference.DataContractHash {
+		t.Fatalf("GetDataContractInfo() = %v, %v, want the contract hash.", info, err)
+	}
+	_, err = client.DeleteProvider(t.Context(), &inferencepb.DeleteProviderRequest{Provider: "ollama"})
+	if err != nil {
+		t.Fatalf("DeleteProvider() error = %v.", err)
+	}
+}
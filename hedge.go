@@ -0,0 +1,156 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/events"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// errHedgeLost aborts the stream of the attempt that lost a hedged race.
+var errHedgeLost = errors.New("hedged attempt lost the race")
+
+// hedgeRace decides which of two concurrent attempts owns the stream: the first one to deliver content.
+type hedgeRace struct {
+	mu      sync.Mutex
+	winner  int
+	cancels [2]context.CancelFunc
+}
+
+func (r *hedgeRace) handler(
+	i int,
+	stream *fallbackStream,
+	routes []spec.FallbackRoute,
+	delay time.Duration,
+) spec.StreamHandler {
+	return func(event spec.StreamEvent) error {
+		r.mu.Lock()
+		if r.winner == -1 && isContentEvent(event) {
+			r.winner = i
+			for j, cancel := range r.cancels {
+				if j != i && cancel != nil {
+					cancel()
+				}
+			}
+			if i == 1 {
+				reason := fmt.Errorf("no content from the first route within %s", delay)
+				if err := stream.switchRoute(routes[0], routes[1], reason); err != nil {
+					r.mu.Unlock()
+					return err
+				}
+			}
+		}
+		winner := r.winner
+		r.mu.Unlock()
+
+		switch winner {
+		case i:
+			return stream.handle(event)
+		case -1:
+			// Usage and error events of an attempt that has not streamed content yet are dropped.
+			return nil
+		default:
+			return errHedgeLost
+		}
+	}
+}
+
+func (r *hedgeRace) winnerIndex() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.winner
+}
+
+func (r *hedgeRace) cancelAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cancel := range r.cancels {
+		if cancel != nil {
+			cancel()
+		}
+	}
+}
+
+type hedgeResult struct {
+	index int
+	resp  *spec.FetchCompletionResponse
+	err   error
+}
+
+// fetchHedged streams req from routes[0] and, if no content arrived within delay (or routes[0] failed first), also
+// from routes[1]. The first route to stream content wins; the other attempt is canceled. Both attempts publish their
+// own attempt and finished events, numbered from attempt.
+func (ps *ProviderSetAPI) fetchHedged(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	stream *fallbackStream,
+	routes []spec.FallbackRoute,
+	delay time.Duration,
+	requestID string,
+	attempt int,
+) (*spec.FetchCompletionResponse, error) {
+	race := &hedgeRace{winner: -1}
+	defer race.cancelAll()
+	results := make(chan hedgeResult, len(routes))
+	start := func(i int) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		race.mu.Lock()
+		race.cancels[i] = cancel
+		race.mu.Unlock()
+
+		attemptOpts := *opts
+		attemptOpts.StreamHandler = race.handler(i, stream, routes, delay)
+		go func() {
+			resp, err := ps.fetchRoute(
+				attemptCtx, routes[i].Provider, routeRequest(req, routes[i]), &attemptOpts, requestID, attempt+i,
+			)
+			results <- hedgeResult{index: i, resp: resp, err: err}
+		}()
+	}
+
+	start(0)
+	started, running := 1, 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	var last hedgeResult
+	for running > 0 {
+		select {
+		case <-timer.C:
+			if started == 1 && race.winnerIndex() == -1 {
+				ps.eventBus.Publish(events.Event{
+					Kind:             events.KindHedge,
+					RequestID:        requestID,
+					Provider:         routes[0].Provider,
+					Model:            routes[0].Model,
+					Attempt:          attempt + 1,
+					FallbackProvider: routes[1].Provider,
+					FallbackModel:    routes[1].Model,
+				})
+				start(1)
+				started++
+				running++
+			}
+		case res := <-results:
+			running--
+			winner := race.winnerIndex()
+			if res.err == nil || winner == res.index {
+				return res.resp, res.err
+			}
+			if winner == -1 {
+				last = res
+			}
+			if started == 1 && ctx.Err() == nil && stream.canRestart() {
+				// The first route failed before the hedge delay: start the second one right away.
+				start(1)
+				started++
+				running++
+			}
+		}
+	}
+	return last.resp, last.err
+}
@@ -0,0 +1,78 @@
+package inference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionHedge(t *testing.T) {
+	t.Parallel()
+
+	sse := func(text string, wait time.Duration) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(wait):
+			case <-r.Context().Done():
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(
+				`data: {"id":"c","object":"chat.completion.chunk","created":0,"model":"m",` +
+					`"choices":[{"index":0,"delta":{"role":"assistant","content":"` + text + `"},` +
+					`"finish_reason":null}]}` + "\n\n" +
+					`data: {"id":"c","object":"chat.completion.chunk","created":0,"model":"m",` +
+					`"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}` + "\n\n" +
+					"data: [DONE]\n\n",
+			))
+		}
+	}
+	slow := httptest.NewServer(sse("slow", time.Second))
+	t.Cleanup(slow.Close)
+	fast := httptest.NewServer(sse("fast", 0))
+	t.Cleanup(fast.Close)
+
+	ps := newTestProviderSet(t)
+	for name, origin := range map[spec.ProviderName]string{"slow": slow.URL, "fast": fast.URL} {
+		addTestProvider(t, ps, name, &AddProviderConfig{
+			SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+			Origin:                   origin,
+			ChatCompletionPathPrefix: spec.DefaultOpenAIChatCompletionsPrefix,
+		})
+	}
+
+	var text strings.Builder
+	var switched bool
+	start := time.Now()
+	resp, err := ps.FetchCompletion(t.Context(), "slow", &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m", Stream: true},
+		Inputs:     userInputs("hi"),
+	}, &spec.FetchCompletionOptions{
+		StreamHandler: func(event spec.StreamEvent) error {
+			switch event.Kind {
+			case spec.StreamContentKindProviderSwitch:
+				switched = true
+			case spec.StreamContentKindText:
+				text.WriteString(event.Text.Text)
+			default:
+			}
+			return nil
+		},
+		Fallbacks:        []spec.FallbackRoute{{Provider: "fast"}},
+		HedgeAfterMillis: 50,
+	})
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	if !switched || text.String() != "fast" || resp.Metadata.Provider != "fast" {
+		t.Fatalf("switched = %v, text = %q, provider = %s, want the fast route.", switched, text.String(),
+			resp.Metadata.Provider)
+	}
+	if elapsed := time.Since(start); elapsed > 800*time.Millisecond {
+		t.Fatalf("FetchCompletion() took %s, want the slow route canceled.", elapsed)
+	}
+}
@@ -6,13 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"net/http"
+	"slices"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 	anthropicSharedConstant "github.com/anthropics/anthropic-sdk-go/shared/constant"
+	"github.com/tidwall/gjson"
 
 	"github.com/flexigpt/inference-go/internal/logutil"
 	"github.com/flexigpt/inference-go/internal/sdkutil"
@@ -25,6 +27,10 @@ type AnthropicMessagesAPI struct {
 	debugger      spec.CompletionDebugger
 	client        *anthropic.Client
 	mu            sync.RWMutex
+
+	// batchToolChoices holds the tool choice name maps of submitted batches by batch and custom ID, so that their
+	// results can be converted like FetchCompletion responses.
+	batchToolChoices map[string]map[string]map[string]spec.ToolChoice
 }
 
 // NewAnthropicMessagesAPI creates a new instance of Anthropics provider.
@@ -49,7 +55,7 @@ func (api *AnthropicMessagesAPI) InitLLM(ctx context.Context) error {
 		return errors.New("anthropic messages api LLM: no ProviderParam found")
 	}
 
-	if strings.TrimSpace(api.ProviderParam.APIKey) == "" {
+	if strings.TrimSpace(api.ProviderParam.APIKey) == "" && api.ProviderParam.Credentials == nil {
 		logutil.Debug(
 			string(
 				api.ProviderParam.Name,
@@ -99,10 +105,33 @@ func (api *AnthropicMessagesAPI) InitLLM(ctx context.Context) error {
 		)
 	}
 
-	if api.debugger != nil {
-		if httpClient := api.debugger.HTTPClient(nil); httpClient != nil {
-			opts = append(opts, option.WithHTTPClient(httpClient))
+	// Propagate spec.RequestContext from the request context as headers.
+	opts = append(opts, option.WithMiddleware(sdkutil.RequestContextMiddleware))
+
+	if pi.Vertex != nil {
+		opts = append(opts, option.WithMiddleware(vertexMiddleware(pi.Vertex)))
+	}
+	if pi.Credentials != nil {
+		opts = append(opts, option.WithMiddleware(
+			sdkutil.CredentialsMiddleware(pi.Credentials, spec.DefaultAnthropicAuthorizationHeaderKey),
+		))
+	}
+
+	if pi.OriginFailover != nil && len(pi.OriginFailover.Origins) > 0 {
+		primary := spec.DefaultAnthropicOrigin
+		if pi.Origin != "" {
+			primary = pi.Origin
+		}
+		pool, err := sdkutil.NewOriginPool(primary, pi.OriginFailover)
+		if err != nil {
+			api.client = nil
+			return err
 		}
+		opts = append(opts, option.WithMiddleware(pool.Middleware))
+	}
+
+	if httpClient := sdkutil.HTTPClient(&pi, api.debugger); httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
 	}
 
 	c := anthropic.NewClient(opts...)
@@ -145,7 +174,8 @@ func (api *AnthropicMessagesAPI) GetProviderInfo(ctx context.Context) *spec.Prov
 func (api *AnthropicMessagesAPI) IsConfigured(ctx context.Context) bool {
 	api.mu.RLock()
 	defer api.mu.RUnlock()
-	return api.ProviderParam != nil && strings.TrimSpace(api.ProviderParam.APIKey) != ""
+	return api.ProviderParam != nil &&
+		(api.ProviderParam.Credentials != nil || strings.TrimSpace(api.ProviderParam.APIKey) != "")
 }
 
 func (api *AnthropicMessagesAPI) SetProviderAPIKey(ctx context.Context, apiKey string) error {
@@ -161,10 +191,58 @@ func (api *AnthropicMessagesAPI) SetProviderAPIKey(ctx context.Context, apiKey s
 	return nil
 }
 
+// FetchRaw sends an arbitrary request to the provider, reusing the configured auth, base URL, debugger and retries.
+func (api *AnthropicMessagesAPI) FetchRaw(
+	ctx context.Context,
+	method, path string,
+	body json.RawMessage,
+) (*spec.RawResponse, error) {
+	api.mu.RLock()
+	client := api.client
+	api.mu.RUnlock()
+	if client == nil {
+		return nil, errors.New("anthropic messages api LLM: client not initialized")
+	}
+	relPath, err := sdkutil.RawRequestPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var params any
+	if len(body) > 0 {
+		params = body
+	}
+	var httpResp *http.Response
+	if err := client.Execute(ctx, strings.ToUpper(method), relPath, params, &httpResp); err != nil {
+		// Error statuses are returned like any other response; the SDK keeps the body on the error.
+		var apiErr *anthropic.Error
+		if errors.As(err, &apiErr) && apiErr.Response != nil {
+			return sdkutil.ReadRawResponse(apiErr.Response)
+		}
+		return nil, err
+	}
+	return sdkutil.ReadRawResponse(httpResp)
+}
+
 func (api *AnthropicMessagesAPI) FetchCompletion(
 	ctx context.Context,
 	req *spec.FetchCompletionRequest,
 	opts *spec.FetchCompletionOptions,
+) (resp *spec.FetchCompletionResponse, err error) {
+	api.mu.RLock()
+	var providerName spec.ProviderName
+	if api.ProviderParam != nil {
+		providerName = api.ProviderParam.Name
+	}
+	api.mu.RUnlock()
+	defer sdkutil.RecoverFetchCompletion(providerName, &resp, &err)
+
+	return api.fetchCompletion(ctx, req, opts)
+}
+
+func (api *AnthropicMessagesAPI) fetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
 ) (*spec.FetchCompletionResponse, error) {
 	api.mu.RLock()
 	client := api.client
@@ -174,12 +252,94 @@ func (api *AnthropicMessagesAPI) FetchCompletion(
 	}
 	api.mu.RUnlock()
 
-	if client == nil {
+	if client == nil && (opts == nil || !opts.DryRun) {
 		return nil, errors.New("anthropic messages api LLM: client not initialized")
 	}
 	if req == nil || len(req.Inputs) == 0 || req.ModelParam.Name == "" {
 		return nil, errors.New("anthropic messages api LLM: empty completion data")
 	}
+	params, toolChoiceNameMap, betaOpts, err := buildMessageParams(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	timeout := pi.Timeouts.Timeout(req.ModelParam.Name, req.ModelParam.Timeout)
+	reqOpts := append([]option.RequestOption{option.WithRequestTimeout(timeout)}, betaOpts...)
+
+	effective := params
+	effective.Messages, effective.System, effective.Tools = nil, nil, nil
+	effectiveParams := sdkutil.EffectiveParams(effective, "messages", "system", "tools")
+
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	if opts != nil && opts.DryRun {
+		return sdkutil.DryRunResponse(&pi, req.ModelParam.Name, useStream, params)
+	}
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+		})
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		fullRawResp    *anthropic.Message
+		apiErr         error
+	)
+	if useStream {
+		normalizedResp, fullRawResp, apiErr = api.doStreaming(
+			ctx,
+			client,
+			pi.Name,
+			req.ModelParam.Name,
+			params,
+			opts,
+			reqOpts,
+			toolChoiceNameMap,
+		)
+	} else {
+		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(
+			ctx,
+			client,
+			pi.Name,
+			params,
+			reqOpts,
+			toolChoiceNameMap,
+		)
+	}
+
+	normalizedResp = sdkutil.FinalizeResponse(pi.Name, normalizedResp, apiErr)
+	if normalizedResp != nil {
+		normalizedResp.Metadata = &spec.ResponseMetadata{EffectiveParams: effectiveParams}
+	}
+
+	if span != nil {
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: fullRawResp,
+			Response:         normalizedResp, // may be nil
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+// buildMessageParams converts req into Messages API params. It also returns the tool choice name map needed to
+// convert the response and the beta header options the request needs.
+func buildMessageParams(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+) (anthropic.MessageNewParams, map[string]spec.ToolChoice, []option.RequestOption, error) {
 	// Decide if we must override thinking based on interleaved input history.
 	thinkingAnalysis := analyzeAnthropicThinkingBehavior(req.Inputs)
 
@@ -190,7 +350,7 @@ func (api *AnthropicMessagesAPI) FetchCompletion(
 		req.Inputs,
 	)
 	if err != nil {
-		return nil, err
+		return anthropic.MessageNewParams{}, nil, nil, err
 	}
 
 	params := anthropic.MessageNewParams{
@@ -205,15 +365,13 @@ func (api *AnthropicMessagesAPI) FetchCompletion(
 	if len(sysParams) > 0 {
 		params.System = sysParams
 	}
+	if rc, ok := spec.RequestContextFromContext(ctx); ok && rc.TenantID != "" {
+		params.Metadata.UserID = anthropic.String(rc.TenantID)
+	}
 
 	// Apply thinking / temperature in a robust, policy-driven way.
 	applyAnthropicThinkingPolicy(&params, &req.ModelParam, thinkingAnalysis)
 
-	timeout := spec.DefaultAPITimeout
-	if req.ModelParam.Timeout > 0 {
-		timeout = time.Duration(req.ModelParam.Timeout) * time.Second
-	}
-
 	// Optional: provider-side stop sequences.
 	if len(req.ModelParam.StopSequences) > 0 {
 		params.StopSequences = req.ModelParam.StopSequences
@@ -222,91 +380,68 @@ func (api *AnthropicMessagesAPI) FetchCompletion(
 	// Optional: output format (Anthropic supports jsonSchema only).
 	if req.ModelParam.OutputParam != nil {
 		if err := applyAnthropicOutputParam(&params, req.ModelParam.OutputParam); err != nil {
-			return nil, err
+			return params, nil, nil, err
 		}
 	}
 
 	var toolChoiceNameMap map[string]spec.ToolChoice
+	reqOpts := filesRequestOptions(req.Inputs)
 	if len(req.ToolChoices) > 0 {
 		toolDefs, nameMap, err := toolChoicesToAnthropicTools(req.ToolChoices)
 		if err != nil {
-			return nil, err
+			return params, nil, nil, err
 		}
 		if len(toolDefs) > 0 {
 			params.Tools = toolDefs
 			toolChoiceNameMap = nameMap
+			reqOpts = append(reqOpts, codeExecutionRequestOptions(req.ToolChoices)...)
 			// Optional: tool policy. Must be applied after tool defs are built.
 			if req.ToolPolicy != nil {
 				if err := applyAnthropicToolPolicy(&params, req.ToolPolicy, toolChoiceNameMap); err != nil {
-					return nil, err
+					return params, nil, nil, err
 				}
 			}
 		}
 	}
-
-	var span spec.CompletionSpan
-	if api.debugger != nil {
-		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
-			Provider: pi.Name,
-			Model:    req.ModelParam.Name,
-			Request:  req,
-			Options:  opts,
-		})
+	if err := applyAnthropicParamOverlay(&params, req.ModelParam.AdditionalParametersRawJSON); err != nil {
+		return params, nil, nil, err
 	}
 
-	var (
-		normalizedResp *spec.FetchCompletionResponse
-		fullRawResp    *anthropic.Message
-		apiErr         error
-	)
-	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
-	if useStream {
-		normalizedResp, fullRawResp, apiErr = api.doStreaming(
-			ctx,
-			client,
-			pi.Name,
-			req.ModelParam.Name,
-			params,
-			opts,
-			timeout,
-			toolChoiceNameMap,
-		)
-	} else {
-		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(ctx, client, params, timeout, toolChoiceNameMap)
-	}
+	return params, toolChoiceNameMap, reqOpts, nil
+}
 
-	if span != nil {
-		end := spec.CompletionSpanEnd{
-			ProviderResponse: fullRawResp,
-			Response:         normalizedResp, // may be nil
-			Err:              apiErr,
-		}
-		if normalizedResp != nil {
-			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
-				normalizedResp.DebugDetails = dd
-			}
-		} else {
-			_ = span.End(&end) // ignore return; nothing to attach to
-		}
-	}
+// anthropicOverlayDeny are the request fields AdditionalParametersRawJSON cannot set.
+var anthropicOverlayDeny = []string{"model", "messages", "system", "tools", "stream"}
 
-	return normalizedResp, apiErr
+// applyAnthropicParamOverlay merges ModelParam.AdditionalParametersRawJSON into the request body.
+func applyAnthropicParamOverlay(params *anthropic.MessageNewParams, raw *string) error {
+	overlay, err := sdkutil.ParseParamOverlay(raw, anthropicOverlayDeny...)
+	if err != nil || overlay == nil {
+		return err
+	}
+	extra, err := sdkutil.OverlayFields(params, params.ExtraFields(), overlay)
+	if err != nil {
+		return err
+	}
+	params.SetExtraFields(extra)
+	return nil
 }
 
 func (api *AnthropicMessagesAPI) doNonStreaming(
 	ctx context.Context,
 	client *anthropic.Client,
+	providerName spec.ProviderName,
 	params anthropic.MessageNewParams,
-	timeout time.Duration,
+	reqOpts []option.RequestOption,
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *anthropic.Message, error) {
 	resp := &spec.FetchCompletionResponse{}
 
-	anthropicMsg, err := client.Messages.New(ctx, params, option.WithRequestTimeout(timeout))
+	anthropicMsg, err := client.Messages.New(ctx, params, reqOpts...)
 
 	resp.Usage = usageFromAnthropicMessage(anthropicMsg)
 	if err != nil {
-		resp.Error = &spec.Error{Message: err.Error()}
+		resp.Error = providerError(providerName, err)
 		return resp, anthropicMsg, err
 	}
 	resp.Outputs = outputsFromAnthropicMessage(anthropicMsg, toolChoiceNameMap)
@@ -320,66 +455,44 @@ func (api *AnthropicMessagesAPI) doStreaming(
 	modelName spec.ModelName,
 	params anthropic.MessageNewParams,
 	opts *spec.FetchCompletionOptions,
-	timeout time.Duration,
+	reqOpts []option.RequestOption,
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *anthropic.Message, error) {
 	resp := &spec.FetchCompletionResponse{}
-	streamCfg := sdkutil.ResolveStreamConfig(opts)
-
-	emitText := func(chunk string) error {
-		if strings.TrimSpace(chunk) == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:     spec.StreamContentKindText,
-			Provider: providerName,
-			Model:    modelName,
-			Text: &spec.StreamTextChunk{
-				Text: chunk,
-			},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
-
-	emitThinking := func(chunk string) error {
-		if strings.TrimSpace(chunk) == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:     spec.StreamContentKindThinking,
-			Provider: providerName,
-			Model:    modelName,
-			Thinking: &spec.StreamThinkingChunk{Text: chunk},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
-
-	writeTextData, flushTextData := sdkutil.NewBufferedStreamer(
-		emitText,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
-	writeThinkingData, flushThinkingData := sdkutil.NewBufferedStreamer(
-		emitThinking,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
-
-	stream := client.Messages.NewStreaming(
+	pipeline := sdkutil.NewStreamPipeline(
 		ctx,
-		params,
-		option.WithRequestTimeout(timeout),
+		opts.StreamHandler,
+		providerName,
+		modelName,
+		sdkutil.ResolveStreamConfig(opts),
 	)
+
+	stream := client.Messages.NewStreaming(ctx, params, reqOpts...)
 	defer func() { _ = stream.Close() }()
 
 	var (
 		respFull            anthropic.Message
 		streamWriteErr      error
 		streamAccumulateErr error
+		// Result blocks the SDK cannot represent lose fields when re-encoded on content_block_stop. They arrive
+		// complete in content_block_start, so their raw JSON is kept and restored once the stream ends.
+		rawResultBlocks = map[int]string{}
+		// blockOpen reports whether the last content block has not received its content_block_stop yet.
+		blockOpen bool
 	)
 
 	for stream.Next() {
 		event := stream.Current()
+		switch event.AsAny().(type) {
+		case anthropic.ContentBlockStartEvent:
+			blockOpen = true
+		case anthropic.ContentBlockStopEvent:
+			blockOpen = false
+		}
+		if start, ok := event.AsAny().(anthropic.ContentBlockStartEvent); ok &&
+			start.ContentBlock.Type == codeExecutionResultType {
+			rawResultBlocks[len(respFull.Content)] = start.ContentBlock.RawJSON()
+		}
 		err := respFull.Accumulate(event)
 		if err != nil {
 			streamAccumulateErr = err
@@ -396,12 +509,12 @@ func (api *AnthropicMessagesAPI) doStreaming(
 		case anthropic.ContentBlockStopEvent:
 			// Content block done.
 		case anthropic.ContentBlockStartEvent:
-			streamWriteErr = handleContentBlockStartEvent(eventVariant, writeTextData, writeThinkingData)
+			streamWriteErr = handleContentBlockStartEvent(eventVariant, pipeline)
 			if streamWriteErr != nil {
 				break
 			}
 		case anthropic.ContentBlockDeltaEvent:
-			streamWriteErr = handleContentBlockDeltaEvent(eventVariant, writeTextData, writeThinkingData)
+			streamWriteErr = handleContentBlockDeltaEvent(eventVariant, pipeline)
 			if streamWriteErr != nil {
 				break
 			}
@@ -414,33 +527,55 @@ func (api *AnthropicMessagesAPI) doStreaming(
 		}
 	}
 
-	if flushTextData != nil {
-		flushTextData()
+	if err := pipeline.Close(); err != nil && streamWriteErr == nil {
+		streamWriteErr = err
 	}
-
-	if flushThinkingData != nil {
-		flushThinkingData()
+	if blockOpen {
+		// The stream failed mid-block. Closing the block re-encodes it, so that its partial content is salvaged.
+		var stop anthropic.MessageStreamEventUnion
+		if stop.UnmarshalJSON([]byte(`{"type":"content_block_stop","index":0}`)) == nil {
+			_ = respFull.Accumulate(stop)
+		}
+	}
+	for i, raw := range rawResultBlocks {
+		if i < len(respFull.Content) {
+			_ = respFull.Content[i].UnmarshalJSON([]byte(raw))
+		}
 	}
 
 	streamErr := errors.Join(stream.Err(), streamAccumulateErr, streamWriteErr)
 	resp.Usage = usageFromAnthropicMessage(&respFull)
+	pipeline.EmitUsage(resp.Usage)
 	if streamErr != nil {
-		resp.Error = &spec.Error{Message: streamErr.Error()}
+		resp.Error = providerError(providerName, streamErr)
+		pipeline.EmitError(resp.Error)
 	}
 	resp.Outputs = outputsFromAnthropicMessage(&respFull, toolChoiceNameMap)
 	return resp, &respFull, streamErr
 }
 
+// providerError returns the spec.Error of a failed call, with the status, error type and body of an Anthropic API
+// error.
+func providerError(provider spec.ProviderName, err error) *spec.Error {
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		raw := apiErr.RawJSON()
+		return sdkutil.NewError(provider, err, apiErr.StatusCode, gjson.Get(raw, "error.type").String(), raw)
+	}
+	return sdkutil.NewError(provider, err, 0, "", "")
+}
+
 func handleContentBlockStartEvent(
 	event anthropic.ContentBlockStartEvent,
-	writeTextData, writeThinkingData func(string) error,
+	pipeline *sdkutil.StreamPipeline,
 ) error {
+	pos := sdkutil.StreamPosition{OutputItemIndex: int(event.Index)}
 	switch cb := event.ContentBlock.AsAny().(type) {
 	case anthropic.TextBlock:
-		return writeTextData(cb.Text)
+		return pipeline.WriteText(pos, cb.Text)
 
 	case anthropic.ThinkingBlock:
-		return writeThinkingData(cb.Thinking)
+		return pipeline.WriteThinking(pos, cb.Thinking)
 
 	case anthropic.RedactedThinkingBlock:
 		// We don't stream redacted thinking to the caller.
@@ -455,17 +590,26 @@ func handleContentBlockStartEvent(
 
 func handleContentBlockDeltaEvent(
 	event anthropic.ContentBlockDeltaEvent,
-	writeTextData, writeThinkingData func(string) error,
+	pipeline *sdkutil.StreamPipeline,
 ) error {
+	pos := sdkutil.StreamPosition{OutputItemIndex: int(event.Index)}
 	switch delta := event.Delta.AsAny().(type) {
 	case anthropic.TextDelta:
-		return writeTextData(delta.Text)
+		return pipeline.WriteText(pos, delta.Text)
 
 	case anthropic.ThinkingDelta:
-		return writeThinkingData(delta.Thinking)
+		return pipeline.WriteThinking(pos, delta.Thinking)
 
-	case anthropic.InputJSONDelta:
 	case anthropic.CitationsDelta:
+		if c, ok := anthropicCitationDeltaToSpec(delta.Citation); ok {
+			return pipeline.Emit(spec.StreamEvent{
+				Kind:            spec.StreamContentKindCitation,
+				OutputItemIndex: pos.OutputItemIndex,
+				Citation:        &c,
+			})
+		}
+
+	case anthropic.InputJSONDelta:
 	case anthropic.SignatureDelta:
 	default:
 		// Unknown or future delta variant.
@@ -633,6 +777,17 @@ func toAnthropicMessagesInput(
 				out = append(out, anthropic.NewAssistantMessage(*block))
 			}
 
+		case spec.InputKindCodeExecutionToolCall:
+			if block := codeExecutionCallToAnthropicBlock(in.CodeExecutionToolCall); block != nil {
+				out = append(out, anthropic.NewAssistantMessage(*block))
+			}
+
+		case spec.InputKindCodeExecutionToolOutput:
+			// Like web_search_tool_result, code_execution_tool_result is an assistant block.
+			if block := codeExecutionOutputToAnthropicBlock(in.CodeExecutionToolOutput); block != nil {
+				out = append(out, anthropic.NewAssistantMessage(*block))
+			}
+
 		case spec.InputKindFunctionToolOutput, spec.InputKindCustomToolOutput, spec.InputKindWebSearchToolOutput:
 			isWebSearchOutput := false
 			var output *spec.ToolOutput
@@ -659,6 +814,8 @@ func toAnthropicMessagesInput(
 		}
 	}
 
+	out = mergeAdjacentAnthropicMessages(out)
+
 	// System prompt as a single text block.
 	if len(sysParts) > 0 {
 		sysStr := strings.Join(sysParts, "\n\n")
@@ -668,6 +825,45 @@ func toAnthropicMessagesInput(
 	return out, sysPrompts, nil
 }
 
+// mergeAdjacentAnthropicMessages merges consecutive messages of the same role into one, as Anthropic requires turns
+// to alternate. Inputs map to one message per item, so a turn with text and tool_use blocks (or several
+// tool_result blocks) would otherwise be sent as consecutive assistant (or user) messages. In merged user messages
+// the tool_result blocks are moved first, which Anthropic also requires.
+func mergeAdjacentAnthropicMessages(msgs []anthropic.MessageParam) []anthropic.MessageParam {
+	if len(msgs) < 2 {
+		return msgs
+	}
+	out := make([]anthropic.MessageParam, 0, len(msgs))
+	merged := false
+	for _, m := range msgs {
+		if n := len(out); n > 0 && out[n-1].Role == m.Role {
+			out[n-1].Content = append(out[n-1].Content, m.Content...)
+			merged = true
+			continue
+		}
+		m.Content = slices.Clone(m.Content)
+		out = append(out, m)
+	}
+	if !merged {
+		return msgs
+	}
+	for i := range out {
+		if out[i].Role == anthropic.MessageParamRoleUser {
+			slices.SortStableFunc(out[i].Content, func(a, b anthropic.ContentBlockParamUnion) int {
+				return toolResultOrder(a) - toolResultOrder(b)
+			})
+		}
+	}
+	return out
+}
+
+func toolResultOrder(b anthropic.ContentBlockParamUnion) int {
+	if b.OfToolResult != nil {
+		return 0
+	}
+	return 1
+}
+
 // contentItemsToAnthropicContentBlocks converts generic content items into Anthropic
 // content blocks (text/image/document).
 func contentItemsToAnthropicContentBlocks(
@@ -790,7 +986,7 @@ func toolOutputToAnthropicBlocks(
 
 	switch toolOutput.Type {
 	case spec.ToolTypeFunction, spec.ToolTypeCustom:
-		items := contentItemsToAnthropicToolResultBlocks(toolOutput.Contents)
+		items := contentItemsToAnthropicToolResultBlocks(sdkutil.ToolOutputContents(toolOutput, true))
 		if len(items) == 0 {
 			return nil
 		}
@@ -1000,6 +1196,9 @@ func contentItemFileToAnthropicDocumentBlockParam(fileItem *spec.ContentItemFile
 	mime := strings.TrimSpace(fileItem.FileMIME)
 	// Map files to document blocks where possible.
 	switch {
+	case strings.TrimSpace(fileItem.FileID) != "":
+		return &anthropic.DocumentBlockParam{Source: fileDocumentSource(strings.TrimSpace(fileItem.FileID))}
+
 	case data != "" && strings.HasPrefix(mime, "application/pdf"):
 		return &anthropic.DocumentBlockParam{
 			Source: anthropic.DocumentBlockParamSourceUnion{
@@ -1039,6 +1238,7 @@ func toolChoicesToAnthropicTools(
 	ordered, nameMap := sdkutil.BuildToolChoiceNameMapping(toolChoices)
 	out := make([]anthropic.ToolUnionParam, 0, len(ordered))
 	webSearchAdded := false
+	codeExecutionAdded := false
 
 	for _, tw := range ordered {
 		tc := tw.Choice
@@ -1138,6 +1338,13 @@ func toolChoicesToAnthropicTools(
 				OfWebSearchTool20250305: &wsTool,
 			})
 			webSearchAdded = true
+
+		case spec.ToolTypeCodeExecution:
+			if codeExecutionAdded {
+				continue
+			}
+			out = append(out, codeExecutionToolParam())
+			codeExecutionAdded = true
 		}
 
 	}
@@ -1284,6 +1491,17 @@ func outputsFromAnthropicMessage(
 			if id == "" {
 				continue
 			}
+			if string(v.Name) == codeExecutionToolName {
+				choiceID := toolChoiceIDOfType(toolChoiceNameMap, spec.ToolTypeCodeExecution)
+				if choiceID == "" {
+					continue
+				}
+				outs = append(outs, spec.OutputUnion{
+					Kind:                  spec.OutputKindCodeExecutionToolCall,
+					CodeExecutionToolCall: codeExecutionCallFromAnthropic(v, choiceID),
+				})
+				continue
+			}
 
 			var choiceID string
 
@@ -1389,7 +1607,17 @@ func outputsFromAnthropicMessage(
 				)
 			}
 		default:
-			// Future content variants.
+			if content.Type != codeExecutionResultType {
+				// Future content variants.
+				continue
+			}
+			choiceID := toolChoiceIDOfType(toolChoiceNameMap, spec.ToolTypeCodeExecution)
+			if out := codeExecutionOutputFromAnthropic(content.RawJSON(), choiceID); out != nil {
+				outs = append(outs, spec.OutputUnion{
+					Kind:                    spec.OutputKindCodeExecutionToolOutput,
+					CodeExecutionToolOutput: out,
+				})
+			}
 		}
 	}
 
@@ -1425,6 +1653,23 @@ func anthropicCitationsToSpec(
 	return out
 }
 
+// anthropicCitationDeltaToSpec converts a streamed citation into a generic URL citation. Like
+// anthropicCitationsToSpec, only web_search_result_location is supported.
+func anthropicCitationDeltaToSpec(cc anthropic.CitationsDeltaCitationUnion) (spec.Citation, bool) {
+	if cc.Type != string(anthropicSharedConstant.WebSearchResultLocation("").Default()) {
+		return spec.Citation{}, false
+	}
+	return spec.Citation{
+		Kind: spec.CitationKindURL,
+		URLCitation: &spec.URLCitation{
+			URL:            cc.URL,
+			Title:          cc.Title,
+			CitedText:      cc.CitedText,
+			EncryptedIndex: cc.EncryptedIndex,
+		},
+	}, true
+}
+
 // anthropicServerToolInputToWebSearchCallItems converts the server web search
 // input payload into our generic WebSearchToolCall items.
 func anthropicServerToolInputToWebSearchCallItems(
@@ -0,0 +1,229 @@
+package anthropicsdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// newTestAPI returns an initialized adapter for a server running handler.
+func newTestAPI(t *testing.T, handler http.HandlerFunc) *AnthropicMessagesAPI {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	api, err := NewAnthropicMessagesAPI(spec.ProviderParam{
+		Name:                     "anthropic",
+		SDKType:                  spec.ProviderSDKTypeAnthropic,
+		Origin:                   srv.URL,
+		ChatCompletionPathPrefix: spec.DefaultAnthropicChatCompletionPrefix,
+		APIKey:                   "k",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewAnthropicMessagesAPI() error = %v.", err)
+	}
+	if err := api.InitLLM(t.Context()); err != nil {
+		t.Fatalf("InitLLM() error = %v.", err)
+	}
+	return api
+}
+
+// userInputs returns a single user message holding text.
+func userInputs(text string) []spec.InputUnion {
+	return []spec.InputUnion{{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role: spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			}},
+		},
+	}}
+}
+
+func TestAnthropicMergesAdjacentSameRoleMessages(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	text := func(text string) []spec.InputOutputContentItemUnion {
+		return []spec.InputOutputContentItemUnion{{
+			Kind:     spec.ContentItemKindText,
+			TextItem: &spec.ContentItemText{Text: text},
+		}}
+	}
+	toolOutput := func(callID string) spec.InputUnion {
+		return spec.InputUnion{Kind: spec.InputKindFunctionToolOutput, FunctionToolOutput: &spec.ToolOutput{
+			Type: spec.ToolTypeFunction, ChoiceID: "w", CallID: callID,
+			Contents: []spec.ToolOutputItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: "sunny"},
+			}},
+		}}
+	}
+	toolCall := func(id string) spec.InputUnion {
+		return spec.InputUnion{Kind: spec.InputKindFunctionToolCall, FunctionToolCall: &spec.ToolCall{
+			Type: spec.ToolTypeFunction, ChoiceID: "w", ID: id, CallID: id, Name: "weather", Arguments: `{}`,
+		}}
+	}
+	resp, err := api.FetchCompletion(t.Context(), &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "claude", MaxOutputLength: 64},
+		Inputs: []spec.InputUnion{
+			{
+				Kind:         spec.InputKindInputMessage,
+				InputMessage: &spec.InputOutputContent{Role: spec.RoleUser, Contents: text("Weather?")},
+			},
+			{
+				Kind:          spec.InputKindOutputMessage,
+				OutputMessage: &spec.InputOutputContent{Role: spec.RoleAssistant, Contents: text("Checking.")},
+			},
+			toolCall("tc1"),
+			toolCall("tc2"),
+			toolOutput("tc1"),
+			{
+				Kind:         spec.InputKindInputMessage,
+				InputMessage: &spec.InputOutputContent{Role: spec.RoleUser, Contents: text("Also tomorrow?")},
+			},
+			toolOutput("tc2"),
+		},
+	}, &spec.FetchCompletionOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+
+	dd, _ := resp.DebugDetails.(map[string]any)
+	req, _ := dd["request"].(map[string]any)
+	body, _ := req["body"].(map[string]any)
+	msgs, _ := body["messages"].([]any)
+	want := []struct {
+		role  string
+		types []string
+	}{
+		{role: "user", types: []string{"text"}},
+		{role: "assistant", types: []string{"text", "tool_use", "tool_use"}},
+		{role: "user", types: []string{"tool_result", "tool_result", "text"}},
+	}
+	if len(msgs) != len(want) {
+		t.Fatalf("messages = %v, want %d alternating messages.", msgs, len(want))
+	}
+	for i, w := range want {
+		msg, _ := msgs[i].(map[string]any)
+		content, _ := msg["content"].([]any)
+		if msg["role"] != w.role || len(content) != len(w.types) {
+			t.Fatalf("messages[%d] = %v, want role %s with %v blocks.", i, msg, w.role, w.types)
+		}
+		for j, typ := range w.types {
+			if block, _ := content[j].(map[string]any); block["type"] != typ {
+				t.Fatalf("messages[%d].content[%d] = %v, want type %s.", i, j, block, typ)
+			}
+		}
+	}
+}
+
+func TestAnthropicParallelToolCallRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	const message = `{"id":"msg1","type":"message","role":"assistant","model":"m","stop_reason":"tool_use",` +
+		`"content":[{"type":"text","text":"Checking both."},` +
+		`{"type":"tool_use","id":"tu1","name":"weather","input":{"city":"Paris"}},` +
+		`{"type":"tool_use","id":"tu2","name":"weather","input":{"city":"Rome"}}],` +
+		`"usage":{"input_tokens":1,"output_tokens":1}}`
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(message))
+	})
+
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m", MaxOutputLength: 64},
+		Inputs:     userInputs("Weather in Paris and Rome?"),
+		ToolChoices: []spec.ToolChoice{{
+			Type: spec.ToolTypeFunction, ID: "w", Name: "weather",
+			Arguments: map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{}}},
+		}},
+	}
+	resp, err := api.FetchCompletion(t.Context(), req, nil)
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+
+	// Replay the turn: the assistant text and both calls, then one output per call.
+	var calls []*spec.ToolCall
+	for _, out := range resp.Outputs {
+		switch {
+		case out.OutputMessage != nil:
+			req.Inputs = append(req.Inputs, spec.InputUnion{
+				Kind:          spec.InputKindOutputMessage,
+				OutputMessage: out.OutputMessage,
+			})
+		case out.FunctionToolCall != nil:
+			calls = append(calls, out.FunctionToolCall)
+			req.Inputs = append(req.Inputs, spec.InputUnion{
+				Kind:             spec.InputKindFunctionToolCall,
+				FunctionToolCall: out.FunctionToolCall,
+			})
+		}
+	}
+	if len(calls) != 2 {
+		t.Fatalf("Outputs = %+v, want two parallel tool calls.", resp.Outputs)
+	}
+	for _, call := range calls {
+		req.Inputs = append(req.Inputs, spec.InputUnion{
+			Kind: spec.InputKindFunctionToolOutput,
+			FunctionToolOutput: &spec.ToolOutput{
+				Type: spec.ToolTypeFunction, ChoiceID: call.ChoiceID, CallID: call.CallID, Name: call.Name,
+				Contents: []spec.ToolOutputItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: "sunny"},
+				}},
+			},
+		})
+	}
+	if _, err := api.FetchCompletion(t.Context(), req, nil); err != nil {
+		t.Fatalf("FetchCompletion() replay error = %v.", err)
+	}
+
+	mu.Lock()
+	msgs, _ := bodies[len(bodies)-1]["messages"].([]any)
+	mu.Unlock()
+	var got []string
+	for _, m := range msgs {
+		msg, _ := m.(map[string]any)
+		content, _ := msg["content"].([]any)
+		blocks := make([]string, 0, len(content))
+		for _, c := range content {
+			block, _ := c.(map[string]any)
+			id, _ := block["id"].(string)
+			if id == "" {
+				id, _ = block["tool_use_id"].(string)
+			}
+			blocks = append(blocks, fmt.Sprint(block["type"], ":", id))
+		}
+		got = append(got, fmt.Sprint(msg["role"], blocks))
+	}
+	want := []string{
+		"user[text:]",
+		"assistant[text: tool_use:tu1 tool_use:tu2]",
+		"user[tool_result:tu1 tool_result:tu2]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("replayed messages = %q, want %q.", got, want)
+	}
+}
@@ -0,0 +1,244 @@
+package anthropicsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/anthropics/anthropic-sdk-go/packages/param"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// Message Batches limits, checked before submitting so that oversized batches fail fast instead of after upload.
+const (
+	maxBatchRequests  = 100_000
+	maxBatchBodyBytes = 256 << 20
+)
+
+var batchCustomIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// SubmitBatch creates a Message Batch. Each request is converted as FetchCompletion would convert it; Stream is
+// ignored.
+func (api *AnthropicMessagesAPI) SubmitBatch(
+	ctx context.Context,
+	reqs []spec.BatchRequest,
+) (*spec.BatchStatus, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("anthropic messages api LLM: empty batch")
+	}
+	if len(reqs) > maxBatchRequests {
+		return nil, fmt.Errorf(
+			"anthropic messages api LLM: batch has %d requests, the limit is %d",
+			len(reqs),
+			maxBatchRequests,
+		)
+	}
+	client := api.snapshotClient()
+	if client == nil {
+		return nil, errors.New("anthropic messages api LLM: client not initialized")
+	}
+
+	var (
+		body        anthropic.MessageBatchNewParams
+		toolChoices = map[string]map[string]spec.ToolChoice{}
+		seen        = make(map[string]struct{}, len(reqs))
+		filesOpts   []option.RequestOption
+		codeOpts    []option.RequestOption
+	)
+	for _, r := range reqs {
+		if !batchCustomIDPattern.MatchString(r.CustomID) {
+			return nil, fmt.Errorf(
+				"anthropic messages api LLM: invalid batch custom id %q: want 1-64 letters, digits, '-' or '_'",
+				r.CustomID,
+			)
+		}
+		if _, dup := seen[r.CustomID]; dup {
+			return nil, fmt.Errorf("anthropic messages api LLM: duplicate batch custom id %q", r.CustomID)
+		}
+		seen[r.CustomID] = struct{}{}
+		if r.Request == nil || len(r.Request.Inputs) == 0 || r.Request.ModelParam.Name == "" {
+			return nil, fmt.Errorf("anthropic messages api LLM: empty completion data for %q", r.CustomID)
+		}
+
+		params, nameMap, _, err := buildMessageParams(ctx, r.Request)
+		if err != nil {
+			return nil, fmt.Errorf("anthropic messages api LLM: batch request %q: %w", r.CustomID, err)
+		}
+		// Beta headers apply to the whole batch; add each one once.
+		if filesOpts == nil {
+			filesOpts = filesRequestOptions(r.Request.Inputs)
+		}
+		if codeOpts == nil && len(nameMap) > 0 {
+			codeOpts = codeExecutionRequestOptions(r.Request.ToolChoices)
+		}
+		if len(nameMap) > 0 {
+			toolChoices[r.CustomID] = nameMap
+		}
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		body.Requests = append(body.Requests, anthropic.MessageBatchNewParamsRequest{
+			CustomID: r.CustomID,
+			Params:   param.Override[anthropic.MessageBatchNewParamsRequestParams](json.RawMessage(raw)),
+		})
+	}
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxBatchBodyBytes {
+		return nil, fmt.Errorf(
+			"anthropic messages api LLM: batch is %d bytes, the limit is %d",
+			len(raw),
+			maxBatchBodyBytes,
+		)
+	}
+
+	batch, err := client.Messages.Batches.New(ctx, body, append(filesOpts, codeOpts...)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(toolChoices) > 0 {
+		api.mu.Lock()
+		if api.batchToolChoices == nil {
+			api.batchToolChoices = map[string]map[string]map[string]spec.ToolChoice{}
+		}
+		api.batchToolChoices[batch.ID] = toolChoices
+		api.mu.Unlock()
+	}
+	return batchStatusFromAnthropic(batch), nil
+}
+
+// GetBatch retrieves the status of a Message Batch.
+func (api *AnthropicMessagesAPI) GetBatch(ctx context.Context, batchID string) (*spec.BatchStatus, error) {
+	if strings.TrimSpace(batchID) == "" {
+		return nil, errors.New("anthropic messages api LLM: empty batch id")
+	}
+	client := api.snapshotClient()
+	if client == nil {
+		return nil, errors.New("anthropic messages api LLM: client not initialized")
+	}
+	batch, err := client.Messages.Batches.Get(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return batchStatusFromAnthropic(batch), nil
+}
+
+// CancelBatch cancels a Message Batch. Requests already being processed may still complete.
+func (api *AnthropicMessagesAPI) CancelBatch(ctx context.Context, batchID string) (*spec.BatchStatus, error) {
+	if strings.TrimSpace(batchID) == "" {
+		return nil, errors.New("anthropic messages api LLM: empty batch id")
+	}
+	client := api.snapshotClient()
+	if client == nil {
+		return nil, errors.New("anthropic messages api LLM: client not initialized")
+	}
+	batch, err := client.Messages.Batches.Cancel(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return batchStatusFromAnthropic(batch), nil
+}
+
+// BatchResults streams the results of an ended Message Batch. Tool calls are matched to their ToolChoices only for
+// batches submitted through this provider instance; other batches report text and thinking outputs only.
+func (api *AnthropicMessagesAPI) BatchResults(
+	ctx context.Context,
+	batchID string,
+	handler func(spec.BatchResult) error,
+) error {
+	if strings.TrimSpace(batchID) == "" || handler == nil {
+		return errors.New("anthropic messages api LLM: empty batch id or handler")
+	}
+	api.mu.RLock()
+	client := api.client
+	var providerName spec.ProviderName
+	if api.ProviderParam != nil {
+		providerName = api.ProviderParam.Name
+	}
+	toolChoices := api.batchToolChoices[batchID]
+	api.mu.RUnlock()
+	if client == nil {
+		return errors.New("anthropic messages api LLM: client not initialized")
+	}
+
+	stream := client.Messages.Batches.ResultsStreaming(ctx, batchID)
+	defer stream.Close()
+	for stream.Next() {
+		item := stream.Current()
+		if err := handler(batchResultFromAnthropic(providerName, item, toolChoices[item.CustomID])); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
+
+func batchResultFromAnthropic(
+	providerName spec.ProviderName,
+	item anthropic.MessageBatchIndividualResponse,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) spec.BatchResult {
+	res := spec.BatchResult{CustomID: item.CustomID}
+	switch item.Result.Type {
+	case "succeeded":
+		msg := item.Result.Message
+		res.Response = &spec.FetchCompletionResponse{
+			Outputs: outputsFromAnthropicMessage(&msg, toolChoiceNameMap),
+			Usage:   usageFromAnthropicMessage(&msg),
+		}
+	case "errored":
+		e := item.Result.Error.Error
+		res.Error = sdkutil.NewError(providerName, errors.New(e.Message), 0, e.Type, item.Result.Error.RawJSON())
+	case "canceled":
+		res.Error = &spec.Error{
+			Code:     spec.ErrorCodeCanceled,
+			Message:  "batch request canceled",
+			Provider: providerName,
+		}
+	case "expired":
+		res.Error = &spec.Error{
+			Code:     spec.ErrorCodeTimeout,
+			Message:  "batch request expired before processing",
+			Provider: providerName,
+		}
+	default:
+		res.Error = &spec.Error{
+			Code:     spec.ErrorCodeUnknown,
+			Message:  fmt.Sprintf("unknown batch result type %q", item.Result.Type),
+			Provider: providerName,
+		}
+	}
+	return res
+}
+
+func batchStatusFromAnthropic(batch *anthropic.MessageBatch) *spec.BatchStatus {
+	status := spec.BatchStatusInProgress
+	switch batch.ProcessingStatus {
+	case anthropic.MessageBatchProcessingStatusCanceling:
+		status = spec.BatchStatusCanceling
+	case anthropic.MessageBatchProcessingStatusEnded:
+		status = spec.BatchStatusEnded
+	}
+	counts := batch.RequestCounts
+	return &spec.BatchStatus{
+		ID:         batch.ID,
+		Status:     status,
+		Processing: counts.Processing,
+		Succeeded:  counts.Succeeded,
+		Errored:    counts.Errored,
+		Canceled:   counts.Canceled,
+		Expired:    counts.Expired,
+		CreatedAt:  batch.CreatedAt,
+		EndedAt:    batch.EndedAt,
+		ExpiresAt:  batch.ExpiresAt,
+	}
+}
@@ -0,0 +1,181 @@
+package anthropicsdk
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/anthropics/anthropic-sdk-go/packages/param"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// Code execution is a beta server tool: the non-beta SDK types have no variants for it, so its tool definition and
+// blocks are sent as raw JSON and its result blocks are decoded from the raw response JSON.
+const (
+	codeExecutionToolType   = "code_execution_20250522"
+	codeExecutionToolName   = "code_execution"
+	codeExecutionResultType = "code_execution_tool_result"
+	codeExecutionBeta       = "code-execution-2025-05-22"
+)
+
+func codeExecutionToolParam() anthropic.ToolUnionParam {
+	return param.Override[anthropic.ToolUnionParam](map[string]any{
+		"type": codeExecutionToolType,
+		"name": codeExecutionToolName,
+	})
+}
+
+// codeExecutionRequestOptions returns the beta header needed when tools include code execution.
+func codeExecutionRequestOptions(toolChoices []spec.ToolChoice) []option.RequestOption {
+	for _, tc := range toolChoices {
+		if tc.Type == spec.ToolTypeCodeExecution {
+			return []option.RequestOption{option.WithHeaderAdd("anthropic-beta", codeExecutionBeta)}
+		}
+	}
+	return nil
+}
+
+// codeExecutionCallToAnthropicBlock converts a code execution ToolCall into a server_tool_use block.
+func codeExecutionCallToAnthropicBlock(call *spec.ToolCall) *anthropic.ContentBlockParamUnion {
+	if call == nil || strings.TrimSpace(call.ID) == "" {
+		return nil
+	}
+	args := strings.TrimSpace(call.Arguments)
+	if args == "" {
+		args = "{}"
+	}
+	block := param.Override[anthropic.ContentBlockParamUnion](map[string]any{
+		"type":  "server_tool_use",
+		"id":    call.ID,
+		"name":  codeExecutionToolName,
+		"input": json.RawMessage(args),
+	})
+	return &block
+}
+
+// codeExecutionOutputToAnthropicBlock converts a code execution ToolOutput into a code_execution_tool_result block.
+func codeExecutionOutputToAnthropicBlock(out *spec.ToolOutput) *anthropic.ContentBlockParamUnion {
+	if out == nil || strings.TrimSpace(out.CallID) == "" || len(out.CodeExecutionToolOutputItems) == 0 {
+		return nil
+	}
+
+	var content map[string]any
+	files := []map[string]any{}
+	for _, it := range out.CodeExecutionToolOutputItems {
+		switch {
+		case it.Kind == spec.CodeExecutionToolOutputKindError && it.ErrorItem != nil:
+			content = map[string]any{"type": "code_execution_tool_result_error", "error_code": it.ErrorItem.Code}
+		case it.Kind == spec.CodeExecutionToolOutputKindResult && it.ResultItem != nil && content == nil:
+			content = map[string]any{
+				"type":        "code_execution_result",
+				"stdout":      it.ResultItem.Stdout,
+				"stderr":      it.ResultItem.Stderr,
+				"return_code": it.ResultItem.ReturnCode,
+			}
+		case it.Kind == spec.CodeExecutionToolOutputKindFile && it.FileItem != nil:
+			files = append(files, map[string]any{"type": "code_execution_output", "file_id": it.FileItem.FileID})
+		}
+	}
+	if content == nil {
+		return nil
+	}
+	if content["type"] == "code_execution_result" {
+		content["content"] = files
+	}
+	block := param.Override[anthropic.ContentBlockParamUnion](map[string]any{
+		"type":        codeExecutionResultType,
+		"tool_use_id": out.CallID,
+		"content":     content,
+	})
+	return &block
+}
+
+// codeExecutionCallFromAnthropic converts a code_execution server_tool_use block into a ToolCall.
+func codeExecutionCallFromAnthropic(v anthropic.ServerToolUseBlock, choiceID string) *spec.ToolCall {
+	args, err := json.Marshal(v.Input)
+	if err != nil || v.Input == nil {
+		args = []byte("{}")
+	}
+	return &spec.ToolCall{
+		ChoiceID:  choiceID,
+		Type:      spec.ToolTypeCodeExecution,
+		Role:      spec.RoleAssistant,
+		ID:        v.ID,
+		CallID:    v.ID,
+		Name:      codeExecutionToolName,
+		Arguments: string(args),
+		Status:    spec.StatusCompleted,
+	}
+}
+
+type anthropicCodeExecutionResultBlock struct {
+	ToolUseID string `json:"tool_use_id"`
+	Content   struct {
+		Type       string `json:"type"`
+		ErrorCode  string `json:"error_code"`
+		Stdout     string `json:"stdout"`
+		Stderr     string `json:"stderr"`
+		ReturnCode int64  `json:"return_code"`
+		Content    []struct {
+			FileID string `json:"file_id"`
+		} `json:"content"`
+	} `json:"content"`
+}
+
+// codeExecutionOutputFromAnthropic decodes a raw code_execution_tool_result block into a ToolOutput.
+func codeExecutionOutputFromAnthropic(raw, choiceID string) *spec.ToolOutput {
+	var block anthropicCodeExecutionResultBlock
+	if err := json.Unmarshal([]byte(raw), &block); err != nil || block.ToolUseID == "" {
+		logutil.Debug("anthropic: could not decode code execution result", "error", err)
+		return nil
+	}
+	out := &spec.ToolOutput{
+		ChoiceID: choiceID,
+		Type:     spec.ToolTypeCodeExecution,
+		Role:     spec.RoleAssistant,
+		ID:       block.ToolUseID,
+		CallID:   block.ToolUseID,
+		Status:   spec.StatusCompleted,
+		Name:     codeExecutionToolName,
+	}
+	c := block.Content
+	if c.ErrorCode != "" {
+		out.IsError = true
+		out.CodeExecutionToolOutputItems = []spec.CodeExecutionToolOutputItemUnion{{
+			Kind:      spec.CodeExecutionToolOutputKindError,
+			ErrorItem: &spec.CodeExecutionToolOutputError{Code: c.ErrorCode},
+		}}
+		return out
+	}
+	out.CodeExecutionToolOutputItems = []spec.CodeExecutionToolOutputItemUnion{{
+		Kind: spec.CodeExecutionToolOutputKindResult,
+		ResultItem: &spec.CodeExecutionToolOutputResult{
+			Stdout:     c.Stdout,
+			Stderr:     c.Stderr,
+			ReturnCode: c.ReturnCode,
+		},
+	}}
+	for _, f := range c.Content {
+		if f.FileID != "" {
+			out.CodeExecutionToolOutputItems = append(out.CodeExecutionToolOutputItems,
+				spec.CodeExecutionToolOutputItemUnion{
+					Kind:     spec.CodeExecutionToolOutputKindFile,
+					FileItem: &spec.CodeExecutionToolOutputFile{FileID: f.FileID},
+				})
+		}
+	}
+	return out
+}
+
+// toolChoiceIDOfType returns the ID of the first tool choice of type t.
+func toolChoiceIDOfType(toolChoiceNameMap map[string]spec.ToolChoice, t spec.ToolType) string {
+	for _, tc := range toolChoiceNameMap {
+		if tc.Type == t {
+			return tc.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package anthropicsdk
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestAnthropicCodeExecution(t *testing.T) {
+	t.Parallel()
+
+	const (
+		callBlock = `{"type":"server_tool_use","id":"srv1","name":"code_execution","input":{"code":"print(1)"}}`
+		// The SSE stream delivers the call input through input_json_delta events.
+		callStart   = `{"type":"server_tool_use","id":"srv1","name":"code_execution","input":{}}`
+		resultBlock = `{"type":"code_execution_tool_result","tool_use_id":"srv1","content":` +
+			`{"type":"code_execution_result","stdout":"1\n","stderr":"","return_code":0,` +
+			`"content":[{"type":"code_execution_output","file_id":"file_1"}]}}`
+		textBlock = `{"type":"text","text":"done"}`
+		usage     = `"usage":{"input_tokens":1,"output_tokens":1}`
+	)
+	message := `{"id":"msg1","type":"message","role":"assistant","model":"m","stop_reason":"end_turn",` +
+		`"content":[` + callBlock + `,` + resultBlock + `,` + textBlock + `],` + usage + `}`
+	sse := func(event, data string) string { return "event: " + event + "\ndata: " + data + "\n\n" }
+	stream := sse("message_start", `{"type":"message_start","message":{"id":"msg1","type":"message",`+
+		`"role":"assistant","model":"m","content":[],`+usage+`}}`) +
+		sse("content_block_start", `{"type":"content_block_start","index":0,"content_block":`+callStart+`}`) +
+		sse("content_block_delta", `{"type":"content_block_delta","index":0,"delta":`+
+			`{"type":"input_json_delta","partial_json":"{\"code\":\"print(1)\"}"}}`) +
+		sse("content_block_stop", `{"type":"content_block_stop","index":0}`) +
+		sse("content_block_start", `{"type":"content_block_start","index":1,"content_block":`+resultBlock+`}`) +
+		sse("content_block_stop", `{"type":"content_block_stop","index":1}`) +
+		sse("content_block_start", `{"type":"content_block_start","index":2,"content_block":`+
+			`{"type":"text","text":""}}`) +
+		sse("content_block_delta", `{"type":"content_block_delta","index":2,"delta":`+
+			`{"type":"text_delta","text":"done"}}`) +
+		sse("content_block_stop", `{"type":"content_block_stop","index":2}`) +
+		sse("message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn"},`+usage+`}`) +
+		sse("message_stop", `{"type":"message_stop"}`)
+
+	var (
+		mu       sync.Mutex
+		bodies   []string
+		betaHdrs []string
+	)
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		betaHdrs = append(betaHdrs, r.Header.Get("Anthropic-Beta"))
+		mu.Unlock()
+		if strings.Contains(string(b), `"stream":true`) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(stream))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(message))
+	})
+
+	wantOutput := &spec.ToolOutput{
+		ChoiceID: "ce", Type: spec.ToolTypeCodeExecution, Role: spec.RoleAssistant, ID: "srv1", CallID: "srv1",
+		Status: spec.StatusCompleted, Name: "code_execution",
+		CodeExecutionToolOutputItems: []spec.CodeExecutionToolOutputItemUnion{
+			{
+				Kind:       spec.CodeExecutionToolOutputKindResult,
+				ResultItem: &spec.CodeExecutionToolOutputResult{Stdout: "1\n"},
+			},
+			{Kind: spec.CodeExecutionToolOutputKindFile, FileItem: &spec.CodeExecutionToolOutputFile{FileID: "file_1"}},
+		},
+	}
+	tests := []struct {
+		name   string
+		stream bool
+	}{
+		{name: "NonStreaming."},
+		{name: "Streaming.", stream: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m", Stream: tc.stream},
+				Inputs:     userInputs("run it"),
+				ToolChoices: []spec.ToolChoice{
+					{Type: spec.ToolTypeCodeExecution, ID: "ce", Name: "code_execution"},
+				},
+			}
+			var opts *spec.FetchCompletionOptions
+			if tc.stream {
+				opts = &spec.FetchCompletionOptions{StreamHandler: func(spec.StreamEvent) error { return nil }}
+			}
+			resp, err := api.FetchCompletion(t.Context(), req, opts)
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			if len(resp.Outputs) != 3 || resp.Outputs[0].CodeExecutionToolCall == nil {
+				t.Fatalf("Outputs = %+v, want call, result and text.", resp.Outputs)
+			}
+			if got := resp.Outputs[0].CodeExecutionToolCall.Arguments; got != `{"code":"print(1)"}` {
+				t.Fatalf("call Arguments = %s, want the code input.", got)
+			}
+			if got := resp.Outputs[1].CodeExecutionToolOutput; !reflect.DeepEqual(got, wantOutput) {
+				t.Fatalf("CodeExecutionToolOutput = %+v, want = %+v.", got, wantOutput)
+			}
+
+			// Replaying the items sends them back as raw blocks.
+			req.ModelParam.Stream = false
+			req.Inputs = append(req.Inputs,
+				spec.InputUnion{
+					Kind:                  spec.InputKindCodeExecutionToolCall,
+					CodeExecutionToolCall: resp.Outputs[0].CodeExecutionToolCall,
+				},
+				spec.InputUnion{
+					Kind:                    spec.InputKindCodeExecutionToolOutput,
+					CodeExecutionToolOutput: resp.Outputs[1].CodeExecutionToolOutput,
+				},
+			)
+			if _, err := api.FetchCompletion(t.Context(), req, nil); err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			mu.Lock()
+			body, beta := bodies[len(bodies)-1], betaHdrs[len(betaHdrs)-1]
+			mu.Unlock()
+			for _, want := range []string{
+				`"type":"code_execution_20250522"`,
+				`"type":"server_tool_use"`,
+				`"code":"print(1)"`,
+				`"type":"code_execution_tool_result"`,
+				`"file_id":"file_1"`,
+			} {
+				if !strings.Contains(body, want) {
+					t.Fatalf("request body = %s, want it to contain %s.", body, want)
+				}
+			}
+			if !strings.Contains(beta, "code-execution-2025-05-22") {
+				t.Fatalf("anthropic-beta = %q, want the code execution beta.", beta)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package anthropicsdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/anthropics/anthropic-sdk-go/packages/param"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// The Files API is in beta: uploads, deletes and messages that reference a file by ID need the beta header, and
+// the non-beta document source union has no file variant, so file sources are sent as raw JSON.
+const filesBeta = anthropic.AnthropicBetaFilesAPI2025_04_14
+
+// UploadFile stores a file with the Files API.
+func (api *AnthropicMessagesAPI) UploadFile(
+	ctx context.Context,
+	req *spec.UploadFileRequest,
+) (*spec.ProviderFile, error) {
+	if req == nil || req.Reader == nil || strings.TrimSpace(req.FileName) == "" {
+		return nil, errors.New("anthropic messages api LLM: empty upload file request")
+	}
+	client := api.snapshotClient()
+	if client == nil {
+		return nil, errors.New("anthropic messages api LLM: client not initialized")
+	}
+	meta, err := client.Beta.Files.Upload(ctx, anthropic.BetaFileUploadParams{
+		File:  anthropic.File(req.Reader, req.FileName, req.FileMIME),
+		Betas: []anthropic.AnthropicBeta{filesBeta},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &spec.ProviderFile{
+		ID:        meta.ID,
+		FileName:  meta.Filename,
+		FileMIME:  meta.MimeType,
+		SizeBytes: meta.SizeBytes,
+		CreatedAt: meta.CreatedAt,
+	}, nil
+}
+
+// DeleteFile deletes a file stored with the Files API.
+func (api *AnthropicMessagesAPI) DeleteFile(ctx context.Context, fileID string) error {
+	if strings.TrimSpace(fileID) == "" {
+		return errors.New("anthropic messages api LLM: empty file id")
+	}
+	client := api.snapshotClient()
+	if client == nil {
+		return errors.New("anthropic messages api LLM: client not initialized")
+	}
+	_, err := client.Beta.Files.Delete(ctx, fileID, anthropic.BetaFileDeleteParams{
+		Betas: []anthropic.AnthropicBeta{filesBeta},
+	})
+	return err
+}
+
+func (api *AnthropicMessagesAPI) snapshotClient() *anthropic.Client {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.client
+}
+
+// fileDocumentSource returns a document source referencing an uploaded file.
+func fileDocumentSource(fileID string) anthropic.DocumentBlockParamSourceUnion {
+	return param.Override[anthropic.DocumentBlockParamSourceUnion](map[string]any{
+		"type":    "file",
+		"file_id": fileID,
+	})
+}
+
+// filesRequestOptions returns the beta header needed when inputs reference uploaded files.
+func filesRequestOptions(inputs []spec.InputUnion) []option.RequestOption {
+	hasFileID := func(f *spec.ContentItemFile) bool { return f != nil && strings.TrimSpace(f.FileID) != "" }
+	for _, in := range inputs {
+		if msg := in.InputMessage; msg != nil {
+			for _, c := range msg.Contents {
+				if hasFileID(c.FileItem) {
+					return []option.RequestOption{option.WithHeaderAdd("anthropic-beta", string(filesBeta))}
+				}
+			}
+		}
+		for _, out := range []*spec.ToolOutput{in.FunctionToolOutput, in.CustomToolOutput} {
+			if out == nil {
+				continue
+			}
+			for _, c := range out.Contents {
+				if hasFileID(c.FileItem) {
+					return []option.RequestOption{option.WithHeaderAdd("anthropic-beta", string(filesBeta))}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -184,11 +184,15 @@ func isAssistantAuthoredItem(in spec.InputUnion) bool {
 		return in.OutputMessage != nil && in.OutputMessage.Role == spec.RoleAssistant
 	case spec.InputKindReasoningMessage:
 		return in.ReasoningMessage != nil
-	case spec.InputKindFunctionToolCall, spec.InputKindCustomToolCall, spec.InputKindWebSearchToolCall:
+	case spec.InputKindFunctionToolCall, spec.InputKindCustomToolCall, spec.InputKindWebSearchToolCall,
+		spec.InputKindCodeExecutionToolCall:
 		return true
 	case spec.InputKindWebSearchToolOutput:
 		// In our Anthropic adapter, web_search_tool_result is an assistant block.
 		return in.WebSearchToolOutput != nil
+	case spec.InputKindCodeExecutionToolOutput:
+		// Likewise code_execution_tool_result.
+		return in.CodeExecutionToolOutput != nil
 	default:
 		return false
 	}
@@ -275,13 +279,21 @@ func applyAnthropicThinkingPolicy(
 			effectiveBudget = anthropicDefaultThinkingBudget
 		}
 		params.Thinking = anthropic.ThinkingConfigParamOfEnabled(effectiveBudget)
-		// Do not set temperature when thinking is enabled.
+		// Do not set temperature, top_p or top_k when thinking is enabled.
 		return
 	}
 
-	// Thinking disabled => temperature is allowed.
+	// Thinking disabled => sampling parameters are allowed, but newer models reject temperature and top_p together.
 	if mp.Temperature != nil {
 		params.Temperature = anthropic.Float(*mp.Temperature)
+		if mp.TopP != nil {
+			logutil.Warn("anthropic: temperature and top_p both set; dropping top_p", "model", string(mp.Name))
+		}
+	} else if mp.TopP != nil {
+		params.TopP = anthropic.Float(*mp.TopP)
+	}
+	if mp.TopK != nil {
+		params.TopK = anthropic.Int(int64(*mp.TopK))
 	}
 }
 
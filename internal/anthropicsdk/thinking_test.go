@@ -0,0 +1,53 @@
+package anthropicsdk
+
+import (
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestApplyAnthropicThinkingPolicySampling(t *testing.T) {
+	t.Parallel()
+
+	temperature, topP, topK := 0.2, 0.9, 40
+	tests := []struct {
+		name            string
+		mp              spec.ModelParam
+		wantTemperature bool
+		wantTopP        bool
+		wantTopK        bool
+	}{
+		{
+			name:     "TopPOnly.",
+			mp:       spec.ModelParam{Name: "m", TopP: &topP, TopK: &topK},
+			wantTopP: true, wantTopK: true,
+		},
+		{
+			name:            "TemperatureWinsOverTopP.",
+			mp:              spec.ModelParam{Name: "m", Temperature: &temperature, TopP: &topP},
+			wantTemperature: true,
+		},
+		{
+			name: "ThinkingEnabled.",
+			mp: spec.ModelParam{
+				Name: "m", Temperature: &temperature, TopP: &topP, TopK: &topK,
+				Reasoning: &spec.ReasoningParam{Type: spec.ReasoningTypeHybridWithTokens, Tokens: 2048},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var params anthropic.MessageNewParams
+			applyAnthropicThinkingPolicy(&params, &tc.mp, anthropicThinkingAnalysis{})
+			if params.Temperature.Valid() != tc.wantTemperature || params.TopP.Valid() != tc.wantTopP ||
+				params.TopK.Valid() != tc.wantTopK {
+				t.Fatalf("temperature = %v, top_p = %v, top_k = %v, want set = %v, %v, %v.",
+					params.Temperature, params.TopP, params.TopK, tc.wantTemperature, tc.wantTopP, tc.wantTopK)
+			}
+		})
+	}
+}
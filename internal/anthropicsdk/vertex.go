@@ -0,0 +1,72 @@
+package anthropicsdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const vertexAnthropicVersion = "vertex-2023-10-16"
+
+// vertexMiddleware rewrites Messages API requests to Claude-on-Vertex publisher model routes. Vertex takes the model
+// from the path and the API version from the body, so "model" is moved out of the body and "anthropic_version" is
+// added.
+func vertexMiddleware(
+	cfg *spec.VertexAI,
+) func(*http.Request, func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		if req.Method != http.MethodPost || req.Body == nil || req.Body == http.NoBody {
+			return next(req)
+		}
+		var method string
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/v1/messages"):
+			method = "rawPredict"
+		case strings.HasSuffix(req.URL.Path, "/v1/messages/count_tokens"):
+			method = "count-tokens:rawPredict"
+		default:
+			return next(req)
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		var payload map[string]json.RawMessage
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+		var model string
+		_ = json.Unmarshal(payload["model"], &model)
+		var stream bool
+		_ = json.Unmarshal(payload["stream"], &stream)
+		if stream && method == "rawPredict" {
+			method = "streamRawPredict"
+		}
+		if method == "rawPredict" || method == "streamRawPredict" {
+			delete(payload, "model")
+		}
+		if _, ok := payload["anthropic_version"]; !ok {
+			payload["anthropic_version"] = json.RawMessage(`"` + vertexAnthropicVersion + `"`)
+		}
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+
+		if method == "count-tokens:rawPredict" {
+			req.URL.Path = cfg.ProjectPath() + "/publishers/anthropic/models/count-tokens:rawPredict"
+		} else {
+			req.URL.Path = cfg.ProjectPath() + "/publishers/anthropic/models/" + model + ":" + method
+		}
+		req.URL.RawPath = ""
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+		req.ContentLength = int64(len(body))
+		return next(req)
+	}
+}
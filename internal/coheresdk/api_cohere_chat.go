@@ -0,0 +1,575 @@
+package coheresdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v3/packages/ssestream"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// CohereChatAPI struct that implements the CompletionProvider interface for Cohere's v2 Chat API.
+// Requests are plain JSON over net/http; streams are Server-Sent Events.
+type CohereChatAPI struct {
+	ProviderParam *spec.ProviderParam
+	debugger      spec.CompletionDebugger
+	client        *http.Client
+	mu            sync.RWMutex
+}
+
+func NewCohereChatAPI(
+	pi spec.ProviderParam,
+	debugger spec.CompletionDebugger,
+) (*CohereChatAPI, error) {
+	if pi.Name == "" {
+		return nil, errors.New("cohere chat api LLM: invalid args")
+	}
+	return &CohereChatAPI{
+		ProviderParam: &pi,
+		debugger:      debugger,
+	}, nil
+}
+
+func (api *CohereChatAPI) InitLLM(ctx context.Context) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.ProviderParam == nil {
+		api.client = nil
+		return errors.New("cohere chat api LLM: no ProviderParam found")
+	}
+	if api.ProviderParam.APIKey == "" && api.ProviderParam.Credentials == nil {
+		logutil.Debug(
+			"cohere chat api LLM: skipping init because API key is empty",
+			"name", string(api.ProviderParam.Name),
+		)
+		api.client = nil
+		return nil
+	}
+
+	client := &http.Client{}
+	if httpClient := sdkutil.HTTPClient(api.ProviderParam, api.debugger); httpClient != nil {
+		client = httpClient
+	}
+	api.client = client
+	logutil.Info(
+		"cohere chat api LLM provider initialized",
+		"name", string(api.ProviderParam.Name),
+		"URL", chatURL(api.ProviderParam),
+	)
+	return nil
+}
+
+func (api *CohereChatAPI) DeInitLLM(ctx context.Context) error {
+	api.mu.Lock()
+	var name spec.ProviderName
+	if api.ProviderParam != nil {
+		name = api.ProviderParam.Name
+	}
+	api.client = nil
+	api.mu.Unlock()
+	logutil.Info(
+		"cohere chat api LLM: provider de initialized",
+		"name",
+		string(name),
+	)
+	return nil
+}
+
+func (api *CohereChatAPI) GetProviderInfo(ctx context.Context) *spec.ProviderParam {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	if api.ProviderParam == nil {
+		return nil
+	}
+	cp := *api.ProviderParam
+	cp.DefaultHeaders = sdkutil.CloneStringMap(cp.DefaultHeaders)
+	return &cp
+}
+
+func (api *CohereChatAPI) IsConfigured(ctx context.Context) bool {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.ProviderParam != nil && api.client != nil
+}
+
+// SetProviderAPIKey sets the key for a provider.
+func (api *CohereChatAPI) SetProviderAPIKey(
+	ctx context.Context,
+	apiKey string,
+) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.ProviderParam == nil {
+		return errors.New("cohere chat api LLM: no ProviderParam found")
+	}
+	// Allow empty to clear.
+	api.ProviderParam.APIKey = strings.TrimSpace(apiKey)
+
+	return nil
+}
+
+// FetchRaw sends an arbitrary request to the API, relative to Origin, e.g. "v2/embed" or "v2/rerank".
+func (api *CohereChatAPI) FetchRaw(
+	ctx context.Context,
+	method, path string,
+	body json.RawMessage,
+) (*spec.RawResponse, error) {
+	client, pi := api.snapshot()
+	if client == nil {
+		return nil, errors.New("cohere chat api LLM: client not initialized")
+	}
+	relPath, err := sdkutil.RawRequestPath(path)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, err := do(ctx, client, &pi, strings.ToUpper(method), origin(&pi)+"/"+relPath, body)
+	if err != nil {
+		return nil, err
+	}
+	return sdkutil.ReadRawResponse(httpResp)
+}
+
+func (api *CohereChatAPI) FetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (resp *spec.FetchCompletionResponse, err error) {
+	api.mu.RLock()
+	var providerName spec.ProviderName
+	if api.ProviderParam != nil {
+		providerName = api.ProviderParam.Name
+	}
+	api.mu.RUnlock()
+	defer sdkutil.RecoverFetchCompletion(providerName, &resp, &err)
+
+	return api.fetchCompletion(ctx, req, opts)
+}
+
+func (api *CohereChatAPI) fetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	client, pi := api.snapshot()
+	if client == nil && (opts == nil || !opts.DryRun) {
+		return nil, errors.New("cohere chat api LLM: client not initialized")
+	}
+	if req == nil || len(req.Inputs) == 0 || req.ModelParam.Name == "" {
+		return nil, errors.New("cohere chat api LLM: empty completion data")
+	}
+
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	params, toolChoiceNameMap := toCohereChatRequest(req, useStream)
+	overlay, err := sdkutil.ParseParamOverlay(
+		req.ModelParam.AdditionalParametersRawJSON, "model", "messages", "tools", "documents", "stream",
+	)
+	if err != nil {
+		return nil, err
+	}
+	if params.Extra, err = sdkutil.OverlayFields(params, nil, overlay); err != nil {
+		return nil, err
+	}
+
+	effective := *params
+	effective.Messages, effective.Tools, effective.Documents = nil, nil, nil
+	effectiveParams := sdkutil.EffectiveParams(effective, "messages", "tools", "documents")
+
+	if opts != nil && opts.DryRun {
+		return sdkutil.DryRunResponse(&pi, req.ModelParam.Name, useStream, params)
+	}
+
+	timeout := pi.Timeouts.Timeout(req.ModelParam.Name, req.ModelParam.Timeout)
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+		})
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		fullRawResp    *cohereChatResponse
+		apiErr         error
+	)
+	if useStream {
+		normalizedResp, fullRawResp, apiErr = doStreaming(
+			ctx, client, &pi, req.ModelParam.Name, params, opts, timeout, toolChoiceNameMap,
+		)
+	} else {
+		normalizedResp, fullRawResp, apiErr = doNonStreaming(ctx, client, &pi, params, timeout, toolChoiceNameMap)
+	}
+
+	normalizedResp = sdkutil.FinalizeResponse(pi.Name, normalizedResp, apiErr)
+	if normalizedResp != nil {
+		normalizedResp.Metadata = &spec.ResponseMetadata{EffectiveParams: effectiveParams}
+	}
+
+	if span != nil {
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: fullRawResp,
+			Response:         normalizedResp, // may be nil
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+func doNonStreaming(
+	ctx context.Context,
+	client *http.Client,
+	pi *spec.ProviderParam,
+	params *cohereChatRequest,
+	timeout time.Duration,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) (*spec.FetchCompletionResponse, *cohereChatResponse, error) {
+	resp := &spec.FetchCompletionResponse{}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpResp, err := post(ctx, client, pi, params)
+	if err != nil {
+		resp.Error = providerError(pi.Name, err)
+		return resp, nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	var cResp cohereChatResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&cResp); err != nil {
+		resp.Error = providerError(pi.Name, err)
+		return resp, nil, err
+	}
+
+	resp.Usage = usageFromCohere(cResp.Usage)
+	resp.Outputs = outputsFromCohere(&cResp, toolChoiceNameMap)
+	return resp, &cResp, nil
+}
+
+// cohereStreamEvent is the data payload of a v2 chat stream event. Delta.Message fields hold one element: the content
+// block, tool call or citation at Index.
+type cohereStreamEvent struct {
+	Type  string `json:"type"`
+	ID    string `json:"id,omitempty"`
+	Index int    `json:"index"`
+	Delta struct {
+		Message struct {
+			Content   *cohereContent  `json:"content,omitempty"`
+			ToolPlan  string          `json:"tool_plan,omitempty"`
+			ToolCalls *cohereToolCall `json:"tool_calls,omitempty"`
+			Citations *cohereCitation `json:"citations,omitempty"`
+		} `json:"message"`
+		FinishReason string       `json:"finish_reason,omitempty"`
+		Usage        *cohereUsage `json:"usage,omitempty"`
+		Error        string       `json:"error,omitempty"`
+	} `json:"delta"`
+}
+
+func doStreaming(
+	ctx context.Context,
+	client *http.Client,
+	pi *spec.ProviderParam,
+	modelName spec.ModelName,
+	params *cohereChatRequest,
+	opts *spec.FetchCompletionOptions,
+	timeout time.Duration,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) (*spec.FetchCompletionResponse, *cohereChatResponse, error) {
+	resp := &spec.FetchCompletionResponse{}
+	pipeline := sdkutil.NewStreamPipeline(
+		ctx,
+		opts.StreamHandler,
+		pi.Name,
+		modelName,
+		sdkutil.ResolveStreamConfig(opts),
+	)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		acc            cohereChatResponse
+		streamWriteErr error
+		streamErr      error
+		statusCode     int
+	)
+	httpResp, err := post(ctx, client, pi, params)
+	if err != nil {
+		streamErr = err
+	} else {
+		statusCode = httpResp.StatusCode
+		decoder := ssestream.NewDecoder(httpResp)
+		for decoder.Next() {
+			var ev cohereStreamEvent
+			if err := json.Unmarshal(decoder.Event().Data, &ev); err != nil {
+				streamErr = err
+				break
+			}
+			if ev.Delta.Error != "" {
+				streamErr = &apiError{StatusCode: statusCode, Message: ev.Delta.Error}
+				break
+			}
+			if streamWriteErr = applyStreamEvent(&acc, &ev, pipeline, toolChoiceNameMap); streamWriteErr != nil {
+				streamErr = streamWriteErr
+				break
+			}
+		}
+		if streamErr == nil {
+			streamErr = decoder.Err()
+		}
+		_ = decoder.Close()
+	}
+	if err := pipeline.Close(); err != nil && streamErr == nil {
+		streamErr = err
+	}
+
+	resp.Usage = usageFromCohere(acc.Usage)
+	pipeline.EmitUsage(resp.Usage)
+	if streamErr != nil {
+		resp.Error = providerError(pi.Name, streamErr)
+		if !errors.Is(streamErr, streamWriteErr) {
+			pipeline.EmitError(resp.Error)
+		}
+	}
+	resp.Outputs = outputsFromCohere(&acc, toolChoiceNameMap)
+	return resp, &acc, streamErr
+}
+
+// applyStreamEvent folds ev into acc and forwards text, thinking, tool plan and tool call progress to pipeline.
+func applyStreamEvent(
+	acc *cohereChatResponse,
+	ev *cohereStreamEvent,
+	pipeline *sdkutil.StreamPipeline,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) error {
+	msg := ev.Delta.Message
+	pos := sdkutil.StreamPosition{ContentIndex: ev.Index}
+	switch ev.Type {
+	case "message-start":
+		acc.ID = ev.ID
+		acc.Message.Role = string(spec.RoleAssistant)
+
+	case "content-start":
+		if msg.Content != nil && ev.Index == len(acc.Message.Content) {
+			acc.Message.Content = append(acc.Message.Content, *msg.Content)
+		}
+
+	case "content-delta":
+		if msg.Content == nil || ev.Index < 0 || ev.Index >= len(acc.Message.Content) {
+			return nil
+		}
+		c := &acc.Message.Content[ev.Index]
+		if msg.Content.Thinking != "" {
+			c.Thinking += msg.Content.Thinking
+			return pipeline.WriteThinking(pos, msg.Content.Thinking)
+		}
+		if msg.Content.Text != "" {
+			c.Text += msg.Content.Text
+			return pipeline.WriteText(pos, msg.Content.Text)
+		}
+
+	case "tool-plan-delta":
+		if msg.ToolPlan != "" {
+			acc.Message.ToolPlan += msg.ToolPlan
+			return pipeline.WriteThinking(pos, msg.ToolPlan)
+		}
+
+	case "tool-call-start":
+		if msg.ToolCalls == nil || ev.Index != len(acc.Message.ToolCalls) {
+			return nil
+		}
+		acc.Message.ToolCalls = append(acc.Message.ToolCalls, *msg.ToolCalls)
+		tc := acc.Message.ToolCalls[ev.Index]
+		phase := spec.StreamToolCallPhaseStart
+		return emitToolCall(pipeline, phase, ev.Index, tc, tc.Function.Arguments, toolChoiceNameMap)
+
+	case "tool-call-delta":
+		if msg.ToolCalls == nil || ev.Index < 0 || ev.Index >= len(acc.Message.ToolCalls) {
+			return nil
+		}
+		delta := msg.ToolCalls.Function.Arguments
+		acc.Message.ToolCalls[ev.Index].Function.Arguments += delta
+		tc := acc.Message.ToolCalls[ev.Index]
+		return emitToolCall(pipeline, spec.StreamToolCallPhaseDelta, ev.Index, tc, delta, toolChoiceNameMap)
+
+	case "tool-call-end":
+		if ev.Index < 0 || ev.Index >= len(acc.Message.ToolCalls) {
+			return nil
+		}
+		tc := acc.Message.ToolCalls[ev.Index]
+		return emitToolCall(pipeline, spec.StreamToolCallPhaseFinish, ev.Index, tc, "", toolChoiceNameMap)
+
+	case "citation-start":
+		if msg.Citations != nil {
+			acc.Message.Citations = append(acc.Message.Citations, *msg.Citations)
+		}
+
+	case "message-end":
+		acc.FinishReason = ev.Delta.FinishReason
+		acc.Usage = ev.Delta.Usage
+	}
+	return nil
+}
+
+func emitToolCall(
+	pipeline *sdkutil.StreamPipeline,
+	phase spec.StreamToolCallPhase,
+	index int,
+	tc cohereToolCall,
+	argsDelta string,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) error {
+	choice := toolChoiceNameMap[tc.Function.Name]
+	chunk := &spec.StreamToolCallChunk{
+		Phase:          phase,
+		Index:          index,
+		Type:           choice.Type,
+		ChoiceID:       choice.ID,
+		CallID:         tc.ID,
+		Name:           tc.Function.Name,
+		ArgumentsDelta: argsDelta,
+	}
+	if phase == spec.StreamToolCallPhaseFinish {
+		chunk.Arguments = tc.Function.Arguments
+	}
+	return pipeline.Emit(spec.StreamEvent{Kind: spec.StreamContentKindToolCall, ToolCall: chunk})
+}
+
+// apiError is a non-2xx response, or an error reported in the stream.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("cohere: %d %s", e.StatusCode, e.Message)
+}
+
+// providerError returns the spec.Error of a failed call, with the status and message of an apiError.
+func providerError(provider spec.ProviderName, err error) *spec.Error {
+	var ae *apiError
+	if errors.As(err, &ae) {
+		return sdkutil.NewError(provider, err, ae.StatusCode, "", ae.Message)
+	}
+	return sdkutil.NewError(provider, err, 0, "", "")
+}
+
+func (api *CohereChatAPI) snapshot() (*http.Client, spec.ProviderParam) {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	var pi spec.ProviderParam
+	if api.ProviderParam != nil {
+		pi = *api.ProviderParam
+	}
+	return api.client, pi
+}
+
+func post(
+	ctx context.Context,
+	client *http.Client,
+	pi *spec.ProviderParam,
+	params *cohereChatRequest,
+) (*http.Response, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := do(ctx, client, pi, http.MethodPost, chatURL(pi), raw)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer func() { _ = resp.Body.Close() }()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		var payload struct {
+			Message string `json:"message"`
+		}
+		msg := strings.TrimSpace(string(b))
+		if json.Unmarshal(b, &payload) == nil && payload.Message != "" {
+			msg = payload.Message
+		}
+		return nil, &apiError{StatusCode: resp.StatusCode, Message: msg}
+	}
+	return resp, nil
+}
+
+// do sends a request with the provider headers: defaults, request context, and the API key or credentials.
+func do(
+	ctx context.Context,
+	client *http.Client,
+	pi *spec.ProviderParam,
+	method, url string,
+	body []byte,
+) (*http.Response, error) {
+	var rd io.Reader
+	if len(body) > 0 {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, rd)
+	if err != nil {
+		return nil, err
+	}
+	if rd != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range pi.DefaultHeaders {
+		req.Header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+	if rc, ok := spec.RequestContextFromContext(ctx); ok {
+		for k, v := range sdkutil.RequestContextHeaders(rc) {
+			if req.Header.Get(k) == "" {
+				req.Header.Set(k, v)
+			}
+		}
+	}
+	token := pi.APIKey
+	if pi.Credentials != nil {
+		if token, err = pi.Credentials.Token(ctx); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case token == "":
+	case pi.APIKeyHeaderKey != "" && !strings.EqualFold(pi.APIKeyHeaderKey, spec.DefaultAuthorizationHeaderKey):
+		req.Header.Set(pi.APIKeyHeaderKey, token)
+	default:
+		req.Header.Set(spec.DefaultAuthorizationHeaderKey, "Bearer "+token)
+	}
+	return client.Do(req)
+}
+
+func origin(pi *spec.ProviderParam) string {
+	if pi.Origin == "" {
+		return spec.DefaultCohereOrigin
+	}
+	return strings.TrimSuffix(pi.Origin, "/")
+}
+
+func chatURL(pi *spec.ProviderParam) string {
+	prefix := pi.ChatCompletionPathPrefix
+	if prefix == "" {
+		prefix = spec.DefaultCohereChatPrefix
+	}
+	return origin(pi) + prefix
+}
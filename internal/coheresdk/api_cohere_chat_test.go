@@ -0,0 +1,140 @@
+package coheresdk
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestCohereChatAPIFetchCompletion(t *testing.T) {
+	t.Parallel()
+
+	const usage = `{"tokens":{"input_tokens":12,"output_tokens":6}}`
+	response := `{"id":"c1","finish_reason":"TOOL_CALL","message":{"role":"assistant",` +
+		`"content":[{"type":"text","text":"Got sunny."}],"tool_plan":"I will check.",` +
+		`"tool_calls":[{"id":"tc1","type":"function","function":{"name":"get_weather",` +
+		`"arguments":"{\"city\":\"Paris\"}"}}]},"usage":` + usage + `}`
+	sse := func(data string) string {
+		var ev struct {
+			Type string `json:"type"`
+		}
+		_ = json.Unmarshal([]byte(data), &ev)
+		return "event: " + ev.Type + "\ndata: " + data + "\n\n"
+	}
+	stream := sse(`{"type":"message-start","id":"c1","delta":{"message":{"role":"assistant"}}}`) +
+		sse(`{"type":"tool-plan-delta","delta":{"message":{"tool_plan":"I will check."}}}`) +
+		sse(`{"type":"content-start","index":0,"delta":{"message":{"content":{"type":"text","text":""}}}}`) +
+		sse(`{"type":"content-delta","index":0,"delta":{"message":{"content":{"text":"Got "}}}}`) +
+		sse(`{"type":"content-delta","index":0,"delta":{"message":{"content":{"text":"sunny."}}}}`) +
+		sse(`{"type":"content-end","index":0}`) +
+		sse(`{"type":"tool-call-start","index":0,"delta":{"message":{"tool_calls":{"id":"tc1","type":"function",`+
+			`"function":{"name":"get_weather","arguments":""}}}}}`) +
+		sse(`{"type":"tool-call-delta","index":0,"delta":{"message":{"tool_calls":{"function":`+
+			`{"arguments":"{\"city\":"}}}}}`) +
+		sse(`{"type":"tool-call-delta","index":0,"delta":{"message":{"tool_calls":{"function":`+
+			`{"arguments":"\"Paris\"}"}}}}}`) +
+		sse(`{"type":"tool-call-end","index":0}`) +
+		sse(`{"type":"message-end","delta":{"finish_reason":"TOOL_CALL","usage":`+usage+`}}`)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != spec.DefaultCohereChatPrefix || r.Header.Get("Authorization") != "Bearer k" {
+			http.NotFound(w, r)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(b), `"stream":true`) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(stream))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+
+	api, err := NewCohereChatAPI(spec.ProviderParam{
+		Name:                     "cohere",
+		SDKType:                  spec.ProviderSDKTypeCohere,
+		Origin:                   srv.URL,
+		ChatCompletionPathPrefix: spec.DefaultCohereChatPrefix,
+		APIKey:                   "k",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewCohereChatAPI() error = %v.", err)
+	}
+	if err := api.InitLLM(t.Context()); err != nil {
+		t.Fatalf("InitLLM() error = %v.", err)
+	}
+
+	tests := []struct {
+		name   string
+		stream bool
+	}{
+		{name: "NonStreaming."},
+		{name: "Streaming.", stream: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				opts   *spec.FetchCompletionOptions
+				text   string
+				phases []spec.StreamToolCallPhase
+			)
+			if tc.stream {
+				opts = &spec.FetchCompletionOptions{StreamHandler: func(e spec.StreamEvent) error {
+					switch e.Kind {
+					case spec.StreamContentKindText:
+						text += e.Text.Text
+					case spec.StreamContentKindToolCall:
+						phases = append(phases, e.ToolCall.Phase)
+					}
+					return nil
+				}}
+			}
+			resp, err := api.FetchCompletion(t.Context(), &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "command-a", Stream: tc.stream},
+				Inputs: []spec.InputUnion{{
+					Kind: spec.InputKindInputMessage,
+					InputMessage: &spec.InputOutputContent{
+						Role: spec.RoleUser,
+						Contents: []spec.InputOutputContentItemUnion{{
+							Kind:     spec.ContentItemKindText,
+							TextItem: &spec.ContentItemText{Text: "weather in Paris?"},
+						}},
+					},
+				}},
+				ToolChoices: []spec.ToolChoice{{
+					Type: spec.ToolTypeFunction, ID: "w", Name: "get_weather",
+					Arguments: map[string]any{"type": "object"},
+				}},
+			}, opts)
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			if tc.stream {
+				want := []spec.StreamToolCallPhase{
+					spec.StreamToolCallPhaseStart, spec.StreamToolCallPhaseDelta,
+					spec.StreamToolCallPhaseDelta, spec.StreamToolCallPhaseFinish,
+				}
+				if text != "Got sunny." || !reflect.DeepEqual(phases, want) {
+					t.Fatalf("streamed text = %q, tool call phases = %v.", text, phases)
+				}
+			}
+			if len(resp.Outputs) != 3 || resp.Outputs[2].FunctionToolCall == nil ||
+				resp.Outputs[2].FunctionToolCall.Arguments != `{"city":"Paris"}` {
+				t.Fatalf("Outputs = %+v, want tool plan, message and the get_weather call.", resp.Outputs)
+			}
+			if resp.Usage == nil || resp.Usage.InputTokensTotal != 12 || resp.Usage.OutputTokens != 6 {
+				t.Fatalf("Usage = %+v, want 12 input and 6 output tokens.", resp.Usage)
+			}
+		})
+	}
+}
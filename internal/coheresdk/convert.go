@@ -0,0 +1,460 @@
+package coheresdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// cohereChatRequest is the /v2/chat request body.
+type cohereChatRequest struct {
+	Model            string                `json:"model"`
+	Messages         []cohereMessage       `json:"messages"`
+	Tools            []cohereTool          `json:"tools,omitempty"`
+	Documents        []cohereDocument      `json:"documents,omitempty"`
+	Stream           bool                  `json:"stream"`
+	Temperature      *float64              `json:"temperature,omitempty"`
+	P                *float64              `json:"p,omitempty"`
+	K                *int                  `json:"k,omitempty"`
+	FrequencyPenalty *float64              `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64              `json:"presence_penalty,omitempty"`
+	MaxTokens        int                   `json:"max_tokens,omitempty"`
+	StopSequences    []string              `json:"stop_sequences,omitempty"`
+	ResponseFormat   *cohereResponseFormat `json:"response_format,omitempty"`
+	Thinking         *cohereThinking       `json:"thinking,omitempty"`
+
+	// Extra holds the ModelParam.AdditionalParametersRawJSON fields, already merged with the values they replace.
+	Extra map[string]any `json:"-"`
+}
+
+func (r cohereChatRequest) MarshalJSON() ([]byte, error) {
+	type plain cohereChatRequest
+	return sdkutil.MarshalWithExtra(plain(r), r.Extra)
+}
+
+type cohereMessage struct {
+	Role       string           `json:"role"`
+	Content    any              `json:"content,omitempty"`
+	ToolPlan   string           `json:"tool_plan,omitempty"`
+	ToolCalls  []cohereToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// cohereContent is a content block of a message. Text blocks carry Text; thinking blocks carry Thinking.
+type cohereContent struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	Thinking string          `json:"thinking,omitempty"`
+	ImageURL *cohereImageURL `json:"image_url,omitempty"`
+}
+
+type cohereImageURL struct {
+	URL string `json:"url"`
+}
+
+type cohereToolCall struct {
+	ID       string                 `json:"id"`
+	Type     string                 `json:"type"`
+	Function cohereToolCallFunction `json:"function"`
+}
+
+type cohereToolCallFunction struct {
+	Name string `json:"name"`
+	// Arguments is a JSON encoded object.
+	Arguments string `json:"arguments"`
+}
+
+type cohereTool struct {
+	Type     string             `json:"type"`
+	Function cohereToolFunction `json:"function"`
+}
+
+type cohereToolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type cohereDocument struct {
+	ID   string            `json:"id,omitempty"`
+	Data map[string]string `json:"data"`
+}
+
+type cohereResponseFormat struct {
+	Type       string         `json:"type"`
+	JSONSchema map[string]any `json:"json_schema,omitempty"`
+}
+
+type cohereThinking struct {
+	Type        string `json:"type"`
+	TokenBudget int    `json:"token_budget,omitempty"`
+}
+
+// cohereChatResponse is a /v2/chat response. Streamed responses are accumulated into the same shape.
+type cohereChatResponse struct {
+	ID           string                `json:"id"`
+	FinishReason string                `json:"finish_reason"`
+	Message      cohereResponseMessage `json:"message"`
+	Usage        *cohereUsage          `json:"usage,omitempty"`
+}
+
+type cohereResponseMessage struct {
+	Role      string           `json:"role"`
+	Content   []cohereContent  `json:"content,omitempty"`
+	ToolPlan  string           `json:"tool_plan,omitempty"`
+	ToolCalls []cohereToolCall `json:"tool_calls,omitempty"`
+	Citations []cohereCitation `json:"citations,omitempty"`
+}
+
+type cohereCitation struct {
+	Start   int64                  `json:"start"`
+	End     int64                  `json:"end"`
+	Text    string                 `json:"text"`
+	Sources []cohereCitationSource `json:"sources,omitempty"`
+	// Type is TEXT_CONTENT or THINKING_CONTENT.
+	Type string `json:"type,omitempty"`
+}
+
+type cohereCitationSource struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id"`
+	Document   map[string]any `json:"document,omitempty"`
+	ToolOutput map[string]any `json:"tool_output,omitempty"`
+}
+
+type cohereUsage struct {
+	Tokens *struct {
+		InputTokens  float64 `json:"input_tokens"`
+		OutputTokens float64 `json:"output_tokens"`
+	} `json:"tokens,omitempty"`
+	CachedTokens float64 `json:"cached_tokens,omitempty"`
+}
+
+func toCohereChatRequest(
+	req *spec.FetchCompletionRequest,
+	stream bool,
+) (*cohereChatRequest, map[string]spec.ToolChoice) {
+	mp := req.ModelParam
+	params := &cohereChatRequest{
+		Model:            string(mp.Name),
+		Stream:           stream,
+		Temperature:      mp.Temperature,
+		P:                mp.TopP,
+		K:                mp.TopK,
+		FrequencyPenalty: mp.FrequencyPenalty,
+		PresencePenalty:  mp.PresencePenalty,
+		MaxTokens:        mp.MaxOutputLength,
+		StopSequences:    mp.StopSequences,
+	}
+
+	toolNames, toolChoiceNameMap := sdkutil.BuildToolChoiceNameMapping(req.ToolChoices)
+	for _, tn := range toolNames {
+		switch tn.Choice.Type {
+		case spec.ToolTypeFunction, spec.ToolTypeCustom:
+			params.Tools = append(params.Tools, cohereTool{
+				Type: "function",
+				Function: cohereToolFunction{
+					Name:        tn.Name,
+					Description: sdkutil.ToolDescription(tn.Choice),
+					Parameters:  tn.Choice.Arguments,
+				},
+			})
+		default:
+			logutil.Debug("cohere chat api LLM: unsupported tool type", "type", tn.Choice.Type)
+		}
+	}
+	params.Messages, params.Documents = toCohereMessages(mp.SystemPrompt, req.Inputs)
+
+	if r := mp.Reasoning; r != nil {
+		switch {
+		case r.Level == spec.ReasoningLevelNone:
+			params.Thinking = &cohereThinking{Type: "disabled"}
+		case r.Type == spec.ReasoningTypeHybridWithTokens && r.Tokens > 0:
+			params.Thinking = &cohereThinking{Type: "enabled", TokenBudget: r.Tokens}
+		default:
+			params.Thinking = &cohereThinking{Type: "enabled"}
+		}
+	}
+
+	if op := mp.OutputParam; op != nil && op.Format != nil && op.Format.Kind == spec.OutputFormatKindJSONSchema &&
+		op.Format.JSONSchemaParam != nil {
+		params.ResponseFormat = &cohereResponseFormat{
+			Type:       "json_object",
+			JSONSchema: op.Format.JSONSchemaParam.Schema,
+		}
+	}
+	return params, toolChoiceNameMap
+}
+
+// toCohereMessages converts inputs to chat messages. Text files attached to user messages are sent as request
+// documents (documents mode) so that the response cites them; reasoning is replayed as the tool plan and thinking of
+// the next assistant message.
+func toCohereMessages(systemPrompt string, inputs []spec.InputUnion) ([]cohereMessage, []cohereDocument) {
+	var (
+		out           []cohereMessage
+		docs          []cohereDocument
+		reasoning     *spec.ReasoningContent
+		lastAssistant = -1
+	)
+	if sp := strings.TrimSpace(systemPrompt); sp != "" {
+		out = append(out, cohereMessage{Role: "system", Content: sp})
+	}
+	appendAssistant := func(content []cohereContent) {
+		m := cohereMessage{Role: string(spec.RoleAssistant)}
+		if reasoning != nil {
+			m.ToolPlan = strings.Join(reasoning.Summary, "\n")
+			thinking := make([]cohereContent, 0, len(reasoning.Thinking)+len(content))
+			for _, t := range reasoning.Thinking {
+				thinking = append(thinking, cohereContent{Type: "thinking", Thinking: t})
+			}
+			content = append(thinking, content...)
+			reasoning = nil
+		}
+		if len(content) > 0 {
+			m.Content = content
+		}
+		out = append(out, m)
+		lastAssistant = len(out) - 1
+	}
+
+	for _, in := range inputs {
+		if sdkutil.IsInputUnionEmpty(in) {
+			continue
+		}
+		switch in.Kind {
+		case spec.InputKindInputMessage:
+			if in.InputMessage == nil || in.InputMessage.Role != spec.RoleUser {
+				continue
+			}
+			var content []cohereContent
+			for _, it := range in.InputMessage.Contents {
+				switch {
+				case it.Kind == spec.ContentItemKindText && it.TextItem != nil:
+					if t := strings.TrimSpace(it.TextItem.Text); t != "" {
+						content = append(content, cohereContent{Type: "text", Text: t})
+					}
+				case it.Kind == spec.ContentItemKindImage && it.ImageItem != nil:
+					if u := imageURL(it.ImageItem); u != "" {
+						content = append(content, cohereContent{Type: "image_url", ImageURL: &cohereImageURL{URL: u}})
+					}
+				case it.Kind == spec.ContentItemKindFile && it.FileItem != nil:
+					if d, ok := fileToCohereDocument(it.FileItem, len(docs)); ok {
+						docs = append(docs, d)
+					}
+				}
+			}
+			if len(content) > 0 {
+				out = append(out, cohereMessage{Role: string(spec.RoleUser), Content: content})
+				lastAssistant = -1
+			}
+
+		case spec.InputKindOutputMessage:
+			if in.OutputMessage == nil || in.OutputMessage.Role != spec.RoleAssistant {
+				continue
+			}
+			var content []cohereContent
+			for _, it := range in.OutputMessage.Contents {
+				if it.Kind == spec.ContentItemKindText && it.TextItem != nil && it.TextItem.Text != "" {
+					content = append(content, cohereContent{Type: "text", Text: it.TextItem.Text})
+				}
+			}
+			if len(content) > 0 {
+				appendAssistant(content)
+			}
+
+		case spec.InputKindReasoningMessage:
+			reasoning = in.ReasoningMessage
+
+		case spec.InputKindFunctionToolCall, spec.InputKindCustomToolCall:
+			call := in.FunctionToolCall
+			if call == nil {
+				call = in.CustomToolCall
+			}
+			if call == nil || strings.TrimSpace(call.CallID) == "" || strings.TrimSpace(call.Name) == "" {
+				continue
+			}
+			args := call.Arguments
+			if !json.Valid([]byte(args)) {
+				args = "{}"
+			}
+			if lastAssistant < 0 {
+				appendAssistant(nil)
+			}
+			out[lastAssistant].ToolCalls = append(out[lastAssistant].ToolCalls, cohereToolCall{
+				ID:       call.CallID,
+				Type:     "function",
+				Function: cohereToolCallFunction{Name: call.Name, Arguments: args},
+			})
+
+		case spec.InputKindFunctionToolOutput, spec.InputKindCustomToolOutput:
+			output := in.FunctionToolOutput
+			if output == nil {
+				output = in.CustomToolOutput
+			}
+			if output == nil || strings.TrimSpace(output.CallID) == "" {
+				continue
+			}
+			var content []cohereContent
+			for _, it := range sdkutil.ToolOutputContents(output, false) {
+				if it.Kind == spec.ContentItemKindText && it.TextItem != nil && it.TextItem.Text != "" {
+					content = append(content, cohereContent{Type: "text", Text: it.TextItem.Text})
+				}
+			}
+			out = append(out, cohereMessage{Role: string(spec.RoleTool), ToolCallID: output.CallID, Content: content})
+			lastAssistant = -1
+
+		default:
+			// Hosted tools have no Cohere equivalent.
+			continue
+		}
+	}
+	return out, docs
+}
+
+// fileToCohereDocument converts a text file (base64 FileData with a text/* MIME type) to a document.
+func fileToCohereDocument(f *spec.ContentItemFile, n int) (cohereDocument, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(f.FileMIME), "text/") || f.FileData == "" {
+		logutil.Debug("cohere chat api LLM: skipping non-text file", "name", f.FileName, "mime", f.FileMIME)
+		return cohereDocument{}, false
+	}
+	data, err := base64.StdEncoding.DecodeString(f.FileData)
+	if err != nil || !utf8.Valid(data) {
+		logutil.Debug("cohere chat api LLM: skipping undecodable file", "name", f.FileName)
+		return cohereDocument{}, false
+	}
+	d := cohereDocument{ID: f.ID, Data: map[string]string{"snippet": string(data)}}
+	if d.ID == "" {
+		d.ID = "doc_" + strconv.Itoa(n)
+	}
+	if f.FileName != "" {
+		d.Data["title"] = f.FileName
+	}
+	if ctx := strings.TrimSpace(f.AdditionalContext); ctx != "" {
+		d.Data["context"] = ctx
+	}
+	return d, true
+}
+
+func imageURL(img *spec.ContentItemImage) string {
+	if u := strings.TrimSpace(img.ImageURL); u != "" {
+		return u
+	}
+	if img.ImageData == "" {
+		return ""
+	}
+	mime := img.ImageMIME
+	if mime == "" {
+		mime = spec.DefaultImageDataMIME
+	}
+	return "data:" + mime + ";base64," + img.ImageData
+}
+
+func outputsFromCohere(resp *cohereChatResponse, toolChoiceNameMap map[string]spec.ToolChoice) []spec.OutputUnion {
+	status := spec.StatusCompleted
+	if resp.FinishReason == "MAX_TOKENS" {
+		status = spec.StatusIncomplete
+	}
+
+	var (
+		outs     []spec.OutputUnion
+		text     strings.Builder
+		thinking []string
+	)
+	for _, c := range resp.Message.Content {
+		switch c.Type {
+		case "text":
+			text.WriteString(c.Text)
+		case "thinking":
+			if c.Thinking != "" {
+				thinking = append(thinking, c.Thinking)
+			}
+		}
+	}
+	if len(thinking) > 0 || resp.Message.ToolPlan != "" {
+		r := &spec.ReasoningContent{Role: spec.RoleAssistant, Status: status, Thinking: thinking}
+		if resp.Message.ToolPlan != "" {
+			r.Summary = []string{resp.Message.ToolPlan}
+		}
+		outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindReasoningMessage, ReasoningMessage: r})
+	}
+	if text.Len() > 0 {
+		outs = append(outs, spec.OutputUnion{
+			Kind: spec.OutputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{
+				ID:     resp.ID,
+				Role:   spec.RoleAssistant,
+				Status: status,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind: spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{
+						Text:      text.String(),
+						Citations: citationsFromCohere(resp.Message.Citations),
+					},
+				}},
+			},
+		})
+	}
+	for _, tc := range resp.Message.ToolCalls {
+		choice, ok := toolChoiceNameMap[tc.Function.Name]
+		if !ok || choice.ID == "" || tc.ID == "" {
+			continue
+		}
+		call := spec.ToolCall{
+			ChoiceID:  choice.ID,
+			Type:      choice.Type,
+			Role:      spec.RoleAssistant,
+			ID:        tc.ID,
+			CallID:    tc.ID,
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+			Status:    status,
+		}
+		if choice.Type == spec.ToolTypeCustom {
+			outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindCustomToolCall, CustomToolCall: &call})
+		} else {
+			outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindFunctionToolCall, FunctionToolCall: &call})
+		}
+	}
+	return outs
+}
+
+// citationsFromCohere converts citations of the response text; citations of thinking content are dropped.
+func citationsFromCohere(cs []cohereCitation) []spec.Citation {
+	var out []spec.Citation
+	for _, c := range cs {
+		if c.Type == "THINKING_CONTENT" {
+			continue
+		}
+		dc := &spec.DocumentCitation{CitedText: c.Text, StartIndex: c.Start, EndIndex: c.End}
+		for _, s := range c.Sources {
+			src := spec.DocumentCitationSource{ID: s.ID}
+			switch s.Type {
+			case "tool":
+				src.Kind, src.Fields = spec.DocumentCitationSourceKindTool, s.ToolOutput
+			default:
+				src.Kind, src.Fields = spec.DocumentCitationSourceKindDocument, s.Document
+			}
+			dc.Sources = append(dc.Sources, src)
+		}
+		out = append(out, spec.Citation{Kind: spec.CitationKindDocument, DocumentCitation: dc})
+	}
+	return out
+}
+
+func usageFromCohere(u *cohereUsage) *spec.Usage {
+	usage := &spec.Usage{}
+	if u == nil || u.Tokens == nil {
+		return usage
+	}
+	usage.InputTokensTotal = int64(u.Tokens.InputTokens)
+	usage.InputTokensCached = int64(u.CachedTokens)
+	usage.InputTokensUncached = max(usage.InputTokensTotal-usage.InputTokensCached, 0)
+	usage.OutputTokens = int64(u.Tokens.OutputTokens)
+	return usage
+}
@@ -0,0 +1,199 @@
+package coheresdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestToCohereChatRequest(t *testing.T) {
+	t.Parallel()
+
+	user := func(items ...spec.InputOutputContentItemUnion) spec.InputUnion {
+		return spec.InputUnion{
+			Kind:         spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{Role: spec.RoleUser, Contents: items},
+		}
+	}
+	text := spec.InputOutputContentItemUnion{
+		Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: "weather in Paris?"},
+	}
+	file := func(mime string) spec.InputOutputContentItemUnion {
+		return spec.InputOutputContentItemUnion{Kind: spec.ContentItemKindFile, FileItem: &spec.ContentItemFile{
+			FileName: "forecast.txt", FileMIME: mime, FileData: base64.StdEncoding.EncodeToString([]byte("sunny")),
+		}}
+	}
+	call := &spec.ToolCall{
+		Type: spec.ToolTypeFunction, ChoiceID: "w", CallID: "tc1", Name: "get_weather", Arguments: `{"city":"Paris"}`,
+	}
+	output := &spec.ToolOutput{
+		Type: spec.ToolTypeFunction, ChoiceID: "w", CallID: "tc1",
+		Contents: []spec.ToolOutputItemUnion{{
+			Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: "sunny"},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		param   spec.ModelParam
+		inputs  []spec.InputUnion
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "TextFileBecomesDocument.",
+			inputs: []spec.InputUnion{user(text, file("text/plain"))},
+			want: []string{
+				`"documents":[{"id":"doc_0","data":{"snippet":"sunny","title":"forecast.txt"}}]`,
+				`{"role":"user","content":[{"type":"text","text":"weather in Paris?"}]}`,
+			},
+		},
+		{
+			name:    "NonTextFileIsSkipped.",
+			inputs:  []spec.InputUnion{user(text, file("application/pdf"))},
+			notWant: []string{`"documents"`},
+		},
+		{
+			name: "ReplaysToolPlanCallAndOutput.",
+			inputs: []spec.InputUnion{
+				user(text),
+				{Kind: spec.InputKindReasoningMessage, ReasoningMessage: &spec.ReasoningContent{
+					Role: spec.RoleAssistant, Summary: []string{"I will check."},
+				}},
+				{Kind: spec.InputKindFunctionToolCall, FunctionToolCall: call},
+				{Kind: spec.InputKindFunctionToolOutput, FunctionToolOutput: output},
+			},
+			want: []string{
+				`"tool_plan":"I will check.","tool_calls":[{"id":"tc1"`,
+				`{"role":"tool","content":[{"type":"text","text":"sunny"}],"tool_call_id":"tc1"}`,
+			},
+		},
+		{
+			name: "SystemPromptAndThinkingBudget.",
+			param: spec.ModelParam{
+				SystemPrompt: "Be brief.",
+				Reasoning:    &spec.ReasoningParam{Type: spec.ReasoningTypeHybridWithTokens, Tokens: 1024},
+			},
+			inputs: []spec.InputUnion{user(text)},
+			want: []string{
+				`{"role":"system","content":"Be brief."}`,
+				`"thinking":{"type":"enabled","token_budget":1024}`,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.param.Name = "command-a"
+			params, _ := toCohereChatRequest(&spec.FetchCompletionRequest{
+				ModelParam: tc.param,
+				Inputs:     tc.inputs,
+				ToolChoices: []spec.ToolChoice{{
+					Type: spec.ToolTypeFunction, ID: "w", Name: "get_weather",
+					Arguments: map[string]any{"type": "object"},
+				}},
+			}, false)
+			b, err := json.Marshal(params)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v.", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(string(b), want) {
+					t.Fatalf("request = %s, want it to contain %s.", b, want)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(string(b), notWant) {
+					t.Fatalf("request = %s, want it not to contain %s.", b, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputsFromCohere(t *testing.T) {
+	t.Parallel()
+
+	const citation = `{"start":4,"end":9,"text":"sunny","type":"TEXT_CONTENT",` +
+		`"sources":[{"type":"document","id":"doc_0","document":{"title":"forecast.txt","snippet":"sunny"}}]}`
+	toolChoiceNameMap := map[string]spec.ToolChoice{
+		"get_weather": {Type: spec.ToolTypeFunction, ID: "w", Name: "get_weather"},
+	}
+
+	tests := []struct {
+		name          string
+		response      string
+		wantKinds     []spec.OutputKind
+		wantStatus    spec.Status
+		wantCitations []spec.Citation
+	}{
+		{
+			name: "ToolPlanTextAndCall.",
+			response: `{"id":"c1","finish_reason":"TOOL_CALL","message":{"role":"assistant",` +
+				`"content":[{"type":"text","text":"Got sunny."}],"tool_plan":"I will check.",` +
+				`"tool_calls":[{"id":"tc1","type":"function","function":{"name":"get_weather",` +
+				`"arguments":"{\"city\":\"Paris\"}"}}],"citations":[` + citation + `]}}`,
+			wantKinds: []spec.OutputKind{
+				spec.OutputKindReasoningMessage, spec.OutputKindOutputMessage, spec.OutputKindFunctionToolCall,
+			},
+			wantStatus: spec.StatusCompleted,
+			wantCitations: []spec.Citation{{
+				Kind: spec.CitationKindDocument,
+				DocumentCitation: &spec.DocumentCitation{
+					CitedText: "sunny", StartIndex: 4, EndIndex: 9,
+					Sources: []spec.DocumentCitationSource{{
+						Kind: spec.DocumentCitationSourceKindDocument, ID: "doc_0",
+						Fields: map[string]any{"title": "forecast.txt", "snippet": "sunny"},
+					}},
+				},
+			}},
+		},
+		{
+			name: "MaxTokensIsIncomplete.",
+			response: `{"id":"c1","finish_reason":"MAX_TOKENS","message":{"role":"assistant",` +
+				`"content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Got"}],` +
+				`"citations":[{"start":0,"end":3,"text":"hmm","type":"THINKING_CONTENT","sources":[]}]}}`,
+			wantKinds:  []spec.OutputKind{spec.OutputKindReasoningMessage, spec.OutputKindOutputMessage},
+			wantStatus: spec.StatusIncomplete,
+		},
+		{
+			name: "UnknownToolIsDropped.",
+			response: `{"id":"c1","finish_reason":"TOOL_CALL","message":{"role":"assistant",` +
+				`"tool_calls":[{"id":"tc1","type":"function","function":{"name":"other","arguments":"{}"}}]}}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var resp cohereChatResponse
+			if err := json.Unmarshal([]byte(tc.response), &resp); err != nil {
+				t.Fatalf("Unmarshal() error = %v.", err)
+			}
+			outs := outputsFromCohere(&resp, toolChoiceNameMap)
+			var kinds []spec.OutputKind
+			for _, o := range outs {
+				kinds = append(kinds, o.Kind)
+			}
+			if !reflect.DeepEqual(kinds, tc.wantKinds) {
+				t.Fatalf("output kinds = %v, want %v.", kinds, tc.wantKinds)
+			}
+			for _, o := range outs {
+				if o.OutputMessage == nil {
+					continue
+				}
+				if o.OutputMessage.Status != tc.wantStatus {
+					t.Fatalf("Status = %q, want %q.", o.OutputMessage.Status, tc.wantStatus)
+				}
+				if got := o.OutputMessage.Contents[0].TextItem.Citations; !reflect.DeepEqual(got, tc.wantCitations) {
+					t.Fatalf("Citations = %+v, want %+v.", got, tc.wantCitations)
+				}
+			}
+		})
+	}
+}
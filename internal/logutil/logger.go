@@ -4,11 +4,15 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 )
 
 var (
 	mu           sync.RWMutex
 	globalLogger *slog.Logger
+
+	// minLevel, if set, drops records below it regardless of the installed logger.
+	minLevel atomic.Pointer[slog.Level]
 )
 
 func init() {
@@ -95,5 +99,36 @@ func SetDefault(logger *slog.Logger) {
 	if logger == nil {
 		logger = slog.New(slog.DiscardHandler)
 	}
-	globalLogger = logger
+	globalLogger = slog.New(levelHandler{logger.Handler()})
+}
+
+// SetLevel drops records below level for the process-wide logger, on top of its handler's own level. A nil level
+// removes the filter. It takes effect immediately, including for loggers already derived via With.
+func SetLevel(level *slog.Level) {
+	if level == nil {
+		minLevel.Store(nil)
+		return
+	}
+	l := *level
+	minLevel.Store(&l)
+}
+
+// levelHandler applies minLevel in front of the installed handler.
+type levelHandler struct {
+	slog.Handler
+}
+
+func (h levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if floor := minLevel.Load(); floor != nil && level < *floor {
+		return false
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{h.Handler.WithGroup(name)}
 }
@@ -0,0 +1,553 @@
+package ollamasdk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// OllamaChatAPI struct that implements the CompletionProvider interface for Ollama's native /api/chat protocol.
+// There is no official Go SDK dependency; requests are plain JSON over net/http and streams are NDJSON.
+type OllamaChatAPI struct {
+	ProviderParam *spec.ProviderParam
+	debugger      spec.CompletionDebugger
+	client        *http.Client
+	mu            sync.RWMutex
+}
+
+func NewOllamaChatAPI(
+	pi spec.ProviderParam,
+	debugger spec.CompletionDebugger,
+) (*OllamaChatAPI, error) {
+	if pi.Name == "" {
+		return nil, errors.New("ollama chat api LLM: invalid args")
+	}
+	return &OllamaChatAPI{
+		ProviderParam: &pi,
+		debugger:      debugger,
+	}, nil
+}
+
+// InitLLM initializes the HTTP client. Ollama needs no API key; one is sent as a bearer token when set, for servers
+// behind an authenticating proxy.
+func (api *OllamaChatAPI) InitLLM(ctx context.Context) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.ProviderParam == nil {
+		api.client = nil
+		return errors.New("ollama chat api LLM: no ProviderParam found")
+	}
+
+	client := &http.Client{}
+	if httpClient := sdkutil.HTTPClient(api.ProviderParam, api.debugger); httpClient != nil {
+		client = httpClient
+	}
+	api.client = client
+	logutil.Info(
+		"ollama chat api LLM provider initialized",
+		"name", string(api.ProviderParam.Name),
+		"URL", chatURL(api.ProviderParam),
+	)
+	return nil
+}
+
+func (api *OllamaChatAPI) DeInitLLM(ctx context.Context) error {
+	api.mu.Lock()
+	var name spec.ProviderName
+	if api.ProviderParam != nil {
+		name = api.ProviderParam.Name
+	}
+	api.client = nil
+	api.mu.Unlock()
+	logutil.Info(
+		"ollama chat api LLM: provider de initialized",
+		"name",
+		string(name),
+	)
+	return nil
+}
+
+func (api *OllamaChatAPI) GetProviderInfo(ctx context.Context) *spec.ProviderParam {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	if api.ProviderParam == nil {
+		return nil
+	}
+	cp := *api.ProviderParam
+	cp.DefaultHeaders = sdkutil.CloneStringMap(cp.DefaultHeaders)
+	return &cp
+}
+
+// IsConfigured reports whether the client is initialized; no API key is required.
+func (api *OllamaChatAPI) IsConfigured(ctx context.Context) bool {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.ProviderParam != nil && api.client != nil
+}
+
+// SetProviderAPIKey sets the optional bearer token for a provider.
+func (api *OllamaChatAPI) SetProviderAPIKey(
+	ctx context.Context,
+	apiKey string,
+) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.ProviderParam == nil {
+		return errors.New("ollama chat api LLM: no ProviderParam found")
+	}
+	// Allow empty to clear.
+	api.ProviderParam.APIKey = strings.TrimSpace(apiKey)
+
+	return nil
+}
+
+// FetchRaw sends an arbitrary request to the server, relative to Origin, e.g. "api/show".
+func (api *OllamaChatAPI) FetchRaw(
+	ctx context.Context,
+	method, path string,
+	body json.RawMessage,
+) (*spec.RawResponse, error) {
+	client, pi := api.snapshot()
+	if client == nil {
+		return nil, errors.New("ollama chat api LLM: client not initialized")
+	}
+	relPath, err := sdkutil.RawRequestPath(path)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, err := api.do(ctx, client, &pi, strings.ToUpper(method), origin(&pi)+"/"+relPath, body)
+	if err != nil {
+		return nil, err
+	}
+	return sdkutil.ReadRawResponse(httpResp)
+}
+
+func (api *OllamaChatAPI) FetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (resp *spec.FetchCompletionResponse, err error) {
+	api.mu.RLock()
+	var providerName spec.ProviderName
+	if api.ProviderParam != nil {
+		providerName = api.ProviderParam.Name
+	}
+	api.mu.RUnlock()
+	defer sdkutil.RecoverFetchCompletion(providerName, &resp, &err)
+
+	return api.fetchCompletion(ctx, req, opts)
+}
+
+func (api *OllamaChatAPI) fetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	client, pi := api.snapshot()
+	if client == nil && (opts == nil || !opts.DryRun) {
+		return nil, errors.New("ollama chat api LLM: client not initialized")
+	}
+	if req == nil || len(req.Inputs) == 0 || req.ModelParam.Name == "" {
+		return nil, errors.New("ollama chat api LLM: empty completion data")
+	}
+
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	params, toolChoiceNameMap := toOllamaChatRequest(req, &pi, useStream)
+	overlay, err := sdkutil.ParseParamOverlay(
+		req.ModelParam.AdditionalParametersRawJSON, "model", "messages", "tools", "stream",
+	)
+	if err != nil {
+		return nil, err
+	}
+	if params.Extra, err = sdkutil.OverlayFields(params, nil, overlay); err != nil {
+		return nil, err
+	}
+
+	effective := *params
+	effective.Messages, effective.Tools = nil, nil
+	effectiveParams := sdkutil.EffectiveParams(effective, "messages", "tools")
+
+	if opts != nil && opts.DryRun {
+		return sdkutil.DryRunResponse(&pi, req.ModelParam.Name, useStream, params)
+	}
+
+	timeout := pi.Timeouts.Timeout(req.ModelParam.Name, req.ModelParam.Timeout)
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+		})
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		fullRawResp    *ollamaChatResponse
+		apiErr         error
+	)
+	if useStream {
+		normalizedResp, fullRawResp, apiErr = api.doStreaming(
+			ctx, client, &pi, req.ModelParam.Name, params, opts, timeout, toolChoiceNameMap,
+		)
+	} else {
+		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(ctx, client, &pi, params, timeout, toolChoiceNameMap)
+	}
+
+	normalizedResp = sdkutil.FinalizeResponse(pi.Name, normalizedResp, apiErr)
+	if normalizedResp != nil {
+		normalizedResp.Metadata = &spec.ResponseMetadata{EffectiveParams: effectiveParams}
+	}
+
+	if span != nil {
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: fullRawResp,
+			Response:         normalizedResp, // may be nil
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+func (api *OllamaChatAPI) doNonStreaming(
+	ctx context.Context,
+	client *http.Client,
+	pi *spec.ProviderParam,
+	params *ollamaChatRequest,
+	timeout time.Duration,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) (*spec.FetchCompletionResponse, *ollamaChatResponse, error) {
+	resp := &spec.FetchCompletionResponse{}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpResp, err := api.post(ctx, client, pi, chatURL(pi), params)
+	if err != nil {
+		resp.Error = providerError(pi.Name, err)
+		return resp, nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	var oResp ollamaChatResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&oResp); err != nil {
+		resp.Error = providerError(pi.Name, err)
+		return resp, nil, err
+	}
+	if oResp.Error != "" {
+		err := &apiError{StatusCode: httpResp.StatusCode, Message: oResp.Error}
+		resp.Error = providerError(pi.Name, err)
+		return resp, &oResp, err
+	}
+
+	resp.Usage = usageFromOllama(&oResp)
+	resp.Outputs = outputsFromOllama(&oResp, nil, toolChoiceNameMap)
+	return resp, &oResp, nil
+}
+
+func (api *OllamaChatAPI) doStreaming(
+	ctx context.Context,
+	client *http.Client,
+	pi *spec.ProviderParam,
+	modelName spec.ModelName,
+	params *ollamaChatRequest,
+	opts *spec.FetchCompletionOptions,
+	timeout time.Duration,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) (*spec.FetchCompletionResponse, *ollamaChatResponse, error) {
+	resp := &spec.FetchCompletionResponse{}
+	pipeline := sdkutil.NewStreamPipeline(
+		ctx,
+		opts.StreamHandler,
+		pi.Name,
+		modelName,
+		sdkutil.ResolveStreamConfig(opts),
+	)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// acc accumulates the streamed message; callIDs are assigned as tool calls arrive so that the stream events and
+	// the final outputs agree.
+	var (
+		acc            ollamaChatResponse
+		callIDs        []string
+		streamWriteErr error
+		streamErr      error
+		statusCode     int
+	)
+	httpResp, err := api.post(ctx, client, pi, chatURL(pi), params)
+	if err != nil {
+		streamErr = err
+	} else {
+		defer func() { _ = httpResp.Body.Close() }()
+		statusCode = httpResp.StatusCode
+		streamErr = readNDJSON(httpResp.Body, func(line []byte) error {
+			var chunk ollamaChatResponse
+			if err := json.Unmarshal(line, &chunk); err != nil {
+				return err
+			}
+			if chunk.Error != "" {
+				return &apiError{StatusCode: statusCode, Message: chunk.Error}
+			}
+			if chunk.Message.Thinking != "" {
+				acc.Message.Thinking += chunk.Message.Thinking
+				streamWriteErr = pipeline.WriteThinking(sdkutil.StreamPosition{}, chunk.Message.Thinking)
+				if streamWriteErr != nil {
+					return streamWriteErr
+				}
+			}
+			if chunk.Message.Content != "" {
+				acc.Message.Content += chunk.Message.Content
+				streamWriteErr = pipeline.WriteText(sdkutil.StreamPosition{}, chunk.Message.Content)
+				if streamWriteErr != nil {
+					return streamWriteErr
+				}
+			}
+			// Ollama delivers each tool call complete, in a single chunk.
+			for _, tc := range chunk.Message.ToolCalls {
+				id := newCallID()
+				callIDs = append(callIDs, id)
+				acc.Message.ToolCalls = append(acc.Message.ToolCalls, tc)
+				streamWriteErr = emitToolCall(pipeline, len(callIDs)-1, id, tc, toolChoiceNameMap)
+				if streamWriteErr != nil {
+					return streamWriteErr
+				}
+			}
+			if chunk.Done {
+				acc.Model, acc.CreatedAt, acc.Done = chunk.Model, chunk.CreatedAt, true
+				acc.DoneReason = chunk.DoneReason
+				acc.PromptEvalCount, acc.EvalCount = chunk.PromptEvalCount, chunk.EvalCount
+				return errStreamDone
+			}
+			return nil
+		})
+		if errors.Is(streamErr, errStreamDone) {
+			streamErr = nil
+		}
+	}
+	if err := pipeline.Close(); err != nil && streamErr == nil {
+		streamErr = err
+	}
+
+	resp.Usage = usageFromOllama(&acc)
+	pipeline.EmitUsage(resp.Usage)
+	if streamErr != nil {
+		resp.Error = providerError(pi.Name, streamErr)
+		if !errors.Is(streamErr, streamWriteErr) {
+			pipeline.EmitError(resp.Error)
+		}
+	}
+	resp.Outputs = outputsFromOllama(&acc, callIDs, toolChoiceNameMap)
+	return resp, &acc, streamErr
+}
+
+var errStreamDone = errors.New("ollama: stream done")
+
+func emitToolCall(
+	pipeline *sdkutil.StreamPipeline,
+	index int,
+	id string,
+	tc ollamaToolCall,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) error {
+	choice := toolChoiceNameMap[tc.Function.Name]
+	chunk := spec.StreamToolCallChunk{
+		Index:    index,
+		Type:     choice.Type,
+		ChoiceID: choice.ID,
+		CallID:   id,
+		Name:     tc.Function.Name,
+	}
+	start := chunk
+	start.Phase = spec.StreamToolCallPhaseStart
+	if err := pipeline.Emit(spec.StreamEvent{Kind: spec.StreamContentKindToolCall, ToolCall: &start}); err != nil {
+		return err
+	}
+	finish := chunk
+	finish.Phase = spec.StreamToolCallPhaseFinish
+	finish.Arguments = toolCallArguments(tc.Function.Arguments)
+	return pipeline.Emit(spec.StreamEvent{Kind: spec.StreamContentKindToolCall, ToolCall: &finish})
+}
+
+// apiError is a non-2xx response, or an error object in the response body.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("ollama: %d %s", e.StatusCode, e.Message)
+}
+
+// providerError returns the spec.Error of a failed call, with the status and message of an apiError.
+func providerError(provider spec.ProviderName, err error) *spec.Error {
+	var ae *apiError
+	if errors.As(err, &ae) {
+		return sdkutil.NewError(provider, err, ae.StatusCode, "", ae.Message)
+	}
+	return sdkutil.NewError(provider, err, 0, "", "")
+}
+
+func (api *OllamaChatAPI) snapshot() (*http.Client, spec.ProviderParam) {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	var pi spec.ProviderParam
+	if api.ProviderParam != nil {
+		pi = *api.ProviderParam
+	}
+	return api.client, pi
+}
+
+func (api *OllamaChatAPI) post(
+	ctx context.Context,
+	client *http.Client,
+	pi *spec.ProviderParam,
+	url string,
+	body any,
+) (*http.Response, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := api.do(ctx, client, pi, http.MethodPost, url, raw)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, readAPIError(resp)
+	}
+	return resp, nil
+}
+
+// do sends a request with the provider headers: defaults, request context, and the API key or credentials.
+func (api *OllamaChatAPI) do(
+	ctx context.Context,
+	client *http.Client,
+	pi *spec.ProviderParam,
+	method, url string,
+	body []byte,
+) (*http.Response, error) {
+	var rd io.Reader
+	if len(body) > 0 {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, rd)
+	if err != nil {
+		return nil, err
+	}
+	if rd != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range pi.DefaultHeaders {
+		req.Header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+	if rc, ok := spec.RequestContextFromContext(ctx); ok {
+		for k, v := range sdkutil.RequestContextHeaders(rc) {
+			if req.Header.Get(k) == "" {
+				req.Header.Set(k, v)
+			}
+		}
+	}
+	switch {
+	case pi.Credentials != nil:
+		token, err := pi.Credentials.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(spec.DefaultAuthorizationHeaderKey, "Bearer "+token)
+	case pi.APIKey != "" && pi.APIKeyHeaderKey != "" &&
+		!strings.EqualFold(pi.APIKeyHeaderKey, spec.DefaultAuthorizationHeaderKey):
+		req.Header.Set(pi.APIKeyHeaderKey, pi.APIKey)
+	case pi.APIKey != "":
+		req.Header.Set(spec.DefaultAuthorizationHeaderKey, "Bearer "+pi.APIKey)
+	}
+	return client.Do(req)
+}
+
+func readAPIError(resp *http.Response) error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var payload struct {
+		Error string `json:"error"`
+	}
+	msg := strings.TrimSpace(string(b))
+	if json.Unmarshal(b, &payload) == nil && payload.Error != "" {
+		msg = payload.Error
+	}
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	return &apiError{StatusCode: resp.StatusCode, Message: msg}
+}
+
+// readNDJSON calls fn for each non-empty line of r until fn returns an error or r is exhausted.
+func readNDJSON(r io.Reader, fn func(line []byte) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 64<<20)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func origin(pi *spec.ProviderParam) string {
+	if pi.Origin == "" {
+		return spec.DefaultOllamaOrigin
+	}
+	return strings.TrimSuffix(pi.Origin, "/")
+}
+
+func chatURL(pi *spec.ProviderParam) string {
+	prefix := pi.ChatCompletionPathPrefix
+	if prefix == "" {
+		prefix = spec.DefaultOllamaChatPrefix
+	}
+	return origin(pi) + prefix
+}
+
+// apiURL returns the URL of another endpoint of the API that serves chat, e.g. "/api" + "/tags".
+func apiURL(pi *spec.ProviderParam, endpoint string) string {
+	prefix := pi.ChatCompletionPathPrefix
+	if prefix == "" {
+		prefix = spec.DefaultOllamaChatPrefix
+	}
+	return origin(pi) + strings.TrimSuffix(prefix, "/chat") + endpoint
+}
+
+// newCallID returns a tool call ID. Ollama does not identify tool calls, but spec tool outputs are matched to their
+// calls by ID.
+func newCallID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return "call_" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,130 @@
+package ollamasdk
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// newTestAPI returns an initialized adapter for a server running handler.
+func newTestAPI(t *testing.T, handler http.HandlerFunc) *OllamaChatAPI {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	api, err := NewOllamaChatAPI(spec.ProviderParam{
+		Name:    "ollama",
+		SDKType: spec.ProviderSDKTypeOllama,
+		Origin:  srv.URL,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewOllamaChatAPI() error = %v.", err)
+	}
+	if err := api.InitLLM(t.Context()); err != nil {
+		t.Fatalf("InitLLM() error = %v.", err)
+	}
+	return api
+}
+
+func TestOllamaChatAPIFetchCompletion(t *testing.T) {
+	t.Parallel()
+
+	const final = `{"model":"qwen3","message":{"role":"assistant","content":"",` +
+		`"tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"Paris"}}}]},` +
+		`"done":true,"done_reason":"stop","prompt_eval_count":7,"eval_count":5}`
+	response := `{"model":"qwen3","message":{"role":"assistant","content":"Checking.","thinking":"hmm",` +
+		`"tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"Paris"}}}]},` +
+		`"done":true,"done_reason":"stop","prompt_eval_count":7,"eval_count":5}`
+	stream := `{"model":"qwen3","message":{"role":"assistant","content":"","thinking":"hm"},"done":false}` + "\n" +
+		`{"model":"qwen3","message":{"role":"assistant","content":"","thinking":"m"},"done":false}` + "\n" +
+		`{"model":"qwen3","message":{"role":"assistant","content":"Checking."},"done":false}` + "\n" +
+		final + "\n"
+
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if strings.Contains(string(b), `"stream":true`) {
+			_, _ = w.Write([]byte(stream))
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	})
+	if !api.IsConfigured(t.Context()) {
+		t.Fatalf("IsConfigured() = false, want true without an API key.")
+	}
+
+	tests := []struct {
+		name   string
+		stream bool
+	}{
+		{name: "NonStreaming."},
+		{name: "Streaming.", stream: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				opts      *spec.FetchCompletionOptions
+				thinking  string
+				text      string
+				callPhase []spec.StreamToolCallPhase
+			)
+			if tc.stream {
+				opts = &spec.FetchCompletionOptions{StreamHandler: func(e spec.StreamEvent) error {
+					switch e.Kind {
+					case spec.StreamContentKindThinking:
+						thinking += e.Thinking.Text
+					case spec.StreamContentKindText:
+						text += e.Text.Text
+					case spec.StreamContentKindToolCall:
+						callPhase = append(callPhase, e.ToolCall.Phase)
+					}
+					return nil
+				}}
+			}
+			resp, err := api.FetchCompletion(t.Context(), &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "qwen3", Stream: tc.stream},
+				Inputs: []spec.InputUnion{{
+					Kind: spec.InputKindInputMessage,
+					InputMessage: &spec.InputOutputContent{
+						Role: spec.RoleUser,
+						Contents: []spec.InputOutputContentItemUnion{{
+							Kind:     spec.ContentItemKindText,
+							TextItem: &spec.ContentItemText{Text: "weather in Paris?"},
+						}},
+					},
+				}},
+				ToolChoices: []spec.ToolChoice{{
+					Type: spec.ToolTypeFunction, ID: "w", Name: "get_weather",
+					Arguments: map[string]any{"type": "object"},
+				}},
+			}, opts)
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			if tc.stream {
+				want := []spec.StreamToolCallPhase{spec.StreamToolCallPhaseStart, spec.StreamToolCallPhaseFinish}
+				if thinking != "hmm" || text != "Checking." || !reflect.DeepEqual(callPhase, want) {
+					t.Fatalf("streamed thinking = %q, text = %q, tool call phases = %v.", thinking, text, callPhase)
+				}
+			}
+			if len(resp.Outputs) != 3 || resp.Outputs[0].ReasoningMessage == nil ||
+				resp.Outputs[1].OutputMessage == nil || resp.Outputs[2].FunctionToolCall == nil {
+				t.Fatalf("Outputs = %+v, want reasoning, message and tool call.", resp.Outputs)
+			}
+			if resp.Usage == nil || resp.Usage.InputTokensTotal != 7 || resp.Usage.OutputTokens != 5 {
+				t.Fatalf("Usage = %+v, want 7 input and 5 output tokens.", resp.Usage)
+			}
+		})
+	}
+}
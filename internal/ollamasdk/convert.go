@@ -0,0 +1,350 @@
+package ollamasdk
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ollamaChatRequest is the /api/chat request body.
+type ollamaChatRequest struct {
+	Model     string          `json:"model"`
+	Messages  []ollamaMessage `json:"messages"`
+	Tools     []ollamaTool    `json:"tools,omitempty"`
+	Format    map[string]any  `json:"format,omitempty"`
+	Options   *ollamaOptions  `json:"options,omitempty"`
+	Stream    bool            `json:"stream"`
+	Think     any             `json:"think,omitempty"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+
+	// Extra holds the ModelParam.AdditionalParametersRawJSON fields, already merged with the values they replace.
+	Extra map[string]any `json:"-"`
+}
+
+func (r ollamaChatRequest) MarshalJSON() ([]byte, error) {
+	type plain ollamaChatRequest
+	return sdkutil.MarshalWithExtra(plain(r), r.Extra)
+}
+
+type ollamaOptions struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	TopK             *int     `json:"top_k,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	NumPredict       int      `json:"num_predict,omitempty"`
+	NumCtx           int      `json:"num_ctx,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function ollamaToolCallFunction `json:"function"`
+}
+
+type ollamaToolCallFunction struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type ollamaTool struct {
+	Type     string             `json:"type"`
+	Function ollamaToolFunction `json:"function"`
+}
+
+type ollamaToolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+// ollamaChatResponse is a /api/chat response, or one NDJSON chunk of a streamed response.
+type ollamaChatResponse struct {
+	Model           string        `json:"model"`
+	CreatedAt       string        `json:"created_at,omitempty"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason,omitempty"`
+	PromptEvalCount int64         `json:"prompt_eval_count,omitempty"`
+	EvalCount       int64         `json:"eval_count,omitempty"`
+	Error           string        `json:"error,omitempty"`
+}
+
+func toOllamaChatRequest(
+	req *spec.FetchCompletionRequest,
+	pi *spec.ProviderParam,
+	stream bool,
+) (*ollamaChatRequest, map[string]spec.ToolChoice) {
+	mp := req.ModelParam
+	params := &ollamaChatRequest{
+		Model:  string(mp.Name),
+		Stream: stream,
+	}
+
+	toolNames, toolChoiceNameMap := sdkutil.BuildToolChoiceNameMapping(req.ToolChoices)
+	for _, tn := range toolNames {
+		switch tn.Choice.Type {
+		case spec.ToolTypeFunction, spec.ToolTypeCustom:
+			params.Tools = append(params.Tools, ollamaTool{
+				Type: "function",
+				Function: ollamaToolFunction{
+					Name:        tn.Name,
+					Description: sdkutil.ToolDescription(tn.Choice),
+					Parameters:  tn.Choice.Arguments,
+				},
+			})
+		default:
+			logutil.Debug("ollama chat api LLM: unsupported tool type", "type", tn.Choice.Type)
+		}
+	}
+	params.Messages = toOllamaMessages(mp.SystemPrompt, req.Inputs)
+
+	opts := &ollamaOptions{
+		Temperature:      mp.Temperature,
+		TopP:             mp.TopP,
+		TopK:             mp.TopK,
+		FrequencyPenalty: mp.FrequencyPenalty,
+		PresencePenalty:  mp.PresencePenalty,
+		NumPredict:       mp.MaxOutputLength,
+		Stop:             mp.StopSequences,
+	}
+	if pi.Ollama != nil {
+		opts.NumCtx = pi.Ollama.NumCtx
+		params.KeepAlive = pi.Ollama.KeepAlive
+	}
+	if opts.Temperature != nil || opts.TopP != nil || opts.TopK != nil || opts.FrequencyPenalty != nil ||
+		opts.PresencePenalty != nil || opts.NumPredict > 0 || opts.NumCtx > 0 || len(opts.Stop) > 0 {
+		params.Options = opts
+	}
+
+	if r := mp.Reasoning; r != nil {
+		switch r.Level {
+		case spec.ReasoningLevelNone:
+			params.Think = false
+		case spec.ReasoningLevelLow, spec.ReasoningLevelMedium, spec.ReasoningLevelHigh:
+			// Levels are honored by models that support them (e.g. gpt-oss); others treat any level as true.
+			params.Think = string(r.Level)
+		default:
+			params.Think = true
+		}
+	}
+
+	if op := mp.OutputParam; op != nil && op.Format != nil && op.Format.Kind == spec.OutputFormatKindJSONSchema &&
+		op.Format.JSONSchemaParam != nil {
+		params.Format = op.Format.JSONSchemaParam.Schema
+	}
+
+	return params, toolChoiceNameMap
+}
+
+// toOllamaMessages converts inputs to chat messages. Tool calls and reasoning are folded into assistant messages, and
+// tool outputs are named after the call they answer as Ollama does not identify tool calls.
+func toOllamaMessages(systemPrompt string, inputs []spec.InputUnion) []ollamaMessage {
+	var (
+		out           []ollamaMessage
+		thinking      string
+		callNames     = map[string]string{}
+		lastAssistant = -1
+	)
+	if sp := strings.TrimSpace(systemPrompt); sp != "" {
+		out = append(out, ollamaMessage{Role: "system", Content: sp})
+	}
+	appendAssistant := func(m ollamaMessage) {
+		m.Role = string(spec.RoleAssistant)
+		m.Thinking, thinking = thinking, ""
+		out = append(out, m)
+		lastAssistant = len(out) - 1
+	}
+
+	for _, in := range inputs {
+		if sdkutil.IsInputUnionEmpty(in) {
+			continue
+		}
+		switch in.Kind {
+		case spec.InputKindInputMessage:
+			if in.InputMessage == nil || in.InputMessage.Role != spec.RoleUser {
+				continue
+			}
+			m := ollamaMessage{Role: string(spec.RoleUser)}
+			for _, it := range in.InputMessage.Contents {
+				switch {
+				case it.Kind == spec.ContentItemKindText && it.TextItem != nil:
+					m.Content = joinText(m.Content, it.TextItem.Text)
+				case it.Kind == spec.ContentItemKindImage && it.ImageItem != nil && it.ImageItem.ImageData != "":
+					m.Images = append(m.Images, it.ImageItem.ImageData)
+				}
+			}
+			if m.Content != "" || len(m.Images) > 0 {
+				out = append(out, m)
+				lastAssistant = -1
+			}
+
+		case spec.InputKindOutputMessage:
+			if in.OutputMessage == nil || in.OutputMessage.Role != spec.RoleAssistant {
+				continue
+			}
+			var m ollamaMessage
+			for _, it := range in.OutputMessage.Contents {
+				if it.Kind == spec.ContentItemKindText && it.TextItem != nil {
+					m.Content = joinText(m.Content, it.TextItem.Text)
+				}
+			}
+			if m.Content != "" {
+				appendAssistant(m)
+			}
+
+		case spec.InputKindReasoningMessage:
+			if in.ReasoningMessage != nil {
+				thinking = joinText(thinking, strings.Join(in.ReasoningMessage.Thinking, "\n"))
+			}
+
+		case spec.InputKindFunctionToolCall, spec.InputKindCustomToolCall:
+			call := in.FunctionToolCall
+			if call == nil {
+				call = in.CustomToolCall
+			}
+			if call == nil || strings.TrimSpace(call.Name) == "" {
+				continue
+			}
+			callNames[call.CallID] = call.Name
+			tc := ollamaToolCall{Function: ollamaToolCallFunction{
+				Name:      call.Name,
+				Arguments: json.RawMessage(toolCallArguments(json.RawMessage(call.Arguments))),
+			}}
+			if lastAssistant < 0 {
+				appendAssistant(ollamaMessage{})
+			}
+			out[lastAssistant].ToolCalls = append(out[lastAssistant].ToolCalls, tc)
+
+		case spec.InputKindFunctionToolOutput, spec.InputKindCustomToolOutput:
+			output := in.FunctionToolOutput
+			if output == nil {
+				output = in.CustomToolOutput
+			}
+			if output == nil {
+				continue
+			}
+			m := ollamaMessage{Role: string(spec.RoleTool), ToolName: callNames[output.CallID]}
+			if m.ToolName == "" {
+				m.ToolName = output.Name
+			}
+			for _, it := range sdkutil.ToolOutputContents(output, false) {
+				if it.Kind == spec.ContentItemKindText && it.TextItem != nil {
+					m.Content = joinText(m.Content, it.TextItem.Text)
+				}
+			}
+			out = append(out, m)
+			lastAssistant = -1
+
+		default:
+			// Hosted tools have no equivalent in a local server.
+			continue
+		}
+	}
+	return out
+}
+
+func outputsFromOllama(
+	resp *ollamaChatResponse,
+	callIDs []string,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) []spec.OutputUnion {
+	status := spec.StatusCompleted
+	if resp.DoneReason == "length" {
+		status = spec.StatusIncomplete
+	}
+
+	var outs []spec.OutputUnion
+	if resp.Message.Thinking != "" {
+		outs = append(outs, spec.OutputUnion{
+			Kind: spec.OutputKindReasoningMessage,
+			ReasoningMessage: &spec.ReasoningContent{
+				Role:     spec.RoleAssistant,
+				Status:   status,
+				Thinking: []string{resp.Message.Thinking},
+			},
+		})
+	}
+	if resp.Message.Content != "" {
+		outs = append(outs, spec.OutputUnion{
+			Kind: spec.OutputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{
+				Role:   spec.RoleAssistant,
+				Status: status,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: resp.Message.Content},
+				}},
+			},
+		})
+	}
+	for i, tc := range resp.Message.ToolCalls {
+		choice, ok := toolChoiceNameMap[tc.Function.Name]
+		if !ok || choice.ID == "" {
+			continue
+		}
+		id := ""
+		if i < len(callIDs) {
+			id = callIDs[i]
+		} else {
+			id = newCallID()
+		}
+		call := spec.ToolCall{
+			ChoiceID:  choice.ID,
+			Type:      choice.Type,
+			Role:      spec.RoleAssistant,
+			ID:        id,
+			CallID:    id,
+			Name:      tc.Function.Name,
+			Arguments: toolCallArguments(tc.Function.Arguments),
+			Status:    status,
+		}
+		if choice.Type == spec.ToolTypeCustom {
+			outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindCustomToolCall, CustomToolCall: &call})
+		} else {
+			outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindFunctionToolCall, FunctionToolCall: &call})
+		}
+	}
+	return outs
+}
+
+func usageFromOllama(resp *ollamaChatResponse) *spec.Usage {
+	return &spec.Usage{
+		InputTokensTotal:    resp.PromptEvalCount,
+		InputTokensUncached: resp.PromptEvalCount,
+		OutputTokens:        resp.EvalCount,
+	}
+}
+
+// toolCallArguments returns arguments as a JSON object string; Ollama requires an object, so anything else becomes
+// "{}".
+func toolCallArguments(args json.RawMessage) string {
+	var obj map[string]any
+	if err := json.Unmarshal(args, &obj); err != nil || obj == nil {
+		return "{}"
+	}
+	return string(args)
+}
+
+func joinText(a, b string) string {
+	b = strings.TrimSpace(b)
+	switch {
+	case b == "":
+		return a
+	case a == "":
+		return b
+	default:
+		return a + "\n" + b
+	}
+}
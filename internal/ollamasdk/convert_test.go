@@ -0,0 +1,179 @@
+package ollamasdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestToOllamaChatRequest(t *testing.T) {
+	t.Parallel()
+
+	user := spec.InputUnion{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role: spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: "weather in Paris?"},
+			}},
+		},
+	}
+	call := &spec.ToolCall{
+		Type: spec.ToolTypeFunction, ChoiceID: "w", CallID: "call_1", Name: "get_weather",
+		Arguments: `{"city":"Paris"}`,
+	}
+	output := &spec.ToolOutput{
+		Type: spec.ToolTypeFunction, ChoiceID: "w", CallID: "call_1",
+		Contents: []spec.ToolOutputItemUnion{{
+			Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: "sunny"},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		param   spec.ModelParam
+		ollama  *spec.OllamaOptions
+		inputs  []spec.InputUnion
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "NoOptions.",
+			inputs:  []spec.InputUnion{user},
+			want:    []string{`"messages":[{"role":"user","content":"weather in Paris?"}]`},
+			notWant: []string{`"options"`, `"think"`, `"keep_alive"`},
+		},
+		{
+			name:   "ProviderOptionsAndThinkLevel.",
+			param:  spec.ModelParam{Reasoning: &spec.ReasoningParam{Level: spec.ReasoningLevelHigh}},
+			ollama: &spec.OllamaOptions{KeepAlive: "10m", NumCtx: 8192},
+			inputs: []spec.InputUnion{user},
+			want:   []string{`"options":{"num_ctx":8192}`, `"think":"high"`, `"keep_alive":"10m"`},
+		},
+		{
+			name:   "ThinkingOff.",
+			param:  spec.ModelParam{Reasoning: &spec.ReasoningParam{Level: spec.ReasoningLevelNone}},
+			inputs: []spec.InputUnion{user},
+			want:   []string{`"think":false`},
+		},
+		{
+			name: "ReplaysToolCallAndNamedOutput.",
+			inputs: []spec.InputUnion{
+				user,
+				{Kind: spec.InputKindReasoningMessage, ReasoningMessage: &spec.ReasoningContent{
+					Role: spec.RoleAssistant, Thinking: []string{"hmm"},
+				}},
+				{Kind: spec.InputKindFunctionToolCall, FunctionToolCall: call},
+				{Kind: spec.InputKindFunctionToolOutput, FunctionToolOutput: output},
+			},
+			want: []string{
+				`{"role":"assistant","content":"","thinking":"hmm","tool_calls":[{"function":{"name":"get_weather",` +
+					`"arguments":{"city":"Paris"}}}]}`,
+				`{"role":"tool","content":"sunny","tool_name":"get_weather"}`,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.param.Name = "qwen3"
+			params, _ := toOllamaChatRequest(&spec.FetchCompletionRequest{
+				ModelParam: tc.param,
+				Inputs:     tc.inputs,
+				ToolChoices: []spec.ToolChoice{{
+					Type: spec.ToolTypeFunction, ID: "w", Name: "get_weather",
+					Arguments: map[string]any{"type": "object"},
+				}},
+			}, &spec.ProviderParam{Name: "ollama", Ollama: tc.ollama}, false)
+			b, err := json.Marshal(params)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v.", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(string(b), want) {
+					t.Fatalf("request = %s, want it to contain %s.", b, want)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(string(b), notWant) {
+					t.Fatalf("request = %s, want it not to contain %s.", b, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputsFromOllama(t *testing.T) {
+	t.Parallel()
+
+	toolChoiceNameMap := map[string]spec.ToolChoice{
+		"get_weather": {Type: spec.ToolTypeFunction, ID: "w", Name: "get_weather"},
+	}
+
+	tests := []struct {
+		name       string
+		response   string
+		callIDs    []string
+		wantKinds  []spec.OutputKind
+		wantStatus spec.Status
+		wantArgs   string
+	}{
+		{
+			name: "ThinkingTextAndCall.",
+			response: `{"model":"qwen3","message":{"role":"assistant","content":"Checking.","thinking":"hmm",` +
+				`"tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"Paris"}}}]},` +
+				`"done":true,"done_reason":"stop"}`,
+			callIDs: []string{"call_1"},
+			wantKinds: []spec.OutputKind{
+				spec.OutputKindReasoningMessage, spec.OutputKindOutputMessage, spec.OutputKindFunctionToolCall,
+			},
+			wantStatus: spec.StatusCompleted,
+			wantArgs:   `{"city":"Paris"}`,
+		},
+		{
+			name: "LengthIsIncomplete.",
+			response: `{"model":"qwen3","message":{"role":"assistant","content":"Check"},` +
+				`"done":true,"done_reason":"length"}`,
+			wantKinds:  []spec.OutputKind{spec.OutputKindOutputMessage},
+			wantStatus: spec.StatusIncomplete,
+		},
+		{
+			name: "NonObjectArgumentsBecomeEmptyObject.",
+			response: `{"model":"qwen3","message":{"role":"assistant","content":"",` +
+				`"tool_calls":[{"function":{"name":"get_weather","arguments":"Paris"}}]},"done":true}`,
+			wantKinds:  []spec.OutputKind{spec.OutputKindFunctionToolCall},
+			wantStatus: spec.StatusCompleted,
+			wantArgs:   `{}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var resp ollamaChatResponse
+			if err := json.Unmarshal([]byte(tc.response), &resp); err != nil {
+				t.Fatalf("Unmarshal() error = %v.", err)
+			}
+			outs := outputsFromOllama(&resp, tc.callIDs, toolChoiceNameMap)
+			var kinds []spec.OutputKind
+			for _, o := range outs {
+				kinds = append(kinds, o.Kind)
+				switch {
+				case o.OutputMessage != nil && o.OutputMessage.Status != tc.wantStatus:
+					t.Fatalf("Status = %q, want %q.", o.OutputMessage.Status, tc.wantStatus)
+				case o.FunctionToolCall != nil &&
+					(o.FunctionToolCall.CallID == "" || o.FunctionToolCall.Arguments != tc.wantArgs):
+					t.Fatalf("FunctionToolCall = %+v, want arguments %s.", o.FunctionToolCall, tc.wantArgs)
+				}
+			}
+			if !reflect.DeepEqual(kinds, tc.wantKinds) {
+				t.Fatalf("output kinds = %v, want %v.", kinds, tc.wantKinds)
+			}
+		})
+	}
+}
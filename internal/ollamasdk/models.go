@@ -0,0 +1,109 @@
+package ollamasdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name       string    `json:"name"`
+		Size       int64     `json:"size"`
+		Digest     string    `json:"digest"`
+		ModifiedAt time.Time `json:"modified_at"`
+		Details    struct {
+			Family            string `json:"family"`
+			ParameterSize     string `json:"parameter_size"`
+			QuantizationLevel string `json:"quantization_level"`
+		} `json:"details"`
+	} `json:"models"`
+}
+
+type ollamaPullResponse struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ListModels lists the models available on the server (/api/tags).
+func (api *OllamaChatAPI) ListModels(ctx context.Context) ([]spec.LocalModel, error) {
+	client, pi := api.snapshot()
+	if client == nil {
+		return nil, errors.New("ollama chat api LLM: client not initialized")
+	}
+	resp, err := api.do(ctx, client, &pi, http.MethodGet, apiURL(&pi, "/tags"), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		return nil, readAPIError(resp)
+	}
+
+	var tags ollamaTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, err
+	}
+	out := make([]spec.LocalModel, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		out = append(out, spec.LocalModel{
+			Name:              spec.ModelName(m.Name),
+			SizeBytes:         m.Size,
+			Digest:            m.Digest,
+			ModifiedAt:        m.ModifiedAt,
+			Family:            m.Details.Family,
+			ParameterSize:     m.Details.ParameterSize,
+			QuantizationLevel: m.Details.QuantizationLevel,
+		})
+	}
+	return out, nil
+}
+
+// PullModel downloads model (/api/pull), reporting each streamed status line to progress.
+func (api *OllamaChatAPI) PullModel(
+	ctx context.Context,
+	model spec.ModelName,
+	progress func(spec.LocalModelPullProgress),
+) error {
+	if model == "" {
+		return errors.New("ollama chat api LLM: empty model name")
+	}
+	client, pi := api.snapshot()
+	if client == nil {
+		return errors.New("ollama chat api LLM: client not initialized")
+	}
+	resp, err := api.post(ctx, client, &pi, apiURL(&pi, "/pull"), map[string]any{
+		"model":  string(model),
+		"stream": true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return readNDJSON(resp.Body, func(line []byte) error {
+		var p ollamaPullResponse
+		if err := json.Unmarshal(line, &p); err != nil {
+			return err
+		}
+		if p.Error != "" {
+			return &apiError{StatusCode: resp.StatusCode, Message: p.Error}
+		}
+		if progress != nil {
+			progress(spec.LocalModelPullProgress{
+				Status:    p.Status,
+				Digest:    p.Digest,
+				Total:     p.Total,
+				Completed: p.Completed,
+			})
+		}
+		return nil
+	})
+}
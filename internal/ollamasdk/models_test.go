@@ -0,0 +1,77 @@
+package ollamasdk
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestOllamaLocalModels(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"qwen3:8b","size":5200000000,"digest":"abc",` +
+				`"modified_at":"2025-05-01T10:00:00Z","details":{"family":"qwen3","parameter_size":"8.2B",` +
+				`"quantization_level":"Q4_K_M"}}]}`))
+		case "/api/pull":
+			b, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(b), `"missing"`) {
+				_, _ = w.Write([]byte(`{"error":"pull model manifest: file does not exist"}` + "\n"))
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"pulling manifest"}` + "\n" +
+				`{"status":"pulling abc","digest":"abc","total":10,"completed":10}` + "\n" +
+				`{"status":"success"}` + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	models, err := api.ListModels(t.Context())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v.", err)
+	}
+	if len(models) != 1 || models[0].Name != "qwen3:8b" || models[0].SizeBytes != 5200000000 ||
+		models[0].QuantizationLevel != "Q4_K_M" {
+		t.Fatalf("ListModels() = %+v, want qwen3:8b.", models)
+	}
+
+	tests := []struct {
+		name    string
+		model   spec.ModelName
+		want    []string
+		wantErr string
+	}{
+		{name: "Success.", model: "qwen3:8b", want: []string{"pulling manifest", "pulling abc", "success"}},
+		{name: "ServerError.", model: "missing", wantErr: "file does not exist"},
+		{name: "EmptyName.", wantErr: "empty model name"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var statuses []string
+			err := api.PullModel(t.Context(), tc.model, func(p spec.LocalModelPullProgress) {
+				statuses = append(statuses, p.Status)
+			})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("PullModel() error = %v, want %q.", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PullModel() error = %v.", err)
+			}
+			if !reflect.DeepEqual(statuses, tc.want) {
+				t.Fatalf("pull statuses = %v, want = %v.", statuses, tc.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package openaichatsdk
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -11,6 +14,7 @@ import (
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/packages/respjson"
 	"github.com/openai/openai-go/v3/shared"
 	openaiSharedConstant "github.com/openai/openai-go/v3/shared/constant"
 
@@ -47,7 +51,9 @@ func (api *OpenAIChatCompletionsAPI) InitLLM(ctx context.Context) error {
 		api.client = nil
 		return errors.New("openai chat completion api LLM: no ProviderParam found")
 	}
-	if strings.TrimSpace(api.ProviderParam.APIKey) == "" {
+	keyless := strings.TrimSpace(api.ProviderParam.APIKey) == "" && api.ProviderParam.Credentials == nil
+	// Local OpenAI-compatible servers (LM Studio, llama.cpp, ...) usually run without a key.
+	if keyless && api.ProviderParam.SDKType != spec.ProviderSDKTypeOpenAICompatible {
 		logutil.Debug(
 			string(
 				api.ProviderParam.Name,
@@ -61,6 +67,10 @@ func (api *OpenAIChatCompletionsAPI) InitLLM(ctx context.Context) error {
 	opts := []option.RequestOption{
 		option.WithAPIKey(pi.APIKey),
 	}
+	if keyless {
+		// Send no Authorization header, not even one taken from OPENAI_API_KEY.
+		opts = append(opts, option.WithHeaderDel(spec.DefaultAuthorizationHeaderKey))
+	}
 
 	providerURL := spec.DefaultOpenAIOrigin
 	if pi.Origin != "" {
@@ -72,6 +82,13 @@ func (api *OpenAIChatCompletionsAPI) InitLLM(ctx context.Context) error {
 			pathPrefix,
 			"chat/completions",
 		)
+		if pi.SDKType == spec.ProviderSDKTypeOpenAICompletions {
+			pathPrefix = strings.TrimSuffix(pathPrefix, "completions")
+		}
+		if pi.Azure != nil {
+			// Azure paths are derived from the resource endpoint by the Azure middleware.
+			pathPrefix = ""
+		}
 		providerURL = baseURL + pathPrefix
 		opts = append(opts, option.WithBaseURL(strings.TrimSuffix(providerURL, "/")))
 	}
@@ -80,7 +97,7 @@ func (api *OpenAIChatCompletionsAPI) InitLLM(ctx context.Context) error {
 		opts = append(opts, option.WithHeader(strings.TrimSpace(k), strings.TrimSpace(v)))
 	}
 
-	if pi.APIKeyHeaderKey != "" &&
+	if pi.APIKeyHeaderKey != "" && !keyless &&
 		!strings.EqualFold(
 			pi.APIKeyHeaderKey,
 			spec.DefaultAuthorizationHeaderKey,
@@ -91,10 +108,40 @@ func (api *OpenAIChatCompletionsAPI) InitLLM(ctx context.Context) error {
 		)
 	}
 
-	if api.debugger != nil {
-		if httpClient := api.debugger.HTTPClient(nil); httpClient != nil {
-			opts = append(opts, option.WithHTTPClient(httpClient))
+	if o := pi.OpenRouter; o != nil {
+		if o.AppURL != "" {
+			opts = append(opts, option.WithHeader("HTTP-Referer", o.AppURL))
 		}
+		if o.AppTitle != "" {
+			opts = append(opts, option.WithHeader("X-Title", o.AppTitle))
+		}
+	}
+
+	// Propagate spec.RequestContext from the request context as headers.
+	opts = append(opts, option.WithMiddleware(sdkutil.RequestContextMiddleware))
+
+	if pi.Azure != nil {
+		opts = append(opts, option.WithMiddleware(sdkutil.AzureMiddleware(pi.Azure, pi.APIKey)))
+	}
+	if pi.Credentials != nil {
+		opts = append(opts, option.WithMiddleware(sdkutil.CredentialsMiddleware(pi.Credentials)))
+	}
+
+	if pi.OriginFailover != nil && len(pi.OriginFailover.Origins) > 0 {
+		primary := spec.DefaultOpenAIOrigin
+		if pi.Origin != "" {
+			primary = pi.Origin
+		}
+		pool, err := sdkutil.NewOriginPool(primary, pi.OriginFailover)
+		if err != nil {
+			api.client = nil
+			return err
+		}
+		opts = append(opts, option.WithMiddleware(pool.Middleware))
+	}
+
+	if httpClient := sdkutil.HTTPClient(&pi, api.debugger); httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
 	}
 
 	c := openai.NewClient(opts...)
@@ -139,7 +186,9 @@ func (api *OpenAIChatCompletionsAPI) GetProviderInfo(ctx context.Context) *spec.
 func (api *OpenAIChatCompletionsAPI) IsConfigured(ctx context.Context) bool {
 	api.mu.RLock()
 	defer api.mu.RUnlock()
-	return api.ProviderParam != nil && strings.TrimSpace(api.ProviderParam.APIKey) != ""
+	return api.ProviderParam != nil && (api.ProviderParam.Credentials != nil ||
+		api.ProviderParam.SDKType == spec.ProviderSDKTypeOpenAICompatible ||
+		strings.TrimSpace(api.ProviderParam.APIKey) != "")
 }
 
 // SetProviderAPIKey sets the key for a provider.
@@ -160,10 +209,58 @@ func (api *OpenAIChatCompletionsAPI) SetProviderAPIKey(
 	return nil
 }
 
+// FetchRaw sends an arbitrary request to the provider, reusing the configured auth, base URL, debugger and retries.
+func (api *OpenAIChatCompletionsAPI) FetchRaw(
+	ctx context.Context,
+	method, path string,
+	body json.RawMessage,
+) (*spec.RawResponse, error) {
+	api.mu.RLock()
+	client := api.client
+	api.mu.RUnlock()
+	if client == nil {
+		return nil, errors.New("openai chat completions api LLM: client not initialized")
+	}
+	relPath, err := sdkutil.RawRequestPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var params any
+	if len(body) > 0 {
+		params = body
+	}
+	var httpResp *http.Response
+	if err := client.Execute(ctx, strings.ToUpper(method), relPath, params, &httpResp); err != nil {
+		// Error statuses are returned like any other response; the SDK keeps the body on the error.
+		var apiErr *openai.Error
+		if errors.As(err, &apiErr) && apiErr.Response != nil {
+			return sdkutil.ReadRawResponse(apiErr.Response)
+		}
+		return nil, err
+	}
+	return sdkutil.ReadRawResponse(httpResp)
+}
+
 func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 	ctx context.Context,
 	req *spec.FetchCompletionRequest,
 	opts *spec.FetchCompletionOptions,
+) (resp *spec.FetchCompletionResponse, err error) {
+	api.mu.RLock()
+	var providerName spec.ProviderName
+	if api.ProviderParam != nil {
+		providerName = api.ProviderParam.Name
+	}
+	api.mu.RUnlock()
+	defer sdkutil.RecoverFetchCompletion(providerName, &resp, &err)
+
+	return api.fetchCompletion(ctx, req, opts)
+}
+
+func (api *OpenAIChatCompletionsAPI) fetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
 ) (*spec.FetchCompletionResponse, error) {
 	api.mu.RLock()
 	client := api.client
@@ -173,12 +270,15 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 	}
 	api.mu.RUnlock()
 
-	if client == nil {
+	if client == nil && (opts == nil || !opts.DryRun) {
 		return nil, errors.New("openai chat completions api LLM: client not initialized")
 	}
 	if req == nil || len(req.Inputs) == 0 || req.ModelParam.Name == "" {
 		return nil, errors.New("openai chat completions api LLM: empty completion data")
 	}
+	if pi.SDKType == spec.ProviderSDKTypeOpenAICompletions {
+		return api.fetchLegacyCompletion(ctx, client, &pi, req, opts)
+	}
 
 	// Build OpenAI chat messages.
 	msgs, err := toOpenAIChatMessages(
@@ -186,14 +286,14 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 		req.ModelParam.SystemPrompt,
 		req.Inputs,
 		req.ModelParam.Name,
-		pi.Name,
+		&pi,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	params := openai.ChatCompletionNewParams{
-		Model:    shared.ChatModel(req.ModelParam.Name),
+		Model:    shared.ChatModel(pi.Azure.Deployment(req.ModelParam.Name)),
 		Messages: msgs,
 	}
 	if req.ModelParam.MaxOutputLength > 0 {
@@ -202,6 +302,24 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 	if t := req.ModelParam.Temperature; t != nil {
 		params.Temperature = openai.Float(*t)
 	}
+	if p := req.ModelParam.TopP; p != nil {
+		params.TopP = openai.Float(*p)
+	}
+	if p := req.ModelParam.FrequencyPenalty; p != nil {
+		params.FrequencyPenalty = openai.Float(*p)
+	}
+	if p := req.ModelParam.PresencePenalty; p != nil {
+		params.PresencePenalty = openai.Float(*p)
+	}
+	if n := req.ModelParam.NumChoices; n > 1 {
+		params.N = openai.Int(int64(n))
+	}
+	if req.ModelParam.Logprobs || req.ModelParam.TopLogprobs > 0 {
+		params.Logprobs = openai.Bool(true)
+		if n := req.ModelParam.TopLogprobs; n > 0 {
+			params.TopLogprobs = openai.Int(int64(n))
+		}
+	}
 
 	if rp := req.ModelParam.Reasoning; rp != nil &&
 		rp.Type == spec.ReasoningTypeSingleWithLevels {
@@ -219,10 +337,7 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 
 		}
 	}
-	timeout := spec.DefaultAPITimeout
-	if req.ModelParam.Timeout > 0 {
-		timeout = time.Duration(req.ModelParam.Timeout) * time.Second
-	}
+	timeout := pi.Timeouts.Timeout(req.ModelParam.Name, req.ModelParam.Timeout)
 	// Optional: stop sequences (Chat Completions supports up to 4).
 	if len(req.ModelParam.StopSequences) > 0 {
 		if len(req.ModelParam.StopSequences) > 4 {
@@ -240,6 +355,20 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 		return nil, err
 	}
 
+	// Optional: grammar-constrained decoding and beam search for local servers and Fireworks.
+	constraint := req.ModelParam.Constraint
+	if constraint != nil && constraint.Backend == "" && pi.SDKType == spec.ProviderSDKTypeFireworks {
+		c := *constraint
+		c.Backend = spec.DecodingConstraintBackendFireworks
+		constraint = &c
+	}
+	if err := applyOpenAIChatConstraint(&params, constraint); err != nil {
+		return nil, err
+	}
+	if err := applyOpenAIChatBeamSearch(&params, req.ModelParam.BeamSearch); err != nil {
+		return nil, err
+	}
+
 	var toolChoiceNameMap map[string]spec.ToolChoice
 	if len(req.ToolChoices) > 0 {
 		toolDefs, nameMap, err := toolChoicesToOpenAIChatTools(req.ToolChoices)
@@ -263,6 +392,26 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 		}
 	}
 
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	applyOpenAIChatQuirks(&params, pi.Quirks)
+	if pi.SDKType == spec.ProviderSDKTypeOpenRouter {
+		applyOpenRouterOptions(&params, pi.OpenRouter)
+	}
+	if pi.SDKType == spec.ProviderSDKTypeOpenAICompatible {
+		applyOpenAICompatibleCapabilities(&params, pi.Capabilities, useStream)
+	}
+	if err := applyOpenAIChatParamOverlay(&params, req.ModelParam.AdditionalParametersRawJSON); err != nil {
+		return nil, err
+	}
+
+	effective := params
+	effective.Messages, effective.Tools = nil, nil
+	effectiveParams := sdkutil.EffectiveParams(effective, "messages", "tools")
+
+	if opts != nil && opts.DryRun {
+		return sdkutil.DryRunResponse(&pi, req.ModelParam.Name, useStream, params)
+	}
+
 	var span spec.CompletionSpan
 	if api.debugger != nil {
 		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
@@ -278,8 +427,21 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 		fullRawResp    *openai.ChatCompletion
 		apiErr         error
 	)
-	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
-	if useStream {
+	switch {
+	case useStream && pi.SDKType == spec.ProviderSDKTypeOpenAICompatibleSSE:
+		normalizedResp, fullRawResp, apiErr = api.doCustomSSEStreaming(
+			ctx,
+			client,
+			pi.Name,
+			req.ModelParam.Name,
+			pi.SSEStreamSchema,
+			pi.Quirks == nil || !pi.Quirks.NoStreamUsage,
+			params,
+			opts,
+			timeout,
+			toolChoiceNameMap,
+		)
+	case useStream:
 		normalizedResp, fullRawResp, apiErr = api.doStreaming(
 			ctx,
 			client,
@@ -290,8 +452,20 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 			timeout,
 			toolChoiceNameMap,
 		)
-	} else {
-		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(ctx, client, params, timeout, toolChoiceNameMap)
+	default:
+		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(
+			ctx,
+			client,
+			pi.Name,
+			params,
+			timeout,
+			toolChoiceNameMap,
+		)
+	}
+
+	normalizedResp = sdkutil.FinalizeResponse(pi.Name, normalizedResp, apiErr)
+	if normalizedResp != nil {
+		normalizedResp.Metadata = &spec.ResponseMetadata{EffectiveParams: effectiveParams}
 	}
 
 	if span != nil {
@@ -315,6 +489,7 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 func (api *OpenAIChatCompletionsAPI) doNonStreaming(
 	ctx context.Context,
 	client *openai.Client,
+	providerName spec.ProviderName,
 	params openai.ChatCompletionNewParams,
 	timeout time.Duration,
 	toolChoiceNameMap map[string]spec.ToolChoice,
@@ -325,11 +500,19 @@ func (api *OpenAIChatCompletionsAPI) doNonStreaming(
 
 	resp.Usage = usageFromOpenAIChatCompletion(oaiResp)
 	if err != nil {
-		resp.Error = &spec.Error{Message: err.Error()}
+		resp.Error = providerError(providerName, err)
 		return resp, oaiResp, err
 	}
 
 	resp.Outputs = outputsFromOpenAIChatCompletion(oaiResp, toolChoiceNameMap)
+	resp.Outputs = withSearchGrounding(
+		resp.Outputs,
+		oaiResp.ID,
+		oaiResp.JSON.ExtraFields["citations"].Raw(),
+		oaiResp.JSON.ExtraFields["search_results"].Raw(),
+		toolChoiceNameMap,
+	)
+	resp.Choices = choicesFromOpenAIChatCompletion(oaiResp, resp.Outputs, toolChoiceNameMap)
 
 	return resp, oaiResp, nil
 }
@@ -345,28 +528,13 @@ func (api *OpenAIChatCompletionsAPI) doStreaming(
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *openai.ChatCompletion, error) {
 	resp := &spec.FetchCompletionResponse{}
-	streamCfg := sdkutil.ResolveStreamConfig(opts)
-	// No thinking data available in openai chat completions API, hence no thinking writer.
-	emitText := func(chunk string) error {
-		if strings.TrimSpace(chunk) == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:     spec.StreamContentKindText,
-			Provider: providerName,
-			Model:    modelName,
-			Text: &spec.StreamTextChunk{
-				Text: chunk,
-			},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
-
-	// No thinking data available in openai chat completions API, hence no thinking writer.
-	writeText, flushText := sdkutil.NewBufferedStreamer(
-		emitText,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
+	// OpenAI itself streams no thinking; compatible backends may send reasoning_content deltas.
+	pipeline := sdkutil.NewStreamPipeline(
+		ctx,
+		opts.StreamHandler,
+		providerName,
+		modelName,
+		sdkutil.ResolveStreamConfig(opts),
 	)
 
 	stream := client.Chat.Completions.NewStreaming(
@@ -376,47 +544,206 @@ func (api *OpenAIChatCompletionsAPI) doStreaming(
 	)
 	defer func() { _ = stream.Close() }()
 
+	emitToolCall := func(chunk *spec.StreamToolCallChunk) error {
+		return pipeline.Emit(spec.StreamEvent{Kind: spec.StreamContentKindToolCall, ToolCall: chunk})
+	}
+	toolCalls := newChatToolCallStreamTracker(toolChoiceNameMap)
+
 	acc := openai.ChatCompletionAccumulator{}
-	var streamWriteErr error
+	var (
+		streamWriteErr error
+		cost           *float64
+		reasoning      strings.Builder
+		citations      string
+		searchResults  string
+	)
 	for stream.Next() {
 		chunk := stream.Current()
 		acc.AddChunk(chunk)
-
-		// When JustFinished* triggers, the current chunk isn't textual content.
-		if _, ok := acc.JustFinishedContent(); ok {
-			continue
+		// The accumulator only sums token counts; the reported cost arrives with the final usage chunk.
+		if c := usageCost(chunk.Usage); c != nil {
+			cost = c
 		}
-
-		if _, ok := acc.JustFinishedRefusal(); ok {
-			continue
+		// Grounded backends (Perplexity) repeat citations and search results on chunks; keep the latest.
+		if raw := chunk.JSON.ExtraFields["citations"].Raw(); raw != "" && raw != "null" {
+			citations = raw
+		}
+		if raw := chunk.JSON.ExtraFields["search_results"].Raw(); raw != "" && raw != "null" {
+			searchResults = raw
 		}
 
-		if _, ok := acc.JustFinishedToolCall(); ok {
+		// JustFinishedToolCall is not reliable with parallel tool calls, so tool call lifecycle is tracked per index
+		// directly from the chunk deltas instead. A single chunk may carry both text and tool call deltas.
+		// Only the first choice is streamed; servers returning several (n > 1, beam search) send one per chunk.
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Index != 0 {
 			continue
 		}
-
-		// Best to use chunks after handling JustFinished events.
-		if len(chunk.Choices) > 0 && strings.TrimSpace(chunk.Choices[0].Delta.Content) != "" {
-			streamWriteErr = writeText(chunk.Choices[0].Delta.Content)
+		choice := chunk.Choices[0]
+		if r := reasoningContent(choice.Delta.JSON.ExtraFields); r != "" {
+			reasoning.WriteString(r)
+			if streamWriteErr = pipeline.WriteThinking(sdkutil.StreamPosition{}, r); streamWriteErr != nil {
+				break
+			}
+		}
+		if strings.TrimSpace(choice.Delta.Content) != "" {
+			streamWriteErr = pipeline.WriteText(sdkutil.StreamPosition{}, choice.Delta.Content)
+			if streamWriteErr != nil {
+				break
+			}
+		}
+		if streamWriteErr = emitChatDeltaCitations(pipeline, choice.Delta.JSON.ExtraFields); streamWriteErr != nil {
+			break
+		}
+		if len(choice.Logprobs.Content) > 0 {
+			streamWriteErr = pipeline.Emit(spec.StreamEvent{
+				Kind:     spec.StreamContentKindLogprobs,
+				Logprobs: &spec.StreamLogprobsChunk{Tokens: tokenLogprobs(choice.Logprobs.Content)},
+			})
 			if streamWriteErr != nil {
 				break
 			}
 		}
+		if len(choice.Delta.ToolCalls) > 0 {
+			if streamWriteErr = toolCalls.addDeltas(choice.Delta.ToolCalls, emitToolCall); streamWriteErr != nil {
+				break
+			}
+		}
+		if choice.FinishReason != "" {
+			if streamWriteErr = toolCalls.finishAll(emitToolCall); streamWriteErr != nil {
+				break
+			}
+		}
+	}
+	if streamWriteErr == nil && stream.Err() == nil {
+		// Some compatible backends end the stream without a finish reason.
+		streamWriteErr = toolCalls.finishAll(emitToolCall)
 	}
-	if flushText != nil {
-		flushText()
+	if err := pipeline.Close(); err != nil && streamWriteErr == nil {
+		streamWriteErr = err
 	}
 
 	streamErr := errors.Join(stream.Err(), streamWriteErr)
-
 	resp.Usage = usageFromOpenAIChatCompletion(&acc.ChatCompletion)
+	if cost != nil {
+		resp.Usage.Cost = cost
+	}
+	pipeline.EmitUsage(resp.Usage)
 	if streamErr != nil {
-		resp.Error = &spec.Error{Message: streamErr.Error()}
+		resp.Error = providerError(providerName, streamErr)
+		pipeline.EmitError(resp.Error)
 	}
-	resp.Outputs = outputsFromOpenAIChatCompletion(&acc.ChatCompletion, toolChoiceNameMap)
+	var finishReason string
+	if len(acc.Choices) > 0 {
+		finishReason = acc.Choices[0].FinishReason
+	}
+	resp.Outputs = reasoningOutputs(acc.ID, reasoning.String(), mapOpenAIChatFinishReasonToStatus(finishReason))
+	resp.Outputs = append(resp.Outputs, outputsFromOpenAIChatCompletion(&acc.ChatCompletion, toolChoiceNameMap)...)
+	resp.Outputs = withSearchGrounding(resp.Outputs, acc.ID, citations, searchResults, toolChoiceNameMap)
+	resp.Choices = choicesFromOpenAIChatCompletion(&acc.ChatCompletion, resp.Outputs, toolChoiceNameMap)
 	return resp, &acc.ChatCompletion, streamErr
 }
 
+// providerError returns the spec.Error of a failed call, with the status, code and body of an OpenAI API error.
+func providerError(provider spec.ProviderName, err error) *spec.Error {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return sdkutil.NewError(provider, err, apiErr.StatusCode, cmp.Or(apiErr.Code, apiErr.Type), apiErr.RawJSON())
+	}
+	return sdkutil.NewError(provider, err, 0, "", "")
+}
+
+type chatStreamToolCall struct {
+	id       string
+	name     string
+	args     strings.Builder
+	finished bool
+}
+
+// chatToolCallStreamTracker turns Chat Completions tool call deltas into start/delta/finish stream events.
+// Tool calls are keyed by their delta index so that interleaved parallel tool calls are tracked independently.
+type chatToolCallStreamTracker struct {
+	toolChoiceNameMap map[string]spec.ToolChoice
+	calls             map[int64]*chatStreamToolCall
+	order             []int64
+}
+
+func newChatToolCallStreamTracker(toolChoiceNameMap map[string]spec.ToolChoice) *chatToolCallStreamTracker {
+	return &chatToolCallStreamTracker{
+		toolChoiceNameMap: toolChoiceNameMap,
+		calls:             map[int64]*chatStreamToolCall{},
+	}
+}
+
+func (t *chatToolCallStreamTracker) addDeltas(
+	deltas []openai.ChatCompletionChunkChoiceDeltaToolCall,
+	emit func(*spec.StreamToolCallChunk) error,
+) error {
+	for _, d := range deltas {
+		c, ok := t.calls[d.Index]
+		if !ok {
+			c = &chatStreamToolCall{}
+			t.calls[d.Index] = c
+			t.order = append(t.order, d.Index)
+		}
+		if c.id == "" && d.ID != "" {
+			c.id = d.ID
+		}
+		if c.name == "" && d.Function.Name != "" {
+			c.name = d.Function.Name
+		}
+		if !ok {
+			if err := emit(t.chunk(d.Index, c, spec.StreamToolCallPhaseStart)); err != nil {
+				return err
+			}
+		}
+		if d.Function.Arguments == "" {
+			continue
+		}
+		c.args.WriteString(d.Function.Arguments)
+		ev := t.chunk(d.Index, c, spec.StreamToolCallPhaseDelta)
+		ev.ArgumentsDelta = d.Function.Arguments
+		if err := emit(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *chatToolCallStreamTracker) finishAll(emit func(*spec.StreamToolCallChunk) error) error {
+	for _, idx := range t.order {
+		c := t.calls[idx]
+		if c.finished {
+			continue
+		}
+		c.finished = true
+		ev := t.chunk(idx, c, spec.StreamToolCallPhaseFinish)
+		ev.Arguments = c.args.String()
+		if err := emit(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *chatToolCallStreamTracker) chunk(
+	idx int64,
+	c *chatStreamToolCall,
+	phase spec.StreamToolCallPhase,
+) *spec.StreamToolCallChunk {
+	out := &spec.StreamToolCallChunk{
+		Phase:  phase,
+		Index:  int(idx),
+		Type:   spec.ToolTypeFunction,
+		CallID: c.id,
+		Name:   c.name,
+	}
+	if tc, ok := t.toolChoiceNameMap[c.name]; ok {
+		out.ChoiceID = tc.ID
+		out.Type = tc.Type
+	}
+	return out
+}
+
 func applyOpenAIChatOutputParam(params *openai.ChatCompletionNewParams, op *spec.OutputParam) error {
 	if params == nil || op == nil {
 		return nil
@@ -469,6 +796,30 @@ func applyOpenAIChatOutputParam(params *openai.ChatCompletionNewParams, op *spec
 	}
 }
 
+// applyOpenAIChatQuirks adjusts fully built params to the provider quirks.
+func applyOpenAIChatQuirks(params *openai.ChatCompletionNewParams, quirks *spec.ProviderQuirks) {
+	if quirks == nil {
+		return
+	}
+	if quirks.NoParallelToolCalls {
+		params.ParallelToolCalls = param.Opt[bool]{}
+	}
+	if quirks.RequiresMaxTokens && !params.MaxCompletionTokens.Valid() {
+		maxTokens := quirks.MaxTokens
+		if maxTokens <= 0 {
+			maxTokens = spec.DefaultQuirksMaxTokens
+		}
+		params.MaxCompletionTokens = openai.Int(int64(maxTokens))
+	}
+	if quirks.LegacyMaxTokensField && params.MaxCompletionTokens.Valid() {
+		params.MaxTokens = params.MaxCompletionTokens
+		params.MaxCompletionTokens = param.Opt[int64]{}
+	}
+	if quirks.FixedTemperature != nil {
+		params.Temperature = openai.Float(*quirks.FixedTemperature)
+	}
+}
+
 func applyOpenAIChatToolPolicy(
 	params *openai.ChatCompletionNewParams,
 	policy *spec.ToolPolicy,
@@ -506,13 +857,18 @@ func applyOpenAIChatToolPolicy(
 
 		allowedChoices := make([]map[string]any, 0, len(resolvedTools))
 		for _, t := range resolvedTools {
-			// We register tools as function tools in toolChoicesToOpenAIChatTools
-			// (even when the original ToolChoice.Type is "custom"), so tool_choice
-			// must reference "function".
+			// toolChoicesToOpenAIChatTools registers tools as function tools, except custom tools with a
+			// format, so tool_choice must reference "function" for every other tool.
 			c := map[string]any{
 				"type":     "function",
 				"function": map[string]string{"name": t.Name},
 			}
+			if sdkutil.IsFormattedCustomTool(toolChoiceNameMap[t.Name]) {
+				c = map[string]any{
+					"type":   "custom",
+					"custom": map[string]string{"name": t.Name},
+				}
+			}
 			allowedChoices = append(allowedChoices, c)
 		}
 		if policy.Mode == spec.ToolPolicyModeTool {
@@ -540,12 +896,12 @@ func toOpenAIChatMessages(
 	systemPrompt string,
 	inputs []spec.InputUnion,
 	modelName spec.ModelName,
-	providerName spec.ProviderName,
+	pi *spec.ProviderParam,
 ) ([]openai.ChatCompletionMessageParamUnion, error) {
 	var out []openai.ChatCompletionMessageParamUnion
 
 	// Top-level system/developer instructions.
-	if msg := getOpenAIMessageFromSystemPrompt(providerName, modelName, systemPrompt); msg != nil {
+	if msg := getOpenAIMessageFromSystemPrompt(pi, modelName, systemPrompt); msg != nil {
 		out = append(out, *msg)
 	}
 
@@ -609,6 +965,13 @@ func toOpenAIChatMessages(
 			// Chat Completions doesn't expose web search as a tool;
 			// it is configured via top-level web_search_options instead.
 			continue
+
+		case spec.InputKindCodeExecutionToolCall, spec.InputKindCodeExecutionToolOutput,
+			spec.InputKindImageGenerationToolOutput, spec.InputKindCodeInterpreterToolCall,
+			spec.InputKindFileSearchToolCall, spec.InputKindComputerToolCall, spec.InputKindComputerToolOutput,
+			spec.InputKindMCPToolCall, spec.InputKindMCPListTools:
+			// Chat Completions has no hosted code execution, image generation, file search, computer use or MCP.
+			continue
 		}
 	}
 
@@ -665,6 +1028,12 @@ func contentItemsToOpenAIUserMessageParts(
 			}
 			f := it.FileItem
 
+			if id := strings.TrimSpace(f.FileID); id != "" {
+				out = append(out, openai.FileContentPart(openai.ChatCompletionContentPartFileFileParam{
+					FileID: param.NewOpt(id),
+				}))
+				continue
+			}
 			// Embedded data as data URL.
 			// Chat completions doesn't support sending file URL only.
 			if data := strings.TrimSpace(f.FileData); data != "" {
@@ -787,12 +1156,16 @@ func toolCallToOpenAIChatAssistantMessage(
 func toolOutputToOpenAIChatMessages(
 	output *spec.ToolOutput,
 ) *openai.ChatCompletionMessageParamUnion {
-	if output == nil || strings.TrimSpace(output.CallID) == "" || len(output.Contents) == 0 {
+	if output == nil || strings.TrimSpace(output.CallID) == "" {
+		return nil
+	}
+	contents := sdkutil.ToolOutputContents(output, false)
+	if len(contents) == 0 {
 		return nil
 	}
 
 	parts := make([]openai.ChatCompletionContentPartTextParam, 0)
-	for _, it := range output.Contents {
+	for _, it := range contents {
 		if it.Kind == spec.ContentItemKindText && it.TextItem != nil {
 			if s := strings.TrimSpace(it.TextItem.Text); s != "" {
 				parts = append(parts, openai.ChatCompletionContentPartTextParam{
@@ -809,7 +1182,7 @@ func toolOutputToOpenAIChatMessages(
 // getOpenAIMessageFromSystemPrompt returns a single system/developer message
 // based on the model and provider.
 func getOpenAIMessageFromSystemPrompt(
-	providerName spec.ProviderName,
+	pi *spec.ProviderParam,
 	modelName spec.ModelName,
 	systemPrompt string,
 ) *openai.ChatCompletionMessageParamUnion {
@@ -818,15 +1191,28 @@ func getOpenAIMessageFromSystemPrompt(
 		return nil
 	}
 	msg := openai.SystemMessage(sp)
-	// Convert a system message to a developer message for o* / gpt-5* models.
-	if providerName == "openai" &&
-		(strings.HasPrefix(string(modelName), "o") ||
-			strings.HasPrefix(string(modelName), "gpt-5")) {
+	if usesDeveloperRole(pi, modelName) {
 		msg = openai.DeveloperMessage(sp)
 	}
 	return &msg
 }
 
+// usesDeveloperRole applies the provider's developer role policy. Without a policy, o* / gpt-5* models on the
+// "openai" provider get the developer role. The NoDeveloperRole quirk always wins.
+func usesDeveloperRole(pi *spec.ProviderParam, modelName spec.ModelName) bool {
+	if pi == nil || (pi.Quirks != nil && pi.Quirks.NoDeveloperRole) {
+		return false
+	}
+	if pi.DeveloperRole != nil {
+		return pi.DeveloperRole.UsesDeveloperRole(modelName)
+	}
+	if pi.Name != "openai" {
+		return false
+	}
+	def := spec.DeveloperRolePolicy{Models: spec.DefaultDeveloperRoleModels}
+	return def.UsesDeveloperRole(modelName)
+}
+
 func toolChoicesToOpenAIChatTools(
 	toolChoices []spec.ToolChoice,
 ) ([]openai.ChatCompletionToolUnionParam, map[string]spec.ToolChoice, error) {
@@ -843,10 +1229,21 @@ func toolChoicesToOpenAIChatTools(
 
 		switch tc.Type {
 		case spec.ToolTypeFunction, spec.ToolTypeCustom:
+			if sdkutil.IsFormattedCustomTool(tc) {
+				if name == "" {
+					continue
+				}
+				custom, err := customToolToOpenAIChatTool(name, tc)
+				if err != nil {
+					return nil, nil, err
+				}
+				out = append(out, custom)
+				continue
+			}
 			if tc.Arguments == nil || name == "" {
 				continue
 			}
-			// For now, both function and custom tools are expressed as function tools,
+			// Function tools, and custom tools without a format, are expressed as function tools,
 			// mirroring the Responses adapter behavior.
 			fn := shared.FunctionDefinitionParam{
 				Name:       name,
@@ -855,6 +1252,10 @@ func toolChoicesToOpenAIChatTools(
 			if desc := sdkutil.ToolDescription(tc); desc != "" {
 				fn.Description = openai.String(desc)
 			}
+			if tc.Strict {
+				fn.Parameters = sdkutil.StrictJSONSchema(tc.Arguments)
+				fn.Strict = openai.Bool(true)
+			}
 			out = append(out, openai.ChatCompletionFunctionTool(fn))
 
 		case spec.ToolTypeWebSearch:
@@ -869,6 +1270,28 @@ func toolChoicesToOpenAIChatTools(
 	return out, nameMap, nil
 }
 
+// customToolToOpenAIChatTool maps a custom tool with a format to a Chat Completions custom tool.
+func customToolToOpenAIChatTool(name string, tc spec.ToolChoice) (openai.ChatCompletionToolUnionParam, error) {
+	if err := sdkutil.ValidateCustomToolFormat(tc); err != nil {
+		return openai.ChatCompletionToolUnionParam{}, fmt.Errorf("openai chat.completions: %w", err)
+	}
+	custom := openai.ChatCompletionCustomToolCustomParam{Name: name}
+	if desc := sdkutil.ToolDescription(tc); desc != "" {
+		custom.Description = openai.String(desc)
+	}
+	if tc.Format.Kind == spec.CustomToolFormatKindGrammar {
+		custom.Format.OfGrammar = &openai.ChatCompletionCustomToolCustomFormatGrammarParam{
+			Grammar: openai.ChatCompletionCustomToolCustomFormatGrammarGrammarParam{
+				Definition: tc.Format.Definition,
+				Syntax:     string(tc.Format.Syntax),
+			},
+		}
+	} else {
+		custom.Format.OfText = &openai.ChatCompletionCustomToolCustomFormatTextParam{}
+	}
+	return openai.ChatCompletionCustomTool(custom), nil
+}
+
 // firstWebSearchToolChoice returns the first ToolChoice of type webSearch, if any.
 func firstWebSearchToolChoice(tools []spec.ToolChoice) *spec.ToolChoice {
 	for i := range tools {
@@ -927,12 +1350,40 @@ func outputsFromOpenAIChatCompletion(
 	if resp == nil || len(resp.Choices) == 0 {
 		return nil
 	}
+	return outputsFromOpenAIChatChoice(resp.ID, resp.Choices[0], toolChoiceNameMap)
+}
+
+// choicesFromOpenAIChatCompletion returns the outputs of every choice when the completion has more than one (n > 1
+// or beam search). first holds the already converted outputs of the first choice.
+func choicesFromOpenAIChatCompletion(
+	resp *openai.ChatCompletion,
+	first []spec.OutputUnion,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) []spec.ChoiceOutputs {
+	if resp == nil || len(resp.Choices) < 2 {
+		return nil
+	}
+	choices := make([]spec.ChoiceOutputs, 0, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		outs := first
+		if i > 0 {
+			outs = outputsFromOpenAIChatChoice(resp.ID, choice, toolChoiceNameMap)
+		}
+		choices = append(choices, spec.ChoiceOutputs{Index: int(choice.Index), Outputs: outs})
+	}
+	return choices
+}
 
-	choice := resp.Choices[0]
+func outputsFromOpenAIChatChoice(
+	id string,
+	choice openai.ChatCompletionChoice,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) []spec.OutputUnion {
 	msg := choice.Message
 	status := mapOpenAIChatFinishReasonToStatus(choice.FinishReason)
 
-	var outs []spec.OutputUnion
+	// Reasoning text of compatible backends (reasoning_content) precedes the answer.
+	outs := reasoningOutputs(id, reasoningContent(msg.JSON.ExtraFields), status)
 
 	// Assistant text output.
 	if refusal := strings.TrimSpace(msg.Refusal); refusal != "" {
@@ -941,7 +1392,7 @@ func outputsFromOpenAIChatCompletion(
 		}
 
 		outMsg := spec.InputOutputContent{
-			ID:   resp.ID,
+			ID:   id,
 			Role: spec.RoleAssistant,
 			// Chat Completions does not expose per-block status; use finish_reason.
 			Status: status,
@@ -964,9 +1415,12 @@ func outputsFromOpenAIChatCompletion(
 		if len(msg.Annotations) > 0 {
 			textItem.Citations = chatAnnotationsToCitations(msg.Annotations)
 		}
+		if len(choice.Logprobs.Content) > 0 {
+			textItem.Logprobs = tokenLogprobs(choice.Logprobs.Content)
+		}
 
 		outMsg := spec.InputOutputContent{
-			ID:   resp.ID,
+			ID:   id,
 			Role: spec.RoleAssistant,
 			// Chat Completions does not expose per-block status; use finish_reason.
 			Status: status,
@@ -1092,6 +1546,26 @@ func chatAnnotationsToCitations(
 	return out
 }
 
+// emitChatDeltaCitations delivers the URL citations of a chunk delta. The SDK does not model annotations on deltas,
+// so they are read from the extra fields.
+func emitChatDeltaCitations(pipeline *sdkutil.StreamPipeline, extra map[string]respjson.Field) error {
+	raw := extra["annotations"].Raw()
+	if raw == "" || raw == "null" {
+		return nil
+	}
+	var anns []openai.ChatCompletionMessageAnnotation
+	if json.Unmarshal([]byte(raw), &anns) != nil {
+		// Malformed annotations are not worth failing the stream for.
+		return nil
+	}
+	for _, c := range chatAnnotationsToCitations(anns) {
+		if err := pipeline.Emit(spec.StreamEvent{Kind: spec.StreamContentKindCitation, Citation: &c}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func mapOpenAIChatFinishReasonToStatus(reason string) spec.Status {
 	switch reason {
 	case "length":
@@ -1119,6 +1593,52 @@ func usageFromOpenAIChatCompletion(resp *openai.ChatCompletion) *spec.Usage {
 	uOut.InputTokensUncached = max(u.PromptTokens-u.PromptTokensDetails.CachedTokens, 0)
 	uOut.OutputTokens = u.CompletionTokens
 	uOut.ReasoningTokens = u.CompletionTokensDetails.ReasoningTokens
+	uOut.Cost = usageCost(u)
 
 	return uOut
 }
+
+func tokenLogprobs(in []openai.ChatCompletionTokenLogprob) []spec.TokenLogprob {
+	out := make([]spec.TokenLogprob, 0, len(in))
+	for _, t := range in {
+		tl := spec.TokenLogprob{Token: t.Token, Logprob: t.Logprob, Bytes: logprobBytes(t.Bytes)}
+		for _, top := range t.TopLogprobs {
+			tl.TopLogprobs = append(tl.TopLogprobs, spec.TopLogprob{
+				Token:   top.Token,
+				Logprob: top.Logprob,
+				Bytes:   logprobBytes(top.Bytes),
+			})
+		}
+		out = append(out, tl)
+	}
+	return out
+}
+
+func logprobBytes(in []int64) []int {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]int, len(in))
+	for i, b := range in {
+		out[i] = int(b)
+	}
+	return out
+}
+
+// openAIChatOverlayDeny are the request fields AdditionalParametersRawJSON cannot set.
+var openAIChatOverlayDeny = []string{"model", "messages", "tools", "stream"}
+
+// applyOpenAIChatParamOverlay merges ModelParam.AdditionalParametersRawJSON into the request body, on top of the
+// extensions set from constraints, beam search and provider options.
+func applyOpenAIChatParamOverlay(params *openai.ChatCompletionNewParams, raw *string) error {
+	overlay, err := sdkutil.ParseParamOverlay(raw, openAIChatOverlayDeny...)
+	if err != nil || overlay == nil {
+		return err
+	}
+	extra, err := sdkutil.OverlayFields(params, params.ExtraFields(), overlay)
+	if err != nil {
+		return err
+	}
+	params.SetExtraFields(extra)
+	return nil
+}
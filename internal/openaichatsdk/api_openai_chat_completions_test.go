@@ -0,0 +1,448 @@
+package openaichatsdk
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// newTestAPI returns an initialized adapter for pi, pointed at a server running handler.
+func newTestAPI(t *testing.T, pi spec.ProviderParam, handler http.HandlerFunc) *OpenAIChatCompletionsAPI {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	pi.Origin = srv.URL
+	api, err := NewOpenAIChatCompletionsAPI(pi, nil)
+	if err != nil {
+		t.Fatalf("NewOpenAIChatCompletionsAPI() error = %v.", err)
+	}
+	if err := api.InitLLM(t.Context()); err != nil {
+		t.Fatalf("InitLLM() error = %v.", err)
+	}
+	return api
+}
+
+// userInputs returns a single user message holding text.
+func userInputs(text string) []spec.InputUnion {
+	return []spec.InputUnion{{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role: spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			}},
+		},
+	}}
+}
+
+// capturedRequest is what a fixture server saw of one request.
+type capturedRequest struct {
+	path   string
+	body   string
+	header http.Header
+}
+
+// fixtureHandler replies with stream to streaming requests and response otherwise, sending each request to got.
+func fixtureHandler(response, stream string, got chan<- capturedRequest) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got <- capturedRequest{path: r.URL.Path, body: string(b), header: r.Header.Clone()}
+		if strings.Contains(string(b), `"stream":true`) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(stream))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}
+}
+
+// streamedText concatenates the text of the streamed events of kind.
+func streamedText(events []spec.StreamEvent, kind spec.StreamContentKind) string {
+	var b strings.Builder
+	for _, e := range events {
+		switch {
+		case e.Kind != kind:
+		case kind == spec.StreamContentKindText:
+			b.WriteString(e.Text.Text)
+		case kind == spec.StreamContentKindThinking:
+			b.WriteString(e.Thinking.Text)
+		}
+	}
+	return b.String()
+}
+
+// messageText concatenates the text of the output messages in outputs.
+func messageText(outputs []spec.OutputUnion) string {
+	var b strings.Builder
+	for _, o := range outputs {
+		if o.OutputMessage == nil {
+			continue
+		}
+		for _, c := range o.OutputMessage.Contents {
+			if c.TextItem != nil {
+				b.WriteString(c.TextItem.Text)
+			}
+		}
+	}
+	return b.String()
+}
+
+// chatChunk returns one chat.completion.chunk SSE event with the given choices and extra top-level fields.
+func chatChunk(choices, extra string) string {
+	return `data: {"id":"c1","object":"chat.completion.chunk","created":0,"model":"m",` + extra +
+		`"choices":[` + choices + `]}` + "\n\n"
+}
+
+func TestOpenAIChatCompletionsFetchCompletion(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hi = `{"token":"Hi","logprob":-0.1,"bytes":[72,105],"top_logprobs":[` +
+			`{"token":"Hi","logprob":-0.1,"bytes":[72,105]},` +
+			`{"token":"Hello","logprob":-2.5,"bytes":null}]}`
+		there          = `{"token":" there","logprob":-0.3,"bytes":[32,116,104,101,114,101],"top_logprobs":[]}`
+		openRouterCost = `"usage":{"prompt_tokens":4,"completion_tokens":2,"total_tokens":6,"cost":0.0015,` +
+			`"is_byok":false}`
+		legacyUsage = `"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}`
+		grounding   = `"citations":` + testCitations + `,"search_results":` + testSearchResults + `,`
+	)
+	legacyChunk := func(text, finish string) string {
+		return `data: {"id":"cmpl1","object":"text_completion","created":0,"model":"base",` +
+			`"choices":[{"index":0,"text":"` + text + `","finish_reason":` + finish + `,"logprobs":null}]}` + "\n\n"
+	}
+	allowFallbacks := false
+
+	tests := []struct {
+		name       string
+		pi         spec.ProviderParam
+		param      spec.ModelParam
+		response   string
+		stream     string
+		wantPath   string
+		wantBody   []string
+		wantHeader map[string]string
+		// check inspects the response; streamed is nil for non-streaming requests.
+		check func(t *testing.T, resp *spec.FetchCompletionResponse, streamed []spec.StreamEvent)
+	}{
+		{
+			name:  "DeepSeekReasoningContent.",
+			param: spec.ModelParam{Name: "deepseek-reasoner"},
+			response: `{"id":"c1","object":"chat.completion","created":0,"model":"m","choices":[{"index":0,` +
+				`"message":{"role":"assistant","content":"4","reasoning_content":"2+2 is 4."},` +
+				`"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":6,"total_tokens":11}}`,
+			stream: chatChunk(`{"index":0,"delta":{"role":"assistant","content":null,"reasoning_content":"2+2 "},`+
+				`"finish_reason":null}`, "") +
+				chatChunk(`{"index":0,"delta":{"content":null,"reasoning_content":"is 4."},"finish_reason":null}`, "") +
+				chatChunk(`{"index":0,"delta":{"content":"4","reasoning_content":null},"finish_reason":null}`, "") +
+				chatChunk(`{"index":0,"delta":{},"finish_reason":"stop"}`, "") +
+				"data: [DONE]\n\n",
+			check: func(t *testing.T, resp *spec.FetchCompletionResponse, streamed []spec.StreamEvent) {
+				t.Helper()
+				if streamed != nil {
+					thinking := streamedText(streamed, spec.StreamContentKindThinking)
+					text := streamedText(streamed, spec.StreamContentKindText)
+					if thinking != "2+2 is 4." || text != "4" || streamed[0].Kind != spec.StreamContentKindThinking {
+						t.Fatalf("streamed thinking = %q, text = %q, want thinking before the answer.", thinking, text)
+					}
+				}
+				if len(resp.Outputs) != 2 || resp.Outputs[0].ReasoningMessage == nil ||
+					resp.Outputs[1].OutputMessage == nil {
+					t.Fatalf("Outputs = %+v, want reasoning and message.", resp.Outputs)
+				}
+				if got := resp.Outputs[0].ReasoningMessage.Thinking; len(got) != 1 || got[0] != "2+2 is 4." {
+					t.Fatalf("Thinking = %q, want the reasoning_content.", got)
+				}
+				if got := messageText(resp.Outputs); got != "4" {
+					t.Fatalf("text = %q, want = %q.", got, "4")
+				}
+			},
+		},
+		{
+			name:     "Logprobs.",
+			param:    spec.ModelParam{Name: "m", TopLogprobs: 2},
+			wantBody: []string{`"logprobs":true`, `"top_logprobs":2`},
+			response: `{"id":"c1","object":"chat.completion","created":0,"model":"m",` +
+				`"choices":[{"index":0,"message":{"role":"assistant","content":"Hi there"},` +
+				`"logprobs":{"content":[` + hi + `,` + there + `],"refusal":null},"finish_reason":"stop"}]}`,
+			stream: chatChunk(`{"index":0,"delta":{"content":"Hi"},"logprobs":{"content":[`+hi+`],"refusal":null},`+
+				`"finish_reason":null}`, "") +
+				chatChunk(`{"index":0,"delta":{"content":" there"},"logprobs":{"content":[`+there+`],`+
+					`"refusal":null},"finish_reason":null}`, "") +
+				chatChunk(`{"index":0,"delta":{},"finish_reason":"stop"}`, "") +
+				"data: [DONE]\n\n",
+			check: func(t *testing.T, resp *spec.FetchCompletionResponse, streamed []spec.StreamEvent) {
+				t.Helper()
+				if streamed != nil {
+					var tokens []spec.TokenLogprob
+					for _, e := range streamed {
+						if e.Kind == spec.StreamContentKindLogprobs {
+							tokens = append(tokens, e.Logprobs.Tokens...)
+						}
+					}
+					if len(tokens) != 2 || tokens[1].Token != " there" {
+						t.Fatalf("streamed logprobs = %+v, want one per token.", tokens)
+					}
+				}
+				if len(resp.Outputs) != 1 || resp.Outputs[0].OutputMessage == nil {
+					t.Fatalf("Outputs = %+v, want one message.", resp.Outputs)
+				}
+				got := resp.Outputs[0].OutputMessage.Contents[0].TextItem.Logprobs
+				if len(got) != 2 || got[0].Token != "Hi" || got[0].Logprob != -0.1 || len(got[0].Bytes) != 2 {
+					t.Fatalf("Logprobs = %+v, want both tokens.", got)
+				}
+				if top := got[0].TopLogprobs; len(top) != 2 || top[1].Token != "Hello" || top[1].Logprob != -2.5 {
+					t.Fatalf("TopLogprobs = %+v, want both alternatives.", top)
+				}
+			},
+		},
+		{
+			name:     "NumChoices.",
+			param:    spec.ModelParam{Name: "m", NumChoices: 2},
+			wantBody: []string{`"n":2`},
+			response: `{"id":"c1","object":"chat.completion","created":0,"model":"m","choices":[` +
+				`{"index":0,"message":{"role":"assistant","content":"Red"},"finish_reason":"stop"},` +
+				`{"index":1,"message":{"role":"assistant","content":"Blue"},"finish_reason":"stop"}]}`,
+			stream: chatChunk(`{"index":0,"delta":{"role":"assistant","content":"Red"},"finish_reason":null}`, "") +
+				chatChunk(`{"index":1,"delta":{"role":"assistant","content":"Blue"},"finish_reason":null}`, "") +
+				chatChunk(`{"index":0,"delta":{},"finish_reason":"stop"}`, "") +
+				chatChunk(`{"index":1,"delta":{},"finish_reason":"stop"}`, "") +
+				"data: [DONE]\n\n",
+			check: func(t *testing.T, resp *spec.FetchCompletionResponse, streamed []spec.StreamEvent) {
+				t.Helper()
+				if got := streamedText(streamed, spec.StreamContentKindText); streamed != nil && got != "Red" {
+					t.Fatalf("streamed text = %q, want only the first choice.", got)
+				}
+				if got := messageText(resp.Outputs); got != "Red" {
+					t.Fatalf("Outputs text = %q, want the first choice.", got)
+				}
+				if len(resp.Choices) != 2 {
+					t.Fatalf("Choices = %+v, want two.", resp.Choices)
+				}
+				for i, want := range []string{"Red", "Blue"} {
+					if c := resp.Choices[i]; c.Index != i || messageText(c.Outputs) != want {
+						t.Fatalf("Choices[%d] = index %d, text %q, want %q.", i, c.Index, messageText(c.Outputs), want)
+					}
+				}
+			},
+		},
+		{
+			name: "OpenRouter.",
+			pi: spec.ProviderParam{
+				SDKType:                  spec.ProviderSDKTypeOpenRouter,
+				ChatCompletionPathPrefix: spec.DefaultOpenRouterChatPrefix,
+				OpenRouter: &spec.OpenRouterOptions{
+					Provider: &spec.OpenRouterProviderPreferences{
+						Order: []string{"anthropic"}, AllowFallbacks: &allowFallbacks, Sort: "price",
+					},
+					FallbackModels: []spec.ModelName{"openai/gpt-4o"},
+					Transforms:     []string{"middle-out"},
+					AppURL:         "https://example.com",
+					AppTitle:       "Example",
+				},
+			},
+			param:    spec.ModelParam{Name: "anthropic/claude-sonnet-4"},
+			wantPath: "/api/v1/chat/completions",
+			wantBody: []string{
+				`"provider":{"allow_fallbacks":false,"order":["anthropic"],"sort":"price"}`,
+				`"models":["anthropic/claude-sonnet-4","openai/gpt-4o"]`,
+				`"transforms":["middle-out"]`,
+				`"usage":{"include":true}`,
+			},
+			wantHeader: map[string]string{"HTTP-Referer": "https://example.com", "X-Title": "Example"},
+			response: `{"id":"c1","object":"chat.completion","created":0,"model":"m",` +
+				`"choices":[{"index":0,"message":{"role":"assistant","content":"Hi there"},"finish_reason":"stop"}],` +
+				openRouterCost + `}`,
+			stream: chatChunk(`{"index":0,"delta":{"role":"assistant","content":"Hi there"},"finish_reason":null}`,
+				"") +
+				chatChunk(`{"index":0,"delta":{},"finish_reason":"stop"}`, "") +
+				chatChunk("", openRouterCost+",") +
+				"data: [DONE]\n\n",
+			check: func(t *testing.T, resp *spec.FetchCompletionResponse, _ []spec.StreamEvent) {
+				t.Helper()
+				if len(resp.Outputs) != 1 || resp.Outputs[0].OutputMessage == nil {
+					t.Fatalf("Outputs = %+v, want one message.", resp.Outputs)
+				}
+				if resp.Usage == nil || resp.Usage.InputTokensTotal != 4 || resp.Usage.Cost == nil ||
+					*resp.Usage.Cost != 0.0015 {
+					t.Fatalf("Usage = %+v, want 4 input tokens and a cost of 0.0015.", resp.Usage)
+				}
+			},
+		},
+		{
+			name: "PerplexitySearchGrounding.",
+			pi: spec.ProviderParam{
+				SDKType:                  spec.ProviderSDKTypePerplexity,
+				ChatCompletionPathPrefix: spec.DefaultPerplexityChatPrefix,
+			},
+			param: spec.ModelParam{Name: "sonar"},
+			response: `{"id":"c1","object":"chat.completion","created":0,"model":"m",` + grounding +
+				`"choices":[{"index":0,"message":{"role":"assistant","content":"Go is great [1][2]."},` +
+				`"finish_reason":"stop"}]}`,
+			stream: chatChunk(`{"index":0,"delta":{"role":"assistant","content":"Go is great [1][2]."},`+
+				`"finish_reason":null}`, grounding) +
+				chatChunk(`{"index":0,"delta":{},"finish_reason":"stop"}`, grounding) +
+				"data: [DONE]\n\n",
+			check: func(t *testing.T, resp *spec.FetchCompletionResponse, _ []spec.StreamEvent) {
+				t.Helper()
+				if len(resp.Outputs) != 2 || resp.Outputs[0].WebSearchToolOutput == nil ||
+					resp.Outputs[1].OutputMessage == nil ||
+					len(resp.Outputs[1].OutputMessage.Contents[0].TextItem.Citations) != 2 {
+					t.Fatalf("Outputs = %+v, want a web search output followed by a cited message.", resp.Outputs)
+				}
+			},
+		},
+		{
+			name: "LegacyCompletions.",
+			pi: spec.ProviderParam{
+				SDKType:                  spec.ProviderSDKTypeOpenAICompletions,
+				ChatCompletionPathPrefix: spec.DefaultOpenAICompletionsPrefix,
+			},
+			param: spec.ModelParam{
+				Name: "base", MaxOutputLength: 2, Echo: true, SystemPrompt: "A story.", StopSequences: []string{"\n"},
+			},
+			wantPath: "/v1/completions",
+			wantBody: []string{`"prompt":"A story.\n\nTitle:"`, `"echo":true`, `"max_tokens":2`, `"stop":["\n"]`},
+			response: `{"id":"cmpl1","object":"text_completion","created":0,"model":"base",` +
+				`"choices":[{"index":0,"text":"Once upon","finish_reason":"length","logprobs":null}],` +
+				legacyUsage + `}`,
+			stream: legacyChunk("Once", "null") + legacyChunk(" upon", `"length"`) +
+				`data: {"id":"cmpl1","object":"text_completion","created":0,"model":"base","choices":[],` +
+				legacyUsage + "}\n\n" +
+				"data: [DONE]\n\n",
+			check: func(t *testing.T, resp *spec.FetchCompletionResponse, streamed []spec.StreamEvent) {
+				t.Helper()
+				if got := streamedText(streamed, spec.StreamContentKindText); streamed != nil && got != "Once upon" {
+					t.Fatalf("streamed text = %q, want = %q.", got, "Once upon")
+				}
+				if len(resp.Outputs) != 1 || resp.Outputs[0].OutputMessage == nil {
+					t.Fatalf("Outputs = %+v, want one message.", resp.Outputs)
+				}
+				msg := resp.Outputs[0].OutputMessage
+				if msg.Contents[0].TextItem.Text != "Once upon" || msg.Status != spec.StatusIncomplete {
+					t.Fatalf("OutputMessage = %+v, want the truncated completion.", msg)
+				}
+				if resp.Usage == nil || resp.Usage.InputTokensTotal != 3 || resp.Usage.OutputTokens != 2 {
+					t.Fatalf("Usage = %+v, want 3 input and 2 output tokens.", resp.Usage)
+				}
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			pi := tc.pi
+			pi.Name = "p"
+			pi.APIKey = "k"
+			if pi.SDKType == "" {
+				pi.SDKType = spec.ProviderSDKTypeOpenAIChatCompletions
+				pi.ChatCompletionPathPrefix = "/chat/completions"
+			}
+			requests := make(chan capturedRequest, 1)
+			api := newTestAPI(t, pi, fixtureHandler(tc.response, tc.stream, requests))
+
+			for _, stream := range []bool{false, true} {
+				param := tc.param
+				param.Stream = stream
+				var (
+					opts     *spec.FetchCompletionOptions
+					streamed []spec.StreamEvent
+				)
+				if stream {
+					streamed = []spec.StreamEvent{}
+					opts = &spec.FetchCompletionOptions{StreamHandler: func(e spec.StreamEvent) error {
+						streamed = append(streamed, e)
+						return nil
+					}}
+				}
+				resp, err := api.FetchCompletion(t.Context(), &spec.FetchCompletionRequest{
+					ModelParam: param,
+					Inputs:     userInputs("Title:"),
+				}, opts)
+				if err != nil {
+					t.Fatalf("FetchCompletion(stream=%t) error = %v.", stream, err)
+				}
+				got := <-requests
+				if tc.wantPath != "" && got.path != tc.wantPath {
+					t.Fatalf("path = %s, want %s.", got.path, tc.wantPath)
+				}
+				for _, want := range tc.wantBody {
+					if !strings.Contains(got.body, want) {
+						t.Fatalf("request body = %s, want it to contain %s.", got.body, want)
+					}
+				}
+				for k, v := range tc.wantHeader {
+					if got.header.Get(k) != v {
+						t.Fatalf("header %s = %q, want %q.", k, got.header.Get(k), v)
+					}
+				}
+				tc.check(t, resp, streamed)
+			}
+		})
+	}
+}
+
+func TestOpenAIChatCompletionsFetchFIMCompletion(t *testing.T) {
+	t.Parallel()
+
+	const reply = `{"id":"f1","object":"text_completion","created":0,"model":"codestral",` +
+		`"choices":[{"index":0,"text":"return a + b","finish_reason":"stop","logprobs":null}]}`
+
+	tests := []struct {
+		name     string
+		template *spec.FIMPromptTemplate
+		want     []string
+	}{
+		{name: "SuffixField.", want: []string{`"prompt":"def add(a, b):\n    "`, `"suffix":"\n\nprint(add(1, 2))"`}},
+		{
+			name:     "PromptTemplate.",
+			template: &spec.FIMPromptTemplate{PrefixToken: "<P>", SuffixToken: "<S>", MiddleToken: "<M>"},
+			want:     []string{`"prompt":"<P>def add(a, b):\n    <S>\n\nprint(add(1, 2))<M>"`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			requests := make(chan capturedRequest, 1)
+			api := newTestAPI(t, spec.ProviderParam{
+				Name:                     "codestral",
+				SDKType:                  spec.ProviderSDKTypeOpenAICompletions,
+				ChatCompletionPathPrefix: "/v1/fim/completions",
+				APIKey:                   "k",
+			}, fixtureHandler(reply, "", requests))
+
+			resp, err := api.FetchFIMCompletion(t.Context(), &spec.FIMCompletionRequest{
+				ModelParam:     spec.ModelParam{Name: "codestral", MaxOutputLength: 16},
+				Prefix:         "def add(a, b):\n    ",
+				Suffix:         "\n\nprint(add(1, 2))",
+				PromptTemplate: tc.template,
+			}, nil)
+			if err != nil {
+				t.Fatalf("FetchFIMCompletion() error = %v.", err)
+			}
+			if got := messageText(resp.Outputs); len(resp.Outputs) != 1 || got != "return a + b" {
+				t.Fatalf("Outputs = %+v, want the middle text.", resp.Outputs)
+			}
+			got := <-requests
+			if got.path != "/v1/fim/completions" {
+				t.Fatalf("path = %s, want /v1/fim/completions.", got.path)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(got.body, want) {
+					t.Fatalf("request body = %s, want it to contain %s.", got.body, want)
+				}
+			}
+			if tc.template != nil && strings.Contains(got.body, `"suffix"`) {
+				t.Fatalf("request body = %s, want no suffix field with a prompt template.", got.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package openaichatsdk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// applyOpenAIChatConstraint maps a decoding constraint onto the request body extensions of its backend.
+func applyOpenAIChatConstraint(params *openai.ChatCompletionNewParams, c *spec.DecodingConstraint) error {
+	if params == nil || c == nil {
+		return nil
+	}
+	set := 0
+	for _, ok := range []bool{c.Grammar != "", c.JSONSchema != nil, c.Regex != "", len(c.Choices) > 0} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("decoding constraint: exactly one of grammar, jsonSchema, regex and choices is required")
+	}
+
+	extra := map[string]any{}
+	switch c.Backend {
+	case spec.DecodingConstraintBackendLlamaCpp:
+		switch {
+		case c.Grammar != "":
+			extra["grammar"] = c.Grammar
+		case c.JSONSchema != nil:
+			extra["json_schema"] = c.JSONSchema
+		default:
+			return errors.New("decoding constraint: llama.cpp supports grammar and jsonSchema only")
+		}
+	case spec.DecodingConstraintBackendVLLM:
+		switch {
+		case c.Grammar != "":
+			extra["guided_grammar"] = c.Grammar
+		case c.JSONSchema != nil:
+			extra["guided_json"] = c.JSONSchema
+		case c.Regex != "":
+			extra["guided_regex"] = c.Regex
+		default:
+			extra["guided_choice"] = c.Choices
+		}
+	case spec.DecodingConstraintBackendFireworks:
+		if params.ResponseFormat.OfText != nil || params.ResponseFormat.OfJSONObject != nil ||
+			params.ResponseFormat.OfJSONSchema != nil {
+			return errors.New("decoding constraint: fireworks constraints cannot be combined with an output format")
+		}
+		switch {
+		case c.Grammar != "":
+			extra["response_format"] = map[string]any{"type": "grammar", "grammar": c.Grammar}
+		case c.JSONSchema != nil:
+			extra["response_format"] = map[string]any{"type": "json_object", "schema": c.JSONSchema}
+		default:
+			return errors.New("decoding constraint: fireworks supports grammar and jsonSchema only")
+		}
+	default:
+		return fmt.Errorf("decoding constraint: unknown backend %q", c.Backend)
+	}
+
+	mergeExtraFields(params, extra)
+	return nil
+}
+
+// applyOpenAIChatBeamSearch maps beam search onto vLLM's sampling extensions. vLLM uses n as the beam width.
+func applyOpenAIChatBeamSearch(params *openai.ChatCompletionNewParams, b *spec.BeamSearchParam) error {
+	if params == nil || b == nil {
+		return nil
+	}
+	if b.Width < 1 {
+		return errors.New("beam search: width must be at least 1")
+	}
+	params.N = openai.Int(int64(b.Width))
+	extra := map[string]any{"use_beam_search": true}
+	if b.LengthPenalty != nil {
+		extra["length_penalty"] = *b.LengthPenalty
+	}
+	mergeExtraFields(params, extra)
+	return nil
+}
+
+// mergeExtraFields adds extra to the request body extensions already set on params; keys in extra win.
+func mergeExtraFields(params *openai.ChatCompletionNewParams, extra map[string]any) {
+	for k, v := range params.ExtraFields() {
+		if _, ok := extra[k]; !ok {
+			extra[k] = v
+		}
+	}
+	params.SetExtraFields(extra)
+}
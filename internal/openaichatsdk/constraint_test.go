@@ -0,0 +1,224 @@
+package openaichatsdk
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestApplyOpenAIChatConstraint(t *testing.T) {
+	t.Parallel()
+
+	schema := map[string]any{"type": "object"}
+	tests := []struct {
+		name       string
+		constraint *spec.DecodingConstraint
+		want       []string
+		wantErr    bool
+	}{
+		{name: "NoConstraint."},
+		{
+			name: "LlamaCppGrammar.",
+			constraint: &spec.DecodingConstraint{
+				Backend: spec.DecodingConstraintBackendLlamaCpp, Grammar: `root ::= "yes" | "no"`,
+			},
+			want: []string{`"grammar":"root ::= \"yes\" | \"no\""`},
+		},
+		{
+			name:       "LlamaCppJSONSchema.",
+			constraint: &spec.DecodingConstraint{Backend: spec.DecodingConstraintBackendLlamaCpp, JSONSchema: schema},
+			want:       []string{`"json_schema":{"type":"object"}`},
+		},
+		{
+			name:       "LlamaCppRegexUnsupported.",
+			constraint: &spec.DecodingConstraint{Backend: spec.DecodingConstraintBackendLlamaCpp, Regex: `\d+`},
+			wantErr:    true,
+		},
+		{
+			name:       "VLLMJSONSchema.",
+			constraint: &spec.DecodingConstraint{Backend: spec.DecodingConstraintBackendVLLM, JSONSchema: schema},
+			want:       []string{`"guided_json":{"type":"object"}`},
+		},
+		{
+			name:       "VLLMRegex.",
+			constraint: &spec.DecodingConstraint{Backend: spec.DecodingConstraintBackendVLLM, Regex: `\d+`},
+			want:       []string{`"guided_regex":"\\d+"`},
+		},
+		{
+			name: "VLLMChoices.",
+			constraint: &spec.DecodingConstraint{
+				Backend: spec.DecodingConstraintBackendVLLM, Choices: []string{"yes", "no"},
+			},
+			want: []string{`"guided_choice":["yes","no"]`},
+		},
+		{
+			name: "FireworksGrammar.",
+			constraint: &spec.DecodingConstraint{
+				Backend: spec.DecodingConstraintBackendFireworks, Grammar: `root ::= "yes" | "no"`,
+			},
+			want: []string{`"response_format":{"grammar":"root ::= \"yes\" | \"no\"","type":"grammar"}`},
+		},
+		{
+			name:       "FireworksJSONSchema.",
+			constraint: &spec.DecodingConstraint{Backend: spec.DecodingConstraintBackendFireworks, JSONSchema: schema},
+			want:       []string{`"response_format":{"schema":{"type":"object"},"type":"json_object"}`},
+		},
+		{
+			name: "FireworksChoicesUnsupported.",
+			constraint: &spec.DecodingConstraint{
+				Backend: spec.DecodingConstraintBackendFireworks, Choices: []string{"yes", "no"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "MultipleConstraints.",
+			constraint: &spec.DecodingConstraint{
+				Backend: spec.DecodingConstraintBackendVLLM, Regex: `\d+`, Choices: []string{"1"},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "UnknownBackend.",
+			constraint: &spec.DecodingConstraint{Backend: "tgi", Regex: `\d+`},
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := openai.ChatCompletionNewParams{Model: "m"}
+			err := applyOpenAIChatConstraint(&params, tc.constraint)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("applyOpenAIChatConstraint() error = %v, wantErr = %v.", err, tc.wantErr)
+			}
+			b, err := json.Marshal(params)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v.", err)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(string(b), w) {
+					t.Fatalf("params %s do not contain %s.", b, w)
+				}
+			}
+		})
+	}
+}
+
+func TestFireworksConstraintBackend(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		sdkType spec.ProviderSDKType
+		backend spec.DecodingConstraintBackend
+		want    string
+	}{
+		{
+			name:    "FireworksDefault.",
+			sdkType: spec.ProviderSDKTypeFireworks,
+			want:    `"response_format":{"grammar":"root ::= \"yes\" | \"no\"","type":"grammar"}`,
+		},
+		{
+			name:    "ExplicitBackend.",
+			sdkType: spec.ProviderSDKTypeFireworks,
+			backend: spec.DecodingConstraintBackendVLLM,
+			want:    `"guided_grammar":"root ::= \"yes\" | \"no\""`,
+		},
+		{
+			name:    "OtherProviderNeedsBackend.",
+			sdkType: spec.ProviderSDKTypeOpenAICompatible,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			requests := make(chan capturedRequest, 1)
+			api := newTestAPI(t, spec.ProviderParam{
+				Name:                     "p",
+				SDKType:                  tc.sdkType,
+				ChatCompletionPathPrefix: spec.DefaultFireworksChatPrefix,
+				APIKey:                   "k",
+			}, fixtureHandler(`{"id":"fw1","object":"chat.completion","created":0,"model":"m",`+
+				`"choices":[{"index":0,"message":{"role":"assistant","content":"yes"},"finish_reason":"stop"}]}`,
+				"", requests))
+			_, err := api.FetchCompletion(t.Context(), &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{
+					Name:       "m",
+					Constraint: &spec.DecodingConstraint{Backend: tc.backend, Grammar: `root ::= "yes" | "no"`},
+				},
+				Inputs: userInputs("yes or no?"),
+			}, nil)
+			if tc.want == "" {
+				if err == nil {
+					t.Fatalf("FetchCompletion() error = nil, want an unknown backend error.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			if body := (<-requests).body; !strings.Contains(body, tc.want) {
+				t.Fatalf("request body = %s, want it to contain %s.", body, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyOpenAIChatBeamSearch(t *testing.T) {
+	t.Parallel()
+
+	penalty := 0.8
+	tests := []struct {
+		name       string
+		beamSearch *spec.BeamSearchParam
+		want       []string
+		wantErr    bool
+	}{
+		{name: "NoBeamSearch."},
+		{
+			name:       "Width.",
+			beamSearch: &spec.BeamSearchParam{Width: 4},
+			want:       []string{`"n":4`, `"use_beam_search":true`},
+		},
+		{
+			name:       "LengthPenalty.",
+			beamSearch: &spec.BeamSearchParam{Width: 2, LengthPenalty: &penalty},
+			want:       []string{`"n":2`, `"length_penalty":0.8`},
+		},
+		{name: "ZeroWidth.", beamSearch: &spec.BeamSearchParam{}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := openai.ChatCompletionNewParams{Model: "m"}
+			// Beam search combines with guided decoding.
+			if err := applyOpenAIChatConstraint(&params, &spec.DecodingConstraint{
+				Backend: spec.DecodingConstraintBackendVLLM, Choices: []string{"yes", "no"},
+			}); err != nil {
+				t.Fatalf("applyOpenAIChatConstraint() error = %v.", err)
+			}
+			err := applyOpenAIChatBeamSearch(&params, tc.beamSearch)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("applyOpenAIChatBeamSearch() error = %v, wantErr = %v.", err, tc.wantErr)
+			}
+			b, err := json.Marshal(params)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v.", err)
+			}
+			for _, w := range append(tc.want, `"guided_choice":["yes","no"]`) {
+				if !strings.Contains(string(b), w) {
+					t.Fatalf("params %s do not contain %s.", b, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package openaichatsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/packages/ssestream"
+	openaiSharedConstant "github.com/openai/openai-go/v3/shared/constant"
+	"github.com/tidwall/gjson"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const disabledSSEPath = "-"
+
+// resolvedSSEStreamSchema is an SSEStreamSchema with defaults applied.
+type resolvedSSEStreamSchema spec.SSEStreamSchema
+
+func resolveSSEStreamSchema(s *spec.SSEStreamSchema) resolvedSSEStreamSchema {
+	out := resolvedSSEStreamSchema{}
+	if s != nil {
+		out = resolvedSSEStreamSchema(*s)
+	}
+	def := func(v *string, d string) {
+		if strings.TrimSpace(*v) == "" {
+			*v = d
+		}
+	}
+	def(&out.TextDeltaPath, "choices.0.delta.content")
+	def(&out.ReasoningDeltaPath, "choices.0.delta.reasoning_content")
+	def(&out.ToolCallsPath, "choices.0.delta.tool_calls")
+	def(&out.ToolCallIndexPath, "index")
+	def(&out.ToolCallIDPath, "id")
+	def(&out.ToolCallNamePath, "function.name")
+	def(&out.ToolCallArgumentsPath, "function.arguments")
+	def(&out.FinishReasonPath, "choices.0.finish_reason")
+	def(&out.UsageInputTokensPath, "usage.prompt_tokens")
+	def(&out.UsageOutputTokensPath, "usage.completion_tokens")
+	def(&out.UsageCachedTokensPath, "usage.prompt_tokens_details.cached_tokens")
+	def(&out.UsageReasoningTokensPath, "usage.completion_tokens_details.reasoning_tokens")
+	def(&out.ErrorMessagePath, "error.message")
+	def(&out.DoneSentinel, "[DONE]")
+	return out
+}
+
+func getSSEPath(data []byte, path string) gjson.Result {
+	if path == disabledSSEPath {
+		return gjson.Result{}
+	}
+	return gjson.GetBytes(data, path)
+}
+
+// doCustomSSEStreaming streams from an OpenAI-compatible backend whose chunk schema is described by an
+// SSEStreamSchema. The raw SSE payloads are decoded with JSON paths instead of the SDK chunk types, and a synthetic
+// ChatCompletion is assembled so that outputs and usage are mapped exactly like the regular Chat Completions path.
+func (api *OpenAIChatCompletionsAPI) doCustomSSEStreaming(
+	ctx context.Context,
+	client *openai.Client,
+	providerName spec.ProviderName,
+	modelName spec.ModelName,
+	schema *spec.SSEStreamSchema,
+	includeUsage bool,
+	params openai.ChatCompletionNewParams,
+	opts *spec.FetchCompletionOptions,
+	timeout time.Duration,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) (*spec.FetchCompletionResponse, *openai.ChatCompletion, error) {
+	resp := &spec.FetchCompletionResponse{}
+	rs := resolveSSEStreamSchema(schema)
+
+	pipeline := sdkutil.NewStreamPipeline(
+		ctx,
+		opts.StreamHandler,
+		providerName,
+		modelName,
+		sdkutil.ResolveStreamConfig(opts),
+	)
+	emitToolCall := func(chunk *spec.StreamToolCallChunk) error {
+		return pipeline.Emit(spec.StreamEvent{Kind: spec.StreamContentKindToolCall, ToolCall: chunk})
+	}
+	toolCalls := newChatToolCallStreamTracker(toolChoiceNameMap)
+
+	if includeUsage {
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	}
+	var httpResp *http.Response
+	err := client.Post(
+		ctx,
+		"chat/completions",
+		params,
+		&httpResp,
+		option.WithRequestTimeout(timeout),
+		option.WithJSONSet("stream", true),
+	)
+	if err != nil {
+		_ = pipeline.Close()
+		resp.Error = providerError(providerName, err)
+		return resp, nil, err
+	}
+	decoder := ssestream.NewDecoder(httpResp)
+	if decoder == nil {
+		_ = pipeline.Close()
+		err := errors.New("openai compatible sse: empty stream response")
+		resp.Error = providerError(providerName, err)
+		return resp, nil, err
+	}
+	defer func() { _ = decoder.Close() }()
+
+	var (
+		completion     openai.ChatCompletion
+		text           strings.Builder
+		reasoning      strings.Builder
+		finishReason   string
+		streamWriteErr error
+		apiErr         error
+	)
+	for decoder.Next() {
+		data := decoder.Event().Data
+		if strings.TrimSpace(string(data)) == rs.DoneSentinel {
+			break
+		}
+		if len(data) == 0 || !gjson.ValidBytes(data) {
+			continue
+		}
+		if msg := getSSEPath(data, rs.ErrorMessagePath); msg.Exists() && msg.String() != "" {
+			apiErr = fmt.Errorf("openai compatible sse: %s", msg.String())
+			break
+		}
+		if id := gjson.GetBytes(data, "id"); id.Exists() && completion.ID == "" {
+			completion.ID = id.String()
+		}
+
+		if r := getSSEPath(data, rs.ReasoningDeltaPath); r.Exists() && r.String() != "" {
+			reasoning.WriteString(r.String())
+			if streamWriteErr = pipeline.WriteThinking(sdkutil.StreamPosition{}, r.String()); streamWriteErr != nil {
+				break
+			}
+		}
+		if t := getSSEPath(data, rs.TextDeltaPath); t.Exists() && t.String() != "" {
+			text.WriteString(t.String())
+			if streamWriteErr = pipeline.WriteText(sdkutil.StreamPosition{}, t.String()); streamWriteErr != nil {
+				break
+			}
+		}
+		if tcs := getSSEPath(data, rs.ToolCallsPath); tcs.IsArray() {
+			var deltas []openai.ChatCompletionChunkChoiceDeltaToolCall
+			for i, tc := range tcs.Array() {
+				d := openai.ChatCompletionChunkChoiceDeltaToolCall{Index: int64(i)}
+				if idx := getSSEPath([]byte(tc.Raw), rs.ToolCallIndexPath); idx.Exists() {
+					d.Index = idx.Int()
+				}
+				d.ID = getSSEPath([]byte(tc.Raw), rs.ToolCallIDPath).String()
+				d.Function.Name = getSSEPath([]byte(tc.Raw), rs.ToolCallNamePath).String()
+				d.Function.Arguments = getSSEPath([]byte(tc.Raw), rs.ToolCallArgumentsPath).String()
+				deltas = append(deltas, d)
+			}
+			if streamWriteErr = toolCalls.addDeltas(deltas, emitToolCall); streamWriteErr != nil {
+				break
+			}
+		}
+		if fr := getSSEPath(data, rs.FinishReasonPath); fr.Exists() && fr.String() != "" {
+			finishReason = fr.String()
+		}
+		if u := getSSEPath(data, rs.UsageInputTokensPath); u.Exists() {
+			completion.Usage.PromptTokens = u.Int()
+		}
+		if u := getSSEPath(data, rs.UsageOutputTokensPath); u.Exists() {
+			completion.Usage.CompletionTokens = u.Int()
+		}
+		if u := getSSEPath(data, rs.UsageCachedTokensPath); u.Exists() {
+			completion.Usage.PromptTokensDetails.CachedTokens = u.Int()
+		}
+		if u := getSSEPath(data, rs.UsageReasoningTokensPath); u.Exists() {
+			completion.Usage.CompletionTokensDetails.ReasoningTokens = u.Int()
+		}
+	}
+	if streamWriteErr == nil && apiErr == nil && decoder.Err() == nil {
+		streamWriteErr = toolCalls.finishAll(emitToolCall)
+	}
+	if err := pipeline.Close(); err != nil && streamWriteErr == nil {
+		streamWriteErr = err
+	}
+
+	if finishReason == "" {
+		finishReason = "stop"
+	}
+	completion.Choices = []openai.ChatCompletionChoice{{
+		FinishReason: finishReason,
+		Message: openai.ChatCompletionMessage{
+			Content:   text.String(),
+			ToolCalls: toolCalls.completedToolCalls(),
+		},
+	}}
+
+	streamErr := errors.Join(decoder.Err(), apiErr, streamWriteErr)
+	resp.Usage = usageFromOpenAIChatCompletion(&completion)
+	pipeline.EmitUsage(resp.Usage)
+	if streamErr != nil {
+		resp.Error = providerError(providerName, streamErr)
+		pipeline.EmitError(resp.Error)
+	}
+
+	resp.Outputs = reasoningOutputs(completion.ID, reasoning.String(), mapOpenAIChatFinishReasonToStatus(finishReason))
+	resp.Outputs = append(resp.Outputs, outputsFromOpenAIChatCompletion(&completion, toolChoiceNameMap)...)
+	return resp, &completion, streamErr
+}
+
+// completedToolCalls returns the tracked tool calls in first-seen order.
+func (t *chatToolCallStreamTracker) completedToolCalls() []openai.ChatCompletionMessageToolCallUnion {
+	out := make([]openai.ChatCompletionMessageToolCallUnion, 0, len(t.order))
+	for _, idx := range t.order {
+		c := t.calls[idx]
+		tc := openai.ChatCompletionMessageToolCallUnion{
+			ID:   c.id,
+			Type: string(openaiSharedConstant.Function("").Default()),
+		}
+		tc.Function.Name = c.name
+		tc.Function.Arguments = c.args.String()
+		out = append(out, tc)
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package openaichatsdk
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestCustomSSEStreaming(t *testing.T) {
+	t.Parallel()
+
+	chunks := []string{
+		`{"id":"r1","out":{"think":"pondering"}}`,
+		`{"out":{"text":"Hello, "}}`,
+		`{"out":{"text":"world"}}`,
+		`{"out":{"calls":[{"n":0,"callId":"c1","fn":"lookup","args":"{\"q\":"}]}}`,
+		`{"out":{"calls":[{"n":0,"args":"1}"}]},"stop":"tool_calls"}`,
+		`{"meta":{"in":7,"out":3}}`,
+		`END`,
+	}
+	api := newTestAPI(t, spec.ProviderParam{
+		Name:    "custom",
+		SDKType: spec.ProviderSDKTypeOpenAICompatibleSSE,
+		APIKey:  "k",
+		SSEStreamSchema: &spec.SSEStreamSchema{
+			TextDeltaPath:         "out.text",
+			ReasoningDeltaPath:    "out.think",
+			ToolCallsPath:         "out.calls",
+			ToolCallIndexPath:     "n",
+			ToolCallIDPath:        "callId",
+			ToolCallNamePath:      "fn",
+			ToolCallArgumentsPath: "args",
+			FinishReasonPath:      "stop",
+			UsageInputTokensPath:  "meta.in",
+			UsageOutputTokensPath: "meta.out",
+			DoneSentinel:          "END",
+		},
+	}, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, c := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", c)
+		}
+	})
+
+	var kinds []string
+	resp, err := api.FetchCompletion(t.Context(), &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m", Stream: true},
+		Inputs:     userInputs("hi"),
+		ToolChoices: []spec.ToolChoice{{
+			Type:      spec.ToolTypeFunction,
+			ID:        "t1",
+			Name:      "lookup",
+			Arguments: map[string]any{"type": "object"},
+		}},
+	}, &spec.FetchCompletionOptions{StreamHandler: func(ev spec.StreamEvent) error {
+		kinds = append(kinds, string(ev.Kind))
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+
+	if got, want := strings.Join(kinds, ","), "thinking,text,toolCall,toolCall,toolCall,toolCall,usage"; got != want {
+		t.Fatalf("event kinds = %q, want = %q.", got, want)
+	}
+	if len(resp.Outputs) != 3 {
+		t.Fatalf("len(Outputs) = %d, want = 3.", len(resp.Outputs))
+	}
+	if got := resp.Outputs[1].OutputMessage.Contents[0].TextItem.Text; got != "Hello, world" {
+		t.Fatalf("text = %q, want = %q.", got, "Hello, world")
+	}
+	call := resp.Outputs[2].FunctionToolCall
+	if call == nil || call.Arguments != `{"q":1}` || call.ChoiceID != "t1" {
+		t.Fatalf("tool call = %+v, want lookup({\"q\":1}).", call)
+	}
+	if resp.Usage.InputTokensTotal != 7 || resp.Usage.OutputTokens != 3 {
+		t.Fatalf("Usage = %+v, want 7 in / 3 out.", resp.Usage)
+	}
+}
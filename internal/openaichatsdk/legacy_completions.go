@@ -0,0 +1,351 @@
+package openaichatsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/packages/param"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// fetchLegacyCompletion serves ProviderSDKTypeOpenAICompletions providers through the legacy /v1/completions
+// endpoint.
+func (api *OpenAIChatCompletionsAPI) fetchLegacyCompletion(
+	ctx context.Context,
+	client *openai.Client,
+	pi *spec.ProviderParam,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	prompt := legacyPrompt(req.ModelParam.SystemPrompt, req.Inputs)
+	if prompt == "" {
+		return nil, errors.New("openai completions: empty prompt")
+	}
+	if len(req.ToolChoices) > 0 {
+		logutil.Debug("openai completions: tools are not supported; ignoring tool choices", "name", pi.Name)
+	}
+
+	mp := req.ModelParam
+	params, err := legacyCompletionParams(pi.Azure.Deployment(mp.Name), &mp)
+	if err != nil {
+		return nil, err
+	}
+	params.Prompt = openai.CompletionNewParamsPromptUnion{OfString: openai.String(prompt)}
+	if mp.Echo {
+		params.Echo = openai.Bool(true)
+	}
+
+	return api.runLegacyCompletion(ctx, client, pi, req, mp, params, opts)
+}
+
+// FetchFIMCompletion completes the code between req.Prefix and req.Suffix through the completions endpoint relative
+// to the provider base URL, e.g. /v1/completions, DeepSeek's /beta/completions or Codestral's /v1/fim/completions
+// (set as the ChatCompletionPathPrefix of a ProviderSDKTypeOpenAICompletions provider).
+func (api *OpenAIChatCompletionsAPI) FetchFIMCompletion(
+	ctx context.Context,
+	req *spec.FIMCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (resp *spec.FetchCompletionResponse, err error) {
+	api.mu.RLock()
+	client := api.client
+	var pi spec.ProviderParam
+	if api.ProviderParam != nil {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+	defer sdkutil.RecoverFetchCompletion(pi.Name, &resp, &err)
+
+	if client == nil && (opts == nil || !opts.DryRun) {
+		return nil, errors.New("openai completions: client not initialized")
+	}
+	if req == nil || req.ModelParam.Name == "" || (req.Prefix == "" && req.Suffix == "") {
+		return nil, errors.New("openai completions: empty fim completion data")
+	}
+
+	mp := req.ModelParam
+	params, err := legacyCompletionParams(pi.Azure.Deployment(mp.Name), &mp)
+	if err != nil {
+		return nil, err
+	}
+	if t := req.PromptTemplate; t != nil {
+		params.Prompt = openai.CompletionNewParamsPromptUnion{OfString: openai.String(
+			t.PrefixToken + req.Prefix + t.SuffixToken + req.Suffix + t.MiddleToken,
+		)}
+	} else {
+		params.Prompt = openai.CompletionNewParamsPromptUnion{OfString: openai.String(req.Prefix)}
+		if req.Suffix != "" {
+			params.Suffix = openai.String(req.Suffix)
+		}
+	}
+	return api.runLegacyCompletion(ctx, client, &pi, nil, mp, params, opts)
+}
+
+// legacyCompletionParams maps the model parameters shared by text and fill-in-the-middle completions.
+func legacyCompletionParams(model spec.ModelName, mp *spec.ModelParam) (openai.CompletionNewParams, error) {
+	params := openai.CompletionNewParams{Model: openai.CompletionNewParamsModel(model)}
+	if mp.MaxOutputLength > 0 {
+		params.MaxTokens = openai.Int(int64(mp.MaxOutputLength))
+	}
+	if t := mp.Temperature; t != nil {
+		params.Temperature = openai.Float(*t)
+	}
+	if p := mp.TopP; p != nil {
+		params.TopP = openai.Float(*p)
+	}
+	if p := mp.FrequencyPenalty; p != nil {
+		params.FrequencyPenalty = openai.Float(*p)
+	}
+	if p := mp.PresencePenalty; p != nil {
+		params.PresencePenalty = openai.Float(*p)
+	}
+	if len(mp.StopSequences) > 0 {
+		if len(mp.StopSequences) > 4 {
+			return params, fmt.Errorf(
+				"openai completions: stopSequences supports up to 4 items (got %d)",
+				len(mp.StopSequences),
+			)
+		}
+		params.Stop = openai.CompletionNewParamsStopUnion{OfStringArray: mp.StopSequences}
+	}
+	return params, nil
+}
+
+// runLegacyCompletion sends params to the completions endpoint. req is the originating chat-shaped request, nil for
+// fill-in-the-middle completions.
+func (api *OpenAIChatCompletionsAPI) runLegacyCompletion(
+	ctx context.Context,
+	client *openai.Client,
+	pi *spec.ProviderParam,
+	req *spec.FetchCompletionRequest,
+	mp spec.ModelParam,
+	params openai.CompletionNewParams,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	useStream := mp.Stream && opts != nil && opts.StreamHandler != nil
+	if useStream && (pi.Quirks == nil || !pi.Quirks.NoStreamUsage) {
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	}
+
+	overlay, err := sdkutil.ParseParamOverlay(mp.AdditionalParametersRawJSON, "model", "prompt", "suffix", "stream")
+	if err != nil {
+		return nil, err
+	}
+	if overlay != nil {
+		extra, err := sdkutil.OverlayFields(params, params.ExtraFields(), overlay)
+		if err != nil {
+			return nil, err
+		}
+		params.SetExtraFields(extra)
+	}
+
+	effective := params
+	effective.Prompt, effective.Suffix = openai.CompletionNewParamsPromptUnion{}, param.Opt[string]{}
+	effectiveParams := sdkutil.EffectiveParams(effective, "prompt", "suffix")
+
+	if opts != nil && opts.DryRun {
+		return sdkutil.DryRunResponse(pi, mp.Name, useStream, params)
+	}
+
+	timeout := pi.Timeouts.Timeout(mp.Name, mp.Timeout)
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    mp.Name,
+			Request:  req,
+			Options:  opts,
+		})
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		fullRawResp    *openai.Completion
+		apiErr         error
+	)
+	if useStream {
+		normalizedResp, fullRawResp, apiErr = doLegacyStreaming(ctx, client, pi.Name, mp.Name, params, opts, timeout)
+	} else {
+		normalizedResp, fullRawResp, apiErr = doLegacyNonStreaming(ctx, client, pi.Name, params, timeout)
+	}
+
+	normalizedResp = sdkutil.FinalizeResponse(pi.Name, normalizedResp, apiErr)
+	if normalizedResp != nil {
+		normalizedResp.Metadata = &spec.ResponseMetadata{EffectiveParams: effectiveParams}
+	}
+
+	if span != nil {
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: fullRawResp,
+			Response:         normalizedResp, // may be nil
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+func doLegacyNonStreaming(
+	ctx context.Context,
+	client *openai.Client,
+	providerName spec.ProviderName,
+	params openai.CompletionNewParams,
+	timeout time.Duration,
+) (*spec.FetchCompletionResponse, *openai.Completion, error) {
+	resp := &spec.FetchCompletionResponse{}
+
+	oaiResp, err := client.Completions.New(ctx, params, option.WithRequestTimeout(timeout))
+	if oaiResp != nil {
+		resp.Usage = usageFromOpenAICompletionUsage(oaiResp.Usage)
+	}
+	if err != nil {
+		resp.Error = providerError(providerName, err)
+		return resp, oaiResp, err
+	}
+
+	resp.Outputs = outputsFromOpenAICompletion(oaiResp)
+	return resp, oaiResp, nil
+}
+
+func doLegacyStreaming(
+	ctx context.Context,
+	client *openai.Client,
+	providerName spec.ProviderName,
+	modelName spec.ModelName,
+	params openai.CompletionNewParams,
+	opts *spec.FetchCompletionOptions,
+	timeout time.Duration,
+) (*spec.FetchCompletionResponse, *openai.Completion, error) {
+	resp := &spec.FetchCompletionResponse{}
+	pipeline := sdkutil.NewStreamPipeline(
+		ctx,
+		opts.StreamHandler,
+		providerName,
+		modelName,
+		sdkutil.ResolveStreamConfig(opts),
+	)
+
+	stream := client.Completions.NewStreaming(ctx, params, option.WithRequestTimeout(timeout))
+	defer func() { _ = stream.Close() }()
+
+	// acc is the streamed completion folded into a single choice.
+	var (
+		acc            openai.Completion
+		text           strings.Builder
+		streamWriteErr error
+	)
+	acc.Choices = []openai.CompletionChoice{{}}
+	for stream.Next() {
+		chunk := stream.Current()
+		if acc.ID == "" {
+			acc.ID, acc.Model, acc.Created = chunk.ID, chunk.Model, chunk.Created
+		}
+		if chunk.Usage.TotalTokens > 0 || chunk.Usage.PromptTokens > 0 {
+			acc.Usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			acc.Choices[0].FinishReason = choice.FinishReason
+		}
+		if choice.Text != "" {
+			text.WriteString(choice.Text)
+			if streamWriteErr = pipeline.WriteText(sdkutil.StreamPosition{}, choice.Text); streamWriteErr != nil {
+				break
+			}
+		}
+	}
+	if err := pipeline.Close(); err != nil && streamWriteErr == nil {
+		streamWriteErr = err
+	}
+	acc.Choices[0].Text = text.String()
+
+	streamErr := errors.Join(stream.Err(), streamWriteErr)
+	resp.Usage = usageFromOpenAICompletionUsage(acc.Usage)
+	pipeline.EmitUsage(resp.Usage)
+	if streamErr != nil {
+		resp.Error = providerError(providerName, streamErr)
+		pipeline.EmitError(resp.Error)
+	}
+	resp.Outputs = outputsFromOpenAICompletion(&acc)
+	return resp, &acc, streamErr
+}
+
+// legacyPrompt flattens the system prompt and the text of user and assistant messages into one prompt, in order,
+// separated by blank lines. A single user message is sent verbatim.
+func legacyPrompt(systemPrompt string, inputs []spec.InputUnion) string {
+	var parts []string
+	if sp := strings.TrimSpace(systemPrompt); sp != "" {
+		parts = append(parts, sp)
+	}
+	for _, in := range inputs {
+		var msg *spec.InputOutputContent
+		switch in.Kind {
+		case spec.InputKindInputMessage:
+			msg = in.InputMessage
+		case spec.InputKindOutputMessage:
+			msg = in.OutputMessage
+		default:
+			continue
+		}
+		if msg == nil {
+			continue
+		}
+		for _, it := range msg.Contents {
+			if it.Kind == spec.ContentItemKindText && it.TextItem != nil && it.TextItem.Text != "" {
+				parts = append(parts, it.TextItem.Text)
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func outputsFromOpenAICompletion(c *openai.Completion) []spec.OutputUnion {
+	if c == nil || len(c.Choices) == 0 || c.Choices[0].Text == "" {
+		return nil
+	}
+	choice := c.Choices[0]
+	status := spec.StatusCompleted
+	if choice.FinishReason == openai.CompletionChoiceFinishReasonLength {
+		status = spec.StatusIncomplete
+	}
+	return []spec.OutputUnion{{
+		Kind: spec.OutputKindOutputMessage,
+		OutputMessage: &spec.InputOutputContent{
+			ID:     c.ID,
+			Role:   spec.RoleAssistant,
+			Status: status,
+			Contents: []spec.InputOutputContentItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: choice.Text},
+			}},
+		},
+	}}
+}
+
+func usageFromOpenAICompletionUsage(u openai.CompletionUsage) *spec.Usage {
+	return &spec.Usage{
+		InputTokensTotal:    u.PromptTokens,
+		InputTokensCached:   u.PromptTokensDetails.CachedTokens,
+		InputTokensUncached: max(u.PromptTokens-u.PromptTokensDetails.CachedTokens, 0),
+		OutputTokens:        u.CompletionTokens,
+		ReasoningTokens:     u.CompletionTokensDetails.ReasoningTokens,
+	}
+}
@@ -0,0 +1,50 @@
+package openaichatsdk
+
+import (
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/param"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// applyOpenAICompatibleCapabilities drops the fields of fully built params that a generic OpenAI-compatible server
+// does not support, and requests streaming usage where it does. Nil capabilities mean the defaults.
+func applyOpenAICompatibleCapabilities(
+	params *openai.ChatCompletionNewParams,
+	capabilities *spec.OpenAICompatibleCapabilities,
+	stream bool,
+) {
+	caps := spec.DefaultOpenAICompatibleCapabilities
+	if capabilities != nil {
+		caps = *capabilities
+	}
+	if !caps.SupportsTools {
+		params.Tools = nil
+		params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{}
+	}
+	if !caps.SupportsTools || !caps.SupportsParallelToolCalls {
+		params.ParallelToolCalls = param.Opt[bool]{}
+	}
+	if !caps.SupportsReasoningEffort {
+		params.ReasoningEffort = ""
+	}
+	if caps.SupportsStreamUsage && stream {
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	}
+	if !caps.SupportsJSONSchema && params.ResponseFormat.OfJSONSchema != nil {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{}
+	}
+	if !caps.SupportsStopSequences {
+		params.Stop = openai.ChatCompletionNewParamsStopUnion{}
+	}
+	if !caps.SupportsVerbosity {
+		params.Verbosity = ""
+	}
+	if !caps.SupportsWebSearch {
+		params.WebSearchOptions = openai.ChatCompletionNewParamsWebSearchOptions{}
+	}
+	if !caps.SupportsLogprobs {
+		params.Logprobs = param.Opt[bool]{}
+		params.TopLogprobs = param.Opt[int64]{}
+	}
+}
@@ -0,0 +1,128 @@
+package openaichatsdk
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/shared"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestApplyOpenAICompatibleCapabilities(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		capabilities *spec.OpenAICompatibleCapabilities
+		stream       bool
+		want         []string
+		notWant      []string
+	}{
+		{
+			name:   "Defaults.",
+			stream: true,
+			want:   []string{`"tools":`, `"tool_choice":"auto"`, `"response_format":`, `"stop":`},
+			notWant: []string{
+				"parallel_tool_calls", "reasoning_effort", "stream_options", "verbosity", "logprobs",
+			},
+		},
+		{
+			name:         "NothingSupported.",
+			capabilities: &spec.OpenAICompatibleCapabilities{},
+			notWant: []string{
+				"tools", "tool_choice", "parallel_tool_calls", "reasoning_effort", "response_format", "stop",
+				"logprobs",
+			},
+		},
+		{
+			name: "AllSupported.",
+			capabilities: &spec.OpenAICompatibleCapabilities{
+				SupportsTools: true, SupportsParallelToolCalls: true, SupportsReasoningEffort: true,
+				SupportsStreamUsage: true, SupportsJSONSchema: true, SupportsStopSequences: true,
+				SupportsVerbosity: true, SupportsLogprobs: true,
+			},
+			stream: true,
+			want: []string{
+				`"tools":`, `"parallel_tool_calls":false`, `"reasoning_effort":"low"`,
+				`"stream_options":{"include_usage":true}`, `"response_format":`, `"stop":`, `"verbosity":"low"`,
+				`"logprobs":true`, `"top_logprobs":3`,
+			},
+		},
+		{
+			name:         "StreamUsageNotStreaming.",
+			capabilities: &spec.OpenAICompatibleCapabilities{SupportsStreamUsage: true},
+			notWant:      []string{"stream_options"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := openai.ChatCompletionNewParams{
+				Model: "m",
+				Tools: []openai.ChatCompletionToolUnionParam{
+					openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{Name: "lookup"}),
+				},
+				ToolChoice: openai.ChatCompletionToolChoiceOptionUnionParam{
+					OfAuto: openai.String(string(openai.ChatCompletionToolChoiceOptionAutoAuto)),
+				},
+				ParallelToolCalls: openai.Bool(false),
+				ReasoningEffort:   shared.ReasoningEffortLow,
+				ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+					OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+						JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{Name: "answer"},
+					},
+				},
+				Stop:        openai.ChatCompletionNewParamsStopUnion{OfStringArray: []string{"END"}},
+				Verbosity:   openai.ChatCompletionNewParamsVerbosityLow,
+				Logprobs:    openai.Bool(true),
+				TopLogprobs: openai.Int(3),
+			}
+			applyOpenAICompatibleCapabilities(&params, tc.capabilities, tc.stream)
+			b, err := json.Marshal(params)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v.", err)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(string(b), w) {
+					t.Fatalf("params %s do not contain %s.", b, w)
+				}
+			}
+			for _, w := range tc.notWant {
+				if strings.Contains(string(b), w) {
+					t.Fatalf("params %s contain %s.", b, w)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAICompatibleWithoutAPIKey(t *testing.T) {
+	t.Parallel()
+
+	requests := make(chan capturedRequest, 1)
+	api := newTestAPI(t, spec.ProviderParam{
+		Name:                     "lmstudio",
+		SDKType:                  spec.ProviderSDKTypeOpenAICompatible,
+		ChatCompletionPathPrefix: "/v1/chat/completions",
+	}, fixtureHandler(`{"id":"c1","object":"chat.completion","created":0,"model":"m",`+
+		`"choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`, "", requests))
+	if !api.IsConfigured(t.Context()) {
+		t.Fatalf("IsConfigured() = false, want true without an API key.")
+	}
+
+	_, err := api.FetchCompletion(t.Context(), &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs:     userInputs("hi"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	if gotAuth := (<-requests).header.Values(spec.DefaultAuthorizationHeaderKey); len(gotAuth) != 0 {
+		t.Fatalf("Authorization = %q, want no header.", gotAuth)
+	}
+}
@@ -0,0 +1,65 @@
+package openaichatsdk
+
+import (
+	"strconv"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// applyOpenRouterOptions adds OpenRouter's routing and usage accounting fields to the request body.
+func applyOpenRouterOptions(params *openai.ChatCompletionNewParams, o *spec.OpenRouterOptions) {
+	if params == nil {
+		return
+	}
+	extra := map[string]any{"usage": map[string]any{"include": true}}
+	if o != nil {
+		if p := o.Provider; p != nil {
+			prefs := map[string]any{}
+			if len(p.Order) > 0 {
+				prefs["order"] = p.Order
+			}
+			if p.AllowFallbacks != nil {
+				prefs["allow_fallbacks"] = *p.AllowFallbacks
+			}
+			if p.RequireParameters {
+				prefs["require_parameters"] = true
+			}
+			if p.DataCollection != "" {
+				prefs["data_collection"] = p.DataCollection
+			}
+			if len(p.Only) > 0 {
+				prefs["only"] = p.Only
+			}
+			if len(p.Ignore) > 0 {
+				prefs["ignore"] = p.Ignore
+			}
+			if p.Sort != "" {
+				prefs["sort"] = p.Sort
+			}
+			if len(prefs) > 0 {
+				extra["provider"] = prefs
+			}
+		}
+		if len(o.FallbackModels) > 0 {
+			// OpenRouter tries the models array in order, so the requested model goes first.
+			models := []spec.ModelName{spec.ModelName(params.Model)}
+			extra["models"] = append(models, o.FallbackModels...)
+		}
+		if len(o.Transforms) > 0 {
+			extra["transforms"] = o.Transforms
+		}
+	}
+
+	mergeExtraFields(params, extra)
+}
+
+// usageCost returns the cost reported in the usage object by OpenRouter (and compatible gateways), if any.
+func usageCost(u openai.CompletionUsage) *float64 {
+	cost, err := strconv.ParseFloat(u.JSON.ExtraFields["cost"].Raw(), 64)
+	if err != nil {
+		return nil
+	}
+	return &cost
+}
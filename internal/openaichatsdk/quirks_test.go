@@ -0,0 +1,119 @@
+package openaichatsdk
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestApplyOpenAIChatQuirks(t *testing.T) {
+	t.Parallel()
+
+	one := 1.0
+	tests := []struct {
+		name    string
+		quirks  *spec.ProviderQuirks
+		maxOut  int64
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "NoQuirks.",
+			maxOut: 100,
+			want:   []string{`"max_completion_tokens":100`, `"parallel_tool_calls":false`, `"temperature":0.2`},
+		},
+		{
+			name: "AllQuirks.",
+			quirks: &spec.ProviderQuirks{
+				NoParallelToolCalls:  true,
+				RequiresMaxTokens:    true,
+				LegacyMaxTokensField: true,
+				FixedTemperature:     &one,
+			},
+			want:    []string{`"max_tokens":4096`, `"temperature":1`},
+			notWant: []string{"parallel_tool_calls", "max_completion_tokens"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := openai.ChatCompletionNewParams{
+				Model:             "m",
+				ParallelToolCalls: openai.Bool(false),
+				Temperature:       openai.Float(0.2),
+			}
+			if tc.maxOut > 0 {
+				params.MaxCompletionTokens = openai.Int(tc.maxOut)
+			}
+			applyOpenAIChatQuirks(&params, tc.quirks)
+			b, err := json.Marshal(params)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v.", err)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(string(b), w) {
+					t.Fatalf("params %s do not contain %s.", b, w)
+				}
+			}
+			for _, w := range tc.notWant {
+				if strings.Contains(string(b), w) {
+					t.Fatalf("params %s contain %s.", b, w)
+				}
+			}
+		})
+	}
+}
+
+func TestUsesDeveloperRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		pi    *spec.ProviderParam
+		model spec.ModelName
+		want  bool
+	}{
+		{name: "OpenAIDefaultReasoning.", pi: &spec.ProviderParam{Name: "openai"}, model: "o3", want: true},
+		{name: "OpenAIDefaultGPT5.", pi: &spec.ProviderParam{Name: "openai"}, model: "gpt-5-mini", want: true},
+		{name: "OpenAIDefaultOther.", pi: &spec.ProviderParam{Name: "openai"}, model: "gpt-4o"},
+		{name: "OtherProviderDefault.", pi: &spec.ProviderParam{Name: "openrouter"}, model: "openai/o3"},
+		{
+			name: "OtherProviderPolicy.",
+			pi: &spec.ProviderParam{
+				Name:          "openrouter",
+				DeveloperRole: &spec.DeveloperRolePolicy{Models: []string{"openai/o*"}},
+			},
+			model: "openai/o3",
+			want:  true,
+		},
+		{
+			name:  "EmptyPolicy.",
+			pi:    &spec.ProviderParam{Name: "openai", DeveloperRole: &spec.DeveloperRolePolicy{}},
+			model: "o3",
+		},
+		{
+			name: "QuirkWins.",
+			pi: &spec.ProviderParam{
+				Name:          "azure",
+				Quirks:        &spec.ProviderQuirks{NoDeveloperRole: true},
+				DeveloperRole: &spec.DeveloperRolePolicy{Models: []string{"*"}},
+			},
+			model: "o3",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := usesDeveloperRole(tc.pi, tc.model); got != tc.want {
+				t.Fatalf("usesDeveloperRole() = %v, want = %v.", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package openaichatsdk
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/openai/openai-go/v3/packages/respjson"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// reasoningContentFields are the non-standard message and delta fields carrying reasoning text: reasoning_content
+// (DeepSeek, vLLM, Qwen) and reasoning (OpenRouter, Ollama).
+var reasoningContentFields = []string{"reasoning_content", "reasoning"}
+
+// reasoningContent returns the reasoning text of a message or delta, if the backend sent any.
+func reasoningContent(extra map[string]respjson.Field) string {
+	for _, name := range reasoningContentFields {
+		f, ok := extra[name]
+		if !ok {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal([]byte(f.Raw()), &s); err == nil && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// reasoningOutputs wraps reasoning text in a reasoning message output, or returns nil if there is none.
+func reasoningOutputs(id, reasoning string, status spec.Status) []spec.OutputUnion {
+	r := strings.TrimSpace(reasoning)
+	if r == "" {
+		return nil
+	}
+	return []spec.OutputUnion{{
+		Kind: spec.OutputKindReasoningMessage,
+		ReasoningMessage: &spec.ReasoningContent{
+			ID:       id,
+			Role:     spec.RoleAssistant,
+			Status:   status,
+			Thinking: []string{r},
+		},
+	}}
+}
@@ -0,0 +1,89 @@
+package openaichatsdk
+
+import (
+	"slices"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// withSearchGrounding maps the citations and search_results fields of Perplexity Sonar (and compatible grounded
+// backends) onto outs. The search results become a web search tool output ahead of the answer, and the cited URLs
+// become URL citations on the answer text. citations and searchResults are the raw JSON of the fields.
+func withSearchGrounding(
+	outs []spec.OutputUnion,
+	id, citations, searchResults string,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) []spec.OutputUnion {
+	results := gjson.Parse(searchResults).Array()
+	titles := make(map[string]string, len(results))
+	items := make([]spec.WebSearchToolOutputItemUnion, 0, len(results))
+	for _, r := range results {
+		url := r.Get("url").String()
+		if url == "" {
+			continue
+		}
+		titles[url] = r.Get("title").String()
+		items = append(items, spec.WebSearchToolOutputItemUnion{
+			Kind: spec.WebSearchToolOutputKindSearch,
+			SearchItem: &spec.WebSearchToolOutputSearch{
+				URL:             url,
+				Title:           r.Get("title").String(),
+				RenderedContent: r.Get("snippet").String(),
+				PageAge:         r.Get("date").String(),
+			},
+		})
+	}
+
+	var cites []spec.Citation
+	for _, c := range gjson.Parse(citations).Array() {
+		if c.String() == "" {
+			continue
+		}
+		cites = append(cites, spec.Citation{
+			Kind:        spec.CitationKindURL,
+			URLCitation: &spec.URLCitation{URL: c.String(), Title: titles[c.String()]},
+		})
+	}
+
+	answer := slices.IndexFunc(outs, func(o spec.OutputUnion) bool {
+		return o.Kind == spec.OutputKindOutputMessage && o.OutputMessage != nil
+	})
+	if answer >= 0 && len(cites) > 0 {
+		for _, c := range outs[answer].OutputMessage.Contents {
+			if c.Kind == spec.ContentItemKindText && c.TextItem != nil {
+				c.TextItem.Citations = append(c.TextItem.Citations, cites...)
+				break
+			}
+		}
+	}
+	if len(items) == 0 {
+		return outs
+	}
+
+	var choiceID string
+	for _, tc := range toolChoiceNameMap {
+		if tc.Type == spec.ToolTypeWebSearch {
+			choiceID = tc.ID
+			break
+		}
+	}
+	search := spec.OutputUnion{
+		Kind: spec.OutputKindWebSearchToolOutput,
+		WebSearchToolOutput: &spec.ToolOutput{
+			ChoiceID:                 choiceID,
+			Type:                     spec.ToolTypeWebSearch,
+			Role:                     spec.RoleAssistant,
+			ID:                       id,
+			CallID:                   id,
+			Status:                   spec.StatusCompleted,
+			Name:                     spec.DefaultWebSearchToolName,
+			WebSearchToolOutputItems: items,
+		},
+	}
+	if answer < 0 {
+		return append(outs, search)
+	}
+	return slices.Insert(outs, answer, search)
+}
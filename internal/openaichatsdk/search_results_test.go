@@ -0,0 +1,92 @@
+package openaichatsdk
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	testCitations     = `["https://go.dev/doc","https://go.dev/blog"]`
+	testSearchResults = `[{"title":"Go docs","url":"https://go.dev/doc","date":"2025-01-02","snippet":"Docs."},` +
+		`{"title":"Go blog","url":"https://go.dev/blog","snippet":"Blog."}]`
+)
+
+func TestWithSearchGrounding(t *testing.T) {
+	t.Parallel()
+
+	answer := func() []spec.OutputUnion {
+		return []spec.OutputUnion{{
+			Kind: spec.OutputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{
+				Role: spec.RoleAssistant,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: "Go is great [1][2]."},
+				}},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name          string
+		outs          []spec.OutputUnion
+		citations     string
+		searchResults string
+		wantKinds     []spec.OutputKind
+		wantCitations []spec.URLCitation
+	}{
+		{
+			name: "NoGrounding.", outs: answer(),
+			wantKinds: []spec.OutputKind{spec.OutputKindOutputMessage},
+		},
+		{
+			name: "SearchResultsAndCitations.", outs: answer(),
+			citations: testCitations, searchResults: testSearchResults,
+			wantKinds: []spec.OutputKind{spec.OutputKindWebSearchToolOutput, spec.OutputKindOutputMessage},
+			wantCitations: []spec.URLCitation{
+				{URL: "https://go.dev/doc", Title: "Go docs"}, {URL: "https://go.dev/blog", Title: "Go blog"},
+			},
+		},
+		{
+			name: "CitationsOnly.", outs: answer(), citations: testCitations,
+			wantKinds:     []spec.OutputKind{spec.OutputKindOutputMessage},
+			wantCitations: []spec.URLCitation{{URL: "https://go.dev/doc"}, {URL: "https://go.dev/blog"}},
+		},
+		{
+			name: "SearchResultsWithoutAnswer.", searchResults: testSearchResults,
+			wantKinds: []spec.OutputKind{spec.OutputKindWebSearchToolOutput},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			outs := withSearchGrounding(tc.outs, "pplx1", tc.citations, tc.searchResults, nil)
+			var kinds []spec.OutputKind
+			var cites []spec.URLCitation
+			for _, o := range outs {
+				kinds = append(kinds, o.Kind)
+				if o.OutputMessage != nil {
+					for _, c := range o.OutputMessage.Contents[0].TextItem.Citations {
+						cites = append(cites, *c.URLCitation)
+					}
+				}
+				if o.WebSearchToolOutput != nil {
+					items := o.WebSearchToolOutput.WebSearchToolOutputItems
+					if len(items) != 2 || items[0].SearchItem.RenderedContent != "Docs." ||
+						items[0].SearchItem.PageAge != "2025-01-02" {
+						t.Fatalf("search items = %+v, want the two search results.", items)
+					}
+				}
+			}
+			if !reflect.DeepEqual(kinds, tc.wantKinds) {
+				t.Fatalf("output kinds = %v, want %v.", kinds, tc.wantKinds)
+			}
+			if !reflect.DeepEqual(cites, tc.wantCitations) {
+				t.Fatalf("citations = %+v, want %+v.", cites, tc.wantCitations)
+			}
+		})
+	}
+}
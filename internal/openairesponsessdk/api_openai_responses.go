@@ -1,9 +1,12 @@
 package openairesponsessdk
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -48,7 +51,7 @@ func (api *OpenAIResponsesAPI) InitLLM(ctx context.Context) error {
 		api.client = nil
 		return errors.New("openai responses api LLM: no ProviderParam found")
 	}
-	if strings.TrimSpace(api.ProviderParam.APIKey) == "" {
+	if strings.TrimSpace(api.ProviderParam.APIKey) == "" && api.ProviderParam.Credentials == nil {
 		logutil.Debug(
 			string(
 				api.ProviderParam.Name,
@@ -71,6 +74,10 @@ func (api *OpenAIResponsesAPI) InitLLM(ctx context.Context) error {
 		pathPrefix := pi.ChatCompletionPathPrefix
 		// Remove "responses" from pathPrefix if present; SDK adds it internally.
 		pathPrefix = strings.TrimSuffix(pathPrefix, "responses")
+		if pi.Azure != nil {
+			// Azure paths are derived from the resource endpoint by the Azure middleware.
+			pathPrefix = ""
+		}
 
 		providerURL = baseURL + pathPrefix
 		opts = append(opts, option.WithBaseURL(strings.TrimSuffix(providerURL, "/")))
@@ -91,10 +98,31 @@ func (api *OpenAIResponsesAPI) InitLLM(ctx context.Context) error {
 		)
 	}
 
-	if api.debugger != nil {
-		if httpClient := api.debugger.HTTPClient(nil); httpClient != nil {
-			opts = append(opts, option.WithHTTPClient(httpClient))
+	// Propagate spec.RequestContext from the request context as headers.
+	opts = append(opts, option.WithMiddleware(sdkutil.RequestContextMiddleware))
+
+	if pi.Azure != nil {
+		opts = append(opts, option.WithMiddleware(sdkutil.AzureMiddleware(pi.Azure, pi.APIKey)))
+	}
+	if pi.Credentials != nil {
+		opts = append(opts, option.WithMiddleware(sdkutil.CredentialsMiddleware(pi.Credentials)))
+	}
+
+	if pi.OriginFailover != nil && len(pi.OriginFailover.Origins) > 0 {
+		primary := spec.DefaultOpenAIOrigin
+		if pi.Origin != "" {
+			primary = pi.Origin
+		}
+		pool, err := sdkutil.NewOriginPool(primary, pi.OriginFailover)
+		if err != nil {
+			api.client = nil
+			return err
 		}
+		opts = append(opts, option.WithMiddleware(pool.Middleware))
+	}
+
+	if httpClient := sdkutil.HTTPClient(&pi, api.debugger); httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
 	}
 
 	c := openai.NewClient(opts...)
@@ -139,7 +167,8 @@ func (api *OpenAIResponsesAPI) GetProviderInfo(ctx context.Context) *spec.Provid
 func (api *OpenAIResponsesAPI) IsConfigured(ctx context.Context) bool {
 	api.mu.RLock()
 	defer api.mu.RUnlock()
-	return api.ProviderParam != nil && strings.TrimSpace(api.ProviderParam.APIKey) != ""
+	return api.ProviderParam != nil &&
+		(api.ProviderParam.Credentials != nil || strings.TrimSpace(api.ProviderParam.APIKey) != "")
 }
 
 // SetProviderAPIKey sets the key for a provider.
@@ -159,10 +188,58 @@ func (api *OpenAIResponsesAPI) SetProviderAPIKey(
 	return nil
 }
 
+// FetchRaw sends an arbitrary request to the provider, reusing the configured auth, base URL, debugger and retries.
+func (api *OpenAIResponsesAPI) FetchRaw(
+	ctx context.Context,
+	method, path string,
+	body json.RawMessage,
+) (*spec.RawResponse, error) {
+	api.mu.RLock()
+	client := api.client
+	api.mu.RUnlock()
+	if client == nil {
+		return nil, errors.New("openai responses api LLM: client not initialized")
+	}
+	relPath, err := sdkutil.RawRequestPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var params any
+	if len(body) > 0 {
+		params = body
+	}
+	var httpResp *http.Response
+	if err := client.Execute(ctx, strings.ToUpper(method), relPath, params, &httpResp); err != nil {
+		// Error statuses are returned like any other response; the SDK keeps the body on the error.
+		var apiErr *openai.Error
+		if errors.As(err, &apiErr) && apiErr.Response != nil {
+			return sdkutil.ReadRawResponse(apiErr.Response)
+		}
+		return nil, err
+	}
+	return sdkutil.ReadRawResponse(httpResp)
+}
+
 func (api *OpenAIResponsesAPI) FetchCompletion(
 	ctx context.Context,
 	req *spec.FetchCompletionRequest,
 	opts *spec.FetchCompletionOptions,
+) (resp *spec.FetchCompletionResponse, err error) {
+	api.mu.RLock()
+	var providerName spec.ProviderName
+	if api.ProviderParam != nil {
+		providerName = api.ProviderParam.Name
+	}
+	api.mu.RUnlock()
+	defer sdkutil.RecoverFetchCompletion(providerName, &resp, &err)
+
+	return api.fetchCompletion(ctx, req, opts)
+}
+
+func (api *OpenAIResponsesAPI) fetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
 ) (*spec.FetchCompletionResponse, error) {
 	api.mu.RLock()
 	client := api.client
@@ -172,7 +249,7 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 	}
 	api.mu.RUnlock()
 
-	if client == nil {
+	if client == nil && (opts == nil || !opts.DryRun) {
 		return nil, errors.New("openai responses api LLM: client not initialized")
 	}
 	if req == nil || len(req.Inputs) == 0 || req.ModelParam.Name == "" {
@@ -193,7 +270,7 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 	// Log: logutil.LogJSON(inputItems).
 
 	params := responses.ResponseNewParams{
-		Model:   shared.ChatModel(req.ModelParam.Name),
+		Model:   shared.ChatModel(pi.Azure.Deployment(req.ModelParam.Name)),
 		Input:   responses.ResponseNewParamsInputUnion{OfInputItemList: inputItems},
 		Store:   openai.Bool(false),
 		Include: []responses.ResponseIncludable{"reasoning.encrypted_content"},
@@ -201,6 +278,14 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 	if req.ModelParam.MaxOutputLength > 0 {
 		params.MaxOutputTokens = openai.Int(int64(req.ModelParam.MaxOutputLength))
 	}
+	if rc, ok := spec.RequestContextFromContext(ctx); ok {
+		params.Metadata = requestContextMetadata(rc)
+	}
+	if req.ModelParam.Background {
+		// Background responses must be stored so they can be retrieved later.
+		params.Background = openai.Bool(true)
+		params.Store = openai.Bool(true)
+	}
 
 	// Top‑level instructions.
 	if sys := strings.TrimSpace(req.ModelParam.SystemPrompt); sys != "" {
@@ -209,6 +294,9 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 	if req.ModelParam.Temperature != nil {
 		params.Temperature = openai.Float(*req.ModelParam.Temperature)
 	}
+	if req.ModelParam.TopP != nil {
+		params.TopP = openai.Float(*req.ModelParam.TopP)
+	}
 
 	if rp := req.ModelParam.Reasoning; rp != nil &&
 		rp.Type == spec.ReasoningTypeSingleWithLevels {
@@ -243,10 +331,7 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 		}
 	}
 
-	timeout := spec.DefaultAPITimeout
-	if req.ModelParam.Timeout > 0 {
-		timeout = time.Duration(req.ModelParam.Timeout) * time.Second
-	}
+	timeout := pi.Timeouts.Timeout(req.ModelParam.Name, req.ModelParam.Timeout)
 
 	// Optional: output format + verbosity (Responses uses top-level "text").
 	if err := applyOpenAIResponsesOutputParam(&params, req.ModelParam.OutputParam); err != nil {
@@ -262,6 +347,7 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 		if len(toolDefs) > 0 {
 			params.Tools = toolDefs
 			toolChoiceNameMap = nameMap
+			params.Include = append(params.Include, hostedToolIncludes(toolDefs)...)
 			// Optional: tool policy (tool_choice).
 			if req.ToolPolicy != nil {
 				if err := applyOpenAIResponsesToolPolicy(&params, req.ToolPolicy, toolChoiceNameMap); err != nil {
@@ -271,6 +357,21 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 		}
 	}
 
+	applyOpenAIResponsesQuirks(&params, pi.Quirks)
+	if err := applyOpenAIResponsesParamOverlay(&params, req.ModelParam.AdditionalParametersRawJSON); err != nil {
+		return nil, err
+	}
+
+	effective := params
+	effective.Input = responses.ResponseNewParamsInputUnion{}
+	effective.Instructions, effective.Tools = param.Opt[string]{}, nil
+	effectiveParams := sdkutil.EffectiveParams(effective, "input", "instructions", "tools")
+
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	if opts != nil && opts.DryRun {
+		return sdkutil.DryRunResponse(&pi, req.ModelParam.Name, useStream, params)
+	}
+
 	var span spec.CompletionSpan
 	if api.debugger != nil {
 		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
@@ -286,7 +387,6 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 		fullRawResp    *responses.Response
 		apiErr         error
 	)
-	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
 	if useStream {
 		normalizedResp, fullRawResp, apiErr = api.doStreaming(
 			ctx,
@@ -299,7 +399,20 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 			toolChoiceNameMap,
 		)
 	} else {
-		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(ctx, client, params, timeout, toolChoiceNameMap)
+		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(
+			ctx,
+			client,
+			pi.Name,
+			params,
+			timeout,
+			toolChoiceNameMap,
+		)
+	}
+
+	normalizedResp = sdkutil.FinalizeResponse(pi.Name, normalizedResp, apiErr)
+	if normalizedResp != nil {
+		normalizedResp.Metadata = responseMetadata(fullRawResp)
+		normalizedResp.Metadata.EffectiveParams = effectiveParams
 	}
 
 	if span != nil {
@@ -323,6 +436,7 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 func (api *OpenAIResponsesAPI) doNonStreaming(
 	ctx context.Context,
 	client *openai.Client,
+	providerName spec.ProviderName,
 	params responses.ResponseNewParams,
 	timeout time.Duration,
 	toolChoiceNameMap map[string]spec.ToolChoice,
@@ -333,7 +447,7 @@ func (api *OpenAIResponsesAPI) doNonStreaming(
 	resp.Usage = usageFromOpenAIResponse(oaiResp)
 
 	if err != nil {
-		resp.Error = &spec.Error{Message: err.Error()}
+		resp.Error = providerError(providerName, err, "", "")
 		// Even on error, return any partial usage/debug we have.
 		return resp, oaiResp, err
 	}
@@ -353,47 +467,12 @@ func (api *OpenAIResponsesAPI) doStreaming(
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *responses.Response, error) {
 	resp := &spec.FetchCompletionResponse{}
-	streamCfg := sdkutil.ResolveStreamConfig(opts)
-
-	emitText := func(chunk string) error {
-		if strings.TrimSpace(chunk) == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:     spec.StreamContentKindText,
-			Provider: providerName,
-			Model:    modelName,
-			Text: &spec.StreamTextChunk{
-				Text: chunk,
-			},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
-
-	emitThinking := func(chunk string) error {
-		if strings.TrimSpace(chunk) == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:     spec.StreamContentKindThinking,
-			Provider: providerName,
-			Model:    modelName,
-			Thinking: &spec.StreamThinkingChunk{
-				Text: chunk,
-			},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
-
-	writeTextData, flushTextData := sdkutil.NewBufferedStreamer(
-		emitText,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
-	writeThinkingData, flushThinkingData := sdkutil.NewBufferedStreamer(
-		emitThinking,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
+	pipeline := sdkutil.NewStreamPipeline(
+		ctx,
+		opts.StreamHandler,
+		providerName,
+		modelName,
+		sdkutil.ResolveStreamConfig(opts),
 	)
 
 	var oaiResp responses.Response
@@ -405,13 +484,30 @@ func (api *OpenAIResponsesAPI) doStreaming(
 	)
 	defer func() { _ = stream.Close() }()
 
-	var streamWriteErr error
+	var (
+		streamWriteErr error
+		// apiErrCode and apiErrRaw are the error code and body of a response.failed event.
+		apiErrCode string
+		apiErrRaw  string
+		// doneItems and pendingText salvage the output of a stream that fails before the final response arrives.
+		doneItems   []responses.ResponseOutputItemUnion
+		pendingText strings.Builder
+	)
 	for stream.Next() {
 		chunk := stream.Current()
 
+		if chunk.Type == "response.output_item.done" {
+			doneItems = append(doneItems, chunk.Item)
+			pendingText.Reset()
+		}
+
 		// Incremental assistant text.
 		if chunk.Type == "response.output_text.delta" {
-			streamWriteErr = writeTextData(chunk.Delta)
+			pendingText.WriteString(chunk.Delta)
+			streamWriteErr = pipeline.WriteText(
+				sdkutil.StreamPosition{OutputItemIndex: int(chunk.OutputIndex), ContentIndex: int(chunk.ContentIndex)},
+				chunk.Delta,
+			)
 			if streamWriteErr != nil {
 				break
 			}
@@ -419,7 +515,10 @@ func (api *OpenAIResponsesAPI) doStreaming(
 
 		// Incremental reasoning text.
 		if chunk.Type == "response.reasoning_summary_text.delta" {
-			streamWriteErr = writeThinkingData(chunk.Delta)
+			streamWriteErr = pipeline.WriteThinking(
+				sdkutil.StreamPosition{OutputItemIndex: int(chunk.OutputIndex), ContentIndex: int(chunk.SummaryIndex)},
+				chunk.Delta,
+			)
 			if streamWriteErr != nil {
 				break
 			}
@@ -427,7 +526,34 @@ func (api *OpenAIResponsesAPI) doStreaming(
 
 		// Incremental reasoning text.
 		if chunk.Type == "response.reasoning_text.delta" {
-			streamWriteErr = writeThinkingData(chunk.Delta)
+			streamWriteErr = pipeline.WriteThinking(
+				sdkutil.StreamPosition{OutputItemIndex: int(chunk.OutputIndex), ContentIndex: int(chunk.ContentIndex)},
+				chunk.Delta,
+			)
+			if streamWriteErr != nil {
+				break
+			}
+		}
+
+		// Partial images of an in-progress image generation.
+		if event, ok := imagePartialEventFromOpenAIStreamEvent(&chunk, toolChoiceNameMap); ok {
+			streamWriteErr = pipeline.Emit(event)
+			if streamWriteErr != nil {
+				break
+			}
+		}
+
+		// URL citations of the text streamed so far.
+		if event, ok := citationEventFromOpenAIStreamEvent(&chunk); ok {
+			streamWriteErr = pipeline.Emit(event)
+			if streamWriteErr != nil {
+				break
+			}
+		}
+
+		// Output item and content part lifecycle.
+		if event, ok := lifecycleEventFromOpenAIStreamEvent(&chunk); ok {
+			streamWriteErr = pipeline.Emit(event)
 			if streamWriteErr != nil {
 				break
 			}
@@ -446,6 +572,7 @@ func (api *OpenAIResponsesAPI) doStreaming(
 				errJSON = "unknown error"
 			}
 			streamWriteErr = fmt.Errorf("API failed, %s", errJSON)
+			apiErrCode, apiErrRaw = string(oaiResp.Error.Code), oaiResp.Error.RawJSON()
 			break
 		}
 
@@ -457,27 +584,172 @@ func (api *OpenAIResponsesAPI) doStreaming(
 		}
 
 	}
-	if flushTextData != nil {
-		flushTextData()
-	}
-	if flushThinkingData != nil {
-		flushThinkingData()
+	if err := pipeline.Close(); err != nil && streamWriteErr == nil {
+		streamWriteErr = err
 	}
 
 	streamErr := errors.Join(stream.Err(), streamWriteErr)
-
 	resp.Usage = usageFromOpenAIResponse(&oaiResp)
+	pipeline.EmitUsage(resp.Usage)
 	if streamErr != nil {
-		resp.Error = &spec.Error{Message: streamErr.Error()}
-	}
-
-	if len(oaiResp.Output) > 0 {
+		resp.Error = providerError(providerName, streamErr, apiErrCode, apiErrRaw)
+		pipeline.EmitError(resp.Error)
+	}
+
+	// A failed stream without a final response keeps the completed output items.
+	salvage := streamErr != nil && len(oaiResp.Output) == 0
+	switch {
+	case salvage && len(doneItems) > 0:
+		salvaged := oaiResp
+		salvaged.Output = doneItems
+		resp.Outputs = outputsFromOpenAIResponse(&salvaged, toolChoiceNameMap)
+	case len(oaiResp.Output) > 0:
 		resp.Outputs = outputsFromOpenAIResponse(&oaiResp, toolChoiceNameMap)
 	}
+	if salvage && pendingText.Len() > 0 {
+		// Text of the message that was still streaming when the call failed.
+		resp.Outputs = append(resp.Outputs, spec.OutputUnion{
+			Kind: spec.OutputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{
+				Role:   spec.RoleAssistant,
+				Status: spec.StatusIncomplete,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: pendingText.String()},
+				}},
+			},
+		})
+	}
 
 	return resp, &oaiResp, streamErr
 }
 
+// providerError returns the spec.Error of a failed call, with the status, code and body of an OpenAI API error.
+// apiErrCode and apiErrRaw are the error code and body reported by a response.failed event, if any.
+func providerError(provider spec.ProviderName, err error, apiErrCode, apiErrRaw string) *spec.Error {
+	if apiErrCode != "" {
+		return sdkutil.NewError(provider, err, 0, apiErrCode, apiErrRaw)
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return sdkutil.NewError(provider, err, apiErr.StatusCode, cmp.Or(apiErr.Code, apiErr.Type), apiErr.RawJSON())
+	}
+	return sdkutil.NewError(provider, err, 0, "", "")
+}
+
+// citationEventFromOpenAIStreamEvent maps an output text annotation event carrying a URL citation to a stream event.
+func citationEventFromOpenAIStreamEvent(chunk *responses.ResponseStreamEventUnion) (spec.StreamEvent, bool) {
+	if chunk.Type != "response.output_text.annotation.added" || chunk.Annotation == nil {
+		return spec.StreamEvent{}, false
+	}
+	// The annotation is untyped in the event; decode it as the annotation union of the final output text.
+	raw, err := json.Marshal(chunk.Annotation)
+	if err != nil {
+		return spec.StreamEvent{}, false
+	}
+	var ann responses.ResponseOutputTextAnnotationUnion
+	if err := json.Unmarshal(raw, &ann); err != nil {
+		return spec.StreamEvent{}, false
+	}
+	citations := responsesAnnotationsToCitations([]responses.ResponseOutputTextAnnotationUnion{ann})
+	if len(citations) == 0 {
+		return spec.StreamEvent{}, false
+	}
+	return spec.StreamEvent{
+		Kind:            spec.StreamContentKindCitation,
+		OutputItemIndex: int(chunk.OutputIndex),
+		ContentIndex:    int(chunk.ContentIndex),
+		Citation:        &citations[0],
+	}, true
+}
+
+// lifecycleEventFromOpenAIStreamEvent maps output item and content part added/done events to a stream event.
+func lifecycleEventFromOpenAIStreamEvent(chunk *responses.ResponseStreamEventUnion) (spec.StreamEvent, bool) {
+	var phase spec.StreamLifecyclePhase
+	switch chunk.Type {
+	case "response.output_item.added", "response.content_part.added":
+		phase = spec.StreamLifecyclePhaseAdded
+	case "response.output_item.done", "response.content_part.done":
+		phase = spec.StreamLifecyclePhaseDone
+	default:
+		return spec.StreamEvent{}, false
+	}
+
+	if chunk.Type == "response.output_item.added" || chunk.Type == "response.output_item.done" {
+		item := &spec.StreamOutputItemChunk{
+			Phase:            phase,
+			OutputIndex:      int(chunk.OutputIndex),
+			ItemID:           chunk.Item.ID,
+			OutputKind:       outputKindFromOpenAIItemType(chunk.Item.Type),
+			ProviderItemType: chunk.Item.Type,
+			CallID:           chunk.Item.CallID,
+			Name:             chunk.Item.Name,
+		}
+		return spec.StreamEvent{
+			Kind:            spec.StreamContentKindOutputItem,
+			OutputItemIndex: item.OutputIndex,
+			OutputItem:      item,
+		}, true
+	}
+
+	part := &spec.StreamContentPartChunk{
+		Phase:            phase,
+		OutputIndex:      int(chunk.OutputIndex),
+		ContentIndex:     int(chunk.ContentIndex),
+		ItemID:           chunk.ItemID,
+		ProviderPartType: chunk.Part.Type,
+	}
+	return spec.StreamEvent{
+		Kind:            spec.StreamContentKindContentPart,
+		OutputItemIndex: part.OutputIndex,
+		ContentIndex:    part.ContentIndex,
+		ContentPart:     part,
+	}, true
+}
+
+func outputKindFromOpenAIItemType(t string) spec.OutputKind {
+	switch t {
+	case "message":
+		return spec.OutputKindOutputMessage
+	case "reasoning":
+		return spec.OutputKindReasoningMessage
+	case "function_call":
+		return spec.OutputKindFunctionToolCall
+	case "custom_tool_call":
+		return spec.OutputKindCustomToolCall
+	case "web_search_call":
+		return spec.OutputKindWebSearchToolCall
+	case "image_generation_call":
+		return spec.OutputKindImageGenerationToolOutput
+	case "code_interpreter_call":
+		return spec.OutputKindCodeInterpreterToolCall
+	case "file_search_call":
+		return spec.OutputKindFileSearchToolCall
+	case "computer_call":
+		return spec.OutputKindComputerToolCall
+	case "mcp_call":
+		return spec.OutputKindMCPToolCall
+	case "mcp_list_tools":
+		return spec.OutputKindMCPListTools
+	default:
+		return ""
+	}
+}
+
+// hostedToolIncludes asks for the outputs of hosted tools in tools, which are omitted from responses by default.
+func hostedToolIncludes(tools []responses.ToolUnionParam) []responses.ResponseIncludable {
+	var out []responses.ResponseIncludable
+	for _, t := range tools {
+		switch {
+		case t.OfCodeInterpreter != nil:
+			out = append(out, responses.ResponseIncludableCodeInterpreterCallOutputs)
+		case t.OfFileSearch != nil:
+			out = append(out, responses.ResponseIncludableFileSearchCallResults)
+		}
+	}
+	return out
+}
+
 func applyOpenAIResponsesOutputParam(params *responses.ResponseNewParams, op *spec.OutputParam) error {
 	if params == nil || op == nil {
 		return nil
@@ -535,6 +807,26 @@ func applyOpenAIResponsesOutputParam(params *responses.ResponseNewParams, op *sp
 	return nil
 }
 
+// applyOpenAIResponsesQuirks adjusts fully built params to the provider quirks.
+func applyOpenAIResponsesQuirks(params *responses.ResponseNewParams, quirks *spec.ProviderQuirks) {
+	if quirks == nil {
+		return
+	}
+	if quirks.NoParallelToolCalls {
+		params.ParallelToolCalls = param.Opt[bool]{}
+	}
+	if quirks.RequiresMaxTokens && !params.MaxOutputTokens.Valid() {
+		maxTokens := quirks.MaxTokens
+		if maxTokens <= 0 {
+			maxTokens = spec.DefaultQuirksMaxTokens
+		}
+		params.MaxOutputTokens = openai.Int(int64(maxTokens))
+	}
+	if quirks.FixedTemperature != nil {
+		params.Temperature = openai.Float(*quirks.FixedTemperature)
+	}
+}
+
 func applyOpenAIResponsesToolPolicy(
 	params *responses.ResponseNewParams,
 	policy *spec.ToolPolicy,
@@ -573,11 +865,14 @@ func applyOpenAIResponsesToolPolicy(
 		allowedChoices := make([]map[string]any, 0, len(resolvedTools))
 		for _, t := range resolvedTools {
 			c := map[string]any{
-				// We register tools as function tools (even for spec.ToolTypeCustom),
+				// We register tools as function tools (even for spec.ToolTypeCustom without a format),
 				// so tool_choice must reference "function".
 				"type": "function",
 				"name": t.Name,
 			}
+			if sdkutil.IsFormattedCustomTool(toolChoiceNameMap[t.Name]) {
+				c["type"] = "custom"
+			}
 			allowedChoices = append(allowedChoices, c)
 		}
 		if policy.Mode == spec.ToolPolicyModeTool {
@@ -704,10 +999,52 @@ func toOpenAIResponsesInput(
 
 		case spec.InputKindWebSearchToolOutput:
 			// Ok. Responses doesn't have a web search output.
+
+		case spec.InputKindImageGenerationToolOutput:
+			if item := imageGenerationOutputToOpenAI(in.ImageGenerationToolOutput); item != nil {
+				out = append(out, *item)
+			}
+
+		case spec.InputKindCodeInterpreterToolCall:
+			if item := codeInterpreterCallToOpenAI(in.CodeInterpreterToolCall); item != nil {
+				out = append(out, *item)
+			}
+
+		case spec.InputKindFileSearchToolCall:
+			if item := fileSearchCallToOpenAI(in.FileSearchToolCall); item != nil {
+				out = append(out, *item)
+			}
+
+		case spec.InputKindComputerToolCall:
+			if item := computerCallToOpenAI(in.ComputerToolCall); item != nil {
+				out = append(out, *item)
+			}
+
+		case spec.InputKindComputerToolOutput:
+			item, err := computerOutputToOpenAI(in.ComputerToolOutput)
+			if err != nil {
+				return nil, err
+			}
+			if item != nil {
+				out = append(out, *item)
+			}
+
+		case spec.InputKindMCPToolCall:
+			if item := mcpCallToOpenAI(in.MCPToolCall); item != nil {
+				out = append(out, *item)
+			}
+
+		case spec.InputKindMCPListTools:
+			if item := mcpListToolsToOpenAI(in.MCPListTools); item != nil {
+				out = append(out, *item)
+			}
+
+		case spec.InputKindCodeExecutionToolCall, spec.InputKindCodeExecutionToolOutput:
+			// Anthropic code execution items have no Responses equivalent.
 		}
 	}
 
-	return out, nil
+	return sanitizeOpenAIResponsesItemIDs(out), nil
 }
 
 // contentItemsToOpenAI converts spec.Content items to OpenAI input message parts.
@@ -776,8 +1113,16 @@ func contentItemsToOpenAIInputContent(
 			}
 			f := it.FileItem
 
-			// Prefer embedded data as true file input.
-			if data := strings.TrimSpace(f.FileData); data != "" {
+			// Prefer an uploaded file, then embedded data as true file input.
+			if id := strings.TrimSpace(f.FileID); id != "" {
+				fileParam := responses.ResponseInputFileParam{
+					FileID: param.NewOpt(id),
+					Type:   openaiSharedConstant.InputFile("").Default(),
+				}
+				out = append(out, responses.ResponseInputContentUnionParam{
+					OfInputFile: &fileParam,
+				})
+			} else if data := strings.TrimSpace(f.FileData); data != "" {
 				mime := spec.DefaultFileDataMIME
 				if f.FileMIME != "" {
 					mime = f.FileMIME
@@ -800,7 +1145,7 @@ func contentItemsToOpenAIInputContent(
 					OfInputFile: &fileParam,
 				})
 			} else {
-				logutil.Debug("no data or url present for file", "id", f.ID, "name", f.FileName)
+				logutil.Debug("no id, data or url present for file", "id", f.ID, "name", f.FileName)
 			}
 		case spec.ContentItemKindRefusal:
 			// Refusal should not be present in InputMessage.
@@ -1023,7 +1368,9 @@ func toolOutputToOpenAIResponses(
 		default:
 		}
 
-		items, err := contentItemsToOpenAIFunctionCallOutputContent(toolOutput.Contents)
+		items, err := contentItemsToOpenAIFunctionCallOutputContent(
+			sdkutil.ToolOutputContents(toolOutput, false),
+		)
 		if err != nil {
 			return nil
 		}
@@ -1042,7 +1389,9 @@ func toolOutputToOpenAIResponses(
 		}
 
 	case spec.ToolTypeCustom:
-		fcItems, err := contentItemsToOpenAIFunctionCallOutputContent(toolOutput.Contents)
+		fcItems, err := contentItemsToOpenAIFunctionCallOutputContent(
+			sdkutil.ToolOutputContents(toolOutput, false),
+		)
 		if err != nil {
 			return nil
 		}
@@ -1168,8 +1517,16 @@ func contentItemsToOpenAIFunctionCallOutputContent(
 			}
 			f := it.FileItem
 
-			// Prefer embedded data as true file input.
-			if data := strings.TrimSpace(f.FileData); data != "" {
+			// Prefer an uploaded file, then embedded data as true file input.
+			if id := strings.TrimSpace(f.FileID); id != "" {
+				fileParam := responses.ResponseInputFileContentParam{
+					FileID: param.NewOpt(id),
+					Type:   openaiSharedConstant.InputFile("").Default(),
+				}
+				out = append(out, responses.ResponseFunctionCallOutputItemUnionParam{
+					OfInputFile: &fileParam,
+				})
+			} else if data := strings.TrimSpace(f.FileData); data != "" {
 				mime := spec.DefaultFileDataMIME
 				if f.FileMIME != "" {
 					mime = f.FileMIME
@@ -1192,7 +1549,7 @@ func contentItemsToOpenAIFunctionCallOutputContent(
 					OfInputFile: &fileParam,
 				})
 			} else {
-				logutil.Debug("no data or url present for file", "id", f.ID, "name", f.FileName)
+				logutil.Debug("no id, data or url present for file", "id", f.ID, "name", f.FileName)
 			}
 		case spec.ContentItemKindRefusal:
 			// Refusal should not be present in call output.
@@ -1204,6 +1561,26 @@ func contentItemsToOpenAIFunctionCallOutputContent(
 	return out, nil
 }
 
+// customToolToOpenAIResponsesTool maps a custom tool with a format to a Responses custom tool.
+func customToolToOpenAIResponsesTool(name string, tc spec.ToolChoice) (responses.ToolUnionParam, error) {
+	if err := sdkutil.ValidateCustomToolFormat(tc); err != nil {
+		return responses.ToolUnionParam{}, fmt.Errorf("openai responses: %w", err)
+	}
+	custom := responses.CustomToolParam{
+		Name:        name,
+		Description: param.NewOpt(sdkutil.ToolDescription(tc)),
+	}
+	if tc.Format.Kind == spec.CustomToolFormatKindGrammar {
+		custom.Format.OfGrammar = &shared.CustomToolInputFormatGrammarParam{
+			Definition: tc.Format.Definition,
+			Syntax:     string(tc.Format.Syntax),
+		}
+	} else {
+		custom.Format.OfText = &shared.CustomToolInputFormatTextParam{}
+	}
+	return responses.ToolUnionParam{OfCustom: &custom}, nil
+}
+
 func toolChoicesToOpenAIResponseTools(
 	toolChoices []spec.ToolChoice,
 ) ([]responses.ToolUnionParam, map[string]spec.ToolChoice, error) {
@@ -1214,22 +1591,41 @@ func toolChoicesToOpenAIResponseTools(
 	ordered, nameMap := sdkutil.BuildToolChoiceNameMapping(toolChoices)
 	out := make([]responses.ToolUnionParam, 0, len(ordered))
 	webSearchAdded := false
+	imageGenerationAdded := false
+	codeInterpreterAdded := false
+	fileSearchAdded := false
+	computerUseAdded := false
 
 	for _, tw := range ordered {
 		tc := tw.Choice
 		name := tw.Name
 		switch tc.Type {
 		case spec.ToolTypeFunction, spec.ToolTypeCustom:
+			if sdkutil.IsFormattedCustomTool(tc) {
+				if name == "" {
+					continue
+				}
+				custom, err := customToolToOpenAIResponsesTool(name, tc)
+				if err != nil {
+					return nil, nil, err
+				}
+				out = append(out, custom)
+				continue
+			}
 			if tc.Arguments == nil || name == "" {
 				continue
 			}
-			// For now, both function and custom tools are expressed as function tools.
+			// Function tools, and custom tools without a format, are expressed as function tools.
 			fn := responses.FunctionToolParam{
 				Name:        name,
 				Parameters:  tc.Arguments,
 				Type:        openaiSharedConstant.Function("function"),
 				Description: param.NewOpt(sdkutil.ToolDescription(tc)),
 			}
+			if tc.Strict {
+				fn.Parameters = sdkutil.StrictJSONSchema(tc.Arguments)
+				fn.Strict = param.NewOpt(true)
+			}
 
 			out = append(out, responses.ToolUnionParam{OfFunction: &fn})
 
@@ -1266,6 +1662,50 @@ func toolChoicesToOpenAIResponseTools(
 			out = append(out, responses.ToolUnionParam{OfWebSearch: &fn})
 			webSearchAdded = true
 
+		case spec.ToolTypeImageGeneration:
+			if imageGenerationAdded {
+				// Like web search, image generation is a single built-in tool.
+				continue
+			}
+			out = append(out, imageGenerationToolParam(tc.ImageGenerationArguments))
+			imageGenerationAdded = true
+
+		case spec.ToolTypeCodeInterpreter:
+			if codeInterpreterAdded {
+				continue
+			}
+			out = append(out, codeInterpreterToolParam(tc.CodeInterpreterArguments))
+			codeInterpreterAdded = true
+
+		case spec.ToolTypeFileSearch:
+			if fileSearchAdded {
+				continue
+			}
+			if tc.FileSearchArguments == nil || len(tc.FileSearchArguments.VectorStoreIDs) == 0 {
+				return nil, nil, errors.New("openai responses: fileSearch tool requires vector store IDs")
+			}
+			out = append(out, fileSearchToolParam(tc.FileSearchArguments))
+			fileSearchAdded = true
+
+		case spec.ToolTypeComputerUse:
+			if computerUseAdded {
+				continue
+			}
+			args := tc.ComputerUseArguments
+			if args == nil || args.DisplayWidth <= 0 || args.DisplayHeight <= 0 {
+				return nil, nil, errors.New("openai responses: computerUse tool requires a display width and height")
+			}
+			out = append(out, computerUseToolParam(args))
+			computerUseAdded = true
+
+		case spec.ToolTypeMCP:
+			// Unlike the other hosted tools, each MCP server is a tool of its own.
+			if tc.MCPArguments == nil || strings.TrimSpace(tc.MCPArguments.ServerURL) == "" ||
+				strings.TrimSpace(mcpServerLabel(tc)) == "" {
+				return nil, nil, errors.New("openai responses: mcp tool requires a server URL and label")
+			}
+			out = append(out, mcpToolParam(tc))
+
 		default:
 			continue
 
@@ -1504,6 +1944,70 @@ func outputsFromOpenAIResponse(
 					WebSearchToolCall: &call,
 				},
 			)
+
+		case string(openaiSharedConstant.ImageGenerationCall("").Default()):
+			ct := item.AsImageGenerationCall()
+			choice, ok := imageGenerationChoice(toolChoiceNameMap)
+			if ct.ID == "" || !ok {
+				continue
+			}
+			outs = append(outs, spec.OutputUnion{
+				Kind:                      spec.OutputKindImageGenerationToolOutput,
+				ImageGenerationToolOutput: imageGenerationOutputFromOpenAI(ct, choice),
+			})
+
+		case string(openaiSharedConstant.CodeInterpreterCall("").Default()):
+			ct := item.AsCodeInterpreterCall()
+			if ct.ID == "" {
+				continue
+			}
+			choice := hostedToolChoice(toolChoiceNameMap, spec.ToolTypeCodeInterpreter)
+			outs = append(outs, spec.OutputUnion{
+				Kind:                    spec.OutputKindCodeInterpreterToolCall,
+				CodeInterpreterToolCall: codeInterpreterCallFromOpenAI(ct, choice),
+			})
+
+		case string(openaiSharedConstant.FileSearchCall("").Default()):
+			ct := item.AsFileSearchCall()
+			if ct.ID == "" {
+				continue
+			}
+			choice := hostedToolChoice(toolChoiceNameMap, spec.ToolTypeFileSearch)
+			outs = append(outs, spec.OutputUnion{
+				Kind:               spec.OutputKindFileSearchToolCall,
+				FileSearchToolCall: fileSearchCallFromOpenAI(ct, choice),
+			})
+
+		case string(responses.ResponseComputerToolCallTypeComputerCall):
+			ct := item.AsComputerCall()
+			if ct.ID == "" {
+				continue
+			}
+			choice := hostedToolChoice(toolChoiceNameMap, spec.ToolTypeComputerUse)
+			outs = append(outs, spec.OutputUnion{
+				Kind:             spec.OutputKindComputerToolCall,
+				ComputerToolCall: computerCallFromOpenAI(ct, choice),
+			})
+
+		case string(openaiSharedConstant.McpCall("").Default()):
+			ct := item.AsMcpCall()
+			if ct.ID == "" {
+				continue
+			}
+			outs = append(outs, spec.OutputUnion{
+				Kind:        spec.OutputKindMCPToolCall,
+				MCPToolCall: mcpCallFromOpenAI(ct, mcpChoice(toolChoiceNameMap, ct.ServerLabel)),
+			})
+
+		case string(openaiSharedConstant.McpListTools("").Default()):
+			ct := item.AsMcpListTools()
+			if ct.ID == "" {
+				continue
+			}
+			outs = append(outs, spec.OutputUnion{
+				Kind:         spec.OutputKindMCPListTools,
+				MCPListTools: mcpListToolsFromOpenAI(ct, mcpChoice(toolChoiceNameMap, ct.ServerLabel)),
+			})
 		}
 	}
 
@@ -1570,3 +2074,38 @@ func fromOpenAIStatus(status string) spec.Status {
 		return spec.Status(status)
 	}
 }
+
+// requestContextMetadata maps a spec.RequestContext to Responses request metadata.
+func requestContextMetadata(rc spec.RequestContext) shared.Metadata {
+	md := shared.Metadata{}
+	if rc.TenantID != "" {
+		md["tenant_id"] = rc.TenantID
+	}
+	if rc.TraceID != "" {
+		md["trace_id"] = rc.TraceID
+	}
+	if rc.FeatureTag != "" {
+		md["feature"] = rc.FeatureTag
+	}
+	if len(md) == 0 {
+		return nil
+	}
+	return md
+}
+
+// openAIResponsesOverlayDeny are the request fields AdditionalParametersRawJSON cannot set.
+var openAIResponsesOverlayDeny = []string{"model", "input", "instructions", "tools", "stream"}
+
+// applyOpenAIResponsesParamOverlay merges ModelParam.AdditionalParametersRawJSON into the request body.
+func applyOpenAIResponsesParamOverlay(params *responses.ResponseNewParams, raw *string) error {
+	overlay, err := sdkutil.ParseParamOverlay(raw, openAIResponsesOverlayDeny...)
+	if err != nil || overlay == nil {
+		return err
+	}
+	extra, err := sdkutil.OverlayFields(params, params.ExtraFields(), overlay)
+	if err != nil {
+		return err
+	}
+	params.SetExtraFields(extra)
+	return nil
+}
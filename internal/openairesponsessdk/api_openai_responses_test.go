@@ -0,0 +1,155 @@
+package openairesponsessdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// newTestAPI returns an initialized adapter for a server running handler.
+func newTestAPI(t *testing.T, handler http.HandlerFunc) *OpenAIResponsesAPI {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	api, err := NewOpenAIResponsesAPI(spec.ProviderParam{
+		Name:    "openai",
+		SDKType: spec.ProviderSDKTypeOpenAIResponses,
+		Origin:  srv.URL,
+		APIKey:  "k",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewOpenAIResponsesAPI() error = %v.", err)
+	}
+	if err := api.InitLLM(t.Context()); err != nil {
+		t.Fatalf("InitLLM() error = %v.", err)
+	}
+	return api
+}
+
+// userInputs returns a single user message holding text.
+func userInputs(text string) []spec.InputUnion {
+	return []spec.InputUnion{{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role: spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			}},
+		},
+	}}
+}
+
+func TestOpenAIResponsesForeignItemIDs(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	text := func(s string) []spec.InputOutputContentItemUnion {
+		return []spec.InputOutputContentItemUnion{{
+			Kind:     spec.ContentItemKindText,
+			TextItem: &spec.ContentItemText{Text: s},
+		}}
+	}
+	call := func(id, callID string) spec.InputUnion {
+		return spec.InputUnion{Kind: spec.InputKindFunctionToolCall, FunctionToolCall: &spec.ToolCall{
+			Type: spec.ToolTypeFunction, ChoiceID: "w", ID: id, CallID: callID, Name: "weather", Arguments: `{}`,
+		}}
+	}
+	output := func(id, callID string) spec.InputUnion {
+		return spec.InputUnion{Kind: spec.InputKindFunctionToolOutput, FunctionToolOutput: &spec.ToolOutput{
+			Type: spec.ToolTypeFunction, ChoiceID: "w", ID: id, CallID: callID, Name: "weather",
+			Contents: []spec.ToolOutputItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: "sunny"},
+			}},
+		}}
+	}
+	// A history replayed from Anthropic and another provider, followed by a native OpenAI turn.
+	inputs := []spec.InputUnion{
+		{
+			Kind:         spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{Role: spec.RoleUser, Contents: text("Weather?")},
+		},
+		{
+			Kind: spec.InputKindReasoningMessage,
+			ReasoningMessage: &spec.ReasoningContent{
+				Role: spec.RoleAssistant, Thinking: []string{"check"}, Signature: "sig",
+			},
+		},
+		{
+			Kind:          spec.InputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{Role: spec.RoleAssistant, Contents: text("Checking.")},
+		},
+		call("toolu_01abc", "toolu_01abc"),
+		output("toolu_01abc", "toolu_01abc"),
+		call("functions.weather:0", "functions.weather:0"),
+		output("", "functions.weather:0"),
+		{
+			Kind: spec.InputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{
+				ID: "msg_openai", Role: spec.RoleAssistant, Contents: text("Sunny."),
+			},
+		},
+		call("fc_openai", "call_openai"),
+		output("", "call_openai"),
+	}
+	resp, err := api.FetchCompletion(t.Context(), &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs:     inputs,
+	}, &spec.FetchCompletionOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+
+	dd, _ := resp.DebugDetails.(map[string]any)
+	req, _ := dd["request"].(map[string]any)
+	body, _ := req["body"].(map[string]any)
+	items, _ := body["input"].([]any)
+	var got []string
+	for _, it := range items {
+		item, _ := it.(map[string]any)
+		id, _ := item["id"].(string)
+		callID, _ := item["call_id"].(string)
+		typ, ok := item["type"]
+		if !ok {
+			// Input messages are sent without a type.
+			typ = item["role"]
+		}
+		got = append(got, fmt.Sprint(typ, " ", id, " ", callID))
+	}
+	if len(got) != 9 {
+		t.Fatalf("input = %q, want 9 items without the foreign reasoning item.", got)
+	}
+
+	regenerated := strings.Fields(got[4])
+	if len(regenerated) != 2 || !strings.HasPrefix(regenerated[1], "call_") {
+		t.Fatalf("input[4] = %q, want a regenerated call ID.", got[4])
+	}
+	msgID := strings.Fields(got[1])[1]
+	if !strings.HasPrefix(msgID, "msg_") {
+		t.Fatalf("input[1] = %q, want a regenerated message ID.", got[1])
+	}
+	want := []string{
+		"user  ",
+		"message " + msgID + " ",
+		"function_call  toolu_01abc",
+		"function_call_output  toolu_01abc",
+		"function_call  " + regenerated[1],
+		"function_call_output  " + regenerated[1],
+		"message msg_openai ",
+		"function_call fc_openai call_openai",
+		"function_call_output  call_openai",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("input = %q, want %q.", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package openairesponsessdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// GetResponse retrieves a stored response, e.g. one started with ModelParam.Background.
+func (api *OpenAIResponsesAPI) GetResponse(
+	ctx context.Context,
+	responseID string,
+) (*spec.FetchCompletionResponse, error) {
+	client, providerName, err := api.backgroundClient(responseID)
+	if err != nil {
+		return nil, err
+	}
+	oaiResp, err := client.Responses.Get(ctx, responseID, responses.ResponseGetParams{})
+	return backgroundResponse(providerName, oaiResp, err)
+}
+
+// CancelResponse cancels a queued or in-progress background response.
+func (api *OpenAIResponsesAPI) CancelResponse(
+	ctx context.Context,
+	responseID string,
+) (*spec.FetchCompletionResponse, error) {
+	client, providerName, err := api.backgroundClient(responseID)
+	if err != nil {
+		return nil, err
+	}
+	oaiResp, err := client.Responses.Cancel(ctx, responseID)
+	return backgroundResponse(providerName, oaiResp, err)
+}
+
+func (api *OpenAIResponsesAPI) backgroundClient(responseID string) (*openai.Client, spec.ProviderName, error) {
+	if strings.TrimSpace(responseID) == "" {
+		return nil, "", errors.New("openai responses api LLM: empty response id")
+	}
+	api.mu.RLock()
+	client := api.client
+	var providerName spec.ProviderName
+	if api.ProviderParam != nil {
+		providerName = api.ProviderParam.Name
+	}
+	api.mu.RUnlock()
+	if client == nil {
+		return nil, "", errors.New("openai responses api LLM: client not initialized")
+	}
+	return client, providerName, nil
+}
+
+func backgroundResponse(
+	providerName spec.ProviderName,
+	oaiResp *responses.Response,
+	err error,
+) (*spec.FetchCompletionResponse, error) {
+	resp := &spec.FetchCompletionResponse{Usage: usageFromOpenAIResponse(oaiResp)}
+	if err != nil {
+		resp.Error = providerError(providerName, err, "", "")
+		return sdkutil.FinalizeResponse(providerName, resp, err), err
+	}
+	resp.Outputs = outputsFromOpenAIResponse(oaiResp, nil)
+	resp.Metadata = responseMetadata(oaiResp)
+	return resp, nil
+}
+
+// responseMetadata records the ID and status of oaiResp, so background responses can be polled.
+func responseMetadata(oaiResp *responses.Response) *spec.ResponseMetadata {
+	md := &spec.ResponseMetadata{}
+	if oaiResp != nil {
+		md.ResponseID = oaiResp.ID
+		md.Status = fromOpenAIStatus(string(oaiResp.Status))
+	}
+	return md
+}
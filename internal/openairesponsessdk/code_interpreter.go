@@ -0,0 +1,99 @@
+package openairesponsessdk
+
+import (
+	"strings"
+
+	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const codeInterpreterToolName = "code_interpreter"
+
+func codeInterpreterToolParam(args *spec.CodeInterpreterToolChoiceItem) responses.ToolUnionParam {
+	tool := responses.ToolCodeInterpreterParam{}
+	if args != nil && strings.TrimSpace(args.ContainerID) != "" {
+		tool.Container.OfString = param.NewOpt(args.ContainerID)
+	} else {
+		auto := &responses.ToolCodeInterpreterContainerCodeInterpreterContainerAutoParam{}
+		if args != nil {
+			auto.FileIDs = args.FileIDs
+			auto.MemoryLimit = args.MemoryLimit
+		}
+		tool.Container.OfCodeInterpreterToolAuto = auto
+	}
+	return responses.ToolUnionParam{OfCodeInterpreter: &tool}
+}
+
+// hostedToolChoice returns the first tool choice of type t.
+func hostedToolChoice(toolChoiceNameMap map[string]spec.ToolChoice, t spec.ToolType) spec.ToolChoice {
+	for _, tc := range toolChoiceNameMap {
+		if tc.Type == t {
+			return tc
+		}
+	}
+	return spec.ToolChoice{}
+}
+
+// codeInterpreterCallFromOpenAI converts a code_interpreter_call item, including its outputs, into a ToolCall.
+func codeInterpreterCallFromOpenAI(
+	item responses.ResponseCodeInterpreterToolCall,
+	choice spec.ToolChoice,
+) *spec.ToolCall {
+	ci := &spec.CodeInterpreterToolCallItem{Code: item.Code, ContainerID: item.ContainerID}
+	for _, o := range item.Outputs {
+		switch o.Type {
+		case "logs":
+			ci.Outputs = append(ci.Outputs, spec.CodeInterpreterOutput{
+				Kind: spec.CodeInterpreterOutputKindLogs,
+				Logs: o.Logs,
+			})
+		case "image":
+			ci.Outputs = append(ci.Outputs, spec.CodeInterpreterOutput{
+				Kind:     spec.CodeInterpreterOutputKindImage,
+				ImageURL: o.URL,
+			})
+		}
+	}
+	return &spec.ToolCall{
+		Type:                        spec.ToolTypeCodeInterpreter,
+		ChoiceID:                    choice.ID,
+		ID:                          item.ID,
+		CallID:                      item.ID,
+		Role:                        spec.RoleAssistant,
+		Name:                        codeInterpreterToolName,
+		Status:                      fromOpenAIStatus(string(item.Status)),
+		CodeInterpreterToolCallItem: ci,
+	}
+}
+
+// codeInterpreterCallToOpenAI converts a code interpreter call back into a code_interpreter_call input item.
+func codeInterpreterCallToOpenAI(call *spec.ToolCall) *responses.ResponseInputItemUnionParam {
+	if call == nil || call.CodeInterpreterToolCallItem == nil || strings.TrimSpace(call.ID) == "" {
+		return nil
+	}
+	ci := call.CodeInterpreterToolCallItem
+	item := &responses.ResponseCodeInterpreterToolCallParam{
+		ID:          call.ID,
+		ContainerID: ci.ContainerID,
+		Code:        param.NewOpt(ci.Code),
+		Status:      responses.ResponseCodeInterpreterToolCallStatus(toOpenAIStatus(call.Status)),
+	}
+	if item.Status == "" {
+		item.Status = responses.ResponseCodeInterpreterToolCallStatusCompleted
+	}
+	for _, o := range ci.Outputs {
+		switch o.Kind {
+		case spec.CodeInterpreterOutputKindLogs:
+			item.Outputs = append(item.Outputs, responses.ResponseCodeInterpreterToolCallOutputUnionParam{
+				OfLogs: &responses.ResponseCodeInterpreterToolCallOutputLogsParam{Logs: o.Logs},
+			})
+		case spec.CodeInterpreterOutputKindImage:
+			item.Outputs = append(item.Outputs, responses.ResponseCodeInterpreterToolCallOutputUnionParam{
+				OfImage: &responses.ResponseCodeInterpreterToolCallOutputImageParam{URL: o.ImageURL},
+			})
+		}
+	}
+	return &responses.ResponseInputItemUnionParam{OfCodeInterpreterCall: item}
+}
@@ -0,0 +1,176 @@
+package openairesponsessdk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const computerUseToolName = "computer_use_preview"
+
+func computerUseToolParam(args *spec.ComputerUseToolChoiceItem) responses.ToolUnionParam {
+	env := responses.ComputerToolEnvironment(strings.TrimSpace(args.Environment))
+	if env == "" {
+		env = responses.ComputerToolEnvironmentBrowser
+	}
+	return responses.ToolParamOfComputerUsePreview(args.DisplayHeight, args.DisplayWidth, env)
+}
+
+// computerCallFromOpenAI converts a computer_call item into a ToolCall carrying the action to perform.
+func computerCallFromOpenAI(item responses.ResponseComputerToolCall, choice spec.ToolChoice) *spec.ToolCall {
+	a := item.Action
+	action := &spec.ComputerAction{
+		Kind:    computerActionKindFromOpenAI(a.Type),
+		Button:  a.Button,
+		X:       a.X,
+		Y:       a.Y,
+		Keys:    a.Keys,
+		ScrollX: a.ScrollX,
+		ScrollY: a.ScrollY,
+		Text:    a.Text,
+	}
+	for _, p := range a.Path {
+		action.Path = append(action.Path, spec.ComputerActionPoint{X: p.X, Y: p.Y})
+	}
+	var checks []spec.ComputerSafetyCheck
+	for _, c := range item.PendingSafetyChecks {
+		checks = append(checks, spec.ComputerSafetyCheck{ID: c.ID, Code: c.Code, Message: c.Message})
+	}
+	return &spec.ToolCall{
+		Type:                spec.ToolTypeComputerUse,
+		ChoiceID:            choice.ID,
+		ID:                  item.ID,
+		CallID:              item.CallID,
+		Role:                spec.RoleAssistant,
+		Name:                computerUseToolName,
+		Status:              fromOpenAIStatus(string(item.Status)),
+		ComputerAction:      action,
+		PendingSafetyChecks: checks,
+	}
+}
+
+func computerActionKindFromOpenAI(t string) spec.ComputerActionKind {
+	switch t {
+	case "double_click":
+		return spec.ComputerActionKindDoubleClick
+	default:
+		return spec.ComputerActionKind(t)
+	}
+}
+
+// computerCallToOpenAI converts a computer call back into a computer_call input item.
+func computerCallToOpenAI(call *spec.ToolCall) *responses.ResponseInputItemUnionParam {
+	if call == nil || call.ComputerAction == nil || strings.TrimSpace(call.ID) == "" {
+		return nil
+	}
+	a := call.ComputerAction
+	var action responses.ResponseComputerToolCallActionUnionParam
+	switch a.Kind {
+	case spec.ComputerActionKindClick:
+		action.OfClick = &responses.ResponseComputerToolCallActionClickParam{Button: a.Button, X: a.X, Y: a.Y}
+	case spec.ComputerActionKindDoubleClick:
+		action.OfDoubleClick = &responses.ResponseComputerToolCallActionDoubleClickParam{X: a.X, Y: a.Y}
+	case spec.ComputerActionKindDrag:
+		drag := &responses.ResponseComputerToolCallActionDragParam{}
+		for _, p := range a.Path {
+			drag.Path = append(drag.Path, responses.ResponseComputerToolCallActionDragPathParam{X: p.X, Y: p.Y})
+		}
+		action.OfDrag = drag
+	case spec.ComputerActionKindKeypress:
+		action.OfKeypress = &responses.ResponseComputerToolCallActionKeypressParam{Keys: a.Keys}
+	case spec.ComputerActionKindMove:
+		action.OfMove = &responses.ResponseComputerToolCallActionMoveParam{X: a.X, Y: a.Y}
+	case spec.ComputerActionKindScreenshot:
+		action.OfScreenshot = &responses.ResponseComputerToolCallActionScreenshotParam{}
+	case spec.ComputerActionKindScroll:
+		action.OfScroll = &responses.ResponseComputerToolCallActionScrollParam{
+			ScrollX: a.ScrollX,
+			ScrollY: a.ScrollY,
+			X:       a.X,
+			Y:       a.Y,
+		}
+	case spec.ComputerActionKindType:
+		action.OfType = &responses.ResponseComputerToolCallActionTypeParam{Text: a.Text}
+	case spec.ComputerActionKindWait:
+		action.OfWait = &responses.ResponseComputerToolCallActionWaitParam{}
+	default:
+		return nil
+	}
+	item := &responses.ResponseComputerToolCallParam{
+		ID:                  call.ID,
+		CallID:              call.CallID,
+		Action:              action,
+		PendingSafetyChecks: []responses.ResponseComputerToolCallPendingSafetyCheckParam{},
+		Status:              responses.ResponseComputerToolCallStatus(toOpenAIStatus(call.Status)),
+		Type:                responses.ResponseComputerToolCallTypeComputerCall,
+	}
+	if item.Status == "" {
+		item.Status = responses.ResponseComputerToolCallStatusCompleted
+	}
+	for _, c := range call.PendingSafetyChecks {
+		item.PendingSafetyChecks = append(
+			item.PendingSafetyChecks,
+			responses.ResponseComputerToolCallPendingSafetyCheckParam{
+				ID:      c.ID,
+				Code:    optString(c.Code),
+				Message: optString(c.Message),
+			},
+		)
+	}
+	return &responses.ResponseInputItemUnionParam{OfComputerCall: item}
+}
+
+// computerOutputToOpenAI converts a computer tool output into a computer_call_output item. The screenshot is the
+// first image (by data or URL) or file (by provider file ID) in Contents.
+func computerOutputToOpenAI(out *spec.ToolOutput) (*responses.ResponseInputItemUnionParam, error) {
+	if out == nil || strings.TrimSpace(out.CallID) == "" {
+		return nil, nil
+	}
+	var shot responses.ResponseComputerToolCallOutputScreenshotParam
+	for _, c := range out.Contents {
+		if img := c.ImageItem; c.Kind == spec.ContentItemKindImage && img != nil {
+			if data := strings.TrimSpace(img.ImageData); data != "" {
+				mime := strings.TrimSpace(img.ImageMIME)
+				if mime == "" {
+					mime = spec.DefaultImageDataMIME
+				}
+				shot.ImageURL = param.NewOpt(fmt.Sprintf("data:%s;base64,%s", mime, data))
+				break
+			}
+			if u := strings.TrimSpace(img.ImageURL); u != "" {
+				shot.ImageURL = param.NewOpt(u)
+				break
+			}
+		}
+		if f := c.FileItem; c.Kind == spec.ContentItemKindFile && f != nil && strings.TrimSpace(f.FileID) != "" {
+			shot.FileID = param.NewOpt(f.FileID)
+			break
+		}
+	}
+	if !shot.ImageURL.Valid() && !shot.FileID.Valid() {
+		return nil, fmt.Errorf("openai responses: computer tool output %q has no screenshot", out.CallID)
+	}
+	item := responses.ResponseInputItemParamOfComputerCallOutput(out.CallID, shot)
+	for _, c := range out.AcknowledgedSafetyChecks {
+		item.OfComputerCallOutput.AcknowledgedSafetyChecks = append(
+			item.OfComputerCallOutput.AcknowledgedSafetyChecks,
+			responses.ResponseInputItemComputerCallOutputAcknowledgedSafetyCheckParam{
+				ID:      c.ID,
+				Code:    optString(c.Code),
+				Message: optString(c.Message),
+			},
+		)
+	}
+	return &item, nil
+}
+
+func optString(s string) param.Opt[string] {
+	if s == "" {
+		return param.Opt[string]{}
+	}
+	return param.NewOpt(s)
+}
@@ -0,0 +1,70 @@
+package openairesponsessdk
+
+import (
+	"strings"
+
+	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const fileSearchToolName = "file_search"
+
+func fileSearchToolParam(args *spec.FileSearchToolChoiceItem) responses.ToolUnionParam {
+	tool := responses.FileSearchToolParam{VectorStoreIDs: args.VectorStoreIDs}
+	if args.MaxNumResults > 0 {
+		tool.MaxNumResults = param.NewOpt(args.MaxNumResults)
+	}
+	return responses.ToolUnionParam{OfFileSearch: &tool}
+}
+
+// fileSearchCallFromOpenAI converts a file_search_call item, including its results, into a ToolCall.
+func fileSearchCallFromOpenAI(item responses.ResponseFileSearchToolCall, choice spec.ToolChoice) *spec.ToolCall {
+	fs := &spec.FileSearchToolCallItem{Queries: item.Queries}
+	for _, r := range item.Results {
+		fs.Results = append(fs.Results, spec.FileSearchResult{
+			FileID:   r.FileID,
+			FileName: r.Filename,
+			Score:    r.Score,
+			Text:     r.Text,
+		})
+	}
+	return &spec.ToolCall{
+		Type:                   spec.ToolTypeFileSearch,
+		ChoiceID:               choice.ID,
+		ID:                     item.ID,
+		CallID:                 item.ID,
+		Role:                   spec.RoleAssistant,
+		Name:                   fileSearchToolName,
+		Status:                 fromOpenAIStatus(string(item.Status)),
+		FileSearchToolCallItem: fs,
+	}
+}
+
+// fileSearchCallToOpenAI converts a file search call back into a file_search_call input item.
+func fileSearchCallToOpenAI(call *spec.ToolCall) *responses.ResponseInputItemUnionParam {
+	if call == nil || call.FileSearchToolCallItem == nil || strings.TrimSpace(call.ID) == "" {
+		return nil
+	}
+	fs := call.FileSearchToolCallItem
+	status := responses.ResponseFileSearchToolCallStatus(toOpenAIStatus(call.Status))
+	if status == "" {
+		status = responses.ResponseFileSearchToolCallStatusCompleted
+	}
+	item := responses.ResponseInputItemParamOfFileSearchCall(call.ID, fs.Queries, status)
+	for _, r := range fs.Results {
+		res := responses.ResponseFileSearchToolCallResultParam{FileID: param.NewOpt(r.FileID)}
+		if r.FileName != "" {
+			res.Filename = param.NewOpt(r.FileName)
+		}
+		if r.Score != 0 {
+			res.Score = param.NewOpt(r.Score)
+		}
+		if r.Text != "" {
+			res.Text = param.NewOpt(r.Text)
+		}
+		item.OfFileSearchCall.Results = append(item.OfFileSearchCall.Results, res)
+	}
+	return &item
+}
@@ -0,0 +1,318 @@
+package openairesponsessdk
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestOpenAIResponsesCodeInterpreterAndFileSearch(t *testing.T) {
+	t.Parallel()
+
+	const (
+		ci = `{"type":"code_interpreter_call","id":"ci1","status":"completed","container_id":"cntr_1",` +
+			`"code":"print(1+1)","outputs":[{"type":"logs","logs":"2\n"},{"type":"image","url":"https://x/p.png"}]}`
+		fs = `{"type":"file_search_call","id":"fs1","status":"completed","queries":["refund policy"],` +
+			`"results":[{"file_id":"file_1","filename":"policy.pdf","score":0.9,"text":"30 days"}]}`
+		text = `{"type":"message","id":"m1","role":"assistant","status":"completed",` +
+			`"content":[{"type":"output_text","text":"done","annotations":[]}]}`
+	)
+	response := `{"id":"r1","object":"response","created_at":0,"model":"m","status":"completed",` +
+		`"output":[` + ci + `,` + fs + `,` + text + `]}`
+
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	})
+	lastBody := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return bodies[len(bodies)-1]
+	}
+
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs:     userInputs("check the policy and compute"),
+		ToolChoices: []spec.ToolChoice{
+			{
+				Type: spec.ToolTypeCodeInterpreter, ID: "code", Name: "code_interpreter",
+				CodeInterpreterArguments: &spec.CodeInterpreterToolChoiceItem{FileIDs: []string{"file_1"}},
+			},
+			{
+				Type: spec.ToolTypeFileSearch, ID: "docs", Name: "file_search",
+				FileSearchArguments: &spec.FileSearchToolChoiceItem{VectorStoreIDs: []string{"vs_1"}, MaxNumResults: 5},
+			},
+		},
+	}
+	resp, err := api.FetchCompletion(t.Context(), req, nil)
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	body := lastBody()
+	for _, want := range []string{
+		`"container":{"file_ids":["file_1"],"type":"auto"}`,
+		`"type":"code_interpreter"`,
+		`"vector_store_ids":["vs_1"]`,
+		`"max_num_results":5`,
+		`"code_interpreter_call.outputs"`,
+		`"file_search_call.results"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("request body = %s, want it to contain %s.", body, want)
+		}
+	}
+
+	if len(resp.Outputs) != 3 {
+		t.Fatalf("Outputs = %+v, want code interpreter, file search and text.", resp.Outputs)
+	}
+	wantCI := &spec.ToolCall{
+		Type: spec.ToolTypeCodeInterpreter, ChoiceID: "code", ID: "ci1", CallID: "ci1", Role: spec.RoleAssistant,
+		Name: "code_interpreter", Status: spec.StatusCompleted,
+		CodeInterpreterToolCallItem: &spec.CodeInterpreterToolCallItem{
+			Code: "print(1+1)", ContainerID: "cntr_1",
+			Outputs: []spec.CodeInterpreterOutput{
+				{Kind: spec.CodeInterpreterOutputKindLogs, Logs: "2\n"},
+				{Kind: spec.CodeInterpreterOutputKindImage, ImageURL: "https://x/p.png"},
+			},
+		},
+	}
+	if got := resp.Outputs[0].CodeInterpreterToolCall; !reflect.DeepEqual(got, wantCI) {
+		t.Fatalf("CodeInterpreterToolCall = %+v, want = %+v.", got, wantCI)
+	}
+	wantFS := &spec.ToolCall{
+		Type: spec.ToolTypeFileSearch, ChoiceID: "docs", ID: "fs1", CallID: "fs1", Role: spec.RoleAssistant,
+		Name: "file_search", Status: spec.StatusCompleted,
+		FileSearchToolCallItem: &spec.FileSearchToolCallItem{
+			Queries: []string{"refund policy"},
+			Results: []spec.FileSearchResult{{FileID: "file_1", FileName: "policy.pdf", Score: 0.9, Text: "30 days"}},
+		},
+	}
+	if got := resp.Outputs[1].FileSearchToolCall; !reflect.DeepEqual(got, wantFS) {
+		t.Fatalf("FileSearchToolCall = %+v, want = %+v.", got, wantFS)
+	}
+
+	// Replaying the calls sends them back as code_interpreter_call and file_search_call items.
+	req.Inputs = append(req.Inputs,
+		spec.InputUnion{Kind: spec.InputKindCodeInterpreterToolCall, CodeInterpreterToolCall: wantCI},
+		spec.InputUnion{Kind: spec.InputKindFileSearchToolCall, FileSearchToolCall: wantFS},
+	)
+	if _, err := api.FetchCompletion(t.Context(), req, nil); err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	body = lastBody()
+	for _, want := range []string{
+		`"id":"ci1"`, `"type":"code_interpreter_call"`, `"logs":"2\n"`,
+		`"id":"fs1"`, `"type":"file_search_call"`, `"queries":["refund policy"]`, `"text":"30 days"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("request body = %s, want it to contain %s.", body, want)
+		}
+	}
+
+	req.ToolChoices = []spec.ToolChoice{{Type: spec.ToolTypeFileSearch, ID: "docs", Name: "file_search"}}
+	if _, err := api.FetchCompletion(t.Context(), req, nil); err == nil {
+		t.Fatalf("FetchCompletion() error = nil, want an error for file search without vector stores.")
+	}
+}
+
+func TestOpenAIResponsesComputerUse(t *testing.T) {
+	t.Parallel()
+
+	const call = `{"type":"computer_call","id":"cu1","call_id":"call_1","status":"completed",` +
+		`"action":{"type":"click","button":"left","x":10,"y":20},` +
+		`"pending_safety_checks":[{"id":"sc1","code":"malicious_instructions","message":"check"}]}`
+	var (
+		mu   sync.Mutex
+		body string
+	)
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		body = string(b)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"r1","object":"response","created_at":0,"model":"m","status":"completed",` +
+			`"output":[` + call + `]}`))
+	})
+	lastBody := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return body
+	}
+
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs:     userInputs("open the settings"),
+		ToolChoices: []spec.ToolChoice{{
+			Type: spec.ToolTypeComputerUse, ID: "pc", Name: "computer_use_preview",
+			ComputerUseArguments: &spec.ComputerUseToolChoiceItem{DisplayWidth: 1024, DisplayHeight: 768},
+		}},
+	}
+	resp, err := api.FetchCompletion(t.Context(), req, nil)
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	got := lastBody()
+	for _, want := range []string{
+		`"type":"computer_use_preview"`, `"display_width":1024`, `"display_height":768`, `"environment":"browser"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("request body = %s, want it to contain %s.", got, want)
+		}
+	}
+
+	wantCall := &spec.ToolCall{
+		Type: spec.ToolTypeComputerUse, ChoiceID: "pc", ID: "cu1", CallID: "call_1", Role: spec.RoleAssistant,
+		Name: "computer_use_preview", Status: spec.StatusCompleted,
+		ComputerAction: &spec.ComputerAction{Kind: spec.ComputerActionKindClick, Button: "left", X: 10, Y: 20},
+		PendingSafetyChecks: []spec.ComputerSafetyCheck{
+			{ID: "sc1", Code: "malicious_instructions", Message: "check"},
+		},
+	}
+	if len(resp.Outputs) != 1 || resp.Outputs[0].Kind != spec.OutputKindComputerToolCall ||
+		!reflect.DeepEqual(resp.Outputs[0].ComputerToolCall, wantCall) {
+		t.Fatalf("Outputs = %+v, want the computer call %+v.", resp.Outputs, wantCall)
+	}
+
+	// Answering the call replays it and sends the screenshot with the acknowledged safety checks.
+	req.Inputs = append(req.Inputs,
+		spec.InputUnion{Kind: spec.InputKindComputerToolCall, ComputerToolCall: wantCall},
+		spec.InputUnion{Kind: spec.InputKindComputerToolOutput, ComputerToolOutput: &spec.ToolOutput{
+			Type: spec.ToolTypeComputerUse, CallID: "call_1", Name: "computer_use_preview",
+			Contents: []spec.ToolOutputItemUnion{{
+				Kind:      spec.ContentItemKindImage,
+				ImageItem: &spec.ContentItemImage{ImageMIME: "image/png", ImageData: "aGk="},
+			}},
+			AcknowledgedSafetyChecks: wantCall.PendingSafetyChecks,
+		}},
+	)
+	if _, err := api.FetchCompletion(t.Context(), req, nil); err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	got = lastBody()
+	for _, want := range []string{
+		`"type":"computer_call"`, `"call_id":"call_1"`, `"button":"left"`,
+		`"type":"computer_call_output"`, `"image_url":"data:image/png;base64,aGk="`,
+		`"acknowledged_safety_checks":[{"id":"sc1"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("request body = %s, want it to contain %s.", got, want)
+		}
+	}
+
+	req.ToolChoices = []spec.ToolChoice{{Type: spec.ToolTypeComputerUse, ID: "pc", Name: "computer_use_preview"}}
+	if _, err := api.FetchCompletion(t.Context(), req, nil); err == nil {
+		t.Fatalf("FetchCompletion() without a display size succeeded, want an error.")
+	}
+}
+
+func TestOpenAIResponsesMCP(t *testing.T) {
+	t.Parallel()
+
+	const (
+		list = `{"type":"mcp_list_tools","id":"ml1","server_label":"docs",` +
+			`"tools":[{"name":"search","description":"Search docs","input_schema":{"type":"object"}}]}`
+		call = `{"type":"mcp_call","id":"mc1","server_label":"docs","name":"search",` +
+			`"arguments":"{\"q\":\"mcp\"}","output":"3 hits","status":"completed"}`
+	)
+	var (
+		mu   sync.Mutex
+		body string
+	)
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		body = string(b)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"r1","object":"response","created_at":0,"model":"m","status":"completed",` +
+			`"output":[` + list + `,` + call + `]}`))
+	})
+	lastBody := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return body
+	}
+
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs:     userInputs("search the docs for mcp"),
+		ToolChoices: []spec.ToolChoice{{
+			Type: spec.ToolTypeMCP, ID: "docs-server", Name: "docs",
+			MCPArguments: &spec.MCPToolChoiceItem{
+				ServerURL:       "https://mcp.example.com",
+				AllowedTools:    []string{"search"},
+				RequireApproval: "never",
+			},
+		}},
+	}
+	resp, err := api.FetchCompletion(t.Context(), req, nil)
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	got := lastBody()
+	for _, want := range []string{
+		`"type":"mcp"`, `"server_label":"docs"`, `"server_url":"https://mcp.example.com"`,
+		`"allowed_tools":["search"]`, `"require_approval":"never"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("request body = %s, want it to contain %s.", got, want)
+		}
+	}
+
+	wantList := &spec.ToolCall{
+		Type: spec.ToolTypeMCP, ChoiceID: "docs-server", ID: "ml1", CallID: "ml1", Role: spec.RoleAssistant,
+		Name: "mcp_list_tools", Status: spec.StatusCompleted,
+		MCPToolCallItem: &spec.MCPToolCallItem{
+			ServerLabel: "docs",
+			Tools: []spec.MCPToolInfo{{
+				Name: "search", Description: "Search docs", InputSchema: map[string]any{"type": "object"},
+			}},
+		},
+	}
+	wantCall := &spec.ToolCall{
+		Type: spec.ToolTypeMCP, ChoiceID: "docs-server", ID: "mc1", CallID: "mc1", Role: spec.RoleAssistant,
+		Name: "search", Arguments: `{"q":"mcp"}`, Status: spec.StatusCompleted,
+		MCPToolCallItem: &spec.MCPToolCallItem{ServerLabel: "docs", Output: "3 hits"},
+	}
+	if len(resp.Outputs) != 2 || !reflect.DeepEqual(resp.Outputs[0].MCPListTools, wantList) ||
+		!reflect.DeepEqual(resp.Outputs[1].MCPToolCall, wantCall) {
+		t.Fatalf("Outputs = %+v, want the tool listing %+v and the call %+v.", resp.Outputs, wantList, wantCall)
+	}
+
+	// Replaying sends the listing and the call back as mcp_list_tools and mcp_call items.
+	req.Inputs = append(req.Inputs,
+		spec.InputUnion{Kind: spec.InputKindMCPListTools, MCPListTools: wantList},
+		spec.InputUnion{Kind: spec.InputKindMCPToolCall, MCPToolCall: wantCall},
+	)
+	if _, err := api.FetchCompletion(t.Context(), req, nil); err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	got = lastBody()
+	for _, want := range []string{
+		`"type":"mcp_list_tools"`, `"description":"Search docs"`,
+		`"type":"mcp_call"`, `"id":"mc1"`, `"output":"3 hits"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("request body = %s, want it to contain %s.", got, want)
+		}
+	}
+
+	req.ToolChoices = []spec.ToolChoice{{Type: spec.ToolTypeMCP, ID: "docs-server", Name: "docs"}}
+	if _, err := api.FetchCompletion(t.Context(), req, nil); err == nil {
+		t.Fatalf("FetchCompletion() without a server URL succeeded, want an error.")
+	}
+}
@@ -0,0 +1,120 @@
+package openairesponsessdk
+
+import (
+	"strings"
+
+	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const imageGenerationToolName = "image_generation"
+
+func imageGenerationToolParam(args *spec.ImageGenerationToolChoiceItem) responses.ToolUnionParam {
+	tool := responses.ToolImageGenerationParam{}
+	if args != nil {
+		tool.Size = args.Size
+		tool.Quality = args.Quality
+		tool.Background = args.Background
+		tool.OutputFormat = args.OutputFormat
+		if args.PartialImages > 0 {
+			tool.PartialImages = param.NewOpt(args.PartialImages)
+		}
+	}
+	return responses.ToolUnionParam{OfImageGeneration: &tool}
+}
+
+// imageGenerationChoice returns the first image generation tool choice.
+func imageGenerationChoice(toolChoiceNameMap map[string]spec.ToolChoice) (spec.ToolChoice, bool) {
+	for _, tc := range toolChoiceNameMap {
+		if tc.Type == spec.ToolTypeImageGeneration {
+			return tc, true
+		}
+	}
+	return spec.ToolChoice{}, false
+}
+
+// imageMIME returns the MIME type of the images generated by choice. The API does not report the format of an image,
+// so it is derived from the requested output format, which defaults to png.
+func imageMIME(choice spec.ToolChoice) string {
+	format := ""
+	if choice.ImageGenerationArguments != nil {
+		format = strings.ToLower(choice.ImageGenerationArguments.OutputFormat)
+	}
+	switch format {
+	case "jpeg", "jpg":
+		return "image/jpeg"
+	case "webp":
+		return "image/webp"
+	default:
+		return "image/png"
+	}
+}
+
+// imageGenerationOutputFromOpenAI converts an image_generation_call item into a ToolOutput holding the image.
+func imageGenerationOutputFromOpenAI(
+	item responses.ResponseOutputItemImageGenerationCall,
+	choice spec.ToolChoice,
+) *spec.ToolOutput {
+	out := &spec.ToolOutput{
+		ChoiceID: choice.ID,
+		Type:     spec.ToolTypeImageGeneration,
+		Role:     spec.RoleAssistant,
+		ID:       item.ID,
+		Name:     imageGenerationToolName,
+		Status:   fromOpenAIStatus(item.Status),
+		IsError:  item.Status == "failed",
+	}
+	if item.Result != "" {
+		out.Contents = []spec.ToolOutputItemUnion{{
+			Kind:      spec.ContentItemKindImage,
+			ImageItem: &spec.ContentItemImage{ImageMIME: imageMIME(choice), ImageData: item.Result},
+		}}
+	}
+	return out
+}
+
+// imageGenerationOutputToOpenAI converts a generated image back into an image_generation_call input item.
+func imageGenerationOutputToOpenAI(out *spec.ToolOutput) *responses.ResponseInputItemUnionParam {
+	if out == nil || strings.TrimSpace(out.ID) == "" {
+		return nil
+	}
+	var result string
+	for _, c := range out.Contents {
+		if c.Kind == spec.ContentItemKindImage && c.ImageItem != nil && c.ImageItem.ImageData != "" {
+			result = c.ImageItem.ImageData
+			break
+		}
+	}
+	status := toOpenAIStatus(out.Status)
+	if status == "" {
+		status = "completed"
+	}
+	item := responses.ResponseInputItemParamOfImageGenerationCall(out.ID, result, status)
+	if result == "" {
+		item.OfImageGenerationCall.Result = param.Null[string]()
+	}
+	return &item
+}
+
+// imagePartialEventFromOpenAIStreamEvent maps a partial image event to a stream event.
+func imagePartialEventFromOpenAIStreamEvent(
+	chunk *responses.ResponseStreamEventUnion,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) (spec.StreamEvent, bool) {
+	if chunk.Type != "response.image_generation_call.partial_image" {
+		return spec.StreamEvent{}, false
+	}
+	choice, _ := imageGenerationChoice(toolChoiceNameMap)
+	return spec.StreamEvent{
+		Kind:            spec.StreamContentKindImagePartial,
+		OutputItemIndex: int(chunk.OutputIndex),
+		ImagePartial: &spec.StreamImagePartialChunk{
+			ItemID:            chunk.ItemID,
+			PartialImageIndex: int(chunk.PartialImageIndex),
+			ImageData:         chunk.PartialImageB64,
+			ImageMIME:         imageMIME(choice),
+		},
+	}, true
+}
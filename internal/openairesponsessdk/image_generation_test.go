@@ -0,0 +1,136 @@
+package openairesponsessdk
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestOpenAIResponsesImageGeneration(t *testing.T) {
+	t.Parallel()
+
+	const (
+		item = `{"type":"image_generation_call","id":"ig1","status":"completed","result":"RklOQUw="}`
+		text = `{"type":"message","id":"m1","role":"assistant","status":"completed",` +
+			`"content":[{"type":"output_text","text":"here","annotations":[]}]}`
+	)
+	response := `{"id":"r1","object":"response","created_at":0,"model":"m","status":"completed",` +
+		`"output":[` + item + `,` + text + `]}`
+	sse := func(data string) string { return "data: " + data + "\n\n" }
+	stream := sse(`{"type":"response.output_item.added","sequence_number":1,"output_index":0,`+
+		`"item":{"type":"image_generation_call","id":"ig1","status":"in_progress"}}`) +
+		sse(`{"type":"response.image_generation_call.partial_image","sequence_number":2,"output_index":0,`+
+			`"item_id":"ig1","partial_image_index":0,"partial_image_b64":"UDA="}`) +
+		sse(`{"type":"response.image_generation_call.partial_image","sequence_number":3,"output_index":0,`+
+			`"item_id":"ig1","partial_image_index":1,"partial_image_b64":"UDE="}`) +
+		sse(`{"type":"response.output_item.done","sequence_number":4,"output_index":0,"item":`+item+`}`) +
+		sse(`{"type":"response.completed","sequence_number":5,"response":`+response+`}`)
+
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+		if strings.Contains(string(b), `"stream":true`) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(stream))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	})
+
+	wantOutput := &spec.ToolOutput{
+		ChoiceID: "img", Type: spec.ToolTypeImageGeneration, Role: spec.RoleAssistant, ID: "ig1",
+		Status: spec.StatusCompleted, Name: "image_generation",
+		Contents: []spec.ToolOutputItemUnion{{
+			Kind:      spec.ContentItemKindImage,
+			ImageItem: &spec.ContentItemImage{ImageMIME: "image/webp", ImageData: "RklOQUw="},
+		}},
+	}
+	tests := []struct {
+		name        string
+		stream      bool
+		wantPartial []string
+	}{
+		{name: "NonStreaming."},
+		{name: "Streaming.", stream: true, wantPartial: []string{"UDA=", "UDE="}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m", Stream: tc.stream},
+				Inputs:     userInputs("draw a cat"),
+				ToolChoices: []spec.ToolChoice{{
+					Type: spec.ToolTypeImageGeneration, ID: "img", Name: "image_generation",
+					ImageGenerationArguments: &spec.ImageGenerationToolChoiceItem{
+						Size: "1024x1024", Quality: "low", OutputFormat: "webp", PartialImages: 2,
+					},
+				}},
+			}
+			var partials []string
+			var opts *spec.FetchCompletionOptions
+			if tc.stream {
+				opts = &spec.FetchCompletionOptions{StreamHandler: func(e spec.StreamEvent) error {
+					if e.Kind == spec.StreamContentKindImagePartial {
+						if e.ImagePartial.ImageMIME != "image/webp" || e.ImagePartial.ItemID != "ig1" {
+							t.Errorf("ImagePartial = %+v, want a webp partial of ig1.", e.ImagePartial)
+						}
+						partials = append(partials, e.ImagePartial.ImageData)
+					}
+					return nil
+				}}
+			}
+			resp, err := api.FetchCompletion(t.Context(), req, opts)
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			if !reflect.DeepEqual(partials, tc.wantPartial) {
+				t.Fatalf("partial images = %v, want = %v.", partials, tc.wantPartial)
+			}
+			if len(resp.Outputs) != 2 {
+				t.Fatalf("Outputs = %+v, want image and text.", resp.Outputs)
+			}
+			if got := resp.Outputs[0].ImageGenerationToolOutput; !reflect.DeepEqual(got, wantOutput) {
+				t.Fatalf("ImageGenerationToolOutput = %+v, want = %+v.", got, wantOutput)
+			}
+
+			mu.Lock()
+			body := bodies[len(bodies)-1]
+			mu.Unlock()
+			for _, want := range []string{
+				`"type":"image_generation"`, `"size":"1024x1024"`, `"quality":"low"`, `"partial_images":2`,
+			} {
+				if !strings.Contains(body, want) {
+					t.Fatalf("request body = %s, want it to contain %s.", body, want)
+				}
+			}
+
+			// Replaying the image sends it back as an image_generation_call item.
+			req.ModelParam.Stream = false
+			req.Inputs = append(req.Inputs, spec.InputUnion{
+				Kind:                      spec.InputKindImageGenerationToolOutput,
+				ImageGenerationToolOutput: resp.Outputs[0].ImageGenerationToolOutput,
+			})
+			if _, err := api.FetchCompletion(t.Context(), req, nil); err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			mu.Lock()
+			body = bodies[len(bodies)-1]
+			mu.Unlock()
+			want := `{"result":"RklOQUw=","id":"ig1","status":"completed","type":"image_generation_call"}`
+			if !strings.Contains(body, want) {
+				t.Fatalf("request body = %s, want it to contain %s.", body, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package openairesponsessdk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+)
+
+// Item ID prefixes of the Responses API. Input items carrying an ID without the prefix of their type are rejected.
+const (
+	messageItemIDPrefix        = "msg_"
+	functionCallItemIDPrefix   = "fc_"
+	customToolCallItemIDPrefix = "ctc_"
+	reasoningItemIDPrefix      = "rs_"
+	regeneratedCallIDPrefix    = "call_"
+)
+
+// validCallID matches the call IDs the Responses API accepts.
+var validCallID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// itemIDMapper rewrites the IDs of history items that originate from other providers (e.g. Anthropic toolu_ IDs)
+// so that cross-provider histories replay cleanly. Foreign item IDs are dropped where the API allows it and
+// regenerated where it requires one; call IDs the API does not accept are regenerated, consistently for a call and
+// its output. Regenerated IDs are derived from the original ID, so the same history maps to the same request.
+type itemIDMapper struct {
+	// ids maps the prefix and original ID to the regenerated ID.
+	ids map[string]string
+}
+
+// sanitizeOpenAIResponsesItemIDs applies itemIDMapper to input items in place. Reasoning items of other providers
+// cannot be replayed and are removed.
+func sanitizeOpenAIResponsesItemIDs(items responses.ResponseInputParam) responses.ResponseInputParam {
+	m := &itemIDMapper{ids: map[string]string{}}
+	out := items[:0]
+	for i, item := range items {
+		switch {
+		case item.OfOutputMessage != nil:
+			item.OfOutputMessage.ID = m.requiredItemID(messageItemIDPrefix, item.OfOutputMessage.ID, i)
+		case item.OfFunctionCall != nil:
+			item.OfFunctionCall.ID = m.optionalItemID(functionCallItemIDPrefix, item.OfFunctionCall.ID)
+			item.OfFunctionCall.CallID = m.callID(item.OfFunctionCall.CallID)
+		case item.OfFunctionCallOutput != nil:
+			item.OfFunctionCallOutput.ID = m.optionalItemID(functionCallItemIDPrefix, item.OfFunctionCallOutput.ID)
+			item.OfFunctionCallOutput.CallID = m.callID(item.OfFunctionCallOutput.CallID)
+		case item.OfCustomToolCall != nil:
+			item.OfCustomToolCall.ID = m.optionalItemID(customToolCallItemIDPrefix, item.OfCustomToolCall.ID)
+			item.OfCustomToolCall.CallID = m.callID(item.OfCustomToolCall.CallID)
+		case item.OfCustomToolCallOutput != nil:
+			item.OfCustomToolCallOutput.ID = m.optionalItemID(
+				customToolCallItemIDPrefix,
+				item.OfCustomToolCallOutput.ID,
+			)
+			item.OfCustomToolCallOutput.CallID = m.callID(item.OfCustomToolCallOutput.CallID)
+		case item.OfReasoning != nil:
+			if !strings.HasPrefix(item.OfReasoning.ID, reasoningItemIDPrefix) {
+				logutil.Debug("dropped foreign reasoning item from openai responses input", "id", item.OfReasoning.ID)
+				continue
+			}
+		}
+		out = append(out, item)
+	}
+	if len(m.ids) > 0 {
+		logutil.Debug("regenerated foreign item ids for openai responses input", "count", len(m.ids))
+	}
+	return out
+}
+
+// requiredItemID returns id if it has prefix, else an ID regenerated with prefix. Items without an ID get one
+// derived from their position.
+func (m *itemIDMapper) requiredItemID(prefix, id string, index int) string {
+	if strings.HasPrefix(id, prefix) {
+		return id
+	}
+	if id == "" {
+		id = "item-" + strconv.Itoa(index)
+	}
+	return m.regenerate(prefix, id)
+}
+
+// optionalItemID returns id if it has prefix, else no ID.
+func (m *itemIDMapper) optionalItemID(prefix string, id param.Opt[string]) param.Opt[string] {
+	if !id.Valid() || strings.HasPrefix(id.Value, prefix) {
+		return id
+	}
+	return param.Opt[string]{}
+}
+
+// callID returns id if the API accepts it, else a regenerated call ID.
+func (m *itemIDMapper) callID(id string) string {
+	if validCallID.MatchString(id) {
+		return id
+	}
+	return m.regenerate(regeneratedCallIDPrefix, id)
+}
+
+func (m *itemIDMapper) regenerate(prefix, id string) string {
+	key := prefix + id
+	if out, ok := m.ids[key]; ok {
+		return out
+	}
+	sum := sha256.Sum256([]byte(id))
+	out := prefix + hex.EncodeToString(sum[:12])
+	m.ids[key] = out
+	return out
+}
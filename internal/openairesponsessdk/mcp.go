@@ -0,0 +1,134 @@
+package openairesponsessdk
+
+import (
+	"strings"
+
+	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const mcpListToolsName = "mcp_list_tools"
+
+// mcpServerLabel returns the server label of an mcp tool choice.
+func mcpServerLabel(tc spec.ToolChoice) string {
+	if tc.MCPArguments != nil && strings.TrimSpace(tc.MCPArguments.ServerLabel) != "" {
+		return tc.MCPArguments.ServerLabel
+	}
+	return tc.Name
+}
+
+func mcpToolParam(tc spec.ToolChoice) responses.ToolUnionParam {
+	args := tc.MCPArguments
+	tool := responses.ToolMcpParam{
+		ServerLabel: mcpServerLabel(tc),
+		ServerURL:   param.NewOpt(args.ServerURL),
+		Headers:     args.Headers,
+	}
+	if d := strings.TrimSpace(args.ServerDescription); d != "" {
+		tool.ServerDescription = param.NewOpt(d)
+	}
+	if len(args.AllowedTools) > 0 {
+		tool.AllowedTools.OfMcpAllowedTools = args.AllowedTools
+	}
+	if a := strings.TrimSpace(args.RequireApproval); a != "" {
+		tool.RequireApproval.OfMcpToolApprovalSetting = param.NewOpt(a)
+	}
+	return responses.ToolUnionParam{OfMcp: &tool}
+}
+
+// mcpChoice returns the mcp tool choice for serverLabel.
+func mcpChoice(toolChoiceNameMap map[string]spec.ToolChoice, serverLabel string) spec.ToolChoice {
+	for _, tc := range toolChoiceNameMap {
+		if tc.Type == spec.ToolTypeMCP && mcpServerLabel(tc) == serverLabel {
+			return tc
+		}
+	}
+	return spec.ToolChoice{}
+}
+
+// mcpCallFromOpenAI converts an mcp_call item, including its result, into a ToolCall.
+func mcpCallFromOpenAI(item responses.ResponseOutputItemMcpCall, choice spec.ToolChoice) *spec.ToolCall {
+	status := fromOpenAIStatus(item.Status)
+	if status == "" {
+		status = spec.StatusCompleted
+	}
+	return &spec.ToolCall{
+		Type:      spec.ToolTypeMCP,
+		ChoiceID:  choice.ID,
+		ID:        item.ID,
+		CallID:    item.ID,
+		Role:      spec.RoleAssistant,
+		Name:      item.Name,
+		Arguments: item.Arguments,
+		Status:    status,
+		MCPToolCallItem: &spec.MCPToolCallItem{
+			ServerLabel: item.ServerLabel,
+			Output:      item.Output,
+			Error:       item.Error,
+		},
+	}
+}
+
+// mcpListToolsFromOpenAI converts an mcp_list_tools item into a ToolCall listing the server tools.
+func mcpListToolsFromOpenAI(item responses.ResponseOutputItemMcpListTools, choice spec.ToolChoice) *spec.ToolCall {
+	mcp := &spec.MCPToolCallItem{ServerLabel: item.ServerLabel, Error: item.Error}
+	for _, t := range item.Tools {
+		info := spec.MCPToolInfo{Name: t.Name, Description: t.Description}
+		if schema, ok := t.InputSchema.(map[string]any); ok {
+			info.InputSchema = schema
+		}
+		mcp.Tools = append(mcp.Tools, info)
+	}
+	return &spec.ToolCall{
+		Type:            spec.ToolTypeMCP,
+		ChoiceID:        choice.ID,
+		ID:              item.ID,
+		CallID:          item.ID,
+		Role:            spec.RoleAssistant,
+		Name:            mcpListToolsName,
+		Status:          spec.StatusCompleted,
+		MCPToolCallItem: mcp,
+	}
+}
+
+// mcpCallToOpenAI converts an MCP call back into an mcp_call input item.
+func mcpCallToOpenAI(call *spec.ToolCall) *responses.ResponseInputItemUnionParam {
+	if call == nil || call.MCPToolCallItem == nil || strings.TrimSpace(call.ID) == "" {
+		return nil
+	}
+	mcp := call.MCPToolCallItem
+	item := &responses.ResponseInputItemMcpCallParam{
+		ID:          call.ID,
+		Arguments:   call.Arguments,
+		Name:        call.Name,
+		ServerLabel: mcp.ServerLabel,
+		Output:      optString(mcp.Output),
+		Error:       optString(mcp.Error),
+	}
+	return &responses.ResponseInputItemUnionParam{OfMcpCall: item}
+}
+
+// mcpListToolsToOpenAI converts an MCP tool listing back into an mcp_list_tools input item.
+func mcpListToolsToOpenAI(call *spec.ToolCall) *responses.ResponseInputItemUnionParam {
+	if call == nil || call.MCPToolCallItem == nil || strings.TrimSpace(call.ID) == "" {
+		return nil
+	}
+	mcp := call.MCPToolCallItem
+	tools := make([]responses.ResponseInputItemMcpListToolsToolParam, 0, len(mcp.Tools))
+	for _, t := range mcp.Tools {
+		var schema any = map[string]any{}
+		if t.InputSchema != nil {
+			schema = t.InputSchema
+		}
+		tools = append(tools, responses.ResponseInputItemMcpListToolsToolParam{
+			Name:        t.Name,
+			Description: optString(t.Description),
+			InputSchema: schema,
+		})
+	}
+	item := responses.ResponseInputItemParamOfMcpListTools(call.ID, mcp.ServerLabel, tools)
+	item.OfMcpListTools.Error = optString(mcp.Error)
+	return &item
+}
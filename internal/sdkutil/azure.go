@@ -0,0 +1,76 @@
+package sdkutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const azureAPIKeyHeader = "api-key"
+
+// AzureMiddleware returns an OpenAI SDK middleware that rewrites requests built against an Origin base URL to Azure
+// OpenAI routes: chat completions go to the deployment named by the request's model, responses and other paths move
+// under /openai. It adds the api-version query parameter and sends the API key in api-key instead of Authorization.
+func AzureMiddleware(
+	cfg *spec.AzureOpenAI,
+	apiKey string,
+) func(*http.Request, func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		p := req.URL.Path
+		switch {
+		case strings.HasSuffix(p, "/chat/completions"):
+			model, err := requestModel(req)
+			if err != nil {
+				return nil, err
+			}
+			prefix := strings.TrimSuffix(p, "/chat/completions")
+			req.URL.Path = prefix + "/openai/deployments/" + model + "/chat/completions"
+			req.URL.RawPath = prefix + "/openai/deployments/" + url.PathEscape(model) + "/chat/completions"
+		case strings.Contains(p, "/responses"):
+			i := strings.Index(p, "/responses")
+			req.URL.Path = p[:i] + "/openai" + p[i:]
+			req.URL.RawPath = ""
+		case !strings.HasPrefix(p, "/openai/"):
+			req.URL.Path = "/openai" + p
+			req.URL.RawPath = ""
+		}
+
+		q := req.URL.Query()
+		if q.Get("api-version") == "" {
+			q.Set("api-version", cfg.APIVersion)
+			req.URL.RawQuery = q.Encode()
+		}
+		req.Header.Del(spec.DefaultAuthorizationHeaderKey)
+		if apiKey != "" && req.Header.Get(azureAPIKeyHeader) == "" {
+			req.Header.Set(azureAPIKeyHeader, apiKey)
+		}
+		return next(req)
+	}
+}
+
+// requestModel reads the model field of a JSON request body and restores the body.
+func requestModel(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+
+	var payload struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", err
+	}
+	return payload.Model, nil
+}
@@ -0,0 +1,26 @@
+package sdkutil
+
+import (
+	"net/http"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// CredentialsMiddleware returns an SDK middleware that authenticates each request with a bearer token from cp. The
+// clearHeaders are removed first, e.g. the API key header the SDK sets from an empty key.
+func CredentialsMiddleware(
+	cp spec.CredentialProvider,
+	clearHeaders ...string,
+) func(*http.Request, func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		token, err := cp.Token(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range clearHeaders {
+			req.Header.Del(h)
+		}
+		req.Header.Set(spec.DefaultAuthorizationHeaderKey, "Bearer "+token)
+		return next(req)
+	}
+}
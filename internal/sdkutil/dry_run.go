@@ -0,0 +1,122 @@
+package sdkutil
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// DryRunResponse returns the response for a FetchCompletionOptions.DryRun call: no outputs, and DebugDetails holding
+// the serialized provider request. Large base64 payloads (images, files) are replaced with a size marker; credentials
+// are never part of body.
+func DryRunResponse(
+	pi *spec.ProviderParam,
+	model spec.ModelName,
+	stream bool,
+	body any,
+) (*spec.FetchCompletionResponse, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("dry run: marshal provider request: %w", err)
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("dry run: decode provider request: %w", err)
+	}
+
+	return &spec.FetchCompletionResponse{
+		DebugDetails: map[string]any{
+			"dryRun":   true,
+			"provider": pi.Name,
+			"model":    model,
+			"request": map[string]any{
+				"url":    dryRunURL(pi),
+				"stream": stream,
+				"body":   omitBase64(decoded),
+			},
+		},
+	}, nil
+}
+
+// dryRunURL returns the endpoint the adapter would call. Without an Origin the SDK clients use their own default
+// base URL and ignore ChatCompletionPathPrefix, so the default endpoint for the SDK type is reported.
+func dryRunURL(pi *spec.ProviderParam) string {
+	if pi.Origin != "" {
+		return strings.TrimSuffix(pi.Origin, "/") + pi.ChatCompletionPathPrefix
+	}
+	switch pi.SDKType {
+	case spec.ProviderSDKTypeAnthropic:
+		return spec.DefaultAnthropicOrigin + spec.DefaultAnthropicChatCompletionPrefix
+	case spec.ProviderSDKTypeOpenAIResponses:
+		return spec.DefaultOpenAIOrigin + "/v1/responses"
+	case spec.ProviderSDKTypeOpenAICompletions:
+		return spec.DefaultOpenAIOrigin + spec.DefaultOpenAICompletionsPrefix
+	case spec.ProviderSDKTypeOllama:
+		return spec.DefaultOllamaOrigin + cmp.Or(pi.ChatCompletionPathPrefix, spec.DefaultOllamaChatPrefix)
+	case spec.ProviderSDKTypeCohere:
+		return spec.DefaultCohereOrigin + cmp.Or(pi.ChatCompletionPathPrefix, spec.DefaultCohereChatPrefix)
+	default:
+		return spec.DefaultOpenAIOrigin + spec.DefaultOpenAIChatCompletionsPrefix
+	}
+}
+
+func omitBase64(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		for k, e := range vv {
+			vv[k] = omitBase64(e)
+		}
+	case []any:
+		for i, e := range vv {
+			vv[i] = omitBase64(e)
+		}
+	case string:
+		if isBase64Payload(vv) {
+			return fmt.Sprintf("[omitted: %d bytes base64 data]", len(vv))
+		}
+	}
+	return v
+}
+
+// isBase64Payload reports data URLs and long strings made only of base64 characters.
+func isBase64Payload(s string) bool {
+	if len(s) < 128 {
+		return false
+	}
+	if strings.HasPrefix(s, "data:") && strings.Contains(s, ";base64,") {
+		return true
+	}
+	if len(s)%4 != 0 {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case r == '+', r == '/', r == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// EffectiveParams returns params in the provider wire format as a map without the contentFields, for
+// ResponseMetadata.EffectiveParams. Callers should also clear those fields on a copy of params, to avoid encoding
+// the whole conversation.
+func EffectiveParams(params any, contentFields ...string) map[string]any {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	for _, f := range contentFields {
+		delete(out, f)
+	}
+	return out
+}
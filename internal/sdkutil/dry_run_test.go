@@ -0,0 +1,61 @@
+package sdkutil
+
+import (
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestDryRunResponseURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		pi   spec.ProviderParam
+		want string
+	}{
+		{
+			name: "Origin.",
+			pi: spec.ProviderParam{
+				SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+				Origin:                   "http://127.0.0.1:1/",
+				ChatCompletionPathPrefix: "/v1/chat/completions",
+			},
+			want: "http://127.0.0.1:1/v1/chat/completions",
+		},
+		{
+			name: "DefaultAnthropic.",
+			pi:   spec.ProviderParam{SDKType: spec.ProviderSDKTypeAnthropic},
+			want: spec.DefaultAnthropicOrigin + spec.DefaultAnthropicChatCompletionPrefix,
+		},
+		{
+			name: "DefaultOpenAIChat.",
+			pi:   spec.ProviderParam{SDKType: spec.ProviderSDKTypeOpenAIChatCompletions},
+			want: spec.DefaultOpenAIOrigin + spec.DefaultOpenAIChatCompletionsPrefix,
+		},
+		{
+			name: "DefaultOpenAIResponses.",
+			pi:   spec.ProviderParam{SDKType: spec.ProviderSDKTypeOpenAIResponses},
+			want: spec.DefaultOpenAIOrigin + "/v1/responses",
+		},
+		{
+			name: "DefaultOllamaWithPrefix.",
+			pi:   spec.ProviderParam{SDKType: spec.ProviderSDKTypeOllama, ChatCompletionPathPrefix: "/api/generate"},
+			want: spec.DefaultOllamaOrigin + "/api/generate",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			resp, err := DryRunResponse(&tc.pi, "m", false, map[string]any{"model": "m"})
+			if err != nil {
+				t.Fatalf("DryRunResponse() error = %v.", err)
+			}
+			dd, _ := resp.DebugDetails.(map[string]any)
+			req, _ := dd["request"].(map[string]any)
+			if got := req["url"]; got != tc.want {
+				t.Fatalf("url = %v, want = %v.", got, tc.want)
+			}
+		})
+	}
+}
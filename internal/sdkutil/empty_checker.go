@@ -25,6 +25,24 @@ func IsInputUnionEmpty(in spec.InputUnion) bool {
 		return in.WebSearchToolCall == nil
 	case spec.InputKindWebSearchToolOutput:
 		return in.WebSearchToolOutput == nil
+	case spec.InputKindCodeExecutionToolCall:
+		return in.CodeExecutionToolCall == nil
+	case spec.InputKindCodeExecutionToolOutput:
+		return in.CodeExecutionToolOutput == nil
+	case spec.InputKindImageGenerationToolOutput:
+		return in.ImageGenerationToolOutput == nil
+	case spec.InputKindCodeInterpreterToolCall:
+		return in.CodeInterpreterToolCall == nil
+	case spec.InputKindFileSearchToolCall:
+		return in.FileSearchToolCall == nil
+	case spec.InputKindComputerToolCall:
+		return in.ComputerToolCall == nil
+	case spec.InputKindComputerToolOutput:
+		return in.ComputerToolOutput == nil
+	case spec.InputKindMCPToolCall:
+		return in.MCPToolCall == nil
+	case spec.InputKindMCPListTools:
+		return in.MCPListTools == nil
 	default:
 		// Zero-value or unknown kind -> nothing to send.
 		return true
@@ -93,6 +111,7 @@ func isContentItemEmpty(it spec.InputOutputContentItemUnion) bool {
 		return f.ID == "" &&
 			f.FileName == "" &&
 			f.FileURL == "" &&
+			f.FileID == "" &&
 			f.FileData == "" &&
 			f.AdditionalContext == "" &&
 			f.CitationConfig == nil
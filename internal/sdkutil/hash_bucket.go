@@ -0,0 +1,15 @@
+package sdkutil
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"strings"
+)
+
+// HashBucket maps parts to a uniformly distributed number in [0, 1). Equal parts always give the same number, which
+// makes it suitable for stateless, sticky assignment such as percentage rollouts and experiment variants.
+func HashBucket(parts ...string) float64 {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return float64(binary.BigEndian.Uint64(sum[:8])>>11) / math.Exp2(53)
+}
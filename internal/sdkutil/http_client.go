@@ -0,0 +1,22 @@
+package sdkutil
+
+import (
+	"net/http"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// HTTPClient returns the client a provider sends its requests with: ProviderParam.HTTPClient, wrapped by dbg if
+// set. Nil means the SDK default client.
+func HTTPClient(pi *spec.ProviderParam, dbg spec.CompletionDebugger) *http.Client {
+	var base *http.Client
+	if pi != nil {
+		base = pi.HTTPClient
+	}
+	if dbg != nil {
+		if c := dbg.HTTPClient(base); c != nil {
+			return c
+		}
+	}
+	return base
+}
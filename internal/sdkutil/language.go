@@ -0,0 +1,106 @@
+package sdkutil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// LocaleHint returns the system prompt snippet that asks for a response in locale (a BCP 47 tag).
+func LocaleHint(locale string) string {
+	return "Respond in the language and regional conventions of the locale \"" + strings.TrimSpace(locale) +
+		"\", unless the user explicitly asks for another language."
+}
+
+// AppendLocaleHint appends the locale hint to a system prompt.
+func AppendLocaleHint(systemPrompt, locale string) string {
+	if strings.TrimSpace(locale) == "" {
+		return systemPrompt
+	}
+	if strings.TrimSpace(systemPrompt) == "" {
+		return LocaleHint(locale)
+	}
+	return strings.TrimRight(systemPrompt, "\n") + "\n\n" + LocaleHint(locale)
+}
+
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+}
+
+// stopWords are frequent function words of Latin-script languages.
+var stopWords = map[string][]string{
+	"en": {"the", "and", "is", "of", "to", "in", "that", "it", "you", "for", "with", "this"},
+	"es": {"el", "la", "de", "que", "y", "en", "los", "es", "por", "con", "para", "una"},
+	"fr": {"le", "la", "les", "de", "et", "est", "que", "un", "une", "pour", "des", "vous"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "zu", "ein", "ich", "mit", "sie", "den"},
+	"pt": {"o", "a", "de", "que", "e", "do", "da", "em", "um", "para", "com", "não"},
+	"it": {"il", "di", "che", "e", "la", "per", "un", "non", "sono", "gli", "una", "con"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "zijn", "voor"},
+}
+
+// DetectLanguage returns a best-effort ISO 639-1 code for text: by script for non-Latin text and by stop words for
+// a few Latin-script languages. It returns "" when unsure.
+func DetectLanguage(text string) string {
+	counts := map[string]int{}
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, sl := range scriptLanguages {
+			if unicode.Is(sl.table, r) {
+				counts[sl.lang]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	// Kana marks Japanese even when most characters are Han.
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	if lang, n := maxCount(counts); n*2 > letters {
+		return lang
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	scores := map[string]int{}
+	for lang, list := range stopWords {
+		for _, w := range words {
+			for _, sw := range list {
+				if w == sw {
+					scores[lang]++
+				}
+			}
+		}
+	}
+	lang, n := maxCount(scores)
+	if n < 2 {
+		return ""
+	}
+	return lang
+}
+
+// maxCount returns the key with the highest count, breaking ties by key for determinism.
+func maxCount(counts map[string]int) (key string, n int) {
+	for k, c := range counts {
+		if c > n || (c == n && k < key) {
+			key, n = k, c
+		}
+	}
+	return key, n
+}
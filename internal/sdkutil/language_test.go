@@ -0,0 +1,28 @@
+package sdkutil
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "English.", text: "This is the answer to the question you asked.", want: "en"},
+		{name: "French.", text: "Voici la réponse et les détails pour vous.", want: "fr"},
+		{name: "German.", text: "Das ist die Antwort und ich bin nicht sicher.", want: "de"},
+		{name: "Japanese.", text: "これは答えです。日本語で書きます。", want: "ja"},
+		{name: "Russian.", text: "Это ответ на ваш вопрос.", want: "ru"},
+		{name: "Empty.", text: "123 !!", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := DetectLanguage(tc.text); got != tc.want {
+				t.Fatalf("DetectLanguage(%q) = %q, want = %q.", tc.text, got, tc.want)
+			}
+		})
+	}
+}
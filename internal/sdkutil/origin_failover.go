@@ -0,0 +1,150 @@
+package sdkutil
+
+import (
+	"cmp"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// latencyWeight is the weight of the newest sample in the latency moving average.
+const latencyWeight = 0.3
+
+// OriginPool routes SDK requests across a provider's primary and fallback origins. Its Middleware is an SDK
+// middleware (anthropic and openai option.Middleware).
+type OriginPool struct {
+	mu       sync.Mutex
+	origins  []*originState
+	cooldown time.Duration
+	latency  bool
+}
+
+type originState struct {
+	url            *url.URL
+	unhealthyUntil time.Time
+	latency        time.Duration
+}
+
+// NewOriginPool returns a pool for primary (the origin the SDK client was built with) and the failover origins.
+func NewOriginPool(primary string, failover *spec.OriginFailover) (*OriginPool, error) {
+	if err := failover.Validate(); err != nil {
+		return nil, err
+	}
+	p := &OriginPool{cooldown: time.Duration(spec.DefaultOriginCooldownMillis) * time.Millisecond}
+	origins := []string{primary}
+	if failover != nil {
+		origins = append(origins, failover.Origins...)
+		p.latency = failover.LatencyBased
+		if failover.CooldownMillis > 0 {
+			p.cooldown = time.Duration(failover.CooldownMillis) * time.Millisecond
+		}
+	}
+	for _, o := range origins {
+		u, err := url.Parse(strings.TrimSuffix(o, "/"))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, errors.New("invalid origin: " + o)
+		}
+		p.origins = append(p.origins, &originState{url: u})
+	}
+	return p, nil
+}
+
+// Middleware sends req to the preferred healthy origin and fails over to the next one on a network error or a 5xx
+// status. Requests whose body cannot be replayed are not failed over.
+func (p *OriginPool) Middleware(
+	req *http.Request,
+	next func(*http.Request) (*http.Response, error),
+) (*http.Response, error) {
+	order := p.order()
+	for i, o := range order {
+		r := req.Clone(req.Context())
+		if i > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		p.rewrite(r, o)
+
+		start := time.Now()
+		resp, err := next(r)
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		p.report(o, time.Since(start), failed)
+
+		replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if !failed || i == len(order)-1 || !replayable {
+			return resp, err
+		}
+		logutil.Warn("origin failed, failing over", "origin", o.url.String(), "next", order[i+1].url.String())
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+	}
+	return nil, errors.New("no origin available")
+}
+
+// order returns healthy origins first, by configuration order or latency, then cooling-down origins by expiry.
+func (p *OriginPool) order() []*originState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	out := slices.Clone(p.origins)
+	slices.SortStableFunc(out, func(a, b *originState) int {
+		aDown, bDown := now.Before(a.unhealthyUntil), now.Before(b.unhealthyUntil)
+		switch {
+		case aDown && bDown:
+			return a.unhealthyUntil.Compare(b.unhealthyUntil)
+		case aDown:
+			return 1
+		case bDown:
+			return -1
+		case p.latency:
+			return cmp.Compare(a.latency, b.latency)
+		default:
+			return 0
+		}
+	})
+	return out
+}
+
+func (p *OriginPool) report(o *originState, latency time.Duration, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if failed {
+		o.unhealthyUntil = time.Now().Add(p.cooldown)
+		return
+	}
+	o.unhealthyUntil = time.Time{}
+	if o.latency == 0 {
+		o.latency = latency
+	} else {
+		o.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(o.latency))
+	}
+}
+
+// rewrite points r at origin o, replacing the primary origin's scheme, host and path prefix.
+func (p *OriginPool) rewrite(r *http.Request, o *originState) {
+	primary := p.origins[0].url
+	if o == p.origins[0] {
+		return
+	}
+	r.URL.Scheme, r.URL.Host = o.url.Scheme, o.url.Host
+	r.Host = o.url.Host
+	if rest, ok := strings.CutPrefix(r.URL.Path, primary.Path); ok {
+		r.URL.Path = o.url.Path + rest
+		r.URL.RawPath = ""
+	}
+}
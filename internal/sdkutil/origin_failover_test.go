@@ -0,0 +1,54 @@
+package sdkutil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestOriginPoolFailover(t *testing.T) {
+	t.Parallel()
+
+	var primaryHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		primaryHits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(primary.Close)
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(r.URL.Path + " " + string(body)))
+	}))
+	t.Cleanup(fallback.Close)
+
+	pool, err := NewOriginPool(primary.URL+"/v1", &spec.OriginFailover{Origins: []string{fallback.URL + "/eu/v1"}})
+	if err != nil {
+		t.Fatalf("NewOriginPool() error = %v.", err)
+	}
+
+	for i := range 2 {
+		req, err := http.NewRequestWithContext(
+			t.Context(), http.MethodPost, primary.URL+"/v1/messages", strings.NewReader("payload"),
+		)
+		if err != nil {
+			t.Fatalf("NewRequest() error = %v.", err)
+		}
+		resp, err := pool.Middleware(req, http.DefaultClient.Do)
+		if err != nil {
+			t.Fatalf("Middleware() error = %v.", err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if want := "/eu/v1/messages payload"; resp.StatusCode != http.StatusOK || string(got) != want {
+			t.Fatalf("request %d = %d %q, want = 200 %q.", i, resp.StatusCode, got, want)
+		}
+	}
+	if got := primaryHits.Load(); got != 1 {
+		t.Fatalf("primary hits = %d, want = 1 (skipped while cooling down).", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"runtime/debug"
 
 	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/spec"
 )
 
 // Recover logs a panic (if any) at error level and prevents it from bringing
@@ -14,3 +15,26 @@ func Recover(msg string, fields ...any) {
 		logutil.Error(msg, fields...)
 	}
 }
+
+// RecoverFetchCompletion converts a panic into a *spec.PanicError and a response whose DebugDetails carry the panic
+// value and stack, so that SDK failures on malformed responses do not crash the host process. It must be deferred
+// directly by a FetchCompletion with named results:
+//
+//	defer sdkutil.RecoverFetchCompletion(providerName, &resp, &err)
+func RecoverFetchCompletion(provider spec.ProviderName, resp **spec.FetchCompletionResponse, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := string(debug.Stack())
+	logutil.Error("fetch completion panic", "provider", provider, "panic", r, "stack", stack)
+
+	pe := &spec.PanicError{Provider: provider, Value: r, Stack: stack}
+	if *resp == nil {
+		*resp = &spec.FetchCompletionResponse{}
+	}
+	(*resp).Error = &spec.Error{Code: spec.ErrorCodePanic, Message: pe.Error(), Provider: provider}
+	(*resp).Partial = len((*resp).Outputs) > 0
+	(*resp).DebugDetails = map[string]any{"panic": pe.Error(), "stack": stack}
+	*err = pe
+}
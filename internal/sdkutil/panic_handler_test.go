@@ -0,0 +1,46 @@
+package sdkutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestRecoverFetchCompletion(t *testing.T) {
+	t.Parallel()
+
+	fetch := func(partial *spec.FetchCompletionResponse) (resp *spec.FetchCompletionResponse, err error) {
+		defer RecoverFetchCompletion("p", &resp, &err)
+		resp = partial
+		var m map[string]int
+		m["boom"]++ // Nil map write panics.
+		return resp, nil
+	}
+
+	tests := []struct {
+		name    string
+		partial *spec.FetchCompletionResponse
+	}{
+		{name: "NoPartialResponse."},
+		{name: "PartialResponseIsKept.", partial: &spec.FetchCompletionResponse{Outputs: []spec.OutputUnion{{}}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := fetch(tc.partial)
+			var pe *spec.PanicError
+			if !errors.As(err, &pe) || pe.Provider != "p" || pe.Stack == "" {
+				t.Fatalf("FetchCompletion() error = %v, want = *spec.PanicError with stack.", err)
+			}
+			if resp == nil || resp.Error == nil || resp.Error.Code != "panic" || resp.DebugDetails == nil {
+				t.Fatalf("FetchCompletion() resp = %+v, want panic error and debug details.", resp)
+			}
+			if tc.partial != nil && len(resp.Outputs) != 1 {
+				t.Fatalf("Outputs = %d, want = 1.", len(resp.Outputs))
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package sdkutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ParseParamOverlay decodes ModelParam.AdditionalParametersRawJSON into the top-level request fields it sets. A nil
+// or blank raw yields nil. Fields in deny are managed by the adapter (model, conversation, tools, streaming) and
+// cannot be overlaid.
+func ParseParamOverlay(raw *string, deny ...string) (map[string]any, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, nil
+	}
+	var overlay map[string]any
+	if err := json.Unmarshal([]byte(*raw), &overlay); err != nil {
+		return nil, fmt.Errorf("additionalParametersRawJSON: %w", err)
+	}
+	if overlay == nil {
+		return nil, errors.New("additionalParametersRawJSON: must be a JSON object")
+	}
+	for _, name := range slices.Sorted(maps.Keys(overlay)) {
+		if slices.Contains(deny, name) {
+			return nil, fmt.Errorf(
+				"additionalParametersRawJSON: field %q is set by the adapter and cannot be overridden", name,
+			)
+		}
+	}
+	return overlay, nil
+}
+
+// OverlayFields returns the request extra fields that apply overlay to params: extra (the extra fields already set)
+// plus every overlay field merged into its serialized value. Objects are merged recursively; any other value,
+// including null, replaces the current one.
+func OverlayFields(params any, extra, overlay map[string]any) (map[string]any, error) {
+	if len(overlay) == 0 {
+		return extra, nil
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("additionalParametersRawJSON: encode request: %w", err)
+	}
+	var current map[string]any
+	if err := json.Unmarshal(raw, &current); err != nil {
+		return nil, fmt.Errorf("additionalParametersRawJSON: decode request: %w", err)
+	}
+	out := maps.Clone(extra)
+	if out == nil {
+		out = make(map[string]any, len(overlay))
+	}
+	for name, value := range overlay {
+		out[name] = MergeJSON(current[name], value)
+	}
+	return out, nil
+}
+
+// MarshalWithExtra encodes v, a struct, with the top-level fields in extra added or replaced. It backs the
+// MarshalJSON of request types that are not SDK params.
+func MarshalWithExtra(v any, extra map[string]any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return raw, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range extra {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = b
+	}
+	return json.Marshal(fields)
+}
+
+// MergeJSON merges overlay into base, both decoded JSON values. Objects are merged key by key, recursively; any
+// other overlay value replaces base. Neither input is modified.
+func MergeJSON(base, overlay any) any {
+	o, ok := overlay.(map[string]any)
+	if !ok {
+		return overlay
+	}
+	b, ok := base.(map[string]any)
+	if !ok {
+		return o
+	}
+	out := maps.Clone(b)
+	for k, v := range o {
+		out[k] = MergeJSON(b[k], v)
+	}
+	return out
+}
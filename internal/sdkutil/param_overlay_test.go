@@ -0,0 +1,56 @@
+package sdkutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOverlayFields(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		Model   string         `json:"model"`
+		Options map[string]any `json:"options,omitempty"`
+		Stop    []string       `json:"stop,omitempty"`
+	}
+	params := request{
+		Model:   "m",
+		Options: map[string]any{"temperature": 0.5, "nested": map[string]any{"a": 1, "b": 2}},
+		Stop:    []string{"x"},
+	}
+	raw := `{"options":{"num_ctx":8192,"nested":{"b":3}},"stop":null,"mirostat":2}`
+	overlay, err := ParseParamOverlay(&raw, "model")
+	if err != nil {
+		t.Fatalf("ParseParamOverlay() error = %v.", err)
+	}
+	got, err := OverlayFields(params, map[string]any{"keep": true}, overlay)
+	if err != nil {
+		t.Fatalf("OverlayFields() error = %v.", err)
+	}
+	want := map[string]any{
+		"keep": true,
+		"options": map[string]any{
+			"temperature": 0.5,
+			"num_ctx":     float64(8192),
+			"nested":      map[string]any{"a": float64(1), "b": float64(3)},
+		},
+		"stop":     nil,
+		"mirostat": float64(2),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("OverlayFields() = %#v, want %#v.", got, want)
+	}
+	if params.Options["nested"].(map[string]any)["b"] != 2 {
+		t.Fatalf("OverlayFields() modified params: %#v.", params.Options)
+	}
+
+	for _, bad := range []string{`{"model":"x"}`, `[1]`, `null`, `{`} {
+		if _, err := ParseParamOverlay(&bad, "model"); err == nil {
+			t.Fatalf("ParseParamOverlay(%s) error = nil, want an error.", bad)
+		}
+	}
+	blank := "  "
+	if overlay, err := ParseParamOverlay(&blank); overlay != nil || err != nil {
+		t.Fatalf("ParseParamOverlay(blank) = %v, %v, want nil, nil.", overlay, err)
+	}
+}
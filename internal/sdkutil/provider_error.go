@@ -0,0 +1,129 @@
+package sdkutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// NewError returns the spec.Error of a failed call. statusCode is the HTTP status of the provider response (0 if
+// none was received), providerCode the provider's own error code or type, and raw the provider error body.
+func NewError(provider spec.ProviderName, err error, statusCode int, providerCode, raw string) *spec.Error {
+	if err == nil {
+		return nil
+	}
+	code := ErrorCode(err, statusCode, providerCode)
+	return &spec.Error{
+		Code:       code,
+		Message:    err.Error(),
+		Provider:   provider,
+		HTTPStatus: statusCode,
+		Retryable: code != spec.ErrorCodeQuotaExceeded && (IsRetryableError(err, statusCode) ||
+			code == spec.ErrorCodeRateLimit || code == spec.ErrorCodeOverloaded || code == spec.ErrorCodeServer),
+		RawProviderError: raw,
+	}
+}
+
+// FinalizeResponse applies the error contract of spec.FetchCompletionResponse to the result of a call: on error the
+// response is non-nil, Error describes err, and Partial reports whether Outputs hold salvaged output.
+func FinalizeResponse(
+	provider spec.ProviderName,
+	resp *spec.FetchCompletionResponse,
+	err error,
+) *spec.FetchCompletionResponse {
+	if err == nil {
+		if resp != nil {
+			resp.Partial = false
+		}
+		return resp
+	}
+	if resp == nil {
+		resp = &spec.FetchCompletionResponse{}
+	}
+	if resp.Error == nil {
+		resp.Error = NewError(provider, err, 0, "", "")
+	}
+	resp.Partial = len(resp.Outputs) > 0
+	return resp
+}
+
+// ErrorCode maps a failure onto the spec.ErrorCode constants. Provider error codes take precedence over the HTTP
+// status, which takes precedence over the Go error chain.
+func ErrorCode(err error, statusCode int, providerCode string) string {
+	if errors.Is(err, context.Canceled) {
+		return spec.ErrorCodeCanceled
+	}
+	if isContextLengthError(err, providerCode) {
+		return spec.ErrorCodeContextLength
+	}
+	switch providerCode {
+	case "authentication_error", "invalid_api_key":
+		return spec.ErrorCodeAuthentication
+	case "permission_error", "permission_denied":
+		return spec.ErrorCodePermissionDenied
+	case "not_found_error", "model_not_found":
+		return spec.ErrorCodeNotFound
+	case "rate_limit_error", "rate_limit_exceeded":
+		return spec.ErrorCodeRateLimit
+	case "insufficient_quota":
+		return spec.ErrorCodeQuotaExceeded
+	case "overloaded_error", "overloaded":
+		return spec.ErrorCodeOverloaded
+	case "api_error", "server_error":
+		return spec.ErrorCodeServer
+	case "invalid_request_error", "invalid_prompt":
+		return spec.ErrorCodeInvalidRequest
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return spec.ErrorCodeAuthentication
+	case statusCode == http.StatusForbidden:
+		return spec.ErrorCodePermissionDenied
+	case statusCode == http.StatusNotFound:
+		return spec.ErrorCodeNotFound
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusGatewayTimeout:
+		return spec.ErrorCodeTimeout
+	case statusCode == http.StatusTooManyRequests:
+		return spec.ErrorCodeRateLimit
+	case statusCode == http.StatusServiceUnavailable, statusCode == 529:
+		// 529 is Anthropic's overloaded status.
+		return spec.ErrorCodeOverloaded
+	case statusCode >= http.StatusInternalServerError:
+		return spec.ErrorCodeServer
+	case statusCode >= http.StatusBadRequest:
+		return spec.ErrorCodeInvalidRequest
+	}
+
+	var panicErr *spec.PanicError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return spec.ErrorCodeTimeout
+	case errors.As(err, &panicErr):
+		return spec.ErrorCodePanic
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return spec.ErrorCodeTimeout
+		}
+		return spec.ErrorCodeNetwork
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return spec.ErrorCodeNetwork
+	}
+	return spec.ErrorCodeUnknown
+}
+
+// isContextLengthError reports whether the prompt did not fit the model's context window. Anthropic reports this as
+// a plain invalid request, so its message is checked as well.
+func isContextLengthError(err error, providerCode string) bool {
+	if providerCode == "context_length_exceeded" || providerCode == "string_above_max_length" {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "prompt is too long") || strings.Contains(msg, "maximum context length")
+}
@@ -0,0 +1,66 @@
+package sdkutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestNewError(t *testing.T) {
+	t.Parallel()
+
+	apiErr := errors.New("api error")
+	tests := []struct {
+		name          string
+		err           error
+		status        int
+		providerCode  string
+		wantCode      string
+		wantRetryable bool
+	}{
+		{name: "ProviderCodeWins.", err: apiErr, status: 400, providerCode: "rate_limit_exceeded",
+			wantCode: spec.ErrorCodeRateLimit, wantRetryable: true},
+		{name: "ContextLengthCode.", err: apiErr, status: 400, providerCode: "context_length_exceeded",
+			wantCode: spec.ErrorCodeContextLength},
+		{name: "ContextLengthMessage.", err: errors.New("prompt is too long: 210000 tokens"), status: 400,
+			providerCode: "invalid_request_error", wantCode: spec.ErrorCodeContextLength},
+		{name: "Unauthorized.", err: apiErr, status: 401, wantCode: spec.ErrorCodeAuthentication},
+		{name: "NotFound.", err: apiErr, status: 404, wantCode: spec.ErrorCodeNotFound},
+		{name: "QuotaExceeded.", err: apiErr, status: 429, providerCode: "insufficient_quota",
+			wantCode: spec.ErrorCodeQuotaExceeded},
+		{name: "TooManyRequests.", err: apiErr, status: 429, wantCode: spec.ErrorCodeRateLimit, wantRetryable: true},
+		{name: "Overloaded.", err: apiErr, status: 529, wantCode: spec.ErrorCodeOverloaded, wantRetryable: true},
+		{name: "ServerError.", err: apiErr, status: 502, wantCode: spec.ErrorCodeServer, wantRetryable: true},
+		{name: "BadRequest.", err: apiErr, status: 422, wantCode: spec.ErrorCodeInvalidRequest},
+		{name: "FailedEventCode.", err: apiErr, providerCode: "server_error",
+			wantCode: spec.ErrorCodeServer, wantRetryable: true},
+		{name: "DeadlineExceeded.", err: fmt.Errorf("post: %w", context.DeadlineExceeded),
+			wantCode: spec.ErrorCodeTimeout, wantRetryable: true},
+		{name: "Canceled.", err: fmt.Errorf("post: %w", context.Canceled), status: 500,
+			wantCode: spec.ErrorCodeCanceled},
+		{name: "DroppedConnection.", err: io.ErrUnexpectedEOF, wantCode: spec.ErrorCodeNetwork, wantRetryable: true},
+		{name: "Unknown.", err: apiErr, wantCode: spec.ErrorCodeUnknown},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := NewError("p", tc.err, tc.status, tc.providerCode, `{"error":{}}`)
+			if got.Code != tc.wantCode || got.Retryable != tc.wantRetryable {
+				t.Fatalf("NewError() = %+v, want code %q and retryable %v.", got, tc.wantCode, tc.wantRetryable)
+			}
+			if got.Provider != "p" || got.HTTPStatus != tc.status || got.RawProviderError != `{"error":{}}` ||
+				got.Message != tc.err.Error() {
+				t.Fatalf("NewError() = %+v, want the provider, status, body and message recorded.", got)
+			}
+		})
+	}
+
+	if got := NewError("p", nil, 500, "", ""); got != nil {
+		t.Fatalf("NewError(nil) = %+v, want nil.", got)
+	}
+}
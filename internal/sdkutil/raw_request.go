@@ -0,0 +1,39 @@
+package sdkutil
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// RawRequestPath normalizes a raw request path so that it is resolved relative to the SDK base URL.
+func RawRequestPath(path string) (string, error) {
+	p := strings.TrimLeft(strings.TrimSpace(path), "/")
+	if p == "" {
+		return "", errors.New("raw request: empty path")
+	}
+	if strings.Contains(p, "://") {
+		return "", errors.New("raw request: path must be relative to the provider base URL")
+	}
+	return p, nil
+}
+
+// ReadRawResponse reads and closes the body of resp.
+func ReadRawResponse(resp *http.Response) (*spec.RawResponse, error) {
+	if resp == nil {
+		return nil, errors.New("raw request: no response")
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &spec.RawResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package sdkutil
+
+import (
+	"net/http"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// RequestContextMiddleware is an SDK middleware (anthropic and openai option.Middleware) that sets the
+// spec.RequestContext headers from the request context. Headers already set on the request win.
+func RequestContextMiddleware(
+	req *http.Request,
+	next func(*http.Request) (*http.Response, error),
+) (*http.Response, error) {
+	rc, ok := spec.RequestContextFromContext(req.Context())
+	if ok {
+		for k, v := range RequestContextHeaders(rc) {
+			if req.Header.Get(k) == "" {
+				req.Header.Set(k, v)
+			}
+		}
+	}
+	return next(req)
+}
+
+// RequestContextHeaders returns the headers for the non-empty fields of rc.
+func RequestContextHeaders(rc spec.RequestContext) map[string]string {
+	h := map[string]string{}
+	if rc.TenantID != "" {
+		h[spec.HeaderTenantID] = rc.TenantID
+	}
+	if rc.TraceID != "" {
+		h[spec.HeaderTraceID] = rc.TraceID
+	}
+	if rc.FeatureTag != "" {
+		h[spec.HeaderFeatureTag] = rc.FeatureTag
+	}
+	return h
+}
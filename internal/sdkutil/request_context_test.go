@@ -0,0 +1,61 @@
+package sdkutil
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestRequestContextMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		rc     *spec.RequestContext
+		preset map[string]string
+		want   map[string]string
+	}{
+		{
+			name: "NoRequestContext.",
+			want: map[string]string{spec.HeaderTenantID: "", spec.HeaderTraceID: ""},
+		},
+		{
+			name: "FieldsBecomeHeaders.",
+			rc:   &spec.RequestContext{TenantID: "t1", FeatureTag: "search"},
+			want: map[string]string{spec.HeaderTenantID: "t1", spec.HeaderTraceID: "", spec.HeaderFeatureTag: "search"},
+		},
+		{
+			name:   "ExplicitHeadersWin.",
+			rc:     &spec.RequestContext{TraceID: "ctx"},
+			preset: map[string]string{spec.HeaderTraceID: "explicit"},
+			want:   map[string]string{spec.HeaderTraceID: "explicit"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			if tc.rc != nil {
+				ctx = spec.WithRequestContext(ctx, *tc.rc)
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://example.invalid", http.NoBody)
+			if err != nil {
+				t.Fatalf("NewRequestWithContext() error = %v.", err)
+			}
+			for k, v := range tc.preset {
+				req.Header.Set(k, v)
+			}
+			_, _ = RequestContextMiddleware(req, func(r *http.Request) (*http.Response, error) {
+				for k, want := range tc.want {
+					if got := r.Header.Get(k); got != want {
+						t.Errorf("header %s = %q, want = %q.", k, got, want)
+					}
+				}
+				return nil, nil
+			})
+		})
+	}
+}
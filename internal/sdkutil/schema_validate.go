@@ -0,0 +1,337 @@
+package sdkutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSchemaViolations bounds the violations reported for one value.
+const maxSchemaViolations = 20
+
+// ValidateJSONSchema checks value, a JSON document decoded with encoding/json, against schema and returns the
+// violations, each prefixed with the JSON pointer of the offending value. It covers the keywords used by structured
+// output schemas: type, enum, const, properties, required, additionalProperties, items, prefixItems, anyOf, oneOf,
+// allOf, not, local $ref, and the numeric, string and array bounds. Unknown keywords are ignored.
+func ValidateJSONSchema(schema map[string]any, value any) []string {
+	v := &schemaValidator{root: schema}
+	v.validate(schema, value, "")
+	return v.violations
+}
+
+type schemaValidator struct {
+	root       map[string]any
+	violations []string
+	depth      int
+}
+
+func (v *schemaValidator) addf(path, format string, args ...any) {
+	if len(v.violations) >= maxSchemaViolations {
+		return
+	}
+	if path == "" {
+		path = "/"
+	}
+	v.violations = append(v.violations, path+": "+fmt.Sprintf(format, args...))
+}
+
+// matches reports whether value is valid against schema without recording violations.
+func (v *schemaValidator) matches(schema any, value any, path string) bool {
+	sub := &schemaValidator{root: v.root, depth: v.depth}
+	sub.validate(schema, value, path)
+	return len(sub.violations) == 0
+}
+
+func (v *schemaValidator) validate(node any, value any, path string) {
+	switch s := node.(type) {
+	case bool:
+		if !s {
+			v.addf(path, "no value is allowed")
+		}
+		return
+	case map[string]any:
+		v.validateObjectSchema(s, value, path)
+	}
+}
+
+func (v *schemaValidator) validateObjectSchema(s map[string]any, value any, path string) {
+	// Guard against recursive $refs that never consume input.
+	if v.depth > 64 {
+		return
+	}
+	v.depth++
+	defer func() { v.depth-- }()
+
+	if ref, ok := s["$ref"].(string); ok {
+		target, ok := v.resolveRef(ref)
+		if !ok {
+			v.addf(path, "unresolvable $ref %q", ref)
+			return
+		}
+		v.validate(target, value, path)
+	}
+
+	if t, ok := s["type"]; ok && !matchesAnyType(schemaTypes(t), value) {
+		v.addf(path, "expected %s, got %s", strings.Join(schemaTypes(t), " or "), jsonTypeOf(value))
+		return
+	}
+	if enum, ok := s["enum"].([]any); ok &&
+		!slices.ContainsFunc(enum, func(e any) bool { return jsonEqual(e, value) }) {
+		v.addf(path, "value %s is not one of the allowed values", compactJSON(value))
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, value) {
+		v.addf(path, "value %s does not equal %s", compactJSON(value), compactJSON(c))
+	}
+
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			v.validate(sub, value, path)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok &&
+		!slices.ContainsFunc(anyOf, func(sub any) bool { return v.matches(sub, value, path) }) {
+		v.addf(path, "value matches none of the anyOf schemas")
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		n := 0
+		for _, sub := range oneOf {
+			if v.matches(sub, value, path) {
+				n++
+			}
+		}
+		if n != 1 {
+			v.addf(path, "value matches %d of the oneOf schemas, want exactly 1", n)
+		}
+	}
+	if not, ok := s["not"]; ok && v.matches(not, value, path) {
+		v.addf(path, "value matches the not schema")
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.validateObject(s, val, path)
+	case []any:
+		v.validateArray(s, val, path)
+	case string:
+		v.validateString(s, val, path)
+	case float64:
+		v.validateNumber(s, val, path)
+	case json.Number:
+		if f, err := val.Float64(); err == nil {
+			v.validateNumber(s, f, path)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s, obj map[string]any, path string) {
+	for _, name := range stringList(s["required"]) {
+		if _, ok := obj[name]; !ok {
+			v.addf(path, "missing required property %q", name)
+		}
+	}
+	props, _ := s["properties"].(map[string]any)
+	for _, name := range slices.Sorted(maps.Keys(obj)) {
+		childPath := path + "/" + escapePointer(name)
+		if prop, ok := props[name]; ok {
+			v.validate(prop, obj[name], childPath)
+			continue
+		}
+		switch ap := s["additionalProperties"].(type) {
+		case bool:
+			if !ap {
+				v.addf(path, "unexpected property %q", name)
+			}
+		case map[string]any:
+			v.validate(ap, obj[name], childPath)
+		}
+	}
+	if n, ok := schemaNumber(s["minProperties"]); ok && float64(len(obj)) < n {
+		v.addf(path, "has %d properties, want at least %v", len(obj), n)
+	}
+	if n, ok := schemaNumber(s["maxProperties"]); ok && float64(len(obj)) > n {
+		v.addf(path, "has %d properties, want at most %v", len(obj), n)
+	}
+}
+
+func (v *schemaValidator) validateArray(s map[string]any, arr []any, path string) {
+	prefix, _ := s["prefixItems"].([]any)
+	for i, item := range arr {
+		childPath := fmt.Sprintf("%s/%d", path, i)
+		if i < len(prefix) {
+			v.validate(prefix[i], item, childPath)
+		} else if items, ok := s["items"]; ok {
+			v.validate(items, item, childPath)
+		}
+	}
+	if n, ok := schemaNumber(s["minItems"]); ok && float64(len(arr)) < n {
+		v.addf(path, "has %d items, want at least %v", len(arr), n)
+	}
+	if n, ok := schemaNumber(s["maxItems"]); ok && float64(len(arr)) > n {
+		v.addf(path, "has %d items, want at most %v", len(arr), n)
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					v.addf(path, "items %d and %d are equal", i, j)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(s map[string]any, str, path string) {
+	n := float64(utf8.RuneCountInString(str))
+	if m, ok := schemaNumber(s["minLength"]); ok && n < m {
+		v.addf(path, "string is %v characters long, want at least %v", n, m)
+	}
+	if m, ok := schemaNumber(s["maxLength"]); ok && n > m {
+		v.addf(path, "string is %v characters long, want at most %v", n, m)
+	}
+	if p, ok := s["pattern"].(string); ok {
+		// Patterns Go cannot compile (e.g. lookarounds) are skipped rather than reported.
+		if re, err := regexp.Compile(p); err == nil && !re.MatchString(str) {
+			v.addf(path, "string does not match pattern %q", p)
+		}
+	}
+}
+
+func (v *schemaValidator) validateNumber(s map[string]any, n float64, path string) {
+	if m, ok := schemaNumber(s["minimum"]); ok && n < m {
+		v.addf(path, "%v is less than the minimum %v", n, m)
+	}
+	if m, ok := schemaNumber(s["maximum"]); ok && n > m {
+		v.addf(path, "%v is greater than the maximum %v", n, m)
+	}
+	if m, ok := schemaNumber(s["exclusiveMinimum"]); ok && n <= m {
+		v.addf(path, "%v is not greater than %v", n, m)
+	}
+	if m, ok := schemaNumber(s["exclusiveMaximum"]); ok && n >= m {
+		v.addf(path, "%v is not less than %v", n, m)
+	}
+	if m, ok := schemaNumber(s["multipleOf"]); ok && m > 0 {
+		if q := n / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.addf(path, "%v is not a multiple of %v", n, m)
+		}
+	}
+}
+
+// resolveRef resolves a local reference such as "#", "#/$defs/item" or "#/definitions/item".
+func (v *schemaValidator) resolveRef(ref string) (any, bool) {
+	if ref == "#" {
+		return v.root, true
+	}
+	rest, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, false
+	}
+	var node any = v.root
+	for part := range strings.SplitSeq(rest, "/") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		if node, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+func schemaTypes(t any) []string {
+	if s, ok := t.(string); ok {
+		return []string{s}
+	}
+	return stringList(t)
+}
+
+func stringList(v any) []string {
+	switch l := v.(type) {
+	case []string:
+		return l
+	case []any:
+		out := make([]string, 0, len(l))
+		for _, e := range l {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func matchesAnyType(types []string, value any) bool {
+	got := jsonTypeOf(value)
+	for _, t := range types {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonTypeOf(value any) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case json.Number:
+		if _, err := val.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func schemaNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonEqual compares two JSON values by their encoding, so that e.g. int and float64 schema constants compare equal.
+func jsonEqual(a, b any) bool {
+	return compactJSON(a) == compactJSON(b)
+}
+
+func compactJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,67 @@
+package sdkutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string", "minLength": 1},
+			"age":   map[string]any{"type": "integer", "minimum": 0},
+			"unit":  map[string]any{"enum": []any{"c", "f"}},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/tag"}},
+			"email": map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "null"}}},
+		},
+		"required":             []string{"name", "age"},
+		"additionalProperties": false,
+		"$defs": map[string]any{
+			"tag": map[string]any{"type": "string", "pattern": "^[a-z]+$"},
+		},
+	}
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{name: "Valid.", doc: `{"name":"ann","age":30,"unit":"c","tags":["go"],"email":null}`},
+		{
+			name: "WrongTypes.",
+			doc:  `{"name":"","age":1.5,"email":3}`,
+			want: []string{
+				"/age: expected integer, got number",
+				"/email: value matches none of the anyOf schemas",
+				"/name: string is 0 characters long, want at least 1",
+			},
+		},
+		{
+			name: "MissingAndExtra.",
+			doc:  `{"name":"ann","extra":true,"tags":["Go"],"unit":"k"}`,
+			want: []string{
+				`/: missing required property "age"`,
+				`/: unexpected property "extra"`,
+				`/tags/0: string does not match pattern "^[a-z]+$"`,
+				`/unit: value "k" is not one of the allowed values`,
+			},
+		},
+		{name: "NotAnObject.", doc: `[1]`, want: []string{"/: expected object, got array"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var doc any
+			if err := json.Unmarshal([]byte(tc.doc), &doc); err != nil {
+				t.Fatalf("Unmarshal() error = %v.", err)
+			}
+			if got := ValidateJSONSchema(schema, doc); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("ValidateJSONSchema() = %q, want %q.", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package sdkutil
+
+import (
+	"maps"
+	"slices"
+)
+
+// StrictJSONSchema returns a copy of schema adapted to OpenAI strict mode: every object sets additionalProperties to
+// false unless it already sets it, and lists all its properties as required, with properties that were optional
+// made nullable so the model can still omit a value.
+func StrictJSONSchema(schema map[string]any) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	out, _ := strictSchemaNode(schema).(map[string]any)
+	return out
+}
+
+func strictSchemaNode(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		out := maps.Clone(v)
+		for _, key := range []string{"items", "additionalProperties", "not"} {
+			if child, ok := out[key].(map[string]any); ok {
+				out[key] = strictSchemaNode(child)
+			}
+		}
+		for _, key := range []string{"anyOf", "oneOf", "allOf", "prefixItems"} {
+			if children, ok := out[key].([]any); ok {
+				out[key] = strictSchemaNode(children)
+			}
+		}
+		for _, key := range []string{"$defs", "definitions"} {
+			if defs, ok := out[key].(map[string]any); ok {
+				strictDefs := make(map[string]any, len(defs))
+				for name, def := range defs {
+					strictDefs[name] = strictSchemaNode(def)
+				}
+				out[key] = strictDefs
+			}
+		}
+		if props, ok := out["properties"].(map[string]any); ok {
+			strictObject(out, props)
+		} else if out["type"] == "object" {
+			if _, ok := out["additionalProperties"]; !ok {
+				out["additionalProperties"] = false
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			out[i] = strictSchemaNode(child)
+		}
+		return out
+	default:
+		return node
+	}
+}
+
+func strictObject(out, props map[string]any) {
+	required := map[string]bool{}
+	switch r := out["required"].(type) {
+	case []string:
+		for _, name := range r {
+			required[name] = true
+		}
+	case []any:
+		for _, name := range r {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := slices.Sorted(maps.Keys(props))
+	strictProps := make(map[string]any, len(props))
+	for _, name := range names {
+		prop := strictSchemaNode(props[name])
+		if !required[name] {
+			prop = nullableSchema(prop)
+		}
+		strictProps[name] = prop
+	}
+	out["properties"] = strictProps
+	out["required"] = names
+	if _, ok := out["additionalProperties"]; !ok {
+		out["additionalProperties"] = false
+	}
+}
+
+// nullableSchema allows null in addition to the values prop accepts.
+func nullableSchema(prop any) any {
+	m, ok := prop.(map[string]any)
+	if !ok {
+		return prop
+	}
+	switch t := m["type"].(type) {
+	case string:
+		if t != "null" {
+			m["type"] = []any{t, "null"}
+		}
+		return m
+	case []any:
+		if !slices.Contains(t, any("null")) {
+			m["type"] = append(slices.Clone(t), "null")
+		}
+		return m
+	case []string:
+		if !slices.Contains(t, "null") {
+			m["type"] = append(slices.Clone(t), "null")
+		}
+		return m
+	}
+	return map[string]any{"anyOf": []any{m, map[string]any{"type": "null"}}}
+}
@@ -0,0 +1,100 @@
+package sdkutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStrictJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		schema map[string]any
+		want   map[string]any
+	}{
+		{name: "Nil.", schema: nil, want: nil},
+		{
+			name: "OptionalBecomesNullable.",
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"city":  map[string]any{"type": "string"},
+					"units": map[string]any{"enum": []any{"c", "f"}},
+				},
+				"required": []any{"city"},
+			},
+			want: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"city": map[string]any{"type": "string"},
+					"units": map[string]any{"anyOf": []any{
+						map[string]any{"enum": []any{"c", "f"}},
+						map[string]any{"type": "null"},
+					}},
+				},
+				"required":             []string{"city", "units"},
+				"additionalProperties": false,
+			},
+		},
+		{
+			name: "NestedObjectsAndArrays.",
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"items": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type":       "object",
+							"properties": map[string]any{"id": map[string]any{"type": "integer"}},
+							"required":   []string{"id"},
+						},
+					},
+				},
+				"required":             []string{"items"},
+				"additionalProperties": true,
+			},
+			want: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"items": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type":                 "object",
+							"properties":           map[string]any{"id": map[string]any{"type": "integer"}},
+							"required":             []string{"id"},
+							"additionalProperties": false,
+						},
+					},
+				},
+				"required":             []string{"items"},
+				"additionalProperties": true,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := StrictJSONSchema(tc.schema); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("StrictJSONSchema() = %#v, want %#v.", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("InputUnchanged.", func(t *testing.T) {
+		t.Parallel()
+
+		in := map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"q": map[string]any{"type": "string"}},
+		}
+		_ = StrictJSONSchema(in)
+		if _, ok := in["additionalProperties"]; ok {
+			t.Fatalf("StrictJSONSchema() modified its input: %#v.", in)
+		}
+		if got := in["properties"].(map[string]any)["q"].(map[string]any)["type"]; got != "string" {
+			t.Fatalf("StrictJSONSchema() modified a property type to %v.", got)
+		}
+	})
+}
@@ -1,11 +1,17 @@
 package sdkutil
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/flexigpt/inference-go/internal/logutil"
 	"github.com/flexigpt/inference-go/spec"
@@ -14,84 +20,388 @@ import (
 const (
 	FlushInterval  = 256 * time.Millisecond
 	FlushChunkSize = 1024
+
+	PacingMaxLag   = 2 * time.Second
+	pacingInterval = 40 * time.Millisecond
 )
 
-// NewBufferedStreamer returns two functions:
-//   - write(chunk)  -> use this instead of onDataFlush
-//   - flush()       -> call once when streaming is finished
-func NewBufferedStreamer(
-	onDataFlush func(string) error,
-	flushInterval time.Duration,
-	maxSize int,
-) (write func(string) error, flush func()) {
-	if flushInterval <= 0 {
-		flushInterval = FlushInterval
-	}
-	if maxSize <= 0 {
-		maxSize = FlushChunkSize
-	}
-	var mu sync.Mutex
-	var buf strings.Builder
-	ticker := time.NewTicker(flushInterval)
-	done := make(chan struct{})
-
-	// Background goroutine time-based flush.
-	go func() {
-		defer Recover("buffered streamer background flush panic")
-
-		for {
-			select {
-			case <-ticker.C:
-				mu.Lock()
-				if buf.Len() > 0 {
-					data := buf.String()
-					buf.Reset()
-					mu.Unlock()
-					_ = onDataFlush(data)
+// StreamPosition locates a delta within the provider output.
+type StreamPosition struct {
+	OutputItemIndex int
+	ContentIndex    int
+}
+
+// StreamPipeline delivers stream events to a StreamHandler in the order the provider produced them.
+//
+// Text and thinking deltas are buffered per kind and position and flushed on size, on a timer, or as soon as any event
+// of a different kind or position is written. Only one kind is ever pending, so delivery order always matches
+// production order, and the handler is never invoked concurrently: the timer and the writers both deliver under the
+// pipeline lock. The handler therefore must not call back into the pipeline.
+//
+// With pacing enabled, buffered data is instead released by the timer at an even character rate, bounded by a maximum
+// lag behind the provider.
+//
+// Once the call context is canceled, the background timer stops, writes fail with the context error so that the
+// adapter stops reading the provider stream, and Close no longer waits for a paced backlog.
+type StreamPipeline struct {
+	ctx      context.Context
+	handler  spec.StreamHandler
+	provider spec.ProviderName
+	model    spec.ModelName
+	maxSize  int
+	boundary spec.StreamFlushBoundary
+	// paceCPS is the pacing rate in characters per second; 0 disables pacing.
+	paceCPS  int
+	maxLag   time.Duration
+	interval time.Duration
+
+	mu          sync.Mutex
+	pendingKind spec.StreamContentKind
+	pendingPos  StreamPosition
+	buf         strings.Builder
+	seq         int64
+	// paceCredit carries fractional characters between pacing ticks.
+	paceCredit float64
+	// flushErr is a handler error from a timer-based flush, surfaced on the next write.
+	flushErr error
+	// handlerFailed is set once the handler returned an error or panicked.
+	handlerFailed bool
+	closed        bool
+
+	ticker *time.Ticker
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewStreamPipeline returns a running pipeline. Close must be called once streaming is finished.
+func NewStreamPipeline(
+	ctx context.Context,
+	handler spec.StreamHandler,
+	provider spec.ProviderName,
+	model spec.ModelName,
+	cfg ResolvedStreamConfig,
+) *StreamPipeline {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = FlushInterval
+	}
+	if cfg.FlushChunkSize <= 0 {
+		cfg.FlushChunkSize = FlushChunkSize
+	}
+	p := &StreamPipeline{
+		ctx:      ctx,
+		handler:  handler,
+		provider: provider,
+		model:    model,
+		maxSize:  cfg.FlushChunkSize,
+		boundary: cfg.FlushBoundary,
+		paceCPS:  cfg.PacingCharsPerSecond,
+		maxLag:   cfg.PacingMaxLag,
+		interval: cfg.FlushInterval,
+		done:     make(chan struct{}),
+	}
+	if p.paceCPS > 0 {
+		p.interval = min(p.interval, pacingInterval)
+		if p.maxLag <= 0 {
+			p.maxLag = PacingMaxLag
+		}
+	}
+	p.ticker = time.NewTicker(p.interval)
+	go p.run()
+	return p
+}
+
+// WriteText buffers an assistant text delta.
+func (p *StreamPipeline) WriteText(pos StreamPosition, chunk string) error {
+	return p.write(spec.StreamContentKindText, pos, chunk)
+}
+
+// WriteThinking buffers a reasoning/thinking delta.
+func (p *StreamPipeline) WriteThinking(pos StreamPosition, chunk string) error {
+	return p.write(spec.StreamContentKindThinking, pos, chunk)
+}
+
+// Emit delivers a non-buffered event after flushing any pending buffered data.
+// Provider and Model are filled in if empty; SequenceNumber is always assigned by the pipeline.
+func (p *StreamPipeline) Emit(event spec.StreamEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.takeFlushErrLocked(); err != nil {
+		return err
+	}
+	if err := p.flushLocked(); err != nil {
+		return err
+	}
+	return p.deliverLocked(event)
+}
+
+// Close flushes pending data and stops the background timer. It is safe to call more than once.
+// With pacing enabled, Close first waits up to the maximum lag for the paced backlog to drain.
+func (p *StreamPipeline) Close() error {
+	var err error
+	p.once.Do(func() {
+		p.drainPaced()
+		close(p.done)
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.closed = true
+		err = errors.Join(p.takeFlushErrLocked(), p.flushLocked())
+	})
+	return err
+}
+
+// EmitUsage delivers a usage event after flushing pending data. It is a no-op if usage is nil or the handler failed.
+func (p *StreamPipeline) EmitUsage(usage *spec.Usage) {
+	if usage == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.handlerFailed || p.flushLocked() != nil {
+		return
+	}
+	_ = p.deliverLocked(spec.StreamEvent{Kind: spec.StreamContentKindUsage, Usage: usage})
+}
+
+// EmitError delivers a terminal error event after flushing pending data.
+// It is a no-op if e is nil or if the handler itself already failed, since the failure then originated downstream.
+func (p *StreamPipeline) EmitError(e *spec.Error) {
+	if e == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.handlerFailed {
+		return
+	}
+	if p.flushLocked() != nil {
+		return
+	}
+	_ = p.deliverLocked(spec.StreamEvent{
+		Kind: spec.StreamContentKindError,
+		Error: &spec.StreamErrorChunk{
+			Error:         e,
+			PartialOutput: p.seq > 0,
+			Retryable:     e.Retryable,
+		},
+	})
+}
+
+func (p *StreamPipeline) run() {
+	defer Recover("stream pipeline background flush panic")
+
+	for {
+		select {
+		case <-p.ticker.C:
+			p.mu.Lock()
+			if !p.closed && p.flushErr == nil {
+				if p.paceCPS > 0 {
+					p.flushErr = p.flushPacedLocked()
 				} else {
-					mu.Unlock()
+					p.flushErr = p.flushToBoundaryLocked()
 				}
-			case <-done:
-				ticker.Stop()
-				return
 			}
+			p.mu.Unlock()
+		case <-p.done:
+			p.ticker.Stop()
+			return
+		case <-p.ctx.Done():
+			p.ticker.Stop()
+			return
 		}
-	}()
+	}
+}
+
+func (p *StreamPipeline) write(kind spec.StreamContentKind, pos StreamPosition, chunk string) error {
+	if chunk == "" {
+		return nil
+	}
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.takeFlushErrLocked(); err != nil {
+		return err
+	}
+	if p.pendingKind != kind || p.pendingPos != pos {
+		if err := p.flushLocked(); err != nil {
+			return err
+		}
+		p.pendingKind = kind
+		p.pendingPos = pos
+	}
+	p.buf.WriteString(chunk)
+	if p.paceCPS == 0 && p.buf.Len() >= p.maxSize {
+		// Size-based flush.
+		return p.flushToBoundaryLocked()
+	}
+	return nil
+}
+
+// flushToBoundaryLocked flushes buffered data up to the last allowed boundary and keeps the remainder buffered.
+func (p *StreamPipeline) flushToBoundaryLocked() error {
+	if p.boundary == "" || p.boundary == spec.StreamFlushBoundaryNone || p.buf.Len() >= 4*p.maxSize {
+		return p.flushLocked()
+	}
+	return p.flushPrefixLocked(lastFlushBoundary(p.buf.String(), p.boundary))
+}
+
+// flushPacedLocked releases one pacing tick worth of characters, plus any backlog beyond the maximum lag.
+func (p *StreamPipeline) flushPacedLocked() error {
+	if p.buf.Len() == 0 {
+		p.paceCredit = 0
+		return nil
+	}
+	data := p.buf.String()
+	p.paceCredit += float64(p.paceCPS) * p.interval.Seconds()
+	n := int(p.paceCredit)
+	backlog := utf8.RuneCountInString(data)
+	if allowed := int(float64(p.paceCPS) * p.maxLag.Seconds()); backlog-n > allowed {
+		n = backlog - allowed
+	}
+	if n <= 0 {
+		return nil
+	}
+	p.paceCredit -= float64(n)
+	if p.paceCredit < 0 {
+		p.paceCredit = 0
+	}
+	return p.flushPrefixLocked(runePrefixLen(data, n))
+}
 
-	// Returns the wrapped write.
-	write = func(chunk string) error {
-		mu.Lock()
-		buf.WriteString(chunk)
-		over := buf.Len() >= maxSize
-		if over {
-			data := buf.String()
-			buf.Reset()
-			mu.Unlock()
-			// Size-based flush.
-			return onDataFlush(data)
+// drainPaced waits, up to the maximum lag, for the paced backlog to be released by the timer.
+func (p *StreamPipeline) drainPaced() {
+	if p.paceCPS == 0 {
+		return
+	}
+	deadline := time.Now().Add(p.maxLag)
+	for time.Now().Before(deadline) && p.ctx.Err() == nil {
+		p.mu.Lock()
+		pending := p.buf.Len() > 0 && p.flushErr == nil
+		p.mu.Unlock()
+		if !pending {
+			return
 		}
-		mu.Unlock()
+		time.Sleep(p.interval)
+	}
+}
+
+// flushPrefixLocked flushes the first cut bytes of the buffer and keeps the remainder buffered.
+func (p *StreamPipeline) flushPrefixLocked(cut int) error {
+	if cut <= 0 {
+		return nil
+	}
+	data := p.buf.String()
+	if cut >= len(data) {
+		return p.flushLocked()
+	}
+	if strings.TrimSpace(data[:cut]) == "" {
+		// Keep whitespace-only prefixes buffered so they are not dropped.
 		return nil
 	}
+	p.buf.Reset()
+	p.buf.WriteString(data[:cut])
+	err := p.flushLocked()
+	p.buf.WriteString(data[cut:])
+	return err
+}
 
-	var once sync.Once
-	// Flush everything, stop ticker.
-	flush = func() {
-		once.Do(func() {
-			close(done)
-			mu.Lock()
-			if buf.Len() > 0 {
-				data := buf.String()
-				buf.Reset()
-				mu.Unlock()
-				_ = onDataFlush(data)
-				return
-			}
-			mu.Unlock()
-		})
+func (p *StreamPipeline) takeFlushErrLocked() error {
+	err := p.flushErr
+	p.flushErr = nil
+	return err
+}
+
+func (p *StreamPipeline) flushLocked() error {
+	if p.buf.Len() == 0 {
+		return nil
+	}
+	data := p.buf.String()
+	kind := p.pendingKind
+	p.buf.Reset()
+	if strings.TrimSpace(data) == "" {
+		return nil
+	}
+
+	event := spec.StreamEvent{
+		Kind:            kind,
+		OutputItemIndex: p.pendingPos.OutputItemIndex,
+		ContentIndex:    p.pendingPos.ContentIndex,
+	}
+	switch kind {
+	case spec.StreamContentKindText:
+		event.Text = &spec.StreamTextChunk{Text: data}
+	case spec.StreamContentKindThinking:
+		event.Thinking = &spec.StreamThinkingChunk{Text: data}
+	default:
+		return nil
+	}
+	return p.deliverLocked(event)
+}
+
+func (p *StreamPipeline) deliverLocked(event spec.StreamEvent) error {
+	if event.Provider == "" {
+		event.Provider = p.provider
+	}
+	if event.Model == "" {
+		event.Model = p.model
+	}
+	p.seq++
+	event.SequenceNumber = p.seq
+	if err := SafeCallStreamHandler(p.handler, event); err != nil {
+		p.handlerFailed = true
+		return err
+	}
+	return nil
+}
+
+// IsRetryableError reports whether a request that failed with err (and, if known, the given HTTP status code) can be
+// retried safely. Caller cancellation is never retryable.
+func IsRetryableError(err error, statusCode int) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch {
+	case statusCode == http.StatusRequestTimeout,
+		statusCode == http.StatusConflict,
+		statusCode == http.StatusTooManyRequests,
+		statusCode >= http.StatusInternalServerError:
+		return true
+	case statusCode != 0:
+		return false
 	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// lastFlushBoundary returns the byte offset just past the last boundary in s, or -1 if there is none.
+func lastFlushBoundary(s string, boundary spec.StreamFlushBoundary) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c != ' ' && c != '\n' && c != '\t' && c != '\r' {
+			continue
+		}
+		if boundary != spec.StreamFlushBoundarySentence || c == '\n' {
+			return i + 1
+		}
+		if i > 0 && strings.IndexByte(".!?", s[i-1]) >= 0 {
+			return i + 1
+		}
+	}
+	return -1
+}
 
-	return write, flush
+// runePrefixLen returns the byte length of the first n runes of s.
+func runePrefixLen(s string, n int) int {
+	i := 0
+	for ; n > 0 && i < len(s); n-- {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return i
 }
 
 // SafeCallStreamHandler invokes the provided StreamHandler and converts any
@@ -124,6 +434,10 @@ func SafeCallStreamHandler(handler spec.StreamHandler, event spec.StreamEvent) (
 type ResolvedStreamConfig struct {
 	FlushInterval  time.Duration
 	FlushChunkSize int
+	FlushBoundary  spec.StreamFlushBoundary
+
+	PacingCharsPerSecond int
+	PacingMaxLag         time.Duration
 }
 
 // ResolveStreamConfig converts optional FetchCompletionOptions into a concrete
@@ -143,5 +457,13 @@ func ResolveStreamConfig(opts *spec.FetchCompletionOptions) ResolvedStreamConfig
 	if opts.StreamConfig.FlushChunkSize > 0 {
 		cfg.FlushChunkSize = opts.StreamConfig.FlushChunkSize
 	}
+	cfg.FlushBoundary = opts.StreamConfig.FlushBoundary
+	if opts.StreamConfig.PacingCharsPerSecond > 0 {
+		cfg.PacingCharsPerSecond = opts.StreamConfig.PacingCharsPerSecond
+		cfg.PacingMaxLag = PacingMaxLag
+		if opts.StreamConfig.PacingMaxLagMillis > 0 {
+			cfg.PacingMaxLag = time.Duration(opts.StreamConfig.PacingMaxLagMillis) * time.Millisecond
+		}
+	}
 	return cfg
 }
@@ -0,0 +1,200 @@
+package sdkutil
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestStreamPipelineOrder(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	var lastSeq int64
+	handler := func(ev spec.StreamEvent) error {
+		if ev.SequenceNumber != lastSeq+1 {
+			t.Errorf("SequenceNumber = %d, want = %d.", ev.SequenceNumber, lastSeq+1)
+		}
+		lastSeq = ev.SequenceNumber
+		switch ev.Kind {
+		case spec.StreamContentKindText:
+			got = append(got, "text:"+ev.Text.Text)
+		case spec.StreamContentKindThinking:
+			got = append(got, "thinking:"+ev.Thinking.Text)
+		case spec.StreamContentKindToolCall:
+			got = append(got, "tool:"+ev.ToolCall.Name)
+		default:
+			got = append(got, string(ev.Kind))
+		}
+		return nil
+	}
+
+	// A long interval so only kind switches and Close flush.
+	p := NewStreamPipeline(
+		t.Context(), handler, "p", "m", ResolvedStreamConfig{FlushInterval: time.Hour, FlushChunkSize: 1 << 20},
+	)
+	steps := []func() error{
+		func() error { return p.WriteThinking(StreamPosition{}, "a") },
+		func() error { return p.WriteThinking(StreamPosition{}, "b") },
+		func() error { return p.WriteText(StreamPosition{}, "c") },
+		func() error {
+			return p.Emit(spec.StreamEvent{Kind: spec.StreamContentKindToolCall, ToolCall: &spec.StreamToolCallChunk{Name: "t"}})
+		},
+		func() error { return p.WriteThinking(StreamPosition{}, "d") },
+		func() error { return p.WriteText(StreamPosition{}, "e") },
+		func() error { return p.WriteText(StreamPosition{}, "f") },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("write error = %v.", err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v.", err)
+	}
+
+	want := "thinking:ab,text:c,tool:t,thinking:d,text:ef"
+	if s := strings.Join(got, ","); s != want {
+		t.Fatalf("events = %q, want = %q.", s, want)
+	}
+}
+
+func TestLastFlushBoundary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		in       string
+		boundary spec.StreamFlushBoundary
+		want     int
+	}{
+		{name: "WordAfterLastSpace.", in: "hello wor", boundary: spec.StreamFlushBoundaryWord, want: 6},
+		{name: "WordNoBoundary.", in: "hello", boundary: spec.StreamFlushBoundaryWord, want: -1},
+		{name: "SentenceAfterPeriod.", in: "One. Two three", boundary: spec.StreamFlushBoundarySentence, want: 5},
+		{name: "SentenceAfterNewline.", in: "- item\n- it", boundary: spec.StreamFlushBoundarySentence, want: 7},
+		{name: "SentenceIgnoresPlainSpace.", in: "one two", boundary: spec.StreamFlushBoundarySentence, want: -1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := lastFlushBoundary(tc.in, tc.boundary); got != tc.want {
+				t.Fatalf("lastFlushBoundary(%q) = %d, want = %d.", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStreamPipelinePacing(t *testing.T) {
+	t.Parallel()
+
+	var got strings.Builder
+	events := 0
+	handler := func(ev spec.StreamEvent) error {
+		events++
+		got.WriteString(ev.Text.Text)
+		return nil
+	}
+
+	p := NewStreamPipeline(t.Context(), handler, "p", "m", ResolvedStreamConfig{
+		FlushInterval:        time.Hour,
+		PacingCharsPerSecond: 2000,
+		PacingMaxLag:         time.Second,
+	})
+	want := strings.Repeat("ab cd ", 50)
+	if err := p.WriteText(StreamPosition{}, want); err != nil {
+		t.Fatalf("WriteText() error = %v.", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v.", err)
+	}
+	if got.String() != want {
+		t.Fatalf("paced text = %q, want = %q.", got.String(), want)
+	}
+	if events < 2 {
+		t.Fatalf("paced events = %d, want more than one.", events)
+	}
+}
+
+func TestStreamPipelineCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	var got strings.Builder
+	p := NewStreamPipeline(ctx, func(ev spec.StreamEvent) error {
+		if ev.Kind == spec.StreamContentKindText {
+			got.WriteString(ev.Text.Text)
+		}
+		return nil
+	}, "p", "m", ResolvedStreamConfig{PacingCharsPerSecond: 1, PacingMaxLag: time.Hour})
+
+	if err := p.WriteText(StreamPosition{}, "hello"); err != nil {
+		t.Fatalf("WriteText() error = %v.", err)
+	}
+	cancel()
+	if err := p.WriteText(StreamPosition{}, " world"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WriteText() error = %v, want context.Canceled.", err)
+	}
+
+	// Close must not wait out the paced backlog once the call is canceled.
+	start := time.Now()
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v.", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Close() took %v after cancellation.", d)
+	}
+	if got.String() != "hello" {
+		t.Fatalf("delivered text = %q, want = %q.", got.String(), "hello")
+	}
+}
+
+func TestStreamPipelineConcurrentFlush(t *testing.T) {
+	t.Parallel()
+
+	var (
+		inHandler atomic.Int32
+		got       strings.Builder
+		lastSeq   int64
+	)
+	handler := func(ev spec.StreamEvent) error {
+		if inHandler.Add(1) != 1 {
+			t.Error("handler invoked concurrently.")
+		}
+		defer inHandler.Add(-1)
+		if ev.SequenceNumber != lastSeq+1 {
+			t.Errorf("SequenceNumber = %d, want = %d.", ev.SequenceNumber, lastSeq+1)
+		}
+		lastSeq = ev.SequenceNumber
+		if ev.Kind == spec.StreamContentKindText {
+			got.WriteString(ev.Text.Text)
+		}
+		// A slow handler widens the window in which the timer and the writer compete for the flush.
+		time.Sleep(10 * time.Microsecond)
+		return nil
+	}
+
+	// The timer fires constantly and small chunks force size-based flushes from the writer as well.
+	p := NewStreamPipeline(t.Context(), handler, "p", "m", ResolvedStreamConfig{
+		FlushInterval: time.Microsecond, FlushChunkSize: 7,
+	})
+	var want strings.Builder
+	for i := range 500 {
+		chunk := strconv.Itoa(i) + ","
+		want.WriteString(chunk)
+		if err := p.WriteText(StreamPosition{}, chunk); err != nil {
+			t.Fatalf("WriteText() error = %v.", err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v.", err)
+	}
+	if got.String() != want.String() {
+		t.Fatalf("delivered text is out of order or incomplete: got %d bytes, want %d.", got.Len(), want.Len())
+	}
+}
@@ -1,6 +1,7 @@
 package sdkutil
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -56,6 +57,138 @@ func FilterMessagesByTokenCount(
 	return filtered
 }
 
+// TruncateMessagesToTokenCount cuts the text of messages and tool outputs so that they fit maxTokenCount, for
+// prompts that still exceed it after FilterMessagesByTokenCount (which always keeps the newest input). Other inputs
+// and items are never cut; the budget they leave goes to the newest text first, and within an input, text items keep
+// their beginning. Text items cut to nothing are dropped, as are messages left without content. It returns an error
+// if the inputs that cannot be cut alone exceed maxTokenCount. messages is not modified.
+func TruncateMessagesToTokenCount(messages []spec.InputUnion, maxTokenCount int) ([]spec.InputUnion, error) {
+	total, text := 0, 0
+	for _, msg := range messages {
+		total += countHeuristicTokensInInputUnion(msg)
+		text += countTruncatableTokens(msg)
+	}
+	if total <= maxTokenCount {
+		return messages, nil
+	}
+	if fixed := total - text; fixed >= maxTokenCount {
+		return nil, fmt.Errorf(
+			"invalid request: inputs need about %d tokens that cannot be truncated, the prompt budget is %d",
+			fixed, maxTokenCount,
+		)
+	}
+
+	out := make([]spec.InputUnion, len(messages))
+	kept := len(messages)
+	remaining := maxTokenCount - (total - text)
+	for i := len(messages) - 1; i >= 0; i-- {
+		in := messages[i]
+		content, toolOut := truncatableParts(&in)
+		switch {
+		case content != nil && *content != nil:
+			c := **content
+			c.Contents = make([]spec.InputOutputContentItemUnion, 0, len(c.Contents))
+			for _, it := range (*content).Contents {
+				if it.Kind == spec.ContentItemKindText && it.TextItem != nil {
+					if it.TextItem = truncateTextItem(it.TextItem, &remaining); it.TextItem == nil {
+						continue
+					}
+				}
+				c.Contents = append(c.Contents, it)
+			}
+			if len(c.Contents) == 0 {
+				continue
+			}
+			*content = &c
+
+		case toolOut != nil && *toolOut != nil:
+			o := **toolOut
+			o.Contents = make([]spec.ToolOutputItemUnion, 0, len(o.Contents))
+			for _, it := range (*toolOut).Contents {
+				if it.Kind == spec.ContentItemKindText && it.TextItem != nil {
+					if it.TextItem = truncateTextItem(it.TextItem, &remaining); it.TextItem == nil {
+						continue
+					}
+				}
+				o.Contents = append(o.Contents, it)
+			}
+			*toolOut = &o
+		}
+		kept--
+		out[kept] = in
+	}
+
+	logutil.Debug("truncated messages to fit token budget", "approxTokens", total, "maxTokens", maxTokenCount)
+	return out[kept:], nil
+}
+
+// truncatableParts returns the field of in holding a message or a function/custom tool output, whose text items
+// TruncateMessagesToTokenCount may cut.
+func truncatableParts(in *spec.InputUnion) (**spec.InputOutputContent, **spec.ToolOutput) {
+	switch in.Kind {
+	case spec.InputKindInputMessage:
+		return &in.InputMessage, nil
+	case spec.InputKindOutputMessage:
+		return &in.OutputMessage, nil
+	case spec.InputKindFunctionToolOutput:
+		return nil, &in.FunctionToolOutput
+	case spec.InputKindCustomToolOutput:
+		return nil, &in.CustomToolOutput
+	default:
+		return nil, nil
+	}
+}
+
+// countTruncatableTokens counts the tokens of the text items TruncateMessagesToTokenCount may cut in in.
+func countTruncatableTokens(in spec.InputUnion) int {
+	content, toolOut := truncatableParts(&in)
+	total := 0
+	switch {
+	case content != nil && *content != nil:
+		for _, it := range (*content).Contents {
+			if it.Kind == spec.ContentItemKindText && it.TextItem != nil {
+				total += countHeuristicTokensInString(it.TextItem.Text)
+			}
+		}
+	case toolOut != nil && *toolOut != nil:
+		for _, it := range (*toolOut).Contents {
+			if it.Kind == spec.ContentItemKindText && it.TextItem != nil {
+				total += countHeuristicTokensInString(it.TextItem.Text)
+			}
+		}
+	}
+	return total
+}
+
+// truncateTextItem returns item cut to *remaining heuristic tokens, or nil if nothing is left, and takes its tokens
+// from *remaining.
+func truncateTextItem(item *spec.ContentItemText, remaining *int) *spec.ContentItemText {
+	n := countHeuristicTokensInString(item.Text)
+	if n <= *remaining {
+		*remaining -= n
+		return item
+	}
+	if *remaining <= 0 {
+		return nil
+	}
+	text := *item
+	text.Text = truncateToHeuristicTokens(text.Text, *remaining)
+	*remaining = 0
+	return &text
+}
+
+// truncateToHeuristicTokens returns the beginning of content holding at most n heuristic tokens.
+func truncateToHeuristicTokens(content string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	locs := tokenRegex.FindAllStringIndex(content, n+1)
+	if len(locs) <= n {
+		return content
+	}
+	return content[:locs[n-1][1]]
+}
+
 func pruneOrphanToolOutputs(msgs []spec.InputUnion) []spec.InputUnion {
 	if len(msgs) == 0 {
 		return msgs
@@ -74,6 +207,8 @@ func pruneOrphanToolOutputs(msgs []spec.InputUnion) []spec.InputUnion {
 			call = in.CustomToolCall
 		case spec.InputKindWebSearchToolCall:
 			call = in.WebSearchToolCall
+		case spec.InputKindCodeExecutionToolCall:
+			call = in.CodeExecutionToolCall
 		default:
 			continue
 		}
@@ -102,6 +237,10 @@ func pruneOrphanToolOutputs(msgs []spec.InputUnion) []spec.InputUnion {
 			toolOut = in.CustomToolOutput
 		case spec.InputKindWebSearchToolOutput:
 			toolOut = in.WebSearchToolOutput
+		case spec.InputKindCodeExecutionToolOutput:
+			toolOut = in.CodeExecutionToolOutput
+		case spec.InputKindComputerToolOutput:
+			toolOut = in.ComputerToolOutput
 		default:
 		}
 
@@ -150,6 +289,33 @@ func countHeuristicTokensInInputUnion(in spec.InputUnion) int {
 	case spec.InputKindWebSearchToolOutput:
 		return countTokensInToolOutput(in.WebSearchToolOutput)
 
+	case spec.InputKindCodeExecutionToolCall:
+		return countTokensInToolCall(in.CodeExecutionToolCall)
+
+	case spec.InputKindCodeExecutionToolOutput:
+		return countTokensInToolOutput(in.CodeExecutionToolOutput)
+
+	case spec.InputKindImageGenerationToolOutput:
+		return countTokensInToolOutput(in.ImageGenerationToolOutput)
+
+	case spec.InputKindCodeInterpreterToolCall:
+		return countTokensInToolCall(in.CodeInterpreterToolCall)
+
+	case spec.InputKindFileSearchToolCall:
+		return countTokensInToolCall(in.FileSearchToolCall)
+
+	case spec.InputKindComputerToolCall:
+		return countTokensInToolCall(in.ComputerToolCall)
+
+	case spec.InputKindComputerToolOutput:
+		return countTokensInToolOutput(in.ComputerToolOutput)
+
+	case spec.InputKindMCPToolCall:
+		return countTokensInToolCall(in.MCPToolCall)
+
+	case spec.InputKindMCPListTools:
+		return countTokensInToolCall(in.MCPListTools)
+
 	default:
 		return 0
 	}
@@ -226,6 +392,32 @@ func countTokensInToolCall(call *spec.ToolCall) int {
 		}
 	}
 
+	if ci := call.CodeInterpreterToolCallItem; ci != nil {
+		total += countHeuristicTokensInString(ci.Code)
+		for _, o := range ci.Outputs {
+			total += countHeuristicTokensInString(o.Logs)
+		}
+	}
+	if a := call.ComputerAction; a != nil {
+		total += countHeuristicTokensInString(a.Text)
+	}
+	if mcp := call.MCPToolCallItem; mcp != nil {
+		total += countHeuristicTokensInString(mcp.Output)
+		total += countHeuristicTokensInString(mcp.Error)
+		for _, t := range mcp.Tools {
+			total += countHeuristicTokensInString(t.Name)
+			total += countHeuristicTokensInString(t.Description)
+		}
+	}
+	if fs := call.FileSearchToolCallItem; fs != nil {
+		for _, q := range fs.Queries {
+			total += countHeuristicTokensInString(q)
+		}
+		for _, r := range fs.Results {
+			total += countHeuristicTokensInString(r.Text)
+		}
+	}
+
 	return total
 }
 
@@ -251,6 +443,14 @@ func countTokensInToolOutput(out *spec.ToolOutput) int {
 		// Error items are usually tiny; we ignore them.
 	}
 
+	// Code execution outputs: stdout and stderr.
+	for _, it := range out.CodeExecutionToolOutputItems {
+		if it.Kind == spec.CodeExecutionToolOutputKindResult && it.ResultItem != nil {
+			total += countHeuristicTokensInString(it.ResultItem.Stdout)
+			total += countHeuristicTokensInString(it.ResultItem.Stderr)
+		}
+	}
+
 	return total
 }
 
@@ -269,3 +469,8 @@ func countHeuristicTokensInString(content string) int {
 	matches := tokenRegex.FindAllString(content, -1)
 	return len(matches)
 }
+
+// CountHeuristicTokens estimates the number of tokens in content with the heuristic used for prompt filtering.
+func CountHeuristicTokens(content string) int {
+	return countHeuristicTokensInString(content)
+}
@@ -0,0 +1,107 @@
+package sdkutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestTruncateMessagesToTokenCount(t *testing.T) {
+	t.Parallel()
+
+	message := func(texts ...string) spec.InputUnion {
+		c := &spec.InputOutputContent{Role: spec.RoleUser}
+		for _, text := range texts {
+			c.Contents = append(c.Contents, spec.InputOutputContentItemUnion{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			})
+		}
+		return spec.InputUnion{Kind: spec.InputKindInputMessage, InputMessage: c}
+	}
+	toolOutput := func(text string) spec.InputUnion {
+		return spec.InputUnion{Kind: spec.InputKindFunctionToolOutput, FunctionToolOutput: &spec.ToolOutput{
+			Type:   spec.ToolTypeFunction,
+			CallID: "c1",
+			Contents: []spec.ToolOutputItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			}},
+		}}
+	}
+	toolCall := func(args string) spec.InputUnion {
+		return spec.InputUnion{Kind: spec.InputKindFunctionToolCall, FunctionToolCall: &spec.ToolCall{
+			Type: spec.ToolTypeFunction, CallID: "c1", Name: "f", Arguments: args,
+		}}
+	}
+	texts := func(ins []spec.InputUnion) [][]string {
+		var out [][]string
+		for _, in := range ins {
+			var items []string
+			switch {
+			case in.InputMessage != nil:
+				for _, it := range in.InputMessage.Contents {
+					items = append(items, it.TextItem.Text)
+				}
+			case in.FunctionToolOutput != nil:
+				for _, it := range in.FunctionToolOutput.Contents {
+					items = append(items, it.TextItem.Text)
+				}
+			default:
+				items = append(items, string(in.Kind))
+			}
+			out = append(out, items)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		inputs  []spec.InputUnion
+		max     int
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name:   "Fits.",
+			inputs: []spec.InputUnion{message("a b"), message("c d")},
+			max:    4,
+			want:   [][]string{{"a b"}, {"c d"}},
+		},
+		{
+			name:   "DropsEmptiedItemsAndMessages.",
+			inputs: []spec.InputUnion{message("a b"), message("c d", "e f g")},
+			max:    3,
+			want:   [][]string{{"c d", "e"}},
+		},
+		{
+			name:   "TruncatesToolOutputText.",
+			inputs: []spec.InputUnion{toolCall(""), toolOutput("a b c d e")},
+			max:    3,
+			want:   [][]string{{string(spec.InputKindFunctionToolCall)}, {"a b"}},
+		},
+		{
+			name:    "FixedInputsExceedBudget.",
+			inputs:  []spec.InputUnion{toolCall(`{"a":"b c d e"}`), message("hi")},
+			max:     3,
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := TruncateMessagesToTokenCount(tc.inputs, tc.max)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("TruncateMessagesToTokenCount() error = %v, wantErr = %v.", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if texts := texts(got); !reflect.DeepEqual(texts, tc.want) {
+				t.Fatalf("TruncateMessagesToTokenCount() = %q, want %q.", texts, tc.want)
+			}
+		})
+	}
+}
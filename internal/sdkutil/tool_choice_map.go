@@ -162,6 +162,30 @@ func sanitizeToolNameComponent(s string) string {
 	return out
 }
 
+// IsFormattedCustomTool reports whether ct is a custom tool sent as a provider custom tool (see ToolChoice.Format)
+// rather than as a function tool.
+func IsFormattedCustomTool(ct spec.ToolChoice) bool {
+	return ct.Type == spec.ToolTypeCustom && ct.Format != nil
+}
+
+// ValidateCustomToolFormat checks the format of a formatted custom tool.
+func ValidateCustomToolFormat(ct spec.ToolChoice) error {
+	switch f := ct.Format; f.Kind {
+	case spec.CustomToolFormatKindText:
+		return nil
+	case spec.CustomToolFormatKindGrammar:
+		if f.Syntax != spec.CustomToolGrammarSyntaxLark && f.Syntax != spec.CustomToolGrammarSyntaxRegex {
+			return fmt.Errorf("custom tool %q: unsupported grammar syntax %q", ct.Name, f.Syntax)
+		}
+		if strings.TrimSpace(f.Definition) == "" {
+			return fmt.Errorf("custom tool %q: grammar definition is required", ct.Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("custom tool %q: unknown format kind %q", ct.Name, f.Kind)
+	}
+}
+
 func ToolDescription(ct spec.ToolChoice) string {
 	if desc := strings.TrimSpace(ct.Description); desc != "" {
 		return desc
@@ -0,0 +1,36 @@
+package sdkutil
+
+import "github.com/flexigpt/inference-go/spec"
+
+// ToolOutputContents returns the content items to send to a provider for a tool output.
+//
+// For error outputs a leading text item describes the failure: "Error [code]: message" from ToolOutput.Error, or,
+// when the provider has no native error flag (nativeErrorFlag false) and no structured error is set, a generic
+// "Error: the tool call failed." so the model can tell a failure from a regular result.
+func ToolOutputContents(out *spec.ToolOutput, nativeErrorFlag bool) []spec.ToolOutputItemUnion {
+	if out == nil {
+		return nil
+	}
+	if !out.IsError {
+		return out.Contents
+	}
+	var header string
+	switch {
+	case out.Error != nil:
+		header = "Error"
+		if out.Error.Code != "" {
+			header += " [" + out.Error.Code + "]"
+		}
+		header += ": " + out.Error.Message
+	case !nativeErrorFlag:
+		header = "Error: the tool call failed."
+	default:
+		return out.Contents
+	}
+	items := make([]spec.ToolOutputItemUnion, 0, len(out.Contents)+1)
+	items = append(items, spec.ToolOutputItemUnion{
+		Kind:     spec.ContentItemKindText,
+		TextItem: &spec.ContentItemText{Text: header},
+	})
+	return append(items, out.Contents...)
+}
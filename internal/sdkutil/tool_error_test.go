@@ -0,0 +1,63 @@
+package sdkutil
+
+import (
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestToolOutputContents(t *testing.T) {
+	t.Parallel()
+
+	text := func(s string) spec.ToolOutputItemUnion {
+		return spec.ToolOutputItemUnion{Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: s}}
+	}
+	tests := []struct {
+		name   string
+		out    *spec.ToolOutput
+		native bool
+		want   []string
+	}{
+		{
+			name: "SuccessIsUnchanged.",
+			out:  &spec.ToolOutput{Contents: []spec.ToolOutputItemUnion{text("42")}},
+			want: []string{"42"},
+		},
+		{
+			name: "StructuredErrorIsRendered.",
+			out: &spec.ToolOutput{
+				IsError: true,
+				Error:   &spec.ToolError{Code: "timeout", Message: "took too long"},
+			},
+			native: true,
+			want:   []string{"Error [timeout]: took too long"},
+		},
+		{
+			name:   "PlainErrorWithNativeFlagIsUnchanged.",
+			out:    &spec.ToolOutput{IsError: true, Contents: []spec.ToolOutputItemUnion{text("bad")}},
+			native: true,
+			want:   []string{"bad"},
+		},
+		{
+			name: "PlainErrorWithoutNativeFlagIsMarked.",
+			out:  &spec.ToolOutput{IsError: true, Contents: []spec.ToolOutputItemUnion{text("bad")}},
+			want: []string{"Error: the tool call failed.", "bad"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := ToolOutputContents(tc.out, tc.native)
+			if len(got) != len(tc.want) {
+				t.Fatalf("ToolOutputContents() len = %d, want = %d.", len(got), len(tc.want))
+			}
+			for i := range got {
+				if got[i].TextItem.Text != tc.want[i] {
+					t.Fatalf("ToolOutputContents()[%d] = %q, want = %q.", i, got[i].TextItem.Text, tc.want[i])
+				}
+			}
+		})
+	}
+}
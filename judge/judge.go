@@ -0,0 +1,380 @@
+// Package judge provides an LLM-as-judge helper that scores candidate outputs
+// against a rubric using a configurable judge model.
+//
+// The judge is layered on top of FetchCompletion: it builds a normalized
+// request that asks the judge model for a JSON object matching a fixed schema
+// (via spec.OutputParam), parses the result and normalizes all scores into the
+// [0, 1] range.
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	DefaultScoreMin = 1
+	DefaultScoreMax = 10
+
+	judgeSchemaName = "judge_scores"
+)
+
+const defaultJudgeSystemPrompt = `You are an impartial evaluator.
+Score each candidate response against every rubric criterion using integer scores in the given range.
+Judge only what is asked by the rubric. Do not reward length. Give a short, concrete reason for every score.
+Respond only with JSON that matches the provided schema.`
+
+// CompletionFetcher is the subset of inference.ProviderSetAPI used by the judge.
+type CompletionFetcher interface {
+	FetchCompletion(
+		ctx context.Context,
+		provider spec.ProviderName,
+		fetchCompletionRequest *spec.FetchCompletionRequest,
+		opts *spec.FetchCompletionOptions,
+	) (*spec.FetchCompletionResponse, error)
+}
+
+// Criterion is a single rubric line.
+type Criterion struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Weight is the relative weight of this criterion in the overall score.
+	// Zero or negative values are treated as 1.
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// Rubric is the set of criteria a candidate is judged against.
+type Rubric struct {
+	Criteria []Criterion `json:"criteria"`
+
+	// ScoreMin and ScoreMax bound the raw integer scale the judge model uses.
+	// Zero values mean DefaultScoreMin/DefaultScoreMax.
+	ScoreMin int `json:"scoreMin,omitempty"`
+	ScoreMax int `json:"scoreMax,omitempty"`
+}
+
+// Config configures a Judge.
+type Config struct {
+	Provider spec.ProviderName `json:"provider"`
+
+	// ModelParam is the judge model configuration. Stream is ignored.
+	// If SystemPrompt is empty, a default evaluator prompt is used.
+	ModelParam spec.ModelParam `json:"modelParam"`
+
+	Rubric Rubric `json:"rubric"`
+}
+
+// Request is a single judging task.
+type Request struct {
+	// Task is the original prompt/instruction the candidates respond to.
+	Task string `json:"task"`
+	// Reference is an optional reference answer.
+	Reference string `json:"reference,omitempty"`
+	// Candidates are the outputs to score.
+	Candidates []string `json:"candidates"`
+}
+
+// CriterionScore is the judge's verdict for one criterion.
+type CriterionScore struct {
+	Name string `json:"name"`
+	// RawScore is the score on the rubric scale.
+	RawScore float64 `json:"rawScore"`
+	// Score is RawScore normalized into [0, 1].
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+// CandidateScore is the judge's verdict for one candidate.
+type CandidateScore struct {
+	Index int `json:"index"`
+	// Score is the weighted mean of the normalized criterion scores, in [0, 1].
+	Score    float64          `json:"score"`
+	Criteria []CriterionScore `json:"criteria"`
+	Reason   string           `json:"reason,omitempty"`
+}
+
+// Result is the output of a judging task.
+type Result struct {
+	Candidates []CandidateScore `json:"candidates"`
+	// Best is the index of the highest scoring candidate (first wins on ties).
+	Best int `json:"best"`
+
+	Usage *spec.Usage `json:"usage,omitempty"`
+}
+
+// Judge scores candidate outputs with a judge model.
+type Judge struct {
+	fetcher CompletionFetcher
+	config  Config
+}
+
+// New validates config and returns a Judge.
+func New(fetcher CompletionFetcher, config Config) (*Judge, error) {
+	if fetcher == nil {
+		return nil, errors.New("judge: nil completion fetcher")
+	}
+	if config.Provider == "" || config.ModelParam.Name == "" {
+		return nil, errors.New("judge: provider and model name are required")
+	}
+	if len(config.Rubric.Criteria) == 0 {
+		return nil, errors.New("judge: rubric must have at least one criterion")
+	}
+	seen := make(map[string]struct{}, len(config.Rubric.Criteria))
+	for _, c := range config.Rubric.Criteria {
+		n := strings.TrimSpace(c.Name)
+		if n == "" {
+			return nil, errors.New("judge: rubric criterion name is required")
+		}
+		if _, ok := seen[n]; ok {
+			return nil, fmt.Errorf("judge: duplicate rubric criterion %q", n)
+		}
+		seen[n] = struct{}{}
+	}
+	if config.Rubric.ScoreMin == 0 && config.Rubric.ScoreMax == 0 {
+		config.Rubric.ScoreMin = DefaultScoreMin
+		config.Rubric.ScoreMax = DefaultScoreMax
+	}
+	if config.Rubric.ScoreMax <= config.Rubric.ScoreMin {
+		return nil, errors.New("judge: rubric scoreMax must be greater than scoreMin")
+	}
+	return &Judge{fetcher: fetcher, config: config}, nil
+}
+
+// Score asks the judge model to score every candidate in req.
+func (j *Judge) Score(ctx context.Context, req Request) (*Result, error) {
+	if len(req.Candidates) == 0 {
+		return nil, errors.New("judge: no candidates to score")
+	}
+
+	mp := j.config.ModelParam
+	mp.Stream = false
+	if strings.TrimSpace(mp.SystemPrompt) == "" {
+		mp.SystemPrompt = defaultJudgeSystemPrompt
+	}
+	mp.OutputParam = &spec.OutputParam{
+		Format: &spec.OutputFormat{
+			Kind: spec.OutputFormatKindJSONSchema,
+			JSONSchemaParam: &spec.JSONSchemaParam{
+				Name:        judgeSchemaName,
+				Description: "Per candidate, per criterion scores with reasons.",
+				Schema:      judgeOutputSchema(),
+				Strict:      true,
+			},
+		},
+	}
+
+	fetchReq := &spec.FetchCompletionRequest{
+		ModelParam: mp,
+		Inputs: []spec.InputUnion{{
+			Kind: spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{
+				Role: spec.RoleUser,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: j.buildPrompt(req)},
+				}},
+			},
+		}},
+	}
+
+	resp, err := j.fetcher.FetchCompletion(ctx, j.config.Provider, fetchReq, nil)
+	if err != nil {
+		return nil, fmt.Errorf("judge: fetch completion: %w", err)
+	}
+	if resp == nil {
+		return nil, errors.New("judge: empty response")
+	}
+
+	raw := outputText(resp.Outputs)
+	if raw == "" {
+		return nil, errors.New("judge: judge model returned no text output")
+	}
+	res, err := j.parse(raw, len(req.Candidates))
+	if err != nil {
+		return nil, err
+	}
+	res.Usage = resp.Usage
+	return res, nil
+}
+
+func (j *Judge) buildPrompt(req Request) string {
+	var b strings.Builder
+	r := j.config.Rubric
+
+	b.WriteString("## Task\n")
+	b.WriteString(strings.TrimSpace(req.Task))
+	b.WriteString("\n\n")
+
+	if ref := strings.TrimSpace(req.Reference); ref != "" {
+		b.WriteString("## Reference answer\n")
+		b.WriteString(ref)
+		b.WriteString("\n\n")
+	}
+
+	fmt.Fprintf(&b, "## Rubric (integer scores from %d to %d)\n", r.ScoreMin, r.ScoreMax)
+	for _, c := range r.Criteria {
+		fmt.Fprintf(&b, "- %s: %s\n", strings.TrimSpace(c.Name), strings.TrimSpace(c.Description))
+	}
+	b.WriteString("\n")
+
+	for i, c := range req.Candidates {
+		fmt.Fprintf(&b, "## Candidate %d\n%s\n\n", i, c)
+	}
+
+	b.WriteString("Score every candidate (by index) on every rubric criterion (by name).")
+	return b.String()
+}
+
+type judgeOutput struct {
+	Candidates []struct {
+		Index    int    `json:"index"`
+		Reason   string `json:"reason"`
+		Criteria []struct {
+			Name   string  `json:"name"`
+			Score  float64 `json:"score"`
+			Reason string  `json:"reason"`
+		} `json:"criteria"`
+	} `json:"candidates"`
+}
+
+func (j *Judge) parse(raw string, numCandidates int) (*Result, error) {
+	var out judgeOutput
+	if err := json.Unmarshal([]byte(extractJSONObject(raw)), &out); err != nil {
+		return nil, fmt.Errorf("judge: invalid judge output: %w", err)
+	}
+
+	r := j.config.Rubric
+	weights := make(map[string]float64, len(r.Criteria))
+	for _, c := range r.Criteria {
+		w := c.Weight
+		if w <= 0 {
+			w = 1
+		}
+		weights[strings.TrimSpace(c.Name)] = w
+	}
+	scale := float64(r.ScoreMax - r.ScoreMin)
+
+	byIndex := make(map[int]CandidateScore, len(out.Candidates))
+	for _, c := range out.Candidates {
+		if c.Index < 0 || c.Index >= numCandidates {
+			continue
+		}
+		if _, dup := byIndex[c.Index]; dup {
+			return nil, fmt.Errorf("judge: judge scored candidate %d more than once", c.Index)
+		}
+		cs := CandidateScore{Index: c.Index, Reason: strings.TrimSpace(c.Reason)}
+		var sum, wsum float64
+		scored := make(map[string]struct{}, len(weights))
+		for _, cr := range c.Criteria {
+			name := strings.TrimSpace(cr.Name)
+			w, ok := weights[name]
+			if !ok {
+				// Criterion not in rubric; ignore.
+				continue
+			}
+			if _, dup := scored[name]; dup {
+				return nil, fmt.Errorf("judge: candidate %d scored criterion %q more than once", c.Index, name)
+			}
+			scored[name] = struct{}{}
+			raw := min(max(cr.Score, float64(r.ScoreMin)), float64(r.ScoreMax))
+			norm := (raw - float64(r.ScoreMin)) / scale
+			cs.Criteria = append(cs.Criteria, CriterionScore{
+				Name:     name,
+				RawScore: raw,
+				Score:    norm,
+				Reason:   strings.TrimSpace(cr.Reason),
+			})
+			sum += norm * w
+			wsum += w
+		}
+		if len(scored) != len(weights) {
+			// A missing criterion would otherwise silently drop out of the weighted mean.
+			return nil, fmt.Errorf(
+				"judge: candidate %d scored %d of %d rubric criteria", c.Index, len(scored), len(weights),
+			)
+		}
+		cs.Score = sum / wsum
+		byIndex[c.Index] = cs
+	}
+
+	if len(byIndex) != numCandidates {
+		return nil, fmt.Errorf("judge: judge scored %d of %d candidates", len(byIndex), numCandidates)
+	}
+
+	res := &Result{Candidates: make([]CandidateScore, 0, numCandidates)}
+	for i := range numCandidates {
+		cs := byIndex[i]
+		res.Candidates = append(res.Candidates, cs)
+		if cs.Score > res.Candidates[res.Best].Score {
+			res.Best = i
+		}
+	}
+	return res, nil
+}
+
+func judgeOutputSchema() map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []any{"candidates"},
+		"properties": map[string]any{
+			"candidates": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"required":             []any{"index", "criteria", "reason"},
+					"properties": map[string]any{
+						"index":  map[string]any{"type": "integer"},
+						"reason": map[string]any{"type": "string"},
+						"criteria": map[string]any{
+							"type": "array",
+							"items": map[string]any{
+								"type":                 "object",
+								"additionalProperties": false,
+								"required":             []any{"name", "score", "reason"},
+								"properties": map[string]any{
+									"name":   map[string]any{"type": "string"},
+									"score":  map[string]any{"type": "number"},
+									"reason": map[string]any{"type": "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// outputText concatenates all assistant text in outputs.
+func outputText(outputs []spec.OutputUnion) string {
+	var b strings.Builder
+	for _, o := range outputs {
+		if o.Kind != spec.OutputKindOutputMessage || o.OutputMessage == nil {
+			continue
+		}
+		for _, c := range o.OutputMessage.Contents {
+			if c.Kind == spec.ContentItemKindText && c.TextItem != nil {
+				b.WriteString(c.TextItem.Text)
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// extractJSONObject trims any surrounding prose/code fences a model may add
+// despite the schema request.
+func extractJSONObject(s string) string {
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end <= start {
+		return s
+	}
+	return s[start : end+1]
+}
@@ -0,0 +1,151 @@
+package judge
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type fakeFetcher struct {
+	text    string
+	lastReq *spec.FetchCompletionRequest
+}
+
+func (f *fakeFetcher) FetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	f.lastReq = req
+	return &spec.FetchCompletionResponse{
+		Outputs: []spec.OutputUnion{{
+			Kind: spec.OutputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{
+				Role: spec.RoleAssistant,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: f.text},
+				}},
+			},
+		}},
+	}, nil
+}
+
+func TestJudgeScore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		text     string
+		wantErr  bool
+		wantBest int
+		want     []float64
+	}{
+		{
+			name: "WeightedScoresAreNormalized.",
+			text: `{"candidates":[
+				{"index":0,"reason":"ok","criteria":[{"name":"accuracy","score":10,"reason":""},{"name":"style","score":1,"reason":""}]},
+				{"index":1,"reason":"ok","criteria":[{"name":"accuracy","score":1,"reason":""},{"name":"style","score":10,"reason":""}]}
+			]}`,
+			wantBest: 0,
+			want:     []float64{0.75, 0.25},
+		},
+		{
+			name: "CodeFencesAndOutOfRangeScoresAreTolerated.",
+			text: "```json\n" + `{"candidates":[
+				{"index":1,"reason":"","criteria":[{"name":"accuracy","score":42,"reason":""},{"name":"style","score":42,"reason":""}]},
+				{"index":0,"reason":"","criteria":[{"name":"accuracy","score":-3,"reason":""},{"name":"style","score":1,"reason":""}]}
+			]}` + "\n```",
+			wantBest: 1,
+			want:     []float64{0, 1},
+		},
+		{
+			name:    "MissingCandidateIsAnError.",
+			text:    `{"candidates":[{"index":0,"reason":"","criteria":[]}]}`,
+			wantErr: true,
+		},
+		{
+			name: "MissingCriterionIsAnError.",
+			text: `{"candidates":[
+				{"index":0,"reason":"","criteria":[{"name":"accuracy","score":10,"reason":""}]},
+				{"index":1,"reason":"","criteria":[{"name":"accuracy","score":1,"reason":""},{"name":"style","score":10,"reason":""}]}
+			]}`,
+			wantErr: true,
+		},
+		{
+			name: "UnknownCriterionDoesNotReplaceARubricCriterion.",
+			text: `{"candidates":[
+				{"index":0,"reason":"","criteria":[{"name":"accuracy","score":10,"reason":""},{"name":"tone","score":10,"reason":""}]},
+				{"index":1,"reason":"","criteria":[{"name":"accuracy","score":1,"reason":""},{"name":"style","score":10,"reason":""}]}
+			]}`,
+			wantErr: true,
+		},
+		{
+			name: "DuplicateCriterionIsAnError.",
+			text: `{"candidates":[
+				{"index":0,"reason":"","criteria":[{"name":"accuracy","score":10,"reason":""},
+					{"name":"accuracy","score":10,"reason":""},{"name":"style","score":1,"reason":""}]},
+				{"index":1,"reason":"","criteria":[{"name":"accuracy","score":1,"reason":""},{"name":"style","score":10,"reason":""}]}
+			]}`,
+			wantErr: true,
+		},
+		{
+			name: "DuplicateCandidateIsAnError.",
+			text: `{"candidates":[
+				{"index":0,"reason":"","criteria":[{"name":"accuracy","score":1,"reason":""},{"name":"style","score":1,"reason":""}]},
+				{"index":1,"reason":"","criteria":[{"name":"accuracy","score":1,"reason":""},{"name":"style","score":10,"reason":""}]},
+				{"index":0,"reason":"","criteria":[{"name":"accuracy","score":10,"reason":""},{"name":"style","score":10,"reason":""}]}
+			]}`,
+			wantErr: true,
+		},
+		{
+			name:    "InvalidJSONIsAnError.",
+			text:    `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &fakeFetcher{text: tc.text}
+			j, err := New(f, Config{
+				Provider:   "p",
+				ModelParam: spec.ModelParam{Name: "m", Stream: true},
+				Rubric: Rubric{Criteria: []Criterion{
+					{Name: "accuracy", Description: "Is it correct?", Weight: 3},
+					{Name: "style", Description: "Is it well written?"},
+				}},
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v.", err)
+			}
+
+			res, err := j.Score(t.Context(), Request{Task: "t", Candidates: []string{"a", "b"}})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Score() error = nil, want error.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Score() error = %v.", err)
+			}
+			if f.lastReq.ModelParam.Stream {
+				t.Fatalf("judge request must not stream.")
+			}
+			if res.Best != tc.wantBest {
+				t.Fatalf("Best = %d, want = %d.", res.Best, tc.wantBest)
+			}
+			for i, w := range tc.want {
+				if got := res.Candidates[i].Score; math.Abs(got-w) > 1e-9 {
+					t.Fatalf("candidate %d score = %v, want = %v.", i, got, w)
+				}
+			}
+		})
+	}
+}
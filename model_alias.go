@@ -0,0 +1,60 @@
+package inference
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// maxAliasDepth bounds alias chains such as "sonnet-latest" -> "sonnet-4" -> "claude-sonnet-4-20250514".
+const maxAliasDepth = 8
+
+// WithModelAliases installs a model alias table. See SetModelAliases.
+func WithModelAliases(aliases map[spec.ModelName]spec.ModelName) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		ps.modelAliases = maps.Clone(aliases)
+	}
+}
+
+// SetModelAliases replaces the model alias table. Aliases map a stable name ("sonnet-latest", "gpt-2025-06") to a
+// concrete model ID and may point at other aliases. They are resolved by FetchCompletion before the request is sent,
+// and the resolved name is recorded in FetchCompletionResponse.Metadata. A nil or empty table disables aliasing.
+func (ps *ProviderSetAPI) SetModelAliases(aliases map[spec.ModelName]spec.ModelName) error {
+	for alias := range aliases {
+		if alias == "" || aliases[alias] == "" {
+			return errors.New("invalid model alias: alias and target must be non-empty")
+		}
+		if _, err := resolveModelAlias(aliases, alias); err != nil {
+			return err
+		}
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.modelAliases = maps.Clone(aliases)
+	return nil
+}
+
+// ResolveModel returns the concrete model name for name. Names that are not aliases are returned unchanged.
+func (ps *ProviderSetAPI) ResolveModel(name spec.ModelName) (spec.ModelName, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return resolveModelAlias(ps.modelAliases, name)
+}
+
+func resolveModelAlias(aliases map[spec.ModelName]spec.ModelName, name spec.ModelName) (spec.ModelName, error) {
+	resolved := name
+	for range maxAliasDepth {
+		target, ok := aliases[resolved]
+		if !ok {
+			return resolved, nil
+		}
+		resolved = target
+	}
+	if _, ok := aliases[resolved]; !ok {
+		return resolved, nil
+	}
+	return "", fmt.Errorf("invalid model alias %q: cycle or chain longer than %d", name, maxAliasDepth)
+}
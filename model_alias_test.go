@@ -0,0 +1,39 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestResolveModel(t *testing.T) {
+	t.Parallel()
+
+	ps := newTestProviderSet(t, WithModelAliases(map[spec.ModelName]spec.ModelName{
+		"sonnet-latest": "sonnet-4",
+		"sonnet-4":      "claude-sonnet-4-20250514",
+	}))
+
+	tests := []struct {
+		name  string
+		model spec.ModelName
+		want  spec.ModelName
+	}{
+		{name: "Chain.", model: "sonnet-latest", want: "claude-sonnet-4-20250514"},
+		{name: "Direct.", model: "sonnet-4", want: "claude-sonnet-4-20250514"},
+		{name: "NotAlias.", model: "gpt-4o", want: "gpt-4o"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ps.ResolveModel(tc.model)
+			if err != nil || got != tc.want {
+				t.Fatalf("ResolveModel() = %q, %v, want = %q.", got, err, tc.want)
+			}
+		})
+	}
+
+	if err := ps.SetModelAliases(map[spec.ModelName]spec.ModelName{"a": "b", "b": "a"}); err == nil {
+		t.Fatalf("SetModelAliases() with a cycle error = nil, want = error.")
+	}
+}
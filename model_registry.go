@@ -0,0 +1,102 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/flexigpt/inference-go/events"
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type modelKey struct {
+	provider spec.ProviderName
+	model    spec.ModelName
+}
+
+// WithModelInfo registers model metadata. See SetModelInfo.
+func WithModelInfo(models ...spec.ModelInfo) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		for _, m := range models {
+			if m.Name != "" {
+				ps.modelInfos[modelKey{provider: m.Provider, model: m.Name}] = m
+			}
+		}
+	}
+}
+
+// WithDeprecationMode selects how FetchCompletion treats deprecated models. The default is DeprecationModeWarn.
+func WithDeprecationMode(mode spec.DeprecationMode) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		ps.deprecationMode = mode
+	}
+}
+
+// SetModelInfo adds or replaces registry entries, keyed by provider and model name. FetchCompletion checks the
+// resolved model against the registry and warns (or fails, see WithDeprecationMode) once it is past DeprecatedAt.
+func (ps *ProviderSetAPI) SetModelInfo(models ...spec.ModelInfo) error {
+	for _, m := range models {
+		if m.Name == "" {
+			return errors.New("invalid model info: name is required")
+		}
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for _, m := range models {
+		ps.modelInfos[modelKey{provider: m.Provider, model: m.Name}] = m
+	}
+	return nil
+}
+
+// ModelInfo returns the registry entry for a model, preferring a provider-specific entry.
+func (ps *ProviderSetAPI) ModelInfo(provider spec.ProviderName, model spec.ModelName) (spec.ModelInfo, bool) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return ps.lookupModelInfo(provider, model)
+}
+
+func (ps *ProviderSetAPI) lookupModelInfo(provider spec.ProviderName, model spec.ModelName) (spec.ModelInfo, bool) {
+	if m, ok := ps.modelInfos[modelKey{provider: provider, model: model}]; ok {
+		return m, true
+	}
+	m, ok := ps.modelInfos[modelKey{model: model}]
+	return m, ok
+}
+
+// checkDeprecation warns about, or rejects, a request for a deprecated model. Callers hold ps.mu.
+func (ps *ProviderSetAPI) checkDeprecation(
+	ctx context.Context,
+	provider spec.ProviderName,
+	model spec.ModelName,
+	now time.Time,
+) error {
+	if ps.deprecationMode == spec.DeprecationModeOff {
+		return nil
+	}
+	m, ok := ps.lookupModelInfo(provider, model)
+	if !ok || m.DeprecatedAt.IsZero() || now.Before(m.DeprecatedAt) {
+		return nil
+	}
+
+	depErr := &spec.ModelDeprecatedError{
+		Provider:     provider,
+		Model:        model,
+		DeprecatedAt: m.DeprecatedAt,
+		Replacement:  m.Replacement,
+	}
+	if ps.deprecationMode == spec.DeprecationModeError {
+		return depErr
+	}
+	logutil.WarnContext(
+		ctx,
+		"model is deprecated",
+		"provider", provider,
+		"model", model,
+		"deprecatedAt", m.DeprecatedAt.Format(time.DateOnly),
+		"replacement", m.Replacement,
+	)
+	ps.eventBus.Publish(events.Event{Kind: events.KindDeprecation, Provider: provider, Model: model, Err: depErr})
+	return nil
+}
@@ -0,0 +1,48 @@
+package inference
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestCheckDeprecation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	models := []spec.ModelInfo{
+		{Name: "old", DeprecatedAt: now.AddDate(0, -1, 0), Replacement: "new"},
+		{Name: "soon", DeprecatedAt: now.AddDate(0, 1, 0)},
+		{Name: "scoped", Provider: "other", DeprecatedAt: now.AddDate(0, -1, 0)},
+	}
+
+	tests := []struct {
+		name    string
+		mode    spec.DeprecationMode
+		model   spec.ModelName
+		wantErr bool
+	}{
+		{name: "WarnDoesNotFail.", model: "old"},
+		{name: "ErrorMode.", mode: spec.DeprecationModeError, model: "old", wantErr: true},
+		{name: "OffMode.", mode: spec.DeprecationModeOff, model: "old"},
+		{name: "NotYetDeprecated.", mode: spec.DeprecationModeError, model: "soon"},
+		{name: "OtherProviderEntry.", mode: spec.DeprecationModeError, model: "scoped"},
+		{name: "Unknown.", mode: spec.DeprecationModeError, model: "x"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ps := newTestProviderSet(t, WithModelInfo(models...), WithDeprecationMode(tc.mode))
+			err := ps.checkDeprecation(t.Context(), "p", tc.model, now)
+			var depErr *spec.ModelDeprecatedError
+			if got := errors.As(err, &depErr); got != tc.wantErr {
+				t.Fatalf("checkDeprecation() = %v, wantErr = %v.", err, tc.wantErr)
+			}
+			if tc.wantErr && depErr.Replacement != "new" {
+				t.Fatalf("Replacement = %q, want = %q.", depErr.Replacement, "new")
+			}
+		})
+	}
+}
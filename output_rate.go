@@ -0,0 +1,138 @@
+package inference
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// OutputRateConfig bounds the output token rate of streaming calls. See WithOutputTokenRate.
+type OutputRateConfig struct {
+	// TokensPerSecond is the output rate shared by all active streams of a provider. It must be > 0.
+	TokensPerSecond float64 `json:"tokensPerSecond"`
+	// Burst is the number of tokens that may be consumed at once before pacing starts. Zero means one second's
+	// worth.
+	Burst int `json:"burst,omitempty"`
+	// Models give models a rate of their own, separate from the provider's, e.g. for deployments with their own
+	// output TPM limit.
+	Models map[spec.ModelName]float64 `json:"models,omitempty"`
+}
+
+// WithOutputTokenRate paces the stream consumption of every provider added afterwards so that the output tokens of
+// all its active streams stay under TokensPerSecond. Stream events are held back before reaching the
+// StreamHandler, which in turn slows reading from the provider, so busy deployments do not hit output TPM limits
+// and abort streams with 429s. Tokens are estimated from the streamed text, thinking and tool arguments. Calls
+// without a StreamHandler are not paced.
+func WithOutputTokenRate(cfg OutputRateConfig) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		if cfg.TokensPerSecond <= 0 {
+			return
+		}
+		cfg.Models = maps.Clone(cfg.Models)
+		ps.outputRateConfig = &cfg
+	}
+}
+
+// outputRateLimiter holds the token buckets of one provider.
+type outputRateLimiter struct {
+	provider *tokenBucket
+	models   map[spec.ModelName]*tokenBucket
+}
+
+func newOutputRateLimiter(cfg OutputRateConfig) *outputRateLimiter {
+	l := &outputRateLimiter{
+		provider: newTokenBucket(cfg.TokensPerSecond, cfg.Burst),
+		models:   make(map[spec.ModelName]*tokenBucket, len(cfg.Models)),
+	}
+	for model, rate := range cfg.Models {
+		if rate > 0 {
+			l.models[model] = newTokenBucket(rate, cfg.Burst)
+		}
+	}
+	return l
+}
+
+// pace returns next wrapped to wait for the token budget of model before forwarding content events.
+func (l *outputRateLimiter) pace(
+	ctx context.Context,
+	model spec.ModelName,
+	next spec.StreamHandler,
+) spec.StreamHandler {
+	bucket := l.provider
+	if b, ok := l.models[model]; ok {
+		bucket = b
+	}
+	return func(event spec.StreamEvent) error {
+		if n := streamEventTokens(event); n > 0 {
+			if err := bucket.wait(ctx, n); err != nil {
+				return err
+			}
+		}
+		return next(event)
+	}
+}
+
+func streamEventTokens(event spec.StreamEvent) int {
+	switch {
+	case event.Text != nil:
+		return sdkutil.CountHeuristicTokens(event.Text.Text)
+	case event.Thinking != nil:
+		return sdkutil.CountHeuristicTokens(event.Thinking.Text)
+	case event.ToolCall != nil:
+		return sdkutil.CountHeuristicTokens(event.ToolCall.ArgumentsDelta)
+	default:
+		return 0
+	}
+}
+
+// tokenBucket is a token bucket that lets its balance go negative: a taker waits until its share of the debt has
+// been refilled, so concurrent takers are served in order and chunks larger than the burst still pass.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	b := float64(burst)
+	if b <= 0 {
+		b = rate
+	}
+	return &tokenBucket{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// wait takes n tokens, sleeping until the bucket has refilled enough to cover them or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// The tokens were never used; return them so an aborted stream does not delay the others.
+		b.mu.Lock()
+		b.tokens = min(b.burst, b.tokens+float64(n))
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,137 @@
+package inference
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestTokenBucketWait(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rate     float64
+		burst    int
+		takes    []int
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		{name: "WithinBurst.", rate: 100, burst: 10, takes: []int{5, 5}, maxDelay: 50 * time.Millisecond},
+		{name: "OverBurst.", rate: 100, burst: 10, takes: []int{10, 10}, minDelay: 80 * time.Millisecond},
+		{name: "ChunkLargerThanBurst.", rate: 100, burst: 5, takes: []int{15}, minDelay: 80 * time.Millisecond},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := newTokenBucket(tc.rate, tc.burst)
+			start := time.Now()
+			for _, n := range tc.takes {
+				if err := b.wait(t.Context(), n); err != nil {
+					t.Fatalf("wait() error = %v.", err)
+				}
+			}
+			elapsed := time.Since(start)
+			if elapsed < tc.minDelay || (tc.maxDelay > 0 && elapsed > tc.maxDelay) {
+				t.Fatalf("wait() took %v, want between %v and %v.", elapsed, tc.minDelay, tc.maxDelay)
+			}
+		})
+	}
+
+	t.Run("Canceled.", func(t *testing.T) {
+		t.Parallel()
+
+		b := newTokenBucket(1, 1)
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		if err := b.wait(ctx, 100); err == nil {
+			t.Fatalf("wait() with a canceled context succeeded, want an error.")
+		}
+	})
+
+	t.Run("CanceledWaiterIsRefunded.", func(t *testing.T) {
+		t.Parallel()
+
+		b := newTokenBucket(100, 10)
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		// Without a refund this would leave ten seconds of debt.
+		if err := b.wait(ctx, 1000); err == nil {
+			t.Fatalf("wait() with a canceled context succeeded, want an error.")
+		}
+		start := time.Now()
+		if err := b.wait(t.Context(), 5); err != nil {
+			t.Fatalf("wait() error = %v.", err)
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Fatalf("wait() after a canceled waiter took %v, want no delay.", elapsed)
+		}
+	})
+}
+
+func TestFetchCompletionOutputTokenRate(t *testing.T) {
+	t.Parallel()
+
+	stream := `{"model":"m","message":{"role":"assistant","content":"one two three"},"done":false}` + "\n" +
+		`{"model":"m","message":{"role":"assistant","content":" four five six"},"done":false}` + "\n" +
+		`{"model":"m","message":{"role":"assistant","content":" seven eight"},"done":true,"done_reason":"stop"}` + "\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(stream))
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name     string
+		model    spec.ModelName
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		// 8 tokens at 40 tokens/s with a burst of 1 take at least 175ms.
+		{name: "ProviderRate.", model: "m", minDelay: 150 * time.Millisecond},
+		{name: "ModelRate.", model: "fast", maxDelay: 120 * time.Millisecond},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ps := newTestProviderSet(t, WithOutputTokenRate(OutputRateConfig{
+				TokensPerSecond: 40,
+				Burst:           1,
+				Models:          map[spec.ModelName]float64{"fast": 1e6},
+			}))
+			addTestProvider(t, ps, "ollama", &AddProviderConfig{
+				SDKType: spec.ProviderSDKTypeOllama,
+				Origin:  srv.URL,
+			})
+
+			var text strings.Builder
+			start := time.Now()
+			_, err := ps.FetchCompletion(t.Context(), "ollama", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: tc.model, Stream: true},
+				Inputs:     userInputs("count"),
+			}, &spec.FetchCompletionOptions{StreamHandler: func(event spec.StreamEvent) error {
+				if event.Text != nil {
+					text.WriteString(event.Text.Text)
+				}
+				return nil
+			}})
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			elapsed := time.Since(start)
+			if elapsed < tc.minDelay || (tc.maxDelay > 0 && elapsed > tc.maxDelay) {
+				t.Fatalf("FetchCompletion() took %v, want between %v and %v.", elapsed, tc.minDelay, tc.maxDelay)
+			}
+			if got := text.String(); got != "one two three four five six seven eight" {
+				t.Fatalf("streamed text = %q, want all of it.", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package inference
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// TestFetchCompletionPartialOutput checks the error contract of every adapter: a failed call returns a response
+// whose Error mirrors the error, with the text streamed before the failure salvaged and Partial set.
+func TestFetchCompletionPartialOutput(t *testing.T) {
+	t.Parallel()
+
+	sse := func(event, data string) string {
+		if event == "" {
+			return "data: " + data + "\n\n"
+		}
+		return "event: " + event + "\ndata: " + data + "\n\n"
+	}
+	adapters := []struct {
+		name      string
+		config    AddProviderConfig
+		errorBody string
+		stream    string
+	}{
+		{
+			name: "OpenAIChat.",
+			config: AddProviderConfig{
+				SDKType: spec.ProviderSDKTypeOpenAIChatCompletions, ChatCompletionPathPrefix: "/chat/completions",
+			},
+			errorBody: `{"error":{"message":"bad request","type":"invalid_request_error"}}`,
+			stream: sse("", `{"id":"c1","object":"chat.completion.chunk","created":0,"model":"m",`+
+				`"choices":[{"index":0,"delta":{"role":"assistant","content":"Partial answer"},`+
+				`"finish_reason":null}]}`),
+		},
+		{
+			name:      "OpenAIResponses.",
+			config:    AddProviderConfig{SDKType: spec.ProviderSDKTypeOpenAIResponses},
+			errorBody: `{"error":{"message":"bad request","type":"invalid_request_error"}}`,
+			stream: sse("", `{"type":"response.output_text.delta","sequence_number":1,"item_id":"m1",`+
+				`"output_index":0,"content_index":0,"delta":"Partial answer"}`),
+		},
+		{
+			name: "Anthropic.",
+			config: AddProviderConfig{
+				SDKType:                  spec.ProviderSDKTypeAnthropic,
+				ChatCompletionPathPrefix: spec.DefaultAnthropicChatCompletionPrefix,
+			},
+			errorBody: `{"type":"error","error":{"type":"invalid_request_error","message":"bad request"}}`,
+			stream: sse("message_start", `{"type":"message_start","message":{"id":"msg1","type":"message",`+
+				`"role":"assistant","model":"m","content":[],"usage":{"input_tokens":1,"output_tokens":1}}}`) +
+				sse("content_block_start", `{"type":"content_block_start","index":0,`+
+					`"content_block":{"type":"text","text":""}}`) +
+				sse("content_block_delta", `{"type":"content_block_delta","index":0,`+
+					`"delta":{"type":"text_delta","text":"Partial answer"}}`),
+		},
+		{
+			name:      "Ollama.",
+			config:    AddProviderConfig{SDKType: spec.ProviderSDKTypeOllama},
+			errorBody: `{"error":"bad request"}`,
+			stream:    `{"model":"m","message":{"role":"assistant","content":"Partial answer"},"done":false}` + "\n",
+		},
+		{
+			name: "Cohere.",
+			config: AddProviderConfig{
+				SDKType: spec.ProviderSDKTypeCohere, ChatCompletionPathPrefix: spec.DefaultCohereChatPrefix,
+			},
+			errorBody: `{"message":"bad request"}`,
+			stream: sse("content-start", `{"type":"content-start","index":0,`+
+				`"delta":{"message":{"content":{"type":"text","text":""}}}}`) +
+				sse("content-delta", `{"type":"content-delta","index":0,`+
+					`"delta":{"message":{"content":{"text":"Partial answer"}}}}`),
+		},
+	}
+
+	for _, a := range adapters {
+		t.Run(a.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				if !strings.Contains(string(b), `"stream":true`) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(a.errorBody))
+					return
+				}
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte(a.stream))
+				w.(http.Flusher).Flush()
+				// Drop the connection mid-stream.
+				panic(http.ErrAbortHandler)
+			}))
+			t.Cleanup(srv.Close)
+
+			ps := newTestProviderSet(t)
+			config := a.config
+			config.Origin = srv.URL
+			addTestProvider(t, ps, "p", &config)
+
+			for _, stream := range []bool{false, true} {
+				var opts *spec.FetchCompletionOptions
+				if stream {
+					opts = &spec.FetchCompletionOptions{StreamHandler: func(spec.StreamEvent) error { return nil }}
+				}
+				resp, err := ps.FetchCompletion(t.Context(), "p", &spec.FetchCompletionRequest{
+					ModelParam: spec.ModelParam{Name: "m", Stream: stream},
+					Inputs:     userInputs("Hello"),
+				}, opts)
+				if err == nil {
+					t.Fatalf("FetchCompletion(stream=%v) error = nil, want an error.", stream)
+				}
+				if resp == nil || resp.Error == nil || resp.Error.Message == "" || resp.Error.Provider != "p" {
+					t.Fatalf("FetchCompletion(stream=%v) response = %+v, want the error mirrored.", stream, resp)
+				}
+				if resp.Partial != stream {
+					t.Fatalf("FetchCompletion(stream=%v) Partial = %v, want %v.", stream, resp.Partial, stream)
+				}
+				if got := salvagedText(resp.Outputs); stream && got != "Partial answer" {
+					t.Fatalf("salvaged text = %q, want the text streamed before the failure.", got)
+				}
+			}
+		})
+	}
+}
+
+// salvagedText joins the text of all output messages.
+func salvagedText(outs []spec.OutputUnion) string {
+	var sb strings.Builder
+	for _, o := range outs {
+		if o.OutputMessage == nil {
+			continue
+		}
+		for _, c := range o.OutputMessage.Contents {
+			if c.TextItem != nil {
+				sb.WriteString(c.TextItem.Text)
+			}
+		}
+	}
+	return sb.String()
+}
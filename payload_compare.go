@@ -0,0 +1,104 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ProviderPayload is the converted request of one provider, as produced by a dry run.
+type ProviderPayload struct {
+	Provider spec.ProviderName    `json:"provider"`
+	SDKType  spec.ProviderSDKType `json:"sdkType"`
+	URL      string               `json:"url,omitempty"`
+	Body     map[string]any       `json:"body,omitempty"`
+	// Error is set when the request could not be converted for this provider.
+	Error string `json:"error,omitempty"`
+}
+
+// PayloadComparison lays out the payloads of one request for several providers side by side.
+type PayloadComparison struct {
+	Payloads []ProviderPayload `json:"payloads"`
+	// Fields maps every top-level body field to the providers whose payload sets it.
+	Fields map[string][]spec.ProviderName `json:"fields"`
+	// Differences lists the top-level fields that are not sent by every converted payload.
+	Differences []string `json:"differences,omitempty"`
+}
+
+// ComparePayloads converts req for every registered provider (or only the given ones) without any network call and
+// reports how the payloads differ. It is a debugging aid for provider-specific behavior: the adapters are asked for
+// their dry run payload directly, so no events are published and no admission slots are taken.
+func (ps *ProviderSetAPI) ComparePayloads(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	providers ...spec.ProviderName,
+) (*PayloadComparison, error) {
+	if req == nil {
+		return nil, errors.New("got empty fetch completion input")
+	}
+	if len(providers) == 0 {
+		ps.mu.RLock()
+		providers = slices.Sorted(maps.Keys(ps.providers))
+		ps.mu.RUnlock()
+	}
+
+	cmp := &PayloadComparison{Fields: map[string][]spec.ProviderName{}}
+	converted := 0
+	for _, name := range providers {
+		payload := ProviderPayload{Provider: name}
+		resp, err := ps.dryRun(ctx, name, req, &payload)
+		if err != nil {
+			payload.Error = err.Error()
+			cmp.Payloads = append(cmp.Payloads, payload)
+			continue
+		}
+		dd, _ := resp.DebugDetails.(map[string]any)
+		request, _ := dd["request"].(map[string]any)
+		payload.URL, _ = request["url"].(string)
+		payload.Body, _ = request["body"].(map[string]any)
+		for field := range payload.Body {
+			cmp.Fields[field] = append(cmp.Fields[field], name)
+		}
+		converted++
+		cmp.Payloads = append(cmp.Payloads, payload)
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(cmp.Fields)) {
+		if len(cmp.Fields[field]) != converted {
+			cmp.Differences = append(cmp.Differences, field)
+		}
+	}
+	return cmp, nil
+}
+
+// dryRun returns the dry run response of provider for req, and records the SDK type of provider in payload.
+func (ps *ProviderSetAPI) dryRun(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	payload *ProviderPayload,
+) (*spec.FetchCompletionResponse, error) {
+	ps.mu.RLock()
+	p, ok := ps.providers[provider]
+	resolvedModel, err := resolveModelAlias(ps.modelAliases, req.ModelParam.Name)
+	modelInfo, _ := ps.lookupModelInfo(provider, resolvedModel)
+	ps.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("invalid provider %s", provider)
+	}
+	if pi := p.GetProviderInfo(ctx); pi != nil {
+		payload.SDKType = pi.SDKType
+	}
+	if err != nil {
+		return nil, err
+	}
+	reqCopy, err := prepareRouteRequest(req, resolvedModel, modelInfo)
+	if err != nil {
+		return nil, err
+	}
+	return p.FetchCompletion(ctx, &reqCopy, &spec.FetchCompletionOptions{DryRun: true})
+}
@@ -0,0 +1,49 @@
+package inference
+
+import (
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/flexigpt/inference-go/events"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestComparePayloads(t *testing.T) {
+	t.Parallel()
+
+	bus := events.NewBus()
+	var published atomic.Int32
+	sub := bus.Subscribe(func(events.Event) { published.Add(1) }, events.SubscribeOptions{})
+	ps := newTestProviderSet(t, WithEventBus(bus))
+	for name, sdkType := range map[spec.ProviderName]spec.ProviderSDKType{
+		"anthropic": spec.ProviderSDKTypeAnthropic,
+		"openai":    spec.ProviderSDKTypeOpenAIChatCompletions,
+	} {
+		addTestProvider(t, ps, name, &AddProviderConfig{
+			SDKType: sdkType,
+			Origin:  "http://127.0.0.1:1",
+		})
+	}
+
+	cmp, err := ps.ComparePayloads(t.Context(), &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m", SystemPrompt: "Be brief."},
+		Inputs:     userInputs("hi"),
+	}, "anthropic", "openai", "missing")
+	if err != nil {
+		t.Fatalf("ComparePayloads() error = %v.", err)
+	}
+	if len(cmp.Payloads) != 3 || cmp.Payloads[2].Error == "" {
+		t.Fatalf("Payloads = %+v, want 3 with an error for the missing provider.", cmp.Payloads)
+	}
+	if got := cmp.Fields["messages"]; len(got) != 2 {
+		t.Fatalf("Fields[messages] = %v, want both providers.", got)
+	}
+	if !slices.Contains(cmp.Differences, "system") || slices.Contains(cmp.Differences, "messages") {
+		t.Fatalf("Differences = %v, want system but not messages.", cmp.Differences)
+	}
+	sub.Close()
+	if n := published.Load(); n != 0 {
+		t.Fatalf("published %d events, want none for a payload comparison.", n)
+	}
+}
@@ -0,0 +1,111 @@
+package inference
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// Pipeline bundles cross-cutting FetchCompletion settings under a name, e.g. "interactive", "batch" or
+// "high-accuracy". A call selects one with FetchCompletionOptions.Pipeline; anything the call sets itself takes
+// precedence over the pipeline.
+//
+// A pipeline has no caching setting: prompt caching is requested per input with CacheControl, and the only
+// response sharing is WithDedupeWindow, which NoDedupe opts out of.
+type Pipeline struct {
+	// Retries is the number of extra attempts on the requested provider and model, made one after another before
+	// any Fallbacks. With HedgeAfterMillis, only the last attempt is hedged with the first fallback.
+	Retries int `json:"retries,omitempty"`
+	// TimeoutSeconds is the request timeout of calls without ModelParam.Timeout.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// Priority is the admission class of calls without one.
+	Priority spec.PriorityClass `json:"priority,omitempty"`
+	// Fallbacks and HedgeAfterMillis route calls that have no fallbacks of their own.
+	Fallbacks        []spec.FallbackRoute `json:"fallbacks,omitempty"`
+	HedgeAfterMillis int                  `json:"hedgeAfterMillis,omitempty"`
+	// NoDedupe opts calls out of WithDedupeWindow, so each one reaches the provider.
+	NoDedupe bool `json:"noDedupe,omitempty"`
+	// Debugger replaces the provider's debugger for calls without a debugger of their own.
+	Debugger spec.CompletionDebugger `json:"-"`
+}
+
+// WithPipelines installs named pipelines. See SetPipelines; NewProviderSetAPI returns its validation error.
+func WithPipelines(pipelines map[string]Pipeline) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		if err := validatePipelines(pipelines); err != nil {
+			ps.optionErrs = append(ps.optionErrs, err)
+			return
+		}
+		ps.pipelines = maps.Clone(pipelines)
+	}
+}
+
+// SetPipelines replaces the named pipelines. Calls naming a pipeline that is not in the table fail.
+func (ps *ProviderSetAPI) SetPipelines(pipelines map[string]Pipeline) error {
+	if err := validatePipelines(pipelines); err != nil {
+		return err
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.pipelines = maps.Clone(pipelines)
+	return nil
+}
+
+func validatePipelines(pipelines map[string]Pipeline) error {
+	for name, p := range pipelines {
+		if name == "" {
+			return errors.New("invalid pipeline: name must be non-empty")
+		}
+		if p.Retries < 0 || p.TimeoutSeconds < 0 || p.HedgeAfterMillis < 0 {
+			return fmt.Errorf("invalid pipeline %q: negative retries, timeout or hedge delay", name)
+		}
+	}
+	return nil
+}
+
+// applyPipeline merges the pipeline selected by opts into copies of req and opts. It returns the inputs unchanged
+// when no pipeline is selected.
+func (ps *ProviderSetAPI) applyPipeline(
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionRequest, *spec.FetchCompletionOptions, Pipeline, error) {
+	if opts == nil || opts.Pipeline == "" {
+		return req, opts, Pipeline{}, nil
+	}
+	ps.mu.RLock()
+	p, ok := ps.pipelines[opts.Pipeline]
+	ps.mu.RUnlock()
+	if !ok {
+		return nil, nil, Pipeline{}, fmt.Errorf("unknown pipeline %q", opts.Pipeline)
+	}
+
+	if p.TimeoutSeconds > 0 && req.ModelParam.Timeout <= 0 {
+		reqCopy := *req
+		reqCopy.ModelParam.Timeout = p.TimeoutSeconds
+		req = &reqCopy
+	}
+
+	optsCopy := *opts
+	if optsCopy.Priority == "" {
+		optsCopy.Priority = p.Priority
+	}
+	if len(optsCopy.Fallbacks) == 0 {
+		optsCopy.Fallbacks = slices.Clone(p.Fallbacks)
+		if optsCopy.HedgeAfterMillis == 0 {
+			optsCopy.HedgeAfterMillis = p.HedgeAfterMillis
+		}
+	}
+	if p.Retries > 0 {
+		retry := spec.FallbackRoute{Provider: provider, Model: req.ModelParam.Name}
+		optsCopy.Fallbacks = append(slices.Repeat([]spec.FallbackRoute{retry}, p.Retries), optsCopy.Fallbacks...)
+	}
+	if optsCopy.Debugger == nil {
+		optsCopy.Debugger = p.Debugger
+	}
+	return req, &optsCopy, p, nil
+}
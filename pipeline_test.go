@@ -0,0 +1,216 @@
+package inference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/events"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionPipeline(t *testing.T) {
+	t.Parallel()
+
+	// The server fails the first request of each test, so a call needs a retry to succeed.
+	newServer := func(hits *atomic.Int32) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if hits.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"bad request"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"ok"},"done":true}` + "\n"))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	pipelines := map[string]Pipeline{
+		"batch":    {Priority: spec.PriorityBackground, TimeoutSeconds: 600},
+		"reliable": {Retries: 1},
+	}
+
+	tests := []struct {
+		name     string
+		pipeline string
+		wantErr  bool
+		wantHits int32
+		want     []events.Kind
+	}{
+		{name: "NoPipeline.", wantErr: true, wantHits: 1},
+		{name: "NoRetries.", pipeline: "batch", wantErr: true, wantHits: 1},
+		{name: "Retries.", pipeline: "reliable", wantHits: 2, want: []events.Kind{events.KindRetry}},
+		{name: "UnknownPipeline.", pipeline: "missing", wantErr: true, wantHits: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var hits atomic.Int32
+			srv := newServer(&hits)
+			bus := events.NewBus()
+			var got []events.Kind
+			sub := bus.Subscribe(func(ev events.Event) { got = append(got, ev.Kind) }, events.SubscribeOptions{
+				Kinds: []events.Kind{events.KindRetry, events.KindFallback},
+			})
+			ps := newTestProviderSet(t, WithPipelines(pipelines), WithEventBus(bus))
+			addTestProvider(t, ps, "ollama", &AddProviderConfig{
+				SDKType: spec.ProviderSDKTypeOllama,
+				Origin:  srv.URL,
+			})
+
+			_, err := ps.FetchCompletion(t.Context(), "ollama", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m"},
+				Inputs:     userInputs("hi"),
+			}, &spec.FetchCompletionOptions{Pipeline: tc.pipeline})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("FetchCompletion() error = %v, wantErr %v.", err, tc.wantErr)
+			}
+			if got := hits.Load(); got != tc.wantHits {
+				t.Fatalf("server hits = %d, want %d.", got, tc.wantHits)
+			}
+			sub.Close()
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("events = %v, want %v.", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFetchCompletionPipelineHedgedRetries(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, maxInFlight atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for cur := maxInFlight.Load(); n > cur && !maxInFlight.CompareAndSwap(cur, n); cur = maxInFlight.Load() {
+		}
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"bad request"}}`))
+	}))
+	t.Cleanup(primary.Close)
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(
+			`data: {"id":"c","object":"chat.completion.chunk","created":0,"model":"m",` +
+				`"choices":[{"index":0,"delta":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}` +
+				"\n\ndata: [DONE]\n\n",
+		))
+	}))
+	t.Cleanup(backup.Close)
+
+	ps := newTestProviderSet(t, WithPipelines(map[string]Pipeline{"hedged": {
+		Retries:          1,
+		Fallbacks:        []spec.FallbackRoute{{Provider: "backup"}},
+		HedgeAfterMillis: 50,
+	}}))
+	for name, origin := range map[spec.ProviderName]string{"primary": primary.URL, "backup": backup.URL} {
+		addTestProvider(t, ps, name, &AddProviderConfig{
+			SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+			Origin:                   origin,
+			ChatCompletionPathPrefix: spec.DefaultOpenAIChatCompletionsPrefix,
+		})
+	}
+
+	resp, err := ps.FetchCompletion(t.Context(), "primary", &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m", Stream: true},
+		Inputs:     userInputs("hi"),
+	}, &spec.FetchCompletionOptions{
+		Pipeline:      "hedged",
+		StreamHandler: func(spec.StreamEvent) error { return nil },
+	})
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	if resp.Metadata.Provider != "backup" {
+		t.Fatalf("provider = %s, want backup.", resp.Metadata.Provider)
+	}
+	if got := maxInFlight.Load(); got != 1 {
+		t.Fatalf("concurrent primary attempts = %d, want retries to run one at a time.", got)
+	}
+}
+
+func TestApplyPipeline(t *testing.T) {
+	t.Parallel()
+
+	ps := newTestProviderSet(t)
+	debugger := &countingDebugger{}
+	if err := ps.SetPipelines(map[string]Pipeline{"accurate": {
+		Retries:          1,
+		TimeoutSeconds:   120,
+		Priority:         spec.PriorityBackground,
+		Fallbacks:        []spec.FallbackRoute{{Provider: "backup"}},
+		HedgeAfterMillis: 500,
+		Debugger:         debugger,
+	}}); err != nil {
+		t.Fatalf("SetPipelines() error = %v.", err)
+	}
+	if err := ps.SetPipelines(map[string]Pipeline{"bad": {Retries: -1}}); err == nil {
+		t.Fatalf("SetPipelines() with negative retries succeeded, want an error.")
+	}
+	if _, err := NewProviderSetAPI(WithPipelines(map[string]Pipeline{"": {}})); err == nil {
+		t.Fatalf("NewProviderSetAPI() with an unnamed pipeline succeeded, want an error.")
+	}
+
+	tests := []struct {
+		name        string
+		timeout     int
+		opts        spec.FetchCompletionOptions
+		wantTimeout int
+		wantOpts    spec.FetchCompletionOptions
+	}{
+		{
+			name:        "PipelineDefaults.",
+			opts:        spec.FetchCompletionOptions{Pipeline: "accurate"},
+			wantTimeout: 120,
+			wantOpts: spec.FetchCompletionOptions{
+				Pipeline:         "accurate",
+				Priority:         spec.PriorityBackground,
+				Fallbacks:        []spec.FallbackRoute{{Provider: "p", Model: "m"}, {Provider: "backup"}},
+				HedgeAfterMillis: 500,
+				Debugger:         debugger,
+			},
+		},
+		{
+			name:    "CallSettingsWin.",
+			timeout: 30,
+			opts: spec.FetchCompletionOptions{
+				Pipeline:  "accurate",
+				Priority:  spec.PriorityInteractive,
+				Fallbacks: []spec.FallbackRoute{{Provider: "other"}},
+			},
+			wantTimeout: 30,
+			wantOpts: spec.FetchCompletionOptions{
+				Pipeline:  "accurate",
+				Priority:  spec.PriorityInteractive,
+				Fallbacks: []spec.FallbackRoute{{Provider: "p", Model: "m"}, {Provider: "other"}},
+				Debugger:  debugger,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &spec.FetchCompletionRequest{ModelParam: spec.ModelParam{Name: "m", Timeout: tc.timeout}}
+			gotReq, gotOpts, _, err := ps.applyPipeline("p", req, &tc.opts)
+			if err != nil {
+				t.Fatalf("applyPipeline() error = %v.", err)
+			}
+			if gotReq.ModelParam.Timeout != tc.wantTimeout || req.ModelParam.Timeout != tc.timeout {
+				t.Fatalf("Timeout = %d (original %d), want %d (original unchanged).",
+					gotReq.ModelParam.Timeout, req.ModelParam.Timeout, tc.wantTimeout)
+			}
+			if !reflect.DeepEqual(*gotOpts, tc.wantOpts) {
+				t.Fatalf("opts = %+v, want = %+v.", *gotOpts, tc.wantOpts)
+			}
+		})
+	}
+}
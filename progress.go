@@ -0,0 +1,108 @@
+package inference
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type progressKey struct{}
+
+// progressTracker invokes a ProgressHandler with the state of the current attempt whenever it changes and on a
+// timer while the attempt is in flight.
+type progressTracker struct {
+	mu    sync.Mutex
+	event spec.ProgressEvent
+
+	handler spec.ProgressHandler
+	start   time.Time
+	changed chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// startProgress starts progress reporting for a non-streaming call with a ProgressHandler. The returned stop
+// function must be called once the call returns; no event is delivered after it.
+func startProgress(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (context.Context, func()) {
+	if opts == nil || opts.ProgressHandler == nil || (req.ModelParam.Stream && opts.StreamHandler != nil) {
+		return ctx, func() {}
+	}
+	interval := time.Duration(opts.ProgressIntervalMillis) * time.Millisecond
+	if interval <= 0 {
+		interval = spec.DefaultProgressIntervalMillis * time.Millisecond
+	}
+	t := &progressTracker{
+		handler: opts.ProgressHandler,
+		start:   time.Now(),
+		changed: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	t.wg.Add(1)
+	go t.run(interval)
+	return context.WithValue(ctx, progressKey{}, t), func() {
+		close(t.done)
+		t.wg.Wait()
+	}
+}
+
+// reportProgress records the state of the current attempt, if ctx carries a progress tracker.
+func reportProgress(
+	ctx context.Context,
+	provider spec.ProviderName,
+	model spec.ModelName,
+	attempt int,
+	status spec.ProgressStatus,
+) {
+	t, _ := ctx.Value(progressKey{}).(*progressTracker)
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.event = spec.ProgressEvent{Provider: provider, Model: model, Attempt: attempt, Status: status}
+	t.mu.Unlock()
+	select {
+	case t.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (t *progressTracker) run(interval time.Duration) {
+	defer t.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.changed:
+			// Report the new state now and restart the interval from it.
+			ticker.Reset(interval)
+		case <-t.done:
+			return
+		}
+		t.mu.Lock()
+		event := t.event
+		t.mu.Unlock()
+		if event.Status == "" {
+			continue
+		}
+		event.Elapsed = time.Since(t.start)
+		t.call(event)
+	}
+}
+
+func (t *progressTracker) call(event spec.ProgressEvent) {
+	callProgressHandler(t.handler, event)
+}
+
+// callProgressHandler invokes handler, recovering from a panic in it.
+func callProgressHandler(handler spec.ProgressHandler, event spec.ProgressEvent) {
+	defer sdkutil.Recover("progress handler panic")
+	handler(event)
+}
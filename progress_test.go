@@ -0,0 +1,92 @@
+package inference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionProgress(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"ok"},"done":true}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "ollama", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOllama,
+		Origin:  srv.URL,
+	})
+
+	tests := []struct {
+		name       string
+		stream     bool
+		wantEvents bool
+	}{
+		{name: "NonStreaming.", wantEvents: true},
+		{name: "Streaming.", stream: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var (
+				mu  sync.Mutex
+				got []spec.ProgressEvent
+			)
+			opts := &spec.FetchCompletionOptions{
+				ProgressIntervalMillis: 50,
+				ProgressHandler: func(e spec.ProgressEvent) {
+					mu.Lock()
+					defer mu.Unlock()
+					got = append(got, e)
+				},
+			}
+			if tc.stream {
+				opts.StreamHandler = func(spec.StreamEvent) error { return nil }
+			}
+			if _, err := ps.FetchCompletion(t.Context(), "ollama", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m", Stream: tc.stream},
+				Inputs:     userInputs("hi"),
+			}, opts); err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			mu.Lock()
+			n := len(got)
+			events := append([]spec.ProgressEvent(nil), got...)
+			mu.Unlock()
+
+			if !tc.wantEvents {
+				if n != 0 {
+					t.Fatalf("progress events = %v, want none for a streaming call.", events)
+				}
+				return
+			}
+			if n < 2 {
+				t.Fatalf("progress events = %v, want periodic events.", events)
+			}
+			for i, e := range events {
+				if e.Provider != "ollama" || e.Model != "m" || e.Attempt != 1 ||
+					e.Status != spec.ProgressStatusInProgress {
+					t.Fatalf("event = %+v, want an in progress event for ollama/m.", e)
+				}
+				if i > 0 && e.Elapsed <= events[i-1].Elapsed {
+					t.Fatalf("Elapsed did not increase: %v.", events)
+				}
+			}
+			time.Sleep(120 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			if len(got) != n {
+				t.Fatalf("got %d progress events after FetchCompletion returned.", len(got)-n)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package inference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionProviderError(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"error":{"message":"This model's maximum context length is 8192 tokens.",` +
+		`"type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "openai", &AddProviderConfig{
+		SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+		Origin:                   srv.URL,
+		ChatCompletionPathPrefix: "/chat/completions",
+	})
+
+	tests := []struct {
+		name   string
+		stream bool
+	}{
+		{name: "NonStreaming."},
+		{name: "Streaming.", stream: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var streamErr *spec.StreamErrorChunk
+			var opts *spec.FetchCompletionOptions
+			if tc.stream {
+				opts = &spec.FetchCompletionOptions{StreamHandler: func(e spec.StreamEvent) error {
+					if e.Kind == spec.StreamContentKindError {
+						streamErr = e.Error
+					}
+					return nil
+				}}
+			}
+			resp, err := ps.FetchCompletion(t.Context(), "openai", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "gpt-4", Stream: tc.stream},
+				Inputs:     userInputs("Hello"),
+			}, opts)
+			if err == nil {
+				t.Fatal("FetchCompletion() error = nil, want the provider error.")
+			}
+			if resp == nil || resp.Error == nil {
+				t.Fatalf("FetchCompletion() response = %+v, want a response error.", resp)
+			}
+			got := resp.Error
+			if got.Code != spec.ErrorCodeContextLength || got.HTTPStatus != http.StatusBadRequest ||
+				got.Provider != "openai" || got.Retryable || !strings.Contains(got.RawProviderError, "param") {
+				t.Fatalf("Error = %+v, want a non retryable context length error from openai.", got)
+			}
+			if tc.stream && (streamErr == nil || streamErr.Error == nil || streamErr.Error.Code != got.Code) {
+				t.Fatalf("stream error event = %+v, want the same error.", streamErr)
+			}
+		})
+	}
+}
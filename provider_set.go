@@ -13,15 +13,22 @@ package inference
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/flexigpt/inference-go/events"
 	"github.com/flexigpt/inference-go/internal/anthropicsdk"
-
+	"github.com/flexigpt/inference-go/internal/coheresdk"
 	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/ollamasdk"
 	"github.com/flexigpt/inference-go/internal/openaichatsdk"
 	"github.com/flexigpt/inference-go/internal/openairesponsessdk"
 	"github.com/flexigpt/inference-go/internal/sdkutil"
@@ -38,6 +45,23 @@ type ProviderSetAPI struct {
 	providers          map[spec.ProviderName]spec.CompletionProvider
 	logger             *slog.Logger
 	debugClientBuilder DebugClientBuilder
+	eventBus           *events.Bus
+	modelAliases       map[spec.ModelName]spec.ModelName
+	modelInfos         map[modelKey]spec.ModelInfo
+	deprecationMode    spec.DeprecationMode
+	admissionConfig    *AdmissionConfig
+	admission          map[spec.ProviderName]*admissionQueue
+	dedupe             *dedupeGroup
+	httpClient         *http.Client
+	debuggers          map[spec.ProviderName]*swappableDebugger
+	pipelines          map[string]Pipeline
+	outputRateConfig   *OutputRateConfig
+	outputRates        map[spec.ProviderName]*outputRateLimiter
+	flags              FlagEvaluator
+	rollouts           []Rollout
+
+	// optionErrs collects the validation errors of ProviderSetOptions, returned by NewProviderSetAPI.
+	optionErrs []error
 }
 
 // ProviderSetOption configures optional behavior for ProviderSetAPI.
@@ -51,13 +75,29 @@ func WithLogger(logger *slog.Logger) ProviderSetOption {
 
 // WithDebugClientBuilder configures a CompletionDebugger factory. The builder
 // is invoked once per provider when it is added. Returning nil disables
-// debugging for that provider.
+// debugging for that provider. Use SetDebugClientBuilder to change it later.
 func WithDebugClientBuilder(builder DebugClientBuilder) ProviderSetOption {
 	return func(ps *ProviderSetAPI) {
 		ps.debugClientBuilder = builder
 	}
 }
 
+// WithHTTPClient sends the requests of every provider added afterwards through client, e.g. a client whose
+// Transport uses the browser's fetch API on js/wasm, or one with custom proxies and TLS settings.
+func WithHTTPClient(client *http.Client) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		ps.httpClient = client
+	}
+}
+
+// WithEventBus publishes request lifecycle events (request started, attempt, stream chunk, retry, fallback,
+// finished) of every FetchCompletion call to bus.
+func WithEventBus(bus *events.Bus) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		ps.eventBus = bus
+	}
+}
+
 // NewProviderSetAPI creates a new ProviderSet and installs the process-wide
 // logger used by this SDK. The logger is chosen via WithLoggerBuilder; if no
 // builder is provided or it returns nil, a no-op logger is used.
@@ -65,7 +105,11 @@ func NewProviderSetAPI(
 	opts ...ProviderSetOption,
 ) (*ProviderSetAPI, error) {
 	ps := &ProviderSetAPI{
-		providers: map[spec.ProviderName]spec.CompletionProvider{},
+		providers:   map[spec.ProviderName]spec.CompletionProvider{},
+		modelInfos:  map[modelKey]spec.ModelInfo{},
+		admission:   map[spec.ProviderName]*admissionQueue{},
+		debuggers:   map[spec.ProviderName]*swappableDebugger{},
+		outputRates: map[spec.ProviderName]*outputRateLimiter{},
 	}
 
 	for _, opt := range opts {
@@ -73,6 +117,9 @@ func NewProviderSetAPI(
 			opt(ps)
 		}
 	}
+	if err := errors.Join(ps.optionErrs...); err != nil {
+		return nil, err
+	}
 
 	if ps.logger != nil {
 		logutil.SetDefault(ps.logger)
@@ -89,6 +136,34 @@ type AddProviderConfig struct {
 	ChatCompletionPathPrefix string               `json:"chatCompletionPathPrefix"`
 	APIKeyHeaderKey          string               `json:"apiKeyHeaderKey"`
 	DefaultHeaders           map[string]string    `json:"defaultHeaders"`
+
+	// SSEStreamSchema configures streaming delta extraction for ProviderSDKTypeOpenAICompatibleSSE.
+	SSEStreamSchema *spec.SSEStreamSchema `json:"sseStreamSchema,omitempty"`
+	// Quirks adapt requests to backends with partial API support.
+	Quirks *spec.ProviderQuirks `json:"quirks,omitempty"`
+	// Capabilities describe what a ProviderSDKTypeOpenAICompatible server supports. Nil means
+	// spec.DefaultOpenAICompatibleCapabilities.
+	Capabilities *spec.OpenAICompatibleCapabilities `json:"capabilities,omitempty"`
+	// DeveloperRole overrides which models get the developer role. Nil keeps the built-in OpenAI defaults.
+	DeveloperRole *spec.DeveloperRolePolicy `json:"developerRole,omitempty"`
+	// Timeouts set per-model default request timeouts. ModelParam.Timeout still overrides them per request.
+	Timeouts *spec.ProviderTimeouts `json:"timeouts,omitempty"`
+	// OriginFailover configures fallback origins tried when Origin fails.
+	OriginFailover *spec.OriginFailover `json:"originFailover,omitempty"`
+	// Azure routes an OpenAI Chat Completions or Responses provider to an Azure OpenAI resource at Origin.
+	Azure *spec.AzureOpenAI `json:"azure,omitempty"`
+	// Vertex routes an Anthropic (Claude) or OpenAI Chat Completions (Gemini and others) provider to Google Vertex
+	// AI. Origin and ChatCompletionPathPrefix default to the Vertex endpoint of the region. It requires Credentials.
+	Vertex *spec.VertexAI `json:"vertex,omitempty"`
+	// Ollama sets keep_alive and num_ctx for a ProviderSDKTypeOllama provider. Origin and ChatCompletionPathPrefix
+	// default to a local server. Ollama providers are ready to use without SetProviderAPIKey.
+	Ollama *spec.OllamaOptions `json:"ollama,omitempty"`
+	// OpenRouter sets provider routing, fallback models, transforms and app attribution for a
+	// ProviderSDKTypeOpenRouter provider. Origin and ChatCompletionPathPrefix default to openrouter.ai.
+	OpenRouter *spec.OpenRouterOptions `json:"openRouter,omitempty"`
+	// Credentials supplies bearer tokens instead of an API key. A provider with Credentials is ready to use
+	// without SetProviderAPIKey.
+	Credentials spec.CredentialProvider `json:"-"`
 }
 
 func (ps *ProviderSetAPI) AddProvider(
@@ -96,7 +171,10 @@ func (ps *ProviderSetAPI) AddProvider(
 	provider spec.ProviderName,
 	config *AddProviderConfig,
 ) (spec.ProviderParam, error) {
-	if config == nil || provider == "" || config.Origin == "" {
+	if config == nil || provider == "" ||
+		(config.Origin == "" && config.Vertex == nil && config.SDKType != spec.ProviderSDKTypeOllama &&
+			config.SDKType != spec.ProviderSDKTypeOpenRouter && config.SDKType != spec.ProviderSDKTypePerplexity &&
+			config.SDKType != spec.ProviderSDKTypeFireworks) {
 		return spec.ProviderParam{}, errors.New("invalid params")
 	}
 
@@ -112,6 +190,33 @@ func (ps *ProviderSetAPI) AddProvider(
 	if ok := isProviderSDKTypeSupported(config.SDKType); !ok {
 		return spec.ProviderParam{}, errors.New("unsupported provider api type")
 	}
+	if err := config.DeveloperRole.Validate(); err != nil {
+		return spec.ProviderParam{}, err
+	}
+	if err := config.Timeouts.Validate(); err != nil {
+		return spec.ProviderParam{}, err
+	}
+	if err := config.OriginFailover.Validate(); err != nil {
+		return spec.ProviderParam{}, err
+	}
+	if err := config.Azure.Validate(); err != nil {
+		return spec.ProviderParam{}, err
+	}
+	if config.Azure != nil && config.SDKType == spec.ProviderSDKTypeAnthropic {
+		return spec.ProviderParam{}, errors.New("azure routing requires an OpenAI sdk type")
+	}
+	if err := config.Vertex.Validate(); err != nil {
+		return spec.ProviderParam{}, err
+	}
+	if config.Vertex != nil {
+		if config.Credentials == nil {
+			return spec.ProviderParam{}, errors.New("vertex ai requires credentials")
+		}
+		if config.SDKType != spec.ProviderSDKTypeAnthropic &&
+			config.SDKType != spec.ProviderSDKTypeOpenAIChatCompletions {
+			return spec.ProviderParam{}, errors.New("vertex ai supports the anthropic and openai chat sdk types")
+		}
+	}
 
 	providerInfo := spec.ProviderParam{
 		Name:                     provider,
@@ -122,17 +227,119 @@ func (ps *ProviderSetAPI) AddProvider(
 		APIKeyHeaderKey:          config.APIKeyHeaderKey,
 		DefaultHeaders:           sdkutil.CloneStringMap(config.DefaultHeaders),
 	}
-
-	var dbg spec.CompletionDebugger
-	if ps.debugClientBuilder != nil {
-		dbg = ps.debugClientBuilder(providerInfo)
+	if config.SSEStreamSchema != nil {
+		schema := *config.SSEStreamSchema
+		providerInfo.SSEStreamSchema = &schema
+	}
+	if config.Quirks != nil {
+		quirks := *config.Quirks
+		providerInfo.Quirks = &quirks
+	}
+	if config.Capabilities != nil {
+		capabilities := *config.Capabilities
+		providerInfo.Capabilities = &capabilities
+	}
+	if config.DeveloperRole != nil {
+		providerInfo.DeveloperRole = &spec.DeveloperRolePolicy{Models: slices.Clone(config.DeveloperRole.Models)}
+	}
+	if config.Timeouts != nil {
+		timeouts := *config.Timeouts
+		timeouts.Models = slices.Clone(timeouts.Models)
+		providerInfo.Timeouts = &timeouts
+	}
+	if config.OriginFailover != nil {
+		failover := *config.OriginFailover
+		failover.Origins = slices.Clone(failover.Origins)
+		providerInfo.OriginFailover = &failover
+	}
+	if config.Azure != nil {
+		azure := *config.Azure
+		azure.Deployments = maps.Clone(azure.Deployments)
+		providerInfo.Azure = &azure
+	}
+	if config.Vertex != nil {
+		vertex := *config.Vertex
+		providerInfo.Vertex = &vertex
+		if providerInfo.Origin == "" {
+			providerInfo.Origin = vertex.Origin()
+		}
+		if providerInfo.ChatCompletionPathPrefix == "" {
+			providerInfo.ChatCompletionPathPrefix = spec.DefaultAnthropicChatCompletionPrefix
+			if config.SDKType == spec.ProviderSDKTypeOpenAIChatCompletions {
+				providerInfo.ChatCompletionPathPrefix = vertex.OpenAIChatCompletionsPrefix()
+			}
+		}
+	}
+	if config.SDKType == spec.ProviderSDKTypeOllama {
+		if config.Ollama != nil {
+			ollama := *config.Ollama
+			providerInfo.Ollama = &ollama
+		}
+		if providerInfo.Origin == "" {
+			providerInfo.Origin = spec.DefaultOllamaOrigin
+		}
+		if providerInfo.ChatCompletionPathPrefix == "" {
+			providerInfo.ChatCompletionPathPrefix = spec.DefaultOllamaChatPrefix
+		}
+	}
+	if config.SDKType == spec.ProviderSDKTypeOpenRouter {
+		if config.OpenRouter != nil {
+			openRouter := *config.OpenRouter
+			if p := openRouter.Provider; p != nil {
+				prefs := *p
+				prefs.Order = slices.Clone(p.Order)
+				prefs.Only = slices.Clone(p.Only)
+				prefs.Ignore = slices.Clone(p.Ignore)
+				openRouter.Provider = &prefs
+			}
+			openRouter.FallbackModels = slices.Clone(openRouter.FallbackModels)
+			openRouter.Transforms = slices.Clone(openRouter.Transforms)
+			providerInfo.OpenRouter = &openRouter
+		}
+		if providerInfo.Origin == "" {
+			providerInfo.Origin = spec.DefaultOpenRouterOrigin
+		}
+		if providerInfo.ChatCompletionPathPrefix == "" {
+			providerInfo.ChatCompletionPathPrefix = spec.DefaultOpenRouterChatPrefix
+		}
+	}
+	if config.SDKType == spec.ProviderSDKTypePerplexity {
+		if providerInfo.Origin == "" {
+			providerInfo.Origin = spec.DefaultPerplexityOrigin
+		}
+		if providerInfo.ChatCompletionPathPrefix == "" {
+			providerInfo.ChatCompletionPathPrefix = spec.DefaultPerplexityChatPrefix
+		}
 	}
+	if config.SDKType == spec.ProviderSDKTypeFireworks {
+		if providerInfo.Origin == "" {
+			providerInfo.Origin = spec.DefaultFireworksOrigin
+		}
+		if providerInfo.ChatCompletionPathPrefix == "" {
+			providerInfo.ChatCompletionPathPrefix = spec.DefaultFireworksChatPrefix
+		}
+	}
+	providerInfo.Credentials = config.Credentials
+	providerInfo.HTTPClient = ps.httpClient
 
+	dbg := newSwappableDebugger(providerInfo, ps.debugClientBuilder)
 	cp, err := getProviderAPI(providerInfo, dbg)
 	if err != nil {
 		return spec.ProviderParam{}, err
 	}
+	if !requiresAPIKey(&providerInfo) {
+		if err := cp.InitLLM(ctx); err != nil {
+			return spec.ProviderParam{}, err
+		}
+	}
 	ps.providers[provider] = cp
+	ps.debuggers[provider] = dbg
+	if ps.admissionConfig != nil {
+		ps.admission[provider] = newAdmissionQueue(*ps.admissionConfig)
+	}
+	if ps.outputRateConfig != nil {
+		ps.outputRates[provider] = newOutputRateLimiter(*ps.outputRateConfig)
+	}
 
 	logutil.Info("add provider", "name", provider)
 
@@ -153,6 +360,9 @@ func (ps *ProviderSetAPI) DeleteProvider(
 		return errors.New("invalid provider: provider does not exist")
 	}
 	delete(ps.providers, provider)
+	delete(ps.admission, provider)
+	delete(ps.debuggers, provider)
+	delete(ps.outputRates, provider)
 	ps.mu.Unlock()
 
 	// Best-effort cleanup outside the lock.
@@ -186,13 +396,53 @@ func (ps *ProviderSetAPI) SetProviderAPIKey(
 	if err != nil {
 		return err
 	}
-	if apiKey == "" {
+	if apiKey == "" && requiresAPIKey(p.GetProviderInfo(ctx)) {
 		return p.DeInitLLM(ctx)
 	}
 	return p.InitLLM(ctx)
 }
 
-// FetchCompletion processes a completion request for a given provider.
+// requiresAPIKey reports whether a provider is unusable without an API key. Providers with Credentials, local
+// Ollama servers and generic OpenAI-compatible servers stay initialized when the key is cleared.
+func requiresAPIKey(pi *spec.ProviderParam) bool {
+	return pi != nil && pi.Credentials == nil && pi.SDKType != spec.ProviderSDKTypeOllama &&
+		pi.SDKType != spec.ProviderSDKTypeOpenAICompatible
+}
+
+// ProviderStatus summarizes a registered provider without its credentials.
+type ProviderStatus struct {
+	Name    spec.ProviderName    `json:"name"`
+	SDKType spec.ProviderSDKType `json:"sdkType"`
+	Origin  string               `json:"origin"`
+	// Configured reports whether the provider has the API key or credentials it needs to serve requests.
+	Configured bool `json:"configured"`
+}
+
+// ListProviders returns the status of all registered providers, sorted by name.
+func (ps *ProviderSetAPI) ListProviders(ctx context.Context) []ProviderStatus {
+	ps.mu.RLock()
+	providers := slices.Collect(maps.Values(ps.providers))
+	ps.mu.RUnlock()
+
+	out := make([]ProviderStatus, 0, len(providers))
+	for _, p := range providers {
+		pi := p.GetProviderInfo(ctx)
+		if pi == nil {
+			continue
+		}
+		out = append(out, ProviderStatus{
+			Name:       pi.Name,
+			SDKType:    pi.SDKType,
+			Origin:     pi.Origin,
+			Configured: p.IsConfigured(ctx),
+		})
+	}
+	slices.SortFunc(out, func(a, b ProviderStatus) int { return strings.Compare(string(a.Name), string(b.Name)) })
+	return out
+}
+
+// FetchCompletion processes a completion request for a given provider, falling back to opts.Fallbacks on failure
+// and applying opts.StopPatterns and the reasoning options to the output.
 func (ps *ProviderSetAPI) FetchCompletion(
 	ctx context.Context,
 	provider spec.ProviderName,
@@ -203,23 +453,335 @@ func (ps *ProviderSetAPI) FetchCompletion(
 		fetchCompletionRequest.ModelParam.Name == "" {
 		return nil, errors.New("got empty fetch completion input")
 	}
+	provider, fetchCompletionRequest = ps.applyRollouts(ctx, provider, fetchCompletionRequest)
+	fetchCompletionRequest, opts, pipeline, err := ps.applyPipeline(provider, fetchCompletionRequest, opts)
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := ps.dedupeKey(provider, fetchCompletionRequest, opts); ok && !pipeline.NoDedupe {
+		return ps.dedupe.do(ctx, key, func() (*spec.FetchCompletionResponse, error) {
+			return ps.fetchCompletion(ctx, provider, fetchCompletionRequest, opts)
+		})
+	}
+	return ps.fetchCompletion(ctx, provider, fetchCompletionRequest, opts)
+}
 
+func (ps *ProviderSetAPI) fetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	fetchCompletionRequest *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	if opts != nil && opts.Debugger != nil {
+		ctx = withRequestDebugger(ctx, opts.Debugger)
+	}
+	var done *doneStream
+	if opts != nil && opts.StreamHandler != nil && fetchCompletionRequest.ModelParam.Stream {
+		done = &doneStream{next: opts.StreamHandler}
+		optsCopy := *opts
+		optsCopy.StreamHandler = done.handle
+		opts = &optsCopy
+	}
+	var stop *stopMatcher
+	if opts != nil && len(opts.StopPatterns) > 0 {
+		var err error
+		if stop, err = newStopMatcher(opts.StopPatterns); err != nil {
+			return nil, err
+		}
+		optsCopy := *opts
+		if opts.StreamHandler != nil {
+			optsCopy.StreamHandler = stop.handler(opts.StreamHandler)
+		}
+		opts = &optsCopy
+	}
+	if opts != nil && opts.MaxThinkingChars > 0 && opts.StreamHandler != nil {
+		optsCopy := *opts
+		optsCopy.StreamHandler = capThinking(opts.StreamHandler, opts.MaxThinkingChars)
+		opts = &optsCopy
+	}
+
+	progressCtx, stopProgress := startProgress(ctx, fetchCompletionRequest, opts)
+	resp, err := ps.fetchWithFallbacks(progressCtx, provider, fetchCompletionRequest, opts)
+	stopProgress()
+	err = canceledError(ctx, provider, err)
+	if stop != nil {
+		resp, err = stop.finish(resp, err)
+	}
+	if resp != nil && opts != nil && opts.DropReasoning {
+		resp.Outputs = dropReasoning(resp.Outputs)
+		for i := range resp.Choices {
+			resp.Choices[i].Outputs = dropReasoning(resp.Choices[i].Outputs)
+		}
+	}
+	if resp != nil && len(resp.Choices) > 0 {
+		// Keep the first choice in sync with Outputs after stop patterns truncated it.
+		resp.Choices[0].Outputs = resp.Outputs
+	}
+	resp = sdkutil.FinalizeResponse(provider, resp, err)
+	if done != nil {
+		done.finish(resp, err)
+	}
+	return resp, err
+}
+
+// canceledError wraps err in a spec.CanceledError when the call failed because ctx was canceled.
+func canceledError(ctx context.Context, provider spec.ProviderName, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	var ce *spec.CanceledError
+	if errors.As(err, &ce) {
+		return err
+	}
+	return &spec.CanceledError{
+		Provider: provider,
+		Reason:   spec.CancelReasonOf(ctx),
+		Cause:    context.Cause(ctx),
+		Err:      err,
+	}
+}
+
+// adapterTimeoutError reports a deadline the adapter applied on its own (the
+// provider or model timeout) as a timeout cancellation; ctx itself is still
+// live, so canceledError would not classify it.
+func adapterTimeoutError(ctx context.Context, provider spec.ProviderName, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var ce *spec.CanceledError
+	if errors.As(err, &ce) {
+		return err
+	}
+	return &spec.CanceledError{
+		Provider: provider,
+		Reason:   spec.CancelReasonTimeout,
+		Cause:    context.DeadlineExceeded,
+		Err:      err,
+	}
+}
+
+// fetchWithFallbacks tries the provider, then opts.Fallbacks.
+func (ps *ProviderSetAPI) fetchWithFallbacks(
+	ctx context.Context,
+	provider spec.ProviderName,
+	fetchCompletionRequest *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	var requestID string
+	if ps.eventBus.Active() {
+		requestID = events.NewID()
+	}
+	routes := []spec.FallbackRoute{{Provider: provider, Model: fetchCompletionRequest.ModelParam.Name}}
+	if opts != nil {
+		routes = append(routes, opts.Fallbacks...)
+	}
+	if len(routes) == 1 {
+		return ps.fetchRoute(ctx, provider, fetchCompletionRequest, opts, requestID, 1)
+	}
+	for i := range routes {
+		if routes[i].Model == "" {
+			routes[i].Model = fetchCompletionRequest.ModelParam.Name
+		}
+	}
+
+	var stream *fallbackStream
+	if opts.StreamHandler != nil {
+		stream = &fallbackStream{next: opts.StreamHandler}
+		optsCopy := *opts
+		optsCopy.StreamHandler = stream.handle
+		opts = &optsCopy
+	}
+	hedgeDelay := time.Duration(opts.HedgeAfterMillis) * time.Millisecond
+	hedgeAt := -1
+	if hedgeDelay > 0 && stream != nil && fetchCompletionRequest.ModelParam.Stream {
+		// Retries of the same route stay sequential; the hedge pairs the last of them with the first real fallback.
+		for i := 0; i+1 < len(routes); i++ {
+			if routes[i+1] != routes[i] {
+				hedgeAt = i
+				break
+			}
+		}
+	}
+
+	var (
+		resp *spec.FetchCompletionResponse
+		err  error
+	)
+	for i := 0; i < len(routes); i++ {
+		if i == hedgeAt {
+			stream.setHold(i+2 < len(routes))
+			resp, err = ps.fetchHedged(
+				ctx, fetchCompletionRequest, opts, stream, routes[i:i+2], hedgeDelay, requestID, i+1,
+			)
+			// The hedged pair is done; any further fallback starts after the second route.
+			i++
+		} else {
+			if stream != nil {
+				stream.setHold(i < len(routes)-1)
+			}
+			resp, err = ps.fetchRoute(
+				ctx, routes[i].Provider, routeRequest(fetchCompletionRequest, routes[i]), opts, requestID, i+1,
+			)
+		}
+		if err == nil || i == len(routes)-1 || ctx.Err() != nil || (stream != nil && !stream.canRestart()) {
+			break
+		}
+
+		route, next := routes[i], routes[i+1]
+		if next == route {
+			logutil.Warn("fetch completion failed, retrying",
+				"provider", route.Provider, "model", route.Model, "attempt", i+1, "error", err)
+			ps.eventBus.Publish(events.Event{
+				Kind:      events.KindRetry,
+				RequestID: requestID,
+				Provider:  route.Provider,
+				Model:     route.Model,
+				Attempt:   i + 1,
+				Err:       err,
+			})
+		} else {
+			logutil.Warn("fetch completion failed, falling back",
+				"provider", route.Provider, "fallbackProvider", next.Provider, "fallbackModel", next.Model, "error", err)
+			ps.eventBus.Publish(events.Event{
+				Kind:             events.KindFallback,
+				RequestID:        requestID,
+				Provider:         route.Provider,
+				Model:            route.Model,
+				Attempt:          i + 1,
+				FallbackProvider: next.Provider,
+				FallbackModel:    next.Model,
+				Err:              err,
+			})
+		}
+		if stream != nil {
+			if serr := stream.switchRoute(route, next, err); serr != nil {
+				return resp, serr
+			}
+		}
+	}
+	if stream != nil {
+		stream.releaseHeld()
+	}
+	return resp, err
+}
+
+// routeRequest returns req with the route's model.
+func routeRequest(req *spec.FetchCompletionRequest, route spec.FallbackRoute) *spec.FetchCompletionRequest {
+	out := *req
+	out.ModelParam.Name = route.Model
+	return &out
+}
+
+// prepareRouteRequest returns a copy of req for the resolved model, with the locale hint and the prompt token budget
+// applied.
+func prepareRouteRequest(
+	req *spec.FetchCompletionRequest,
+	resolvedModel spec.ModelName,
+	modelInfo spec.ModelInfo,
+) (spec.FetchCompletionRequest, error) {
+	reqCopy := *req
+	reqCopy.ModelParam.Name = resolvedModel
+	if reqCopy.ModelParam.Locale != "" {
+		reqCopy.ModelParam.SystemPrompt = sdkutil.AppendLocaleHint(
+			reqCopy.ModelParam.SystemPrompt,
+			reqCopy.ModelParam.Locale,
+		)
+	}
+
+	// If a max prompt length (in tokens) is configured, or the context window is known, apply heuristic filtering.
+	promptBudget, err := promptTokenBudget(&reqCopy.ModelParam, modelInfo)
+	if err != nil {
+		return reqCopy, err
+	}
+	if promptBudget > 0 {
+		reqCopy.Inputs = sdkutil.FilterMessagesByTokenCount(req.Inputs, promptBudget)
+		if modelInfo.ContextWindow > 0 {
+			reqCopy.Inputs, err = sdkutil.TruncateMessagesToTokenCount(reqCopy.Inputs, promptBudget)
+			if err != nil {
+				return reqCopy, err
+			}
+		}
+	}
+	return reqCopy, nil
+}
+
+// fetchRoute sends one request to one provider.
+func (ps *ProviderSetAPI) fetchRoute(
+	ctx context.Context,
+	provider spec.ProviderName,
+	fetchCompletionRequest *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	requestID string,
+	attempt int,
+) (*spec.FetchCompletionResponse, error) {
 	ps.mu.RLock()
 	p, exists := ps.providers[provider]
+	queue := ps.admission[provider]
+	outputRate := ps.outputRates[provider]
+	resolvedModel, err := resolveModelAlias(ps.modelAliases, fetchCompletionRequest.ModelParam.Name)
+	if exists && err == nil {
+		err = ps.checkDeprecation(ctx, provider, resolvedModel, time.Now())
+	}
+	modelInfo, _ := ps.lookupModelInfo(provider, resolvedModel)
 	ps.mu.RUnlock()
 
 	if !exists {
-		return nil, errors.New("invalid provider")
+		return nil, fmt.Errorf("invalid provider %s", provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reqCopy, err := prepareRouteRequest(fetchCompletionRequest, resolvedModel, modelInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := ps.eventBus
+	base := events.Event{RequestID: requestID, Provider: provider, Model: reqCopy.ModelParam.Name}
+	publish := func(kind events.Kind, mutate func(*events.Event)) {
+		if !bus.Active() {
+			return
+		}
+		ev := base
+		ev.Kind = kind
+		if mutate != nil {
+			mutate(&ev)
+		}
+		bus.Publish(ev)
+	}
+	if queue != nil {
+		reportProgress(ctx, provider, reqCopy.ModelParam.Name, attempt, spec.ProgressStatusQueued)
+		var priority spec.PriorityClass
+		if opts != nil {
+			priority = opts.Priority
+		}
+		release, err := queue.acquire(ctx, priority)
+		if err != nil {
+			return nil, fmt.Errorf("fetch completion not admitted for provider %s: %w", provider, err)
+		}
+		defer release()
 	}
 
-	reqCopy := *fetchCompletionRequest
+	start := time.Now()
+	reportProgress(ctx, provider, reqCopy.ModelParam.Name, attempt, spec.ProgressStatusInProgress)
+	if attempt == 1 {
+		publish(events.KindRequestStarted, nil)
+	}
+	publish(events.KindAttempt, func(ev *events.Event) { ev.Attempt = attempt })
 
-	// If a max prompt length (in tokens) is configured, apply heuristic filtering.
-	if reqCopy.ModelParam.MaxPromptLength > 0 {
-		reqCopy.Inputs = sdkutil.FilterMessagesByTokenCount(
-			fetchCompletionRequest.Inputs,
-			reqCopy.ModelParam.MaxPromptLength,
-		)
+	if bus != nil && opts != nil && opts.StreamHandler != nil {
+		optsCopy := *opts
+		next := opts.StreamHandler
+		optsCopy.StreamHandler = func(event spec.StreamEvent) error {
+			publish(events.KindStreamChunk, func(ev *events.Event) { ev.Stream = &event })
+			return next(event)
+		}
+		opts = &optsCopy
+	}
+	if outputRate != nil && opts != nil && opts.StreamHandler != nil && reqCopy.ModelParam.Stream {
+		optsCopy := *opts
+		optsCopy.StreamHandler = outputRate.pace(ctx, reqCopy.ModelParam.Name, opts.StreamHandler)
+		opts = &optsCopy
 	}
 
 	resp, err := p.FetchCompletion(
@@ -227,6 +789,30 @@ func (ps *ProviderSetAPI) FetchCompletion(
 		&reqCopy,
 		opts,
 	)
+	err = adapterTimeoutError(ctx, provider, err)
+	publish(events.KindFinished, func(ev *events.Event) {
+		ev.Attempt = attempt
+		ev.Duration = time.Since(start)
+		ev.Err = err
+		if resp != nil {
+			ev.Usage = resp.Usage
+		}
+	})
+	if resp != nil {
+		if resp.Metadata == nil {
+			resp.Metadata = &spec.ResponseMetadata{}
+		}
+		resp.Metadata.Provider = provider
+		resp.Metadata.RequestedModel = fetchCompletionRequest.ModelParam.Name
+		resp.Metadata.ResolvedModel = resolvedModel
+		resp.Metadata.WebSearchCalls = webSearchCalls(resp.Outputs)
+		if reqCopy.ModelParam.Locale != "" {
+			resp.Metadata.DetectedLanguage = sdkutil.DetectLanguage(outputText(resp.Outputs))
+		}
+		if err == nil {
+			ps.checkOutputSchema(provider, &reqCopy.ModelParam, resp, requestID)
+		}
+	}
 	if err != nil {
 		// Return any partial response we got alongside a contextual error.
 		return resp, fmt.Errorf("fetch completion failed for provider %s: %w", provider, err)
@@ -235,10 +821,370 @@ func (ps *ProviderSetAPI) FetchCompletion(
 	return resp, nil
 }
 
+// FetchRaw sends an arbitrary request to a provider endpoint that is not modeled by spec (e.g. assistants, vector
+// stores). The provider's configured auth, base URL, debugger and SDK retries are reused. path is relative to the
+// provider base URL and body may be nil. Error statuses are not errors: check RawResponse.StatusCode.
+func (ps *ProviderSetAPI) FetchRaw(
+	ctx context.Context,
+	provider spec.ProviderName,
+	method string,
+	path string,
+	body json.RawMessage,
+) (*spec.RawResponse, error) {
+	if provider == "" || strings.TrimSpace(method) == "" || strings.TrimSpace(path) == "" {
+		return nil, errors.New("got empty raw request input")
+	}
+
+	ps.mu.RLock()
+	p, exists := ps.providers[provider]
+	ps.mu.RUnlock()
+	if !exists {
+		return nil, errors.New("invalid provider")
+	}
+
+	rr, ok := p.(spec.RawRequester)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support raw requests", provider)
+	}
+	resp, err := rr.FetchRaw(ctx, method, path, body)
+	if err != nil {
+		return resp, fmt.Errorf("raw request failed for provider %s: %w", provider, err)
+	}
+	return resp, nil
+}
+
+// FetchFIMCompletion requests a fill-in-the-middle code completion from a provider that supports it, e.g. an
+// OpenAI-compatible completions endpoint of DeepSeek, Codestral or a StarCoder server.
+func (ps *ProviderSetAPI) FetchFIMCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FIMCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	if provider == "" || req == nil || req.ModelParam.Name == "" {
+		return nil, errors.New("got empty fim completion input")
+	}
+
+	ps.mu.RLock()
+	p, exists := ps.providers[provider]
+	ps.mu.RUnlock()
+	if !exists {
+		return nil, errors.New("invalid provider")
+	}
+
+	fc, ok := p.(spec.FIMCompleter)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support fim completions", provider)
+	}
+	resp, err := fc.FetchFIMCompletion(ctx, req, opts)
+	if err != nil {
+		return resp, fmt.Errorf("fim completion failed for provider %s: %w", provider, err)
+	}
+	return resp, nil
+}
+
+// ListLocalModels lists the models available on a local inference server provider, e.g. Ollama.
+func (ps *ProviderSetAPI) ListLocalModels(
+	ctx context.Context,
+	provider spec.ProviderName,
+) ([]spec.LocalModel, error) {
+	lm, err := ps.localModelManager(provider)
+	if err != nil {
+		return nil, err
+	}
+	models, err := lm.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list models failed for provider %s: %w", provider, err)
+	}
+	return models, nil
+}
+
+// PullLocalModel downloads a model to a local inference server provider. progress (may be nil) is called for each
+// status update; PullLocalModel returns once the model is available.
+func (ps *ProviderSetAPI) PullLocalModel(
+	ctx context.Context,
+	provider spec.ProviderName,
+	model spec.ModelName,
+	progress func(spec.LocalModelPullProgress),
+) error {
+	if model == "" {
+		return errors.New("got empty model name")
+	}
+	lm, err := ps.localModelManager(provider)
+	if err != nil {
+		return err
+	}
+	if err := lm.PullModel(ctx, model, progress); err != nil {
+		return fmt.Errorf("pull model failed for provider %s: %w", provider, err)
+	}
+	return nil
+}
+
+// UploadFile stores a file with a provider that supports it, e.g. Anthropic. The returned ID is referenced by
+// ContentItemFile.FileID in later requests.
+func (ps *ProviderSetAPI) UploadFile(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.UploadFileRequest,
+) (*spec.ProviderFile, error) {
+	if req == nil || req.Reader == nil || req.FileName == "" {
+		return nil, errors.New("got empty upload file input")
+	}
+	fm, err := ps.fileManager(provider)
+	if err != nil {
+		return nil, err
+	}
+	file, err := fm.UploadFile(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("upload file failed for provider %s: %w", provider, err)
+	}
+	return file, nil
+}
+
+// DeleteFile deletes a file stored with a provider by UploadFile.
+func (ps *ProviderSetAPI) DeleteFile(ctx context.Context, provider spec.ProviderName, fileID string) error {
+	if fileID == "" {
+		return errors.New("got empty file id")
+	}
+	fm, err := ps.fileManager(provider)
+	if err != nil {
+		return err
+	}
+	if err := fm.DeleteFile(ctx, fileID); err != nil {
+		return fmt.Errorf("delete file failed for provider %s: %w", provider, err)
+	}
+	return nil
+}
+
+// GetResponse retrieves a response by ID from a provider, e.g. one started with ModelParam.Background.
+func (ps *ProviderSetAPI) GetResponse(
+	ctx context.Context,
+	provider spec.ProviderName,
+	responseID string,
+) (*spec.FetchCompletionResponse, error) {
+	br, err := ps.backgroundResponder(provider, responseID)
+	if err != nil {
+		return nil, err
+	}
+	return br.GetResponse(ctx, responseID)
+}
+
+// CancelResponse cancels a queued or in-progress background response.
+func (ps *ProviderSetAPI) CancelResponse(
+	ctx context.Context,
+	provider spec.ProviderName,
+	responseID string,
+) (*spec.FetchCompletionResponse, error) {
+	br, err := ps.backgroundResponder(provider, responseID)
+	if err != nil {
+		return nil, err
+	}
+	return br.CancelResponse(ctx, responseID)
+}
+
+// WaitResponse polls a background response every interval until it leaves the queued and in-progress states or ctx
+// is done, and returns the last retrieved response. progress, if non-nil, is invoked after each poll that finds the
+// response still queued or in progress, with the polled status in ProgressEvent.ProviderStatus.
+func (ps *ProviderSetAPI) WaitResponse(
+	ctx context.Context,
+	provider spec.ProviderName,
+	responseID string,
+	interval time.Duration,
+	progress spec.ProgressHandler,
+) (*spec.FetchCompletionResponse, error) {
+	if interval <= 0 {
+		return nil, errors.New("got non-positive poll interval")
+	}
+	br, err := ps.backgroundResponder(provider, responseID)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		resp, err := br.GetResponse(ctx, responseID)
+		if err != nil {
+			return resp, err
+		}
+		if resp.Metadata == nil ||
+			(resp.Metadata.Status != spec.StatusQueued && resp.Metadata.Status != spec.StatusInProgress) {
+			return resp, nil
+		}
+		if progress != nil {
+			status := spec.ProgressStatusInProgress
+			if resp.Metadata.Status == spec.StatusQueued {
+				status = spec.ProgressStatusQueued
+			}
+			callProgressHandler(progress, spec.ProgressEvent{
+				Provider:       provider,
+				Attempt:        1,
+				Status:         status,
+				ProviderStatus: resp.Metadata.Status,
+				Elapsed:        time.Since(start),
+			})
+		}
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SubmitBatch submits requests to a provider's asynchronous batch API, e.g. Anthropic Message Batches. Poll the
+// returned batch with GetBatch and read its results with BatchResults once it has ended.
+func (ps *ProviderSetAPI) SubmitBatch(
+	ctx context.Context,
+	provider spec.ProviderName,
+	reqs []spec.BatchRequest,
+) (*spec.BatchStatus, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("got empty batch input")
+	}
+	bs, err := ps.batchSubmitter(provider)
+	if err != nil {
+		return nil, err
+	}
+	status, err := bs.SubmitBatch(ctx, reqs)
+	if err != nil {
+		return nil, fmt.Errorf("submit batch failed for provider %s: %w", provider, err)
+	}
+	return status, nil
+}
+
+// GetBatch retrieves the status of a batch started with SubmitBatch.
+func (ps *ProviderSetAPI) GetBatch(
+	ctx context.Context,
+	provider spec.ProviderName,
+	batchID string,
+) (*spec.BatchStatus, error) {
+	if batchID == "" {
+		return nil, errors.New("got empty batch id")
+	}
+	bs, err := ps.batchSubmitter(provider)
+	if err != nil {
+		return nil, err
+	}
+	return bs.GetBatch(ctx, batchID)
+}
+
+// BatchResults streams the results of an ended batch to handler.
+func (ps *ProviderSetAPI) BatchResults(
+	ctx context.Context,
+	provider spec.ProviderName,
+	batchID string,
+	handler func(spec.BatchResult) error,
+) error {
+	if batchID == "" || handler == nil {
+		return errors.New("got empty batch id or handler")
+	}
+	bs, err := ps.batchSubmitter(provider)
+	if err != nil {
+		return err
+	}
+	return bs.BatchResults(ctx, batchID, handler)
+}
+
+// CancelBatch cancels a batch started with SubmitBatch.
+func (ps *ProviderSetAPI) CancelBatch(
+	ctx context.Context,
+	provider spec.ProviderName,
+	batchID string,
+) (*spec.BatchStatus, error) {
+	if batchID == "" {
+		return nil, errors.New("got empty batch id")
+	}
+	bs, err := ps.batchSubmitter(provider)
+	if err != nil {
+		return nil, err
+	}
+	return bs.CancelBatch(ctx, batchID)
+}
+
+func (ps *ProviderSetAPI) batchSubmitter(provider spec.ProviderName) (spec.BatchSubmitter, error) {
+	if provider == "" {
+		return nil, errors.New("got empty provider input")
+	}
+	ps.mu.RLock()
+	p, exists := ps.providers[provider]
+	ps.mu.RUnlock()
+	if !exists {
+		return nil, errors.New("invalid provider")
+	}
+	bs, ok := p.(spec.BatchSubmitter)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support batches", provider)
+	}
+	return bs, nil
+}
+
+func (ps *ProviderSetAPI) backgroundResponder(
+	provider spec.ProviderName,
+	responseID string,
+) (spec.BackgroundResponder, error) {
+	if provider == "" || responseID == "" {
+		return nil, errors.New("got empty provider or response id input")
+	}
+	ps.mu.RLock()
+	p, exists := ps.providers[provider]
+	ps.mu.RUnlock()
+	if !exists {
+		return nil, errors.New("invalid provider")
+	}
+	br, ok := p.(spec.BackgroundResponder)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support background responses", provider)
+	}
+	return br, nil
+}
+
+func (ps *ProviderSetAPI) fileManager(provider spec.ProviderName) (spec.FileManager, error) {
+	if provider == "" {
+		return nil, errors.New("got empty provider input")
+	}
+	ps.mu.RLock()
+	p, exists := ps.providers[provider]
+	ps.mu.RUnlock()
+	if !exists {
+		return nil, errors.New("invalid provider")
+	}
+	fm, ok := p.(spec.FileManager)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support file management", provider)
+	}
+	return fm, nil
+}
+
+func (ps *ProviderSetAPI) localModelManager(provider spec.ProviderName) (spec.LocalModelManager, error) {
+	if provider == "" {
+		return nil, errors.New("got empty provider input")
+	}
+	ps.mu.RLock()
+	p, exists := ps.providers[provider]
+	ps.mu.RUnlock()
+	if !exists {
+		return nil, errors.New("invalid provider")
+	}
+	lm, ok := p.(spec.LocalModelManager)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support local model management", provider)
+	}
+	return lm, nil
+}
+
 func isProviderSDKTypeSupported(t spec.ProviderSDKType) bool {
 	if t == spec.ProviderSDKTypeAnthropic ||
 		t == spec.ProviderSDKTypeOpenAIChatCompletions ||
-		t == spec.ProviderSDKTypeOpenAIResponses {
+		t == spec.ProviderSDKTypeOpenAIResponses ||
+		t == spec.ProviderSDKTypeOpenAICompatibleSSE ||
+		t == spec.ProviderSDKTypeOpenAICompletions ||
+		t == spec.ProviderSDKTypeOllama ||
+		t == spec.ProviderSDKTypeCohere ||
+		t == spec.ProviderSDKTypeOpenRouter ||
+		t == spec.ProviderSDKTypePerplexity ||
+		t == spec.ProviderSDKTypeFireworks ||
+		t == spec.ProviderSDKTypeOpenAICompatible {
 		return true
 	}
 	return false
@@ -249,11 +1195,19 @@ func getProviderAPI(p spec.ProviderParam, dbg spec.CompletionDebugger) (spec.Com
 	case spec.ProviderSDKTypeAnthropic:
 		return anthropicsdk.NewAnthropicMessagesAPI(p, dbg)
 
-	case spec.ProviderSDKTypeOpenAIChatCompletions:
+	case spec.ProviderSDKTypeOpenAIChatCompletions, spec.ProviderSDKTypeOpenAICompatibleSSE,
+		spec.ProviderSDKTypeOpenAICompletions, spec.ProviderSDKTypeOpenRouter, spec.ProviderSDKTypePerplexity,
+		spec.ProviderSDKTypeFireworks, spec.ProviderSDKTypeOpenAICompatible:
 		return openaichatsdk.NewOpenAIChatCompletionsAPI(p, dbg)
 
 	case spec.ProviderSDKTypeOpenAIResponses:
 		return openairesponsessdk.NewOpenAIResponsesAPI(p, dbg)
+
+	case spec.ProviderSDKTypeOllama:
+		return ollamasdk.NewOllamaChatAPI(p, dbg)
+
+	case spec.ProviderSDKTypeCohere:
+		return coheresdk.NewCohereChatAPI(p, dbg)
 	}
 
 	return nil, errors.New("invalid provider api type")
@@ -0,0 +1,828 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// newTestProviderSet returns a provider set built with opts.
+func newTestProviderSet(t *testing.T, opts ...ProviderSetOption) *ProviderSetAPI {
+	t.Helper()
+
+	ps, err := NewProviderSetAPI(opts...)
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI() error = %v.", err)
+	}
+	return ps
+}
+
+// addTestProvider adds provider to ps with config and the API key "k".
+func addTestProvider(t *testing.T, ps *ProviderSetAPI, provider spec.ProviderName, config *AddProviderConfig) {
+	t.Helper()
+
+	if _, err := ps.AddProvider(t.Context(), provider, config); err != nil {
+		t.Fatalf("AddProvider() error = %v.", err)
+	}
+	if err := ps.SetProviderAPIKey(t.Context(), provider, "k"); err != nil {
+		t.Fatalf("SetProviderAPIKey() error = %v.", err)
+	}
+}
+
+// userInputs returns a single user message holding text.
+func userInputs(text string) []spec.InputUnion {
+	return []spec.InputUnion{{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role: spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			}},
+		},
+	}}
+}
+
+func TestFetchCompletionDryRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		sdkType spec.ProviderSDKType
+		prefix  string
+	}{
+		{name: "Anthropic.", sdkType: spec.ProviderSDKTypeAnthropic, prefix: spec.DefaultAnthropicChatCompletionPrefix},
+		{
+			name:    "OpenAIChat.",
+			sdkType: spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:  spec.DefaultOpenAIChatCompletionsPrefix,
+		},
+		{name: "OpenAIResponses.", sdkType: spec.ProviderSDKTypeOpenAIResponses, prefix: "/v1/responses"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ps := newTestProviderSet(t)
+			addTestProvider(t, ps, "p", &AddProviderConfig{
+				SDKType:                  tc.sdkType,
+				Origin:                   "http://127.0.0.1:1",
+				ChatCompletionPathPrefix: tc.prefix,
+			})
+
+			resp, err := ps.FetchCompletion(t.Context(), "p", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m", SystemPrompt: "Be brief."},
+				Inputs:     userInputs("hi"),
+			}, &spec.FetchCompletionOptions{DryRun: true})
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			dd, _ := resp.DebugDetails.(map[string]any)
+			req, _ := dd["request"].(map[string]any)
+			body, _ := req["body"].(map[string]any)
+			if dd["dryRun"] != true || body["model"] != "m" || len(resp.Outputs) != 0 {
+				t.Fatalf("DebugDetails = %v, want a dry run body for model m.", resp.DebugDetails)
+			}
+			if got, want := req["url"], "http://127.0.0.1:1"+tc.prefix; got != want {
+				t.Fatalf("url = %v, want = %v.", got, want)
+			}
+		})
+	}
+}
+
+func TestFetchRaw(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/ok":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"x"}`))
+		case "/v1/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"bad"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("404 page not found"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "OK.", path: "ok", wantCode: http.StatusOK, wantBody: `{"id":"x"}`},
+		{name: "JSONError.", path: "json", wantCode: http.StatusBadRequest, wantBody: `{"error":{"message":"bad"}}`},
+		{name: "TextError.", path: "missing", wantCode: http.StatusNotFound, wantBody: "404 page not found"},
+	}
+	for _, sdkType := range []spec.ProviderSDKType{
+		spec.ProviderSDKTypeAnthropic,
+		spec.ProviderSDKTypeOpenAIChatCompletions,
+		spec.ProviderSDKTypeOpenAIResponses,
+		spec.ProviderSDKTypeOllama,
+	} {
+		ps := newTestProviderSet(t)
+		addTestProvider(t, ps, "p", &AddProviderConfig{SDKType: sdkType, Origin: srv.URL + "/v1"})
+		for _, tc := range tests {
+			t.Run(string(sdkType)+"/"+tc.name, func(t *testing.T) {
+				t.Parallel()
+				resp, err := ps.FetchRaw(t.Context(), "p", http.MethodGet, tc.path, nil)
+				if err != nil {
+					t.Fatalf("FetchRaw() error = %v.", err)
+				}
+				if resp.StatusCode != tc.wantCode || string(resp.Body) != tc.wantBody {
+					t.Fatalf("FetchRaw() = %d %q, want %d %q.", resp.StatusCode, resp.Body, tc.wantCode, tc.wantBody)
+				}
+			})
+		}
+	}
+}
+
+func TestFetchCompletionSamplingParams(t *testing.T) {
+	t.Parallel()
+
+	topP, penalty, topK := 0.9, 0.5, 40
+	tests := []struct {
+		name    string
+		sdkType spec.ProviderSDKType
+		prefix  string
+		want    map[string]any
+		absent  []string
+	}{
+		{
+			name:    "Anthropic.",
+			sdkType: spec.ProviderSDKTypeAnthropic,
+			prefix:  spec.DefaultAnthropicChatCompletionPrefix,
+			want:    map[string]any{"top_p": 0.9, "top_k": float64(40)},
+			absent:  []string{"frequency_penalty", "presence_penalty"},
+		},
+		{
+			name:    "OpenAIChat.",
+			sdkType: spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:  spec.DefaultOpenAIChatCompletionsPrefix,
+			want:    map[string]any{"top_p": 0.9, "frequency_penalty": 0.5, "presence_penalty": 0.5},
+			absent:  []string{"top_k"},
+		},
+		{
+			name:    "OpenAIResponses.",
+			sdkType: spec.ProviderSDKTypeOpenAIResponses,
+			prefix:  "/v1/responses",
+			want:    map[string]any{"top_p": 0.9},
+			absent:  []string{"top_k", "frequency_penalty", "presence_penalty"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ps := newTestProviderSet(t)
+			addTestProvider(t, ps, "p", &AddProviderConfig{
+				SDKType:                  tc.sdkType,
+				Origin:                   "http://127.0.0.1:1",
+				ChatCompletionPathPrefix: tc.prefix,
+			})
+
+			resp, err := ps.FetchCompletion(t.Context(), "p", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{
+					Name:             "m",
+					TopP:             &topP,
+					TopK:             &topK,
+					FrequencyPenalty: &penalty,
+					PresencePenalty:  &penalty,
+				},
+				Inputs: userInputs("hi"),
+			}, &spec.FetchCompletionOptions{DryRun: true})
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			dd, _ := resp.DebugDetails.(map[string]any)
+			req, _ := dd["request"].(map[string]any)
+			body, _ := req["body"].(map[string]any)
+			for k, v := range tc.want {
+				if body[k] != v {
+					t.Fatalf("body[%q] = %v, want %v.", k, body[k], v)
+				}
+			}
+			for _, k := range tc.absent {
+				if _, ok := body[k]; ok {
+					t.Fatalf("body[%q] = %v, want it unset.", k, body[k])
+				}
+			}
+		})
+	}
+}
+
+func TestFetchCompletionAdditionalParameters(t *testing.T) {
+	t.Parallel()
+
+	overlay := `{"metadata":{"team":"search"},"seed":7}`
+	tests := []struct {
+		name    string
+		sdkType spec.ProviderSDKType
+		prefix  string
+		raw     string
+		wantErr bool
+	}{
+		{name: "Anthropic.", sdkType: spec.ProviderSDKTypeAnthropic, prefix: spec.DefaultAnthropicChatCompletionPrefix},
+		{name: "OpenAIChat.", sdkType: spec.ProviderSDKTypeOpenAIChatCompletions, prefix: "/v1/chat/completions"},
+		{name: "OpenAIResponses.", sdkType: spec.ProviderSDKTypeOpenAIResponses, prefix: "/v1/responses"},
+		{name: "Ollama.", sdkType: spec.ProviderSDKTypeOllama},
+		{name: "Cohere.", sdkType: spec.ProviderSDKTypeCohere},
+		{
+			name:    "DeniedField.",
+			sdkType: spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:  "/v1/chat/completions",
+			raw:     `{"messages":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "NotAnObject.",
+			sdkType: spec.ProviderSDKTypeAnthropic,
+			prefix:  spec.DefaultAnthropicChatCompletionPrefix,
+			raw:     `[1]`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ps := newTestProviderSet(t)
+			addTestProvider(t, ps, "p", &AddProviderConfig{
+				SDKType:                  tc.sdkType,
+				Origin:                   "http://127.0.0.1:1",
+				ChatCompletionPathPrefix: tc.prefix,
+			})
+
+			raw := overlay
+			if tc.raw != "" {
+				raw = tc.raw
+			}
+			resp, err := ps.FetchCompletion(t.Context(), "p", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m", AdditionalParametersRawJSON: &raw},
+				Inputs:     userInputs("hi"),
+			}, &spec.FetchCompletionOptions{DryRun: true})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("FetchCompletion() error = nil, want an error.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			dd, _ := resp.DebugDetails.(map[string]any)
+			req, _ := dd["request"].(map[string]any)
+			body, _ := req["body"].(map[string]any)
+			if body["seed"] != float64(7) || body["model"] != "m" {
+				t.Fatalf("body = %v, want seed 7 and the model kept.", body)
+			}
+			if md, _ := body["metadata"].(map[string]any); md["team"] != "search" {
+				t.Fatalf("body[metadata] = %v, want the overlay metadata.", body["metadata"])
+			}
+		})
+	}
+}
+
+func TestFetchCompletionEffectiveParams(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"c1","object":"chat.completion","created":0,"model":"gpt-x",` +
+			`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t, WithModelAliases(map[spec.ModelName]spec.ModelName{"latest": "gpt-x"}))
+	addTestProvider(t, ps, "p", &AddProviderConfig{
+		SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+		Origin:                   srv.URL,
+		ChatCompletionPathPrefix: spec.DefaultOpenAIChatCompletionsPrefix,
+		Quirks:                   &spec.ProviderQuirks{RequiresMaxTokens: true},
+	})
+
+	resp, err := ps.FetchCompletion(t.Context(), "p", &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "latest"},
+		Inputs:     userInputs("hi"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	md := resp.Metadata
+	if md == nil || md.RequestedModel != "latest" || md.ResolvedModel != "gpt-x" {
+		t.Fatalf("Metadata = %+v, want latest resolved to gpt-x.", md)
+	}
+	if md.EffectiveParams["model"] != "gpt-x" || md.EffectiveParams["max_completion_tokens"] == nil {
+		t.Fatalf("EffectiveParams = %v, want model and the quirk max tokens.", md.EffectiveParams)
+	}
+	if _, ok := md.EffectiveParams["messages"]; ok {
+		t.Fatalf("EffectiveParams = %v, want no messages.", md.EffectiveParams)
+	}
+}
+
+func TestFetchCompletionLocaleHint(t *testing.T) {
+	t.Parallel()
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "p", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeAnthropic,
+		Origin:  "http://127.0.0.1:1",
+	})
+
+	resp, err := ps.FetchCompletion(t.Context(), "p", &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m", SystemPrompt: "Be brief.", Locale: "fr-CA"},
+		Inputs:     userInputs("hi"),
+	}, &spec.FetchCompletionOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	body, err := json.Marshal(resp.DebugDetails)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v.", err)
+	}
+	if !strings.Contains(string(body), `Be brief.\n\nRespond in the language and regional conventions of the locale`) ||
+		!strings.Contains(string(body), `fr-CA`) {
+		t.Fatalf("request = %s, want the locale hint after the system prompt.", body)
+	}
+}
+
+func TestFetchCompletionAzure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		sdkType   spec.ProviderSDKType
+		body      string
+		wantPath  string
+		wantModel string
+	}{
+		{
+			name:    "ChatCompletionsRoutesToDeployment.",
+			sdkType: spec.ProviderSDKTypeOpenAIChatCompletions,
+			body: `{"id":"c1","object":"chat.completion","created":0,"model":"gpt-4o",` +
+				`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`,
+			wantPath:  "/openai/deployments/prod-4o/chat/completions",
+			wantModel: `"model":"prod-4o"`,
+		},
+		{
+			name:    "ResponsesSendsDeploymentAsModel.",
+			sdkType: spec.ProviderSDKTypeOpenAIResponses,
+			body: `{"id":"r1","object":"response","created_at":0,"model":"gpt-4o","status":"completed",` +
+				`"output":[{"type":"message","id":"m1","role":"assistant","status":"completed",` +
+				`"content":[{"type":"output_text","text":"ok","annotations":[]}]}]}`,
+			wantPath:  "/openai/responses",
+			wantModel: `"model":"prod-4o"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got *http.Request
+			var gotBody string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				got, gotBody = r, string(b)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			ps := newTestProviderSet(t)
+			addTestProvider(t, ps, "azure", &AddProviderConfig{
+				SDKType: tc.sdkType,
+				Origin:  srv.URL,
+				Azure: &spec.AzureOpenAI{
+					APIVersion:  "2025-04-01-preview",
+					Deployments: map[spec.ModelName]string{"gpt-4o": "prod-4o"},
+				},
+			})
+			if err := ps.SetProviderAPIKey(t.Context(), "azure", "secret"); err != nil {
+				t.Fatalf("SetProviderAPIKey() error = %v.", err)
+			}
+
+			resp, err := ps.FetchCompletion(t.Context(), "azure", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "gpt-4o"},
+				Inputs:     userInputs("hi"),
+			}, nil)
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			if outputText(resp.Outputs) != "ok" {
+				t.Fatalf("Outputs = %+v, want ok.", resp.Outputs)
+			}
+			if got.URL.Path != tc.wantPath {
+				t.Fatalf("path = %q, want = %q.", got.URL.Path, tc.wantPath)
+			}
+			if v := got.URL.Query().Get("api-version"); v != "2025-04-01-preview" {
+				t.Fatalf("api-version = %q, want = %q.", v, "2025-04-01-preview")
+			}
+			if got.Header.Get("api-key") != "secret" || got.Header.Get("Authorization") != "" {
+				t.Fatalf("auth headers = %v, want the key in api-key only.", got.Header)
+			}
+			if !strings.Contains(gotBody, tc.wantModel) {
+				t.Fatalf("body = %s, want it to contain %s.", gotBody, tc.wantModel)
+			}
+		})
+	}
+}
+
+func TestFetchCompletionVertex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		sdkType   spec.ProviderSDKType
+		model     spec.ModelName
+		body      string
+		wantPath  string
+		wantBody  []string
+		wantNoKey string
+	}{
+		{
+			name:    "ClaudeUsesPublisherModelRoute.",
+			sdkType: spec.ProviderSDKTypeAnthropic,
+			model:   "claude-sonnet-4@20250514",
+			body: `{"id":"msg1","type":"message","role":"assistant","model":"m","stop_reason":"end_turn",` +
+				`"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":1,"output_tokens":1}}`,
+			wantPath: "/v1/projects/p1/locations/us-east5/publishers/anthropic/models/" +
+				"claude-sonnet-4@20250514:rawPredict",
+			wantBody:  []string{`"anthropic_version":"vertex-2023-10-16"`},
+			wantNoKey: `"model"`,
+		},
+		{
+			name:    "GeminiUsesOpenAICompatibleEndpoint.",
+			sdkType: spec.ProviderSDKTypeOpenAIChatCompletions,
+			model:   "google/gemini-2.5-flash",
+			body: `{"id":"c1","object":"chat.completion","created":0,"model":"google/gemini-2.5-flash",` +
+				`"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"ok"}}]}`,
+			wantPath: "/v1/projects/p1/locations/us-east5/endpoints/openapi/chat/completions",
+			wantBody: []string{`"model":"google/gemini-2.5-flash"`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got *http.Request
+			var gotBody string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				got, gotBody = r, string(b)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			ps := newTestProviderSet(t)
+			// No SetProviderAPIKey: credentials are enough to initialize the provider.
+			addTestProvider(t, ps, "vertex", &AddProviderConfig{
+				SDKType: tc.sdkType,
+				Origin:  srv.URL,
+				Vertex:  &spec.VertexAI{ProjectID: "p1", Region: "us-east5"},
+				Credentials: spec.CredentialProviderFunc(func(context.Context) (string, error) {
+					return "adc-token", nil
+				}),
+			})
+
+			resp, err := ps.FetchCompletion(t.Context(), "vertex", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: tc.model},
+				Inputs:     userInputs("hi"),
+			}, nil)
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			if outputText(resp.Outputs) != "ok" {
+				t.Fatalf("Outputs = %+v, want ok.", resp.Outputs)
+			}
+			if got.URL.Path != tc.wantPath {
+				t.Fatalf("path = %q, want = %q.", got.URL.Path, tc.wantPath)
+			}
+			if v := got.Header.Get("Authorization"); v != "Bearer adc-token" || got.Header.Get("X-Api-Key") != "" {
+				t.Fatalf("auth headers = %v, want only the bearer token.", got.Header)
+			}
+			for _, want := range tc.wantBody {
+				if !strings.Contains(gotBody, want) {
+					t.Fatalf("body = %s, want it to contain %s.", gotBody, want)
+				}
+			}
+			if tc.wantNoKey != "" && strings.Contains(gotBody, tc.wantNoKey) {
+				t.Fatalf("body = %s, want no %s.", gotBody, tc.wantNoKey)
+			}
+		})
+	}
+}
+
+func TestAddProviderVertexValidation(t *testing.T) {
+	t.Parallel()
+
+	creds := spec.CredentialProviderFunc(func(context.Context) (string, error) { return "t", nil })
+	tests := []struct {
+		name   string
+		config AddProviderConfig
+	}{
+		{
+			name: "MissingCredentials.",
+			config: AddProviderConfig{
+				SDKType: spec.ProviderSDKTypeAnthropic,
+				Vertex:  &spec.VertexAI{ProjectID: "p", Region: "us-east5"},
+			},
+		},
+		{
+			name: "MissingRegion.",
+			config: AddProviderConfig{
+				SDKType:     spec.ProviderSDKTypeAnthropic,
+				Vertex:      &spec.VertexAI{ProjectID: "p"},
+				Credentials: creds,
+			},
+		},
+		{
+			name: "ResponsesUnsupported.",
+			config: AddProviderConfig{
+				SDKType:     spec.ProviderSDKTypeOpenAIResponses,
+				Vertex:      &spec.VertexAI{ProjectID: "p", Region: "global"},
+				Credentials: creds,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ps := newTestProviderSet(t)
+			if _, err := ps.AddProvider(t.Context(), "vertex", &tc.config); err == nil {
+				t.Fatalf("AddProvider() error = nil, want an error.")
+			}
+		})
+	}
+}
+
+func TestListProvidersConfigured(t *testing.T) {
+	t.Parallel()
+
+	ps := newTestProviderSet(t)
+	creds := spec.CredentialProviderFunc(func(context.Context) (string, error) { return "t", nil })
+	configs := map[spec.ProviderName]*AddProviderConfig{
+		"anthropic-creds": {SDKType: spec.ProviderSDKTypeAnthropic, Origin: "https://a.example", Credentials: creds},
+		"chat-creds": {
+			SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+			Origin:                   "https://c.example",
+			ChatCompletionPathPrefix: "/chat/completions",
+			Credentials:              creds,
+		},
+		"responses-creds": {
+			SDKType: spec.ProviderSDKTypeOpenAIResponses, Origin: "https://r.example", Credentials: creds,
+		},
+		"responses-nokey": {SDKType: spec.ProviderSDKTypeOpenAIResponses, Origin: "https://r.example"},
+	}
+	for name, config := range configs {
+		if _, err := ps.AddProvider(t.Context(), name, config); err != nil {
+			t.Fatalf("AddProvider(%q) error = %v.", name, err)
+		}
+	}
+
+	want := map[spec.ProviderName]bool{
+		"anthropic-creds": true,
+		"chat-creds":      true,
+		"responses-creds": true,
+		"responses-nokey": false,
+	}
+	got := ps.ListProviders(t.Context())
+	if len(got) != len(want) {
+		t.Fatalf("len(ListProviders()) = %d, want = %d.", len(got), len(want))
+	}
+	for _, st := range got {
+		if st.Configured != want[st.Name] {
+			t.Fatalf("%s: Configured = %v, want = %v.", st.Name, st.Configured, want[st.Name])
+		}
+	}
+}
+
+func TestFetchCompletionCanceled(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"Hel"},"done":false}` + "\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "ollama", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOllama,
+		Origin:  srv.URL,
+	})
+
+	tests := []struct {
+		name      string
+		ctx       func(t *testing.T) (context.Context, func())
+		want      spec.CancelReason
+		wantCause error
+	}{
+		{
+			name: "Budget.",
+			ctx: func(t *testing.T) (context.Context, func()) {
+				t.Helper()
+				ctx, cancel := context.WithCancelCause(t.Context())
+				return ctx, func() { cancel(spec.ErrBudgetExhausted) }
+			},
+			want:      spec.CancelReasonBudget,
+			wantCause: spec.ErrBudgetExhausted,
+		},
+		{
+			name: "ClientDisconnect.",
+			ctx: func(t *testing.T) (context.Context, func()) {
+				t.Helper()
+				ctx, cancel := context.WithCancel(t.Context())
+				return ctx, cancel
+			},
+			want:      spec.CancelReasonClientDisconnect,
+			wantCause: context.Canceled,
+		},
+		{
+			name: "Timeout.",
+			ctx: func(t *testing.T) (context.Context, func()) {
+				t.Helper()
+				ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+				t.Cleanup(cancel)
+				return ctx, func() {}
+			},
+			want:      spec.CancelReasonTimeout,
+			wantCause: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := tc.ctx(t)
+			start := time.Now()
+			_, err := ps.FetchCompletion(ctx, "ollama", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m", Stream: true},
+				Inputs:     userInputs("hi"),
+			}, &spec.FetchCompletionOptions{
+				StreamConfig: &spec.StreamConfig{FlushIntervalMillis: 1},
+				StreamHandler: func(e spec.StreamEvent) error {
+					if e.Kind == spec.StreamContentKindText {
+						cancel()
+					}
+					return nil
+				},
+			})
+			var ce *spec.CanceledError
+			if !errors.As(err, &ce) || ce.Reason != tc.want || !errors.Is(err, tc.wantCause) {
+				t.Fatalf("FetchCompletion() error = %v, want a %s cancellation.", err, tc.want)
+			}
+			if d := time.Since(start); d > 5*time.Second {
+				t.Fatalf("FetchCompletion() returned after %v, want prompt cancellation.", d)
+			}
+		})
+	}
+}
+
+func TestFetchCompletionProviderTimeouts(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(b), `"model":"slow-mini"`) {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"ok"},"done":true}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t)
+	if _, err := ps.AddProvider(t.Context(), "bad", &AddProviderConfig{
+		SDKType:  spec.ProviderSDKTypeOllama,
+		Timeouts: &spec.ProviderTimeouts{Models: []spec.ModelTimeout{{Pattern: "[", Seconds: 1}}},
+	}); err == nil {
+		t.Fatalf("AddProvider() error = nil, want an invalid pattern error.")
+	}
+	addTestProvider(t, ps, "ollama", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOllama,
+		Origin:  srv.URL,
+		Timeouts: &spec.ProviderTimeouts{
+			DefaultSeconds: 600,
+			Models:         []spec.ModelTimeout{{Pattern: "*-mini", Seconds: 1}},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		model   spec.ModelName
+		wantErr bool
+	}{
+		{name: "ProviderDefault.", model: "big"},
+		{name: "ModelRule.", model: "slow-mini", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			start := time.Now()
+			_, err := ps.FetchCompletion(t.Context(), "ollama", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: tc.model},
+				Inputs:     userInputs("hi"),
+			}, nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("FetchCompletion() error = %v, wantErr = %v.", err, tc.wantErr)
+			}
+			var ce *spec.CanceledError
+			if tc.wantErr && (!errors.As(err, &ce) || ce.Reason != spec.CancelReasonTimeout) {
+				t.Fatalf("FetchCompletion() error = %v, want a timeout cancellation.", err)
+			}
+			if d := time.Since(start); d > 5*time.Second {
+				t.Fatalf("FetchCompletion() took %v, want the 1s model timeout.", d)
+			}
+		})
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"model":"m","message":{"role":"assistant","content":"ok"},"done":true}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	var calls atomic.Int32
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	ps := newTestProviderSet(t, WithHTTPClient(client))
+	addTestProvider(t, ps, "ollama", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOllama,
+		Origin:  srv.URL,
+	})
+	if _, err := ps.FetchCompletion(t.Context(), "ollama", &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs:     userInputs("hi"),
+	}, nil); err != nil {
+		t.Fatalf("FetchCompletion() error = %v.", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("injected client requests = %d, want 1.", got)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestFetchFIMCompletionRouting(t *testing.T) {
+	t.Parallel()
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "anthropic", &AddProviderConfig{
+		SDKType:                  spec.ProviderSDKTypeAnthropic,
+		Origin:                   "http://127.0.0.1:1",
+		ChatCompletionPathPrefix: spec.DefaultAnthropicChatCompletionPrefix,
+	})
+
+	tests := []struct {
+		name     string
+		provider spec.ProviderName
+		req      *spec.FIMCompletionRequest
+		want     string
+	}{
+		{name: "EmptyRequest.", provider: "anthropic", want: "empty fim completion input"},
+		{
+			name:     "UnknownProvider.",
+			provider: "missing",
+			req:      &spec.FIMCompletionRequest{ModelParam: spec.ModelParam{Name: "m"}, Prefix: "x"},
+			want:     "invalid provider",
+		},
+		{
+			name:     "Unsupported.",
+			provider: "anthropic",
+			req:      &spec.FIMCompletionRequest{ModelParam: spec.ModelParam{Name: "m"}, Prefix: "x"},
+			want:     "does not support fim",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ps.FetchFIMCompletion(t.Context(), tc.provider, tc.req, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("FetchFIMCompletion() error = %v, want %q.", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package inference
+
+import (
+	"slices"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// capThinking forwards at most maxChars runes of thinking text to next.
+func capThinking(next spec.StreamHandler, maxChars int) spec.StreamHandler {
+	var (
+		mu        sync.Mutex
+		remaining = maxChars
+		truncated bool
+	)
+	return func(event spec.StreamEvent) error {
+		if event.Kind != spec.StreamContentKindThinking || event.Thinking == nil {
+			return next(event)
+		}
+		mu.Lock()
+		if truncated {
+			mu.Unlock()
+			return nil
+		}
+		chunk := *event.Thinking
+		if n := utf8.RuneCountInString(chunk.Text); n > remaining {
+			chunk.Text = truncateRunes(chunk.Text, remaining)
+			chunk.Truncated = true
+			truncated = true
+			remaining = 0
+		} else {
+			remaining -= n
+		}
+		mu.Unlock()
+
+		event.Thinking = &chunk
+		return next(event)
+	}
+}
+
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// dropReasoning removes reasoning outputs.
+func dropReasoning(outputs []spec.OutputUnion) []spec.OutputUnion {
+	return slices.DeleteFunc(slices.Clone(outputs), func(o spec.OutputUnion) bool {
+		return o.Kind == spec.OutputKindReasoningMessage
+	})
+}
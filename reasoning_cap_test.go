@@ -0,0 +1,40 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestCapThinking(t *testing.T) {
+	t.Parallel()
+
+	var got []spec.StreamEvent
+	h := capThinking(func(event spec.StreamEvent) error {
+		got = append(got, event)
+		return nil
+	}, 5)
+	for _, text := range []string{"abc", "déf", "ghi"} {
+		if err := h(spec.StreamEvent{
+			Kind:     spec.StreamContentKindThinking,
+			Thinking: &spec.StreamThinkingChunk{Text: text},
+		}); err != nil {
+			t.Fatalf("handler error = %v.", err)
+		}
+	}
+	err := h(spec.StreamEvent{Kind: spec.StreamContentKindText, Text: &spec.StreamTextChunk{Text: "x"}})
+	if err != nil {
+		t.Fatalf("handler error = %v.", err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("delivered %d events, want = 3.", len(got))
+	}
+	if got[1].Thinking.Text != "dé" || !got[1].Thinking.Truncated || got[0].Thinking.Truncated {
+		t.Fatalf("thinking = %+v, %+v, want the second chunk cut to \"dé\" and marked truncated.",
+			got[0].Thinking, got[1].Thinking)
+	}
+	if got[2].Kind != spec.StreamContentKindText {
+		t.Fatalf("last event kind = %s, want = %s.", got[2].Kind, spec.StreamContentKindText)
+	}
+}
@@ -0,0 +1,42 @@
+package inference
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// RequestHash returns a canonical content hash of req, formatted as "sha256:<hex>". The request is encoded as JSON
+// with all object keys sorted and insignificant whitespace removed, so equal requests hash equally regardless of map
+// ordering (e.g. tool argument schemas or metadata). It is used to deduplicate calls, see WithDedupeWindow.
+func RequestHash(req *spec.FetchCompletionRequest) (string, error) {
+	if req == nil {
+		return "", errors.New("got empty fetch completion request")
+	}
+	b, err := canonicalJSON(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalJSON encodes v as JSON with sorted object keys. Re-encoding through a generic value also sorts the keys of
+// raw JSON values embedded in v.
+func canonicalJSON(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
@@ -0,0 +1,60 @@
+package inference
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestRequestHash(t *testing.T) {
+	t.Parallel()
+
+	request := func(text string, keys ...string) *spec.FetchCompletionRequest {
+		schema := map[string]any{}
+		for _, k := range keys {
+			schema[k] = map[string]any{"title": k, "type": "string"}
+		}
+		return &spec.FetchCompletionRequest{
+			ModelParam: spec.ModelParam{
+				Name:       "m",
+				Constraint: &spec.DecodingConstraint{Backend: spec.DecodingConstraintBackendVLLM, JSONSchema: schema},
+			},
+			Inputs: userInputs(text),
+		}
+	}
+
+	base, err := RequestHash(request("hi", "a", "b", "c", "d"))
+	if err != nil {
+		t.Fatalf("RequestHash() error = %v.", err)
+	}
+	if !strings.HasPrefix(base, "sha256:") {
+		t.Fatalf("RequestHash() = %q, want a sha256: prefix.", base)
+	}
+
+	tests := []struct {
+		name     string
+		req      *spec.FetchCompletionRequest
+		wantSame bool
+	}{
+		{name: "ReorderedMap.", req: request("hi", "d", "c", "b", "a"), wantSame: true},
+		{name: "SameContent.", req: request("hi", "a", "b", "c", "d"), wantSame: true},
+		{name: "DifferentText.", req: request("hello", "a", "b", "c", "d")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := RequestHash(tc.req)
+			if err != nil {
+				t.Fatalf("RequestHash() error = %v.", err)
+			}
+			if (got == base) != tc.wantSame {
+				t.Fatalf("RequestHash() = %q, base %q, want same = %v.", got, base, tc.wantSame)
+			}
+		})
+	}
+
+	if _, err := RequestHash(nil); err == nil {
+		t.Fatalf("RequestHash(nil) error = nil, want an error.")
+	}
+}
@@ -0,0 +1,191 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// FlagEvaluator decides whether a feature flag is on for one call. key identifies who the call is made for (the
+// RequestContext tenant, or else its trace ID) and is empty when the context carries neither. Implementations are
+// called on every matching FetchCompletion and must be safe for concurrent use; they typically wrap a feature flag
+// service.
+type FlagEvaluator interface {
+	FlagEnabled(ctx context.Context, flag, key string) bool
+}
+
+// Rollout moves the calls for one provider (and optionally model) to another route while Flag is on for the call.
+// Turning the flag off rolls the traffic back on the next call.
+type Rollout struct {
+	Flag string `json:"flag"`
+	// From matches the requested provider and model. An empty From.Model matches every model of the provider.
+	// Model aliases are matched as written in the request, before resolution.
+	From spec.FallbackRoute `json:"from"`
+	// To is the route used when the flag is on. An empty To.Model keeps the requested model.
+	To spec.FallbackRoute `json:"to"`
+}
+
+// WithRollouts installs flag-driven route rollouts. See SetRollouts; NewProviderSetAPI returns its validation error.
+func WithRollouts(flags FlagEvaluator, rollouts ...Rollout) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		if err := validateRollouts(rollouts); err != nil {
+			ps.optionErrs = append(ps.optionErrs, err)
+			return
+		}
+		ps.flags = flags
+		ps.rollouts = slices.Clone(rollouts)
+	}
+}
+
+// SetRollouts replaces the route rollouts. Before a call is sent, the first rollout matching its provider and model
+// whose flag is on sends it to the rollout's route instead; fallbacks and pipelines apply to the new route as usual.
+// A nil flags or no rollouts disables rollouts.
+func (ps *ProviderSetAPI) SetRollouts(flags FlagEvaluator, rollouts ...Rollout) error {
+	if err := validateRollouts(rollouts); err != nil {
+		return err
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.flags = flags
+	ps.rollouts = slices.Clone(rollouts)
+	return nil
+}
+
+func validateRollouts(rollouts []Rollout) error {
+	for _, r := range rollouts {
+		if r.Flag == "" || r.From.Provider == "" || r.To.Provider == "" {
+			return errors.New("invalid rollout: flag, from provider and to provider are required")
+		}
+		if r.From == r.To {
+			return fmt.Errorf("invalid rollout %q: from and to are the same route", r.Flag)
+		}
+	}
+	return nil
+}
+
+// applyRollouts returns the provider and request the call is sent with after rollouts.
+func (ps *ProviderSetAPI) applyRollouts(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+) (spec.ProviderName, *spec.FetchCompletionRequest) {
+	ps.mu.RLock()
+	flags, rollouts := ps.flags, ps.rollouts
+	ps.mu.RUnlock()
+	if flags == nil || len(rollouts) == 0 {
+		return provider, req
+	}
+
+	var key string
+	if rc, ok := spec.RequestContextFromContext(ctx); ok {
+		key = rc.TenantID
+		if key == "" {
+			key = rc.TraceID
+		}
+	}
+	for _, r := range rollouts {
+		if r.From.Provider != provider || (r.From.Model != "" && r.From.Model != req.ModelParam.Name) {
+			continue
+		}
+		if !flags.FlagEnabled(ctx, r.Flag, key) {
+			continue
+		}
+		logutil.Debug("fetch completion rolled out",
+			"flag", r.Flag, "provider", provider, "toProvider", r.To.Provider, "toModel", r.To.Model)
+		if r.To.Model == "" {
+			return r.To.Provider, req
+		}
+		return r.To.Provider, routeRequest(req, r.To)
+	}
+	return provider, req
+}
+
+// FlagRule is the state of one flag in PercentageFlags.
+type FlagRule struct {
+	// Percent is the share of keys the flag is on for, from 0 to 100. A key keeps its decision as Percent grows.
+	// Calls without a key are decided at random with the same probability.
+	Percent float64 `json:"percent"`
+	// Allow lists keys (tenants) the flag is always on for, e.g. internal users.
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists keys the flag is always off for. Deny wins over Allow.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// PercentageFlags is a built-in FlagEvaluator ramping flags by percentage with allow and deny lists. Rules can be
+// changed at any time; unknown flags are off. The zero value is not usable; use NewPercentageFlags.
+type PercentageFlags struct {
+	mu    sync.RWMutex
+	rules map[string]FlagRule
+}
+
+// NewPercentageFlags returns a PercentageFlags with the given rules by flag name.
+func NewPercentageFlags(rules map[string]FlagRule) *PercentageFlags {
+	f := &PercentageFlags{rules: make(map[string]FlagRule, len(rules))}
+	for flag, rule := range rules {
+		f.rules[flag] = cloneFlagRule(rule)
+	}
+	return f
+}
+
+// Set replaces the rule of flag.
+func (f *PercentageFlags) Set(flag string, rule FlagRule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules[flag] = cloneFlagRule(rule)
+}
+
+// Disable turns flag off for every key, rolling back its rollouts.
+func (f *PercentageFlags) Disable(flag string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.rules, flag)
+}
+
+// Rule returns the rule of flag.
+func (f *PercentageFlags) Rule(flag string) (FlagRule, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	rule, ok := f.rules[flag]
+	return cloneFlagRule(rule), ok
+}
+
+func (f *PercentageFlags) FlagEnabled(_ context.Context, flag, key string) bool {
+	f.mu.RLock()
+	rule, ok := f.rules[flag]
+	f.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if key != "" {
+		if slices.Contains(rule.Deny, key) {
+			return false
+		}
+		if slices.Contains(rule.Allow, key) {
+			return true
+		}
+	}
+	switch {
+	case rule.Percent <= 0:
+		return false
+	case rule.Percent >= 100:
+		return true
+	case key == "":
+		return rand.Float64()*100 < rule.Percent
+	default:
+		return sdkutil.HashBucket(flag, key)*100 < rule.Percent
+	}
+}
+
+func cloneFlagRule(rule FlagRule) FlagRule {
+	rule.Allow = slices.Clone(rule.Allow)
+	rule.Deny = slices.Clone(rule.Deny)
+	return rule
+}
@@ -0,0 +1,113 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestRollouts(t *testing.T) {
+	t.Parallel()
+
+	flags := NewPercentageFlags(nil)
+	ps := newTestProviderSet(t)
+	for _, name := range []spec.ProviderName{"old", "new"} {
+		addTestProvider(t, ps, name, &AddProviderConfig{
+			SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+			Origin:                   "http://127.0.0.1:1",
+			ChatCompletionPathPrefix: "/v1/chat/completions",
+		})
+	}
+	if err := ps.SetRollouts(flags, Rollout{
+		Flag: "new-model",
+		From: spec.FallbackRoute{Provider: "old", Model: "m1"},
+		To:   spec.FallbackRoute{Provider: "new", Model: "m2"},
+	}); err != nil {
+		t.Fatalf("SetRollouts() error = %v.", err)
+	}
+	if err := ps.SetRollouts(flags, Rollout{Flag: "f", From: spec.FallbackRoute{Provider: "old"}}); err == nil {
+		t.Fatalf("SetRollouts() without a target error = nil, want an error.")
+	}
+	noTarget := Rollout{Flag: "f", From: spec.FallbackRoute{Provider: "old"}}
+	if _, err := NewProviderSetAPI(WithRollouts(flags, noTarget)); err == nil {
+		t.Fatalf("NewProviderSetAPI() with a rollout without a target error = nil, want an error.")
+	}
+
+	route := func(ctx context.Context, model spec.ModelName) (spec.ProviderName, spec.ModelName) {
+		t.Helper()
+		resp, err := ps.FetchCompletion(ctx, "old", &spec.FetchCompletionRequest{
+			ModelParam: spec.ModelParam{Name: model},
+			Inputs:     userInputs("hi"),
+		}, &spec.FetchCompletionOptions{DryRun: true})
+		if err != nil {
+			t.Fatalf("FetchCompletion() error = %v.", err)
+		}
+		return resp.Metadata.Provider, resp.Metadata.ResolvedModel
+	}
+	tenant := spec.WithRequestContext(t.Context(), spec.RequestContext{TenantID: "acme"})
+
+	steps := []struct {
+		name         string
+		rule         *FlagRule
+		ctx          context.Context
+		model        spec.ModelName
+		wantProvider spec.ProviderName
+		wantModel    spec.ModelName
+	}{
+		{name: "FlagUnknown.", ctx: tenant, model: "m1", wantProvider: "old", wantModel: "m1"},
+		{
+			name: "Allowlisted.", rule: &FlagRule{Allow: []string{"acme"}},
+			ctx: tenant, model: "m1", wantProvider: "new", wantModel: "m2",
+		},
+		{name: "OtherModel.", ctx: tenant, model: "m3", wantProvider: "old", wantModel: "m3"},
+		{name: "NoKey.", ctx: t.Context(), model: "m1", wantProvider: "old", wantModel: "m1"},
+		{
+			name: "FullRamp.", rule: &FlagRule{Percent: 100},
+			ctx: t.Context(), model: "m1", wantProvider: "new", wantModel: "m2",
+		},
+		{
+			name: "Denied.", rule: &FlagRule{Percent: 100, Deny: []string{"acme"}},
+			ctx: tenant, model: "m1", wantProvider: "old", wantModel: "m1",
+		},
+		{name: "RolledBack.", rule: &FlagRule{}, ctx: t.Context(), model: "m1", wantProvider: "old", wantModel: "m1"},
+	}
+	for _, s := range steps {
+		if s.rule != nil {
+			flags.Set("new-model", *s.rule)
+		}
+		if p, m := route(s.ctx, s.model); p != s.wantProvider || m != s.wantModel {
+			t.Fatalf("%s routed to %s/%s, want %s/%s.", s.name, p, m, s.wantProvider, s.wantModel)
+		}
+	}
+}
+
+func TestPercentageFlags(t *testing.T) {
+	t.Parallel()
+
+	flags := NewPercentageFlags(map[string]FlagRule{"ramp": {Percent: 10}})
+	on := map[string]bool{}
+	for i := range 2000 {
+		key := fmt.Sprintf("tenant-%d", i)
+		if flags.FlagEnabled(t.Context(), "ramp", key) {
+			on[key] = true
+		}
+	}
+	if share := float64(len(on)) / 2000; share < 0.08 || share > 0.12 {
+		t.Fatalf("enabled share = %.3f, want about 0.1.", share)
+	}
+
+	// Growing the ramp keeps every key that was already on.
+	flags.Set("ramp", FlagRule{Percent: 50})
+	for key := range on {
+		if !flags.FlagEnabled(t.Context(), "ramp", key) {
+			t.Fatalf("FlagEnabled(%s) = false after the ramp grew, want true.", key)
+		}
+	}
+
+	flags.Disable("ramp")
+	if _, ok := flags.Rule("ramp"); ok || flags.FlagEnabled(t.Context(), "ramp", "tenant-1") {
+		t.Fatalf("flag still enabled after Disable().")
+	}
+}
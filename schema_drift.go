@@ -0,0 +1,96 @@
+package inference
+
+import (
+	"encoding/json"
+	"maps"
+	"strings"
+
+	"github.com/flexigpt/inference-go/events"
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// checkOutputSchema validates the output text of resp against the JSON schema requested in modelParam. On a
+// violation it records the violations in resp.Metadata, keeps the payload in resp.DebugDetails, logs a warning and
+// publishes a schema violation event. Responses without output text (e.g. only tool calls) are not checked.
+func (ps *ProviderSetAPI) checkOutputSchema(
+	provider spec.ProviderName,
+	modelParam *spec.ModelParam,
+	resp *spec.FetchCompletionResponse,
+	requestID string,
+) {
+	schema := requestedOutputSchema(modelParam)
+	if schema == nil || resp == nil {
+		return
+	}
+	text := strings.TrimSpace(outputText(resp.Outputs))
+	if text == "" {
+		return
+	}
+
+	var violations []string
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		violations = []string{"/: invalid JSON: " + err.Error()}
+	} else if dec.More() {
+		violations = []string{"/: unexpected data after the JSON value"}
+	} else {
+		violations = sdkutil.ValidateJSONSchema(schema.Schema, value)
+	}
+	if len(violations) == 0 {
+		return
+	}
+
+	if resp.Metadata == nil {
+		resp.Metadata = &spec.ResponseMetadata{}
+	}
+	resp.Metadata.SchemaViolations = violations
+	resp.DebugDetails = withSchemaViolation(resp.DebugDetails, violations, text)
+
+	verr := &spec.SchemaViolationError{
+		Provider:   provider,
+		Model:      modelParam.Name,
+		Schema:     schema.Name,
+		Violations: violations,
+	}
+	logutil.Warn("structured output violates schema",
+		"provider", provider, "model", modelParam.Name, "schema", schema.Name, "violations", len(violations))
+	ps.eventBus.Publish(events.Event{
+		Kind:      events.KindSchemaViolation,
+		RequestID: requestID,
+		Provider:  provider,
+		Model:     modelParam.Name,
+		Err:       verr,
+	})
+}
+
+func requestedOutputSchema(modelParam *spec.ModelParam) *spec.JSONSchemaParam {
+	op := modelParam.OutputParam
+	if op == nil || op.Format == nil || op.Format.Kind != spec.OutputFormatKindJSONSchema {
+		return nil
+	}
+	if p := op.Format.JSONSchemaParam; p != nil && p.Schema != nil {
+		return p
+	}
+	return nil
+}
+
+// withSchemaViolation adds the violations and the invalid payload under "schemaViolation" to a copy of debug details
+// that are empty or a map. Other debug details are left as they are.
+func withSchemaViolation(details any, violations []string, payload string) any {
+	entry := map[string]any{"violations": violations, "payload": payload}
+	switch d := details.(type) {
+	case nil:
+		return map[string]any{"schemaViolation": entry}
+	case map[string]any:
+		// The map may be shared with other callers, e.g. by a deduplicated call.
+		out := maps.Clone(d)
+		out["schemaViolation"] = entry
+		return out
+	default:
+		return details
+	}
+}
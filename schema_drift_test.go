@@ -0,0 +1,120 @@
+package inference
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/events"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionSchemaViolation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "Valid.", content: `{"city":"Paris","temp":21}`},
+		{name: "Violation.", content: `{"city":"Paris"}`, want: []string{`/: missing required property "temp"`}},
+		{
+			name:    "NotJSON.",
+			content: `Paris, 21C`,
+			want:    []string{"/: invalid JSON: invalid character 'P' looking for beginning of value"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"id":"c1","object":"chat.completion","created":0,"model":"m",` +
+					`"choices":[{"index":0,"message":{"role":"assistant","content":` + strconv.Quote(tc.content) +
+					`},"finish_reason":"stop"}]}`))
+			}))
+			t.Cleanup(srv.Close)
+
+			bus := events.NewBus()
+			got := make(chan events.Event, 1)
+			sub := bus.Subscribe(func(ev events.Event) { got <- ev }, events.SubscribeOptions{
+				Kinds: []events.Kind{events.KindSchemaViolation},
+			})
+			t.Cleanup(sub.Close)
+			ps := newTestProviderSet(t, WithEventBus(bus))
+			addTestProvider(t, ps, "openai", &AddProviderConfig{
+				SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+				Origin:                   srv.URL,
+				ChatCompletionPathPrefix: "/chat/completions",
+			})
+
+			resp, err := ps.FetchCompletion(t.Context(), "openai", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{
+					Name: "m",
+					OutputParam: &spec.OutputParam{Format: &spec.OutputFormat{
+						Kind: spec.OutputFormatKindJSONSchema,
+						JSONSchemaParam: &spec.JSONSchemaParam{
+							Name: "weather",
+							Schema: map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"city": map[string]any{"type": "string"},
+									"temp": map[string]any{"type": "number"},
+								},
+								"required": []any{"city", "temp"},
+							},
+						},
+					}},
+				},
+				Inputs: userInputs("Weather in Paris?"),
+			}, nil)
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+			if v := resp.Metadata.SchemaViolations; len(v) != len(tc.want) || (len(v) > 0 && v[0] != tc.want[0]) {
+				t.Fatalf("SchemaViolations = %q, want %q.", v, tc.want)
+			}
+			if tc.want == nil {
+				select {
+				case ev := <-got:
+					t.Fatalf("got schema violation event %+v for a valid output.", ev)
+				case <-time.After(50 * time.Millisecond):
+				}
+				return
+			}
+
+			dd, _ := resp.DebugDetails.(map[string]any)
+			entry, _ := dd["schemaViolation"].(map[string]any)
+			if entry["payload"] != tc.content {
+				t.Fatalf("DebugDetails = %+v, want the invalid payload.", resp.DebugDetails)
+			}
+			select {
+			case ev := <-got:
+				var verr *spec.SchemaViolationError
+				if !errors.As(ev.Err, &verr) || verr.Schema != "weather" || ev.Provider != "openai" {
+					t.Fatalf("event = %+v, want a schema violation of weather from openai.", ev)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("no schema violation event published.")
+			}
+		})
+	}
+}
+
+func TestWithSchemaViolation(t *testing.T) {
+	t.Parallel()
+
+	details := map[string]any{"requestDetails": "r"}
+	got, _ := withSchemaViolation(details, []string{"/: bad"}, "{}").(map[string]any)
+	if got["requestDetails"] != "r" || got["schemaViolation"] == nil {
+		t.Fatalf("withSchemaViolation() = %+v, want the details and the violation.", got)
+	}
+	if _, ok := details["schemaViolation"]; ok {
+		t.Fatalf("withSchemaViolation() modified the input details: %+v.", details)
+	}
+}
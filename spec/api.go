@@ -2,7 +2,14 @@ package spec
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
 	"time"
 )
 
@@ -16,6 +23,22 @@ const (
 
 	DefaultOpenAIOrigin                = "https://api.openai.com"
 	DefaultOpenAIChatCompletionsPrefix = "/v1/chat/completions"
+	DefaultOpenAICompletionsPrefix     = "/v1/completions"
+
+	DefaultOllamaOrigin     = "http://localhost:11434"
+	DefaultOllamaChatPrefix = "/api/chat"
+
+	DefaultCohereOrigin     = "https://api.cohere.com"
+	DefaultCohereChatPrefix = "/v2/chat"
+
+	DefaultOpenRouterOrigin     = "https://openrouter.ai"
+	DefaultOpenRouterChatPrefix = "/api/v1/chat/completions"
+
+	DefaultPerplexityOrigin     = "https://api.perplexity.ai"
+	DefaultPerplexityChatPrefix = "/chat/completions"
+
+	DefaultFireworksOrigin     = "https://api.fireworks.ai"
+	DefaultFireworksChatPrefix = "/inference/v1/chat/completions"
 
 	DefaultFileDataMIME  = "application/octet-stream"
 	DefaultImageDataMIME = "image/png"
@@ -27,8 +50,58 @@ const (
 	ProviderSDKTypeAnthropic             ProviderSDKType = "providerSDKTypeAnthropicMessages"
 	ProviderSDKTypeOpenAIChatCompletions ProviderSDKType = "providerSDKTypeOpenAIChatCompletions"
 	ProviderSDKTypeOpenAIResponses       ProviderSDKType = "providerSDKTypeOpenAIResponses"
+	// ProviderSDKTypeOpenAICompatibleSSE is an OpenAI Chat Completions compatible backend whose streaming chunks
+	// follow a custom schema, described by ProviderParam.SSEStreamSchema.
+	ProviderSDKTypeOpenAICompatibleSSE ProviderSDKType = "providerSDKTypeOpenAICompatibleSSE"
+	// ProviderSDKTypeOpenAICompletions is the legacy text completions API (/v1/completions) still served by
+	// self-hosted servers and needed by base models. Inputs are flattened into a single prompt; tools are not
+	// supported.
+	ProviderSDKTypeOpenAICompletions ProviderSDKType = "providerSDKTypeOpenAICompletions"
+	// ProviderSDKTypeOllama is Ollama's native chat API (/api/chat), with thinking, tool calls and local model
+	// management. No API key is needed.
+	ProviderSDKTypeOllama ProviderSDKType = "providerSDKTypeOllama"
+	// ProviderSDKTypeCohere is Cohere's v2 Chat API, with tool calls and document-grounded citations.
+	ProviderSDKTypeCohere ProviderSDKType = "providerSDKTypeCohere"
+	// ProviderSDKTypeOpenRouter is OpenRouter's OpenAI Chat Completions compatible API, with provider routing,
+	// model fallbacks and usage accounting configured by ProviderParam.OpenRouter.
+	ProviderSDKTypeOpenRouter ProviderSDKType = "providerSDKTypeOpenRouter"
+	// ProviderSDKTypePerplexity is Perplexity's Sonar Chat Completions compatible API. The citations and search
+	// results of grounded answers are returned as URL citations and a web search tool output.
+	ProviderSDKTypePerplexity ProviderSDKType = "providerSDKTypePerplexity"
+	// ProviderSDKTypeFireworks is Fireworks AI's Chat Completions compatible API. Decoding constraints default to
+	// Fireworks' grammar and JSON schema response formats, and FireFunction tool calls use regular tool choices.
+	ProviderSDKTypeFireworks ProviderSDKType = "providerSDKTypeFireworks"
+	// ProviderSDKTypeOpenAICompatible is a "mostly OpenAI compatible" Chat Completions server (LM Studio, llama.cpp
+	// server, LocalAI, KoboldCpp). Request fields the server does not support, per ProviderParam.Capabilities, are
+	// dropped instead of sent.
+	ProviderSDKTypeOpenAICompatible ProviderSDKType = "providerSDKTypeOpenAICompatible"
 )
 
+// SSEStreamSchema describes where streaming deltas live inside each Server-Sent Events data payload of an
+// OpenAI-compatible backend. Paths use gjson syntax (e.g. "choices.0.delta.content"). Empty paths use the OpenAI Chat
+// Completions defaults; set a path to "-" to disable it.
+type SSEStreamSchema struct {
+	TextDeltaPath      string `json:"textDeltaPath,omitempty"`
+	ReasoningDeltaPath string `json:"reasoningDeltaPath,omitempty"`
+
+	// ToolCallsPath points to an array of tool call deltas. The per-element paths are relative to each element.
+	ToolCallsPath         string `json:"toolCallsPath,omitempty"`
+	ToolCallIndexPath     string `json:"toolCallIndexPath,omitempty"`
+	ToolCallIDPath        string `json:"toolCallIDPath,omitempty"`
+	ToolCallNamePath      string `json:"toolCallNamePath,omitempty"`
+	ToolCallArgumentsPath string `json:"toolCallArgumentsPath,omitempty"`
+
+	FinishReasonPath         string `json:"finishReasonPath,omitempty"`
+	UsageInputTokensPath     string `json:"usageInputTokensPath,omitempty"`
+	UsageOutputTokensPath    string `json:"usageOutputTokensPath,omitempty"`
+	UsageCachedTokensPath    string `json:"usageCachedTokensPath,omitempty"`
+	UsageReasoningTokensPath string `json:"usageReasoningTokensPath,omitempty"`
+	ErrorMessagePath         string `json:"errorMessagePath,omitempty"`
+
+	// DoneSentinel is the data payload that terminates the stream. Defaults to "[DONE]".
+	DoneSentinel string `json:"doneSentinel,omitempty"`
+}
+
 // ProviderParam represents information about a provider.
 type ProviderParam struct {
 	Name                     ProviderName      `json:"name"`
@@ -38,6 +111,343 @@ type ProviderParam struct {
 	ChatCompletionPathPrefix string            `json:"chatCompletionPathPrefix"`
 	APIKeyHeaderKey          string            `json:"apiKeyHeaderKey"`
 	DefaultHeaders           map[string]string `json:"defaultHeaders"`
+
+	// SSEStreamSchema is used by ProviderSDKTypeOpenAICompatibleSSE providers. Nil means OpenAI defaults.
+	SSEStreamSchema *SSEStreamSchema `json:"sseStreamSchema,omitempty"`
+
+	// Quirks adapt requests to backends with partial API support. Nil means none.
+	Quirks *ProviderQuirks `json:"quirks,omitempty"`
+
+	// Capabilities describe what a ProviderSDKTypeOpenAICompatible server supports. Nil means
+	// DefaultOpenAICompatibleCapabilities.
+	Capabilities *OpenAICompatibleCapabilities `json:"capabilities,omitempty"`
+
+	// DeveloperRole selects the models whose system prompt is sent with the developer role (OpenAI Chat
+	// Completions). Nil means DefaultDeveloperRoleModels for the "openai" provider and the system role otherwise.
+	DeveloperRole *DeveloperRolePolicy `json:"developerRole,omitempty"`
+
+	// Timeouts set the default request timeout per model. Nil means DefaultAPITimeout. ModelParam.Timeout overrides.
+	Timeouts *ProviderTimeouts `json:"timeouts,omitempty"`
+
+	// OriginFailover adds fallback origins (e.g. other regions) tried when Origin fails. Nil means Origin only.
+	OriginFailover *OriginFailover `json:"originFailover,omitempty"`
+
+	// Azure switches the OpenAI Chat Completions and Responses adapters to Azure OpenAI routing. Nil means OpenAI.
+	Azure *AzureOpenAI `json:"azure,omitempty"`
+
+	// Ollama configures ProviderSDKTypeOllama providers. Nil means server defaults.
+	Ollama *OllamaOptions `json:"ollama,omitempty"`
+
+	// OpenRouter configures ProviderSDKTypeOpenRouter providers. Nil means OpenRouter defaults.
+	OpenRouter *OpenRouterOptions `json:"openRouter,omitempty"`
+
+	// Vertex targets Google Vertex AI: Claude through the Anthropic adapter, Gemini and other models through the
+	// OpenAI Chat Completions adapter (Vertex's OpenAI-compatible endpoint). It requires Credentials.
+	Vertex *VertexAI `json:"vertex,omitempty"`
+
+	// Credentials, if set, supplies a bearer token for every request instead of APIKey, e.g. OAuth2 access tokens
+	// from Google Application Default Credentials or a service account.
+	Credentials CredentialProvider `json:"-"`
+
+	// HTTPClient, if set, sends all requests of the provider, e.g. a fetch-based client when running as js/wasm in a
+	// browser. A CompletionDebugger wraps it. Nil means the SDK default client.
+	HTTPClient *http.Client `json:"-"`
+}
+
+// OllamaOptions are sent with every Ollama chat request.
+type OllamaOptions struct {
+	// KeepAlive is how long the model stays loaded after a request, e.g. "10m", "-1" (forever) or "0" (unload).
+	KeepAlive string `json:"keepAlive,omitempty"`
+	// NumCtx is the context window size in tokens.
+	NumCtx int `json:"numCtx,omitempty"`
+}
+
+// OpenRouterOptions are sent with every OpenRouter chat request. Usage accounting is always requested, so
+// responses carry Usage.Cost.
+type OpenRouterOptions struct {
+	// Provider sets the upstream provider routing preferences.
+	Provider *OpenRouterProviderPreferences `json:"provider,omitempty"`
+	// FallbackModels are tried in order when the requested model is unavailable or fails.
+	FallbackModels []ModelName `json:"fallbackModels,omitempty"`
+	// Transforms are prompt transforms applied by OpenRouter, e.g. "middle-out".
+	Transforms []string `json:"transforms,omitempty"`
+	// AppURL and AppTitle identify the calling app (HTTP-Referer and X-Title headers) in OpenRouter rankings.
+	AppURL   string `json:"appURL,omitempty"`
+	AppTitle string `json:"appTitle,omitempty"`
+}
+
+// OpenRouterProviderPreferences select and order the upstream providers serving a model.
+type OpenRouterProviderPreferences struct {
+	// Order lists provider slugs to try first, e.g. "anthropic" or "together".
+	Order []string `json:"order,omitempty"`
+	// AllowFallbacks allows providers outside Order when they all fail. Nil means OpenRouter's default (true).
+	AllowFallbacks *bool `json:"allowFallbacks,omitempty"`
+	// RequireParameters only routes to providers supporting every request parameter.
+	RequireParameters bool `json:"requireParameters,omitempty"`
+	// DataCollection is "allow" or "deny" for providers that may store prompts.
+	DataCollection string   `json:"dataCollection,omitempty"`
+	Only           []string `json:"only,omitempty"`
+	Ignore         []string `json:"ignore,omitempty"`
+	// Sort orders providers by "price", "throughput" or "latency" instead of load balancing.
+	Sort string `json:"sort,omitempty"`
+}
+
+// CredentialProvider returns a bearer token for a request. It is called for every request, so implementations
+// should cache tokens until they expire (e.g. an oauth2.ReuseTokenSource).
+type CredentialProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// CredentialProviderFunc adapts a function to CredentialProvider.
+type CredentialProviderFunc func(ctx context.Context) (string, error)
+
+func (f CredentialProviderFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// VertexAI identifies a Google Cloud project and region serving Vertex AI models.
+type VertexAI struct {
+	ProjectID string `json:"projectID"`
+	// Region is the Vertex AI location, e.g. "us-east5", or "global".
+	Region string `json:"region"`
+}
+
+// Validate reports a missing project or region.
+func (v *VertexAI) Validate() error {
+	if v == nil {
+		return nil
+	}
+	if strings.TrimSpace(v.ProjectID) == "" || strings.TrimSpace(v.Region) == "" {
+		return errors.New("vertex ai: projectID and region are required")
+	}
+	return nil
+}
+
+// Origin returns the Vertex AI endpoint of the region.
+func (v *VertexAI) Origin() string {
+	if v.Region == "global" {
+		return "https://aiplatform.googleapis.com"
+	}
+	return "https://" + v.Region + "-aiplatform.googleapis.com"
+}
+
+// ProjectPath returns the resource path of the project location, e.g. "/v1/projects/p/locations/us-east5".
+func (v *VertexAI) ProjectPath() string {
+	return "/v1/projects/" + v.ProjectID + "/locations/" + v.Region
+}
+
+// OpenAIChatCompletionsPrefix returns the path of Vertex's OpenAI-compatible Chat Completions endpoint.
+func (v *VertexAI) OpenAIChatCompletionsPrefix() string {
+	return v.ProjectPath() + "/endpoints/openapi/chat/completions"
+}
+
+// AzureOpenAI configures an Azure OpenAI resource. Origin is the resource endpoint (e.g.
+// "https://my-resource.openai.azure.com"); requests go to its /openai paths with the api-version query parameter and
+// the API key in the api-key header. The model name is used as the deployment name: Chat Completions routes to
+// /openai/deployments/{deployment}/chat/completions, Responses sends the deployment as the model.
+type AzureOpenAI struct {
+	// APIVersion is the api-version query parameter, e.g. "2024-10-21". Required.
+	APIVersion string `json:"apiVersion"`
+	// Deployments maps model names to deployment names. Models that are not listed are deployment names already.
+	Deployments map[ModelName]string `json:"deployments,omitempty"`
+}
+
+// Validate reports a missing API version.
+func (a *AzureOpenAI) Validate() error {
+	if a == nil {
+		return nil
+	}
+	if strings.TrimSpace(a.APIVersion) == "" {
+		return errors.New("azure openai: apiVersion is required")
+	}
+	return nil
+}
+
+// Deployment returns the deployment name of model.
+func (a *AzureOpenAI) Deployment(model ModelName) ModelName {
+	if a == nil {
+		return model
+	}
+	if d, ok := a.Deployments[model]; ok && d != "" {
+		return ModelName(d)
+	}
+	return model
+}
+
+const DefaultOriginCooldownMillis = 30000
+
+// OriginFailover configures fallback origins for a provider. A request that fails with a network error or a 5xx
+// status is retried on the next origin, and the failed origin is skipped until its cooldown expires.
+type OriginFailover struct {
+	// Origins are the fallback origins, tried after the primary Origin in order.
+	// Like Origin, they may carry a path that replaces the primary origin's path.
+	Origins []string `json:"origins"`
+	// CooldownMillis is how long a failed origin is skipped. Defaults to DefaultOriginCooldownMillis.
+	CooldownMillis int `json:"cooldownMillis,omitempty"`
+	// LatencyBased orders healthy origins by observed latency instead of configuration order.
+	LatencyBased bool `json:"latencyBased,omitempty"`
+}
+
+// Validate reports malformed origins.
+func (f *OriginFailover) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, o := range f.Origins {
+		u, err := url.Parse(o)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid fallback origin %q", o)
+		}
+	}
+	return nil
+}
+
+const DefaultQuirksMaxTokens = 4096
+
+// ProviderQuirks describe fields that an (OpenAI-compatible) backend ignores or rejects. They are applied by the OpenAI
+// Chat Completions adapter (all flags) and the OpenAI Responses adapter (parallel tools, max tokens, temperature), so
+// callers do not have to special-case providers.
+type ProviderQuirks struct {
+	// NoDeveloperRole sends instructions with the system role even for models that would get the developer role.
+	NoDeveloperRole bool `json:"noDeveloperRole,omitempty"`
+	// NoParallelToolCalls never sends parallel_tool_calls, even when ToolPolicy.DisableParallel is set.
+	NoParallelToolCalls bool `json:"noParallelToolCalls,omitempty"`
+	// RequiresMaxTokens always sends a max tokens limit: MaxOutputLength, or MaxTokens if unset.
+	RequiresMaxTokens bool `json:"requiresMaxTokens,omitempty"`
+	// MaxTokens is the limit used by RequiresMaxTokens. Zero means DefaultQuirksMaxTokens.
+	MaxTokens int `json:"maxTokens,omitempty"`
+	// LegacyMaxTokensField sends max_tokens instead of max_completion_tokens (Chat Completions).
+	LegacyMaxTokensField bool `json:"legacyMaxTokensField,omitempty"`
+	// FixedTemperature, if set, replaces any requested temperature, e.g. 1 for models that reject other values.
+	FixedTemperature *float64 `json:"fixedTemperature,omitempty"`
+	// NoStreamUsage omits stream_options.include_usage when streaming (Chat Completions).
+	NoStreamUsage bool `json:"noStreamUsage,omitempty"`
+}
+
+// OpenAICompatibleCapabilities describe the Chat Completions features of a ProviderSDKTypeOpenAICompatible server.
+// Fields of unsupported features are left out of the request (and so out of ResponseMetadata.EffectiveParams), so a
+// request written for OpenAI still works against the server with reduced functionality.
+type OpenAICompatibleCapabilities struct {
+	// SupportsTools sends tools and tool_choice. Without it, tool definitions are dropped and the model answers in
+	// text; tool calls and outputs already in the inputs are still sent.
+	SupportsTools bool `json:"supportsTools,omitempty"`
+	// SupportsParallelToolCalls sends parallel_tool_calls.
+	SupportsParallelToolCalls bool `json:"supportsParallelToolCalls,omitempty"`
+	// SupportsReasoningEffort sends reasoning_effort for ReasoningTypeSingleWithLevels.
+	SupportsReasoningEffort bool `json:"supportsReasoningEffort,omitempty"`
+	// SupportsStreamUsage requests token usage in the final streaming chunk (stream_options.include_usage).
+	SupportsStreamUsage bool `json:"supportsStreamUsage,omitempty"`
+	// SupportsJSONSchema sends response_format for OutputFormatKindJSONSchema.
+	SupportsJSONSchema bool `json:"supportsJSONSchema,omitempty"`
+	// SupportsStopSequences sends stop.
+	SupportsStopSequences bool `json:"supportsStopSequences,omitempty"`
+	// SupportsVerbosity sends verbosity.
+	SupportsVerbosity bool `json:"supportsVerbosity,omitempty"`
+	// SupportsWebSearch sends web_search_options.
+	SupportsWebSearch bool `json:"supportsWebSearch,omitempty"`
+	// SupportsLogprobs sends logprobs and top_logprobs.
+	SupportsLogprobs bool `json:"supportsLogprobs,omitempty"`
+}
+
+// DefaultOpenAICompatibleCapabilities are assumed when a ProviderSDKTypeOpenAICompatible provider has no
+// Capabilities: the features most local servers implement.
+var DefaultOpenAICompatibleCapabilities = OpenAICompatibleCapabilities{
+	SupportsTools:         true,
+	SupportsJSONSchema:    true,
+	SupportsStopSequences: true,
+}
+
+// DefaultDeveloperRoleModels are the model patterns that get the developer role on the "openai" provider when no
+// DeveloperRolePolicy is configured.
+var DefaultDeveloperRoleModels = []string{"o*", "gpt-5*"}
+
+// DeveloperRolePolicy decides whether a model receives its system prompt as a developer message. It lets providers
+// that route OpenAI reasoning models under other names (Azure deployments, OpenRouter "openai/o3") opt in.
+type DeveloperRolePolicy struct {
+	// Models are path.Match patterns matched against the model name, e.g. "o*" or "openai/gpt-5*".
+	// No patterns means the system role is always used.
+	Models []string `json:"models,omitempty"`
+}
+
+// Validate reports malformed patterns.
+func (p *DeveloperRolePolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, m := range p.Models {
+		if _, err := path.Match(m, ""); err != nil {
+			return fmt.Errorf("invalid developer role model pattern %q: %w", m, err)
+		}
+	}
+	return nil
+}
+
+// UsesDeveloperRole reports whether model matches one of the policy's patterns.
+func (p *DeveloperRolePolicy) UsesDeveloperRole(model ModelName) bool {
+	if p == nil {
+		return false
+	}
+	for _, m := range p.Models {
+		if ok, _ := path.Match(m, string(model)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderTimeouts configure the request timeout of a provider for requests without ModelParam.Timeout, e.g. long
+// timeouts for reasoning models and short ones for small models.
+type ProviderTimeouts struct {
+	// DefaultSeconds applies to models without a matching rule. Zero means DefaultAPITimeout.
+	DefaultSeconds int `json:"defaultSeconds,omitempty"`
+	// Models are checked in order; the first rule whose pattern matches the model name applies.
+	Models []ModelTimeout `json:"models,omitempty"`
+}
+
+// ModelTimeout is the timeout of the models matching Pattern.
+type ModelTimeout struct {
+	// Pattern is a path.Match pattern matched against the model name, e.g. "o*" or "*-mini".
+	Pattern string `json:"pattern"`
+	Seconds int    `json:"seconds"`
+}
+
+// Validate reports malformed patterns and non-positive timeouts.
+func (t *ProviderTimeouts) Validate() error {
+	if t == nil {
+		return nil
+	}
+	if t.DefaultSeconds < 0 {
+		return errors.New("invalid default timeout: must not be negative")
+	}
+	for _, m := range t.Models {
+		if _, err := path.Match(m.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid timeout model pattern %q: %w", m.Pattern, err)
+		}
+		if m.Seconds <= 0 {
+			return fmt.Errorf("invalid timeout for model pattern %q: must be positive", m.Pattern)
+		}
+	}
+	return nil
+}
+
+// Timeout returns the timeout of a request: requestSeconds if positive, else the first matching model rule, the
+// provider default, or DefaultAPITimeout.
+func (t *ProviderTimeouts) Timeout(model ModelName, requestSeconds int) time.Duration {
+	if requestSeconds > 0 {
+		return time.Duration(requestSeconds) * time.Second
+	}
+	if t == nil {
+		return DefaultAPITimeout
+	}
+	for _, m := range t.Models {
+		if ok, _ := path.Match(m.Pattern, string(model)); ok {
+			return time.Duration(m.Seconds) * time.Second
+		}
+	}
+	if t.DefaultSeconds > 0 {
+		return time.Duration(t.DefaultSeconds) * time.Second
+	}
+	return DefaultAPITimeout
 }
 
 // StreamContentKind enumerates the kinds of streaming events that can be delivered while a completion is in progress.
@@ -46,6 +456,24 @@ type StreamContentKind string
 const (
 	StreamContentKindText     StreamContentKind = "text"
 	StreamContentKindThinking StreamContentKind = "thinking"
+	StreamContentKindToolCall StreamContentKind = "toolCall"
+	// StreamContentKindImagePartial delivers a partial image of an in-progress image generation.
+	StreamContentKindImagePartial StreamContentKind = "imagePartial"
+	// StreamContentKindLogprobs delivers the log probabilities of text streamed so far.
+	StreamContentKindLogprobs StreamContentKind = "logprobs"
+	// StreamContentKindCitation delivers a citation of the text streamed so far, as soon as the provider reports it.
+	StreamContentKindCitation StreamContentKind = "citation"
+
+	StreamContentKindOutputItem  StreamContentKind = "outputItem"
+	StreamContentKindContentPart StreamContentKind = "contentPart"
+
+	StreamContentKindUsage StreamContentKind = "usage"
+	StreamContentKindError StreamContentKind = "error"
+
+	// StreamContentKindProviderSwitch reports that the stream restarted on a fallback route.
+	StreamContentKindProviderSwitch StreamContentKind = "providerSwitch"
+	// StreamContentKindDone is the last event of a stream, delivered once the call finished.
+	StreamContentKindDone StreamContentKind = "done"
 )
 
 type StreamTextChunk struct {
@@ -54,6 +482,128 @@ type StreamTextChunk struct {
 
 type StreamThinkingChunk struct {
 	Text string `json:"text"`
+	// Truncated marks the last thinking chunk delivered under FetchCompletionOptions.MaxThinkingChars.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// StreamLogprobsChunk carries the log probabilities of the next output tokens. A provider may send them ahead of,
+// or after, the text events of the same tokens.
+type StreamLogprobsChunk struct {
+	Tokens []TokenLogprob `json:"tokens"`
+}
+
+// StreamToolCallPhase is the lifecycle phase of a streamed tool call.
+type StreamToolCallPhase string
+
+const (
+	StreamToolCallPhaseStart  StreamToolCallPhase = "start"
+	StreamToolCallPhaseDelta  StreamToolCallPhase = "delta"
+	StreamToolCallPhaseFinish StreamToolCallPhase = "finish"
+)
+
+// StreamToolCallChunk describes incremental progress of a single tool call.
+//
+// For every tool call a consumer receives exactly one start event, zero or more delta events and one finish event.
+// Multiple tool calls may be in flight at once (parallel tool calls); use Index to correlate them.
+type StreamToolCallChunk struct {
+	Phase StreamToolCallPhase `json:"phase"`
+
+	// Index is the position of this tool call among the tool calls of the response.
+	Index int `json:"index"`
+
+	Type     ToolType `json:"type,omitempty"`
+	ChoiceID string   `json:"choiceID,omitempty"`
+	CallID   string   `json:"callID,omitempty"`
+	Name     string   `json:"name,omitempty"`
+
+	// ArgumentsDelta is the incremental arguments text; set on delta events.
+	ArgumentsDelta string `json:"argumentsDelta,omitempty"`
+	// Arguments is the complete arguments text; set on finish events.
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// StreamImagePartialChunk is a partial rendering of an image being generated. The final image is delivered as an
+// ImageGenerationToolOutput output.
+type StreamImagePartialChunk struct {
+	ItemID string `json:"itemID,omitempty"`
+	// PartialImageIndex is the 0-based index of the partial image within its generation.
+	PartialImageIndex int `json:"partialImageIndex"`
+	// ImageData is the base64 encoded image.
+	ImageData string `json:"imageData"`
+	ImageMIME string `json:"imageMIME,omitzero"`
+}
+
+// StreamLifecyclePhase is the lifecycle phase of a streamed output item or content part.
+type StreamLifecyclePhase string
+
+const (
+	StreamLifecyclePhaseAdded StreamLifecyclePhase = "added"
+	StreamLifecyclePhaseDone  StreamLifecyclePhase = "done"
+)
+
+// StreamOutputItemChunk reports that an output item was added to, or completed in, the response.
+// It lets consumers build the output tree incrementally. Only emitted by providers that expose item lifecycle.
+type StreamOutputItemChunk struct {
+	Phase StreamLifecyclePhase `json:"phase"`
+
+	// OutputIndex is the position of the item in the response output.
+	OutputIndex int    `json:"outputIndex"`
+	ItemID      string `json:"itemID,omitempty"`
+
+	// OutputKind is the normalized kind of the item. Empty if the item type has no normalized equivalent.
+	OutputKind OutputKind `json:"outputKind,omitempty"`
+	// ProviderItemType is the item type as reported by the provider, e.g. "message" or "function_call".
+	ProviderItemType string `json:"providerItemType"`
+
+	// CallID and Name are set for tool call items.
+	CallID string `json:"callID,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// StreamContentPartChunk reports that a content part was added to, or completed in, an output item.
+type StreamContentPartChunk struct {
+	Phase StreamLifecyclePhase `json:"phase"`
+
+	OutputIndex  int    `json:"outputIndex"`
+	ContentIndex int    `json:"contentIndex"`
+	ItemID       string `json:"itemID,omitempty"`
+
+	// ProviderPartType is the part type as reported by the provider, e.g. "output_text" or "refusal".
+	ProviderPartType string `json:"providerPartType"`
+}
+
+// StreamProviderSwitchChunk describes a switch to a fallback route after the stream failed to start.
+type StreamProviderSwitchChunk struct {
+	FromProvider ProviderName `json:"fromProvider"`
+	FromModel    ModelName    `json:"fromModel"`
+	ToProvider   ProviderName `json:"toProvider"`
+	ToModel      ModelName    `json:"toModel"`
+	// Reason is the error of the failed route.
+	Reason string `json:"reason,omitempty"`
+}
+
+// StreamDoneChunk carries the final status and usage of a streamed call, so that consumers of the stream alone
+// (e.g. SSE proxies) need not wait for the FetchCompletionResponse.
+type StreamDoneChunk struct {
+	// Status is completed, incomplete (the output was cut short, e.g. by the output token limit), failed or
+	// cancelled.
+	Status Status `json:"status"`
+	// Usage is the usage of the call as returned in FetchCompletionResponse.Usage, including the cost when known.
+	Usage *Usage `json:"usage,omitempty"`
+	// Error is set for failed and cancelled calls.
+	Error *Error `json:"error,omitempty"`
+}
+
+// StreamErrorChunk is delivered when a stream failed mid-way, before FetchCompletion returns. It is followed only by
+// the Done event. It is not delivered when the failure originated from the StreamHandler itself.
+type StreamErrorChunk struct {
+	Error *Error `json:"error"`
+	// PartialOutput reports whether any events were delivered before the failure.
+	// FetchCompletionResponse.Outputs may then hold the partial output.
+	PartialOutput bool `json:"partialOutput"`
+	// Retryable reports whether the failure looks transient (timeouts, rate limits, server errors, dropped
+	// connections) so that retrying the same request is safe and may succeed.
+	Retryable bool `json:"retryable"`
 }
 
 type StreamEvent struct {
@@ -63,11 +613,52 @@ type StreamEvent struct {
 	Provider ProviderName `json:"provider,omitempty"`
 	Model    ModelName    `json:"model,omitempty"`
 
+	// SequenceNumber is strictly increasing within one FetchCompletion call, starting at 1.
+	// Consumers can use it to deduplicate, reorder and resume streams.
+	SequenceNumber int64 `json:"sequenceNumber"`
+	// OutputItemIndex is the index of the provider output item (or content block) this event belongs to.
+	// Providers without item indexes report 0.
+	OutputItemIndex int `json:"outputItemIndex"`
+	// ContentIndex is the index of the content part (or reasoning summary part) within the output item.
+	ContentIndex int `json:"contentIndex"`
+
 	// Exactly one of the below will be non-nil depending on Kind.
 	Text     *StreamTextChunk     `json:"text,omitempty"`
 	Thinking *StreamThinkingChunk `json:"thinking,omitempty"`
+	ToolCall *StreamToolCallChunk `json:"toolCall,omitempty"`
+
+	ImagePartial *StreamImagePartialChunk `json:"imagePartial,omitempty"`
+	Logprobs     *StreamLogprobsChunk     `json:"logprobs,omitempty"`
+	// Citation is a source of the text of the output item and content part given by OutputItemIndex and
+	// ContentIndex.
+	Citation *Citation `json:"citation,omitempty"`
+
+	OutputItem  *StreamOutputItemChunk  `json:"outputItem,omitempty"`
+	ContentPart *StreamContentPartChunk `json:"contentPart,omitempty"`
+
+	// Usage is delivered once, after all content, when the provider reports usage for a stream.
+	Usage *Usage            `json:"usage,omitempty"`
+	Error *StreamErrorChunk `json:"error,omitempty"`
+
+	ProviderSwitch *StreamProviderSwitchChunk `json:"providerSwitch,omitempty"`
+	// Done is delivered once, as the last event of every streamed call, after any usage or error event. It is not
+	// delivered when the StreamHandler itself failed.
+	Done *StreamDoneChunk `json:"done,omitempty"`
 }
 
+// StreamFlushBoundary controls where buffered text and thinking data may be split into chunks.
+type StreamFlushBoundary string
+
+const (
+	// StreamFlushBoundaryNone flushes raw buffered data on size/time thresholds. This is the default.
+	StreamFlushBoundaryNone StreamFlushBoundary = "none"
+	// StreamFlushBoundaryWord only splits chunks after whitespace.
+	StreamFlushBoundaryWord StreamFlushBoundary = "word"
+	// StreamFlushBoundarySentence only splits chunks after sentence-ending punctuation followed by whitespace, or
+	// after a newline.
+	StreamFlushBoundarySentence StreamFlushBoundary = "sentence"
+)
+
 // StreamConfig controls low-level behavior of streaming delivery. All fields are optional; zero values mean "use
 // library defaults".
 type StreamConfig struct {
@@ -75,10 +666,71 @@ type StreamConfig struct {
 	FlushIntervalMillis int `json:"flushIntervalMillis,omitempty"`
 	// FlushChunkSize is the approximate target size (in bytes/characters) for chunks passed to the StreamHandler.
 	FlushChunkSize int `json:"flushChunkSize,omitempty"`
+	// FlushBoundary restricts size/time based flushes to word or sentence boundaries. Data without a boundary is held
+	// back until a boundary arrives, the buffer grows past 4x FlushChunkSize, the content kind changes, or the stream
+	// ends.
+	FlushBoundary StreamFlushBoundary `json:"flushBoundary,omitempty"`
+
+	// PacingCharsPerSecond, if > 0, enables smooth typing: bursty provider deltas are re-chunked and released at an
+	// even rate of roughly this many characters per second. FlushChunkSize and FlushBoundary are ignored when pacing.
+	PacingCharsPerSecond int `json:"pacingCharsPerSecond,omitempty"`
+	// PacingMaxLagMillis bounds how far paced output may trail the provider. When the backlog would take longer than
+	// this to release, the excess is released immediately. It also bounds how long stream completion waits for the
+	// backlog to drain. Defaults to 2000.
+	PacingMaxLagMillis int `json:"pacingMaxLagMillis,omitempty"`
 }
 
 type StreamHandler func(event StreamEvent) error
 
+// StopPattern is a client-side stop condition. Exactly one of Literal and Regex must be set. The output is cut
+// before the match. Text that was already streamed before a match became visible is not retracted.
+type StopPattern struct {
+	Literal string `json:"literal,omitempty"`
+	Regex   string `json:"regex,omitempty"`
+}
+
+// FallbackRoute is a provider and model to retry a failed call with. An empty Model keeps the requested model.
+type FallbackRoute struct {
+	Provider ProviderName `json:"provider"`
+	Model    ModelName    `json:"model,omitempty"`
+}
+
+// PriorityClass orders requests waiting for admission. Interactive requests are admitted before background ones.
+type PriorityClass string
+
+const (
+	PriorityInteractive PriorityClass = "interactive"
+	PriorityBackground  PriorityClass = "background"
+)
+
+const DefaultProgressIntervalMillis = 1000
+
+// ProgressStatus is the state of a running call.
+type ProgressStatus string
+
+const (
+	// ProgressStatusQueued means the call waits for admission (see PriorityClass).
+	ProgressStatusQueued ProgressStatus = "queued"
+	// ProgressStatusInProgress means the request was sent and the provider is working on it.
+	ProgressStatusInProgress ProgressStatus = "inProgress"
+)
+
+// ProgressEvent reports a running non-streaming call or background response.
+type ProgressEvent struct {
+	// Provider and Model are those of the current attempt, which change after a fallback.
+	Provider ProviderName   `json:"provider"`
+	Model    ModelName      `json:"model"`
+	Attempt  int            `json:"attempt"`
+	Status   ProgressStatus `json:"status"`
+	// ProviderStatus is the status last reported by the provider, e.g. the polled status of a background response;
+	// empty if the provider has not reported one.
+	ProviderStatus Status `json:"providerStatus,omitempty"`
+	// Elapsed is the time since the call started.
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+type ProgressHandler func(event ProgressEvent)
+
 // FetchCompletionOptions controls optional behaviors for FetchCompletion.
 // A nil pointer is treated the same as &FetchCompletionOptions{}.
 type FetchCompletionOptions struct {
@@ -87,13 +739,102 @@ type FetchCompletionOptions struct {
 	// streaming early and propagate that error back to the caller.
 	StreamHandler StreamHandler `json:"-"`
 	StreamConfig  *StreamConfig `json:"streamConfig,omitempty"`
+
+	// Priority is the admission class used when the ProviderSetAPI has an admission queue. Empty means
+	// PriorityInteractive.
+	Priority PriorityClass `json:"priority,omitempty"`
+
+	// Fallbacks are tried in order when the call fails. A streaming call only falls back while nothing has been
+	// streamed; the restart is announced with a StreamContentKindProviderSwitch event and SequenceNumber keeps
+	// increasing across routes.
+	Fallbacks []FallbackRoute `json:"fallbacks,omitempty"`
+	// HedgeAfterMillis, if > 0, enables slow-start hedging for streaming calls with fallbacks: when no content has
+	// arrived from the first route after this many milliseconds, the same request is also started on the first
+	// fallback. The route that streams content first wins and the other attempt is canceled.
+	HedgeAfterMillis int `json:"hedgeAfterMillis,omitempty"`
+
+	// StopPatterns end the text output client-side at the first match, for providers without (flexible) stop
+	// sequences. When streaming, the provider request is aborted as soon as a match is seen.
+	StopPatterns []StopPattern `json:"stopPatterns,omitempty"`
+
+	// MaxThinkingChars, if > 0, caps the thinking text delivered to StreamHandler. The chunk that reaches the cap is
+	// cut and marked Truncated; later thinking chunks are dropped.
+	MaxThinkingChars int `json:"maxThinkingChars,omitempty"`
+	// DropReasoning removes reasoning outputs from the final response, for products that must not display or store
+	// chain-of-thought. Providers that require reasoning to be sent back (Anthropic thinking with tool use) then
+	// lose it on the next turn.
+	DropReasoning bool `json:"dropReasoning,omitempty"`
+
+	// ProgressHandler, if non-nil, is invoked periodically while a non-streaming call is running, e.g. to render a
+	// "thinking for 42s" indicator. It is never invoked concurrently, nor after FetchCompletion returns.
+	ProgressHandler ProgressHandler `json:"-"`
+	// ProgressIntervalMillis is the interval between progress events. Zero means DefaultProgressIntervalMillis.
+	ProgressIntervalMillis int `json:"progressIntervalMillis,omitempty"`
+
+	// DryRun performs all conversion and validation but makes no network call. The response has no outputs and its
+	// DebugDetails hold the serialized provider request (url, stream flag and body, with base64 payloads omitted).
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Pipeline names a preset of retry, timeout, routing, dedupe and debug settings registered with the
+	// ProviderSetAPI (inference.WithPipelines). Settings made here take precedence over the pipeline's.
+	Pipeline string `json:"pipeline,omitempty"`
+
+	// Debugger, if non-nil, replaces the provider's debugger for this call only, e.g. to capture one suspicious
+	// request with full bodies while the default debugger strips them. Such calls are never deduplicated.
+	Debugger CompletionDebugger `json:"-"`
+}
+
+// ResponseMetadata describes how a request was served.
+type ResponseMetadata struct {
+	// Provider is the provider that served the request, which differs from the requested one after a fallback.
+	Provider ProviderName `json:"provider,omitempty"`
+	// RequestedModel is the model name in the request, possibly an alias.
+	RequestedModel ModelName `json:"requestedModel,omitempty"`
+	// ResolvedModel is the concrete model name sent to the provider.
+	ResolvedModel ModelName `json:"resolvedModel,omitempty"`
+	// EffectiveParams are the top-level request parameters actually sent to the provider, after defaults, clamping,
+	// quirks and overrides, in the provider's wire format. Conversation content, instructions and tool definitions
+	// are left out.
+	EffectiveParams map[string]any `json:"effectiveParams,omitempty"`
+	// DetectedLanguage is the ISO 639-1 code of the response text, detected heuristically when ModelParam.Locale
+	// is set. Empty when unknown.
+	DetectedLanguage string `json:"detectedLanguage,omitempty"`
+	// StoppedByPattern is the text that matched a FetchCompletionOptions.StopPatterns entry, if any.
+	StoppedByPattern string `json:"stoppedByPattern,omitempty"`
+	// WebSearchCalls is the number of server-side web search calls the model made for this request.
+	WebSearchCalls int `json:"webSearchCalls,omitempty"`
+	// ResponseID is the provider's ID of the response, when it has one that can be retrieved later.
+	ResponseID string `json:"responseID,omitempty"`
+	// Status is the provider-reported response status, e.g. StatusQueued for a background request.
+	Status Status `json:"status,omitempty"`
+	// SchemaViolations lists how the output text breaks the requested JSON schema, if it does. The invalid payload
+	// is kept under "schemaViolation" in DebugDetails.
+	SchemaViolations []string `json:"schemaViolations,omitempty"`
+	// Experiments maps the experiments the request took part in to the assigned variant (see package experiments).
+	Experiments map[string]string `json:"experiments,omitempty"`
 }
 
+// FetchCompletionResponse is the result of a completion call. When the call fails after the request was built, the
+// response is still returned with the error: Error describes it, Outputs hold whatever output was salvaged (e.g. the
+// text streamed before a dropped connection), and Partial reports whether there is any.
 type FetchCompletionResponse struct {
-	Outputs      []OutputUnion `json:"outputs,omitempty"`
-	Usage        *Usage        `json:"usage,omitempty"`
-	Error        *Error        `json:"error,omitempty"`
-	DebugDetails any           `json:"debugDetails,omitempty"`
+	Outputs []OutputUnion `json:"outputs,omitempty"`
+	// Choices holds the outputs of every candidate, in choice index order, when the provider returned more than one
+	// (ModelParam.NumChoices > 1). The first entry has the same outputs as Outputs.
+	Choices []ChoiceOutputs `json:"choices,omitempty"`
+
+	Usage        *Usage            `json:"usage,omitempty"`
+	Error        *Error            `json:"error,omitempty"`
+	Metadata     *ResponseMetadata `json:"metadata,omitempty"`
+	DebugDetails any               `json:"debugDetails,omitempty"`
+	// Partial reports that the call failed and Outputs hold the output produced before the failure.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// ChoiceOutputs are the outputs of one candidate completion.
+type ChoiceOutputs struct {
+	Index   int           `json:"index"`
+	Outputs []OutputUnion `json:"outputs,omitempty"`
 }
 
 type FetchCompletionRequest struct {
@@ -167,6 +908,297 @@ type CompletionDebugger interface {
 	) (ctxWithSpan context.Context, span CompletionSpan)
 }
 
+// RawResponse is the undecoded result of a raw provider request.
+type RawResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+// PanicError is returned by FetchCompletion when the adapter or the provider SDK panicked while handling the call,
+// e.g. on a malformed provider response. The accompanying response carries the stack in DebugDetails.
+type PanicError struct {
+	Provider ProviderName
+	Value    any
+	Stack    string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in provider %s: %v", e.Provider, e.Value)
+}
+
+// CancelReason says why the context of a call was canceled.
+type CancelReason string
+
+const (
+	// CancelReasonClientDisconnect is a plain cancellation without a cause, e.g. an HTTP client that went away.
+	CancelReasonClientDisconnect CancelReason = "clientDisconnect"
+	// CancelReasonTimeout is an expired deadline.
+	CancelReasonTimeout CancelReason = "timeout"
+	// CancelReasonBudget is a cancellation caused by ErrBudgetExhausted.
+	CancelReasonBudget CancelReason = "budget"
+	// CancelReasonOther is a cancellation with any other cause.
+	CancelReasonOther CancelReason = "other"
+)
+
+// ErrBudgetExhausted is the cause to cancel a call with (see context.WithCancelCause) when a token, cost or time
+// budget ran out.
+var ErrBudgetExhausted = errors.New("budget exhausted")
+
+// CancelReasonOf returns why ctx was canceled, or "" if it was not.
+func CancelReasonOf(ctx context.Context) CancelReason {
+	err := ctx.Err()
+	if err == nil {
+		return ""
+	}
+	cause := context.Cause(ctx)
+	switch {
+	case errors.Is(cause, ErrBudgetExhausted):
+		return CancelReasonBudget
+	case errors.Is(err, context.DeadlineExceeded):
+		return CancelReasonTimeout
+	case errors.Is(cause, context.Canceled):
+		return CancelReasonClientDisconnect
+	default:
+		return CancelReasonOther
+	}
+}
+
+// CanceledError is returned by FetchCompletion when a call failed because its context was canceled. It unwraps to
+// the provider error (which matches context.Canceled or context.DeadlineExceeded) and to the cancel cause.
+type CanceledError struct {
+	Provider ProviderName
+	Reason   CancelReason
+	Cause    error
+	Err      error
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("request to provider %s canceled (%s): %v", e.Provider, e.Reason, e.Err)
+}
+
+func (e *CanceledError) Unwrap() []error {
+	if e.Cause == nil || errors.Is(e.Err, e.Cause) {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+// ModelInfo is registry metadata about a model.
+type ModelInfo struct {
+	Name ModelName `json:"name"`
+	// Provider restricts the entry to one provider. Empty applies to every provider.
+	Provider ProviderName `json:"provider,omitempty"`
+	// DeprecatedAt is the announced deprecation date. Zero means not deprecated.
+	DeprecatedAt time.Time `json:"deprecatedAt,omitzero"`
+	// Replacement is the suggested model to migrate to.
+	Replacement ModelName `json:"replacement,omitempty"`
+	// ContextWindow is the model's context window in tokens, shared by the prompt and the output. When set,
+	// FetchCompletion reserves ModelParam.MaxOutputLength of it and filters the prompt to fit the rest. Zero means
+	// unknown.
+	ContextWindow int `json:"contextWindow,omitempty"`
+}
+
+// DeprecationMode selects what happens when a request targets a model past its deprecation date.
+type DeprecationMode string
+
+const (
+	// DeprecationModeWarn logs a warning (and publishes a deprecation event) and sends the request. It is the default.
+	DeprecationModeWarn DeprecationMode = "warn"
+	// DeprecationModeError fails the request with a *ModelDeprecatedError.
+	DeprecationModeError DeprecationMode = "error"
+	// DeprecationModeOff skips the check.
+	DeprecationModeOff DeprecationMode = "off"
+)
+
+// ModelDeprecatedError reports a request for a model past its deprecation date.
+type ModelDeprecatedError struct {
+	Provider     ProviderName
+	Model        ModelName
+	DeprecatedAt time.Time
+	Replacement  ModelName
+}
+
+func (e *ModelDeprecatedError) Error() string {
+	msg := fmt.Sprintf(
+		"model %s of provider %s is deprecated since %s",
+		e.Model, e.Provider, e.DeprecatedAt.Format(time.DateOnly),
+	)
+	if e.Replacement != "" {
+		msg += ", use " + string(e.Replacement)
+	}
+	return msg
+}
+
+// SchemaViolationError reports a structured output that does not match the requested JSON schema. It is not
+// returned by FetchCompletion: the response is delivered as is, and the error is published with a schema violation
+// event so that model regressions can be monitored.
+type SchemaViolationError struct {
+	Provider ProviderName
+	Model    ModelName
+	// Schema is the JSONSchemaParam.Name of the requested format.
+	Schema     string
+	Violations []string
+}
+
+func (e *SchemaViolationError) Error() string {
+	return fmt.Sprintf(
+		"output of model %s of provider %s violates schema %q: %s",
+		e.Model, e.Provider, e.Schema, strings.Join(e.Violations, "; "),
+	)
+}
+
+// RawRequester is optionally implemented by a CompletionProvider to support raw passthrough requests to endpoints not
+// modeled by spec. Implementations reuse the provider's auth, base URL, debugger and retry configuration.
+type RawRequester interface {
+	// FetchRaw sends body (may be nil) to path, which is relative to the provider base URL. Responses with an error
+	// status are returned with their status and body; the error is reserved for requests that got no response.
+	FetchRaw(ctx context.Context, method, path string, body json.RawMessage) (*RawResponse, error)
+}
+
+// FIMPromptTemplate builds a fill-in-the-middle prompt from sentinel tokens, for servers without a suffix field, e.g.
+// StarCoder's "<fim_prefix>", "<fim_suffix>" and "<fim_middle>".
+type FIMPromptTemplate struct {
+	PrefixToken string `json:"prefixToken"`
+	SuffixToken string `json:"suffixToken"`
+	MiddleToken string `json:"middleToken"`
+}
+
+// FIMCompletionRequest asks a code model for the text between Prefix and Suffix.
+type FIMCompletionRequest struct {
+	// ModelParam supplies the model, MaxOutputLength, Temperature, TopP, the penalties, StopSequences, Stream and
+	// Timeout. Other fields are ignored.
+	ModelParam ModelParam `json:"modelParam"`
+	Prefix     string     `json:"prefix"`
+	Suffix     string     `json:"suffix,omitempty"`
+
+	// PromptTemplate, if set, sends Prefix and Suffix inside the prompt instead of as the suffix field.
+	PromptTemplate *FIMPromptTemplate `json:"promptTemplate,omitempty"`
+}
+
+// FIMCompleter is optionally implemented by a CompletionProvider that serves fill-in-the-middle completions. The
+// response holds the middle text as a single output message.
+type FIMCompleter interface {
+	FetchFIMCompletion(
+		ctx context.Context,
+		req *FIMCompletionRequest,
+		opts *FetchCompletionOptions,
+	) (*FetchCompletionResponse, error)
+}
+
+// LocalModel is a model available on a local inference server.
+type LocalModel struct {
+	Name              ModelName `json:"name"`
+	SizeBytes         int64     `json:"sizeBytes"`
+	Digest            string    `json:"digest,omitempty"`
+	ModifiedAt        time.Time `json:"modifiedAt"`
+	Family            string    `json:"family,omitempty"`
+	ParameterSize     string    `json:"parameterSize,omitempty"`
+	QuantizationLevel string    `json:"quantizationLevel,omitempty"`
+}
+
+// LocalModelPullProgress reports the progress of a model download.
+type LocalModelPullProgress struct {
+	Status string `json:"status"`
+	Digest string `json:"digest,omitempty"`
+	// Total and Completed are byte counts of the layer being downloaded; zero while not downloading.
+	Total     int64 `json:"total,omitempty"`
+	Completed int64 `json:"completed,omitempty"`
+}
+
+// LocalModelManager is optionally implemented by a CompletionProvider backed by a local inference server that can
+// list and download models.
+type LocalModelManager interface {
+	ListModels(ctx context.Context) ([]LocalModel, error)
+	// PullModel downloads model, calling progress (may be nil) for each status update, and returns once the model is
+	// available.
+	PullModel(ctx context.Context, model ModelName, progress func(LocalModelPullProgress)) error
+}
+
+// UploadFileRequest is a file to store with a provider, so that later requests reference it by
+// ContentItemFile.FileID instead of inlining it as base64 on every turn.
+type UploadFileRequest struct {
+	FileName string `json:"fileName"`
+	FileMIME string `json:"fileMIME,omitempty"`
+	// Reader supplies the file contents.
+	Reader io.Reader `json:"-"`
+}
+
+// ProviderFile is a file stored with a provider.
+type ProviderFile struct {
+	ID        string    `json:"id"`
+	FileName  string    `json:"fileName"`
+	FileMIME  string    `json:"fileMIME,omitempty"`
+	SizeBytes int64     `json:"sizeBytes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FileManager is optionally implemented by a CompletionProvider that stores files for reuse across requests, e.g.
+// the Anthropic Files API.
+type FileManager interface {
+	UploadFile(ctx context.Context, req *UploadFileRequest) (*ProviderFile, error)
+	DeleteFile(ctx context.Context, fileID string) error
+}
+
+// BackgroundResponder is implemented by providers that run requests with ModelParam.Background set, so that
+// long-running jobs do not hold a connection open.
+type BackgroundResponder interface {
+	// GetResponse retrieves a response by ID. Outputs are empty until the status is terminal.
+	GetResponse(ctx context.Context, responseID string) (*FetchCompletionResponse, error)
+	// CancelResponse cancels a queued or in-progress background response.
+	CancelResponse(ctx context.Context, responseID string) (*FetchCompletionResponse, error)
+}
+
+// BatchRequest is one request of a batch. CustomID identifies its result and must be unique within the batch.
+type BatchRequest struct {
+	CustomID string                  `json:"customID"`
+	Request  *FetchCompletionRequest `json:"request"`
+}
+
+type BatchProcessingStatus string
+
+const (
+	BatchStatusInProgress BatchProcessingStatus = "inProgress"
+	BatchStatusCanceling  BatchProcessingStatus = "canceling"
+	BatchStatusEnded      BatchProcessingStatus = "ended"
+)
+
+// BatchStatus is the state of a submitted batch. Results are available once Status is BatchStatusEnded.
+type BatchStatus struct {
+	ID     string                `json:"id"`
+	Status BatchProcessingStatus `json:"status"`
+
+	Processing int64 `json:"processing"`
+	Succeeded  int64 `json:"succeeded"`
+	Errored    int64 `json:"errored"`
+	Canceled   int64 `json:"canceled"`
+	Expired    int64 `json:"expired"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	EndedAt   time.Time `json:"endedAt,omitzero"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+}
+
+// BatchResult is the outcome of one request of an ended batch. Exactly one of Response and Error is set; requests
+// that were canceled or expired carry an Error with the matching message.
+type BatchResult struct {
+	CustomID string                   `json:"customID"`
+	Response *FetchCompletionResponse `json:"response,omitempty"`
+	Error    *Error                   `json:"error,omitempty"`
+}
+
+// BatchSubmitter is implemented by providers with an asynchronous batch API, e.g. Anthropic Message Batches.
+// Batches trade latency (results within 24 hours) for a lower price and separate rate limits.
+type BatchSubmitter interface {
+	// SubmitBatch validates the requests against the provider's batch limits and creates the batch.
+	SubmitBatch(ctx context.Context, reqs []BatchRequest) (*BatchStatus, error)
+	GetBatch(ctx context.Context, batchID string) (*BatchStatus, error)
+	// BatchResults streams the results of an ended batch to handler, in no particular order. An error returned by
+	// handler stops the stream and is returned.
+	BatchResults(ctx context.Context, batchID string, handler func(BatchResult) error) error
+	CancelBatch(ctx context.Context, batchID string) (*BatchStatus, error)
+}
+
 type CompletionProvider interface {
 	InitLLM(ctx context.Context) error
 	DeInitLLM(ctx context.Context) error
@@ -4,6 +4,8 @@ type CitationKind string
 
 const (
 	CitationKindURL CitationKind = "urlCitation"
+	// CitationKindDocument cites spans of documents or tool outputs supplied with the request.
+	CitationKindDocument CitationKind = "documentCitation"
 )
 
 type URLCitation struct {
@@ -16,10 +18,37 @@ type URLCitation struct {
 	EncryptedIndex string `json:"encryptedIndex,omitzero"`
 }
 
+// DocumentCitationSourceKind is what a document citation points at.
+type DocumentCitationSourceKind string
+
+const (
+	DocumentCitationSourceKindDocument DocumentCitationSourceKind = "document"
+	DocumentCitationSourceKindTool     DocumentCitationSourceKind = "tool"
+)
+
+type DocumentCitationSource struct {
+	Kind DocumentCitationSourceKind `json:"kind"`
+	// ID is the document ID, or the ID of the tool output, as referenced by the provider.
+	ID string `json:"id"`
+	// Fields are the cited document's fields (e.g. title, snippet) or the tool output's fields.
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+// DocumentCitation is a span of the response text (StartIndex..EndIndex, in characters) grounded in one or more
+// sources.
+type DocumentCitation struct {
+	CitedText  string `json:"citedText,omitzero"`
+	StartIndex int64  `json:"startIndex,omitzero"`
+	EndIndex   int64  `json:"endIndex,omitzero"`
+
+	Sources []DocumentCitationSource `json:"sources,omitempty"`
+}
+
 type Citation struct {
 	Kind CitationKind `json:"kind"`
 
-	URLCitation *URLCitation `json:"urlCitation,omitempty"`
+	URLCitation      *URLCitation      `json:"urlCitation,omitempty"`
+	DocumentCitation *DocumentCitation `json:"documentCitation,omitempty"`
 }
 
 type CitationConfig struct {
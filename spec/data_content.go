@@ -36,6 +36,25 @@ const (
 type ContentItemText struct {
 	Text      string     `json:"text"`
 	Citations []Citation `json:"citations,omitempty"`
+	// Logprobs holds the per-token log probabilities of Text when ModelParam.Logprobs was requested.
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"`
+}
+
+// TokenLogprob is the log probability of one output token.
+type TokenLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	// Bytes is the UTF-8 encoding of the token; it lets tokens that split a character be recombined.
+	Bytes []int `json:"bytes,omitempty"`
+	// TopLogprobs lists the most likely tokens at this position, most likely first.
+	TopLogprobs []TopLogprob `json:"topLogprobs,omitempty"`
+}
+
+// TopLogprob is one of the most likely alternatives at a token position.
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
 }
 
 type ContentItemRefusal struct {
@@ -64,6 +83,9 @@ type ContentItemFile struct {
 	FileName string `json:"fileName,omitzero"`
 	FileMIME string `json:"fileMIME,omitzero"`
 	FileURL  string `json:"fileURL,omitzero"`
+	// FileID references a file stored with the provider (see FileManager). It takes precedence over FileData and
+	// FileURL.
+	FileID string `json:"fileID,omitzero"`
 
 	FileData          string          `json:"fileData,omitzero"`
 	AdditionalContext string          `json:"additionalContext,omitzero"`
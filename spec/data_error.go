@@ -1,6 +1,35 @@
 package spec
 
+// Error codes set in Error.Code. Adapters map HTTP statuses and provider error codes onto them, so callers can
+// branch on the kind of failure without matching provider messages. ErrorCodeQuotaExceeded, an exhausted account
+// quota or billing limit, is never retryable, unlike ErrorCodeRateLimit.
+const (
+	ErrorCodeAuthentication   = "authentication"
+	ErrorCodePermissionDenied = "permission_denied"
+	ErrorCodeNotFound         = "not_found"
+	ErrorCodeInvalidRequest   = "invalid_request"
+	ErrorCodeContextLength    = "context_length_exceeded"
+	ErrorCodeRateLimit        = "rate_limit"
+	ErrorCodeQuotaExceeded    = "quota_exceeded"
+	ErrorCodeOverloaded       = "overloaded"
+	ErrorCodeServer           = "server_error"
+	ErrorCodeTimeout          = "timeout"
+	ErrorCodeCanceled         = "canceled"
+	ErrorCodeNetwork          = "network"
+	ErrorCodePanic            = "panic"
+	ErrorCodeUnknown          = "unknown"
+)
+
 type Error struct {
+	// Code is one of the ErrorCode constants.
 	Code    string `json:"code"`
 	Message string `json:"message"`
+
+	Provider ProviderName `json:"provider,omitzero"`
+	// HTTPStatus is the status of the failed provider response, or 0 if none was received.
+	HTTPStatus int `json:"httpStatus,omitzero"`
+	// Retryable reports whether the failure looks transient, so that retrying the same request may succeed.
+	Retryable bool `json:"retryable,omitzero"`
+	// RawProviderError is the error as reported by the provider, usually the JSON error body.
+	RawProviderError string `json:"rawProviderError,omitzero"`
 }
@@ -3,49 +3,97 @@ package spec
 type InputKind string
 
 const (
-	InputKindInputMessage        InputKind = "inputMessage"
-	InputKindOutputMessage       InputKind = "outputMessage"
-	InputKindReasoningMessage    InputKind = "reasoningMessage"
-	InputKindFunctionToolCall    InputKind = "functionToolCall"
-	InputKindFunctionToolOutput  InputKind = "functionToolOutput"
-	InputKindCustomToolCall      InputKind = "customToolCall"
-	InputKindCustomToolOutput    InputKind = "customToolOutput"
-	InputKindWebSearchToolCall   InputKind = "webSearchToolCall"
-	InputKindWebSearchToolOutput InputKind = "webSearchToolOutput"
+	InputKindInputMessage            InputKind = "inputMessage"
+	InputKindOutputMessage           InputKind = "outputMessage"
+	InputKindReasoningMessage        InputKind = "reasoningMessage"
+	InputKindFunctionToolCall        InputKind = "functionToolCall"
+	InputKindFunctionToolOutput      InputKind = "functionToolOutput"
+	InputKindCustomToolCall          InputKind = "customToolCall"
+	InputKindCustomToolOutput        InputKind = "customToolOutput"
+	InputKindWebSearchToolCall       InputKind = "webSearchToolCall"
+	InputKindWebSearchToolOutput     InputKind = "webSearchToolOutput"
+	InputKindCodeExecutionToolCall   InputKind = "codeExecutionToolCall"
+	InputKindCodeExecutionToolOutput InputKind = "codeExecutionToolOutput"
+	// InputKindImageGenerationToolOutput carries a generated image (a single image item in Contents).
+	InputKindImageGenerationToolOutput InputKind = "imageGenerationToolOutput"
+	// InputKindCodeInterpreterToolCall carries a code interpreter call together with its outputs.
+	InputKindCodeInterpreterToolCall InputKind = "codeInterpreterToolCall"
+	// InputKindFileSearchToolCall carries a file search call together with its results.
+	InputKindFileSearchToolCall InputKind = "fileSearchToolCall"
+	InputKindComputerToolCall   InputKind = "computerToolCall"
+	// InputKindComputerToolOutput answers a computer call with a screenshot (a single image item in Contents).
+	InputKindComputerToolOutput InputKind = "computerToolOutput"
+	// InputKindMCPToolCall carries an MCP call together with its result.
+	InputKindMCPToolCall InputKind = "mcpToolCall"
+	// InputKindMCPListTools carries the tools an MCP server offers.
+	InputKindMCPListTools InputKind = "mcpListTools"
 )
 
 type InputUnion struct {
 	Kind InputKind `json:"kind"`
 
-	InputMessage        *InputOutputContent `json:"inputMessage,omitempty"`
-	OutputMessage       *InputOutputContent `json:"outputMessage,omitempty"`
-	ReasoningMessage    *ReasoningContent   `json:"reasoningMessage,omitempty"`
-	FunctionToolCall    *ToolCall           `json:"functionToolCall,omitempty"`
-	FunctionToolOutput  *ToolOutput         `json:"functionToolOutput,omitempty"`
-	CustomToolCall      *ToolCall           `json:"customToolCall,omitempty"`
-	CustomToolOutput    *ToolOutput         `json:"customToolOutput,omitempty"`
-	WebSearchToolCall   *ToolCall           `json:"webSearchToolCall,omitempty"`
-	WebSearchToolOutput *ToolOutput         `json:"webSearchToolOutput,omitempty"`
+	InputMessage            *InputOutputContent `json:"inputMessage,omitempty"`
+	OutputMessage           *InputOutputContent `json:"outputMessage,omitempty"`
+	ReasoningMessage        *ReasoningContent   `json:"reasoningMessage,omitempty"`
+	FunctionToolCall        *ToolCall           `json:"functionToolCall,omitempty"`
+	FunctionToolOutput      *ToolOutput         `json:"functionToolOutput,omitempty"`
+	CustomToolCall          *ToolCall           `json:"customToolCall,omitempty"`
+	CustomToolOutput        *ToolOutput         `json:"customToolOutput,omitempty"`
+	WebSearchToolCall       *ToolCall           `json:"webSearchToolCall,omitempty"`
+	WebSearchToolOutput     *ToolOutput         `json:"webSearchToolOutput,omitempty"`
+	CodeExecutionToolCall   *ToolCall           `json:"codeExecutionToolCall,omitempty"`
+	CodeExecutionToolOutput *ToolOutput         `json:"codeExecutionToolOutput,omitempty"`
+
+	ImageGenerationToolOutput *ToolOutput `json:"imageGenerationToolOutput,omitempty"`
+	CodeInterpreterToolCall   *ToolCall   `json:"codeInterpreterToolCall,omitempty"`
+	FileSearchToolCall        *ToolCall   `json:"fileSearchToolCall,omitempty"`
+	ComputerToolCall          *ToolCall   `json:"computerToolCall,omitempty"`
+	ComputerToolOutput        *ToolOutput `json:"computerToolOutput,omitempty"`
+	MCPToolCall               *ToolCall   `json:"mcpToolCall,omitempty"`
+	MCPListTools              *ToolCall   `json:"mcpListTools,omitempty"`
 }
 
 type OutputKind string
 
 const (
-	OutputKindOutputMessage       OutputKind = "outputMessage"
-	OutputKindReasoningMessage    OutputKind = "reasoningMessage"
-	OutputKindFunctionToolCall    OutputKind = "functionToolCall"
-	OutputKindCustomToolCall      OutputKind = "customToolCall"
-	OutputKindWebSearchToolCall   OutputKind = "webSearchToolCall"
-	OutputKindWebSearchToolOutput OutputKind = "webSearchToolOutput"
+	OutputKindOutputMessage           OutputKind = "outputMessage"
+	OutputKindReasoningMessage        OutputKind = "reasoningMessage"
+	OutputKindFunctionToolCall        OutputKind = "functionToolCall"
+	OutputKindCustomToolCall          OutputKind = "customToolCall"
+	OutputKindWebSearchToolCall       OutputKind = "webSearchToolCall"
+	OutputKindWebSearchToolOutput     OutputKind = "webSearchToolOutput"
+	OutputKindCodeExecutionToolCall   OutputKind = "codeExecutionToolCall"
+	OutputKindCodeExecutionToolOutput OutputKind = "codeExecutionToolOutput"
+	// OutputKindImageGenerationToolOutput carries a generated image (a single image item in Contents).
+	OutputKindImageGenerationToolOutput OutputKind = "imageGenerationToolOutput"
+	// OutputKindCodeInterpreterToolCall carries a code interpreter call together with its outputs.
+	OutputKindCodeInterpreterToolCall OutputKind = "codeInterpreterToolCall"
+	// OutputKindFileSearchToolCall carries a file search call together with its results.
+	OutputKindFileSearchToolCall OutputKind = "fileSearchToolCall"
+	// OutputKindComputerToolCall is a computer action for the caller to perform.
+	OutputKindComputerToolCall OutputKind = "computerToolCall"
+	// OutputKindMCPToolCall carries an MCP call together with its result.
+	OutputKindMCPToolCall OutputKind = "mcpToolCall"
+	// OutputKindMCPListTools carries the tools an MCP server offers.
+	OutputKindMCPListTools OutputKind = "mcpListTools"
 )
 
 type OutputUnion struct {
 	Kind OutputKind `json:"kind"`
 
-	OutputMessage       *InputOutputContent `json:"outputMessage,omitempty"`
-	ReasoningMessage    *ReasoningContent   `json:"reasoningMessage,omitempty"`
-	FunctionToolCall    *ToolCall           `json:"functionToolCall,omitempty"`
-	CustomToolCall      *ToolCall           `json:"customToolCall,omitempty"`
-	WebSearchToolCall   *ToolCall           `json:"webSearchToolCall,omitempty"`
-	WebSearchToolOutput *ToolOutput         `json:"webSearchToolOutput,omitempty"`
+	OutputMessage           *InputOutputContent `json:"outputMessage,omitempty"`
+	ReasoningMessage        *ReasoningContent   `json:"reasoningMessage,omitempty"`
+	FunctionToolCall        *ToolCall           `json:"functionToolCall,omitempty"`
+	CustomToolCall          *ToolCall           `json:"customToolCall,omitempty"`
+	WebSearchToolCall       *ToolCall           `json:"webSearchToolCall,omitempty"`
+	WebSearchToolOutput     *ToolOutput         `json:"webSearchToolOutput,omitempty"`
+	CodeExecutionToolCall   *ToolCall           `json:"codeExecutionToolCall,omitempty"`
+	CodeExecutionToolOutput *ToolOutput         `json:"codeExecutionToolOutput,omitempty"`
+
+	ImageGenerationToolOutput *ToolOutput `json:"imageGenerationToolOutput,omitempty"`
+	CodeInterpreterToolCall   *ToolCall   `json:"codeInterpreterToolCall,omitempty"`
+	FileSearchToolCall        *ToolCall   `json:"fileSearchToolCall,omitempty"`
+	ComputerToolCall          *ToolCall   `json:"computerToolCall,omitempty"`
+	MCPToolCall               *ToolCall   `json:"mcpToolCall,omitempty"`
+	MCPListTools              *ToolCall   `json:"mcpListTools,omitempty"`
 }
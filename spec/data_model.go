@@ -102,6 +102,38 @@ type ModelParam struct {
 	//   - Anthropic Messages: supports jsonSchema only via output_config.format. verbosity is not supported.
 	OutputParam *OutputParam `json:"outputParam,omitempty"`
 
+	// TopP, TopK, FrequencyPenalty and PresencePenalty tune sampling; nil leaves the provider default.
+	// Cross-provider notes:
+	//   - OpenAI Chat Completions and legacy completions: top_p, frequency_penalty and presence_penalty. TopK is not
+	//     supported.
+	//   - OpenAI Responses: top_p only.
+	//   - Anthropic Messages: top_p and top_k; like temperature, they are not sent when thinking is enabled. Only one
+	//     of temperature and top_p is sent: when both are set, temperature wins and top_p is dropped with a warning.
+	//     Penalties are not supported.
+	//   - Ollama: options top_p, top_k, frequency_penalty and presence_penalty.
+	//   - Cohere: p, k, frequency_penalty and presence_penalty.
+	TopP             *float64 `json:"topP,omitempty"`
+	TopK             *int     `json:"topK,omitempty"`
+	FrequencyPenalty *float64 `json:"frequencyPenalty,omitempty"`
+	PresencePenalty  *float64 `json:"presencePenalty,omitempty"`
+
+	// NumChoices requests that many independent candidate completions. The first one is returned in
+	// FetchCompletionResponse.Outputs and all of them in FetchCompletionResponse.Choices. Zero or one means a single
+	// completion. Only the first candidate is streamed.
+	// Cross-provider notes:
+	//   - OpenAI Chat Completions: maps to n.
+	//   - Other providers: not supported; a single completion is returned.
+	NumChoices int `json:"numChoices,omitempty"`
+
+	// Logprobs requests the log probability of each output token, returned in ContentItemText.Logprobs and streamed
+	// as StreamContentKindLogprobs events. TopLogprobs additionally requests that many most likely alternatives per
+	// token (0-20) and implies Logprobs.
+	// Cross-provider notes:
+	//   - OpenAI Chat Completions: maps to logprobs and top_logprobs.
+	//   - Other providers: not supported; the fields are ignored.
+	Logprobs    bool `json:"logprobs,omitempty"`
+	TopLogprobs int  `json:"topLogprobs,omitempty"`
+
 	// StopSequences requests provider-side stop sequences when supported.
 	// Cross-provider notes:
 	//   - OpenAI Chat Completions: maps to stop. Up to 4 sequences supported. Not supported by reasoning models
@@ -109,13 +141,83 @@ type ModelParam struct {
 	//   - Anthropic Messages: maps to stop_sequences.
 	StopSequences []string `json:"stopSequences,omitempty"`
 
+	// Locale is a BCP 47 language tag (e.g. "fr-CA") the response should be written in. A localization hint is
+	// appended to the system prompt and the detected response language is recorded in ResponseMetadata.
+	Locale string `json:"locale,omitempty"`
+
+	// Constraint requests grammar-constrained decoding from a local OpenAI-compatible server.
+	// Cross-provider notes:
+	//   - OpenAI Chat Completions (and OpenAI-compatible SSE): maps to the backend's request body extensions.
+	//   - OpenAI Responses, Anthropic Messages: Not supported.
+	Constraint *DecodingConstraint `json:"constraint,omitempty"`
+
+	// BeamSearch replaces sampling with beam search on a vLLM server.
+	// Cross-provider notes:
+	//   - OpenAI Chat Completions (vLLM): maps to use_beam_search, n and length_penalty.
+	//   - Other APIs: Not supported.
+	BeamSearch *BeamSearchParam `json:"beamSearch,omitempty"`
+
+	// Echo returns the prompt followed by the completion as the output text.
+	// Cross-provider notes:
+	//   - OpenAI legacy completions: maps to echo.
+	//   - Other APIs: Not supported.
+	Echo bool `json:"echo,omitempty"`
+
+	// Background runs the request as a stored background job: the call returns once the job is queued, with
+	// ResponseMetadata.ResponseID and Status set, and the result is fetched later by response ID.
+	// Cross-provider notes:
+	//   - OpenAI Responses: maps to background (and store).
+	//   - Other APIs: Not supported.
+	Background bool `json:"background,omitempty"`
+
+	// AdditionalParametersRawJSON is a JSON object overlaid onto the provider request body, for provider knobs not
+	// modeled here. Objects are merged into the values the adapter set, recursively; other values replace them. The
+	// fields an adapter manages itself (model, conversation, system prompt, tools, stream) are rejected.
 	AdditionalParametersRawJSON *string `json:"additionalParametersRawJSON"`
 }
 
+// BeamSearchParam configures beam search decoding. The best beam is returned as the output.
+type BeamSearchParam struct {
+	// Width is the number of beams. Required, at least 1.
+	Width int `json:"width"`
+	// LengthPenalty is the exponent applied to the sequence length when ranking beams. Nil means 1.
+	LengthPenalty *float64 `json:"lengthPenalty,omitempty"`
+}
+
+// DecodingConstraintBackend is the server whose constrained decoding extensions are used.
+type DecodingConstraintBackend string
+
+const (
+	// DecodingConstraintBackendLlamaCpp is the llama.cpp server: grammar and json_schema.
+	DecodingConstraintBackendLlamaCpp DecodingConstraintBackend = "llamaCpp"
+	// DecodingConstraintBackendVLLM is vLLM: guided_grammar, guided_json, guided_regex and guided_choice.
+	DecodingConstraintBackendVLLM DecodingConstraintBackend = "vllm"
+	// DecodingConstraintBackendFireworks is Fireworks AI: grammar and json_object response formats. It is the default
+	// backend of ProviderSDKTypeFireworks providers.
+	DecodingConstraintBackendFireworks DecodingConstraintBackend = "fireworks"
+)
+
+// DecodingConstraint restricts generation to a grammar, a JSON schema, a regular expression or a fixed set of
+// choices. Exactly one of them must be set.
+type DecodingConstraint struct {
+	Backend DecodingConstraintBackend `json:"backend"`
+
+	// Grammar is a GBNF grammar for llama.cpp and Fireworks, or an EBNF/Lark grammar for vLLM.
+	Grammar    string         `json:"grammar,omitzero"`
+	JSONSchema map[string]any `json:"jsonSchema,omitempty"`
+	// Regex and Choices are supported by vLLM only.
+	Regex   string   `json:"regex,omitzero"`
+	Choices []string `json:"choices,omitempty"`
+}
+
 type Usage struct {
 	InputTokensTotal    int64 `json:"inputTokensTotal"`
 	InputTokensCached   int64 `json:"inputTokensCached"`
 	InputTokensUncached int64 `json:"inputTokensUncached"`
 	OutputTokens        int64 `json:"outputTokens"`
 	ReasoningTokens     int64 `json:"reasoningTokens"`
+
+	// Cost is the request cost reported by the provider in its billing unit, e.g. OpenRouter credits (USD).
+	// Nil when the provider does not report costs.
+	Cost *float64 `json:"cost,omitempty"`
 }
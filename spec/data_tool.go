@@ -41,6 +41,26 @@ const (
 	ToolTypeFunction  ToolType = "function"
 	ToolTypeCustom    ToolType = "custom"
 	ToolTypeWebSearch ToolType = "webSearch"
+	// ToolTypeCodeExecution is a provider-hosted code sandbox. Supported by Anthropic (code_execution, beta).
+	// Calls carry the code as JSON Arguments ({"code": "..."}).
+	ToolTypeCodeExecution ToolType = "codeExecution"
+	// ToolTypeImageGeneration is a provider-hosted image generator. Supported by OpenAI Responses (image_generation).
+	// Generated images are returned as ImageGenerationToolOutput items, with no separate call item.
+	ToolTypeImageGeneration ToolType = "imageGeneration"
+	// ToolTypeCodeInterpreter is a provider-hosted Python sandbox. Supported by OpenAI Responses (code_interpreter).
+	// The code and its logs/images are returned together as a CodeInterpreterToolCall item.
+	ToolTypeCodeInterpreter ToolType = "codeInterpreter"
+	// ToolTypeFileSearch searches provider-hosted vector stores. Supported by OpenAI Responses (file_search).
+	// The queries and the retrieved chunks are returned together as a FileSearchToolCall item.
+	ToolTypeFileSearch ToolType = "fileSearch"
+	// ToolTypeComputerUse lets the model operate a computer. Supported by OpenAI Responses (computer_use_preview).
+	// The model emits ComputerToolCall actions that the caller performs, answering each with a ComputerToolOutput
+	// holding a screenshot.
+	ToolTypeComputerUse ToolType = "computerUse"
+	// ToolTypeMCP exposes the tools of a remote MCP server, called by the provider. Supported by OpenAI Responses
+	// (mcp). Each call and its result are returned together as an MCPToolCall item, the tools the server offers as an
+	// MCPListTools item.
+	ToolTypeMCP ToolType = "mcp"
 )
 
 type WebSearchToolChoiceItemUserLocation struct {
@@ -51,6 +71,8 @@ type WebSearchToolChoiceItemUserLocation struct {
 }
 
 type WebSearchToolChoiceItem struct {
+	// MaxUses bounds the searches of one request. Anthropic enforces it natively; OpenAI APIs have no equivalent,
+	// agent.Budget.MaxWebSearches bounds searches across the model calls of a run for every provider.
 	MaxUses           int64                                `json:"max_uses,omitzero"`
 	SearchContextSize string                               `json:"searchContextSize,omitzero"`
 	AllowedDomains    []string                             `json:"allowed_domains,omitzero"`
@@ -58,6 +80,80 @@ type WebSearchToolChoiceItem struct {
 	UserLocation      *WebSearchToolChoiceItemUserLocation `json:"user_location,omitempty"`
 }
 
+type ImageGenerationToolChoiceItem struct {
+	// Size is e.g. "1024x1024", "1536x1024" or "auto".
+	Size string `json:"size,omitzero"`
+	// Quality is "low", "medium", "high" or "auto".
+	Quality string `json:"quality,omitzero"`
+	// Background is "transparent", "opaque" or "auto".
+	Background string `json:"background,omitzero"`
+	// OutputFormat is "png", "webp" or "jpeg".
+	OutputFormat string `json:"outputFormat,omitzero"`
+	// PartialImages, 0 to 3, is the number of partial images streamed as StreamContentKindImagePartial events before
+	// the final image.
+	PartialImages int64 `json:"partialImages,omitzero"`
+}
+
+type CodeInterpreterToolChoiceItem struct {
+	// ContainerID runs the code in an existing container. When empty, a container is created automatically with
+	// FileIDs and MemoryLimit.
+	ContainerID string `json:"containerID,omitzero"`
+	// FileIDs are uploaded files made available to the code in an automatic container.
+	FileIDs []string `json:"fileIDs,omitzero"`
+	// MemoryLimit is "1g", "4g", "16g" or "64g".
+	MemoryLimit string `json:"memoryLimit,omitzero"`
+}
+
+type FileSearchToolChoiceItem struct {
+	VectorStoreIDs []string `json:"vectorStoreIDs"`
+	// MaxNumResults is 1 to 50. Zero means the provider default.
+	MaxNumResults int64 `json:"maxNumResults,omitzero"`
+}
+
+type ComputerUseToolChoiceItem struct {
+	DisplayWidth  int64 `json:"displayWidth"`
+	DisplayHeight int64 `json:"displayHeight"`
+	// Environment is "browser", "mac", "windows", "linux" or "ubuntu".
+	Environment string `json:"environment"`
+}
+
+type MCPToolChoiceItem struct {
+	// ServerLabel identifies the server in calls. Empty means the tool choice Name.
+	ServerLabel       string `json:"serverLabel,omitzero"`
+	ServerURL         string `json:"serverURL"`
+	ServerDescription string `json:"serverDescription,omitzero"`
+	// AllowedTools limits the server tools the model may call. Empty allows all.
+	AllowedTools []string `json:"allowedTools,omitzero"`
+	// RequireApproval is "always" or "never". Empty means the provider default. Approval requests are not surfaced,
+	// so servers that need approval should be configured with "never" once trusted.
+	RequireApproval string `json:"requireApproval,omitzero"`
+	// Headers are sent to the server, e.g. for authentication.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type CustomToolFormatKind string
+
+const (
+	CustomToolFormatKindText    CustomToolFormatKind = "text"
+	CustomToolFormatKindGrammar CustomToolFormatKind = "grammar"
+)
+
+type CustomToolGrammarSyntax string
+
+const (
+	CustomToolGrammarSyntaxLark  CustomToolGrammarSyntax = "lark"
+	CustomToolGrammarSyntaxRegex CustomToolGrammarSyntax = "regex"
+)
+
+// CustomToolFormat constrains the free-form input of a custom tool. Supported by OpenAI (custom tools).
+type CustomToolFormat struct {
+	// Kind is text (unconstrained) or grammar.
+	Kind CustomToolFormatKind `json:"kind"`
+	// Syntax and Definition describe the grammar of a grammar format.
+	Syntax     CustomToolGrammarSyntax `json:"syntax,omitempty"`
+	Definition string                  `json:"definition,omitempty"`
+}
+
 type ToolChoice struct {
 	Type ToolType `json:"type"`
 
@@ -67,8 +163,23 @@ type ToolChoice struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitzero"`
 
-	Arguments          map[string]any           `json:"arguments,omitempty"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Strict asks the provider to guarantee that function tool arguments are valid against Arguments, where
+	// supported (OpenAI). The schema is adapted to strict mode: objects disallow additional properties and list
+	// every property as required, with optional ones made nullable.
+	Strict bool `json:"strict,omitempty"`
+	// Format, for a custom tool, sends it as a provider custom tool whose input is free-form text, optionally
+	// constrained by a grammar, instead of a function tool with Arguments as its JSON schema. The call input is
+	// returned in ToolCall.Arguments as is. Supported by OpenAI Chat Completions and Responses.
+	Format *CustomToolFormat `json:"format,omitempty"`
+
 	WebSearchArguments *WebSearchToolChoiceItem `json:"webSearchArguments,omitempty"`
+
+	ImageGenerationArguments *ImageGenerationToolChoiceItem `json:"imageGenerationArguments,omitempty"`
+	CodeInterpreterArguments *CodeInterpreterToolChoiceItem `json:"codeInterpreterArguments,omitempty"`
+	FileSearchArguments      *FileSearchToolChoiceItem      `json:"fileSearchArguments,omitempty"`
+	ComputerUseArguments     *ComputerUseToolChoiceItem     `json:"computerUseArguments,omitempty"`
+	MCPArguments             *MCPToolChoiceItem             `json:"mcpArguments,omitempty"`
 }
 
 type WebSearchToolCallKind string
@@ -106,6 +217,95 @@ type WebSearchToolCallItemUnion struct {
 	FindItem     *WebSearchToolCallFind     `json:"findItem,omitempty"`
 }
 
+type CodeInterpreterOutputKind string
+
+const (
+	CodeInterpreterOutputKindLogs  CodeInterpreterOutputKind = "logs"
+	CodeInterpreterOutputKindImage CodeInterpreterOutputKind = "image"
+)
+
+type CodeInterpreterOutput struct {
+	Kind     CodeInterpreterOutputKind `json:"kind"`
+	Logs     string                    `json:"logs,omitzero"`
+	ImageURL string                    `json:"imageURL,omitzero"`
+}
+
+// CodeInterpreterToolCallItem is the code run by a code interpreter tool and what it produced.
+type CodeInterpreterToolCallItem struct {
+	Code        string                  `json:"code"`
+	ContainerID string                  `json:"containerID"`
+	Outputs     []CodeInterpreterOutput `json:"outputs,omitempty"`
+}
+
+type FileSearchResult struct {
+	FileID   string  `json:"fileID"`
+	FileName string  `json:"fileName,omitzero"`
+	Score    float64 `json:"score,omitzero"`
+	Text     string  `json:"text,omitzero"`
+}
+
+// FileSearchToolCallItem is the queries of a file search tool call and the chunks it retrieved.
+type FileSearchToolCallItem struct {
+	Queries []string           `json:"queries"`
+	Results []FileSearchResult `json:"results,omitempty"`
+}
+
+type MCPToolInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitzero"`
+	InputSchema map[string]any `json:"inputSchema,omitempty"`
+}
+
+// MCPToolCallItem is the MCP server side of a call: the result of an MCPToolCall, or the tools of an MCPListTools
+// item. The called tool is the ToolCall Name and its JSON input the Arguments.
+type MCPToolCallItem struct {
+	ServerLabel string        `json:"serverLabel"`
+	Output      string        `json:"output,omitzero"`
+	Error       string        `json:"error,omitzero"`
+	Tools       []MCPToolInfo `json:"tools,omitempty"`
+}
+
+type ComputerActionKind string
+
+const (
+	ComputerActionKindClick       ComputerActionKind = "click"
+	ComputerActionKindDoubleClick ComputerActionKind = "doubleClick"
+	ComputerActionKindDrag        ComputerActionKind = "drag"
+	ComputerActionKindKeypress    ComputerActionKind = "keypress"
+	ComputerActionKindMove        ComputerActionKind = "move"
+	ComputerActionKindScreenshot  ComputerActionKind = "screenshot"
+	ComputerActionKindScroll      ComputerActionKind = "scroll"
+	ComputerActionKindType        ComputerActionKind = "type"
+	ComputerActionKindWait        ComputerActionKind = "wait"
+)
+
+type ComputerActionPoint struct {
+	X int64 `json:"x"`
+	Y int64 `json:"y"`
+}
+
+// ComputerAction is an action the model asks the caller to perform. Only the fields relevant to Kind are set.
+type ComputerAction struct {
+	Kind ComputerActionKind `json:"kind"`
+	// Button is "left", "right", "wheel", "back" or "forward" for clicks.
+	Button  string                `json:"button,omitzero"`
+	X       int64                 `json:"x,omitzero"`
+	Y       int64                 `json:"y,omitzero"`
+	Path    []ComputerActionPoint `json:"path,omitempty"`
+	Keys    []string              `json:"keys,omitempty"`
+	ScrollX int64                 `json:"scrollX,omitzero"`
+	ScrollY int64                 `json:"scrollY,omitzero"`
+	Text    string                `json:"text,omitzero"`
+}
+
+// ComputerSafetyCheck is a provider safety check on a computer action. Pending checks must be acknowledged in the
+// ComputerToolOutput answering the call for the run to continue.
+type ComputerSafetyCheck struct {
+	ID      string `json:"id"`
+	Code    string `json:"code,omitzero"`
+	Message string `json:"message,omitzero"`
+}
+
 type ToolCall struct {
 	Type ToolType `json:"type"`
 
@@ -119,6 +319,12 @@ type ToolCall struct {
 	Name                   string                       `json:"name"`
 	Arguments              string                       `json:"arguments,omitempty"`
 	WebSearchToolCallItems []WebSearchToolCallItemUnion `json:"webSearchToolCallItems,omitempty"`
+
+	CodeInterpreterToolCallItem *CodeInterpreterToolCallItem `json:"codeInterpreterToolCallItem,omitempty"`
+	FileSearchToolCallItem      *FileSearchToolCallItem      `json:"fileSearchToolCallItem,omitempty"`
+	ComputerAction              *ComputerAction              `json:"computerAction,omitempty"`
+	PendingSafetyChecks         []ComputerSafetyCheck        `json:"pendingSafetyChecks,omitempty"`
+	MCPToolCallItem             *MCPToolCallItem             `json:"mcpToolCallItem,omitempty"`
 }
 
 type WebSearchToolOutputKind string
@@ -147,6 +353,37 @@ type WebSearchToolOutputItemUnion struct {
 	ErrorItem  *WebSearchToolOutputError  `json:"errorItem,omitempty"`
 }
 
+type CodeExecutionToolOutputKind string
+
+const (
+	CodeExecutionToolOutputKindResult CodeExecutionToolOutputKind = "result"
+	CodeExecutionToolOutputKindFile   CodeExecutionToolOutputKind = "file"
+	CodeExecutionToolOutputKindError  CodeExecutionToolOutputKind = "error"
+)
+
+type CodeExecutionToolOutputResult struct {
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr,omitzero"`
+	ReturnCode int64  `json:"returnCode"`
+}
+
+// CodeExecutionToolOutputFile is a file created by the executed code, retrievable through the provider's files API.
+type CodeExecutionToolOutputFile struct {
+	FileID string `json:"fileID"`
+}
+
+type CodeExecutionToolOutputError struct {
+	Code string `json:"code"`
+}
+
+type CodeExecutionToolOutputItemUnion struct {
+	Kind CodeExecutionToolOutputKind `json:"kind"`
+
+	ResultItem *CodeExecutionToolOutputResult `json:"resultItem,omitempty"`
+	FileItem   *CodeExecutionToolOutputFile   `json:"fileItem,omitempty"`
+	ErrorItem  *CodeExecutionToolOutputError  `json:"errorItem,omitempty"`
+}
+
 type ToolOutputItemUnion struct {
 	Kind ContentItemKind `json:"kind"`
 
@@ -171,4 +408,44 @@ type ToolOutput struct {
 
 	Contents                 []ToolOutputItemUnion          `json:"contents,omitempty"`
 	WebSearchToolOutputItems []WebSearchToolOutputItemUnion `json:"webSearchToolOutputItems,omitempty"`
+
+	CodeExecutionToolOutputItems []CodeExecutionToolOutputItemUnion `json:"codeExecutionToolOutputItems,omitempty"`
+
+	// AcknowledgedSafetyChecks answer the PendingSafetyChecks of a computer use call.
+	AcknowledgedSafetyChecks []ComputerSafetyCheck `json:"acknowledgedSafetyChecks,omitempty"`
+
+	// Error describes a failed tool call when IsError is set. Adapters render it as a leading text item: Anthropic
+	// additionally sets is_error, OpenAI APIs have no error flag and rely on the text alone.
+	Error *ToolError `json:"error,omitempty"`
+
+	// Execution is client-side execution metadata recorded by tool runners. It is never sent to providers.
+	Execution *ToolExecution `json:"execution,omitempty"`
+
+	// Cache is client-side cache metadata recorded for cached web search outputs. It is never sent to providers.
+	Cache *ToolOutputCache `json:"cache,omitempty"`
+}
+
+type ToolOutputCache struct {
+	// Key identifies the cached search: the normalized queries and the domain filters in effect.
+	Key             string `json:"key"`
+	StoredAtMillis  int64  `json:"storedAtMillis"`
+	ExpiresAtMillis int64  `json:"expiresAtMillis"`
+	// Hit reports that the output was replayed from the cache instead of being produced by the provider.
+	Hit bool `json:"hit,omitzero"`
+}
+
+type ToolError struct {
+	// Code is a machine-readable error code, e.g. "timeout" or "invalid_arguments".
+	Code    string `json:"code,omitzero"`
+	Message string `json:"message"`
+}
+
+type ToolExecution struct {
+	DurationMillis int64 `json:"durationMillis"`
+	Attempts       int   `json:"attempts"`
+	TimedOut       bool  `json:"timedOut,omitzero"`
+	Panicked       bool  `json:"panicked,omitzero"`
+	// Truncated reports that the output was cut to the tool's max output size. OutputBytes is the size before the cut.
+	Truncated   bool `json:"truncated,omitzero"`
+	OutputBytes int  `json:"outputBytes"`
 }
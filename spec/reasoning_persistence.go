@@ -0,0 +1,92 @@
+package spec
+
+import (
+	"errors"
+	"slices"
+)
+
+// ReasoningPersistence controls which reasoning fields survive when conversation items are persisted (session
+// history, checkpoints, exports). It is applied to copies; the items passed in are never modified.
+type ReasoningPersistence string
+
+const (
+	// ReasoningPersistenceKeepAll persists reasoning items unchanged. It is the default.
+	ReasoningPersistenceKeepAll ReasoningPersistence = ""
+	// ReasoningPersistenceEncryptedOnly keeps only the opaque provider payloads (EncryptedContent and
+	// RedactedThinking) needed to continue a conversation; Thinking, Summary and Signature are dropped.
+	ReasoningPersistenceEncryptedOnly ReasoningPersistence = "encryptedOnly"
+	// ReasoningPersistenceSummaries keeps only Summary.
+	ReasoningPersistenceSummaries ReasoningPersistence = "summaries"
+	// ReasoningPersistenceDropAll removes reasoning items entirely.
+	ReasoningPersistenceDropAll ReasoningPersistence = "dropAll"
+)
+
+func (p ReasoningPersistence) Validate() error {
+	switch p {
+	case ReasoningPersistenceKeepAll, ReasoningPersistenceEncryptedOnly,
+		ReasoningPersistenceSummaries, ReasoningPersistenceDropAll:
+		return nil
+	}
+	return errors.New("unknown reasoning persistence policy: " + string(p))
+}
+
+// ApplyInputs returns items with the policy applied. Reasoning items left without content are removed.
+func (p ReasoningPersistence) ApplyInputs(items []InputUnion) []InputUnion {
+	if p == ReasoningPersistenceKeepAll || items == nil {
+		return items
+	}
+	out := make([]InputUnion, 0, len(items))
+	for _, in := range items {
+		if in.Kind == InputKindReasoningMessage {
+			r := p.apply(in.ReasoningMessage)
+			if r == nil {
+				continue
+			}
+			in.ReasoningMessage = r
+		}
+		out = append(out, in)
+	}
+	return out
+}
+
+// ApplyOutputs is ApplyInputs for outputs.
+func (p ReasoningPersistence) ApplyOutputs(items []OutputUnion) []OutputUnion {
+	if p == ReasoningPersistenceKeepAll || items == nil {
+		return items
+	}
+	out := make([]OutputUnion, 0, len(items))
+	for _, o := range items {
+		if o.Kind == OutputKindReasoningMessage {
+			r := p.apply(o.ReasoningMessage)
+			if r == nil {
+				continue
+			}
+			o.ReasoningMessage = r
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+func (p ReasoningPersistence) apply(r *ReasoningContent) *ReasoningContent {
+	if r == nil || p == ReasoningPersistenceDropAll {
+		return nil
+	}
+	c := *r
+	switch p {
+	case ReasoningPersistenceEncryptedOnly:
+		c.Thinking, c.Summary, c.Signature = nil, nil, ""
+		c.EncryptedContent = slices.Clone(r.EncryptedContent)
+		c.RedactedThinking = slices.Clone(r.RedactedThinking)
+		if len(c.EncryptedContent) == 0 && len(c.RedactedThinking) == 0 {
+			return nil
+		}
+	case ReasoningPersistenceSummaries:
+		c.Thinking, c.RedactedThinking, c.EncryptedContent, c.Signature = nil, nil, nil, ""
+		c.Summary = slices.Clone(r.Summary)
+		if len(c.Summary) == 0 {
+			return nil
+		}
+	}
+	return &c
+}
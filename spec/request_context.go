@@ -0,0 +1,46 @@
+package spec
+
+import "context"
+
+// Headers set by all adapters from the RequestContext of a call.
+const (
+	HeaderTenantID   = "X-Tenant-ID"
+	HeaderTraceID    = "X-Trace-ID"
+	HeaderFeatureTag = "X-Feature-Tag"
+)
+
+// RequestContext carries well-known caller attributes that adapters propagate to providers without per call site
+// plumbing. Every non-empty field is sent as a header (HeaderTenantID, HeaderTraceID, HeaderFeatureTag) on all
+// provider requests, including FetchRaw. In addition:
+//   - Anthropic: TenantID is sent as metadata.user_id.
+//   - OpenAI Responses: fields are added to request metadata as tenant_id, trace_id and feature.
+//   - OpenAI Chat Completions: headers only, since compatible backends often reject unknown metadata.
+type RequestContext struct {
+	TenantID   string `json:"tenantID,omitempty"`
+	TraceID    string `json:"traceID,omitempty"`
+	FeatureTag string `json:"featureTag,omitempty"`
+}
+
+type requestContextKey struct{}
+
+// WithRequestContext returns ctx carrying rc. Non-empty fields of rc override those already in ctx.
+func WithRequestContext(ctx context.Context, rc RequestContext) context.Context {
+	if prev, ok := RequestContextFromContext(ctx); ok {
+		if rc.TenantID == "" {
+			rc.TenantID = prev.TenantID
+		}
+		if rc.TraceID == "" {
+			rc.TraceID = prev.TraceID
+		}
+		if rc.FeatureTag == "" {
+			rc.FeatureTag = prev.FeatureTag
+		}
+	}
+	return context.WithValue(ctx, requestContextKey{}, rc)
+}
+
+// RequestContextFromContext returns the RequestContext set by WithRequestContext.
+func RequestContextFromContext(ctx context.Context) (RequestContext, bool) {
+	rc, ok := ctx.Value(requestContextKey{}).(RequestContext)
+	return rc, ok
+}
@@ -0,0 +1,178 @@
+package inference
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// errStopPatternMatched aborts the provider stream once a stop pattern matched.
+var errStopPatternMatched = errors.New("stop pattern matched")
+
+type compiledStopPattern struct {
+	literal string
+	re      *regexp.Regexp
+}
+
+// stopMatcher applies client-side stop patterns to the streamed text and to the final response.
+type stopMatcher struct {
+	patterns []compiledStopPattern
+
+	mu      sync.Mutex
+	text    strings.Builder
+	matched bool
+	cut     int
+	match   string
+}
+
+func newStopMatcher(patterns []spec.StopPattern) (*stopMatcher, error) {
+	m := &stopMatcher{}
+	for _, p := range patterns {
+		switch {
+		case p.Literal != "" && p.Regex == "":
+			m.patterns = append(m.patterns, compiledStopPattern{literal: p.Literal})
+		case p.Regex != "" && p.Literal == "":
+			re, err := regexp.Compile(p.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid stop pattern %q: %w", p.Regex, err)
+			}
+			m.patterns = append(m.patterns, compiledStopPattern{re: re})
+		default:
+			return nil, errors.New("invalid stop pattern: exactly one of literal and regex must be set")
+		}
+	}
+	return m, nil
+}
+
+// find returns the earliest match in s, or -1. Literal matches are only searched where they could end after from,
+// the length of the text that was already checked.
+func (m *stopMatcher) find(s string, from int) (start int, match string) {
+	start = -1
+	for _, p := range m.patterns {
+		var i int
+		var mt string
+		if p.re != nil {
+			loc := p.re.FindStringIndex(s)
+			if loc == nil {
+				continue
+			}
+			i, mt = loc[0], s[loc[0]:loc[1]]
+		} else {
+			offset := max(0, from-len(p.literal)+1)
+			j := strings.Index(s[offset:], p.literal)
+			if j < 0 {
+				continue
+			}
+			i, mt = offset+j, p.literal
+		}
+		if start < 0 || i < start {
+			start, match = i, mt
+		}
+	}
+	return start, match
+}
+
+// handler forwards text up to the first match and then aborts the stream.
+func (m *stopMatcher) handler(next spec.StreamHandler) spec.StreamHandler {
+	return func(event spec.StreamEvent) error {
+		m.mu.Lock()
+		if m.matched {
+			m.mu.Unlock()
+			return errStopPatternMatched
+		}
+		if event.Kind != spec.StreamContentKindText || event.Text == nil {
+			m.mu.Unlock()
+			return next(event)
+		}
+		prev := m.text.Len()
+		m.text.WriteString(event.Text.Text)
+		full := m.text.String()
+		i, match := m.find(full, prev)
+		if i < 0 {
+			m.mu.Unlock()
+			return next(event)
+		}
+		m.matched, m.cut, m.match = true, i, match
+		m.mu.Unlock()
+
+		if i > prev {
+			event.Text = &spec.StreamTextChunk{Text: full[prev:i]}
+			if err := next(event); err != nil {
+				return err
+			}
+		}
+		return errStopPatternMatched
+	}
+}
+
+// finish turns a stream aborted by a match into a successful, truncated response and applies the patterns to
+// non-streamed responses.
+func (m *stopMatcher) finish(resp *spec.FetchCompletionResponse, err error) (*spec.FetchCompletionResponse, error) {
+	m.mu.Lock()
+	matched, cut, match := m.matched, m.cut, m.match
+	m.mu.Unlock()
+
+	if matched {
+		if !errors.Is(err, errStopPatternMatched) {
+			return resp, err
+		}
+		err = nil
+		if resp == nil {
+			resp = &spec.FetchCompletionResponse{}
+		}
+		resp.Error = nil
+	} else if err == nil && resp != nil {
+		cut, match = m.find(outputText(resp.Outputs), 0)
+		matched = cut >= 0
+	}
+	if !matched || resp == nil {
+		return resp, err
+	}
+
+	resp.Outputs = truncateOutputText(resp.Outputs, cut)
+	if resp.Metadata == nil {
+		resp.Metadata = &spec.ResponseMetadata{}
+	}
+	resp.Metadata.StoppedByPattern = match
+	return resp, err
+}
+
+// truncateOutputText keeps the first n bytes of assistant text (as concatenated by outputText) and drops every
+// output after the cut.
+func truncateOutputText(outputs []spec.OutputUnion, n int) []spec.OutputUnion {
+	out := make([]spec.OutputUnion, 0, len(outputs))
+	for _, o := range outputs {
+		if o.Kind != spec.OutputKindOutputMessage || o.OutputMessage == nil {
+			out = append(out, o)
+			continue
+		}
+		msg := *o.OutputMessage
+		contents := make([]spec.InputOutputContentItemUnion, 0, len(msg.Contents))
+		for _, c := range msg.Contents {
+			if c.Kind != spec.ContentItemKindText || c.TextItem == nil {
+				contents = append(contents, c)
+				continue
+			}
+			if len(c.TextItem.Text) <= n {
+				n -= len(c.TextItem.Text)
+				contents = append(contents, c)
+				continue
+			}
+			item := *c.TextItem
+			item.Text = item.Text[:n]
+			c.TextItem = &item
+			contents = append(contents, c)
+			msg.Contents = contents
+			o.OutputMessage = &msg
+			return append(out, o)
+		}
+		msg.Contents = contents
+		o.OutputMessage = &msg
+		out = append(out, o)
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package inference
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestStopMatcherStream(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		patterns  []spec.StopPattern
+		chunks    []string
+		want      string
+		streamed  string
+		wantMatch string
+	}{
+		{
+			name:      "LiteralAcrossChunks.",
+			patterns:  []spec.StopPattern{{Literal: "END"}},
+			chunks:    []string{"hello E", "ND more"},
+			want:      "hello ",
+			streamed:  "hello E",
+			wantMatch: "END",
+		},
+		{
+			name:      "Regex.",
+			patterns:  []spec.StopPattern{{Regex: `\n\d+\.`}},
+			chunks:    []string{"answer", "\n2. next"},
+			want:      "answer",
+			streamed:  "answer",
+			wantMatch: "\n2.",
+		},
+		{
+			name:     "NoMatch.",
+			patterns: []spec.StopPattern{{Literal: "zzz"}},
+			chunks:   []string{"a", "b"},
+			want:     "ab",
+			streamed: "ab",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := newStopMatcher(tc.patterns)
+			if err != nil {
+				t.Fatalf("newStopMatcher() error = %v.", err)
+			}
+			var streamed strings.Builder
+			h := m.handler(func(event spec.StreamEvent) error {
+				streamed.WriteString(event.Text.Text)
+				return nil
+			})
+			var streamErr error
+			var full strings.Builder
+			for _, c := range tc.chunks {
+				full.WriteString(c)
+				streamErr = h(spec.StreamEvent{Kind: spec.StreamContentKindText, Text: &spec.StreamTextChunk{Text: c}})
+				if streamErr != nil {
+					break
+				}
+			}
+
+			resp := &spec.FetchCompletionResponse{Outputs: []spec.OutputUnion{{
+				Kind: spec.OutputKindOutputMessage,
+				OutputMessage: &spec.InputOutputContent{
+					Role: spec.RoleAssistant,
+					Contents: []spec.InputOutputContentItemUnion{{
+						Kind:     spec.ContentItemKindText,
+						TextItem: &spec.ContentItemText{Text: full.String()},
+					}},
+				},
+			}}}
+			got, err := m.finish(resp, streamErr)
+			if err != nil || errors.Is(streamErr, errStopPatternMatched) != (tc.wantMatch != "") {
+				t.Fatalf("finish() error = %v, stream error = %v.", err, streamErr)
+			}
+			if text := outputText(got.Outputs); text != tc.want || streamed.String() != tc.streamed {
+				t.Fatalf("output = %q, streamed = %q, want = %q, %q.", text, streamed.String(), tc.want, tc.streamed)
+			}
+			if tc.wantMatch != "" && got.Metadata.StoppedByPattern != tc.wantMatch {
+				t.Fatalf("StoppedByPattern = %q, want = %q.", got.Metadata.StoppedByPattern, tc.wantMatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package inference
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// StreamAccumulator reconstructs a FetchCompletionResponse-equivalent object from stream events alone.
+//
+// It is meant for proxy layers that only see spec.StreamEvent values (for example events forwarded over the wire).
+// Events with a SequenceNumber at or below the last seen one are treated as duplicates and ignored.
+// The zero value is not usable; use NewStreamAccumulator. A StreamAccumulator is safe for concurrent use.
+type StreamAccumulator struct {
+	mu sync.Mutex
+
+	lastSeq int64
+	items   []*accumulatedItem
+	byKey   map[accumulatedItemKey]*accumulatedItem
+	usage   *spec.Usage
+	err     *spec.Error
+}
+
+type accumulatedItemKey struct {
+	kind  spec.OutputKind
+	index int
+}
+
+type accumulatedItem struct {
+	key    accumulatedItemKey
+	id     string
+	parts  []*strings.Builder
+	call   *spec.ToolCall
+	status spec.Status
+	// citations holds the streamed citations per content part.
+	citations map[int][]spec.Citation
+	// image is the latest partial image of an image generation.
+	image *spec.ContentItemImage
+}
+
+// NewStreamAccumulator returns an empty accumulator.
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{byKey: map[accumulatedItemKey]*accumulatedItem{}}
+}
+
+// Handler returns a StreamHandler that accumulates every event and then forwards it to next, if non-nil.
+func (a *StreamAccumulator) Handler(next spec.StreamHandler) spec.StreamHandler {
+	return func(event spec.StreamEvent) error {
+		a.Add(event)
+		if next == nil {
+			return nil
+		}
+		return next(event)
+	}
+}
+
+// Add folds a single event into the accumulated state.
+func (a *StreamAccumulator) Add(event spec.StreamEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if event.SequenceNumber > 0 {
+		if event.SequenceNumber <= a.lastSeq {
+			return
+		}
+		a.lastSeq = event.SequenceNumber
+	}
+
+	switch event.Kind {
+	case spec.StreamContentKindText:
+		if event.Text != nil {
+			it := a.item(spec.OutputKindOutputMessage, event.OutputItemIndex)
+			it.part(event.ContentIndex).WriteString(event.Text.Text)
+		}
+
+	case spec.StreamContentKindThinking:
+		if event.Thinking != nil {
+			it := a.item(spec.OutputKindReasoningMessage, event.OutputItemIndex)
+			it.part(event.ContentIndex).WriteString(event.Thinking.Text)
+		}
+
+	case spec.StreamContentKindCitation:
+		if event.Citation != nil {
+			it := a.item(spec.OutputKindOutputMessage, event.OutputItemIndex)
+			if it.citations == nil {
+				it.citations = map[int][]spec.Citation{}
+			}
+			it.citations[event.ContentIndex] = append(it.citations[event.ContentIndex], *event.Citation)
+		}
+
+	case spec.StreamContentKindToolCall:
+		if event.ToolCall != nil {
+			a.addToolCall(event.ToolCall)
+		}
+
+	case spec.StreamContentKindImagePartial:
+		if ip := event.ImagePartial; ip != nil {
+			it := a.item(spec.OutputKindImageGenerationToolOutput, event.OutputItemIndex)
+			if ip.ItemID != "" {
+				it.id = ip.ItemID
+			}
+			it.image = &spec.ContentItemImage{ImageMIME: ip.ImageMIME, ImageData: ip.ImageData}
+		}
+
+	case spec.StreamContentKindOutputItem:
+		oi := event.OutputItem
+		if oi == nil || oi.OutputKind == "" {
+			return
+		}
+		it := a.item(oi.OutputKind, oi.OutputIndex)
+		if oi.ItemID != "" {
+			it.id = oi.ItemID
+		}
+		if oi.Phase == spec.StreamLifecyclePhaseDone {
+			it.status = spec.StatusCompleted
+		}
+
+	case spec.StreamContentKindUsage:
+		if event.Usage != nil {
+			u := *event.Usage
+			a.usage = &u
+		}
+
+	case spec.StreamContentKindDone:
+		if d := event.Done; d != nil {
+			if d.Usage != nil {
+				u := *d.Usage
+				a.usage = &u
+			}
+			if d.Error != nil {
+				e := *d.Error
+				a.err = &e
+			}
+		}
+
+	case spec.StreamContentKindError:
+		if event.Error != nil && event.Error.Error != nil {
+			e := *event.Error.Error
+			a.err = &e
+		}
+
+	default:
+		// Content parts and unknown kinds carry no output data.
+	}
+}
+
+// Response returns a snapshot of the accumulated outputs, usage and error.
+// It can be called at any time, including while the stream is still in progress.
+func (a *StreamAccumulator) Response() *spec.FetchCompletionResponse {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	resp := &spec.FetchCompletionResponse{}
+	for _, it := range a.items {
+		if out, ok := it.output(); ok {
+			resp.Outputs = append(resp.Outputs, out)
+		}
+	}
+	if a.usage != nil {
+		u := *a.usage
+		resp.Usage = &u
+	}
+	if a.err != nil {
+		e := *a.err
+		resp.Error = &e
+	}
+	return resp
+}
+
+func (a *StreamAccumulator) addToolCall(tc *spec.StreamToolCallChunk) {
+	kind := spec.OutputKindFunctionToolCall
+	if tc.Type == spec.ToolTypeCustom {
+		kind = spec.OutputKindCustomToolCall
+	}
+	it := a.item(kind, tc.Index)
+	if it.call == nil {
+		it.call = &spec.ToolCall{Type: tc.Type, Role: spec.RoleAssistant, Status: spec.StatusInProgress}
+	}
+	c := it.call
+	if tc.ChoiceID != "" {
+		c.ChoiceID = tc.ChoiceID
+	}
+	if tc.CallID != "" {
+		c.CallID = tc.CallID
+		c.ID = tc.CallID
+	}
+	if tc.Name != "" {
+		c.Name = tc.Name
+	}
+	switch tc.Phase {
+	case spec.StreamToolCallPhaseDelta:
+		c.Arguments += tc.ArgumentsDelta
+	case spec.StreamToolCallPhaseFinish:
+		if tc.Arguments != "" {
+			c.Arguments = tc.Arguments
+		}
+		c.Status = spec.StatusCompleted
+	default:
+		// Start only carries identity.
+	}
+}
+
+func (a *StreamAccumulator) item(kind spec.OutputKind, index int) *accumulatedItem {
+	key := accumulatedItemKey{kind: kind, index: index}
+	if it, ok := a.byKey[key]; ok {
+		return it
+	}
+	it := &accumulatedItem{key: key}
+	a.byKey[key] = it
+	a.items = append(a.items, it)
+	return it
+}
+
+func (it *accumulatedItem) part(index int) *strings.Builder {
+	for len(it.parts) <= index {
+		it.parts = append(it.parts, &strings.Builder{})
+	}
+	return it.parts[index]
+}
+
+func (it *accumulatedItem) texts() []string {
+	var out []string
+	for _, p := range it.parts {
+		if p.Len() > 0 {
+			out = append(out, p.String())
+		}
+	}
+	return out
+}
+
+func (it *accumulatedItem) output() (spec.OutputUnion, bool) {
+	switch it.key.kind {
+	case spec.OutputKindOutputMessage:
+		msg := &spec.InputOutputContent{ID: it.id, Role: spec.RoleAssistant, Status: it.status}
+		for i, p := range it.parts {
+			if p.Len() == 0 {
+				continue
+			}
+			msg.Contents = append(msg.Contents, spec.InputOutputContentItemUnion{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: p.String(), Citations: slices.Clone(it.citations[i])},
+			})
+		}
+		if len(msg.Contents) == 0 {
+			return spec.OutputUnion{}, false
+		}
+		return spec.OutputUnion{Kind: spec.OutputKindOutputMessage, OutputMessage: msg}, true
+
+	case spec.OutputKindReasoningMessage:
+		texts := it.texts()
+		if len(texts) == 0 {
+			return spec.OutputUnion{}, false
+		}
+		r := &spec.ReasoningContent{ID: it.id, Role: spec.RoleAssistant, Status: it.status, Thinking: texts}
+		return spec.OutputUnion{Kind: spec.OutputKindReasoningMessage, ReasoningMessage: r}, true
+
+	case spec.OutputKindFunctionToolCall, spec.OutputKindCustomToolCall:
+		if it.call == nil {
+			return spec.OutputUnion{}, false
+		}
+		c := *it.call
+		out := spec.OutputUnion{Kind: it.key.kind}
+		if it.key.kind == spec.OutputKindCustomToolCall {
+			out.CustomToolCall = &c
+		} else {
+			out.FunctionToolCall = &c
+		}
+		return out, true
+
+	case spec.OutputKindImageGenerationToolOutput:
+		if it.image == nil {
+			return spec.OutputUnion{}, false
+		}
+		img := *it.image
+		status := it.status
+		if status == "" {
+			status = spec.StatusInProgress
+		}
+		return spec.OutputUnion{
+			Kind: spec.OutputKindImageGenerationToolOutput,
+			ImageGenerationToolOutput: &spec.ToolOutput{
+				Type:     spec.ToolTypeImageGeneration,
+				ID:       it.id,
+				Role:     spec.RoleAssistant,
+				Status:   status,
+				Contents: []spec.ToolOutputItemUnion{{Kind: spec.ContentItemKindImage, ImageItem: &img}},
+			},
+		}, true
+
+	default:
+		return spec.OutputUnion{}, false
+	}
+}
+
+// outputText concatenates all assistant text in outputs.
+func outputText(outputs []spec.OutputUnion) string {
+	var b strings.Builder
+	for _, o := range outputs {
+		if o.Kind != spec.OutputKindOutputMessage || o.OutputMessage == nil {
+			continue
+		}
+		for _, c := range o.OutputMessage.Contents {
+			if c.Kind == spec.ContentItemKindText && c.TextItem != nil {
+				b.WriteString(c.TextItem.Text)
+			}
+		}
+	}
+	return b.String()
+}
+
+// webSearchCalls counts the server-side web search calls in outputs.
+func webSearchCalls(outputs []spec.OutputUnion) int {
+	n := 0
+	for _, o := range outputs {
+		if o.Kind == spec.OutputKindWebSearchToolCall && o.WebSearchToolCall != nil {
+			n++
+		}
+	}
+	return n
+}
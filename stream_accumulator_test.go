@@ -0,0 +1,97 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestStreamAccumulator(t *testing.T) {
+	t.Parallel()
+
+	events := []spec.StreamEvent{
+		{SequenceNumber: 1, Kind: spec.StreamContentKindThinking, Thinking: &spec.StreamThinkingChunk{Text: "hmm"}},
+		{
+			SequenceNumber:  2,
+			Kind:            spec.StreamContentKindText,
+			OutputItemIndex: 1,
+			Text:            &spec.StreamTextChunk{Text: "Hello, "},
+		},
+		// Duplicate delivery is ignored.
+		{
+			SequenceNumber:  2,
+			Kind:            spec.StreamContentKindText,
+			OutputItemIndex: 1,
+			Text:            &spec.StreamTextChunk{Text: "Hello, "},
+		},
+		{
+			SequenceNumber:  3,
+			Kind:            spec.StreamContentKindText,
+			OutputItemIndex: 1,
+			Text:            &spec.StreamTextChunk{Text: "world"},
+		},
+		{SequenceNumber: 4, Kind: spec.StreamContentKindToolCall, ToolCall: &spec.StreamToolCallChunk{
+			Phase: spec.StreamToolCallPhaseStart, Type: spec.ToolTypeFunction, CallID: "c1", Name: "get",
+		}},
+		{SequenceNumber: 5, Kind: spec.StreamContentKindToolCall, ToolCall: &spec.StreamToolCallChunk{
+			Phase: spec.StreamToolCallPhaseDelta, ArgumentsDelta: `{"a":`,
+		}},
+		{SequenceNumber: 6, Kind: spec.StreamContentKindToolCall, ToolCall: &spec.StreamToolCallChunk{
+			Phase: spec.StreamToolCallPhaseDelta, ArgumentsDelta: `1}`,
+		}},
+		{SequenceNumber: 7, Kind: spec.StreamContentKindToolCall, ToolCall: &spec.StreamToolCallChunk{
+			Phase: spec.StreamToolCallPhaseFinish,
+		}},
+		{SequenceNumber: 8, Kind: spec.StreamContentKindUsage, Usage: &spec.Usage{OutputTokens: 9}},
+	}
+
+	acc := NewStreamAccumulator()
+	h := acc.Handler(nil)
+	for _, ev := range events {
+		if err := h(ev); err != nil {
+			t.Fatalf("handler error = %v.", err)
+		}
+	}
+
+	resp := acc.Response()
+	if len(resp.Outputs) != 3 {
+		t.Fatalf("len(Outputs) = %d, want = 3.", len(resp.Outputs))
+	}
+	if got := resp.Outputs[0].ReasoningMessage.Thinking[0]; got != "hmm" {
+		t.Fatalf("thinking = %q, want = %q.", got, "hmm")
+	}
+	if got := resp.Outputs[1].OutputMessage.Contents[0].TextItem.Text; got != "Hello, world" {
+		t.Fatalf("text = %q, want = %q.", got, "Hello, world")
+	}
+	call := resp.Outputs[2].FunctionToolCall
+	if call.Arguments != `{"a":1}` || call.Name != "get" || call.Status != spec.StatusCompleted {
+		t.Fatalf("tool call = %+v, want completed get({\"a\":1}).", call)
+	}
+	if resp.Usage == nil || resp.Usage.OutputTokens != 9 {
+		t.Fatalf("Usage = %+v, want OutputTokens = 9.", resp.Usage)
+	}
+}
+
+func TestStreamAccumulatorImagePartial(t *testing.T) {
+	t.Parallel()
+
+	acc := NewStreamAccumulator()
+	h := acc.Handler(nil)
+	for i, data := range []string{"UDA=", "UDE="} {
+		if err := h(spec.StreamEvent{
+			SequenceNumber: int64(i + 1),
+			Kind:           spec.StreamContentKindImagePartial,
+			ImagePartial:   &spec.StreamImagePartialChunk{ItemID: "ig1", ImageMIME: "image/webp", ImageData: data},
+		}); err != nil {
+			t.Fatalf("handler error = %v.", err)
+		}
+	}
+
+	// The accumulator holds the latest partial image until the final output arrives.
+	outs := acc.Response().Outputs
+	if len(outs) != 1 || outs[0].ImageGenerationToolOutput == nil ||
+		outs[0].ImageGenerationToolOutput.ID != "ig1" ||
+		outs[0].ImageGenerationToolOutput.Contents[0].ImageItem.ImageData != "UDE=" {
+		t.Fatalf("accumulated Outputs = %+v, want the last partial image.", outs)
+	}
+}
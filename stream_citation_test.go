@@ -0,0 +1,125 @@
+package inference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionStreamCitationEvents(t *testing.T) {
+	t.Parallel()
+
+	sse := func(event, data string) string {
+		if event == "" {
+			return "data: " + data + "\n\n"
+		}
+		return "event: " + event + "\ndata: " + data + "\n\n"
+	}
+	chatChunk := func(body string) string {
+		return sse("", `{"id":"c1","object":"chat.completion.chunk","created":0,"model":"m",`+body+`}`)
+	}
+	adapters := []struct {
+		name   string
+		config AddProviderConfig
+		stream string
+	}{
+		{
+			name: "OpenAIChat.",
+			config: AddProviderConfig{
+				SDKType: spec.ProviderSDKTypeOpenAIChatCompletions, ChatCompletionPathPrefix: "/chat/completions",
+			},
+			stream: chatChunk(`"choices":[{"index":0,"delta":{"role":"assistant","content":"Go is fun."},`+
+				`"finish_reason":null}]`) +
+				chatChunk(`"choices":[{"index":0,"delta":{"annotations":[{"type":"url_citation",`+
+					`"url_citation":{"url":"https://go.dev","title":"Go","start_index":0,"end_index":10}}]},`+
+					`"finish_reason":null}]`) +
+				chatChunk(`"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]`) +
+				"data: [DONE]\n\n",
+		},
+		{
+			name:   "OpenAIResponses.",
+			config: AddProviderConfig{SDKType: spec.ProviderSDKTypeOpenAIResponses},
+			stream: sse("", `{"type":"response.output_text.delta","sequence_number":1,"item_id":"m1",`+
+				`"output_index":0,"content_index":0,"delta":"Go is fun."}`) +
+				sse("", `{"type":"response.output_text.annotation.added","sequence_number":2,"item_id":"m1",`+
+					`"output_index":0,"content_index":0,"annotation_index":0,"annotation":{"type":"url_citation",`+
+					`"url":"https://go.dev","title":"Go","start_index":0,"end_index":10}}`) +
+				sse("", `{"type":"response.completed","sequence_number":3,"response":{"id":"r1",`+
+					`"object":"response","created_at":0,"model":"m","status":"completed","output":[]}}`),
+		},
+		{
+			name: "Anthropic.",
+			config: AddProviderConfig{
+				SDKType:                  spec.ProviderSDKTypeAnthropic,
+				ChatCompletionPathPrefix: spec.DefaultAnthropicChatCompletionPrefix,
+			},
+			stream: sse("message_start", `{"type":"message_start","message":{"id":"msg1","type":"message",`+
+				`"role":"assistant","model":"m","content":[],"usage":{"input_tokens":1,"output_tokens":1}}}`) +
+				sse("content_block_start", `{"type":"content_block_start","index":0,`+
+					`"content_block":{"type":"text","text":""}}`) +
+				sse("content_block_delta", `{"type":"content_block_delta","index":0,`+
+					`"delta":{"type":"citations_delta","citation":{"type":"web_search_result_location",`+
+					`"url":"https://go.dev","title":"Go","cited_text":"Go is fun","encrypted_index":"e1"}}}`) +
+				sse("content_block_delta", `{"type":"content_block_delta","index":0,`+
+					`"delta":{"type":"text_delta","text":"Go is fun."}}`) +
+				sse("content_block_stop", `{"type":"content_block_stop","index":0}`) +
+				sse("message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn"},`+
+					`"usage":{"output_tokens":3}}`) +
+				sse("message_stop", `{"type":"message_stop"}`),
+		},
+	}
+
+	for _, a := range adapters {
+		t.Run(a.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte(a.stream))
+			}))
+			t.Cleanup(srv.Close)
+
+			ps := newTestProviderSet(t)
+			config := a.config
+			config.Origin = srv.URL
+			addTestProvider(t, ps, "p", &config)
+
+			acc := NewStreamAccumulator()
+			var citations []spec.Citation
+			_, err := ps.FetchCompletion(t.Context(), "p", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m", Stream: true},
+				Inputs:     userInputs("Is Go fun?"),
+			}, &spec.FetchCompletionOptions{
+				StreamHandler: acc.Handler(func(event spec.StreamEvent) error {
+					if event.Kind == spec.StreamContentKindCitation {
+						citations = append(citations, *event.Citation)
+					}
+					return nil
+				}),
+			})
+			if err != nil {
+				t.Fatalf("FetchCompletion() error = %v.", err)
+			}
+
+			if len(citations) != 1 {
+				t.Fatalf("citation events = %d, want 1.", len(citations))
+			}
+			c := citations[0]
+			if c.Kind != spec.CitationKindURL || c.URLCitation == nil ||
+				c.URLCitation.URL != "https://go.dev" || c.URLCitation.Title != "Go" {
+				t.Fatalf("citation = %+v, want a URL citation of https://go.dev.", c)
+			}
+
+			outs := acc.Response().Outputs
+			if len(outs) != 1 || outs[0].OutputMessage == nil || len(outs[0].OutputMessage.Contents) != 1 {
+				t.Fatalf("accumulated outputs = %+v, want one text message.", outs)
+			}
+			text := outs[0].OutputMessage.Contents[0].TextItem
+			if len(text.Citations) != 1 || text.Citations[0].URLCitation.URL != "https://go.dev" {
+				t.Fatalf("accumulated citations = %+v, want the streamed citation.", text.Citations)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package inference
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// doneStream sits in front of the caller's stream handler and delivers the StreamContentKindDone event once the call
+// finished, numbered after the last event the caller received.
+type doneStream struct {
+	mu         sync.Mutex
+	next       spec.StreamHandler
+	lastSeq    int64
+	handlerErr error
+}
+
+func (s *doneStream) handle(event spec.StreamEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSeq = max(s.lastSeq, event.SequenceNumber)
+	err := s.next(event)
+	if err != nil && s.handlerErr == nil {
+		s.handlerErr = err
+	}
+	return err
+}
+
+// finish delivers the done event for the final response of the call. It is skipped if the handler failed.
+func (s *doneStream) finish(resp *spec.FetchCompletionResponse, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handlerErr != nil {
+		return
+	}
+
+	done := &spec.StreamDoneChunk{Status: streamDoneStatus(resp, err)}
+	event := spec.StreamEvent{Kind: spec.StreamContentKindDone, SequenceNumber: s.lastSeq + 1, Done: done}
+	if resp != nil {
+		if resp.Usage != nil {
+			u := *resp.Usage
+			done.Usage = &u
+		}
+		done.Error = resp.Error
+		if resp.Metadata != nil {
+			event.Provider, event.Model = resp.Metadata.Provider, resp.Metadata.ResolvedModel
+		}
+	}
+	s.lastSeq++
+	_ = s.next(event)
+}
+
+func streamDoneStatus(resp *spec.FetchCompletionResponse, err error) spec.Status {
+	var ce *spec.CanceledError
+	switch {
+	case errors.As(err, &ce):
+		return spec.StatusCancelled
+	case err != nil:
+		return spec.StatusFailed
+	case resp == nil:
+		return spec.StatusCompleted
+	}
+	for _, out := range resp.Outputs {
+		if out.OutputMessage != nil && out.OutputMessage.Status == spec.StatusIncomplete {
+			return spec.StatusIncomplete
+		}
+	}
+	return spec.StatusCompleted
+}
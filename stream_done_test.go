@@ -0,0 +1,114 @@
+package inference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionStreamDoneEvent(t *testing.T) {
+	t.Parallel()
+
+	chunk := func(body string) string {
+		return `data: {"id":"c1","object":"chat.completion.chunk","created":0,"model":"m",` + body + "}\n\n"
+	}
+	stream := func(finishReason string) string {
+		return chunk(`"choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":null}]`) +
+			chunk(`"choices":[{"index":0,"delta":{},"finish_reason":"`+finishReason+`"}]`) +
+			chunk(`"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}`) +
+			"data: [DONE]\n\n"
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus spec.Status
+		wantUsage  bool
+		wantErr    bool
+	}{
+		{
+			name: "Completed.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte(stream("stop")))
+			},
+			wantStatus: spec.StatusCompleted,
+			wantUsage:  true,
+		},
+		{
+			name: "Incomplete.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte(stream("length")))
+			},
+			wantStatus: spec.StatusIncomplete,
+			wantUsage:  true,
+		},
+		{
+			name: "Failed.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				body := `{"error":{"message":"bad request","type":"invalid_request_error"}}`
+				http.Error(w, body, http.StatusBadRequest)
+			},
+			wantStatus: spec.StatusFailed,
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(tc.handler)
+			t.Cleanup(srv.Close)
+
+			ps := newTestProviderSet(t)
+			addTestProvider(t, ps, "openai", &AddProviderConfig{
+				SDKType:                  spec.ProviderSDKTypeOpenAIChatCompletions,
+				Origin:                   srv.URL,
+				ChatCompletionPathPrefix: "/chat/completions",
+			})
+
+			var events []spec.StreamEvent
+			resp, err := ps.FetchCompletion(t.Context(), "openai", &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "m", Stream: true},
+				Inputs:     userInputs("hi"),
+			}, &spec.FetchCompletionOptions{
+				StreamHandler: func(event spec.StreamEvent) error {
+					events = append(events, event)
+					return nil
+				},
+			})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("FetchCompletion() error = %v, wantErr %v.", err, tc.wantErr)
+			}
+
+			if len(events) == 0 {
+				t.Fatalf("no stream events, want a done event.")
+			}
+			last := events[len(events)-1]
+			if last.Kind != spec.StreamContentKindDone || last.Done == nil {
+				t.Fatalf("last event = %+v, want a done event.", last)
+			}
+			for i, ev := range events[:len(events)-1] {
+				if ev.Kind == spec.StreamContentKindDone {
+					t.Fatalf("events[%d] is a done event, want it only last.", i)
+				}
+				if ev.SequenceNumber >= last.SequenceNumber {
+					t.Fatalf("events[%d].SequenceNumber = %d, want below the done event's %d.",
+						i, ev.SequenceNumber, last.SequenceNumber)
+				}
+			}
+			done := last.Done
+			if done.Status != tc.wantStatus {
+				t.Fatalf("Done.Status = %q, want %q.", done.Status, tc.wantStatus)
+			}
+			if tc.wantUsage && (done.Usage == nil || done.Usage.OutputTokens != 1 || done.Usage.InputTokensTotal != 3) {
+				t.Fatalf("Done.Usage = %+v, want 3 input and 1 output tokens.", done.Usage)
+			}
+			if tc.wantErr && (done.Error == nil || resp == nil || resp.Error == nil) {
+				t.Fatalf("Done.Error = %+v, want the response error.", done.Error)
+			}
+		})
+	}
+}
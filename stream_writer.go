@@ -0,0 +1,124 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	ansiDim   = "\x1b[2m"
+	ansiReset = "\x1b[0m"
+)
+
+// StreamWriterOptions configure how FetchCompletionToWriter renders a stream.
+type StreamWriterOptions struct {
+	// IncludeThinking also writes thinking text. It is separated from the answer text by a blank line.
+	IncludeThinking bool
+	// ANSI renders thinking text dimmed with ANSI escape codes, for terminals.
+	ANSI bool
+}
+
+// FetchCompletionToWriter streams the text of a completion into w as it arrives and returns the full response.
+// The request is always sent as a streaming request; a StreamHandler in opts still receives every event after it has
+// been written. A failed write aborts the stream and is returned.
+func (ps *ProviderSetAPI) FetchCompletionToWriter(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	w io.Writer,
+	wopts *StreamWriterOptions,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	if req == nil || w == nil {
+		return nil, errors.New("got empty request or writer")
+	}
+	sw := &streamWriter{w: w}
+	if wopts != nil {
+		sw.opts = *wopts
+	}
+
+	streamReq := *req
+	streamReq.ModelParam.Stream = true
+	var streamOpts spec.FetchCompletionOptions
+	if opts != nil {
+		streamOpts = *opts
+	}
+	streamOpts.StreamHandler = sw.handler(streamOpts.StreamHandler)
+
+	resp, err := ps.FetchCompletion(ctx, provider, &streamReq, &streamOpts)
+	if ferr := sw.finish(); ferr != nil && err == nil {
+		err = ferr
+	}
+	return resp, err
+}
+
+// streamWriter writes text and thinking events to an io.Writer, separating a thinking run from the text after it.
+type streamWriter struct {
+	w        io.Writer
+	opts     StreamWriterOptions
+	thinking bool
+}
+
+func (s *streamWriter) handler(next spec.StreamHandler) spec.StreamHandler {
+	return func(event spec.StreamEvent) error {
+		if err := s.write(event); err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		return next(event)
+	}
+}
+
+func (s *streamWriter) write(event spec.StreamEvent) error {
+	switch {
+	case event.Kind == spec.StreamContentKindText && event.Text != nil && event.Text.Text != "":
+		if err := s.endThinking(); err != nil {
+			return err
+		}
+		_, err := io.WriteString(s.w, event.Text.Text)
+		return err
+
+	case event.Kind == spec.StreamContentKindThinking && event.Thinking != nil && event.Thinking.Text != "" &&
+		s.opts.IncludeThinking:
+		if !s.thinking {
+			s.thinking = true
+			if s.opts.ANSI {
+				if _, err := io.WriteString(s.w, ansiDim); err != nil {
+					return err
+				}
+			}
+		}
+		_, err := io.WriteString(s.w, event.Thinking.Text)
+		return err
+	}
+	return nil
+}
+
+// endThinking closes a thinking run before answer text follows it.
+func (s *streamWriter) endThinking() error {
+	if !s.thinking {
+		return nil
+	}
+	s.thinking = false
+	sep := "\n\n"
+	if s.opts.ANSI {
+		sep = ansiReset + sep
+	}
+	_, err := io.WriteString(s.w, sep)
+	return err
+}
+
+// finish resets the terminal style if the stream ended while thinking.
+func (s *streamWriter) finish() error {
+	if !s.thinking || !s.opts.ANSI {
+		return nil
+	}
+	s.thinking = false
+	_, err := io.WriteString(s.w, ansiReset)
+	return err
+}
@@ -0,0 +1,73 @@
+package inference
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionToWriter(t *testing.T) {
+	t.Parallel()
+
+	stream := `{"model":"qwen3","message":{"role":"assistant","content":"","thinking":"hm"},"done":false}` + "\n" +
+		`{"model":"qwen3","message":{"role":"assistant","content":"","thinking":"m"},"done":false}` + "\n" +
+		`{"model":"qwen3","message":{"role":"assistant","content":"Hello"},"done":false}` + "\n" +
+		`{"model":"qwen3","message":{"role":"assistant","content":" there"},"done":true,"done_reason":"stop"}` + "\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(stream))
+	}))
+	t.Cleanup(srv.Close)
+
+	ps := newTestProviderSet(t)
+	addTestProvider(t, ps, "ollama", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOllama,
+		Origin:  srv.URL,
+	})
+
+	tests := []struct {
+		name  string
+		wopts *StreamWriterOptions
+		want  string
+	}{
+		{name: "TextOnly.", want: "Hello there"},
+		{name: "Thinking.", wopts: &StreamWriterOptions{IncludeThinking: true}, want: "hmm\n\nHello there"},
+		{
+			name:  "ThinkingANSI.",
+			wopts: &StreamWriterOptions{IncludeThinking: true, ANSI: true},
+			want:  "\x1b[2mhmm\x1b[0m\n\nHello there",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &spec.FetchCompletionRequest{
+				ModelParam: spec.ModelParam{Name: "qwen3"},
+				Inputs:     userInputs("hi"),
+			}
+			var (
+				b      strings.Builder
+				events int
+			)
+			resp, err := ps.FetchCompletionToWriter(t.Context(), "ollama", req, &b, tc.wopts,
+				&spec.FetchCompletionOptions{StreamHandler: func(spec.StreamEvent) error {
+					events++
+					return nil
+				}})
+			if err != nil {
+				t.Fatalf("FetchCompletionToWriter() error = %v.", err)
+			}
+			if b.String() != tc.want {
+				t.Fatalf("written = %q, want = %q.", b.String(), tc.want)
+			}
+			if req.ModelParam.Stream {
+				t.Fatalf("request was modified: Stream = true.")
+			}
+			if events == 0 || resp == nil || len(resp.Outputs) == 0 {
+				t.Fatalf("events = %d, resp = %+v, want forwarded events and a response.", events, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+// Package usagereport delivers a compact usage and cost record per completed request to a billing sink, such as a
+// webhook, with batching and retry.
+//
+// Records are queued without blocking the completion call and sent in batches by a background goroutine. Wrap a
+// fetcher with Reporter.Fetcher, or call Report directly.
+package usagereport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	DefaultBatchSize     = 50
+	DefaultFlushInterval = 5 * time.Second
+	DefaultQueueSize     = 1000
+	DefaultMaxRetries    = 3
+	DefaultRetryBackoff  = 500 * time.Millisecond
+	DefaultSendTimeout   = 30 * time.Second
+)
+
+// Record is the usage of one completed request.
+type Record struct {
+	Time              time.Time         `json:"time"`
+	Provider          spec.ProviderName `json:"provider"`
+	Model             spec.ModelName    `json:"model"`
+	InputTokens       int64             `json:"inputTokens"`
+	CachedInputTokens int64             `json:"cachedInputTokens,omitempty"`
+	OutputTokens      int64             `json:"outputTokens"`
+	ReasoningTokens   int64             `json:"reasoningTokens,omitempty"`
+	Cost              float64           `json:"cost,omitempty"`
+	LatencyMillis     int64             `json:"latencyMillis"`
+	Error             string            `json:"error,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+}
+
+// Sender delivers a batch of records. A returned error triggers a retry of the whole batch.
+type Sender interface {
+	Send(ctx context.Context, records []Record) error
+}
+
+// SenderFunc adapts a callback to Sender.
+type SenderFunc func(ctx context.Context, records []Record) error
+
+func (f SenderFunc) Send(ctx context.Context, records []Record) error {
+	return f(ctx, records)
+}
+
+// WebhookSender posts batches as JSON {"records": [...]} to URL.
+type WebhookSender struct {
+	URL     string
+	Headers map[string]string
+	// Client defaults to a client with a DefaultSendTimeout timeout.
+	Client *http.Client
+}
+
+var defaultWebhookClient = &http.Client{Timeout: DefaultSendTimeout}
+
+func (w *WebhookSender) Send(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return fmt.Errorf("usagereport: encode batch: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("usagereport: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	client := w.Client
+	if client == nil {
+		client = defaultWebhookClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("usagereport: send batch: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("usagereport: send batch: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// CostFunc prices the usage of one request. The unit is up to the caller.
+type CostFunc func(provider spec.ProviderName, model spec.ModelName, usage *spec.Usage) float64
+
+type Options struct {
+	// BatchSize is the number of records per Send. Zero means DefaultBatchSize.
+	BatchSize int
+	// FlushInterval bounds how long a record waits for a batch to fill. Zero means DefaultFlushInterval.
+	FlushInterval time.Duration
+	// QueueSize bounds the records waiting to be batched; further records are dropped. Zero means DefaultQueueSize.
+	QueueSize int
+	// MaxRetries is the number of extra Send attempts per batch. Zero means DefaultMaxRetries; negative disables.
+	MaxRetries int
+	// RetryBackoff is the first retry delay; it doubles per attempt. Zero means DefaultRetryBackoff.
+	RetryBackoff time.Duration
+	// SendTimeout bounds each Send attempt. Zero means DefaultSendTimeout.
+	SendTimeout time.Duration
+	// Cost, if set, fills Record.Cost. Otherwise the provider-reported Usage.Cost is used.
+	Cost CostFunc
+	// Tags are added to every record. Per-request tags come from WithTags.
+	Tags map[string]string
+	// OnError is called when a batch is dropped after all retries.
+	OnError func(err error, records []Record)
+}
+
+// Reporter batches records and sends them in the background. Close it to flush.
+type Reporter struct {
+	sender  Sender
+	opts    Options
+	queue   chan Record
+	flushCh chan chan struct{}
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Int64
+}
+
+func New(sender Sender, opts Options) (*Reporter, error) {
+	if sender == nil {
+		return nil, errors.New("usagereport: nil sender")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultFlushInterval
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = DefaultMaxRetries
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultRetryBackoff
+	}
+	if opts.SendTimeout <= 0 {
+		opts.SendTimeout = DefaultSendTimeout
+	}
+	r := &Reporter{
+		sender:  sender,
+		opts:    opts,
+		queue:   make(chan Record, opts.QueueSize),
+		flushCh: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// Report queues rec without blocking. It returns false if the record was dropped because the queue is full or the
+// reporter is closed.
+func (r *Reporter) Report(rec Record) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		r.dropped.Add(1)
+		return false
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	if len(r.opts.Tags) > 0 {
+		tags := maps.Clone(r.opts.Tags)
+		maps.Copy(tags, rec.Tags)
+		rec.Tags = tags
+	}
+	select {
+	case r.queue <- rec:
+		return true
+	default:
+		r.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns the number of records dropped because the queue was full or the reporter was closed.
+func (r *Reporter) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Flush sends all queued records and waits until they were sent (or dropped after retries), or ctx is done.
+func (r *Reporter) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case r.flushCh <- ack:
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting records, sends the queued ones and waits for completion or ctx.
+func (r *Reporter) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Reporter) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, r.opts.BatchSize)
+	send := func() {
+		if len(batch) > 0 {
+			r.send(batch)
+			batch = make([]Record, 0, r.opts.BatchSize)
+		}
+	}
+	for {
+		select {
+		case rec, ok := <-r.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, rec)
+			if len(batch) >= r.opts.BatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-r.flushCh:
+			for drained := false; !drained; {
+				select {
+				case rec, ok := <-r.queue:
+					if !ok {
+						drained = true
+						continue
+					}
+					batch = append(batch, rec)
+					if len(batch) >= r.opts.BatchSize {
+						send()
+					}
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(ack)
+		}
+	}
+}
+
+// send delivers one batch with exponential backoff retries.
+func (r *Reporter) send(batch []Record) {
+	backoff := r.opts.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= max(r.opts.MaxRetries, 0); attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = r.sendOnce(batch); err == nil {
+			return
+		}
+	}
+	if r.opts.OnError != nil {
+		r.opts.OnError(err, batch)
+	}
+}
+
+func (r *Reporter) sendOnce(batch []Record) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.SendTimeout)
+	defer cancel()
+	return r.sender.Send(ctx, batch)
+}
+
+type tagsKey struct{}
+
+// WithTags attaches per-request tags (e.g. tenant, feature) to records built by Reporter.Fetcher.
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	if prev, ok := ctx.Value(tagsKey{}).(map[string]string); ok {
+		merged := make(map[string]string, len(prev)+len(tags))
+		maps.Copy(merged, prev)
+		maps.Copy(merged, tags)
+		tags = merged
+	}
+	return context.WithValue(ctx, tagsKey{}, tags)
+}
+
+// CompletionFetcher is the subset of inference.ProviderSetAPI wrapped by Fetcher.
+type CompletionFetcher interface {
+	FetchCompletion(
+		ctx context.Context,
+		provider spec.ProviderName,
+		fetchCompletionRequest *spec.FetchCompletionRequest,
+		opts *spec.FetchCompletionOptions,
+	) (*spec.FetchCompletionResponse, error)
+}
+
+// Fetcher wraps next so that a record is reported for every completion call.
+func (r *Reporter) Fetcher(next CompletionFetcher) CompletionFetcher {
+	return &fetcher{next: next, reporter: r}
+}
+
+type fetcher struct {
+	next     CompletionFetcher
+	reporter *Reporter
+}
+
+func (f *fetcher) FetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	start := time.Now()
+	resp, err := f.next.FetchCompletion(ctx, provider, req, opts)
+	if req == nil {
+		return resp, err
+	}
+
+	rec := Record{
+		Time:          start,
+		Provider:      provider,
+		Model:         req.ModelParam.Name,
+		LatencyMillis: time.Since(start).Milliseconds(),
+	}
+	rec.Tags, _ = ctx.Value(tagsKey{}).(map[string]string)
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	if resp != nil && resp.Usage != nil {
+		u := resp.Usage
+		rec.InputTokens = u.InputTokensTotal
+		rec.CachedInputTokens = u.InputTokensCached
+		rec.OutputTokens = u.OutputTokens
+		rec.ReasoningTokens = u.ReasoningTokens
+		if f.reporter.opts.Cost != nil {
+			rec.Cost = f.reporter.opts.Cost(provider, req.ModelParam.Name, u)
+		} else if u.Cost != nil {
+			rec.Cost = *u.Cost
+		}
+	}
+	f.reporter.Report(rec)
+	return resp, err
+}
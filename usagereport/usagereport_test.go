@@ -0,0 +1,160 @@
+package usagereport
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type stubFetcher struct{}
+
+func (stubFetcher) FetchCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	return &spec.FetchCompletionResponse{Usage: &spec.Usage{InputTokensTotal: 10, OutputTokens: 5}}, nil
+}
+
+func TestReporter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		failures    int
+		requests    int
+		batchSize   int
+		wantBatches int
+		wantDropped bool
+	}{
+		{name: "BatchesBySize.", requests: 5, batchSize: 2, wantBatches: 3},
+		{name: "RetriesFailedBatch.", failures: 2, requests: 1, batchSize: 10, wantBatches: 1},
+		{name: "DropsAfterRetries.", failures: 100, requests: 1, batchSize: 10, wantDropped: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var batches [][]Record
+			failures := tc.failures
+			sender := SenderFunc(func(ctx context.Context, records []Record) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if failures > 0 {
+					failures--
+					return errors.New("unavailable")
+				}
+				batches = append(batches, records)
+				return nil
+			})
+			var dropped []Record
+			r, err := New(sender, Options{
+				BatchSize:    tc.batchSize,
+				MaxRetries:   2,
+				RetryBackoff: time.Millisecond,
+				Tags:         map[string]string{"env": "test"},
+				Cost:         func(spec.ProviderName, spec.ModelName, *spec.Usage) float64 { return 0.25 },
+				OnError:      func(_ error, records []Record) { dropped = records },
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v.", err)
+			}
+
+			f := r.Fetcher(stubFetcher{})
+			ctx := WithTags(t.Context(), map[string]string{"tenant": "t1"})
+			for range tc.requests {
+				req := &spec.FetchCompletionRequest{ModelParam: spec.ModelParam{Name: "m"}}
+				if _, err := f.FetchCompletion(ctx, "p", req, nil); err != nil {
+					t.Fatalf("FetchCompletion() error = %v.", err)
+				}
+			}
+			if err := r.Close(t.Context()); err != nil {
+				t.Fatalf("Close() error = %v.", err)
+			}
+
+			if tc.wantDropped {
+				if len(dropped) != tc.requests {
+					t.Fatalf("dropped = %d, want = %d.", len(dropped), tc.requests)
+				}
+				return
+			}
+			if len(batches) != tc.wantBatches {
+				t.Fatalf("batches = %d, want = %d.", len(batches), tc.wantBatches)
+			}
+			rec := batches[0][0]
+			if rec.InputTokens != 10 || rec.OutputTokens != 5 || rec.Cost != 0.25 {
+				t.Fatalf("record = %+v, want tokens 10/5 and cost 0.25.", rec)
+			}
+			if rec.Tags["env"] != "test" || rec.Tags["tenant"] != "t1" {
+				t.Fatalf("Tags = %v, want env and tenant.", rec.Tags)
+			}
+		})
+	}
+}
+
+func TestWithTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		layers []map[string]string
+		want   map[string]string
+	}{
+		{name: "NilThenTags.", layers: []map[string]string{nil, {"k": "v"}}, want: map[string]string{"k": "v"}},
+		{
+			name:   "LaterLayerWins.",
+			layers: []map[string]string{{"k": "a", "x": "1"}, {"k": "b"}},
+			want:   map[string]string{"k": "b", "x": "1"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			for _, tags := range tc.layers {
+				ctx = WithTags(ctx, tags)
+			}
+			got, _ := ctx.Value(tagsKey{}).(map[string]string)
+			if !maps.Equal(got, tc.want) {
+				t.Fatalf("tags = %v, want = %v.", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReporterSendTimeout(t *testing.T) {
+	t.Parallel()
+
+	errCh := make(chan error, 1)
+	sender := SenderFunc(func(ctx context.Context, records []Record) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	r, err := New(sender, Options{
+		MaxRetries:  -1,
+		SendTimeout: 10 * time.Millisecond,
+		OnError:     func(err error, _ []Record) { errCh <- err },
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v.", err)
+	}
+	r.Report(Record{Provider: "p"})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	if err := r.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v, want the hung send to time out.", err)
+	}
+	if err := <-errCh; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("OnError error = %v, want a deadline error.", err)
+	}
+}
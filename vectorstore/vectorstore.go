@@ -0,0 +1,255 @@
+// Package vectorstore manages OpenAI vector stores, the retrieval backend used by the Responses API file_search tool.
+//
+// It is layered on ProviderSetAPI.FetchRaw, so it reuses the auth, base URL, debugger and retries of an already
+// configured OpenAI provider. Files must already be uploaded (with purpose "assistants") and are referenced by ID.
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const DefaultPollInterval = time.Second
+
+// RawFetcher is the subset of inference.ProviderSetAPI used by the vector store client.
+type RawFetcher interface {
+	FetchRaw(
+		ctx context.Context,
+		provider spec.ProviderName,
+		method string,
+		path string,
+		body json.RawMessage,
+	) (*spec.RawResponse, error)
+}
+
+type Status string
+
+const (
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+	StatusExpired    Status = "expired"
+	StatusCancelled  Status = "cancelled"
+	StatusFailed     Status = "failed"
+)
+
+type FileCounts struct {
+	InProgress int64 `json:"in_progress"`
+	Completed  int64 `json:"completed"`
+	Failed     int64 `json:"failed"`
+	Cancelled  int64 `json:"cancelled"`
+	Total      int64 `json:"total"`
+}
+
+type VectorStore struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Status     Status     `json:"status"`
+	FileCounts FileCounts `json:"file_counts"`
+	UsageBytes int64      `json:"usage_bytes"`
+	CreatedAt  int64      `json:"created_at"`
+	ExpiresAt  *int64     `json:"expires_at,omitempty"`
+}
+
+type FileError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type File struct {
+	ID            string     `json:"id"`
+	VectorStoreID string     `json:"vector_store_id"`
+	Status        Status     `json:"status"`
+	UsageBytes    int64      `json:"usage_bytes"`
+	LastError     *FileError `json:"last_error,omitempty"`
+}
+
+type CreateParams struct {
+	Name    string   `json:"name,omitempty"`
+	FileIDs []string `json:"file_ids,omitempty"`
+	// ExpiresAfterDays, if > 0, expires the store this many days after it was last active.
+	ExpiresAfterDays int `json:"-"`
+}
+
+// Client manages vector stores of a single OpenAI provider.
+type Client struct {
+	fetcher  RawFetcher
+	provider spec.ProviderName
+}
+
+// New returns a Client for the given provider. The provider must be an OpenAI (Responses or Chat Completions) provider.
+func New(fetcher RawFetcher, provider spec.ProviderName) (*Client, error) {
+	if fetcher == nil || provider == "" {
+		return nil, errors.New("vectorstore: fetcher and provider are required")
+	}
+	return &Client{fetcher: fetcher, provider: provider}, nil
+}
+
+// Create creates a vector store, optionally attaching already uploaded files.
+func (c *Client) Create(ctx context.Context, params CreateParams) (*VectorStore, error) {
+	body := map[string]any{}
+	if params.Name != "" {
+		body["name"] = params.Name
+	}
+	if len(params.FileIDs) > 0 {
+		body["file_ids"] = params.FileIDs
+	}
+	if params.ExpiresAfterDays > 0 {
+		body["expires_after"] = map[string]any{"anchor": "last_active_at", "days": params.ExpiresAfterDays}
+	}
+	var out VectorStore
+	if err := c.do(ctx, http.MethodPost, "vector_stores", body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Get returns a vector store by ID.
+func (c *Client) Get(ctx context.Context, storeID string) (*VectorStore, error) {
+	if storeID == "" {
+		return nil, errors.New("vectorstore: empty vector store id")
+	}
+	var out VectorStore
+	if err := c.do(ctx, http.MethodGet, "vector_stores/"+url.PathEscape(storeID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns the first page (up to 100) of vector stores, newest first.
+func (c *Client) List(ctx context.Context) ([]VectorStore, error) {
+	var out struct {
+		Data []VectorStore `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "vector_stores?limit=100", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// Delete deletes a vector store. Attached files are detached but not deleted.
+func (c *Client) Delete(ctx context.Context, storeID string) error {
+	if storeID == "" {
+		return errors.New("vectorstore: empty vector store id")
+	}
+	return c.do(ctx, http.MethodDelete, "vector_stores/"+url.PathEscape(storeID), nil, nil)
+}
+
+// AddFile attaches an uploaded file to a vector store. Ingestion is asynchronous; see WaitForFile.
+func (c *Client) AddFile(ctx context.Context, storeID, fileID string) (*File, error) {
+	if storeID == "" || fileID == "" {
+		return nil, errors.New("vectorstore: vector store id and file id are required")
+	}
+	var out File
+	path := "vector_stores/" + url.PathEscape(storeID) + "/files"
+	if err := c.do(ctx, http.MethodPost, path, map[string]any{"file_id": fileID}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RemoveFile detaches a file from a vector store.
+func (c *Client) RemoveFile(ctx context.Context, storeID, fileID string) error {
+	if storeID == "" || fileID == "" {
+		return errors.New("vectorstore: vector store id and file id are required")
+	}
+	path := "vector_stores/" + url.PathEscape(storeID) + "/files/" + url.PathEscape(fileID)
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// GetFile returns the ingestion state of a file in a vector store.
+func (c *Client) GetFile(ctx context.Context, storeID, fileID string) (*File, error) {
+	if storeID == "" || fileID == "" {
+		return nil, errors.New("vectorstore: vector store id and file id are required")
+	}
+	var out File
+	path := "vector_stores/" + url.PathEscape(storeID) + "/files/" + url.PathEscape(fileID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WaitForFile polls until the file leaves the in_progress state or ctx is done.
+// A failed ingestion is returned as an error along with the file.
+// A non-positive interval means DefaultPollInterval.
+func (c *Client) WaitForFile(ctx context.Context, storeID, fileID string, interval time.Duration) (*File, error) {
+	for {
+		f, err := c.GetFile(ctx, storeID, fileID)
+		if err != nil {
+			return nil, err
+		}
+		if f.Status != StatusInProgress {
+			if f.Status == StatusFailed && f.LastError != nil {
+				return f, fmt.Errorf("vectorstore: file %s ingestion failed: %s", fileID, f.LastError.Message)
+			}
+			return f, nil
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return f, err
+		}
+	}
+}
+
+// WaitForStore polls until no file in the store is being ingested or ctx is done.
+// A non-positive interval means DefaultPollInterval.
+func (c *Client) WaitForStore(ctx context.Context, storeID string, interval time.Duration) (*VectorStore, error) {
+	for {
+		vs, err := c.Get(ctx, storeID)
+		if err != nil {
+			return nil, err
+		}
+		if vs.Status != StatusInProgress && vs.FileCounts.InProgress == 0 {
+			return vs, nil
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return vs, err
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var raw json.RawMessage
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("vectorstore: encode request: %w", err)
+		}
+		raw = b
+	}
+	resp, err := c.fetcher.FetchRaw(ctx, c.provider, method, path, raw)
+	if err != nil {
+		return fmt.Errorf("vectorstore: %s %s: %w", method, strings.SplitN(path, "?", 2)[0], err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("vectorstore: %s %s: unexpected status %d", method, path, resp.StatusCode)
+	}
+	if out == nil || len(resp.Body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, out); err != nil {
+		return fmt.Errorf("vectorstore: decode response: %w", err)
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		d = DefaultPollInterval
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type fakeFetcher struct {
+	calls     []string
+	responses []string
+}
+
+func (f *fakeFetcher) FetchRaw(
+	ctx context.Context,
+	provider spec.ProviderName,
+	method string,
+	path string,
+	body json.RawMessage,
+) (*spec.RawResponse, error) {
+	f.calls = append(f.calls, method+" "+path)
+	out := f.responses[0]
+	if len(f.responses) > 1 {
+		f.responses = f.responses[1:]
+	}
+	return &spec.RawResponse{StatusCode: http.StatusOK, Body: []byte(out)}, nil
+}
+
+func TestWaitForFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		responses []string
+		wantErr   bool
+		wantCalls int
+	}{
+		{
+			name: "PollsUntilCompleted.",
+			responses: []string{
+				`{"id":"f1","status":"in_progress"}`,
+				`{"id":"f1","status":"in_progress"}`,
+				`{"id":"f1","status":"completed","usage_bytes":10}`,
+			},
+			wantCalls: 3,
+		},
+		{
+			name:      "FailedIngestionIsAnError.",
+			responses: []string{`{"id":"f1","status":"failed","last_error":{"code":"x","message":"bad file"}}`},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &fakeFetcher{responses: tc.responses}
+			c, err := New(f, "openai")
+			if err != nil {
+				t.Fatalf("New() error = %v.", err)
+			}
+			_, err = c.WaitForFile(t.Context(), "vs1", "f1", time.Millisecond)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("WaitForFile() error = %v, wantErr = %v.", err, tc.wantErr)
+			}
+			if len(f.calls) != tc.wantCalls {
+				t.Fatalf("calls = %d, want = %d.", len(f.calls), tc.wantCalls)
+			}
+			if f.calls[0] != "GET vector_stores/vs1/files/f1" {
+				t.Fatalf("call = %q, want = %q.", f.calls[0], "GET vector_stores/vs1/files/f1")
+			}
+		})
+	}
+}